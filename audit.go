@@ -0,0 +1,40 @@
+package genji
+
+import (
+	"context"
+	"time"
+
+	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// WithUser returns a copy of ctx associated with the given user.
+// The mutations made by the databases and sessions using that context
+// on tables created WITH (audit = true) are recorded with this user.
+func WithUser(ctx context.Context, user string) context.Context {
+	return database.WithUser(ctx, user)
+}
+
+// PurgeAudit deletes the audit entries of the given table recorded before t.
+// Queries using FOR SYSTEM_TIME AS OF a time before t will then fail.
+func (db *DB) PurgeAudit(tableName string, t time.Time) error {
+	ti, err := db.db.Catalog.GetTableInfo(tableName)
+	if err != nil {
+		return err
+	}
+	if !ti.Audit {
+		return stringutil.Errorf("table %q is not audited", tableName)
+	}
+
+	at := t.UTC().Format(database.AuditTimeFormat)
+	auditTable := stringutil.NormalizeIdentifier(database.AuditTableName(tableName), '`')
+	return db.Update(func(tx *Tx) error {
+		err := tx.Exec(stringutil.Sprintf("DELETE FROM %s WHERE at < ?", auditTable), at)
+		if err != nil {
+			return err
+		}
+
+		// record the purge, the history of the table cannot be rebuilt before that time.
+		return tx.Exec(stringutil.Sprintf("INSERT INTO %s (op, at) VALUES ('purge', ?)", auditTable), at)
+	})
+}
@@ -0,0 +1,141 @@
+package genji_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAudit(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec("CREATE TABLE foo(a INTEGER PRIMARY KEY, b TEXT) WITH (audit = true)")
+	require.NoError(t, err)
+
+	alice := db.WithContext(genji.WithUser(context.Background(), "alice"))
+	err = alice.Exec("INSERT INTO foo (a, b) VALUES (1, 'x'), (2, 'y')")
+	require.NoError(t, err)
+	err = db.Exec("UPDATE foo SET b = 'z' WHERE a = 1")
+	require.NoError(t, err)
+	err = db.Exec("DELETE FROM foo WHERE a = 2")
+	require.NoError(t, err)
+
+	entries := func() []document.Document {
+		res, err := db.Query("SELECT op, pk, user, old, new FROM __genji_audit_foo")
+		require.NoError(t, err)
+		defer res.Close()
+
+		var docs []document.Document
+		err = res.Iterate(func(d document.Document) error {
+			fb := document.NewFieldBuffer()
+			err := fb.Copy(d)
+			docs = append(docs, fb)
+			return err
+		})
+		require.NoError(t, err)
+		return docs
+	}
+
+	docs := entries()
+	require.Len(t, docs, 4)
+	testutil.RequireDocJSONEq(t, docs[0], `{"op": "insert", "pk": 1, "user": "alice", "old": null, "new": {"a": 1, "b": "x"}}`)
+	testutil.RequireDocJSONEq(t, docs[1], `{"op": "insert", "pk": 2, "user": "alice", "old": null, "new": {"a": 2, "b": "y"}}`)
+	testutil.RequireDocJSONEq(t, docs[2], `{"op": "update", "pk": 1, "user": null, "old": {"a": 1, "b": "x"}, "new": {"a": 1, "b": "z"}}`)
+	testutil.RequireDocJSONEq(t, docs[3], `{"op": "delete", "pk": 2, "user": null, "old": {"a": 2, "b": "y"}, "new": null}`)
+
+	// entries are written in the same transaction as the mutation
+	tx, err := db.Begin(true)
+	require.NoError(t, err)
+	err = tx.Exec("INSERT INTO foo (a) VALUES (3)")
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+	require.Len(t, entries(), 4)
+
+	t.Run("purge", func(t *testing.T) {
+		err := db.PurgeAudit("foo", time.Now())
+		require.NoError(t, err)
+
+		// only the purge is recorded
+		docs := entries()
+		require.Len(t, docs, 1)
+		testutil.RequireDocJSONEq(t, docs[0], `{"op": "purge", "pk": null, "user": null, "old": null, "new": null}`)
+
+		err = db.Exec("CREATE TABLE bar")
+		require.NoError(t, err)
+		err = db.PurgeAudit("bar", time.Now())
+		require.Error(t, err)
+	})
+
+	t.Run("rename and drop", func(t *testing.T) {
+		err := db.Exec("ALTER TABLE foo RENAME TO baz")
+		require.NoError(t, err)
+		err = db.Exec("INSERT INTO baz (a) VALUES (4)")
+		require.NoError(t, err)
+
+		d, err := db.QueryDocument("SELECT COUNT(*) AS n FROM __genji_audit_baz")
+		require.NoError(t, err)
+		testutil.RequireDocJSONEq(t, d, `{"n": 2}`)
+
+		err = db.Exec("DROP TABLE baz")
+		require.NoError(t, err)
+		err = db.Exec("SELECT * FROM __genji_audit_baz")
+		require.Error(t, err)
+	})
+}
+
+func TestHistory(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec("CREATE TABLE foo(a INTEGER PRIMARY KEY, b TEXT) WITH (audit = true); CREATE TABLE bar")
+	require.NoError(t, err)
+
+	now := func() string {
+		return time.Now().UTC().Format(time.RFC3339Nano)
+	}
+
+	t0 := now()
+	err = db.Exec("INSERT INTO foo (a, b) VALUES (1, 'a'), (2, 'b'), (3, 'c')")
+	require.NoError(t, err)
+	t1 := now()
+	err = db.Exec("UPDATE foo SET b = 'B' WHERE a = 2; DELETE FROM foo WHERE a = 1; INSERT INTO foo (a, b) VALUES (4, 'd')")
+	require.NoError(t, err)
+	t2 := now()
+	err = db.Exec("UPDATE foo SET b = 'BB' WHERE a = 2")
+	require.NoError(t, err)
+
+	query := func(q string, args ...interface{}) string {
+		res, err := db.Query(q, args...)
+		require.NoError(t, err)
+		defer res.Close()
+
+		var buf bytes.Buffer
+		err = testutil.IteratorToJSONArray(&buf, res)
+		require.NoError(t, err)
+		return buf.String()
+	}
+
+	require.JSONEq(t, `[]`, query("SELECT * FROM foo FOR SYSTEM_TIME AS OF ?", t0))
+	require.JSONEq(t, `[{"a": 1, "b": "a"}, {"a": 2, "b": "b"}, {"a": 3, "b": "c"}]`, query("SELECT * FROM foo FOR SYSTEM_TIME AS OF ?", t1))
+	require.JSONEq(t, `[{"a": 2, "b": "B"}, {"a": 3, "b": "c"}, {"a": 4, "b": "d"}]`, query("SELECT * FROM foo FOR SYSTEM_TIME AS OF ?", t2))
+	require.JSONEq(t, `[{"b": "b"}]`, query("SELECT b FROM foo FOR SYSTEM_TIME AS OF ? WHERE a = 2", t1))
+
+	// the history is only available for audited tables
+	err = db.Exec("SELECT * FROM bar FOR SYSTEM_TIME AS OF ?", t1)
+	require.Error(t, err)
+
+	// the history cannot be rebuilt before the last purge
+	err = db.PurgeAudit("foo", time.Now())
+	require.NoError(t, err)
+	err = db.Exec("SELECT * FROM foo FOR SYSTEM_TIME AS OF ?", t2)
+	require.Error(t, err)
+}
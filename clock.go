@@ -0,0 +1,66 @@
+package genji
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// A Clock returns the current time. It is used to generate the values depending on time,
+// like ULID and snowflake docids and the timestamps of audit entries.
+type Clock interface {
+	Now() time.Time
+}
+
+// ManualClock is a Clock that only moves when told to,
+// which makes the values depending on time deterministic in tests.
+// It is safe for concurrent use.
+type ManualClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+// NewManualClock creates a clock set to t.
+func NewManualClock(t time.Time) *ManualClock {
+	return &ManualClock{t: t}
+}
+
+// Now returns the time of the clock. It implements the Clock interface.
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.t
+}
+
+// Set the time of the clock.
+func (c *ManualClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.t = t
+}
+
+// Advance moves the clock forward by d.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.t = c.t.Add(d)
+}
+
+// SetClock sets the clock used to generate the values depending on time.
+// If nil, the time of the system is used.
+// It applies to the transactions started after this call.
+func (db *DB) SetClock(c Clock) {
+	db.db.SetClock(c)
+}
+
+// SetRand sets the source of random bytes used to generate random docids, ULIDs
+// and the nonces of encrypted values, e.g. a math/rand.Rand with a fixed seed
+// to make them deterministic in tests. It doesn't need to be safe for concurrent use.
+// If nil, crypto/rand is used.
+// It applies to the transactions started after this call.
+func (db *DB) SetRand(r io.Reader) {
+	db.db.SetRand(r)
+}
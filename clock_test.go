@@ -0,0 +1,71 @@
+package genji_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClockAndRand(t *testing.T) {
+	date := time.Date(2021, time.March, 4, 12, 0, 0, 0, time.UTC)
+
+	// run creates a database with a manual clock and a seeded source of random bytes,
+	// and returns the docids generated by each strategy and the time of an audit entry.
+	run := func(t *testing.T) []document.Value {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		clock := genji.NewManualClock(date)
+		db.SetClock(clock)
+		db.SetRand(rand.New(rand.NewSource(42)))
+		db.SetKeyProvider(genji.KeyMap{"k": make([]byte, 16)})
+
+		err = db.Exec(`
+			CREATE TABLE r WITH (docid = 'random');
+			CREATE TABLE u WITH (docid = 'ulid');
+			CREATE TABLE s WITH (docid = 'snowflake');
+			CREATE TABLE a(id INTEGER PRIMARY KEY) WITH (audit = true);
+		`)
+		require.NoError(t, err)
+
+		var values []document.Value
+		for _, tb := range []string{"r", "u", "s"} {
+			err = db.Exec("INSERT INTO " + tb + " (a) VALUES (1)")
+			require.NoError(t, err)
+			clock.Advance(time.Second)
+
+			d, err := db.QueryDocument("SELECT pk() AS pk FROM " + tb)
+			require.NoError(t, err)
+			v, err := d.GetByField("pk")
+			require.NoError(t, err)
+			values = append(values, v)
+		}
+
+		err = db.Exec("INSERT INTO a (id) VALUES (1)")
+		require.NoError(t, err)
+		d, err := db.QueryDocument("SELECT at FROM __genji_audit_a")
+		require.NoError(t, err)
+		v, err := d.GetByField("at")
+		require.NoError(t, err)
+		values = append(values, v)
+
+		d, err = db.QueryDocument("SELECT encrypt('secret', 'k') AS e FROM a")
+		require.NoError(t, err)
+		v, err = d.GetByField("e")
+		require.NoError(t, err)
+		return append(values, v)
+	}
+
+	first := run(t)
+	require.Equal(t, first, run(t))
+
+	require.Equal(t, document.TextValue, first[1].Type)
+	// the ulid starts with the time of the clock
+	require.Equal(t, "01EZYHQTF8", first[1].V.(string)[:10])
+	require.Equal(t, "2021-03-04T12:00:03.000000000Z", first[3].V)
+}
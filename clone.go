@@ -0,0 +1,126 @@
+package genji
+
+import (
+	"context"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/engine/memoryengine"
+	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/query"
+	"github.com/genjidb/genji/internal/sql/parser"
+)
+
+// Clone creates an in-memory copy of the selected tables, alongside their indexes.
+// If no table is provided, every table of the database is copied.
+// All the tables are read within the same read-only transaction, which guarantees
+// that the returned database is a consistent snapshot of db.
+// Tables of the returned database are read-only: it is meant to run heavy
+// read workloads without contending with writers of the original database.
+func (db *DB) Clone(ctx context.Context, tables ...string) (*DB, error) {
+	src, err := db.db.BeginTx(ctx, &database.TxOptions{
+		ReadOnly: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer src.Rollback()
+
+	if len(tables) == 0 {
+		tables = db.db.Catalog.ListTables()
+	}
+
+	clone, err := New(ctx, memoryengine.NewEngine())
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.cloneTables(ctx, src, clone, tables)
+	if err != nil {
+		clone.Close()
+		return nil, err
+	}
+
+	for _, tableName := range tables {
+		ti, err := clone.db.Catalog.GetTableInfo(tableName)
+		if err != nil {
+			clone.Close()
+			return nil, err
+		}
+
+		ti.ReadOnly = true
+	}
+
+	return clone, nil
+}
+
+func (db *DB) cloneTables(ctx context.Context, src *database.Transaction, clone *DB, tables []string) error {
+	dst, err := clone.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dst.Rollback()
+
+	for _, tableName := range tables {
+		err = db.cloneTable(ctx, src, clone, dst, tableName)
+		if err != nil {
+			return err
+		}
+	}
+
+	return dst.Commit()
+}
+
+func (db *DB) cloneTable(ctx context.Context, src *database.Transaction, clone *DB, dst *database.Transaction, tableName string) error {
+	ti, err := db.db.Catalog.GetTableInfo(tableName)
+	if err != nil {
+		return err
+	}
+
+	// recreate the schema from its SQL representation.
+	// indexes created by table constraints are recreated by the
+	// CREATE TABLE statement.
+	queries := []string{ti.String()}
+	for _, indexName := range db.db.Catalog.ListIndexes(tableName) {
+		info, err := db.db.Catalog.GetIndexInfo(indexName)
+		if err != nil {
+			return err
+		}
+
+		if info.Owner.Path != nil {
+			continue
+		}
+
+		queries = append(queries, info.String())
+	}
+
+	for _, q := range queries {
+		pq, err := parser.ParseQuery(q)
+		if err != nil {
+			return err
+		}
+
+		res, err := pq.Run(&query.Context{Ctx: ctx, DB: clone.db, Tx: dst})
+		if err != nil {
+			return err
+		}
+		err = res.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	srcTable, err := db.db.Catalog.GetTable(src, tableName)
+	if err != nil {
+		return err
+	}
+
+	dstTable, err := clone.db.Catalog.GetTable(dst, tableName)
+	if err != nil {
+		return err
+	}
+
+	return srcTable.Iterate(func(d document.Document) error {
+		_, err := dstTable.Insert(d)
+		return err
+	})
+}
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"os"
+	"strings"
 
 	"github.com/genjidb/genji/cmd/genji/dbutil"
 	"github.com/genjidb/genji/cmd/genji/shell"
@@ -23,11 +24,32 @@ func NewApp() *cli.App {
 			Name:  "badger",
 			Usage: "use badger engine",
 		},
+		&cli.BoolFlag{
+			Name:  "file",
+			Usage: "use file engine, written in pure Go",
+		},
 		&cli.StringFlag{
 			Name:    "encryption-key",
 			Aliases: []string{"k"},
 			Usage:   "encryption key, badger only",
 		},
+		&cli.BoolFlag{
+			Name:  "low-memory",
+			Usage: "reduce the memory used by the engine at the cost of performance, badger only",
+		},
+		&cli.StringFlag{
+			Name:    "exec",
+			Aliases: []string{"e"},
+			Usage:   "execute the given query and exit",
+		},
+		&cli.BoolFlag{
+			Name:  "readonly",
+			Usage: "reject statements writing to the database",
+		},
+		&cli.DurationFlag{
+			Name:  "timeout",
+			Usage: "maximum duration of each statement, i.e. 30s",
+		},
 	}
 
 	app.Commands = []*cli.Command{
@@ -35,20 +57,25 @@ func NewApp() *cli.App {
 		NewVersionCommand(),
 		NewDumpCommand(),
 		NewRestoreCommand(),
+		NewSchemaCommand(),
+		NewAuditCommand(),
+		NewSeedCommand(),
+		NewTableCommand(),
 	}
 
 	// Root command
 	app.Action = func(c *cli.Context) error {
 		useBolt := c.Bool("bolt")
 		useBadger := c.Bool("badger")
-		if useBolt && useBadger {
-			return cli.Exit("cannot use bolt and badger options at the same time", 2)
+		useFile := c.Bool("file")
+		if (useBolt && useBadger) || (useBolt && useFile) || (useBadger && useFile) {
+			return cli.Exit("cannot use more than one of the bolt, badger and file options", 2)
 		}
 
 		dbpath := c.Args().First()
 
-		if (useBolt || useBadger) && dbpath == "" {
-			return cli.Exit("db path required when using bolt or badger", 2)
+		if (useBolt || useBadger || useFile) && dbpath == "" {
+			return cli.Exit("db path required when using bolt, badger or file", 2)
 		}
 
 		engine := "memory"
@@ -61,25 +88,46 @@ func NewApp() *cli.App {
 			engine = "badger"
 		}
 
+		if useFile {
+			engine = "file"
+		}
+
 		k := c.String("encryption-key")
 		if k != "" && engine != "badger" {
 			return cli.Exit("encryption key is only supported by the badger engine", 2)
 		}
 
-		if dbutil.CanReadFromStandardInput() {
-			db, err := dbutil.OpenDB(c.Context, dbpath, engine, dbutil.DBOptions{EncryptionKey: k})
+		lowMemory := c.Bool("low-memory")
+		if lowMemory && engine != "badger" {
+			return cli.Exit("low memory mode is only supported by the badger engine", 2)
+		}
+
+		execOpts := dbutil.ExecOptions{
+			ReadOnly: c.Bool("readonly"),
+			Timeout:  c.Duration("timeout"),
+		}
+
+		if c.IsSet("exec") || dbutil.CanReadFromStandardInput() {
+			db, err := dbutil.OpenDB(c.Context, dbpath, engine, dbutil.DBOptions{EncryptionKey: k, LowMemory: lowMemory})
 			if err != nil {
 				return err
 			}
 			defer db.Close()
 
-			return dbutil.ExecSQL(c.Context, db, os.Stdin, os.Stdout)
+			if c.IsSet("exec") {
+				return dbutil.ExecSQLWithOptions(c.Context, db, strings.NewReader(c.String("exec")), os.Stdout, execOpts)
+			}
+
+			return dbutil.ExecSQLWithOptions(c.Context, db, os.Stdin, os.Stdout, execOpts)
 		}
 
 		return shell.Run(c.Context, &shell.Options{
 			Engine:        engine,
 			DBPath:        dbpath,
 			EncryptionKey: k,
+			LowMemory:     lowMemory,
+			ReadOnly:      execOpts.ReadOnly,
+			Timeout:       execOpts.Timeout,
 		})
 	}
 
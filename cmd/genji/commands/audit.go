@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"errors"
+	"time"
+
+	"github.com/genjidb/genji/cmd/genji/dbutil"
+	"github.com/urfave/cli/v2"
+)
+
+// NewAuditCommand returns a cli.Command for "genji audit".
+func NewAuditCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "audit",
+		Usage: "Manage the audit trails of the tables created WITH (audit = true)",
+		Subcommands: []*cli.Command{
+			NewAuditPurgeCommand(),
+		},
+	}
+}
+
+// NewAuditPurgeCommand returns a cli.Command for "genji audit purge".
+func NewAuditPurgeCommand() *cli.Command {
+	cmd := cli.Command{
+		Name:      "purge",
+		Usage:     "Delete the old entries of the audit trail of a table",
+		UsageText: `genji audit purge [options] dbpath`,
+		Description: `The purge command deletes the entries of the audit trail of a table
+that are older than the given retention period:
+
+$ genji audit purge -t foo --retention 720h my.db`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "table",
+				Aliases:  []string{"t"},
+				Usage:    "name of the audited table",
+				Required: true,
+			},
+			&cli.DurationFlag{
+				Name:     "retention",
+				Aliases:  []string{"r"},
+				Usage:    "duration during which the entries are kept, e.g. 720h",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:    "engine",
+				Aliases: []string{"e"},
+				Usage:   "name of the engine to use, options are 'bolt', 'badger' or 'file'",
+				Value:   "bolt",
+			},
+			&cli.StringFlag{
+				Name:    "encryption-key",
+				Aliases: []string{"k"},
+				Usage:   "encryption key, badger only",
+			},
+		},
+	}
+
+	cmd.Action = func(c *cli.Context) error {
+		engine := c.String("engine")
+		dbPath := c.Args().First()
+		if dbPath == "" {
+			return errors.New(cmd.UsageText)
+		}
+
+		k := c.String("encryption-key")
+		if k != "" && engine != "badger" {
+			return cli.Exit("encryption key is only supported by the badger engine", 2)
+		}
+
+		db, err := dbutil.OpenDB(c.Context, dbPath, engine, dbutil.DBOptions{EncryptionKey: k})
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		return db.PurgeAudit(c.String("table"), time.Now().Add(-c.Duration("retention")))
+	}
+
+	return &cmd
+}
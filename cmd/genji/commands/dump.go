@@ -4,6 +4,7 @@ import (
 	"errors"
 	"io"
 	"os"
+	"time"
 
 	"github.com/genjidb/genji/cmd/genji/dbutil"
 	"github.com/urfave/cli/v2"
@@ -29,7 +30,15 @@ $ genji dump -t foo -f bar my.db
 
 The dump command can also write directly into a file:
 
-$ genji dump -f dump.sql my.db`,
+$ genji dump -f dump.sql my.db
+
+With --since, only the changes made since the given time are dumped, producing
+an incremental dump to restore after a full dump taken before that time.
+The documents modified in tables created WITH (audit = true) are read from their audit trail,
+the other tables are dumped entirely. The first line of the dump contains the time to pass
+to --since for the next incremental dump:
+
+$ genji dump --since 2021-06-01T10:00:00Z -f inc.sql my.db`,
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:    "file",
@@ -41,10 +50,15 @@ $ genji dump -f dump.sql my.db`,
 				Aliases: []string{"t"},
 				Usage:   "name of the table, it must already exist. Defaults to all tables.",
 			},
+			&cli.TimestampFlag{
+				Name:   "since",
+				Usage:  "only dump the changes made since the given RFC 3339 time.",
+				Layout: time.RFC3339Nano,
+			},
 			&cli.StringFlag{
 				Name:    "engine",
 				Aliases: []string{"e"},
-				Usage:   "name of the engine to use, options are 'bolt' or 'badger'",
+				Usage:   "name of the engine to use, options are 'bolt', 'badger' or 'file'",
 				Value:   "bolt",
 			},
 			&cli.StringFlag{
@@ -87,6 +101,11 @@ $ genji dump -f dump.sql my.db`,
 			w = file
 		}
 
+		if since := c.Timestamp("since"); since != nil {
+			_, err = dbutil.DumpChanges(c.Context, db, w, *since, tables...)
+			return err
+		}
+
 		return dbutil.Dump(c.Context, db, w, tables...)
 	}
 
@@ -44,7 +44,7 @@ $ curl https://api.github.com/repos/genjidb/genji/issues | genji insert --db my.
 			&cli.StringFlag{
 				Name:    "engine",
 				Aliases: []string{"e"},
-				Usage:   "name of the engine to use, options are 'bolt' or 'badger'",
+				Usage:   "name of the engine to use, options are 'bolt', 'badger' or 'file'",
 				Value:   "bolt",
 			},
 			&cli.StringFlag{
@@ -4,24 +4,30 @@ import (
 	"errors"
 	"os"
 
+	"github.com/genjidb/genji"
 	"github.com/genjidb/genji/cmd/genji/dbutil"
 	"github.com/urfave/cli/v2"
 )
 
 // NewRestoreCommand returns a cli.Command for "genji restore".
 func NewRestoreCommand() (cmd *cli.Command) {
-	return &cli.Command{
+	cmd = &cli.Command{
 		Name:      "restore",
-		Usage:     "Restore a database from a file created by genji dump",
-		UsageText: `genji restore dumpFile dbPath`,
+		Usage:     "Restore a database from files created by genji dump",
+		UsageText: `genji restore dumpFile [incrementalDumpFile...] dbPath`,
 		Description: `The restore command can restore a database from a text file.
 
-	$ genji restore dump.sql my.db`,
+	$ genji restore dump.sql my.db
+
+A full dump can be followed by a chain of incremental dumps, created with genji dump --since,
+which are applied in order:
+
+	$ genji restore dump.sql inc1.sql inc2.sql my.db`,
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:    "engine",
 				Aliases: []string{"e"},
-				Usage:   "name of the engine to use, options are 'bolt' or 'badger'",
+				Usage:   "name of the engine to use, options are 'bolt', 'badger' or 'file'",
 				Value:   "bolt",
 			},
 			&cli.StringFlag{
@@ -37,7 +43,7 @@ func NewRestoreCommand() (cmd *cli.Command) {
 				return cli.Exit("encryption key is only supported by the badger engine", 2)
 			}
 
-			if c.Args().Len() != 2 {
+			if c.Args().Len() < 2 {
 				return errors.New(cmd.UsageText)
 			}
 			dbPath := c.Args().Get(c.Args().Len() - 1)
@@ -45,24 +51,40 @@ func NewRestoreCommand() (cmd *cli.Command) {
 				return errors.New("database path expected")
 			}
 
-			f := c.Args().First()
-			if f == "" {
-				return errors.New("dump file expected")
+			files := c.Args().Slice()[:c.Args().Len()-1]
+			for _, f := range files {
+				if f == "" {
+					return errors.New("dump file expected")
+				}
 			}
 
-			file, err := os.Open(f)
-			if err != nil {
-				return err
-			}
-			defer file.Close()
-
 			db, err := dbutil.OpenDB(c.Context, dbPath, engine, dbutil.DBOptions{EncryptionKey: k})
 			if err != nil {
 				return err
 			}
 			defer db.Close()
 
-			return dbutil.ExecSQL(c.Context, db, file, os.Stdout)
+			for _, f := range files {
+				err = restoreFile(c, db, f)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
 		},
 	}
+
+	return cmd
+}
+
+// restoreFile executes the queries of the dump file.
+func restoreFile(c *cli.Context, db *genji.DB, f string) error {
+	file, err := os.Open(f)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return dbutil.ExecSQL(c.Context, db, file, os.Stdout)
 }
@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/cmd/genji/dbutil"
+	"github.com/urfave/cli/v2"
+)
+
+// NewSchemaCommand returns a cli.Command for "genji schema".
+func NewSchemaCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "schema",
+		Usage: "Manage the schema of a database",
+		Subcommands: []*cli.Command{
+			NewSchemaDiffCommand(),
+		},
+	}
+}
+
+// NewSchemaDiffCommand returns a cli.Command for "genji schema diff".
+func NewSchemaDiffCommand() *cli.Command {
+	cmd := cli.Command{
+		Name:      "diff",
+		Usage:     "Output the statements migrating the schema of a database to the one of another",
+		UsageText: `genji schema diff [options] old new`,
+		Description: `The diff command compares the schemas of two databases and outputs
+the statements required to migrate the first one to the second one.
+
+$ genji schema diff old.db new.db
+ALTER TABLE foo ADD FIELD b TEXT;
+CREATE INDEX idx_foo_b ON foo (b);
+
+Each database can be replaced by a file containing SQL statements, like the ones
+created by genji dump, if its name ends with .sql:
+
+$ genji schema diff my.db schema.sql | genji my.db`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "engine",
+				Aliases: []string{"e"},
+				Usage:   "name of the engine to use, options are 'bolt', 'badger' or 'file'",
+				Value:   "bolt",
+			},
+			&cli.StringFlag{
+				Name:    "encryption-key",
+				Aliases: []string{"k"},
+				Usage:   "encryption key, badger only",
+			},
+		},
+	}
+
+	cmd.Action = func(c *cli.Context) error {
+		engine := c.String("engine")
+		k := c.String("encryption-key")
+		if k != "" && engine != "badger" {
+			return cli.Exit("encryption key is only supported by the badger engine", 2)
+		}
+
+		if c.Args().Len() != 2 {
+			return errors.New(cmd.UsageText)
+		}
+
+		var dbs [2]*genji.DB
+		for i := range dbs {
+			db, err := openSchemaSource(c.Context, c.Args().Get(i), engine, dbutil.DBOptions{EncryptionKey: k})
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			dbs[i] = db
+		}
+
+		return dbutil.SchemaDiff(dbs[0], dbs[1], os.Stdout)
+	}
+
+	return &cmd
+}
+
+// openSchemaSource opens the database at path or, if path is an SQL file,
+// creates an in-memory database and executes the content of the file.
+func openSchemaSource(ctx context.Context, path, engine string, opts dbutil.DBOptions) (*genji.DB, error) {
+	if !strings.HasSuffix(path, ".sql") {
+		return dbutil.OpenDB(ctx, path, engine, opts)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	db, err := dbutil.OpenDB(ctx, "", "memory", opts)
+	if err != nil {
+		return nil, err
+	}
+
+	err = dbutil.ExecSQL(ctx, db, f, ioutil.Discard)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"errors"
+
+	"github.com/genjidb/genji/cmd/genji/dbutil"
+	"github.com/urfave/cli/v2"
+)
+
+// NewSeedCommand returns a cli.Command for "genji seed".
+func NewSeedCommand() *cli.Command {
+	cmd := cli.Command{
+		Name:      "seed",
+		Usage:     "Insert generated documents into a table, for benchmarks and demos",
+		UsageText: `genji seed [options] dbpath`,
+		Description: `The seed command inserts documents generated from a template into a table,
+creating it if it doesn't exist. The template is a Go text/template producing a JSON object:
+
+$ genji seed -t users -n 100000 --template '{"name": "{{name}}", "age": {{int 18 80}}}' my.db
+
+Available functions:
+  name, firstName, lastName, email   random person names and emails
+  int MIN MAX, float MIN MAX         random numbers between MIN and MAX
+  bool                               random boolean
+  pick A B...                        one of the arguments
+  word, text N                       random words
+  date                               random date of the last ten years
+  uuid                               random UUID
+  seq                                position of the document, starting at 1`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "table",
+				Aliases:  []string{"t"},
+				Usage:    "name of the table",
+				Required: true,
+			},
+			&cli.IntFlag{
+				Name:    "count",
+				Aliases: []string{"n"},
+				Usage:   "number of documents to insert",
+				Value:   1000,
+			},
+			&cli.StringFlag{
+				Name:     "template",
+				Usage:    "template of the documents",
+				Required: true,
+			},
+			&cli.IntFlag{
+				Name:  "batch-size",
+				Usage: "number of documents inserted per transaction",
+				Value: 1000,
+			},
+			&cli.Int64Flag{
+				Name:  "seed",
+				Usage: "seed of the random generator, for reproducible data",
+			},
+			&cli.StringFlag{
+				Name:    "engine",
+				Aliases: []string{"e"},
+				Usage:   "name of the engine to use, options are 'bolt', 'badger' or 'file'",
+				Value:   "bolt",
+			},
+			&cli.StringFlag{
+				Name:    "encryption-key",
+				Aliases: []string{"k"},
+				Usage:   "encryption key, badger only",
+			},
+		},
+	}
+
+	cmd.Action = func(c *cli.Context) error {
+		engine := c.String("engine")
+		dbPath := c.Args().First()
+		if dbPath == "" {
+			return errors.New(cmd.UsageText)
+		}
+
+		k := c.String("encryption-key")
+		if k != "" && engine != "badger" {
+			return cli.Exit("encryption key is only supported by the badger engine", 2)
+		}
+
+		db, err := dbutil.OpenDB(c.Context, dbPath, engine, dbutil.DBOptions{EncryptionKey: k})
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		return dbutil.Seed(c.Context, db, c.String("table"), c.String("template"), dbutil.SeedOptions{
+			Count:     c.Int("count"),
+			BatchSize: c.Int("batch-size"),
+			Seed:      c.Int64("seed"),
+		})
+	}
+
+	return &cmd
+}
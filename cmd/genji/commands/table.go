@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"github.com/genjidb/genji/cmd/genji/dbutil"
+	"github.com/urfave/cli/v2"
+)
+
+// NewTableCommand returns a cli.Command for "genji table".
+func NewTableCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "table",
+		Usage: "Move a single table between databases",
+		Subcommands: []*cli.Command{
+			NewTableExportCommand(),
+			NewTableImportCommand(),
+		},
+	}
+}
+
+// NewTableExportCommand returns a cli.Command for "genji table export".
+func NewTableExportCommand() *cli.Command {
+	cmd := cli.Command{
+		Name:      "export",
+		Usage:     "Export a table with its schema, indexes and sequences state",
+		UsageText: `genji table export [options] table dbpath`,
+		Description: `The export command writes a table, its constraints, its indexes, its documents
+and the state of its sequences to a file, to be imported in another database with genji table import.
+
+By default, the export is sent to the standard output:
+
+$ genji table export -o foo.genjitable foo my.db`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "name of the file to output to. Defaults to STDOUT.",
+			},
+			&cli.StringFlag{
+				Name:    "engine",
+				Aliases: []string{"e"},
+				Usage:   "name of the engine to use, options are 'bolt', 'badger' or 'file'",
+				Value:   "bolt",
+			},
+			&cli.StringFlag{
+				Name:    "encryption-key",
+				Aliases: []string{"k"},
+				Usage:   "encryption key, badger only",
+			},
+		},
+	}
+
+	cmd.Action = func(c *cli.Context) error {
+		engine := c.String("engine")
+		table := c.Args().Get(0)
+		dbPath := c.Args().Get(1)
+		if table == "" || dbPath == "" {
+			return errors.New(cmd.UsageText)
+		}
+
+		k := c.String("encryption-key")
+		if k != "" && engine != "badger" {
+			return cli.Exit("encryption key is only supported by the badger engine", 2)
+		}
+
+		db, err := dbutil.OpenDB(c.Context, dbPath, engine, dbutil.DBOptions{EncryptionKey: k})
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		var w io.Writer = os.Stdout
+
+		if f := c.String("output"); f != "" {
+			file, err := os.Create(f)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			w = file
+		}
+
+		return dbutil.ExportTable(c.Context, db, w, table)
+	}
+
+	return &cmd
+}
+
+// NewTableImportCommand returns a cli.Command for "genji table import".
+func NewTableImportCommand() *cli.Command {
+	cmd := cli.Command{
+		Name:      "import",
+		Usage:     "Import a table exported by genji table export",
+		UsageText: `genji table import [options] exportFile dbpath`,
+		Description: `The import command creates a table from a file created by genji table export.
+The table must not exist in the database:
+
+$ genji table import foo.genjitable other.db`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "engine",
+				Aliases: []string{"e"},
+				Usage:   "name of the engine to use, options are 'bolt', 'badger' or 'file'",
+				Value:   "bolt",
+			},
+			&cli.StringFlag{
+				Name:    "encryption-key",
+				Aliases: []string{"k"},
+				Usage:   "encryption key, badger only",
+			},
+		},
+	}
+
+	cmd.Action = func(c *cli.Context) error {
+		engine := c.String("engine")
+		f := c.Args().Get(0)
+		dbPath := c.Args().Get(1)
+		if f == "" || dbPath == "" {
+			return errors.New(cmd.UsageText)
+		}
+
+		k := c.String("encryption-key")
+		if k != "" && engine != "badger" {
+			return cli.Exit("encryption key is only supported by the badger engine", 2)
+		}
+
+		file, err := os.Open(f)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		db, err := dbutil.OpenDB(c.Context, dbPath, engine, dbutil.DBOptions{EncryptionKey: k})
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		return dbutil.ImportTable(c.Context, db, file, os.Stdout)
+	}
+
+	return &cmd
+}
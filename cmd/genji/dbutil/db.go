@@ -12,12 +12,15 @@ import (
 	"github.com/genjidb/genji/engine"
 	"github.com/genjidb/genji/engine/badgerengine"
 	"github.com/genjidb/genji/engine/boltengine"
+	"github.com/genjidb/genji/engine/fileengine"
 	"github.com/genjidb/genji/engine/memoryengine"
 	"go.etcd.io/bbolt"
 )
 
 type DBOptions struct {
 	EncryptionKey string
+	// Use the low memory options of the engine, badger only.
+	LowMemory bool
 }
 
 // OpenDB opens a database at the given path, using the selected engine.
@@ -39,17 +42,27 @@ func OpenDB(ctx context.Context, dbPath, engineName string, opts DBOptions) (*ge
 		}
 	case "badger":
 		opt := badger.DefaultOptions(dbPath).WithLogger(nil)
+		if opts.LowMemory {
+			opt = badgerengine.LowMemoryOptions(dbPath).WithLogger(nil)
+		}
 		if opts.EncryptionKey != "" {
 			opt.EncryptionKey = []byte(opts.EncryptionKey)
 			opt.IndexCacheSize = 100 << 20
 		}
 
-		ng, err = badgerengine.NewEngine(opt)
+		var bng *badgerengine.Engine
+		bng, err = badgerengine.NewEngine(opt)
 		if err != nil && strings.HasPrefix(err.Error(), "Cannot acquire directory lock") {
 			return nil, errors.New("database is locked")
 		}
+		if err == nil && opts.LowMemory {
+			bng.PrefetchSize = badgerengine.LowMemoryPrefetchSize
+		}
+		ng = bng
+	case "file":
+		ng, err = fileengine.NewEngine(dbPath)
 	default:
-		return nil, fmt.Errorf(`engine should be "bolt" or "badger", got %q`, engineName)
+		return nil, fmt.Errorf(`engine should be "bolt", "badger" or "file", got %q`, engineName)
 	}
 	if err != nil {
 		return nil, err
@@ -4,9 +4,14 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
+	"time"
 
 	"github.com/genjidb/genji"
 	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/query/statement"
+	"github.com/genjidb/genji/internal/sql/parser"
 	"go.uber.org/multierr"
 )
 
@@ -35,6 +40,10 @@ func Dump(ctx context.Context, db *genji.DB, w io.Writer, tables ...string) erro
 
 		return dumpTable(tx, w, query, name)
 	})
+	// views are dumped after the tables they read
+	if err == nil && len(tables) == 0 {
+		err = dumpViews(tx, w, i > 0)
+	}
 	if err != nil {
 		_, er := fmt.Fprintln(w, "ROLLBACK;")
 		return multierr.Append(err, er)
@@ -74,6 +83,138 @@ func dumpTable(tx *genji.Tx, w io.Writer, query, tableName string) error {
 	})
 }
 
+// DumpChanges dumps the changes made to the database since the given time as SQL queries
+// in the given writer. The resulting incremental dump must be restored after a full dump
+// taken before since, or after the incremental dump preceding it. It returns the time
+// until which the changes were dumped, to be used as the start of the next incremental dump.
+// It is also written in the first line of the dump.
+//
+// Only the documents modified since then are dumped for the tables created WITH (audit = true)
+// and having a primary key, as recorded by their audit trail, which must not have been purged since.
+// They are created if they don't exist. The other tables are dropped and dumped entirely.
+// Other schema changes, like dropped tables or views, are not dumped.
+// Changes are applied idempotently, which allows a dump to overlap the previous one.
+//
+// The changes are read in a read-write transaction, to make sure no change
+// being committed while the dump starts is missed.
+// If tables is provided, only selected tables will be outputted.
+func DumpChanges(ctx context.Context, db *genji.DB, w io.Writer, since time.Time, tables ...string) (time.Time, error) {
+	tx, err := db.Begin(true)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer tx.Rollback()
+
+	until := time.Now().UTC()
+
+	_, err = fmt.Fprintf(w, "-- changes from %s to %s\nBEGIN TRANSACTION;\n", since.UTC().Format(time.RFC3339Nano), until.Format(time.RFC3339Nano))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	i := 0
+	err = QueryTables(tx, tables, func(name, query string) error {
+		// Blank separation between tables.
+		if i > 0 {
+			if _, err := fmt.Fprintln(w, ""); err != nil {
+				return err
+			}
+		}
+		i++
+
+		stmt, err := parser.ParseQuery(query)
+		if err != nil {
+			return err
+		}
+		info := stmt.Statements[0].(*statement.CreateTableStmt).Info
+		if !info.Audit || len(info.FieldConstraints.GetPrimaryKeys()) == 0 {
+			if _, err := fmt.Fprintf(w, "DROP TABLE IF EXISTS %s;\n", name); err != nil {
+				return err
+			}
+			return dumpTable(tx, w, query, name)
+		}
+
+		return dumpTableChanges(tx, w, query, name, since, until)
+	})
+	if err != nil {
+		_, er := fmt.Fprintln(w, "ROLLBACK;")
+		return time.Time{}, multierr.Append(err, er)
+	}
+
+	_, err = fmt.Fprintln(w, "COMMIT;")
+	return until, err
+}
+
+// dumpTableChanges displays the changes made to an audited table between since and until
+// as SQL statements, preceded by its schema.
+func dumpTableChanges(tx *genji.Tx, w io.Writer, query, tableName string, since, until time.Time) error {
+	var schema strings.Builder
+	if err := dumpSchema(tx, &schema, query, tableName); err != nil {
+		return err
+	}
+
+	// the table may have been created after the previous dump
+	for _, q := range strings.SplitAfter(schema.String(), "\n") {
+		if q == "" {
+			continue
+		}
+
+		for _, prefix := range []string{"CREATE TABLE ", "CREATE INDEX ", "CREATE UNIQUE INDEX ", "CREATE SEQUENCE "} {
+			if strings.HasPrefix(q, prefix) {
+				q = prefix + "IF NOT EXISTS " + q[len(prefix):]
+				break
+			}
+		}
+		if _, err := io.WriteString(w, q); err != nil {
+			return err
+		}
+	}
+
+	res, err := tx.Query(
+		fmt.Sprintf("SELECT op, pk, new FROM %s WHERE at >= ? AND at < ?", database.AuditTableName(tableName)),
+		since.UTC().Format(database.AuditTimeFormat), until.UTC().Format(database.AuditTimeFormat),
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+
+	return res.Iterate(func(d document.Document) error {
+		op, err := d.GetByField("op")
+		if err != nil {
+			return err
+		}
+
+		if op.V.(string) == "delete" {
+			pk, err := d.GetByField("pk")
+			if err != nil {
+				return err
+			}
+
+			data, err := pk.MarshalJSON()
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintf(w, "DELETE FROM %s WHERE pk() = %s;\n", tableName, data)
+			return err
+		}
+
+		v, err := d.GetByField("new")
+		if err != nil {
+			return err
+		}
+
+		data, err := document.MarshalJSON(v.V.(document.Document))
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(w, "INSERT INTO %s VALUES %s ON CONFLICT DO REPLACE;\n", tableName, data)
+		return err
+	})
+}
+
 // DumpSchema takes a database and dumps its schema as SQL queries in the given writer.
 // If tables are provided, only selected tables will be outputted.
 func DumpSchema(ctx context.Context, db *genji.DB, w io.Writer, tables ...string) error {
@@ -84,7 +225,7 @@ func DumpSchema(ctx context.Context, db *genji.DB, w io.Writer, tables ...string
 	defer tx.Rollback()
 
 	i := 0
-	return QueryTables(tx, tables, func(name, query string) error {
+	err = QueryTables(tx, tables, func(name, query string) error {
 		// Blank separation between tables.
 		if i > 0 {
 			if _, err := fmt.Fprintln(w, ""); err != nil {
@@ -95,6 +236,40 @@ func DumpSchema(ctx context.Context, db *genji.DB, w io.Writer, tables ...string
 
 		return dumpSchema(tx, w, query, name)
 	})
+	if err != nil || len(tables) > 0 {
+		return err
+	}
+
+	return dumpViews(tx, w, i > 0)
+}
+
+// dumpViews displays the CREATE VIEW statements of all views, preceded by a blank line
+// if sep is true and there is at least one view.
+func dumpViews(tx *genji.Tx, w io.Writer, sep bool) error {
+	res, err := tx.Query("SELECT sql FROM __genji_catalog WHERE type = 'view'")
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+
+	return res.Iterate(func(d document.Document) error {
+		var q string
+
+		err = document.Scan(d, &q)
+		if err != nil {
+			return err
+		}
+
+		if sep {
+			if _, err := fmt.Fprintln(w, ""); err != nil {
+				return err
+			}
+			sep = false
+		}
+
+		_, err = fmt.Fprintf(w, "%s;\n", q)
+		return err
+	})
 }
 
 // dumpSchema displays the schema of the given table as SQL statements.
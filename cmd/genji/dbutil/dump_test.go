@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io/ioutil"
 	"testing"
+	"time"
 
 	"github.com/genjidb/genji"
 	"github.com/stretchr/testify/require"
@@ -83,6 +85,14 @@ func TestDump(t *testing.T) {
 				require.NoError(t, err)
 				writeToBuf(q + "\n")
 			}
+
+			// views are only dumped with the whole database
+			q := "CREATE VIEW v AS SELECT a FROM tblA WHERE a > 1;"
+			err = db.Exec(q)
+			require.NoError(t, err)
+			if len(tt.tables) == 0 {
+				want.WriteString("\n" + q + "\n")
+			}
 			want.WriteString("COMMIT;\n")
 
 			var got bytes.Buffer
@@ -94,6 +104,74 @@ func TestDump(t *testing.T) {
 	}
 }
 
+func TestDumpChanges(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE foo (a INTEGER PRIMARY KEY, b TEXT) WITH (audit = true);
+		CREATE INDEX idx_foo_b ON foo (b);
+		CREATE TABLE bar;
+		INSERT INTO foo (a, b) VALUES (1, 'a'), (2, 'b'), (3, 'c');
+		INSERT INTO bar (a) VALUES (1);
+	`)
+	require.NoError(t, err)
+
+	since := time.Now()
+	var base bytes.Buffer
+	err = Dump(context.Background(), db, &base)
+	require.NoError(t, err)
+
+	err = db.Exec(`
+		UPDATE foo SET b = 'B' WHERE a = 2;
+		DELETE FROM foo WHERE a = 3;
+		INSERT INTO bar (a) VALUES (2);
+		CREATE TABLE baz (a INTEGER PRIMARY KEY) WITH (audit = true);
+		INSERT INTO baz (a) VALUES (1);
+	`)
+	require.NoError(t, err)
+
+	var inc1 bytes.Buffer
+	since, err = DumpChanges(context.Background(), db, &inc1, since)
+	require.NoError(t, err)
+	require.Contains(t, inc1.String(), "-- changes from ")
+	require.Contains(t, inc1.String(), `INSERT INTO foo VALUES {"a": 2, "b": "B"} ON CONFLICT DO REPLACE;`)
+	require.Contains(t, inc1.String(), "DELETE FROM foo WHERE pk() = 3;")
+	require.Contains(t, inc1.String(), "DROP TABLE IF EXISTS bar;")
+	require.NotContains(t, inc1.String(), `{"a": 1, "b": "a"}`)
+
+	err = db.Exec(`
+		INSERT INTO foo (a, b) VALUES (4, 'd');
+		DELETE FROM baz;
+	`)
+	require.NoError(t, err)
+
+	var inc2 bytes.Buffer
+	_, err = DumpChanges(context.Background(), db, &inc2, since)
+	require.NoError(t, err)
+	require.NotContains(t, inc2.String(), "pk() = 3")
+
+	// restore the base dump and the chain of increments
+	restored, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer restored.Close()
+
+	for _, dump := range []*bytes.Buffer{&base, &inc1, &inc2} {
+		err = ExecSQL(context.Background(), restored, dump, ioutil.Discard)
+		require.NoError(t, err)
+	}
+
+	for _, table := range []string{"foo", "bar", "baz"} {
+		var want, got bytes.Buffer
+		err = Dump(context.Background(), db, &want, table)
+		require.NoError(t, err)
+		err = Dump(context.Background(), restored, &got, table)
+		require.NoError(t, err)
+		require.Equal(t, want.String(), got.String())
+	}
+}
+
 func TestDumpSchema(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -4,16 +4,34 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/genjidb/genji"
 	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/query"
+	"github.com/genjidb/genji/internal/sql/parser"
 )
 
+// ExecOptions controls how queries are executed.
+type ExecOptions struct {
+	// If true, statements writing to the database are rejected.
+	ReadOnly bool
+	// Maximum duration of each statement. Zero means no limit.
+	Timeout time.Duration
+}
+
 // ExecSQL reads SQL queries from reader and executes them until the reader is exhausted.
 // If the query has results, they will be outputted to w.
 func ExecSQL(ctx context.Context, db *genji.DB, r io.Reader, w io.Writer) error {
+	return ExecSQLWithOptions(ctx, db, r, w, ExecOptions{})
+}
+
+// ExecSQLWithOptions works like ExecSQL but runs the queries according to opts.
+func ExecSQLWithOptions(ctx context.Context, db *genji.DB, r io.Reader, w io.Writer, opts ExecOptions) error {
 	scanner := bufio.NewScanner(r)
 	scanner.Buffer(nil, 128*1024*1024)
 
@@ -38,7 +56,13 @@ func ExecSQL(ctx context.Context, db *genji.DB, r io.Reader, w io.Writer) error
 			continue
 		}
 
-		if err := runQuery(ctx, db, q, w); err != nil {
+		if opts.ReadOnly {
+			if err := checkReadOnly(q); err != nil {
+				return err
+			}
+		}
+
+		if err := runQuery(ctx, db, q, w, opts.Timeout); err != nil {
 			return err
 		}
 	}
@@ -46,7 +70,45 @@ func ExecSQL(ctx context.Context, db *genji.DB, r io.Reader, w io.Writer) error
 	return scanner.Err()
 }
 
-func runQuery(ctx context.Context, db *genji.DB, q string, w io.Writer) error {
+// checkReadOnly returns an error if q contains statements writing to the database.
+// Read-only transactions can still be started and ended.
+func checkReadOnly(q string) error {
+	pq, err := parser.ParseQuery(q)
+	if err != nil {
+		return err
+	}
+
+	for _, stmt := range pq.Statements {
+		switch stmt.(type) {
+		case query.CommitStmt, query.RollbackStmt:
+			continue
+		}
+
+		if !stmt.IsReadOnly() {
+			return fmt.Errorf("cannot execute %q in read-only mode", q)
+		}
+	}
+
+	return nil
+}
+
+func runQuery(ctx context.Context, db *genji.DB, q string, w io.Writer, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel func()
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+		db = db.WithContext(ctx)
+	}
+
+	err := runQueryContext(ctx, db, q, w)
+	if timeout > 0 && errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("statement timed out after %s", timeout)
+	}
+
+	return err
+}
+
+func runQueryContext(ctx context.Context, db *genji.DB, q string, w io.Writer) error {
 	res, err := db.Query(q)
 	if err != nil {
 		return err
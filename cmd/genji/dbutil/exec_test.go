@@ -3,8 +3,10 @@ package dbutil
 import (
 	"bytes"
 	"context"
+	"io/ioutil"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/genjidb/genji"
 	"github.com/genjidb/genji/document"
@@ -40,3 +42,55 @@ func TestExecSQL(t *testing.T) {
 	require.Equal(t, 1, res.A)
 	require.Equal(t, 2, res.B)
 }
+
+func TestExecSQLWithOptions(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE test;
+		INSERT INTO test (a) VALUES (1), (2), (3);
+	`)
+	require.NoError(t, err)
+
+	t.Run("ReadOnly", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			query string
+			fails bool
+		}{
+			{"Select", "SELECT * FROM test", false},
+			{"Read-only transaction", "BEGIN READ ONLY; SELECT * FROM test; ROLLBACK", false},
+			{"Insert", "INSERT INTO test (a) VALUES (4)", true},
+			{"Create", "CREATE TABLE foo", true},
+			{"Writable transaction", "BEGIN; SELECT * FROM test; COMMIT", true},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				err := ExecSQLWithOptions(context.Background(), db, strings.NewReader(test.query), ioutil.Discard, ExecOptions{ReadOnly: true})
+				if test.fails {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+			})
+		}
+
+		d, err := db.QueryDocument("SELECT COUNT(*) FROM test")
+		require.NoError(t, err)
+		var n int
+		err = document.Scan(d, &n)
+		require.NoError(t, err)
+		require.Equal(t, 3, n)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		err := ExecSQLWithOptions(context.Background(), db, strings.NewReader("SELECT * FROM test"), ioutil.Discard, ExecOptions{Timeout: time.Nanosecond})
+		require.EqualError(t, err, "statement timed out after 1ns")
+
+		err = ExecSQLWithOptions(context.Background(), db, strings.NewReader("SELECT * FROM test"), ioutil.Discard, ExecOptions{Timeout: time.Minute})
+		require.NoError(t, err)
+	})
+}
@@ -2,9 +2,11 @@ package dbutil
 
 import (
 	"context"
+	"strings"
 
 	"github.com/genjidb/genji"
 	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/database"
 	"github.com/genjidb/genji/internal/query/statement"
 	"github.com/genjidb/genji/internal/sql/parser"
 )
@@ -28,6 +30,11 @@ func QueryTables(tx *genji.Tx, tables []string, fn func(name, query string) erro
 			return err
 		}
 
+		// Internal tables, like audit trails, are created along with the tables using them.
+		if strings.HasPrefix(name, database.InternalPrefix) {
+			return nil
+		}
+
 		return fn(name, query)
 	})
 }
@@ -0,0 +1,283 @@
+package dbutil
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/query/statement"
+	"github.com/genjidb/genji/internal/sql/parser"
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// schemaObject is a table, an index or a sequence, as described
+// by the document returned by genji.DB.Schema.
+type schemaObject struct {
+	name  string
+	table string
+	sql   string
+}
+
+// SchemaDiff compares the schemas of the from and to databases and writes in w
+// the statements required to migrate the structure of from to the one of to.
+// New fields are added using ALTER TABLE, other table changes require the table
+// to be dropped and created again.
+// Sequences cannot be dropped, changes to sequences are reported as comments.
+func SchemaDiff(from, to *genji.DB, w io.Writer) error {
+	fromSchema, err := from.Schema()
+	if err != nil {
+		return err
+	}
+	toSchema, err := to.Schema()
+	if err != nil {
+		return err
+	}
+
+	var objects [2]map[string][]schemaObject
+	for i, schema := range []document.Document{fromSchema, toSchema} {
+		objects[i] = make(map[string][]schemaObject)
+		for _, field := range []string{"tables", "indexes", "sequences"} {
+			objects[i][field], err = schemaObjects(schema, field)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	d := schemaDiff{w: w, from: objects[0], to: objects[1]}
+	return d.run()
+}
+
+func schemaObjects(schema document.Document, field string) ([]schemaObject, error) {
+	v, err := schema.GetByField(field)
+	if err != nil {
+		return nil, err
+	}
+
+	var objs []schemaObject
+	err = v.V.(document.Array).Iterate(func(i int, v document.Value) error {
+		d := v.V.(document.Document)
+
+		var obj schemaObject
+		var err error
+		obj.name, err = textField(d, "name")
+		if err != nil {
+			return err
+		}
+		obj.sql, err = textField(d, "sql")
+		if err != nil {
+			return err
+		}
+		if field == "indexes" {
+			obj.table, err = textField(d, "table")
+			if err != nil {
+				return err
+			}
+		}
+
+		objs = append(objs, obj)
+		return nil
+	})
+
+	return objs, err
+}
+
+func textField(d document.Document, field string) (string, error) {
+	v, err := d.GetByField(field)
+	if err != nil {
+		return "", err
+	}
+
+	return v.V.(string), nil
+}
+
+type schemaDiff struct {
+	w        io.Writer
+	from, to map[string][]schemaObject
+
+	// tables dropped by the migration, along with their indexes.
+	dropped map[string]bool
+}
+
+func (d *schemaDiff) run() error {
+	d.dropped = make(map[string]bool)
+
+	// compute which tables must be dropped and which ones can be altered.
+	alters := make(map[string][]string)
+	for _, t := range d.from["tables"] {
+		nt, ok := find(d.to["tables"], t.name)
+		if !ok {
+			d.dropped[t.name] = true
+			continue
+		}
+		if nt.sql == t.sql {
+			continue
+		}
+
+		fields, err := addedFields(t.sql, nt.sql)
+		if err != nil {
+			return err
+		}
+		if fields == nil {
+			d.dropped[t.name] = true
+			continue
+		}
+
+		for _, f := range fields {
+			alters[t.name] = append(alters[t.name], fmt.Sprintf("ALTER TABLE %s ADD FIELD %s", stringutil.NormalizeIdentifier(t.name, '`'), f))
+		}
+	}
+
+	// drop indexes whose definition changed or which were removed,
+	// unless their table is dropped.
+	for _, idx := range d.from["indexes"] {
+		if d.dropped[idx.table] {
+			continue
+		}
+
+		if ni, ok := find(d.to["indexes"], idx.name); !ok || ni.sql != idx.sql {
+			err := d.writeStmt("DROP INDEX %s", stringutil.NormalizeIdentifier(idx.name, '`'))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, t := range d.from["tables"] {
+		if d.dropped[t.name] {
+			err := d.writeStmt("DROP TABLE %s", stringutil.NormalizeIdentifier(t.name, '`'))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, seq := range d.from["sequences"] {
+		if _, ok := find(d.to["sequences"], seq.name); !ok {
+			err := d.writeComment("sequence %s must be dropped manually", seq.name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, seq := range d.to["sequences"] {
+		old, ok := find(d.from["sequences"], seq.name)
+		var err error
+		switch {
+		case !ok:
+			err = d.writeStmt("%s", seq.sql)
+		case old.sql != seq.sql:
+			err = d.writeComment("sequence %s must be dropped manually and created again", seq.name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, t := range d.to["tables"] {
+		if _, ok := find(d.from["tables"], t.name); !ok || d.dropped[t.name] {
+			err := d.writeStmt("%s", t.sql)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, q := range alters[t.name] {
+			err := d.writeStmt("%s", q)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, idx := range d.to["indexes"] {
+		oi, ok := find(d.from["indexes"], idx.name)
+		if !ok || oi.sql != idx.sql || d.dropped[idx.table] {
+			err := d.writeStmt("%s", idx.sql)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (d *schemaDiff) writeStmt(format string, args ...interface{}) error {
+	_, err := fmt.Fprintf(d.w, format+";\n", args...)
+	return err
+}
+
+func (d *schemaDiff) writeComment(format string, args ...interface{}) error {
+	_, err := fmt.Fprintf(d.w, "-- "+format+"\n", args...)
+	return err
+}
+
+func find(objs []schemaObject, name string) (schemaObject, bool) {
+	for _, o := range objs {
+		if o.name == name {
+			return o, true
+		}
+	}
+
+	return schemaObject{}, false
+}
+
+// addedFields returns the definition of the fields that must be added to the table
+// created by from to obtain the one created by to.
+// It returns nil if the table cannot be altered that way.
+func addedFields(from, to string) ([]string, error) {
+	var infos [2]*database.TableInfo
+	for i, q := range []string{from, to} {
+		stmt, err := parser.NewParser(strings.NewReader(q)).ParseStatement()
+		if err != nil {
+			return nil, err
+		}
+
+		ct, ok := stmt.(*statement.CreateTableStmt)
+		if !ok {
+			return nil, fmt.Errorf("expected a CREATE TABLE statement, got %q", q)
+		}
+		infos[i] = &ct.Info
+	}
+
+	if infos[0].Checksum != infos[1].Checksum || infos[0].Audit != infos[1].Audit {
+		return nil, nil
+	}
+
+	var fcs [2]database.FieldConstraints
+	for i, info := range infos {
+		for _, fc := range info.FieldConstraints {
+			if !fc.IsInferred {
+				fcs[i] = append(fcs[i], fc)
+			}
+		}
+	}
+
+	// ALTER TABLE ADD FIELD appends fields to the existing ones,
+	// which must be left unchanged.
+	if len(fcs[0]) >= len(fcs[1]) {
+		return nil, nil
+	}
+	for i := range fcs[0] {
+		if fcs[0][i].String() != fcs[1][i].String() {
+			return nil, nil
+		}
+	}
+
+	var fields []string
+	for _, fc := range fcs[1][len(fcs[0]):] {
+		if fc.IsPrimaryKey {
+			return nil, nil
+		}
+
+		fields = append(fields, fc.String())
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,70 @@
+package dbutil
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaDiff(t *testing.T) {
+	from, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer from.Close()
+
+	err = from.Exec(`
+		CREATE TABLE same(a INTEGER);
+		CREATE TABLE altered(a INTEGER);
+		CREATE TABLE recreated(a INTEGER, b TEXT);
+		CREATE TABLE removed;
+		CREATE INDEX idx_same ON same(a);
+		CREATE INDEX idx_changed ON same(a);
+		CREATE INDEX idx_recreated ON recreated(b);
+		CREATE SEQUENCE seq_removed;
+	`)
+	require.NoError(t, err)
+
+	to, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer to.Close()
+
+	err = to.Exec(`
+		CREATE TABLE same(a INTEGER);
+		CREATE TABLE altered(a INTEGER, b TEXT NOT NULL);
+		CREATE TABLE recreated(a INTEGER, b DOUBLE);
+		CREATE TABLE added;
+		CREATE INDEX idx_same ON same(a);
+		CREATE UNIQUE INDEX idx_changed ON same(a);
+		CREATE INDEX idx_recreated ON recreated(b);
+		CREATE SEQUENCE seq_added;
+	`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = SchemaDiff(from, to, &buf)
+	require.NoError(t, err)
+
+	require.Equal(t, `DROP INDEX idx_changed;
+DROP TABLE recreated;
+DROP TABLE removed;
+-- sequence seq_removed must be dropped manually
+CREATE SEQUENCE seq_added;
+CREATE TABLE added;
+ALTER TABLE altered ADD FIELD b TEXT NOT NULL;
+CREATE TABLE recreated (a INTEGER, b DOUBLE);
+CREATE UNIQUE INDEX idx_changed ON same (a);
+CREATE INDEX idx_recreated ON recreated (b);
+`, buf.String())
+
+	// applying the statements migrates the schema
+	err = ExecSQL(context.Background(), from, &buf, ioutil.Discard)
+	require.NoError(t, err)
+
+	buf.Reset()
+	err = SchemaDiff(from, to, &buf)
+	require.NoError(t, err)
+	require.Equal(t, "-- sequence seq_removed must be dropped manually\n", buf.String())
+}
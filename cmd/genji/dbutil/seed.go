@@ -0,0 +1,164 @@
+package dbutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+)
+
+var (
+	seedFirstNames = []string{"Alice", "Bob", "Carol", "David", "Emma", "Frank", "Grace", "Hugo", "Ines", "Jack", "Kenji", "Laura", "Mehdi", "Nina", "Oscar", "Paula", "Quentin", "Rosa", "Sam", "Tina"}
+	seedLastNames  = []string{"Martin", "Smith", "Garcia", "Müller", "Rossi", "Tanaka", "Dubois", "Silva", "Novak", "Kowalski", "Jensen", "Nguyen", "Cohen", "Ivanov", "Haddad"}
+	seedWords      = []string{"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do", "eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua"}
+)
+
+// SeedOptions configures the generation of documents by Seed.
+type SeedOptions struct {
+	// Number of documents to insert.
+	Count int
+	// Number of documents inserted per transaction.
+	// If zero, 1000 documents are inserted per transaction.
+	BatchSize int
+	// Seed of the random generator, for reproducible data.
+	// If zero, the current time is used.
+	Seed int64
+}
+
+// Seed inserts generated documents into the selected table, creating it if it doesn't exist.
+// Each document is the JSON object obtained by executing tmpl, a text/template
+// with the following functions:
+//   name, firstName, lastName, email: random person names and emails
+//   int MIN MAX, float MIN MAX: random numbers in [MIN, MAX]
+//   bool: random boolean
+//   pick A B...: one of the arguments
+//   word, text N: random words
+//   date: random date of the last ten years, formatted as RFC 3339
+//   uuid: random UUID
+//   seq: position of the document, starting at 1
+// Generated strings are not escaped, the template must quote them, e.g. {"name": "{{name}}"}.
+func Seed(ctx context.Context, db *genji.DB, table, tmpl string, opts SeedOptions) error {
+	if opts.Count < 0 {
+		return errors.New("count must not be negative")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1000
+	}
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+
+	var seq int
+	rnd := rand.New(rand.NewSource(opts.Seed))
+	t, err := template.New("seed").Funcs(seedFuncs(rnd, &seq)).Parse(tmpl)
+	if err != nil {
+		return err
+	}
+
+	err = db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s", table))
+	if err != nil {
+		return err
+	}
+
+	q := fmt.Sprintf("INSERT INTO %s VALUES ?", table)
+	var buf bytes.Buffer
+	for seq < opts.Count {
+		err = db.Update(func(tx *genji.Tx) error {
+			for n := 0; n < opts.BatchSize && seq < opts.Count; n++ {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+
+				seq++
+				buf.Reset()
+				err := t.Execute(&buf, nil)
+				if err != nil {
+					return err
+				}
+
+				var fb document.FieldBuffer
+				err = json.Unmarshal(buf.Bytes(), &fb)
+				if err != nil {
+					return fmt.Errorf("document %d: invalid JSON %s: %w", seq, buf.Bytes(), err)
+				}
+
+				err = tx.Exec(q, &fb)
+				if err != nil {
+					return fmt.Errorf("document %d: %w", seq, err)
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// seedFuncs returns the functions available to the templates of Seed.
+// seq points to the position of the document being generated.
+func seedFuncs(rnd *rand.Rand, seq *int) template.FuncMap {
+	pick := func(l []string) string { return l[rnd.Intn(len(l))] }
+
+	return template.FuncMap{
+		"firstName": func() string { return pick(seedFirstNames) },
+		"lastName":  func() string { return pick(seedLastNames) },
+		"name": func() string {
+			return pick(seedFirstNames) + " " + pick(seedLastNames)
+		},
+		"email": func() string {
+			return fmt.Sprintf("%s.%s%d@example.com", strings.ToLower(pick(seedFirstNames)), strings.ToLower(pick(seedLastNames)), rnd.Intn(1000))
+		},
+		"int": func(min, max int) (int, error) {
+			if max < min {
+				return 0, fmt.Errorf("int: %d is lower than %d", max, min)
+			}
+			return min + rnd.Intn(max-min+1), nil
+		},
+		"float": func(min, max float64) (float64, error) {
+			if max < min {
+				return 0, fmt.Errorf("float: %v is lower than %v", max, min)
+			}
+			return min + rnd.Float64()*(max-min), nil
+		},
+		"bool": func() bool { return rnd.Intn(2) == 1 },
+		"pick": func(values ...interface{}) (interface{}, error) {
+			if len(values) == 0 {
+				return nil, errors.New("pick: no values")
+			}
+			return values[rnd.Intn(len(values))], nil
+		},
+		"word": func() string { return pick(seedWords) },
+		"text": func(n int) string {
+			words := make([]string, n)
+			for i := range words {
+				words[i] = pick(seedWords)
+			}
+			return strings.Join(words, " ")
+		},
+		"date": func() string {
+			now := time.Now().UTC()
+			d := time.Duration(rnd.Int63n(int64(10 * 365 * 24 * time.Hour)))
+			return now.Add(-d).Truncate(time.Second).Format(time.RFC3339)
+		},
+		"uuid": func() string {
+			var b [16]byte
+			rnd.Read(b[:])
+			b[6] = (b[6] & 0x0f) | 0x40
+			b[8] = (b[8] & 0x3f) | 0x80
+			return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+		},
+		"seq": func() int { return *seq },
+	}
+}
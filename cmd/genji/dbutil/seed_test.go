@@ -0,0 +1,87 @@
+package dbutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeed(t *testing.T) {
+	tmpl := `{"id": {{seq}}, "name": "{{name}}", "email": "{{email}}", "age": {{int 18 80}}, "score": {{float 0 1}}, "active": {{bool}}, "status": "{{pick "pending" "done"}}", "bio": "{{text 3}}", "created_at": "{{date}}", "uuid": "{{uuid}}"}`
+
+	t.Run("OK", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = Seed(context.Background(), db, "users", tmpl, SeedOptions{Count: 25, BatchSize: 10, Seed: 42})
+		require.NoError(t, err)
+
+		d, err := db.QueryDocument("SELECT COUNT(*), MIN(id), MAX(id), MIN(age), MAX(age) FROM users")
+		require.NoError(t, err)
+		var count, minID, maxID, minAge, maxAge int
+		err = document.Scan(d, &count, &minID, &maxID, &minAge, &maxAge)
+		require.NoError(t, err)
+		require.Equal(t, 25, count)
+		require.Equal(t, 1, minID)
+		require.Equal(t, 25, maxID)
+		require.GreaterOrEqual(t, minAge, 18)
+		require.LessOrEqual(t, maxAge, 80)
+
+		d, err = db.QueryDocument("SELECT COUNT(*) FROM users WHERE status NOT IN ['pending', 'done']")
+		require.NoError(t, err)
+		err = document.Scan(d, &count)
+		require.NoError(t, err)
+		require.Zero(t, count)
+	})
+
+	t.Run("Reproducible", func(t *testing.T) {
+		var names []string
+		for i := 0; i < 2; i++ {
+			db, err := genji.Open(":memory:")
+			require.NoError(t, err)
+			defer db.Close()
+
+			err = Seed(context.Background(), db, "users", `{"name": "{{name}}"}`, SeedOptions{Count: 5, Seed: 7})
+			require.NoError(t, err)
+
+			d, err := db.QueryDocument("SELECT name FROM users LIMIT 1")
+			require.NoError(t, err)
+			var name string
+			err = document.Scan(d, &name)
+			require.NoError(t, err)
+			names = append(names, name)
+		}
+		require.Equal(t, names[0], names[1])
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec("CREATE TABLE strict(age INTEGER NOT NULL)")
+		require.NoError(t, err)
+
+		tests := []struct {
+			name  string
+			table string
+			tmpl  string
+		}{
+			{"Invalid template", "users", `{"a": {{int 1}`},
+			{"Invalid JSON", "users", `{"a": {{name}}}`},
+			{"Invalid range", "users", `{"a": {{int 10 1}}}`},
+			{"Constraint", "strict", `{"age": "{{name}}"}`},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				err := Seed(context.Background(), db, test.table, test.tmpl, SeedOptions{Count: 1})
+				require.Error(t, err)
+			})
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package dbutil
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	errs "github.com/genjidb/genji/errors"
+	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/query/statement"
+	"github.com/genjidb/genji/internal/sql/parser"
+	"go.uber.org/multierr"
+)
+
+// tableExportHeader is the first line of every table export.
+const tableExportHeader = "-- genji table export: "
+
+// ExportTable writes the given table as SQL queries in the given writer, to be imported
+// in another database with ImportTable.
+// The export contains the schema of the table, its indexes and documents, and the state of
+// the sequences owned by the table, so that they resume where they were in the original database.
+func ExportTable(ctx context.Context, db *genji.DB, w io.Writer, tableName string) error {
+	tx, err := db.Begin(false)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = fmt.Fprintf(w, "%s%s\nBEGIN TRANSACTION;\n", tableExportHeader, tableName); err != nil {
+		return err
+	}
+
+	found := false
+	err = QueryTables(tx, []string{tableName}, func(name, query string) error {
+		found = true
+
+		if err := dumpTable(tx, w, query, name); err != nil {
+			return err
+		}
+
+		return dumpSequencesState(tx, w, name)
+	})
+	if err == nil && !found {
+		err = fmt.Errorf("table %q not found", tableName)
+	}
+	if err != nil {
+		_, er := fmt.Fprintln(w, "ROLLBACK;")
+		return multierr.Append(err, er)
+	}
+
+	_, err = fmt.Fprintln(w, "COMMIT;")
+	return err
+}
+
+// dumpSequencesState displays ALTER SEQUENCE statements restoring the state of the sequences
+// owned by the given table. Sequences that were never used are skipped.
+func dumpSequencesState(tx *genji.Tx, w io.Writer, tableName string) error {
+	res, err := tx.Query("SELECT name, sql FROM __genji_catalog WHERE type = 'sequence' AND owner.table_name = ?", tableName)
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+
+	return res.Iterate(func(d document.Document) error {
+		var name, query string
+		if err := document.Scan(d, &name, &query); err != nil {
+			return err
+		}
+
+		stmt, err := parser.ParseQuery(query)
+		if err != nil {
+			return err
+		}
+		info := stmt.Statements[0].(*statement.CreateSequenceStmt).Info
+
+		// the stored lease is greater than or equal to the current value,
+		// restarting after it never reuses a value
+		ld, err := tx.QueryDocument(fmt.Sprintf("SELECT seq FROM %s WHERE name = ? AND seq IS NOT NULL", database.SequenceTableName), name)
+		if err != nil {
+			if err == errs.ErrDocumentNotFound {
+				return nil
+			}
+			return err
+		}
+
+		var lease int64
+		if err := document.Scan(ld, &lease); err != nil {
+			return err
+		}
+
+		next := lease + info.IncrementBy
+		if next < info.Min || next > info.Max {
+			if !info.Cycle {
+				return nil
+			}
+			next = info.Min
+			if info.IncrementBy < 0 {
+				next = info.Max
+			}
+		}
+
+		_, err = fmt.Fprintf(w, "ALTER SEQUENCE %s RESTART WITH %d;\n", name, next)
+		return err
+	})
+}
+
+// ImportTable executes a table export created by ExportTable.
+// The table must not exist in the database.
+func ImportTable(ctx context.Context, db *genji.DB, r io.Reader, w io.Writer) error {
+	br := bufio.NewReader(r)
+
+	header, err := br.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if !strings.HasPrefix(header, tableExportHeader) {
+		return errors.New("invalid table export: missing header")
+	}
+
+	return ExecSQL(ctx, db, br, w)
+}
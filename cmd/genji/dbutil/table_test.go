@@ -0,0 +1,71 @@
+package dbutil
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImportTable(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE foo (a INTEGER NOT NULL, b TEXT);
+		CREATE UNIQUE INDEX idx_foo_a ON foo (a);
+		CREATE TABLE bar;
+		INSERT INTO foo (a, b) VALUES (1, 'a'), (2, 'b'), (3, 'c');
+		DELETE FROM foo WHERE a = 3;
+		INSERT INTO bar (a) VALUES (1);
+	`)
+	require.NoError(t, err)
+
+	var export bytes.Buffer
+	err = ExportTable(context.Background(), db, &export, "foo")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(export.String(), "-- genji table export: foo\n"))
+	require.Contains(t, export.String(), "ALTER SEQUENCE foo_seq RESTART WITH ")
+	require.NotContains(t, export.String(), "bar")
+
+	err = ExportTable(context.Background(), db, ioutil.Discard, "unknown")
+	require.Error(t, err)
+
+	restored, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer restored.Close()
+
+	err = ImportTable(context.Background(), restored, bytes.NewReader(export.Bytes()), ioutil.Discard)
+	require.NoError(t, err)
+
+	var want, got bytes.Buffer
+	err = Dump(context.Background(), db, &want, "foo")
+	require.NoError(t, err)
+	err = Dump(context.Background(), restored, &got, "foo")
+	require.NoError(t, err)
+	require.Equal(t, want.String(), got.String())
+
+	// the docid sequence resumes after the values used in the original database
+	err = restored.Exec("INSERT INTO foo (a) VALUES (4)")
+	require.NoError(t, err)
+	d, err := restored.QueryDocument("SELECT pk() AS k FROM foo WHERE a = 4")
+	require.NoError(t, err)
+	var k int64
+	err = document.Scan(d, &k)
+	require.NoError(t, err)
+	require.Greater(t, k, int64(3))
+
+	// importing twice fails as the table already exists
+	err = ImportTable(context.Background(), restored, bytes.NewReader(export.Bytes()), ioutil.Discard)
+	require.Error(t, err)
+
+	// only table exports can be imported
+	err = ImportTable(context.Background(), restored, strings.NewReader("CREATE TABLE baz;"), ioutil.Discard)
+	require.Error(t, err)
+}
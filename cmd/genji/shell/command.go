@@ -9,6 +9,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/genjidb/genji"
 	"github.com/genjidb/genji/cmd/genji/dbutil"
@@ -46,6 +47,11 @@ var commands = []command{
 		DisplayName: ".tables",
 		Description: "List names of tables.",
 	},
+	{
+		Name:        ".views",
+		DisplayName: ".views",
+		Description: "List names of views.",
+	},
 	{
 		Name:        ".indexes",
 		Options:     "[table_name]",
@@ -76,6 +82,12 @@ var commands = []command{
 		DisplayName: ".import",
 		Description: "Import data from a file. Only supported type is 'csv'",
 	},
+	{
+		Name:        ".watch",
+		Options:     "INTERVAL query",
+		DisplayName: ".watch",
+		Description: "Run a query every INTERVAL (e.g. 2s) and display its result when it changes, until interrupted.",
+	},
 }
 
 func getUsage(cmdName string) string {
@@ -119,6 +131,25 @@ func runTablesCmd(db *genji.DB, w io.Writer) error {
 	})
 }
 
+// runViewsCmd displays all views.
+func runViewsCmd(db *genji.DB, w io.Writer) error {
+	res, err := db.Query("SELECT name FROM __genji_catalog WHERE type = 'view'")
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+
+	return res.Iterate(func(d document.Document) error {
+		var viewName string
+		err = document.Scan(d, &viewName)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, viewName)
+		return err
+	})
+}
+
 // runIndexesCmd displays a list of indexes. If table is non-empty, it only
 // display that table's indexes. If not, it displays all indexes.
 func runIndexesCmd(db *genji.DB, tableName string, w io.Writer) error {
@@ -203,7 +234,8 @@ func runImportCmd(ctx context.Context, db *genji.DB, fileType, path, table strin
 		return err
 	}
 
-	for {
+	// the headers are on the first line
+	for line := 2; ; line++ {
 		columns, err := r.Read()
 		if err == io.EOF {
 			break
@@ -211,11 +243,59 @@ func runImportCmd(ctx context.Context, db *genji.DB, fileType, path, table strin
 		if err != nil {
 			return err
 		}
-		err = tx.Exec("INSERT INTO "+table+" VALUES ?", document.NewFromCSV(headers, columns))
+
+		d := document.NewFromCSV(headers, columns)
+
+		// report every constraint violated by the line before inserting it
+		_, err = db.ValidateDocumentAll(table, d)
+		if err == nil {
+			err = tx.Exec("INSERT INTO "+table+" VALUES ?", d)
+		}
 		if err != nil {
-			return err
+			return fmt.Errorf("line %d: %w", line, err)
 		}
 	}
 
 	return tx.Commit()
 }
+
+// runWatchCmd runs the query every interval and writes its result to w
+// the first time and every time it changes, until ctx is canceled
+// or the query returns an error.
+func runWatchCmd(ctx context.Context, db *genji.DB, interval time.Duration, q string, w io.Writer, opts dbutil.ExecOptions) error {
+	if interval <= 0 {
+		return errors.New("INTERVAL must be positive")
+	}
+
+	_, err := fmt.Fprintf(w, "Every %s: %s\n", interval, q)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var prev []byte
+	var buf bytes.Buffer
+	for {
+		buf.Reset()
+		err = dbutil.ExecSQLWithOptions(ctx, db, strings.NewReader(q), &buf, opts)
+		if err != nil {
+			return err
+		}
+
+		if prev == nil || !bytes.Equal(prev, buf.Bytes()) {
+			_, err = fmt.Fprintf(w, "\n%s\n%s", time.Now().Format("15:04:05"), buf.Bytes())
+			if err != nil {
+				return err
+			}
+			prev = append(prev[:0], buf.Bytes()...)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
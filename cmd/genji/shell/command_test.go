@@ -3,16 +3,21 @@ package shell
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/dgraph-io/badger/v3"
 	"github.com/genjidb/genji"
 	"github.com/genjidb/genji/cmd/genji/dbutil"
 	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/engine/badgerengine"
+	errs "github.com/genjidb/genji/errors"
+	"github.com/genjidb/genji/internal/testutil"
 	"github.com/stretchr/testify/require"
 )
 
@@ -54,6 +59,25 @@ func TestRunTablesCmd(t *testing.T) {
 	}
 }
 
+func TestRunViewsCmd(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE foo;
+		CREATE VIEW v2 AS SELECT * FROM foo;
+		CREATE VIEW v1 AS SELECT a FROM v2;
+	`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = runViewsCmd(db, &buf)
+	require.NoError(t, err)
+
+	require.Equal(t, "v1\nv2\n", buf.String())
+}
+
 func TestIndexesCmd(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -165,3 +189,102 @@ func TestSaveCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestImportCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "genji")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec("CREATE TABLE test (a INTEGER, b DOUBLE NOT NULL, c TEXT)")
+	require.NoError(t, err)
+
+	t.Run("OK", func(t *testing.T) {
+		path := filepath.Join(dir, "ok.csv")
+		err := ioutil.WriteFile(path, []byte("a,b,c\n1,1.5,foo\n2,3,bar\n"), 0644)
+		require.NoError(t, err)
+
+		err = runImportCmd(context.Background(), db, "csv", path, "test")
+		require.NoError(t, err)
+
+		d, err := db.QueryDocument("SELECT COUNT(*) AS n FROM test")
+		require.NoError(t, err)
+		testutil.RequireDocJSONEq(t, d, `{"n": 2}`)
+	})
+
+	t.Run("Violations", func(t *testing.T) {
+		path := filepath.Join(dir, "invalid.csv")
+		err := ioutil.WriteFile(path, []byte("a,c\n3,baz\nx,qux\n"), 0644)
+		require.NoError(t, err)
+
+		err = runImportCmd(context.Background(), db, "csv", path, "test")
+		var verr errs.ValidationError
+		require.True(t, errors.As(err, &verr))
+		require.Len(t, verr.Violations, 1)
+
+		// the first line is missing b, the error is reported for it only
+		require.Contains(t, err.Error(), "line 2:")
+		require.Equal(t, "NOT NULL", verr.Violations[0].Constraint)
+
+		path = filepath.Join(dir, "invalid2.csv")
+		err = ioutil.WriteFile(path, []byte("a,b\n3,1\nx,y\n"), 0644)
+		require.NoError(t, err)
+
+		err = runImportCmd(context.Background(), db, "csv", path, "test")
+		require.True(t, errors.As(err, &verr))
+		require.Contains(t, err.Error(), "line 3:")
+		require.Len(t, verr.Violations, 2)
+		require.Equal(t, document.NewPath("a"), verr.Violations[0].Path)
+		require.Equal(t, document.NewPath("b"), verr.Violations[1].Path)
+
+		// nothing was imported
+		d, err := db.QueryDocument("SELECT COUNT(*) AS n FROM test")
+		require.NoError(t, err)
+		testutil.RequireDocJSONEq(t, d, `{"n": 2}`)
+	})
+}
+
+func TestWatchCommand(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec("CREATE TABLE jobs(status TEXT); INSERT INTO jobs (status) VALUES ('pending')")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var buf bytes.Buffer
+	done := make(chan error)
+	go func() {
+		done <- runWatchCmd(ctx, db, 5*time.Millisecond, "SELECT COUNT(*) AS n FROM jobs WHERE status = 'pending'", &buf, dbutil.ExecOptions{})
+	}()
+
+	// the result is displayed again only when it changes
+	time.Sleep(50 * time.Millisecond)
+	err = db.Exec("INSERT INTO jobs (status) VALUES ('pending')")
+	require.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	require.Equal(t, context.Canceled, <-done)
+	out := buf.String()
+	require.True(t, strings.HasPrefix(out, "Every 5ms: SELECT COUNT(*) AS n FROM jobs WHERE status = 'pending'\n"), out)
+	require.Equal(t, 1, strings.Count(out, `"n": 1`), out)
+	require.Equal(t, 1, strings.Count(out, `"n": 2`), out)
+
+	t.Run("Errors", func(t *testing.T) {
+		err := runWatchCmd(context.Background(), db, time.Second, "SELECT * FROM unknown", ioutil.Discard, dbutil.ExecOptions{})
+		require.Error(t, err)
+
+		err = runWatchCmd(context.Background(), db, 0, "SELECT 1", ioutil.Discard, dbutil.ExecOptions{})
+		require.Error(t, err)
+
+		err = runWatchCmd(context.Background(), db, time.Second, "INSERT INTO jobs (status) VALUES ('done')", ioutil.Discard, dbutil.ExecOptions{ReadOnly: true})
+		require.Error(t, err)
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/agnivade/levenshtein"
 	"github.com/c-bata/go-prompt"
@@ -44,6 +45,13 @@ type Shell struct {
 	livePrefix string
 	multiLine  bool
 
+	// set to true when a transaction was started with BEGIN
+	// and is not committed or rolled back yet.
+	inTx bool
+	// set to true once the user has been warned that exiting
+	// will roll back the uncommitted changes of the transaction.
+	exitWarned bool
+
 	history []string
 
 	cmdSuggestions []prompt.Suggest
@@ -59,7 +67,7 @@ type Shell struct {
 // Options of the shell.
 type Options struct {
 	// Name of the engine to use when opening the database.
-	// Must be either "memory", "bolt" or "badger"
+	// Must be either "memory", "bolt", "badger" or "file"
 	// If empty, "memory" will be used, unless DBPath is non empty.
 	// In that case "bolt" will be used.
 	Engine string
@@ -68,6 +76,12 @@ type Options struct {
 
 	// Badger only:
 	EncryptionKey string
+	LowMemory     bool
+
+	// If true, statements writing to the database are rejected.
+	ReadOnly bool
+	// Maximum duration of each statement. Zero means no limit.
+	Timeout time.Duration
 }
 
 func (o *Options) validate() error {
@@ -80,7 +94,7 @@ func (o *Options) validate() error {
 	}
 
 	switch o.Engine {
-	case "bolt", "badger", "memory":
+	case "bolt", "badger", "file", "memory":
 	default:
 		return fmt.Errorf("unsupported engine %q", o.Engine)
 	}
@@ -103,7 +117,7 @@ func Run(ctx context.Context, opts *Options) error {
 
 	sh.opts = opts
 
-	db, err := dbutil.OpenDB(ctx, sh.opts.DBPath, sh.opts.Engine, dbutil.DBOptions{EncryptionKey: opts.EncryptionKey})
+	db, err := dbutil.OpenDB(ctx, sh.opts.DBPath, sh.opts.Engine, dbutil.DBOptions{EncryptionKey: opts.EncryptionKey, LowMemory: opts.LowMemory})
 	if err != nil {
 		return err
 	}
@@ -287,7 +301,11 @@ func (sh *Shell) runPrompt(ctx context.Context, execCh chan (string)) error {
 		// However, it returns if the line is empty and sets lastKeyStroke to prompt.ControlD.
 		// if so, we must stop the program.
 		if lastKeyStroke == prompt.ControlD {
-			return errExitCtrlD
+			if sh.confirmExit() {
+				return errExitCtrlD
+			}
+
+			continue
 		}
 
 		input = strings.TrimSpace(input)
@@ -451,11 +469,21 @@ func (sh *Shell) runCommand(ctx context.Context, in string) error {
 		}
 
 		return runTablesCmd(sh.db, os.Stdout)
+	case ".views":
+		if len(cmd) > 1 {
+			return fmt.Errorf(getUsage(".views"))
+		}
+
+		return runViewsCmd(sh.db, os.Stdout)
 	case ".exit", "exit":
 		if len(cmd) > 1 {
 			return fmt.Errorf(getUsage(".exit"))
 		}
 
+		if !sh.confirmExit() {
+			return nil
+		}
+
 		return errExitCommand
 	case ".indexes":
 		if len(cmd) > 2 {
@@ -490,14 +518,48 @@ func (sh *Shell) runCommand(ctx context.Context, in string) error {
 			return fmt.Errorf(getUsage(".import"))
 		}
 
+		if sh.opts != nil && sh.opts.ReadOnly {
+			return errors.New("cannot import in read-only mode")
+		}
+
 		return runImportCmd(ctx, sh.db, cmd[1], cmd[2], cmd[3])
+	case ".watch":
+		if len(cmd) < 3 {
+			return fmt.Errorf(getUsage(".watch"))
+		}
+
+		interval, err := time.ParseDuration(cmd[1])
+		if err != nil {
+			return fmt.Errorf(getUsage(".watch"))
+		}
+
+		// the query is the rest of the input, as typed
+		q := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(strings.TrimPrefix(in, cmd[0])), cmd[1]))
+
+		var execOpts dbutil.ExecOptions
+		if sh.opts != nil {
+			execOpts = dbutil.ExecOptions{ReadOnly: sh.opts.ReadOnly, Timeout: sh.opts.Timeout}
+		}
+
+		// watching stops when the execution is interrupted
+		return runWatchCmd(ctx, sh.db, interval, q, os.Stdout, execOpts)
 	default:
 		return displaySuggestions(in)
 	}
 }
 
 func (sh *Shell) runQuery(ctx context.Context, q string) error {
-	err := dbutil.ExecSQL(ctx, sh.db, strings.NewReader(q), os.Stdout)
+	var execOpts dbutil.ExecOptions
+	if sh.opts != nil {
+		execOpts = dbutil.ExecOptions{ReadOnly: sh.opts.ReadOnly, Timeout: sh.opts.Timeout}
+	}
+
+	err := dbutil.ExecSQLWithOptions(ctx, sh.db, strings.NewReader(q), os.Stdout, execOpts)
+
+	// the query may have started or ended a transaction
+	sh.inTx, _ = sh.txStatus()
+	sh.exitWarned = false
+
 	if err == context.Canceled {
 		return errors.New("interrupted")
 	}
@@ -505,7 +567,53 @@ func (sh *Shell) runQuery(ctx context.Context, q string) error {
 	return err
 }
 
+// txStatus returns whether a transaction was started with BEGIN
+// and the number of documents it wrote that are not committed yet.
+func (sh *Shell) txStatus() (bool, int64) {
+	d, err := sh.db.QueryDocument("SHOW TRANSACTION STATUS")
+	if err != nil {
+		return false, 0
+	}
+
+	explicit, err := d.GetByField("explicit")
+	if err != nil || explicit.Type != document.BoolValue || !explicit.V.(bool) {
+		return false, 0
+	}
+
+	pendingWrites, err := d.GetByField("pending_writes")
+	if err != nil || pendingWrites.Type != document.IntegerValue {
+		return true, 0
+	}
+
+	return true, pendingWrites.V.(int64)
+}
+
+// confirmExit returns false and warns the user the first time they try to exit
+// while the current transaction has uncommitted changes.
+// Exiting again rolls the transaction back.
+func (sh *Shell) confirmExit() bool {
+	if sh.exitWarned {
+		return true
+	}
+
+	inTx, pendingWrites := sh.txStatus()
+	if !inTx || pendingWrites == 0 {
+		return true
+	}
+
+	sh.exitWarned = true
+	fmt.Fprintf(os.Stderr, "Warning: the current transaction has %d uncommitted changes.\n", pendingWrites)
+	fmt.Fprintln(os.Stderr, "Run COMMIT to save them or exit again to roll them back.")
+	return false
+}
+
+// changelivePrefix displays "... " while typing a multi line query
+// and "genji*> " while a transaction is open.
 func (sh *Shell) changelivePrefix() (string, bool) {
+	if !sh.multiLine && sh.inTx {
+		return "genji*> ", true
+	}
+
 	return sh.livePrefix, sh.multiLine
 }
 
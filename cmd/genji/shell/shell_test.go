@@ -0,0 +1,68 @@
+package shell
+
+import (
+	"context"
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShellTransaction(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	sh := Shell{db: db}
+
+	err = sh.executeInput(ctx, "CREATE TABLE foo;")
+	require.NoError(t, err)
+	_, ok := sh.changelivePrefix()
+	require.False(t, ok)
+
+	// the transaction stays open across prompts
+	err = sh.executeInput(ctx, "BEGIN;")
+	require.NoError(t, err)
+	prefix, ok := sh.changelivePrefix()
+	require.True(t, ok)
+	require.Equal(t, "genji*> ", prefix)
+
+	err = sh.executeInput(ctx, "INSERT INTO foo (a)")
+	require.NoError(t, err)
+	prefix, ok = sh.changelivePrefix()
+	require.True(t, ok)
+	require.Equal(t, "... ", prefix)
+
+	err = sh.executeInput(ctx, "VALUES (1);")
+	require.NoError(t, err)
+	inTx, pendingWrites := sh.txStatus()
+	require.True(t, inTx)
+	require.EqualValues(t, 1, pendingWrites)
+
+	// exiting with uncommitted changes requires a confirmation
+	err = sh.executeInput(ctx, ".exit")
+	require.NoError(t, err)
+
+	// running a query resets the confirmation
+	err = sh.executeInput(ctx, "SELECT COUNT(*) FROM foo;")
+	require.NoError(t, err)
+	err = sh.executeInput(ctx, ".exit")
+	require.NoError(t, err)
+	err = sh.executeInput(ctx, ".exit")
+	require.Equal(t, errExitCommand, err)
+
+	err = sh.executeInput(ctx, "COMMIT;")
+	require.NoError(t, err)
+	_, ok = sh.changelivePrefix()
+	require.False(t, ok)
+
+	err = sh.executeInput(ctx, ".exit")
+	require.Equal(t, errExitCommand, err)
+
+	d, err := db.QueryDocument("SELECT COUNT(*) FROM foo")
+	require.NoError(t, err)
+	v, err := d.GetByField("COUNT(*)")
+	require.NoError(t, err)
+	require.EqualValues(t, 1, v.V)
+}
@@ -2,6 +2,8 @@ package genji
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/engine"
@@ -12,7 +14,6 @@ import (
 	"github.com/genjidb/genji/internal/query/statement"
 	"github.com/genjidb/genji/internal/sql/parser"
 	"github.com/genjidb/genji/internal/stream"
-	"github.com/genjidb/genji/internal/stringutil"
 )
 
 // DB represents a collection of tables stored in the underlying engine.
@@ -39,6 +40,49 @@ func (db DB) WithContext(ctx context.Context) *DB {
 	return &db
 }
 
+// SetStrict enables or disables the strict mode, which makes queries behave closer to standard SQL:
+// comparing values of incompatible types, like an integer and a text, returns an error
+// instead of evaluating to false, and NULL follows three-valued logic in every operator,
+// e.g. NOT NULL and NULL AND true evaluate to NULL instead of true and false.
+// Lookups using indexes and primary keys follow the same rules.
+// It applies to the transactions started after this call.
+func (db *DB) SetStrict(strict bool) {
+	db.db.SetStrict(strict)
+}
+
+// SetSynchronous sets how often the commits are synced to disk, one of engine.SyncOff,
+// engine.SyncNormal and engine.SyncFull. Turning it off speeds up bulk loads, at the risk
+// of losing committed changes if the operating system crashes; setting it back to
+// engine.SyncFull afterwards makes every following commit durable again.
+// It is equivalent to SET synchronous = mode and applies to the transactions started after this call.
+func (db *DB) SetSynchronous(mode string) error {
+	return db.db.SetSynchronous(mode)
+}
+
+// RunGC reclaims the space used on disk by overwritten or deleted values, e.g. after
+// a large delete, by running the garbage collection of the engine. It does nothing if
+// the engine doesn't need one. Engines can also schedule it automatically,
+// see badgerengine.Engine.ScheduleGC and logengine.Options.CompactionInterval.
+func (db *DB) RunGC() error {
+	return db.db.RunGC()
+}
+
+// GCStats returns the space used by the engine and the space a garbage collection
+// could reclaim, if the engine can measure it.
+func (db *DB) GCStats() (engine.GCStats, error) {
+	return db.db.GCStats()
+}
+
+// SetEngine registers a secondary engine under the given name. The tables created
+// WITH (engine = 'name'), and their indexes, are stored in it instead of the engine
+// of the database, e.g. to keep hot tables in memory and the others on disk.
+// Transactions using several engines are committed with a two-phase commit.
+// The engine must be registered every time the database is opened, before accessing these tables.
+// If ng is nil, the engine is unregistered. Closing it is the responsibility of the caller.
+func (db *DB) SetEngine(name string, ng engine.Engine) {
+	db.db.SetEngine(name, ng)
+}
+
 // Close the database.
 func (db *DB) Close() error {
 	return db.db.Close()
@@ -87,6 +131,29 @@ func (db *DB) Update(fn func(tx *Tx) error) error {
 	return tx.Commit()
 }
 
+// maxTxRetries is the number of times RunInTx runs a transaction
+// that conflicts with other transactions before giving up.
+const maxTxRetries = 10
+
+// RunInTx starts a read-write transaction, runs fn and automatically commits it.
+// If the commit fails because the transaction conflicts with another one, fn is run again
+// in a new transaction, a limited number of times.
+// Because of this, fn must not have side effects other than the changes made to the database
+// through the transaction.
+// If every attempt conflicts, engine.ErrConflict is returned.
+func (db *DB) RunInTx(fn func(tx *Tx) error) error {
+	var err error
+
+	for i := 0; i < maxTxRetries; i++ {
+		err = db.Update(fn)
+		if !errors.Is(err, engine.ErrConflict) {
+			return err
+		}
+	}
+
+	return err
+}
+
 // Query the database and return the result.
 // The returned result must always be closed after usage.
 func (db *DB) Query(q string, args ...interface{}) (*Result, error) {
@@ -119,12 +186,80 @@ func (db *DB) Exec(q string, args ...interface{}) error {
 	return stmt.Exec(args...)
 }
 
+// ExecResult is the result of a statement run by ExecMulti or ExecStatements.
+type ExecResult struct {
+	// Number of documents output by the statement, for all the batches of parameters.
+	// INSERT statements output the documents they insert.
+	Count int64
+}
+
+// ExecMulti parses and prepares the statements of q once, then runs them in order
+// for each batch of parameters, in a single read-write transaction.
+// Every statement of a batch receives the same parameters: positional parameters
+// are numbered across the whole query. If no batch is given, the statements
+// are run once, without parameters.
+// It returns one result per statement. If any statement fails, the transaction
+// is rolled back.
+func (db *DB) ExecMulti(q string, batches ...[]interface{}) ([]ExecResult, error) {
+	stmt, err := db.Prepare(q)
+	if err != nil {
+		return nil, err
+	}
+
+	// run every statement of the query separately, to get their individual results.
+	stmts := make([]*Statement, len(stmt.pq.Statements))
+	for i, st := range stmt.pq.Statements {
+		pq := query.New(st)
+		pq.SQL = q
+		stmts[i] = &Statement{pq: pq, db: db}
+	}
+
+	return db.ExecStatements(stmts, batches...)
+}
+
+// ExecStatements runs the prepared statements in order for each batch of parameters,
+// in a single read-write transaction, reusing their execution plans.
+// Every statement of a batch receives the same parameters. If no batch is given,
+// the statements are run once, without parameters.
+// It returns one result per statement. If any statement fails, the transaction
+// is rolled back.
+func (db *DB) ExecStatements(stmts []*Statement, batches ...[]interface{}) ([]ExecResult, error) {
+	if len(batches) == 0 {
+		batches = [][]interface{}{nil}
+	}
+
+	results := make([]ExecResult, len(stmts))
+
+	err := db.Update(func(tx *Tx) error {
+		for _, args := range batches {
+			params := argsToParams(args)
+
+			for i, stmt := range stmts {
+				n, err := stmt.execIn(tx, params)
+				if err != nil {
+					return err
+				}
+
+				results[i].Count += n
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
 // Prepare parses the query and returns a prepared statement.
 func (db *DB) Prepare(q string) (*Statement, error) {
 	pq, err := parser.ParseQuery(q)
 	if err != nil {
 		return nil, err
 	}
+	pq.SQL = q
 
 	err = pq.Prepare(newQueryContext(db, nil, nil))
 	if err != nil {
@@ -137,6 +272,35 @@ func (db *DB) Prepare(q string) (*Statement, error) {
 	}, nil
 }
 
+// ValidateDocument ensures d satisfies the field constraints of the given table
+// and returns the document as it would be stored, with its values converted to
+// the types of the constraints. It doesn't start a transaction and can be used to
+// validate user input before writing it.
+// Fields having a default value are allowed to be missing, as well as fields that
+// are not checked by constraints, i.e. the uniqueness of a value.
+// Violations are returned as errors.ConstraintViolationError.
+func (db *DB) ValidateDocument(tableName string, d document.Document) (document.Document, error) {
+	ti, err := db.db.Catalog.GetTableInfo(tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return database.ValidateDocument(ti, d)
+}
+
+// ValidateDocumentAll is like ValidateDocument, but it doesn't stop at the first
+// constraint violation: if d violates one or more constraints, it returns an
+// errors.ValidationError reporting all of them. It is meant to validate documents
+// before importing them, or to report every invalid field of user input at once.
+func (db *DB) ValidateDocumentAll(tableName string, d document.Document) (document.Document, error) {
+	ti, err := db.db.Catalog.GetTableInfo(tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return database.ValidateDocumentAll(ti, d)
+}
+
 // Tx represents a database transaction. It provides methods for managing the
 // collection of tables and the transaction itself.
 // Tx is either read-only or read/write. Read-only can be used to read tables
@@ -157,6 +321,35 @@ func (tx *Tx) Commit() error {
 	return tx.tx.Commit()
 }
 
+// TxStatus describes the state of a transaction.
+type TxStatus struct {
+	Writable bool
+	// Isolation level guaranteed by the database, one of the engine.Isolation* constants.
+	// Writable transactions never run concurrently with other transactions,
+	// which makes transactions serializable, whatever the engine.
+	Isolation string
+	// Isolation level of the transactions of the engine.
+	EngineIsolation string
+	StartedAt       time.Time
+	// Number of documents inserted, replaced or deleted by the transaction
+	// that are not committed yet.
+	PendingWrites int64
+}
+
+// Status returns the state of the transaction, which helps debugging long transactions.
+// The SHOW TRANSACTION STATUS statement returns the same information.
+func (tx *Tx) Status() TxStatus {
+	s := tx.tx.Status()
+
+	return TxStatus{
+		Writable:        s.Writable,
+		Isolation:       s.Isolation,
+		EngineIsolation: s.EngineIsolation,
+		StartedAt:       s.StartedAt,
+		PendingWrites:   s.PendingWrites,
+	}
+}
+
 // Query the database withing the transaction and returns the result.
 // Closing the returned result after usage is not mandatory.
 func (tx *Tx) Query(q string, args ...interface{}) (*Result, error) {
@@ -195,6 +388,7 @@ func (tx *Tx) Prepare(q string) (*Statement, error) {
 	if err != nil {
 		return nil, err
 	}
+	pq.SQL = q
 
 	err = pq.Prepare(newQueryContext(tx.db, tx, nil))
 	if err != nil {
@@ -208,6 +402,72 @@ func (tx *Tx) Prepare(q string) (*Statement, error) {
 	}, nil
 }
 
+// OpenBlob opens the blob stored at the given path of the document of the table
+// whose primary key is key, to read or write it in a streaming fashion.
+// The path is written as in SQL, e.g. "a.b[2]".
+// The blob must be closed before the end of the transaction to save the modifications, if any.
+func (tx *Tx) OpenBlob(tableName string, key interface{}, path string) (*Blob, error) {
+	p, err := document.ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	k, err := document.NewValue(key)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := tx.db.db.Catalog.GetTable(tx.tx, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	rawKey, err := t.EncodeValue(k)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := t.OpenBlob(rawKey, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Blob{b: b}, nil
+}
+
+// A Blob gives streaming access to a blob field of a document.
+// It implements the io.ReadWriteSeeker and io.Closer interfaces.
+// Reads don't copy the stored blob and writes only buffer the modified chunks,
+// until Close writes them to the document.
+type Blob struct {
+	b *database.Blob
+}
+
+// Read implements the io.Reader interface.
+func (b *Blob) Read(p []byte) (int, error) {
+	return b.b.Read(p)
+}
+
+// Write implements the io.Writer interface. Writing past the end of the blob extends it.
+func (b *Blob) Write(p []byte) (int, error) {
+	return b.b.Write(p)
+}
+
+// Seek implements the io.Seeker interface.
+func (b *Blob) Seek(offset int64, whence int) (int64, error) {
+	return b.b.Seek(offset, whence)
+}
+
+// Size returns the current size of the blob.
+func (b *Blob) Size() int64 {
+	return b.b.Size()
+}
+
+// Close writes the modifications to the document, if any.
+func (b *Blob) Close() error {
+	return b.b.Close()
+}
+
 // Statement is a prepared statement. If Statement has been created on a Tx,
 // it will only be valid until Tx closes. If it has been created on a DB, it
 // is valid until the DB closes.
@@ -286,44 +546,80 @@ func (s *Statement) Exec(args ...interface{}) (err error) {
 	})
 }
 
+// execIn runs the statement within tx and returns the number of documents it outputs.
+func (s *Statement) execIn(tx *Tx, params []environment.Param) (n int64, err error) {
+	res, err := s.pq.Run(newQueryContext(s.db, tx, params))
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		er := res.Close()
+		if err == nil {
+			err = er
+		}
+	}()
+
+	err = res.Iterate(func(d document.Document) error {
+		n++
+		return nil
+	})
+	return n, err
+}
+
 // Result of a query.
 type Result struct {
 	result *statement.Result
+	// if set, called once the result is closed.
+	cancel func()
 }
 
 func (r *Result) Iterate(fn func(d document.Document) error) error {
 	return r.result.Iterate(fn)
 }
 
+// Fields returns the names of the fields of the documents returned by the query,
+// as listed in the projection. "*" stands for every field of the documents read by the query.
+// It returns nil if the statement doesn't return documents.
 func (r *Result) Fields() []string {
-	if r.result.Iterator == nil {
+	columns := r.Columns()
+	if columns == nil {
 		return nil
 	}
 
-	stmt, ok := r.result.Iterator.(*statement.StreamStmtIterator)
-	if !ok || stmt.Stream.Op == nil {
-		return nil
+	fields := make([]string, len(columns))
+	for i := range columns {
+		fields[i] = columns[i].Name
 	}
 
-	// Search for the ProjectOperator. If found, extract the projected expression list
-	for op := stmt.Stream.First(); op != nil; op = op.GetNext() {
-		if po, ok := op.(*stream.ProjectOperator); ok {
-			// if there are no projected expression, it's a wildcard
-			if len(po.Exprs) == 0 {
-				break
-			}
+	return fields
+}
 
-			fields := make([]string, len(po.Exprs))
-			for i := range po.Exprs {
-				fields[i] = stringutil.Sprintf("%s", po.Exprs[i])
-			}
+// A Column describes a field of the documents returned by a query.
+type Column struct {
+	// Name of the field. "*" stands for every field of the documents
+	// read by the query.
+	Name string
+	// Type of the values of the field, inferred from the projected expression
+	// and the field constraints of the table. Zero if it cannot be inferred,
+	// in which case the values can be of any type.
+	Type document.ValueType
+}
 
-			return fields
-		}
+// Columns returns the fields of the documents returned by the query and their types,
+// without iterating over the result.
+// It returns nil if the statement doesn't return documents.
+func (r *Result) Columns() []Column {
+	columns := r.result.Columns()
+	if columns == nil {
+		return nil
 	}
 
-	// the stream will output documents in a single field
-	return []string{"*"}
+	cols := make([]Column, len(columns))
+	for i := range columns {
+		cols[i] = Column(columns[i])
+	}
+
+	return cols
 }
 
 // Close the result stream.
@@ -332,6 +628,10 @@ func (r *Result) Close() (err error) {
 		return nil
 	}
 
+	if r.cancel != nil {
+		defer r.cancel()
+	}
+
 	return r.result.Close()
 }
 
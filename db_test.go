@@ -1,14 +1,20 @@
 package genji_test
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
 	"fmt"
 	"log"
 	"testing"
 
 	"github.com/genjidb/genji"
 	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/engine/memoryengine"
+	"github.com/genjidb/genji/engine/wrap"
 	errs "github.com/genjidb/genji/errors"
+	"github.com/genjidb/genji/internal/testutil"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/sync/errgroup"
 )
@@ -120,6 +126,104 @@ func TestQueryDocument(t *testing.T) {
 	})
 }
 
+func TestResultColumns(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE test(a INTEGER, b TEXT);
+		INSERT INTO test (a, b) VALUES (1, 'foo');
+	`)
+	require.NoError(t, err)
+
+	res, err := db.Query("SELECT a AS x, b, *, COUNT(*) FROM test")
+	require.NoError(t, err)
+
+	// available before iterating
+	require.Equal(t, []genji.Column{
+		{Name: "x", Type: document.IntegerValue},
+		{Name: "b", Type: document.TextValue},
+		{Name: "*"},
+		{Name: "COUNT(*)", Type: document.IntegerValue},
+	}, res.Columns())
+	require.Equal(t, []string{"x", "b", "*", "COUNT(*)"}, res.Fields())
+	require.NoError(t, res.Close())
+
+	res, err = db.Query("INSERT INTO test (a) VALUES (2)")
+	require.NoError(t, err)
+	defer res.Close()
+	require.Nil(t, res.Columns())
+}
+
+func TestStrictMode(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE test(a INTEGER PRIMARY KEY, b INTEGER);
+		CREATE INDEX idx_test_b ON test(b);
+		INSERT INTO test (a, b, c) VALUES (1, 10, 'foo'), (2, 20, 20), (3, 30, NULL);
+	`)
+	require.NoError(t, err)
+
+	query := func(t *testing.T, q string) ([]int, error) {
+		t.Helper()
+
+		res, err := db.Query(q)
+		if err != nil {
+			return nil, err
+		}
+		defer res.Close()
+
+		var ids []int
+		err = res.Iterate(func(d document.Document) error {
+			var id int
+			err := document.Scan(d, &id)
+			ids = append(ids, id)
+			return err
+		})
+		return ids, err
+	}
+
+	tests := []struct {
+		query           string
+		loose, strict   []int
+		failsWhenStrict bool
+	}{
+		{"SELECT a FROM test WHERE a = 'x'", nil, nil, true},
+		{"SELECT a FROM test WHERE b = 'x'", nil, nil, true},
+		{"SELECT a FROM test WHERE c > 10", []int{2}, nil, true},
+		{"SELECT a FROM test WHERE b = 1.0 + 9", []int{1}, []int{1}, false},
+		{"SELECT a FROM test WHERE b IN [10, NULL]", []int{1}, []int{1}, false},
+		{"SELECT a FROM test WHERE b = NULL", nil, nil, false},
+		{"SELECT a FROM test WHERE c = NULL", nil, nil, false},
+		{"SELECT a FROM test WHERE NOT (c = NULL)", []int{1, 2, 3}, nil, false},
+		{"SELECT a FROM test WHERE NOT (b = 10 AND 1 = NULL)", []int{1, 2, 3}, []int{2, 3}, false},
+		{"SELECT a FROM test WHERE b = 10 OR NULL", []int{1}, []int{1}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			db.SetStrict(false)
+			ids, err := query(t, test.query)
+			require.NoError(t, err)
+			require.Equal(t, test.loose, ids)
+
+			db.SetStrict(true)
+			defer db.SetStrict(false)
+			ids, err = query(t, test.query)
+			if test.failsWhenStrict {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.strict, ids)
+		})
+	}
+}
+
 func TestPrepareThreadSafe(t *testing.T) {
 	db, err := genji.Open(":memory:")
 	require.NoError(t, err)
@@ -152,6 +256,379 @@ func TestPrepareThreadSafe(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestRunInTx(t *testing.T) {
+	faults := wrap.NewFaults()
+	db, err := genji.New(context.Background(), wrap.New(memoryengine.NewEngine(), faults.Middleware()))
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec("CREATE TABLE test")
+	require.NoError(t, err)
+
+	t.Run("retry", func(t *testing.T) {
+		faults.Inject(wrap.OpCommit, wrap.Fault{Err: engine.ErrConflict, Times: 3})
+		defer faults.Reset()
+
+		var calls int
+		err = db.RunInTx(func(tx *genji.Tx) error {
+			calls++
+			return tx.Exec("INSERT INTO test (a) VALUES (1)")
+		})
+		require.NoError(t, err)
+		require.Equal(t, 4, calls)
+
+		d, err := db.QueryDocument("SELECT COUNT(*) AS c FROM test")
+		require.NoError(t, err)
+		testutil.RequireDocJSONEq(t, d, `{"c": 1}`)
+	})
+
+	t.Run("too many conflicts", func(t *testing.T) {
+		faults.Fail(wrap.OpCommit, engine.ErrConflict)
+
+		err = db.RunInTx(func(tx *genji.Tx) error {
+			return tx.Exec("INSERT INTO test (a) VALUES (2)")
+		})
+		require.Equal(t, engine.ErrConflict, err)
+		faults.Reset()
+
+		d, err := db.QueryDocument("SELECT COUNT(*) AS c FROM test")
+		require.NoError(t, err)
+		testutil.RequireDocJSONEq(t, d, `{"c": 1}`)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		err = db.RunInTx(func(tx *genji.Tx) error {
+			return tx.Exec("INSERT INTO unknown (a) VALUES (1)")
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestTxStatus(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec("CREATE TABLE test")
+	require.NoError(t, err)
+
+	tx, err := db.Begin(true)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	err = tx.Exec("INSERT INTO test (a) VALUES (1), (2)")
+	require.NoError(t, err)
+
+	status := tx.Status()
+	require.True(t, status.Writable)
+	require.Equal(t, engine.IsolationSerializable, status.Isolation)
+	require.Equal(t, engine.IsolationNone, status.EngineIsolation)
+	require.False(t, status.StartedAt.IsZero())
+	require.EqualValues(t, 2, status.PendingWrites)
+
+	d, err := tx.QueryDocument("SHOW TRANSACTION STATUS")
+	require.NoError(t, err)
+	var res struct {
+		Writable      bool
+		Explicit      bool
+		PendingWrites int64 `genji:"pending_writes"`
+	}
+	err = document.StructScan(d, &res)
+	require.NoError(t, err)
+	require.True(t, res.Writable)
+	require.False(t, res.Explicit)
+	require.EqualValues(t, 2, res.PendingWrites)
+	require.NoError(t, tx.Rollback())
+
+	// transactions started with BEGIN are explicit
+	err = db.Exec("BEGIN READ ONLY")
+	require.NoError(t, err)
+	d, err = db.QueryDocument("SHOW TRANSACTION STATUS")
+	require.NoError(t, err)
+	err = document.StructScan(d, &res)
+	require.NoError(t, err)
+	require.False(t, res.Writable)
+	require.True(t, res.Explicit)
+	require.NoError(t, db.Exec("ROLLBACK"))
+}
+
+func TestExecMulti(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec("CREATE TABLE foo(a int UNIQUE); CREATE TABLE bar")
+	require.NoError(t, err)
+
+	t.Run("batches", func(t *testing.T) {
+		res, err := db.ExecMulti("INSERT INTO foo (a) VALUES (?); INSERT INTO bar (b) VALUES (?), (?)", []interface{}{1, "a", "b"}, []interface{}{2, "c", "d"}, []interface{}{3, "e", "f"})
+		require.NoError(t, err)
+		require.Equal(t, []genji.ExecResult{{Count: 3}, {Count: 6}}, res)
+
+		d, err := db.QueryDocument("SELECT COUNT(*) AS foo FROM foo")
+		require.NoError(t, err)
+		testutil.RequireDocJSONEq(t, d, `{"foo": 3}`)
+	})
+
+	t.Run("no batch", func(t *testing.T) {
+		res, err := db.ExecMulti("SELECT * FROM foo WHERE a > 1")
+		require.NoError(t, err)
+		require.Equal(t, []genji.ExecResult{{Count: 2}}, res)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		_, err := db.ExecMulti("INSERT INTO foo (a) VALUES (?)", []interface{}{10}, []interface{}{1})
+		require.ErrorIs(t, err, errs.ErrDuplicateDocument)
+
+		// the whole transaction must have been rolled back
+		d, err := db.QueryDocument("SELECT COUNT(*) AS foo FROM foo")
+		require.NoError(t, err)
+		testutil.RequireDocJSONEq(t, d, `{"foo": 3}`)
+	})
+
+	t.Run("statements", func(t *testing.T) {
+		stmt, err := db.Prepare("DELETE FROM foo WHERE a = ?")
+		require.NoError(t, err)
+
+		_, err = db.ExecStatements([]*genji.Statement{stmt}, []interface{}{1}, []interface{}{2})
+		require.NoError(t, err)
+
+		d, err := db.QueryDocument("SELECT COUNT(*) AS foo FROM foo")
+		require.NoError(t, err)
+		testutil.RequireDocJSONEq(t, d, `{"foo": 1}`)
+	})
+}
+
+func TestValidateDocument(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec("CREATE TABLE foo(a INTEGER NOT NULL, b TEXT NOT NULL DEFAULT 'b', c DOUBLE)")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		doc      string
+		expected string
+		fails    bool
+	}{
+		{"valid", `{"a": 1, "b": "x", "c": 2}`, `{"a": 1, "b": "x", "c": 2.0}`, false},
+		{"default", `{"a": 1.5}`, `{"a": 1}`, false},
+		{"missing", `{"b": "x"}`, ``, true},
+		{"null", `{"a": null}`, ``, true},
+		{"wrong type", `{"a": "x"}`, ``, true},
+		{"unknown table", ``, ``, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			table := "foo"
+			if test.doc == "" {
+				table, test.doc = "bar", "{}"
+			}
+
+			d, err := db.ValidateDocument(table, document.NewFromJSON([]byte(test.doc)))
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			testutil.RequireDocJSONEq(t, d, test.expected)
+		})
+	}
+}
+
+func TestValidateDocumentAll(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec("CREATE TABLE foo(a INTEGER NOT NULL, b TEXT NOT NULL DEFAULT 'b', c DOUBLE, d.e BOOL NOT NULL)")
+	require.NoError(t, err)
+
+	d, err := db.ValidateDocumentAll("foo", document.NewFromJSON([]byte(`{"a": 1.5, "c": 2, "d": {"e": true}}`)))
+	require.NoError(t, err)
+	testutil.RequireDocJSONEq(t, d, `{"a": 1, "c": 2.0, "d": {"e": true}}`)
+
+	_, err = db.ValidateDocumentAll("foo", document.NewFromJSON([]byte(`{"a": "x", "c": ["y"], "d": {}}`)))
+	require.Equal(t, errs.ValidationError{
+		TableName: "foo",
+		Violations: []errs.ConstraintViolationError{
+			{Constraint: "TYPE", TableName: "foo", Path: document.NewPath("a"), ExpectedType: document.IntegerValue, Value: document.NewTextValue("x")},
+			{Constraint: "TYPE", TableName: "foo", Path: document.NewPath("c"), ExpectedType: document.DoubleValue, Value: document.NewArrayValue(document.NewValueBuffer(document.NewTextValue("y")))},
+			{Constraint: "NOT NULL", TableName: "foo", Path: document.NewPath("d", "e")},
+		},
+	}, err)
+	require.EqualError(t, err.(errs.ValidationError).Redact(), `document violates 3 constraints of table "foo"
+	TYPE constraint error: field "a" of table "foo" must be of type "integer"
+	TYPE constraint error: field "c" of table "foo" must be of type "double"
+	NOT NULL constraint error: field "d.e" of table "foo" cannot be null`)
+
+	_, err = db.ValidateDocumentAll("bar", document.NewFieldBuffer())
+	require.True(t, errs.IsNotFoundError(err))
+}
+
+func TestConstraintViolationError(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec("CREATE TABLE foo(a INTEGER NOT NULL, b.c TEXT UNIQUE)")
+	require.NoError(t, err)
+	err = db.Exec(`INSERT INTO foo (a, b) VALUES (1, {c: "secret"})`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected errs.ConstraintViolationError
+		message  string
+	}{
+		{"not null", `INSERT INTO foo (b) VALUES ({c: "x"})`,
+			errs.ConstraintViolationError{Constraint: "NOT NULL", TableName: "foo", Path: document.NewPath("a")},
+			`NOT NULL constraint error: field "a" of table "foo" cannot be null`,
+		},
+		{"null", `INSERT INTO foo (a) VALUES (NULL)`,
+			errs.ConstraintViolationError{Constraint: "NOT NULL", TableName: "foo", Path: document.NewPath("a"), Value: document.NewNullValue()},
+			`NOT NULL constraint error: field "a" of table "foo" cannot be null`,
+		},
+		{"type", `INSERT INTO foo (a) VALUES ("x")`,
+			errs.ConstraintViolationError{Constraint: "TYPE", TableName: "foo", Path: document.NewPath("a"), ExpectedType: document.IntegerValue, Value: document.NewTextValue("x")},
+			`TYPE constraint error: field "a" of table "foo" must be of type "integer", got "x" of type "text"`,
+		},
+		{"unique", `INSERT INTO foo (a, b) VALUES (2, {c: "secret"})`,
+			errs.ConstraintViolationError{Constraint: "UNIQUE", TableName: "foo", IndexName: "foo_b.c_idx", Path: document.NewPath("b", "c"), Value: document.NewTextValue("secret")},
+			`UNIQUE constraint error: field "b.c" of table "foo" must be unique, got duplicate "secret"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := db.Exec(test.query)
+			require.True(t, errs.IsConstraintViolationError(err))
+			require.Equal(t, test.expected, err)
+			require.EqualError(t, err, test.message)
+		})
+	}
+
+	t.Run("redact", func(t *testing.T) {
+		err := db.Exec(`INSERT INTO foo (a, b) VALUES (2, {c: "secret"})`)
+		require.ErrorIs(t, err, errs.ErrDuplicateDocument)
+
+		redacted := err.(errs.ConstraintViolationError).Redact()
+		require.True(t, redacted.Redacted)
+		require.False(t, redacted.HasValue())
+		require.Equal(t, document.NewPath("b", "c"), redacted.Path)
+		require.NotContains(t, redacted.Error(), "secret")
+
+		// the original error is left untouched
+		require.Contains(t, err.Error(), "secret")
+	})
+
+	t.Run("named", func(t *testing.T) {
+		err := db.Exec(`
+			CREATE TABLE bar(id INTEGER CONSTRAINT pk_bar PRIMARY KEY, email TEXT, CONSTRAINT uq_email UNIQUE (email));
+			INSERT INTO bar (id, email) VALUES (1, "a")
+		`)
+		require.NoError(t, err)
+
+		err = db.Exec(`INSERT INTO bar (id, email) VALUES (2, "a")`)
+		require.Equal(t, errs.ConstraintViolationError{Constraint: "UNIQUE", Name: "uq_email", TableName: "bar", IndexName: "uq_email", Path: document.NewPath("email"), Value: document.NewTextValue("a")}, err)
+		require.EqualError(t, err, `UNIQUE constraint "uq_email" error: field "email" of table "bar" must be unique, got duplicate "a"`)
+
+		err = db.Exec(`INSERT INTO bar (id, email) VALUES (1, "b")`)
+		require.ErrorIs(t, err, errs.ErrDuplicateDocument)
+		require.Equal(t, errs.ConstraintViolationError{Constraint: "PRIMARY KEY", Name: "pk_bar", TableName: "bar", Path: document.NewPath("id"), Value: document.NewIntegerValue(1)}, err)
+		require.EqualError(t, err, `PRIMARY KEY constraint "pk_bar" error: field "id" of table "bar" must be unique, got duplicate 1`)
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		_, err := db.ValidateDocument("foo", document.NewFromJSON([]byte(`{"a": [1]}`)))
+		require.Equal(t, errs.ConstraintViolationError{
+			Constraint:   "TYPE",
+			TableName:    "foo",
+			Path:         document.NewPath("a"),
+			ExpectedType: document.IntegerValue,
+			Value:        document.NewArrayValue(document.NewValueBuffer(document.NewIntegerValue(1))),
+		}, err)
+	})
+}
+
+func TestClone(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE foo(a INT PRIMARY KEY, b TEXT UNIQUE);
+		CREATE INDEX idx_foo_c ON foo(c);
+		CREATE TABLE bar;
+		INSERT INTO foo(a, b, c) VALUES (1, 'a', 10), (2, 'b', 20);
+		INSERT INTO bar(a) VALUES (1), (2), (3);
+	`)
+	require.NoError(t, err)
+
+	t.Run("All tables", func(t *testing.T) {
+		clone, err := db.Clone(context.Background())
+		require.NoError(t, err)
+		defer clone.Close()
+
+		// writes to the original database must not be visible in the clone
+		err = db.Exec("INSERT INTO foo(a, b, c) VALUES (3, 'c', 30)")
+		require.NoError(t, err)
+		defer db.Exec("DELETE FROM foo WHERE a = 3")
+
+		var count int
+		d, err := clone.QueryDocument("SELECT COUNT(*) FROM foo")
+		require.NoError(t, err)
+		require.NoError(t, document.Scan(d, &count))
+		require.Equal(t, 2, count)
+
+		d, err = clone.QueryDocument("SELECT COUNT(*) FROM bar")
+		require.NoError(t, err)
+		require.NoError(t, document.Scan(d, &count))
+		require.Equal(t, 3, count)
+
+		var b string
+		d, err = clone.QueryDocument("SELECT b FROM foo WHERE c = 20")
+		require.NoError(t, err)
+		require.NoError(t, document.Scan(d, &b))
+		require.Equal(t, "b", b)
+
+		d, err = clone.QueryDocument("SELECT COUNT(*) FROM __genji_catalog WHERE type = 'index'")
+		require.NoError(t, err)
+		require.NoError(t, document.Scan(d, &count))
+		require.Equal(t, 2, count)
+
+		// the clone is read-only
+		err = clone.Exec("INSERT INTO foo(a, b) VALUES (10, 'z')")
+		require.Error(t, err)
+		err = clone.Exec("DROP TABLE bar")
+		require.Error(t, err)
+	})
+
+	t.Run("Selected tables", func(t *testing.T) {
+		clone, err := db.Clone(context.Background(), "bar")
+		require.NoError(t, err)
+		defer clone.Close()
+
+		_, err = clone.QueryDocument("SELECT * FROM foo")
+		require.Error(t, err)
+
+		d, err := clone.QueryDocument("SELECT COUNT(*) FROM bar")
+		require.NoError(t, err)
+		var count int
+		require.NoError(t, document.Scan(d, &count))
+		require.Equal(t, 3, count)
+	})
+
+	t.Run("Unknown table", func(t *testing.T) {
+		_, err := db.Clone(context.Background(), "baz")
+		require.Error(t, err)
+	})
+}
+
 func BenchmarkSelect(b *testing.B) {
 	for size := 1; size <= 10000; size *= 10 {
 		b.Run(fmt.Sprintf("%.05d", size), func(b *testing.B) {
@@ -244,3 +721,90 @@ func BenchmarkSelectPk(b *testing.B) {
 		})
 	}
 }
+
+// BenchmarkInsertWithIndexes benchmarks successive INSERT statements
+// run within the same transaction, in a database containing
+// tables with 1, 10 and 100 indexes each.
+func BenchmarkInsertWithIndexes(b *testing.B) {
+	for size := 1; size <= 100; size *= 10 {
+		b.Run(fmt.Sprintf("%.03d", size), func(b *testing.B) {
+			db, err := genji.Open(":memory:")
+			require.NoError(b, err)
+			defer db.Close()
+
+			for _, tb := range []string{"foo", "bar", "baz"} {
+				err = db.Exec(fmt.Sprintf("CREATE TABLE %s", tb))
+				require.NoError(b, err)
+
+				for i := 0; i < size; i++ {
+					err = db.Exec(fmt.Sprintf("CREATE INDEX ON %s(a%d)", tb, i))
+					require.NoError(b, err)
+				}
+			}
+
+			tx, err := db.Begin(true)
+			require.NoError(b, err)
+			defer tx.Rollback()
+
+			stmt, err := tx.Prepare("INSERT INTO foo(a0, b) VALUES (?, 'x')")
+			require.NoError(b, err)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				err = stmt.Exec(i)
+				require.NoError(b, err)
+			}
+		})
+	}
+}
+
+func TestQueryParams(t *testing.T) {
+	type doc struct {
+		A int
+		B string
+	}
+
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec("CREATE TABLE test; CREATE INDEX test_a ON test(a)")
+	require.NoError(t, err)
+
+	err = db.Exec("INSERT INTO test VALUES ?", &doc{A: 1, B: "foo"})
+	require.NoError(t, err)
+	err = db.Exec("INSERT INTO test VALUES $d", sql.Named("d", map[string]interface{}{"a": 2, "b": "bar"}))
+	require.NoError(t, err)
+	err = db.Exec("INSERT INTO test VALUES ?", []doc{{A: 3, B: "baz"}, {A: 4, B: "qux"}})
+	require.NoError(t, err)
+	err = db.Exec("INSERT INTO test (a, b) VALUES ?, ?", []interface{}{5, "quux"}, doc{A: 6, B: "corge"})
+	require.NoError(t, err)
+
+	err = db.Exec("INSERT INTO test (a, b) VALUES ?", []interface{}{7})
+	require.Error(t, err)
+	err = db.Exec("INSERT INTO test VALUES ?", []int{1, 2})
+	require.Error(t, err)
+
+	tests := []struct {
+		q      string
+		params []interface{}
+		want   string
+	}{
+		{"SELECT b FROM test WHERE a IN ?", []interface{}{[]int{1, 3, 5}}, `[{"b": "foo"}, {"b": "baz"}, {"b": "quux"}]`},
+		{"SELECT b FROM test WHERE a IN $l", []interface{}{sql.Named("l", []interface{}{2.0, 6})}, `[{"b": "bar"}, {"b": "corge"}]`},
+		{"SELECT b FROM test WHERE b IN ?", []interface{}{[]string{"qux"}}, `[{"b": "qux"}]`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.q, func(t *testing.T) {
+			res, err := db.Query(test.q, test.params...)
+			require.NoError(t, err)
+			defer res.Close()
+
+			var buf bytes.Buffer
+			err = testutil.IteratorToJSONArray(&buf, res)
+			require.NoError(t, err)
+			require.JSONEq(t, test.want, buf.String())
+		})
+	}
+}
@@ -1,7 +1,6 @@
 package document
 
 import (
-	"bytes"
 	"errors"
 	"sort"
 
@@ -224,6 +223,43 @@ func (vb *ValueBuffer) Replace(index int, v Value) error {
 	return nil
 }
 
+// InsertAt inserts a value at the given index, shifting the following values.
+// The index must be between 0 and the length of the buffer.
+func (vb *ValueBuffer) InsertAt(index int, v Value) error {
+	if index < 0 || index > len(vb.Values) {
+		return ErrFieldNotFound
+	}
+
+	vb.Values = append(vb.Values, Value{})
+	copy(vb.Values[index+1:], vb.Values[index:])
+	vb.Values[index] = v
+	return nil
+}
+
+// Delete the value at the given index, shifting the following values.
+func (vb *ValueBuffer) Delete(index int) error {
+	if index < 0 || index >= len(vb.Values) {
+		return ErrFieldNotFound
+	}
+
+	vb.Values = append(vb.Values[:index], vb.Values[index+1:]...)
+	return nil
+}
+
+// Clone returns a deep copy of the buffer.
+// See FieldBuffer.Clone.
+func (vb *ValueBuffer) Clone() *ValueBuffer {
+	newVb := ValueBuffer{
+		Values: make([]Value, len(vb.Values)),
+	}
+
+	for i, v := range vb.Values {
+		newVb.Values[i] = cloneValue(v)
+	}
+
+	return &newVb
+}
+
 // MarshalJSON implements the json.Marshaler interface.
 func (vb ValueBuffer) MarshalJSON() ([]byte, error) {
 	return jsonArray{Array: &vb}.MarshalJSON()
@@ -349,27 +385,5 @@ type jsonArray struct {
 }
 
 func (j jsonArray) MarshalJSON() ([]byte, error) {
-	var buf bytes.Buffer
-
-	buf.WriteByte('[')
-	var notFirst bool
-	err := j.Array.Iterate(func(i int, v Value) error {
-		if notFirst {
-			buf.WriteString(", ")
-		}
-		notFirst = true
-
-		data, err := v.MarshalJSON()
-		if err != nil {
-			return err
-		}
-
-		_, err = buf.Write(data)
-		return err
-	})
-	if err != nil {
-		return nil, err
-	}
-	buf.WriteByte(']')
-	return buf.Bytes(), nil
+	return JSONOptions{}.MarshalArray(j.Array)
 }
@@ -91,3 +91,26 @@ func TestValueBufferApply(t *testing.T) {
 	require.NoError(t, err)
 	require.JSONEq(t, `[6, [6, 6], {"4": 6}]`, string(got))
 }
+
+func TestValueBufferEdit(t *testing.T) {
+	vb := NewValueBuffer(NewIntegerValue(1), NewIntegerValue(2))
+
+	require.NoError(t, vb.InsertAt(0, NewIntegerValue(0)))
+	require.NoError(t, vb.InsertAt(3, NewIntegerValue(3)))
+	require.Error(t, vb.InsertAt(5, NewIntegerValue(5)))
+	require.Equal(t, NewValueBuffer(NewIntegerValue(0), NewIntegerValue(1), NewIntegerValue(2), NewIntegerValue(3)), vb)
+
+	require.NoError(t, vb.Delete(1))
+	require.Equal(t, ErrFieldNotFound, vb.Delete(3))
+	require.Equal(t, NewValueBuffer(NewIntegerValue(0), NewIntegerValue(2), NewIntegerValue(3)), vb)
+
+	vb.Append(NewArrayValue(NewValueBuffer(NewTextValue("a"))))
+	clone := vb.Clone()
+	require.Equal(t, vb, clone)
+
+	nested := clone.Values[3].V.(*ValueBuffer)
+	require.NoError(t, nested.Replace(0, NewTextValue("b")))
+	v, err := vb.Values[3].V.(*ValueBuffer).GetByIndex(0)
+	require.NoError(t, err)
+	require.Equal(t, NewTextValue("a"), v)
+}
@@ -0,0 +1,98 @@
+package document
+
+import (
+	"math"
+)
+
+// This file defines the implicit conversions applied to values, i.e. every conversion
+// that is not requested explicitly with CAST. Every part of Genji relying on them
+// must use these functions, so that comparisons made while scanning a table
+// and lookups made using its indexes and primary key always agree.
+//
+// The rules are the following:
+//
+// Comparisons: values of the same type are compared together, integers and doubles
+// are compared as doubles. Values of other types are never equal: comparing them evaluates
+// to false, or fails in strict mode. NULL is only equal to NULL. See Comparable.
+//
+//	            | bool | integer | double | text | blob | array | document
+//	bool        |  ✓   |         |        |      |      |       |
+//	integer     |      |    ✓    |   ✓    |      |      |       |
+//	double      |      |    ✓    |   ✓    |      |      |       |
+//	text        |      |         |        |  ✓   |      |       |
+//	blob        |      |         |        |      |  ✓   |       |
+//	array       |      |         |        |      |      |   ✓   |
+//	document    |      |         |        |      |      |       |    ✓
+//
+// Storage: values of typed fields are converted with CAST before being stored.
+// Values of fields without type are stored as is, except integers which are stored as doubles,
+// so that 1 and 1.0 are the same key in indexes and primary keys without type. See ConvertUntyped.
+//
+// Lookups: the values used to look up a key of a given type, in an index or a primary key,
+// are only converted if the conversion is lossless: integers to doubles, and doubles
+// without fractional part to integers. Other values cannot be equal to any key of that type.
+// See ConvertKey and ConvertBound.
+
+// Comparable returns true if values of types a and b can be compared together.
+// NULL can only be compared with NULL.
+func Comparable(a, b ValueType) bool {
+	return a == b || (a.IsNumber() && b.IsNumber())
+}
+
+// ConvertUntyped returns the value stored in a field without type constraint
+// for v: integers are converted to doubles, other values are returned as is.
+func ConvertUntyped(v Value) Value {
+	if v.Type == IntegerValue {
+		return NewDoubleValue(float64(v.V.(int64)))
+	}
+
+	return v
+}
+
+// ConvertKey converts v to a key of type t, if the conversion is lossless.
+// It returns false if v cannot be equal to any value of type t.
+// If t is the any type, the key is converted with ConvertUntyped.
+func ConvertKey(v Value, t ValueType) (Value, bool) {
+	switch {
+	case t.IsAny():
+		return ConvertUntyped(v), true
+	case v.Type == t:
+		return v, true
+	case v.Type == IntegerValue && t == DoubleValue:
+		return NewDoubleValue(float64(v.V.(int64))), true
+	case v.Type == DoubleValue && t == IntegerValue:
+		f := v.V.(float64)
+		if !isIntegral(f) {
+			return v, false
+		}
+		return NewIntegerValue(int64(f)), true
+	}
+
+	return v, false
+}
+
+// ConvertBound converts v, the lower or upper bound of a range of keys of type t, to that type.
+// Unlike ConvertKey, a double with a fractional part selecting integer keys
+// is rounded up to the next integer, which is returned with rounded set to true.
+// The bound must then be made inclusive if it is a lower bound, e.g. a > 1.1 becomes a >= 2,
+// and exclusive if it is an upper bound, e.g. a <= 1.1 becomes a < 2.
+// If v cannot be converted, it is returned as is and ok is false.
+func ConvertBound(v Value, t ValueType) (bound Value, rounded, ok bool) {
+	if v.Type == DoubleValue && t == IntegerValue {
+		f := v.V.(float64)
+		if !isIntegral(f) {
+			if math.IsNaN(f) || f > math.MaxInt64 || f < math.MinInt64 {
+				return v, false, false
+			}
+			return NewIntegerValue(int64(math.Ceil(f))), true, true
+		}
+	}
+
+	bound, ok = ConvertKey(v, t)
+	return bound, false, ok
+}
+
+// isIntegral returns true if f has no fractional part and fits in an int64.
+func isIntegral(f float64) bool {
+	return f >= math.MinInt64 && f < math.MaxInt64 && float64(int64(f)) == f
+}
@@ -0,0 +1,185 @@
+package document_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/genjidb/genji/document"
+	"github.com/stretchr/testify/require"
+)
+
+// samples of every type, used to check the conversion rules against the comparison rules.
+var coercionSamples = []document.Value{
+	document.NewNullValue(),
+	document.NewBoolValue(false),
+	document.NewBoolValue(true),
+	document.NewIntegerValue(-1),
+	document.NewIntegerValue(0),
+	document.NewIntegerValue(1),
+	document.NewIntegerValue(2),
+	document.NewDoubleValue(-1.5),
+	document.NewDoubleValue(-1),
+	document.NewDoubleValue(0.5),
+	document.NewDoubleValue(1),
+	document.NewDoubleValue(1.5),
+	document.NewDoubleValue(2),
+	document.NewTextValue("1"),
+	document.NewTextValue("a"),
+	document.NewBlobValue([]byte("1")),
+	document.NewArrayValue(document.NewValueBuffer(document.NewIntegerValue(1))),
+	document.NewDocumentValue(document.NewFieldBuffer().Add("a", document.NewIntegerValue(1))),
+}
+
+var coercionTypes = []document.ValueType{
+	document.NullValue,
+	document.BoolValue,
+	document.IntegerValue,
+	document.DoubleValue,
+	document.TextValue,
+	document.BlobValue,
+	document.ArrayValue,
+	document.DocumentValue,
+}
+
+func TestComparable(t *testing.T) {
+	for _, a := range coercionTypes {
+		for _, b := range coercionTypes {
+			expected := a == b || (a.IsNumber() && b.IsNumber())
+			require.Equal(t, expected, document.Comparable(a, b), "%s and %s", a, b)
+		}
+	}
+
+	// values that are not comparable are never equal, lesser or greater
+	for _, a := range coercionSamples {
+		for _, b := range coercionSamples {
+			if document.Comparable(a.Type, b.Type) || a.Type == document.NullValue || b.Type == document.NullValue {
+				continue
+			}
+
+			for _, cmp := range []func(document.Value) (bool, error){a.IsEqual, a.IsGreaterThan, a.IsGreaterThanOrEqual, a.IsLesserThan, a.IsLesserThanOrEqual} {
+				ok, err := cmp(b)
+				require.NoError(t, err)
+				require.False(t, ok, "%s and %s", a, b)
+			}
+		}
+	}
+}
+
+func TestConvertUntyped(t *testing.T) {
+	for _, v := range coercionSamples {
+		got := document.ConvertUntyped(v)
+		if v.Type == document.IntegerValue {
+			require.Equal(t, document.DoubleValue, got.Type)
+		} else {
+			require.Equal(t, v, got)
+		}
+
+		ok, err := got.IsEqual(v)
+		require.NoError(t, err)
+		require.True(t, ok, "%s", v)
+	}
+}
+
+func TestConvertKey(t *testing.T) {
+	tests := []struct {
+		v        document.Value
+		t        document.ValueType
+		expected document.Value
+		ok       bool
+	}{
+		{document.NewIntegerValue(1), document.IntegerValue, document.NewIntegerValue(1), true},
+		{document.NewIntegerValue(1), document.DoubleValue, document.NewDoubleValue(1), true},
+		{document.NewIntegerValue(1), 0, document.NewDoubleValue(1), true},
+		{document.NewDoubleValue(1), document.IntegerValue, document.NewIntegerValue(1), true},
+		{document.NewDoubleValue(1.5), document.IntegerValue, document.NewDoubleValue(1.5), false},
+		{document.NewDoubleValue(math.MaxFloat64), document.IntegerValue, document.NewDoubleValue(math.MaxFloat64), false},
+		{document.NewTextValue("1"), document.IntegerValue, document.NewTextValue("1"), false},
+		{document.NewTextValue("a"), 0, document.NewTextValue("a"), true},
+		{document.NewBoolValue(true), document.IntegerValue, document.NewBoolValue(true), false},
+		{document.NewNullValue(), document.IntegerValue, document.NewNullValue(), false},
+	}
+
+	for _, test := range tests {
+		got, ok := document.ConvertKey(test.v, test.t)
+		require.Equal(t, test.ok, ok, "%s to %s", test.v, test.t)
+		require.Equal(t, test.expected, got, "%s to %s", test.v, test.t)
+	}
+
+	// a converted key is equal to the original value, and a value that cannot be converted
+	// is not equal to any key of that type.
+	for _, v := range coercionSamples {
+		for _, typ := range coercionTypes[1:] {
+			got, ok := document.ConvertKey(v, typ)
+			if ok {
+				require.Equal(t, typ, got.Type)
+				eq, err := v.IsEqual(got)
+				require.NoError(t, err)
+				require.True(t, eq, "%s to %s", v, typ)
+				continue
+			}
+
+			for _, k := range coercionSamples {
+				if k.Type != typ {
+					continue
+				}
+				eq, err := v.IsEqual(k)
+				require.NoError(t, err)
+				require.False(t, eq, "%s to %s", v, typ)
+			}
+		}
+	}
+}
+
+func TestConvertBound(t *testing.T) {
+	tests := []struct {
+		v        document.Value
+		t        document.ValueType
+		expected document.Value
+		rounded  bool
+		ok       bool
+	}{
+		{document.NewDoubleValue(1.1), document.IntegerValue, document.NewIntegerValue(2), true, true},
+		{document.NewDoubleValue(-1.5), document.IntegerValue, document.NewIntegerValue(-1), true, true},
+		{document.NewDoubleValue(2), document.IntegerValue, document.NewIntegerValue(2), false, true},
+		{document.NewIntegerValue(2), document.DoubleValue, document.NewDoubleValue(2), false, true},
+		{document.NewDoubleValue(math.NaN()), document.IntegerValue, document.NewDoubleValue(math.NaN()), false, false},
+		{document.NewTextValue("a"), document.IntegerValue, document.NewTextValue("a"), false, false},
+	}
+
+	for _, test := range tests {
+		got, rounded, ok := document.ConvertBound(test.v, test.t)
+		require.Equal(t, test.ok, ok, "%s to %s", test.v, test.t)
+		require.Equal(t, test.rounded, rounded, "%s to %s", test.v, test.t)
+		if test.v.Type == document.DoubleValue && math.IsNaN(test.v.V.(float64)) {
+			continue
+		}
+		require.Equal(t, test.expected, got, "%s to %s", test.v, test.t)
+	}
+
+	// a rounded bound selects the same integers as the original one:
+	// k > v <=> k >= bound, and k < v <=> k < bound
+	for _, v := range coercionSamples {
+		bound, rounded, _ := document.ConvertBound(v, document.IntegerValue)
+		if !rounded {
+			continue
+		}
+
+		for _, k := range coercionSamples {
+			if k.Type != document.IntegerValue {
+				continue
+			}
+
+			gt, err := k.IsGreaterThan(v)
+			require.NoError(t, err)
+			gte, err := k.IsGreaterThanOrEqual(bound)
+			require.NoError(t, err)
+			require.Equal(t, gt, gte, "%s > %s", k, v)
+
+			lt, err := k.IsLesserThan(v)
+			require.NoError(t, err)
+			lt2, err := k.IsLesserThan(bound)
+			require.NoError(t, err)
+			require.Equal(t, lt, lt2, "%s < %s", k, v)
+		}
+	}
+}
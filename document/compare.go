@@ -100,6 +100,10 @@ func compare(op operator, l, r Value) (bool, error) {
 	// compare documents together
 	case l.Type == DocumentValue && r.Type == DocumentValue:
 		return compareDocuments(op, l.V.(Document), r.V.(Document))
+
+	// compare references together
+	case l.Type == ReferenceValue && r.Type == ReferenceValue:
+		return compareReferences(op, l.V.(Reference), r.V.(Reference))
 	}
 
 	return false, nil
@@ -388,3 +392,13 @@ func compareDocuments(op operator, l, r Document) (bool, error) {
 		}
 	}
 }
+
+// compareReferences compares the tables of the references,
+// then their keys if they point to the same table.
+func compareReferences(op operator, l, r Reference) (bool, error) {
+	if l.Table != r.Table {
+		return compareTexts(op, l.Table, r.Table), nil
+	}
+
+	return compare(op, l.Key, r.Key)
+}
@@ -185,6 +185,8 @@ func NewValue(x interface{}) (Value, error) {
 		return NewDocumentValue(v), nil
 	case Array:
 		return NewArrayValue(v), nil
+	case Reference:
+		return NewReferenceValue(v.Table, v.Key), nil
 	}
 
 	// Compare by kind to detect type definitions over built-in types.
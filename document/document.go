@@ -2,13 +2,13 @@
 package document
 
 import (
-	"bytes"
 	"errors"
 	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/buger/jsonparser"
+	"github.com/genjidb/genji/internal/stringutil"
 )
 
 // ErrFieldNotFound must be returned by Document implementations, when calling the GetByField method and
@@ -172,10 +172,18 @@ func (fb *FieldBuffer) setFieldValue(field string, reqValue Value) error {
 	return err
 }
 
-// setValueAtPath deep replaces or creates a field at the given path
+// setValueAtPath deep replaces or creates a field at the given path.
+// Only the last fragment of the path can designate a field that doesn't exist yet.
+// Fragments going through values that are neither documents nor arrays, or field names
+// used on arrays, are ignored and v is returned unchanged.
 func setValueAtPath(v Value, p Path, newValue Value) (Value, error) {
 	switch v.Type {
 	case DocumentValue:
+		// array indexes cannot be used on documents
+		if p[0].FieldName == "" {
+			return v, ErrFieldNotFound
+		}
+
 		var buf FieldBuffer
 		err := buf.ScanDocument(v.V.(Document))
 		if err != nil {
@@ -200,6 +208,10 @@ func setValueAtPath(v Value, p Path, newValue Value) (Value, error) {
 		err = buf.setFieldValue(p[0].FieldName, va)
 		return NewDocumentValue(&buf), err
 	case ArrayValue:
+		if p[0].FieldName != "" {
+			return v, nil
+		}
+
 		var vb ValueBuffer
 		err := vb.ScanArray(v.V.(Array))
 		if err != nil {
@@ -227,7 +239,9 @@ func setValueAtPath(v Value, p Path, newValue Value) (Value, error) {
 	return v, nil
 }
 
-// Set replaces a field if it already exists or creates one if not.
+// Set replaces the value at the given path if it already exists or creates it if not.
+// Only the last fragment of the path can designate a field that doesn't exist yet,
+// otherwise it returns ErrFieldNotFound.
 func (fb *FieldBuffer) Set(path Path, v Value) error {
 	if len(path) == 1 {
 		return fb.setFieldValue(path[0].FieldName, v)
@@ -245,8 +259,7 @@ func (fb *FieldBuffer) Set(path Path, v Value) error {
 		}
 	}
 
-	fb.Add(path[0].FieldName, v)
-	return nil
+	return ErrFieldNotFound
 }
 
 // Iterate goes through all the fields of the document and calls the given function by passing each one of them.
@@ -262,58 +275,135 @@ func (fb FieldBuffer) Iterate(fn func(field string, value Value) error) error {
 	return nil
 }
 
-// Delete a field from the buffer.
+// Delete the value at the given path. Documents and arrays that are not
+// buffers are copied to a FieldBuffer or ValueBuffer to be modified.
+// If the path doesn't exist, it returns ErrFieldNotFound.
 func (fb *FieldBuffer) Delete(path Path) error {
-	if len(path) == 1 {
-		for i := range fb.fields {
-			if fb.fields[i].Field == path[0].FieldName {
-				fb.fields = append(fb.fields[0:i], fb.fields[i+1:]...)
-				return nil
-			}
+	if len(path) == 0 || path[0].FieldName == "" {
+		return ErrFieldNotFound
+	}
+
+	for i := range fb.fields {
+		if fb.fields[i].Field != path[0].FieldName {
+			continue
+		}
+
+		if len(path) == 1 {
+			fb.fields = append(fb.fields[0:i], fb.fields[i+1:]...)
+			return nil
 		}
+
+		v, err := deleteValueAtPath(fb.fields[i].Value, path[1:])
+		if err != nil {
+			return err
+		}
+
+		fb.fields[i].Value = v
+		return nil
 	}
 
-	parentPath := path[:len(path)-1]
-	lastFragment := path[len(path)-1]
+	return ErrFieldNotFound
+}
 
-	// get parent doc or array
-	v, err := parentPath.GetValueFromDocument(fb)
+// DeleteByPath parses the path and deletes the value it points to.
+// See Delete and ParsePath.
+func (fb *FieldBuffer) DeleteByPath(path string) error {
+	p, err := ParsePath(path)
 	if err != nil {
 		return err
 	}
+
+	return fb.Delete(p)
+}
+
+// deleteValueAtPath returns a copy of v without the value at the given path.
+func deleteValueAtPath(v Value, p Path) (Value, error) {
 	switch v.Type {
 	case DocumentValue:
-		subBuf, ok := v.V.(*FieldBuffer)
-		if !ok {
-			return errors.New("Delete doesn't support non buffered document")
+		var buf FieldBuffer
+		err := buf.ScanDocument(v.V.(Document))
+		if err != nil {
+			return v, err
 		}
 
-		for i := range subBuf.fields {
-			if subBuf.fields[i].Field == lastFragment.FieldName {
-				subBuf.fields = append(subBuf.fields[0:i], subBuf.fields[i+1:]...)
-				return nil
-			}
+		err = buf.Delete(p)
+		if err != nil {
+			return v, err
 		}
 
-		return ErrFieldNotFound
+		return NewDocumentValue(&buf), nil
 	case ArrayValue:
-		subBuf, ok := v.V.(*ValueBuffer)
-		if !ok {
-			return errors.New("Delete doesn't support non buffered array")
+		if p[0].FieldName != "" {
+			return v, ErrFieldNotFound
 		}
 
-		idx := path[len(path)-1].ArrayIndex
-		if idx >= len(subBuf.Values) {
-			return ErrFieldNotFound
+		var vb ValueBuffer
+		err := vb.ScanArray(v.V.(Array))
+		if err != nil {
+			return v, err
+		}
+
+		if len(p) == 1 {
+			err = vb.Delete(p[0].ArrayIndex)
+			return NewArrayValue(&vb), err
+		}
+
+		va, err := vb.GetByIndex(p[0].ArrayIndex)
+		if err != nil {
+			return v, err
 		}
-		subBuf.Values = append(subBuf.Values[0:idx], subBuf.Values[idx+1:]...)
-	default:
+
+		va, err = deleteValueAtPath(va, p[1:])
+		if err != nil {
+			return v, err
+		}
+
+		err = vb.Replace(p[0].ArrayIndex, va)
+		return NewArrayValue(&vb), err
+	}
+
+	return v, ErrFieldNotFound
+}
+
+// InsertAt inserts a field at the given position, shifting the following fields.
+// The index must be between 0 and the length of the buffer.
+// It doesn't check whether the field already exists.
+func (fb *FieldBuffer) InsertAt(index int, field string, v Value) error {
+	if index < 0 || index > len(fb.fields) {
 		return ErrFieldNotFound
 	}
 
+	fb.fields = append(fb.fields, fieldValue{})
+	copy(fb.fields[index+1:], fb.fields[index:])
+	fb.fields[index] = fieldValue{field, v}
 	return nil
 }
 
+// MoveField moves a field to the given position, shifting the fields in between.
+// The index must be lower than the length of the buffer.
+func (fb *FieldBuffer) MoveField(field string, index int) error {
+	if index < 0 || index >= len(fb.fields) {
+		return ErrFieldNotFound
+	}
+
+	for i := range fb.fields {
+		if fb.fields[i].Field != field {
+			continue
+		}
+
+		fv := fb.fields[i]
+		if i < index {
+			copy(fb.fields[i:index], fb.fields[i+1:index+1])
+		} else {
+			copy(fb.fields[index+1:i+1], fb.fields[index:i])
+		}
+		fb.fields[index] = fv
+		return nil
+	}
+
+	return ErrFieldNotFound
+}
+
 // Replace the value of the field by v.
 func (fb *FieldBuffer) Replace(field string, v Value) error {
 	for i := range fb.fields {
@@ -358,14 +448,86 @@ func (fb *FieldBuffer) Copy(d Document) error {
 	return nil
 }
 
-// Clone the buffer.
+// Clone returns a deep copy of the buffer. Nested documents and arrays
+// are cloned to FieldBuffers and ValueBuffers, and blobs are copied,
+// so that modifying the clone never affects the original buffer.
 func (fb *FieldBuffer) Clone() *FieldBuffer {
-	var newFb FieldBuffer
+	newFb := FieldBuffer{
+		fields:     make([]fieldValue, len(fb.fields)),
+		EncodedKey: append([]byte(nil), fb.EncodedKey...),
+		DecodedKey: cloneValue(fb.DecodedKey),
+	}
+
+	for i, f := range fb.fields {
+		newFb.fields[i] = fieldValue{f.Field, cloneValue(f.Value)}
+	}
 
-	_ = newFb.Copy(fb)
 	return &newFb
 }
 
+// cloneValue returns a deep copy of v.
+func cloneValue(v Value) Value {
+	switch v.Type {
+	case BlobValue:
+		return NewBlobValue(append([]byte{}, v.V.([]byte)...))
+	case DocumentValue:
+		fb, ok := v.V.(*FieldBuffer)
+		if !ok {
+			fb = NewFieldBuffer()
+			_ = fb.Copy(v.V.(Document))
+		}
+		return NewDocumentValue(fb.Clone())
+	case ArrayValue:
+		vb, ok := v.V.(*ValueBuffer)
+		if !ok {
+			vb = NewValueBuffer()
+			_ = vb.Copy(v.V.(Array))
+		}
+		return NewArrayValue(vb.Clone())
+	}
+
+	return v
+}
+
+// SortFields sorts the fields of the buffer by name, recursively, so that documents
+// with the same fields are always represented in the same order.
+// Nested documents and arrays are converted to FieldBuffers and ValueBuffers.
+func (fb *FieldBuffer) SortFields() {
+	sort.SliceStable(fb.fields, func(i, j int) bool {
+		return fb.fields[i].Field < fb.fields[j].Field
+	})
+
+	for i := range fb.fields {
+		fb.fields[i].Value = sortValueFields(fb.fields[i].Value)
+	}
+}
+
+// sortValueFields sorts the fields of the documents contained in v.
+func sortValueFields(v Value) Value {
+	switch v.Type {
+	case DocumentValue:
+		fb, ok := v.V.(*FieldBuffer)
+		if !ok {
+			fb = NewFieldBuffer()
+			_ = fb.Copy(v.V.(Document))
+		}
+		fb.SortFields()
+		return NewDocumentValue(fb)
+	case ArrayValue:
+		vb, ok := v.V.(*ValueBuffer)
+		if !ok {
+			vb = NewValueBuffer()
+			_ = vb.Copy(v.V.(Array))
+		}
+		for i := range vb.Values {
+			vb.Values[i] = sortValueFields(vb.Values[i])
+		}
+		return NewArrayValue(vb)
+	}
+
+	return v
+}
+
 // Apply a function to all the values of the buffer.
 func (fb *FieldBuffer) Apply(fn func(p Path, v Value) (Value, error)) error {
 	path := Path{PathFragment{}}
@@ -473,6 +635,87 @@ func NewPath(fragments ...string) Path {
 	return path
 }
 
+// ParsePath parses a path such as a.b[2].`c d`.
+// Field names that contain characters other than letters, digits and
+// underscores must be enclosed in backquotes, in which a backquote
+// or a backslash must be escaped with a backslash.
+func ParsePath(s string) (Path, error) {
+	var path Path
+
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == '[' && len(path) > 0:
+			end := strings.IndexByte(s[i:], ']')
+			if end == -1 {
+				return nil, stringutil.Errorf("invalid path %q: missing ]", s)
+			}
+
+			idx, err := strconv.Atoi(s[i+1 : i+end])
+			if err != nil || idx < 0 {
+				return nil, stringutil.Errorf("invalid path %q: invalid array index %q", s, s[i+1:i+end])
+			}
+
+			path = append(path, PathFragment{ArrayIndex: idx})
+			i += end + 1
+			continue
+		case s[i] == '.' && len(path) > 0:
+			i++
+		case len(path) > 0:
+			return nil, stringutil.Errorf("invalid path %q: unexpected %q", s, string(s[i]))
+		}
+
+		field, n, err := parsePathField(s[i:])
+		if err != nil {
+			return nil, stringutil.Errorf("invalid path %q: %w", s, err)
+		}
+
+		path = append(path, PathFragment{FieldName: field})
+		i += n
+	}
+
+	if len(path) == 0 {
+		return nil, errors.New("empty path")
+	}
+
+	return path, nil
+}
+
+// parsePathField parses the field name at the beginning of s
+// and returns it with the number of bytes read.
+func parsePathField(s string) (string, int, error) {
+	if len(s) > 0 && s[0] == '`' {
+		var b strings.Builder
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '`':
+				return b.String(), i + 1, nil
+			case '\\':
+				i++
+				if i == len(s) || (s[i] != '`' && s[i] != '\\') {
+					return "", 0, errors.New("bad escape")
+				}
+			}
+			b.WriteByte(s[i])
+		}
+
+		return "", 0, errors.New("missing closing backquote")
+	}
+
+	var n int
+	for n < len(s) && isPathIdentChar(s[n]) {
+		n++
+	}
+	if n == 0 {
+		return "", 0, errors.New("missing field name")
+	}
+
+	return s[:n], n, nil
+}
+
+func isPathIdentChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
 // PathFragment is a fragment of a path representing either a field name or
 // the index of an array.
 type PathFragment struct {
@@ -581,32 +824,5 @@ type jsonDocument struct {
 }
 
 func (j jsonDocument) MarshalJSON() ([]byte, error) {
-	var buf bytes.Buffer
-
-	buf.WriteByte('{')
-
-	var notFirst bool
-	err := j.Document.Iterate(func(f string, v Value) error {
-		if notFirst {
-			buf.WriteString(", ")
-		}
-		notFirst = true
-
-		buf.WriteString(strconv.Quote(f))
-		buf.WriteString(": ")
-
-		data, err := v.MarshalJSON()
-		if err != nil {
-			return err
-		}
-		_, err = buf.Write(data)
-		return err
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	buf.WriteByte('}')
-
-	return buf.Bytes(), nil
+	return JSONOptions{}.MarshalDocument(j.Document)
 }
@@ -118,6 +118,10 @@ func TestFieldBuffer(t *testing.T) {
 			{"unknown path", `{"a": {"b": [1, 2, 3]}}`, `a.e.f`, document.NewIntegerValue(1), ``, true},
 			{"index out of range", `{"a": {"b": [1, 2, 3]}}`, `a.b[1000]`, document.NewIntegerValue(1), ``, true},
 			{"document not array", `{"a": {"b": "foo"}}`, `a[0].b`, document.NewTextValue("bar"), ``, true},
+			{"index of document", `{"a": {"b": "foo"}}`, `a[0]`, document.NewTextValue("bar"), ``, true},
+			{"field of array", `{"a": [1, 2]}`, `a.b`, document.NewTextValue("bar"), `{"a": [1, 2]}`, false},
+			{"unknown root", `{"a": 1}`, `b.c`, document.NewIntegerValue(1), ``, true},
+			{"unknown root array", `{"a": 1}`, `b[0]`, document.NewIntegerValue(1), ``, true},
 		}
 
 		for _, tt := range tests {
@@ -157,6 +161,8 @@ func TestFieldBuffer(t *testing.T) {
 			{`{"a": [1, 2], "b": "hello"}`, "a[5]", ``, true},
 			{`{"a": [1, {"c": [1]}], "b": "hello"}`, "a[1].c", `{"a": [1, {}], "b": "hello"}`, false},
 			{`{"a": [1, {"c": [1]}], "b": "hello"}`, "a[1].d", ``, true},
+			{`{"a": {"b": [1, 2, 3]}}`, "a.b[2]", `{"a": {"b": [1, 2]}}`, false},
+			{`{"a": {"b": [1, 2, 3]}}`, "a[0]", ``, true},
 		}
 
 		for _, test := range tests {
@@ -180,6 +186,79 @@ func TestFieldBuffer(t *testing.T) {
 		}
 	})
 
+	t.Run("DeleteByPath", func(t *testing.T) {
+		// nested values that are not buffers are copied
+		var buf document.FieldBuffer
+		buf.Add("a", document.NewDocumentValue(document.NewFromJSON([]byte(`{"b": [1, {"c": 2, "d": 3}]}`))))
+
+		err := buf.DeleteByPath("a.b[1].c")
+		require.NoError(t, err)
+		got, err := json.Marshal(&buf)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"a": {"b": [1, {"d": 3}]}}`, string(got))
+
+		require.Equal(t, document.ErrFieldNotFound, buf.DeleteByPath("a.b[1].c"))
+		require.Error(t, buf.DeleteByPath("a.b["))
+	})
+
+	t.Run("InsertAt", func(t *testing.T) {
+		buf := document.NewFieldBuffer().Add("a", document.NewIntegerValue(1))
+
+		require.NoError(t, buf.InsertAt(0, "b", document.NewIntegerValue(2)))
+		require.NoError(t, buf.InsertAt(2, "c", document.NewIntegerValue(3)))
+		require.NoError(t, buf.InsertAt(1, "d", document.NewIntegerValue(4)))
+		require.Error(t, buf.InsertAt(5, "e", document.NewIntegerValue(5)))
+		require.Equal(t, `{"b": 2, "d": 4, "a": 1, "c": 3}`, buf.String())
+	})
+
+	t.Run("MoveField", func(t *testing.T) {
+		buf := document.NewFieldBuffer().
+			Add("a", document.NewIntegerValue(1)).
+			Add("b", document.NewIntegerValue(2)).
+			Add("c", document.NewIntegerValue(3))
+
+		require.NoError(t, buf.MoveField("a", 2))
+		require.Equal(t, `{"b": 2, "c": 3, "a": 1}`, buf.String())
+		require.NoError(t, buf.MoveField("a", 0))
+		require.Equal(t, `{"a": 1, "b": 2, "c": 3}`, buf.String())
+		require.Equal(t, document.ErrFieldNotFound, buf.MoveField("d", 0))
+		require.Equal(t, document.ErrFieldNotFound, buf.MoveField("a", 3))
+	})
+
+	t.Run("Clone", func(t *testing.T) {
+		blob := []byte("foo")
+		buf := document.NewFieldBuffer().
+			Add("a", document.NewBlobValue(blob)).
+			Add("b", document.NewDocumentValue(document.NewFromJSON([]byte(`{"c": [1, {"d": 2}]}`))))
+
+		clone := buf.Clone()
+		require.Equal(t, buf.String(), clone.String())
+
+		blob[0] = 'b'
+		require.NoError(t, clone.DeleteByPath("b.c[1].d"))
+		require.Equal(t, `{"a": "Zm9v", "b": {"c": [1, {}]}}`, clone.String())
+		require.Equal(t, `{"a": "Ym9v", "b": {"c": [1, {"d": 2}]}}`, buf.String())
+	})
+
+	t.Run("SortFields", func(t *testing.T) {
+		buf := document.NewFieldBuffer()
+		err := buf.Copy(document.NewFromJSON([]byte(`{"c": [{"e": 1, "d": 2}], "a": {"b": 3, "a": 4}, "b": 5}`)))
+		require.NoError(t, err)
+
+		buf.SortFields()
+		require.Equal(t, `{"a": {"a": 4, "b": 3}, "b": 5, "c": [{"d": 2, "e": 1}]}`, buf.String())
+
+		// documents and arrays that are not buffers are sorted as well
+		arr, err := document.NewFromJSON([]byte(`{"x": [{"f": 1, "e": 2}]}`)).GetByField("x")
+		require.NoError(t, err)
+		buf = document.NewFieldBuffer().
+			Add("b", document.NewDocumentValue(document.NewFromJSON([]byte(`{"d": 1, "c": 2}`)))).
+			Add("a", arr)
+
+		buf.SortFields()
+		require.Equal(t, `{"a": [{"e": 2, "f": 1}], "b": {"c": 2, "d": 1}}`, buf.String())
+	})
+
 	t.Run("Replace", func(t *testing.T) {
 		var buf document.FieldBuffer
 		buf.Add("a", document.NewIntegerValue(10))
@@ -624,6 +703,43 @@ func TestPath(t *testing.T) {
 	}
 }
 
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected document.Path
+		fails    bool
+	}{
+		{"a", document.NewPath("a"), false},
+		{"a.b[2]", document.NewPath("a", "b", "2"), false},
+		{"a[0][1].b_1", document.NewPath("a", "0", "1", "b_1"), false},
+		{"`a b`.`c\\`d`", document.Path{{FieldName: "a b"}, {FieldName: "c`d"}}, false},
+		{"a.`0`", document.Path{{FieldName: "a"}, {FieldName: "0"}}, false},
+		{"", nil, true},
+		{"[0]", nil, true},
+		{"a.", nil, true},
+		{"a..b", nil, true},
+		{"a[b]", nil, true},
+		{"a[-1]", nil, true},
+		{"a[0", nil, true},
+		{"a b", nil, true},
+		{"`a", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			p, err := document.ParsePath(test.path)
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.expected, p)
+			require.Equal(t, parsePath(t, test.path), p)
+		})
+	}
+}
+
 func TestJSONDocument(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -132,11 +132,44 @@ func EncodeValue(v document.Value) ([]byte, error) {
 		return binarysort.AppendFloat64(nil, v.V.(float64)), nil
 	case document.NullValue:
 		return nil, nil
+	case document.ReferenceValue:
+		return encodeReference(v.V.(document.Reference))
 	}
 
 	return nil, errors.New("unknown type")
 }
 
+// encodeReference encodes the table name prefixed by its length,
+// followed by the type and the encoded value of the key.
+func encodeReference(r document.Reference) ([]byte, error) {
+	key, err := EncodeValue(r.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(r.Table)+1+len(key))
+	buf = buf[:binary.PutUvarint(buf, uint64(len(r.Table)))]
+	buf = append(buf, r.Table...)
+	buf = append(buf, byte(r.Key.Type))
+	return append(buf, key...), nil
+}
+
+func decodeReference(data []byte) (document.Value, error) {
+	size, n := binary.Uvarint(data)
+	if n <= 0 || uint64(len(data)-n) < size+1 {
+		return document.Value{}, errors.New("cannot decode reference")
+	}
+	data = data[n:]
+
+	table := string(data[:size])
+	key, err := DecodeValue(document.ValueType(data[size]), data[size+1:])
+	if err != nil {
+		return document.Value{}, err
+	}
+
+	return document.NewReferenceValue(table, key), nil
+}
+
 func encodeInt64(x int64) []byte {
 	buf := make([]byte, binary.MaxVarintLen64)
 	n := binary.PutVarint(buf, x)
@@ -346,6 +379,8 @@ func DecodeValue(t document.ValueType, data []byte) (document.Value, error) {
 		return document.NewDoubleValue(x), nil
 	case document.NullValue:
 		return document.NewNullValue(), nil
+	case document.ReferenceValue:
+		return decodeReference(data)
 	}
 
 	return document.Value{}, errors.New("unknown type")
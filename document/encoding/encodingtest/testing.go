@@ -17,6 +17,7 @@ func TestCodec(t *testing.T, codecBuilder func() encoding.Codec) {
 		test func(*testing.T, func() encoding.Codec)
 	}{
 		{"EncodeDecode", testEncodeDecode},
+		{"FieldOrder", testFieldOrder},
 		{"NewDocument", testDecodeDocument},
 		{"Document/GetByField", testDocumentGetByField},
 		{"Array/GetByIndex", testArrayGetByIndex},
@@ -78,6 +79,15 @@ func testEncodeDecode(t *testing.T, codecBuilder func() encoding.Codec) {
 				Add("array", document.NewArrayValue(complexArray)),
 			`{"age": 10, "name": "john", "address": {"city": "Ajaccio", "country": "France"}, "array": [true, -40, -3.14, 3, "YmxvYg==", "hello", {"city": "Ajaccio", "country": "France"}, [11]]}`,
 		},
+		{
+			"References",
+			document.NewFieldBuffer().
+				Add("author", document.NewReferenceValue("users", document.NewIntegerValue(10))).
+				Add("tags", document.NewArrayValue(document.NewValueBuffer().
+					Append(document.NewReferenceValue("tags", document.NewTextValue("go"))))).
+				Add("name", document.NewTextValue("john")),
+			`{"author": {"$ref": "users", "$id": 10}, "tags": [{"$ref": "tags", "$id": "go"}], "name": "john"}`,
+		},
 	}
 
 	var buf bytes.Buffer
@@ -97,6 +107,29 @@ func testEncodeDecode(t *testing.T, codecBuilder func() encoding.Codec) {
 	}
 }
 
+// testFieldOrder ensures the fields of the documents are decoded
+// in the order they were encoded, at every level.
+func testFieldOrder(t *testing.T, codecBuilder func() encoding.Codec) {
+	d := document.NewFieldBuffer().
+		Add("z", document.NewIntegerValue(1)).
+		Add("a", document.NewDocumentValue(document.NewFieldBuffer().
+			Add("y", document.NewIntegerValue(2)).
+			Add("b", document.NewIntegerValue(3)))).
+		Add("m", document.NewArrayValue(document.NewValueBuffer().
+			Append(document.NewDocumentValue(document.NewFieldBuffer().
+				Add("x", document.NewIntegerValue(4)).
+				Add("c", document.NewIntegerValue(5))))))
+
+	var buf bytes.Buffer
+	codec := codecBuilder()
+	err := codec.NewEncoder(&buf).EncodeDocument(d)
+	require.NoError(t, err)
+
+	data, err := document.MarshalJSON(codec.NewDecoder(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, `{"z": 1, "a": {"y": 2, "b": 3}, "m": [{"x": 4, "c": 5}]}`, string(data))
+}
+
 func testDocumentGetByField(t *testing.T, codecBuilder func() encoding.Codec) {
 	codec := codecBuilder()
 
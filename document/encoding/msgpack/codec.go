@@ -1,6 +1,7 @@
 package msgpack
 
 import (
+	"bytes"
 	"io"
 
 	"github.com/genjidb/genji/document"
@@ -10,6 +11,9 @@ import (
 	"github.com/vmihailenco/msgpack/v5/msgpcode"
 )
 
+// referenceExtID is the MessagePack extension type used to encode references.
+const referenceExtID int8 = 1
+
 // A Codec is a MessagePack implementation of an encoding.Codec.
 type Codec struct{}
 
@@ -109,6 +113,7 @@ func (e *Encoder) EncodeArray(a document.Array) error {
 // - int32 -> int32
 // - int64 -> int64
 // - float64 -> float64
+// - reference -> extension containing the table name and the key
 func (e *Encoder) EncodeValue(v document.Value) error {
 	switch v.Type {
 	case document.DocumentValue:
@@ -127,11 +132,38 @@ func (e *Encoder) EncodeValue(v document.Value) error {
 		return e.enc.EncodeInt(v.V.(int64))
 	case document.DoubleValue:
 		return e.enc.EncodeFloat64(v.V.(float64))
+	case document.ReferenceValue:
+		return e.encodeReference(v.V.(document.Reference))
 	}
 
 	return e.enc.Encode(v.V)
 }
 
+// encodeReference encodes the table and the key of r
+// into the payload of a MessagePack extension.
+func (e *Encoder) encodeReference(r document.Reference) error {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	defer enc.Close()
+
+	err := enc.enc.EncodeString(r.Table)
+	if err != nil {
+		return err
+	}
+	err = enc.EncodeValue(r.Key)
+	if err != nil {
+		return err
+	}
+
+	err = e.enc.EncodeExtHeader(referenceExtID, buf.Len())
+	if err != nil {
+		return err
+	}
+
+	_, err = e.enc.Writer().Write(buf.Bytes())
+	return err
+}
+
 // Close puts the encoder into the pool for reuse.
 func (e *Encoder) Close() {
 	msgpack.PutEncoder(e.enc)
@@ -244,11 +276,36 @@ func (d *Decoder) DecodeValue() (v document.Value, err error) {
 		}
 		v.Type = document.DoubleValue
 		return
+	case msgpcode.FixExt1, msgpcode.FixExt2, msgpcode.FixExt4, msgpcode.FixExt8, msgpcode.FixExt16, msgpcode.Ext8, msgpcode.Ext16, msgpcode.Ext32:
+		return d.decodeReference()
 	}
 
 	panic(stringutil.Sprintf("unsupported type %v", c))
 }
 
+// decodeReference decodes a reference encoded as an extension.
+func (d *Decoder) decodeReference() (document.Value, error) {
+	id, _, err := d.dec.DecodeExtHeader()
+	if err != nil {
+		return document.Value{}, err
+	}
+	if id != referenceExtID {
+		return document.Value{}, stringutil.Errorf("unsupported extension type %d", id)
+	}
+
+	table, err := d.dec.DecodeString()
+	if err != nil {
+		return document.Value{}, err
+	}
+
+	key, err := d.DecodeValue()
+	if err != nil {
+		return document.Value{}, err
+	}
+
+	return document.NewReferenceValue(table, key), nil
+}
+
 // DecodeDocument decodes one document from the reader.
 // If the document is malformed, it will not return an error.
 // However, calls to Iterate or GetByField will fail.
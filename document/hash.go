@@ -0,0 +1,141 @@
+package document
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"math"
+	"sort"
+
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// HashSize is the size of the hashes returned by Hash and HashValue.
+const HashSize = sha256.Size
+
+// markers of the canonical encoding, distinct from the value types.
+const (
+	hashFieldMarker = 0x01
+	hashEndMarker   = 0x00
+)
+
+// Hash returns the SHA-256 hash of the canonical encoding of d.
+// Documents that are equal have the same hash, regardless of the order of their fields
+// and of the codec used to store them, so that hashes can be used to deduplicate documents
+// or to detect changes cheaply. In the canonical encoding, the fields of the documents are
+// sorted by name, recursively, and integers are encoded as doubles,
+// since 1 and 1.0 are equal.
+func Hash(d Document) ([]byte, error) {
+	return HashValue(NewDocumentValue(d))
+}
+
+// HashValue returns the SHA-256 hash of the canonical encoding of v.
+// See Hash.
+func HashValue(v Value) ([]byte, error) {
+	h := sha256.New()
+
+	var buf [binary.MaxVarintLen64]byte
+	err := hashValue(h, buf[:], v)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.Sum(nil), nil
+}
+
+// hashValue writes the canonical encoding of v to h.
+// Every value starts with its type and is self delimited.
+func hashValue(h hash.Hash, buf []byte, v Value) error {
+	t := v.Type
+	if t == IntegerValue {
+		t = DoubleValue
+	}
+	h.Write([]byte{byte(t)})
+
+	switch v.Type {
+	case NullValue:
+	case BoolValue:
+		if v.V.(bool) {
+			h.Write([]byte{1})
+		} else {
+			h.Write([]byte{0})
+		}
+	case IntegerValue:
+		hashDouble(h, buf, float64(v.V.(int64)))
+	case DoubleValue:
+		hashDouble(h, buf, v.V.(float64))
+	case TextValue:
+		hashBytes(h, buf, []byte(v.V.(string)))
+	case BlobValue:
+		hashBytes(h, buf, v.V.([]byte))
+	case ReferenceValue:
+		r := v.V.(Reference)
+		hashBytes(h, buf, []byte(r.Table))
+		return hashValue(h, buf, r.Key)
+	case ArrayValue:
+		err := v.V.(Array).Iterate(func(i int, v Value) error {
+			return hashValue(h, buf, v)
+		})
+		if err != nil {
+			return err
+		}
+		h.Write([]byte{hashEndMarker})
+	case DocumentValue:
+		return hashDocument(h, buf, v.V.(Document))
+	default:
+		return stringutil.Errorf("cannot hash type %s", v.Type)
+	}
+
+	return nil
+}
+
+// hashDocument writes the fields of d to h, sorted by name.
+// Fields with the same name keep their order.
+func hashDocument(h hash.Hash, buf []byte, d Document) error {
+	var fields []fieldValue
+	err := d.Iterate(func(field string, value Value) error {
+		fields = append(fields, fieldValue{Field: field, Value: value})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].Field < fields[j].Field
+	})
+
+	for _, f := range fields {
+		h.Write([]byte{hashFieldMarker})
+		hashBytes(h, buf, []byte(f.Field))
+
+		err = hashValue(h, buf, f.Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	h.Write([]byte{hashEndMarker})
+	return nil
+}
+
+// hashDouble writes the bits of f to h. Zeros and NaNs
+// have a single representation.
+func hashDouble(h hash.Hash, buf []byte, f float64) {
+	switch {
+	case f == 0:
+		f = 0
+	case math.IsNaN(f):
+		f = math.NaN()
+	}
+
+	binary.BigEndian.PutUint64(buf, math.Float64bits(f))
+	h.Write(buf[:8])
+}
+
+// hashBytes writes the length of data followed by data to h.
+func hashBytes(h hash.Hash, buf []byte, data []byte) {
+	n := binary.PutUvarint(buf, uint64(len(data)))
+	h.Write(buf[:n])
+	h.Write(data)
+}
@@ -0,0 +1,135 @@
+package document_test
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/document/encoding/msgpack"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHash(t *testing.T) {
+	hash := func(d document.Document) []byte {
+		t.Helper()
+
+		h, err := document.Hash(d)
+		require.NoError(t, err)
+		require.Len(t, h, document.HashSize)
+		return h
+	}
+
+	t.Run("Field order", func(t *testing.T) {
+		d1 := document.NewFromJSON([]byte(`{"a": 1, "b": {"c": [1, {"d": true, "e": null}], "f": "g"}}`))
+		d2 := document.NewFieldBuffer().
+			Add("b", document.NewDocumentValue(document.NewFromJSON([]byte(`{"f": "g", "c": [1.0, {"e": null, "d": true}]}`)))).
+			Add("a", document.NewDoubleValue(1))
+
+		require.Equal(t, hash(d1), hash(d2))
+
+		// the hash doesn't depend on the codec
+		var buf bytes.Buffer
+		codec := msgpack.NewCodec()
+		enc := codec.NewEncoder(&buf)
+		require.NoError(t, enc.EncodeDocument(d1))
+		enc.Close()
+		require.Equal(t, hash(d1), hash(codec.NewDecoder(buf.Bytes())))
+	})
+
+	t.Run("Structure", func(t *testing.T) {
+		docs := []string{
+			`{}`,
+			`{"a": null}`,
+			`{"a": "b"}`,
+			`{"ab": ""}`,
+			`{"a": ["b", "c"]}`,
+			`{"a": ["bc"]}`,
+			`{"a": [["b"], "c"]}`,
+			`{"a": ["b"], "c": []}`,
+			`{"a": {"b": 1}}`,
+			`{"a": {}, "b": 1}`,
+			`{"a": 1, "a": 2}`,
+			`{"a": 2, "a": 1}`,
+		}
+
+		seen := make(map[string]string)
+		for _, d := range docs {
+			h := string(hash(document.NewFromJSON([]byte(d))))
+			require.NotContains(t, seen, h, "%s and %s", d, seen[h])
+			seen[h] = d
+		}
+	})
+
+	t.Run("Values", func(t *testing.T) {
+		// values have the same hash if and only if they are equal
+		for _, a := range coercionSamples {
+			for _, b := range coercionSamples {
+				ha, err := document.HashValue(a)
+				require.NoError(t, err)
+				hb, err := document.HashValue(b)
+				require.NoError(t, err)
+
+				eq, err := a.IsEqual(b)
+				require.NoError(t, err)
+				require.Equal(t, eq, bytes.Equal(ha, hb), "%s and %s", a, b)
+			}
+		}
+
+		h1, err := document.HashValue(document.NewDoubleValue(0))
+		require.NoError(t, err)
+		h2, err := document.HashValue(document.NewDoubleValue(math.Copysign(0, -1)))
+		require.NoError(t, err)
+		require.Equal(t, h1, h2)
+
+		h1, err = document.HashValue(document.NewTextValue("a"))
+		require.NoError(t, err)
+		h2, err = document.HashValue(document.NewBlobValue([]byte("a")))
+		require.NoError(t, err)
+		require.NotEqual(t, h1, h2)
+	})
+}
+
+// Hashes can be used to remove duplicate documents from a stream,
+// without keeping the documents in memory.
+func ExampleHash() {
+	docs := []document.Document{
+		document.NewFromJSON([]byte(`{"name": "foo", "age": 10}`)),
+		document.NewFromJSON([]byte(`{"age": 10.0, "name": "foo"}`)),
+		document.NewFromJSON([]byte(`{"name": "bar", "age": 10}`)),
+	}
+
+	dedupe := func(docs []document.Document) ([]document.Document, error) {
+		seen := make(map[string]bool)
+
+		var unique []document.Document
+		for _, d := range docs {
+			h, err := document.Hash(d)
+			if err != nil {
+				return nil, err
+			}
+
+			if !seen[string(h)] {
+				seen[string(h)] = true
+				unique = append(unique, d)
+			}
+		}
+
+		return unique, nil
+	}
+
+	unique, err := dedupe(docs)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, d := range unique {
+		data, _ := document.MarshalJSON(d)
+		fmt.Println(string(data))
+	}
+
+	// Output:
+	// {"name": "foo", "age": 10}
+	// {"name": "bar", "age": 10}
+}
@@ -0,0 +1,282 @@
+package document
+
+import (
+	"bytes"
+	"encoding/base64"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// extendedBlobField is the name of the field used to tag
+// blobs encoded in extended mode.
+const extendedBlobField = "$blob"
+
+// JSONOptions configures how documents, arrays and values are encoded to JSON.
+// The zero value produces the same output as MarshalJSON.
+type JSONOptions struct {
+	// Indent is written once per nesting level before each field
+	// and array value, each of them on its own line.
+	// If empty, the output is written on a single line.
+	Indent string
+	// SortFields writes the fields of documents in lexicographic order
+	// instead of the order in which they are stored.
+	SortFields bool
+	// Extended preserves the type of the values that plain JSON cannot represent:
+	// blobs are written as {"$blob": "<base64>"} instead of base64 strings,
+	// and doubles always contain a decimal point or an exponent, so that they
+	// are not decoded as integers.
+	// Use UnmarshalDocument with the same option to decode them. References, which are
+	// always written as {"$ref": "<table>", "$id": <key>}, are also decoded as such.
+	Extended bool
+}
+
+// MarshalDocument encodes a document to JSON.
+func (o JSONOptions) MarshalDocument(d Document) ([]byte, error) {
+	var buf bytes.Buffer
+	err := o.appendDocument(&buf, d, 0)
+	return buf.Bytes(), err
+}
+
+// MarshalArray encodes an array to JSON.
+func (o JSONOptions) MarshalArray(a Array) ([]byte, error) {
+	var buf bytes.Buffer
+	err := o.appendArray(&buf, a, 0)
+	return buf.Bytes(), err
+}
+
+// MarshalValue encodes a value to JSON.
+func (o JSONOptions) MarshalValue(v Value) ([]byte, error) {
+	var buf bytes.Buffer
+	err := o.appendValue(&buf, v, 0)
+	return buf.Bytes(), err
+}
+
+// UnmarshalDocument decodes a JSON object. If the Extended option is set,
+// values tagged by MarshalDocument are decoded with their original type.
+func (o JSONOptions) UnmarshalDocument(data []byte) (*FieldBuffer, error) {
+	fb := NewFieldBuffer()
+	err := fb.UnmarshalJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if !o.Extended {
+		return fb, nil
+	}
+
+	v, err := fromExtendedJSON(NewDocumentValue(fb))
+	if err != nil {
+		return nil, err
+	}
+
+	return v.V.(*FieldBuffer), nil
+}
+
+// newline starts a new line indented for the given level.
+func (o JSONOptions) newline(buf *bytes.Buffer, level int) {
+	buf.WriteByte('\n')
+	for i := 0; i < level; i++ {
+		buf.WriteString(o.Indent)
+	}
+}
+
+// separator writes what comes between two fields or array values.
+func (o JSONOptions) separator(buf *bytes.Buffer, first bool, level int) {
+	if !first {
+		buf.WriteByte(',')
+		if o.Indent == "" {
+			buf.WriteByte(' ')
+		}
+	}
+
+	if o.Indent != "" {
+		o.newline(buf, level+1)
+	}
+}
+
+func (o JSONOptions) appendDocument(buf *bytes.Buffer, d Document, level int) error {
+	buf.WriteByte('{')
+
+	var empty = true
+	appendField := func(f string, v Value) error {
+		o.separator(buf, empty, level)
+		empty = false
+
+		buf.WriteString(strconv.Quote(f))
+		buf.WriteString(": ")
+
+		return o.appendValue(buf, v, level+1)
+	}
+
+	var err error
+	if o.SortFields {
+		var fields []string
+		fields, err = Fields(d)
+		if err != nil {
+			return err
+		}
+		sort.Strings(fields)
+
+		for _, f := range fields {
+			v, err := d.GetByField(f)
+			if err != nil {
+				return err
+			}
+
+			err = appendField(f, v)
+			if err != nil {
+				return err
+			}
+		}
+	} else {
+		err = d.Iterate(appendField)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !empty && o.Indent != "" {
+		o.newline(buf, level)
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+func (o JSONOptions) appendArray(buf *bytes.Buffer, a Array, level int) error {
+	buf.WriteByte('[')
+
+	var empty = true
+	err := a.Iterate(func(i int, v Value) error {
+		o.separator(buf, empty, level)
+		empty = false
+
+		return o.appendValue(buf, v, level+1)
+	})
+	if err != nil {
+		return err
+	}
+
+	if !empty && o.Indent != "" {
+		o.newline(buf, level)
+	}
+	buf.WriteByte(']')
+	return nil
+}
+
+func (o JSONOptions) appendValue(buf *bytes.Buffer, v Value, level int) error {
+	switch v.Type {
+	case ArrayValue:
+		return o.appendArray(buf, v.V.(Array), level)
+	case DocumentValue:
+		return o.appendDocument(buf, v.V.(Document), level)
+	case BlobValue:
+		if o.Extended {
+			buf.WriteString(`{"` + extendedBlobField + `": `)
+			appendBase64(buf, v.V.([]byte))
+			buf.WriteByte('}')
+			return nil
+		}
+
+		appendBase64(buf, v.V.([]byte))
+		return nil
+	case DoubleValue:
+		f := v.V.(float64)
+		data := appendDouble(nil, f)
+		// add a decimal point to integral values
+		if o.Extended && !math.IsInf(f, 0) && bytes.IndexAny(data, ".e") == -1 {
+			data = append(data, ".0"...)
+		}
+		buf.Write(data)
+		return nil
+	}
+
+	data, err := v.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	buf.Write(data)
+	return nil
+}
+
+// appendBase64 writes src as a base64 encoded JSON string.
+func appendBase64(buf *bytes.Buffer, src []byte) {
+	dst := make([]byte, base64.StdEncoding.EncodedLen(len(src))+2)
+	dst[0] = '"'
+	dst[len(dst)-1] = '"'
+	base64.StdEncoding.Encode(dst[1:], src)
+	buf.Write(dst)
+}
+
+// fromExtendedJSON converts the documents tagging values
+// encoded in extended mode to the values they represent.
+func fromExtendedJSON(v Value) (Value, error) {
+	switch v.Type {
+	case ArrayValue:
+		vb := NewValueBuffer()
+		err := v.V.(Array).Iterate(func(i int, v Value) error {
+			v, err := fromExtendedJSON(v)
+			if err != nil {
+				return err
+			}
+
+			vb.Append(v)
+			return nil
+		})
+		if err != nil {
+			return Value{}, err
+		}
+
+		return NewArrayValue(vb), nil
+	case DocumentValue:
+		d := v.V.(Document)
+
+		fields, err := Fields(d)
+		if err != nil {
+			return Value{}, err
+		}
+
+		ref, ok, err := referenceFromDocument(d, fields)
+		if err != nil || ok {
+			return ref, err
+		}
+
+		if len(fields) == 1 && fields[0] == extendedBlobField {
+			b, err := d.GetByField(extendedBlobField)
+			if err != nil {
+				return Value{}, err
+			}
+			if b.Type != TextValue {
+				return Value{}, stringutil.Errorf("invalid %s value: expected text, got %s", extendedBlobField, b.Type)
+			}
+
+			data, err := base64.StdEncoding.DecodeString(b.V.(string))
+			if err != nil {
+				return Value{}, err
+			}
+
+			return NewBlobValue(data), nil
+		}
+
+		fb := NewFieldBuffer()
+		err = d.Iterate(func(f string, v Value) error {
+			v, err := fromExtendedJSON(v)
+			if err != nil {
+				return err
+			}
+
+			fb.Add(f, v)
+			return nil
+		})
+		if err != nil {
+			return Value{}, err
+		}
+
+		return NewDocumentValue(fb), nil
+	}
+
+	return v, nil
+}
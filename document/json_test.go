@@ -0,0 +1,77 @@
+package document_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/document"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONOptions(t *testing.T) {
+	d := document.NewFieldBuffer().
+		Add("b", document.NewIntegerValue(1)).
+		Add("a", document.NewArrayValue(document.NewValueBuffer().
+			Append(document.NewDoubleValue(2)).
+			Append(document.NewBlobValue([]byte("foo"))))).
+		Add("c", document.NewDocumentValue(document.NewFieldBuffer())).
+		Add("d", document.NewArrayValue(document.NewValueBuffer()))
+
+	tests := []struct {
+		name     string
+		opts     document.JSONOptions
+		expected string
+	}{
+		{"Default", document.JSONOptions{}, `{"b": 1, "a": [2, "Zm9v"], "c": {}, "d": []}`},
+		{"Indent", document.JSONOptions{Indent: "  "}, "{\n  \"b\": 1,\n  \"a\": [\n    2,\n    \"Zm9v\"\n  ],\n  \"c\": {},\n  \"d\": []\n}"},
+		{"SortFields", document.JSONOptions{SortFields: true}, `{"a": [2, "Zm9v"], "b": 1, "c": {}, "d": []}`},
+		{"Extended", document.JSONOptions{Extended: true}, `{"b": 1, "a": [2.0, {"$blob": "Zm9v"}], "c": {}, "d": []}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := test.opts.MarshalDocument(d)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, string(data))
+		})
+	}
+
+	t.Run("MarshalJSON", func(t *testing.T) {
+		expected, err := document.MarshalJSON(d)
+		require.NoError(t, err)
+
+		data, err := document.JSONOptions{}.MarshalDocument(d)
+		require.NoError(t, err)
+		require.Equal(t, string(expected), string(data))
+	})
+
+	t.Run("Extended round trip", func(t *testing.T) {
+		opts := document.JSONOptions{Extended: true}
+
+		data, err := opts.MarshalDocument(d)
+		require.NoError(t, err)
+
+		fb, err := opts.UnmarshalDocument(data)
+		require.NoError(t, err)
+
+		v, err := fb.GetByField("a")
+		require.NoError(t, err)
+		a := v.V.(document.Array)
+
+		v, err = a.GetByIndex(0)
+		require.NoError(t, err)
+		require.Equal(t, document.NewDoubleValue(2), v)
+
+		v, err = a.GetByIndex(1)
+		require.NoError(t, err)
+		require.Equal(t, document.NewBlobValue([]byte("foo")), v)
+
+		v, err = fb.GetByField("b")
+		require.NoError(t, err)
+		require.Equal(t, document.NewIntegerValue(1), v)
+	})
+
+	t.Run("Extended invalid blob", func(t *testing.T) {
+		_, err := document.JSONOptions{Extended: true}.UnmarshalDocument([]byte(`{"a": {"$blob": 1}}`))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,86 @@
+package document
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// A JSONStreamReader reads documents from newline-delimited JSON,
+// where each line contains one JSON object. Blank lines are ignored.
+type JSONStreamReader struct {
+	r *bufio.Reader
+	// Options used to decode each line. Only the Extended option is used.
+	Options JSONOptions
+}
+
+// NewJSONStreamReader creates a JSONStreamReader that reads from r.
+func NewJSONStreamReader(r io.Reader) *JSONStreamReader {
+	return &JSONStreamReader{r: bufio.NewReader(r)}
+}
+
+// Iterate decodes each line of the stream and calls fn with the document it contains,
+// until the end of the stream is reached or fn returns an error.
+// Decoding errors report the line of the stream at which they occurred.
+func (s *JSONStreamReader) Iterate(fn func(d Document) error) error {
+	var line int
+	for {
+		data, err := s.r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		eof := err == io.EOF
+		line++
+
+		data = bytes.TrimSpace(data)
+		if len(data) > 0 {
+			fb, err := s.Options.UnmarshalDocument(data)
+			if err != nil {
+				return stringutil.Errorf("line %d: %w", line, err)
+			}
+
+			err = fn(fb)
+			if err != nil {
+				return err
+			}
+		}
+
+		if eof {
+			return nil
+		}
+	}
+}
+
+// A JSONStreamWriter writes documents as newline-delimited JSON.
+type JSONStreamWriter struct {
+	w io.Writer
+	// Options used to encode each document. The Indent option is ignored,
+	// as each document must be written on a single line.
+	Options JSONOptions
+}
+
+// NewJSONStreamWriter creates a JSONStreamWriter that writes to w.
+func NewJSONStreamWriter(w io.Writer) *JSONStreamWriter {
+	return &JSONStreamWriter{w: w}
+}
+
+// WriteDocument writes d to the stream, followed by a newline.
+func (s *JSONStreamWriter) WriteDocument(d Document) error {
+	opts := s.Options
+	opts.Indent = ""
+
+	data, err := opts.MarshalDocument(d)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.w.Write(append(data, '\n'))
+	return err
+}
+
+// WriteAll writes all the documents of the iterator to the stream.
+func (s *JSONStreamWriter) WriteAll(it Iterator) error {
+	return it.Iterate(s.WriteDocument)
+}
@@ -0,0 +1,68 @@
+package document_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/genjidb/genji/document"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONStream(t *testing.T) {
+	t.Run("Read", func(t *testing.T) {
+		r := document.NewJSONStreamReader(strings.NewReader("{\"a\": 1}\n\n  {\"a\": 2, \"b\": [true]}\r\n{\"a\": 3}"))
+
+		var docs []string
+		err := r.Iterate(func(d document.Document) error {
+			data, err := document.MarshalJSON(d)
+			require.NoError(t, err)
+			docs = append(docs, string(data))
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{`{"a": 1}`, `{"a": 2, "b": [true]}`, `{"a": 3}`}, docs)
+	})
+
+	t.Run("Read / Invalid line", func(t *testing.T) {
+		r := document.NewJSONStreamReader(strings.NewReader("{\"a\": 1}\n{\"a\": \n"))
+
+		err := r.Iterate(func(d document.Document) error { return nil })
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "line 2: ")
+	})
+
+	t.Run("Write", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := document.NewJSONStreamWriter(&buf)
+		w.Options = document.JSONOptions{Indent: "  ", Extended: true}
+
+		docs := []document.Document{
+			document.NewFieldBuffer().Add("a", document.NewDocumentValue(document.NewFieldBuffer().Add("b", document.NewDoubleValue(1)))),
+			document.NewFieldBuffer().Add("a", document.NewBlobValue([]byte("foo"))),
+		}
+		for _, d := range docs {
+			require.NoError(t, w.WriteDocument(d))
+		}
+		require.Equal(t, "{\"a\": {\"b\": 1.0}}\n{\"a\": {\"$blob\": \"Zm9v\"}}\n", buf.String())
+
+		r := document.NewJSONStreamReader(&buf)
+		r.Options.Extended = true
+
+		var i int
+		err := r.Iterate(func(d document.Document) error {
+			v, err := d.GetByField("a")
+			require.NoError(t, err)
+			expected, err := docs[i].GetByField("a")
+			require.NoError(t, err)
+
+			ok, err := v.IsEqual(expected)
+			require.NoError(t, err)
+			require.True(t, ok)
+			i++
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, i)
+	})
+}
@@ -0,0 +1,74 @@
+package document
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// Fields used to represent references in JSON,
+// following the MongoDB DBRef convention.
+const (
+	referenceTableField = "$ref"
+	referenceKeyField   = "$id"
+)
+
+// A Reference points to a document of a table, identified by its primary key.
+// References are not checked when they are stored: the document they point to
+// may not exist.
+type Reference struct {
+	Table string
+	Key   Value
+}
+
+// NewReferenceValue returns a value of type Reference, pointing
+// to the document of the given table whose primary key is key.
+func NewReferenceValue(table string, key Value) Value {
+	return Value{
+		Type: ReferenceValue,
+		V:    Reference{Table: table, Key: key},
+	}
+}
+
+// String returns the SQL representation of the reference, e.g. ref("users", 10).
+func (r Reference) String() string {
+	return "ref(" + strconv.Quote(r.Table) + ", " + r.Key.String() + ")"
+}
+
+// MarshalJSON encodes the reference as {"$ref": "<table>", "$id": <key>}.
+func (r Reference) MarshalJSON() ([]byte, error) {
+	key, err := r.Key.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`{"` + referenceTableField + `": `)
+	buf.WriteString(strconv.Quote(r.Table))
+	buf.WriteString(`, "` + referenceKeyField + `": `)
+	buf.Write(key)
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// referenceFromDocument returns the reference represented by a document
+// made of the $ref and $id fields, if any.
+func referenceFromDocument(d Document, fields []string) (Value, bool, error) {
+	if len(fields) != 2 || fields[0] != referenceKeyField || fields[1] != referenceTableField {
+		return Value{}, false, nil
+	}
+
+	table, err := d.GetByField(referenceTableField)
+	if err != nil {
+		return Value{}, false, err
+	}
+	if table.Type != TextValue {
+		return Value{}, false, nil
+	}
+
+	key, err := d.GetByField(referenceKeyField)
+	if err != nil {
+		return Value{}, false, err
+	}
+
+	return NewReferenceValue(table.V.(string), key), true, nil
+}
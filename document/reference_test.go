@@ -0,0 +1,73 @@
+package document_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/document"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReference(t *testing.T) {
+	ref := document.NewReferenceValue("users", document.NewIntegerValue(10))
+
+	t.Run("String", func(t *testing.T) {
+		require.Equal(t, `ref("users", 10)`, ref.String())
+		require.Equal(t, `ref("users", "foo")`, document.NewReferenceValue("users", document.NewTextValue("foo")).String())
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		d := document.NewFieldBuffer().Add("a", ref)
+
+		data, err := document.MarshalJSON(d)
+		require.NoError(t, err)
+		require.Equal(t, `{"a": {"$ref": "users", "$id": 10}}`, string(data))
+
+		fb, err := document.JSONOptions{Extended: true}.UnmarshalDocument(data)
+		require.NoError(t, err)
+		v, err := fb.GetByField("a")
+		require.NoError(t, err)
+		require.Equal(t, ref, v)
+
+		fb, err = document.JSONOptions{}.UnmarshalDocument(data)
+		require.NoError(t, err)
+		v, err = fb.GetByField("a")
+		require.NoError(t, err)
+		require.Equal(t, document.DocumentValue, v.Type)
+	})
+
+	t.Run("Compare", func(t *testing.T) {
+		ok, err := ref.IsEqual(document.NewReferenceValue("users", document.NewDoubleValue(10)))
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		ok, err = ref.IsEqual(document.NewReferenceValue("teams", document.NewIntegerValue(10)))
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		ok, err = ref.IsGreaterThan(document.NewReferenceValue("users", document.NewIntegerValue(9)))
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		ok, err = ref.IsEqual(document.NewIntegerValue(10))
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("Encoding", func(t *testing.T) {
+		a, err := ref.MarshalBinary()
+		require.NoError(t, err)
+		b, err := document.NewReferenceValue("users", document.NewIntegerValue(11)).MarshalBinary()
+		require.NoError(t, err)
+		require.Less(t, string(a), string(b))
+	})
+
+	t.Run("Scan", func(t *testing.T) {
+		var r document.Reference
+		require.NoError(t, ref.Scan(&r))
+		require.Equal(t, document.Reference{Table: "users", Key: document.NewIntegerValue(10)}, r)
+
+		v, err := document.NewValue(r)
+		require.NoError(t, err)
+		require.Equal(t, ref, v)
+	})
+}
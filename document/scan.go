@@ -3,6 +3,7 @@
 package document
 
 import (
+	"database/sql"
 	"errors"
 	"reflect"
 	"strings"
@@ -16,6 +17,54 @@ type Scanner interface {
 	ScanDocument(Document) error
 }
 
+// A ScanError is returned when a value of a document or an array
+// cannot be scanned into a Go value.
+type ScanError struct {
+	// Path of the value, relative to the scanned document or array.
+	Path Path
+	Err  error
+}
+
+func (e *ScanError) Error() string {
+	return stringutil.Sprintf("cannot scan %s: %s", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ScanError) Unwrap() error {
+	return e.Err
+}
+
+// wrapScanError prepends the fragment to the path of the error.
+func wrapScanError(err error, f PathFragment) error {
+	if se, ok := err.(*ScanError); ok {
+		se.Path = append(Path{f}, se.Path...)
+		return se
+	}
+
+	return &ScanError{Path: Path{f}, Err: err}
+}
+
+// scanTypeError returns an error describing a type mismatch
+// between v and the Go value.
+func scanTypeError(v Value, ref reflect.Value) error {
+	return stringutil.Errorf("cannot convert %s value %s into Go value of type %s", v.Type, v, ref.Type())
+}
+
+var sqlScannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// sqlScanner returns ref as a sql.Scanner, if it implements it.
+func sqlScanner(ref reflect.Value) (sql.Scanner, bool) {
+	if ref.Kind() == reflect.Ptr && !ref.IsNil() && ref.Type().Implements(sqlScannerType) {
+		return ref.Interface().(sql.Scanner), true
+	}
+
+	if ref.CanAddr() && ref.Addr().Type().Implements(sqlScannerType) {
+		return ref.Addr().Interface().(sql.Scanner), true
+	}
+
+	return nil, false
+}
+
 // Scan each field of the document into the given variables.
 func Scan(d Document, targets ...interface{}) error {
 	var i int
@@ -33,7 +82,12 @@ func Scan(d Document, targets ...interface{}) error {
 			return &ErrUnsupportedType{target, stringutil.Sprintf("Parameter %d is not valid", i)}
 		}
 
-		return scanValue(v, ref)
+		err := scanValue(v, ref)
+		if err != nil {
+			return wrapScanError(err, PathFragment{FieldName: f})
+		}
+
+		return nil
 	})
 }
 
@@ -89,7 +143,7 @@ func structScan(d Document, ref reflect.Value) error {
 		}
 
 		if err := scanValue(v, f); err != nil {
-			return err
+			return wrapScanError(err, PathFragment{FieldName: name})
 		}
 	}
 
@@ -146,14 +200,14 @@ func sliceScan(a Array, ref reflect.Value) error {
 		if k == reflect.Array {
 			err := scanValue(v, sref.Index(i).Addr())
 			if err != nil {
-				return err
+				return wrapScanError(err, PathFragment{ArrayIndex: i})
 			}
 		} else {
 			newV := reflect.New(stp.Elem())
 
 			err := scanValue(v, newV)
 			if err != nil {
-				return err
+				return wrapScanError(err, PathFragment{ArrayIndex: i})
 			}
 
 			sref = reflect.Append(sref, reflect.Indirect(newV))
@@ -204,7 +258,7 @@ func mapScan(d Document, ref reflect.Value) error {
 
 		err := scanValue(v, newV)
 		if err != nil {
-			return err
+			return wrapScanError(err, PathFragment{FieldName: f})
 		}
 
 		ref.SetMapIndex(reflect.ValueOf(f), newV.Elem())
@@ -223,6 +277,10 @@ func scanValue(v Value, ref reflect.Value) error {
 	}
 
 	if v.Type == NullValue {
+		if sc, ok := sqlScanner(ref); ok {
+			return sc.Scan(nil)
+		}
+
 		if ref.Type().Kind() != reflect.Ptr {
 			return nil
 		}
@@ -263,6 +321,17 @@ func scanValue(v Value, ref reflect.Value) error {
 		return nil
 	}
 
+	// types implementing sql.Scanner, like sql.NullString,
+	// are given the Go representation of the value
+	if sc, ok := sqlScanner(ref); ok {
+		switch v.Type {
+		case DocumentValue, ArrayValue, ReferenceValue:
+			return scanTypeError(v, ref)
+		}
+
+		return sc.Scan(v.V)
+	}
+
 	switch ref.Kind() {
 	case reflect.String:
 		v, err := v.CastAsText()
@@ -272,36 +341,36 @@ func scanValue(v Value, ref reflect.Value) error {
 		ref.SetString(string(v.V.(string)))
 		return nil
 	case reflect.Bool:
-		v, err := v.CastAsBool()
+		b, err := v.CastAsBool()
 		if err != nil {
-			return err
+			return scanTypeError(v, ref)
 		}
-		ref.SetBool(v.V.(bool))
+		ref.SetBool(b.V.(bool))
 		return nil
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		v, err := v.CastAsInteger()
+		i, err := v.CastAsInteger()
 		if err != nil {
-			return err
+			return scanTypeError(v, ref)
 		}
-		x := v.V.(int64)
+		x := i.V.(int64)
 		if x < 0 {
 			return stringutil.Errorf("cannot convert value %d into Go value of type %s", x, ref.Type().Name())
 		}
 		ref.SetUint(uint64(x))
 		return nil
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		v, err := v.CastAsInteger()
+		i, err := v.CastAsInteger()
 		if err != nil {
-			return err
+			return scanTypeError(v, ref)
 		}
-		ref.SetInt(v.V.(int64))
+		ref.SetInt(i.V.(int64))
 		return nil
 	case reflect.Float32, reflect.Float64:
-		v, err := v.CastAsDouble()
+		f, err := v.CastAsDouble()
 		if err != nil {
-			return err
+			return scanTypeError(v, ref)
 		}
-		ref.SetFloat(v.V.(float64))
+		ref.SetFloat(f.V.(float64))
 		return nil
 	case reflect.Interface:
 		switch v.Type {
@@ -325,6 +394,11 @@ func scanValue(v Value, ref reflect.Value) error {
 		return nil
 	}
 
+	if v.Type == ReferenceValue && ref.Type() == reflect.TypeOf(Reference{}) {
+		ref.Set(reflect.ValueOf(v.V))
+		return nil
+	}
+
 	// test with supported stdlib types
 	switch ref.Type().String() {
 	case "time.Time":
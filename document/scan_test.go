@@ -2,6 +2,8 @@ package document_test
 
 import (
 	"bytes"
+	"database/sql"
+	"errors"
 	"testing"
 	"time"
 
@@ -248,6 +250,109 @@ func TestScan(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, bar{}, b)
 	})
+
+	t.Run("Nested", func(t *testing.T) {
+		type inner struct {
+			X int
+			Y *string
+		}
+
+		type outer struct {
+			P  *inner
+			S  []inner
+			PS []*inner
+			M  map[string]int
+			MS map[string]inner
+		}
+
+		d := document.NewFieldBuffer().
+			Add("p", document.NewDocumentValue(document.NewFieldBuffer().
+				Add("x", document.NewIntegerValue(1)).
+				Add("y", document.NewTextValue("a")))).
+			Add("s", document.NewArrayValue(document.NewValueBuffer(
+				document.NewDocumentValue(document.NewFieldBuffer().Add("x", document.NewIntegerValue(2))),
+				document.NewDocumentValue(document.NewFieldBuffer().Add("x", document.NewIntegerValue(3))),
+			))).
+			Add("ps", document.NewArrayValue(document.NewValueBuffer(
+				document.NewDocumentValue(document.NewFieldBuffer().Add("x", document.NewIntegerValue(4))),
+			))).
+			Add("m", document.NewDocumentValue(document.NewFieldBuffer().
+				Add("a", document.NewIntegerValue(5)).
+				Add("b", document.NewDoubleValue(6)))).
+			Add("ms", document.NewDocumentValue(document.NewFieldBuffer().
+				Add("k", document.NewDocumentValue(document.NewFieldBuffer().Add("x", document.NewIntegerValue(7))))))
+
+		var o outer
+		err := document.StructScan(d, &o)
+		require.NoError(t, err)
+		require.Equal(t, outer{
+			P:  &inner{X: 1, Y: strPtr("a")},
+			S:  []inner{{X: 2}, {X: 3}},
+			PS: []*inner{{X: 4}},
+			M:  map[string]int{"a": 5, "b": 6},
+			MS: map[string]inner{"k": {X: 7}},
+		}, o)
+	})
+
+	t.Run("sql.Scanner", func(t *testing.T) {
+		type bar struct {
+			S  sql.NullString
+			I  sql.NullInt64
+			F  sql.NullFloat64
+			B  sql.NullBool
+			N  sql.NullString
+			PS *sql.NullString
+		}
+
+		b := bar{N: sql.NullString{String: "x", Valid: true}}
+
+		d := document.NewFieldBuffer().
+			Add("s", document.NewTextValue("foo")).
+			Add("i", document.NewIntegerValue(10)).
+			Add("f", document.NewDoubleValue(1.5)).
+			Add("b", document.NewBoolValue(true)).
+			Add("n", document.NewNullValue()).
+			Add("ps", document.NewTextValue("bar"))
+		err := document.StructScan(d, &b)
+		require.NoError(t, err)
+		require.Equal(t, bar{
+			S:  sql.NullString{String: "foo", Valid: true},
+			I:  sql.NullInt64{Int64: 10, Valid: true},
+			F:  sql.NullFloat64{Float64: 1.5, Valid: true},
+			B:  sql.NullBool{Bool: true, Valid: true},
+			PS: &sql.NullString{String: "bar", Valid: true},
+		}, b)
+
+		var ns sql.NullString
+		err = document.Scan(document.NewFieldBuffer().Add("a", document.NewTextValue("baz")), &ns)
+		require.NoError(t, err)
+		require.Equal(t, sql.NullString{String: "baz", Valid: true}, ns)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		type inner struct {
+			X int
+		}
+
+		type outer struct {
+			S []inner
+		}
+
+		d := document.NewFieldBuffer().
+			Add("s", document.NewArrayValue(document.NewValueBuffer(
+				document.NewDocumentValue(document.NewFieldBuffer().Add("x", document.NewIntegerValue(1))),
+				document.NewDocumentValue(document.NewFieldBuffer().Add("x", document.NewTextValue("foo"))),
+			)))
+
+		var o outer
+		err := document.StructScan(d, &o)
+		require.Error(t, err)
+
+		var se *document.ScanError
+		require.True(t, errors.As(err, &se))
+		require.Equal(t, "s[1].x", se.Path.String())
+		require.Equal(t, `cannot scan s[1].x: cannot convert text value "foo" into Go value of type int`, err.Error())
+	})
 }
 
 type documentScanner struct {
@@ -2,10 +2,11 @@ package document
 
 import (
 	"bytes"
-	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"math"
 	"strconv"
+	"strings"
 
 	"github.com/buger/jsonparser"
 	"github.com/genjidb/genji/internal/binarysort"
@@ -42,6 +43,9 @@ const (
 	// double family: 0xA0 to 0xAF
 	DoubleValue ValueType = 0xA0
 
+	// reference family: 0xB0 to 0xBF
+	ReferenceValue ValueType = 0xB0
+
 	// string family: 0xC0 to 0xCF
 	TextValue ValueType = 0xC0
 
@@ -73,6 +77,8 @@ func (t ValueType) String() string {
 		return "array"
 	case DocumentValue:
 		return "document"
+	case ReferenceValue:
+		return "reference"
 	}
 
 	return ""
@@ -223,38 +229,41 @@ func (v Value) MarshalJSON() ([]byte, error) {
 	case IntegerValue:
 		return strconv.AppendInt(nil, v.V.(int64), 10), nil
 	case DoubleValue:
-		f := v.V.(float64)
-		abs := math.Abs(f)
-		fmt := byte('f')
-		if abs != 0 {
-			if abs < 1e-6 || abs >= 1e21 {
-				fmt = 'e'
-			}
-		}
-
-		// By default the precision is -1 to use the smallest number of digits.
-		// See https://pkg.go.dev/strconv#FormatFloat
-		prec := -1
-
-		return strconv.AppendFloat(nil, v.V.(float64), fmt, prec, 64), nil
+		return appendDouble(nil, v.V.(float64)), nil
 	case TextValue:
 		return []byte(strconv.Quote(v.V.(string))), nil
 	case BlobValue:
-		src := v.V.([]byte)
-		dst := make([]byte, base64.StdEncoding.EncodedLen(len(src))+2)
-		dst[0] = '"'
-		dst[len(dst)-1] = '"'
-		base64.StdEncoding.Encode(dst[1:], src)
-		return dst, nil
+		var buf bytes.Buffer
+		appendBase64(&buf, v.V.([]byte))
+		return buf.Bytes(), nil
 	case ArrayValue:
 		return jsonArray{v.V.(Array)}.MarshalJSON()
 	case DocumentValue:
 		return jsonDocument{v.V.(Document)}.MarshalJSON()
+	case ReferenceValue:
+		return v.V.(Reference).MarshalJSON()
 	default:
 		return nil, stringutil.Errorf("unexpected type: %d", v.Type)
 	}
 }
 
+// appendDouble appends the JSON representation of f to dst.
+func appendDouble(dst []byte, f float64) []byte {
+	abs := math.Abs(f)
+	fmt := byte('f')
+	if abs != 0 {
+		if abs < 1e-6 || abs >= 1e21 {
+			fmt = 'e'
+		}
+	}
+
+	// By default the precision is -1 to use the smallest number of digits.
+	// See https://pkg.go.dev/strconv#FormatFloat
+	prec := -1
+
+	return strconv.AppendFloat(dst, f, fmt, prec, 64)
+}
+
 // String returns a string representation of the value. It implements the fmt.Stringer interface.
 func (v Value) String() string {
 	switch v.Type {
@@ -263,7 +272,9 @@ func (v Value) String() string {
 	case TextValue:
 		return strconv.Quote(v.V.(string))
 	case BlobValue:
-		return stringutil.Sprintf("%v", v.V)
+		return "X'" + strings.ToUpper(hex.EncodeToString(v.V.([]byte))) + "'"
+	case ReferenceValue:
+		return v.V.(Reference).String()
 	}
 
 	d, _ := v.MarshalJSON()
@@ -300,6 +311,13 @@ func (v Value) Append(buf []byte) ([]byte, error) {
 			return nil, err
 		}
 		return buf.Bytes(), nil
+	case ReferenceValue:
+		var buf bytes.Buffer
+		err := NewValueEncoder(&buf).appendReference(v.V.(Reference))
+		if err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
 	}
 
 	return nil, errors.New("cannot encode type " + v.Type.String() + " as key")
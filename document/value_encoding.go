@@ -56,6 +56,8 @@ func (ve *ValueEncoder) appendValue(v Value) error {
 		return ve.appendArray(v.V.(Array))
 	case DocumentValue:
 		return ve.appendDocument(v.V.(Document))
+	case ReferenceValue:
+		return ve.appendReference(v.V.(Reference))
 	}
 
 	ve.buf = ve.buf[:0]
@@ -143,3 +145,25 @@ func (ve *ValueEncoder) appendDocument(d Document) error {
 
 	return ve.append(documentEnd)
 }
+
+// appendReference encodes a reference into a sort-ordered binary representation.
+// References are sorted by table, then by key.
+func (ve *ValueEncoder) appendReference(r Reference) error {
+	var err error
+
+	ve.buf, err = binarysort.AppendBase64(ve.buf[:0], []byte(r.Table))
+	if err != nil {
+		return err
+	}
+	err = ve.append(ve.buf...)
+	if err != nil {
+		return err
+	}
+
+	err = ve.append(documentValueDelim)
+	if err != nil {
+		return err
+	}
+
+	return ve.appendValue(r.Key)
+}
@@ -16,7 +16,7 @@ func TestValueString(t *testing.T) {
 		expected string
 	}{
 		{"null", document.NewNullValue(), "NULL"},
-		{"bytes", document.NewBlobValue([]byte("bar")), "[98 97 114]"},
+		{"bytes", document.NewBlobValue([]byte("bar")), "X'626172'"},
 		{"string", document.NewTextValue("bar"), "\"bar\""},
 		{"bool", document.NewBoolValue(true), "true"},
 		{"int", document.NewIntegerValue(10), "10"},
@@ -9,6 +9,8 @@ func NewValue(x interface{}) (Value, error) {
 		return NewDocumentValue(v), nil
 	case Array:
 		return NewArrayValue(v), nil
+	case Reference:
+		return NewReferenceValue(v.Table, v.Key), nil
 	case int:
 		return NewIntegerValue(int64(v)), nil
 	case bool:
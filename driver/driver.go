@@ -7,6 +7,7 @@ import (
 	"errors"
 	"io"
 	"runtime"
+	"strings"
 	"sync"
 
 	"github.com/genjidb/genji"
@@ -253,6 +254,11 @@ func (s stmt) Close() error {
 
 var errStop = errors.New("stop")
 
+var (
+	_ driver.Rows                           = (*documentStream)(nil)
+	_ driver.RowsColumnTypeDatabaseTypeName = (*documentStream)(nil)
+)
+
 type documentStream struct {
 	res      *genji.Result
 	cancelFn func()
@@ -324,6 +330,18 @@ func (rs *documentStream) Columns() []string {
 	return rs.res.Fields()
 }
 
+// ColumnTypeDatabaseTypeName returns the inferred type of the column, in uppercase,
+// or an empty string if it is unknown.
+// It implements the driver.RowsColumnTypeDatabaseTypeName interface.
+func (rs *documentStream) ColumnTypeDatabaseTypeName(index int) string {
+	columns := rs.res.Columns()
+	if index >= len(columns) || columns[index].Type == 0 {
+		return ""
+	}
+
+	return strings.ToUpper(columns[index].Type.String())
+}
+
 // Close closes the rows iterator.
 func (rs *documentStream) Close() error {
 	rs.cancelFn()
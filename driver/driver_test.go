@@ -148,6 +148,36 @@ func TestDriver(t *testing.T) {
 		require.Equal(t, 10, count)
 	})
 
+	t.Run("Column types", func(t *testing.T) {
+		rows, err := db.Query("SELECT a AS x, CAST(a AS TEXT), c FROM test")
+		require.NoError(t, err)
+		defer rows.Close()
+
+		columns, err := rows.Columns()
+		require.NoError(t, err)
+		require.Equal(t, []string{"x", "CAST(a AS text)", "c"}, columns)
+
+		types, err := rows.ColumnTypes()
+		require.NoError(t, err)
+		require.Len(t, types, 3)
+		require.Equal(t, "", types[0].DatabaseTypeName())
+		require.Equal(t, "TEXT", types[1].DatabaseTypeName())
+		require.Equal(t, "", types[2].DatabaseTypeName())
+
+		var count int
+		var x int
+		var s string
+		var c foo
+		for rows.Next() {
+			err = rows.Scan(&x, &s, Scanner(&c))
+			require.NoError(t, err)
+			require.Equal(t, count, x)
+			count++
+		}
+		require.NoError(t, rows.Err())
+		require.Equal(t, 10, count)
+	})
+
 	t.Run("Params", func(t *testing.T) {
 		rows, err := db.Query("SELECT a FROM test WHERE a = ?", 5)
 		require.NoError(t, err)
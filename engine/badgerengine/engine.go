@@ -4,11 +4,19 @@ package badgerengine
 import (
 	"bytes"
 	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/dgraph-io/badger/v3"
 	"github.com/genjidb/genji/engine"
 )
 
+// ErrPartiallyCommitted is returned by Rollback when the transaction was split
+// because of its size: the changes made before the last split were committed.
+var ErrPartiallyCommitted = errors.New("transaction was split, only the changes made since the last split were rolled back")
+
 const (
 	separator   byte = 0x1F
 	storeKey         = "__genji.store"
@@ -18,6 +26,46 @@ const (
 // Engine represents a Badger engine.
 type Engine struct {
 	DB *badger.DB
+
+	// Number of values prefetched by iterators. If zero, Badger's default is used.
+	PrefetchSize int
+
+	// Maximum duration a commit waits for other transactions to commit before
+	// syncing the value log, when the engine is in engine.SyncFull mode.
+	// Transactions committing concurrently are synced together in a single group commit,
+	// which improves the throughput of small writes at the cost of their latency.
+	// Commits also join the group of the commits arriving while a sync is running.
+	// If zero, a commit doesn't wait for others to join its group.
+	GroupCommitLatency time.Duration
+
+	// If true, writable transactions exceeding the size Badger allows are split:
+	// the changes made so far are committed and the transaction continues in a new
+	// Badger transaction, which lets bulk inserts and deletes of any size succeed.
+	// A split transaction is no longer atomic nor isolated: its chunks are visible
+	// to other transactions as soon as they are committed, and rolling it back only
+	// discards the changes made since the last split, in which case Rollback
+	// returns ErrPartiallyCommitted.
+	// If false, writes exceeding the size fail with engine.ErrTransactionTooLarge.
+	SplitLargeTransactions bool
+
+	// Ratio of the space of a value log file used by overwritten or deleted values
+	// above which RunGC rewrites it. If zero, DefaultGCDiscardRatio is used.
+	GCDiscardRatio float64
+
+	group syncGroup
+
+	// write volume of the transactions committed since the last garbage collection.
+	written int64
+
+	gcMu    sync.Mutex
+	gcStats engine.GCStats
+	// set when the garbage collection is scheduled by ScheduleGC.
+	stop chan struct{}
+	done chan struct{}
+
+	mu sync.Mutex
+	// synchronous mode set by SetSynchronous, empty for engine.SyncNormal.
+	synchronous string
 }
 
 // NewEngine creates a Badger engine. It takes the same argument as Badger's Open function.
@@ -32,6 +80,44 @@ func NewEngine(opt badger.Options) (*Engine, error) {
 	}, nil
 }
 
+// LowMemoryPrefetchSize is the number of values prefetched by the iterators
+// of the engines created by NewLowMemoryEngine.
+const LowMemoryPrefetchSize = 10
+
+// LowMemoryOptions returns Badger options for devices with little memory,
+// like ARM boards. Badger's default options can use several hundred megabytes
+// of memory for memtables and caches, while these keep them under a few dozen megabytes,
+// at the cost of more frequent flushes and compactions and of slower reads.
+// Values larger than 256 bytes are stored in the value log, which keeps the LSM tree small.
+// Since Badger limits the size of a transaction to a fraction of the memtable size,
+// transactions writing more than about 2MB of keys and small values fail with badger.ErrTxnTooBig.
+// See BenchmarkBadgerEngineLowMemoryStorePut and BenchmarkBadgerEngineLowMemoryTableScan
+// to compare their performance with the default options.
+func LowMemoryOptions(path string) badger.Options {
+	return badger.DefaultOptions(path).
+		WithMemTableSize(16 << 20).
+		WithNumMemtables(2).
+		WithNumLevelZeroTables(2).
+		WithNumLevelZeroTablesStall(4).
+		WithNumCompactors(2).
+		WithBlockCacheSize(16 << 20).
+		WithIndexCacheSize(8 << 20).
+		WithValueLogFileSize(64 << 20).
+		WithValueThreshold(256)
+}
+
+// NewLowMemoryEngine creates a Badger engine at the given path using LowMemoryOptions,
+// whose iterators only prefetch LowMemoryPrefetchSize values.
+func NewLowMemoryEngine(path string) (*Engine, error) {
+	ng, err := NewEngine(LowMemoryOptions(path))
+	if err != nil {
+		return nil, err
+	}
+
+	ng.PrefetchSize = LowMemoryPrefetchSize
+	return ng, nil
+}
+
 // Begin creates a transaction using Badger's transaction API.
 func (e *Engine) Begin(ctx context.Context, opts engine.TxOptions) (engine.Transaction, error) {
 	select {
@@ -42,16 +128,45 @@ func (e *Engine) Begin(ctx context.Context, opts engine.TxOptions) (engine.Trans
 
 	tx := e.DB.NewTransaction(opts.Writable)
 
+	e.mu.Lock()
+	syncFull := e.synchronous == engine.SyncFull
+	e.mu.Unlock()
+
 	return &Transaction{
 		ctx:      ctx,
 		ng:       e,
 		tx:       tx,
 		writable: opts.Writable,
+		syncFull: syncFull,
 	}, nil
 }
 
+// Isolation returns engine.IsolationSerializable: Badger implements serializable
+// snapshot isolation, and fails to commit transactions that read keys written
+// concurrently with engine.ErrConflict.
+// It implements the engine.IsolationReporter interface.
+func (e *Engine) Isolation() string {
+	return engine.IsolationSerializable
+}
+
+// SetSynchronous sets the synchronous mode of the transactions begun after this call.
+// With engine.SyncFull, the value log is synced after every commit, in group commits
+// configured by GroupCommitLatency, unless the engine was opened with SyncWrites,
+// in which case Badger already does it. engine.SyncOff behaves like engine.SyncNormal:
+// Badger never syncs commits unless it is opened with SyncWrites.
+// It implements the engine.Synchronizer interface.
+func (e *Engine) SetSynchronous(mode string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.synchronous = mode
+	return nil
+}
+
 // Close the engine and underlying Badger database.
+// It stops the scheduled garbage collection, if any.
 func (e *Engine) Close() error {
+	e.stopGC()
 	return e.DB.Close()
 }
 
@@ -62,6 +177,14 @@ type Transaction struct {
 	tx        *badger.Txn
 	writable  bool
 	discarded bool
+	// if true, the value log is synced after the commit.
+	syncFull bool
+	// true if the transaction was split because of its size.
+	split bool
+	// iterators open in the transaction, reopened after a split.
+	iterators map[*iterator]struct{}
+	// number of bytes written since the transaction began or was split.
+	written int64
 }
 
 // Rollback the transaction. Can be used safely after commit.
@@ -79,6 +202,10 @@ func (t *Transaction) Rollback() error {
 		return t.ctx.Err()
 	default:
 	}
+
+	if t.split {
+		return ErrPartiallyCommitted
+	}
 	return nil
 }
 
@@ -101,7 +228,78 @@ func (t *Transaction) Commit() error {
 
 	t.discarded = true
 
-	return t.tx.Commit()
+	err := t.tx.Commit()
+	if err == nil {
+		atomic.AddInt64(&t.ng.written, t.written)
+	}
+	if err == nil && t.syncFull && !t.ng.DB.Opts().SyncWrites {
+		err = t.ng.group.sync(t.ng.GroupCommitLatency, t.ng.DB.Sync)
+	}
+
+	return convertError(err)
+}
+
+// convertError converts the errors returned by Badger
+// to their engine package counterpart.
+func convertError(err error) error {
+	switch err {
+	case badger.ErrConflict:
+		return engine.ErrConflict
+	case badger.ErrDiscardedTxn:
+		return engine.ErrTransactionDiscarded
+	case badger.ErrReadOnlyTxn:
+		return engine.ErrTransactionReadOnly
+	case badger.ErrTxnTooBig:
+		return engine.ErrTransactionTooLarge
+	}
+
+	return err
+}
+
+// write calls fn with the Badger transaction, which writes an entry of n bytes.
+// If it fails because the transaction is too large and the engine splits large transactions,
+// the transaction is split and fn is called again with the new Badger transaction.
+func (t *Transaction) write(n int, fn func(tx *badger.Txn) error) error {
+	err := fn(t.tx)
+	if err == badger.ErrTxnTooBig && t.ng.SplitLargeTransactions {
+		err = t.splitTx()
+		if err != nil {
+			return err
+		}
+
+		err = fn(t.tx)
+	}
+	if err != nil {
+		return convertError(err)
+	}
+
+	t.written += int64(n)
+	return nil
+}
+
+// splitTx commits the Badger transaction and replaces it with a new one.
+// The open iterators are closed before the commit, as Badger requires,
+// and reopened at the same position afterwards.
+func (t *Transaction) splitTx() error {
+	for it := range t.iterators {
+		it.suspend()
+	}
+
+	err := t.tx.Commit()
+	if err != nil {
+		return convertError(err)
+	}
+
+	atomic.AddInt64(&t.ng.written, t.written)
+	t.written = 0
+	t.split = true
+	t.tx = t.ng.DB.NewTransaction(true)
+
+	for it := range t.iterators {
+		it.resume(t.tx)
+	}
+
+	return nil
 }
 
 func buildStoreKey(name []byte) []byte {
@@ -147,7 +345,7 @@ func (t *Transaction) GetStore(name []byte) (engine.Store, error) {
 	return &Store{
 		ctx:      t.ctx,
 		ng:       t.ng,
-		tx:       t.tx,
+		t:        t,
 		prefix:   pkey,
 		writable: t.writable,
 		name:     name,
@@ -176,7 +374,9 @@ func (t *Transaction) CreateStore(name []byte) error {
 		return err
 	}
 
-	return t.tx.Set(key, nil)
+	return t.write(len(key), func(tx *badger.Txn) error {
+		return tx.Set(key, nil)
+	})
 }
 
 // DropStore deletes the store and all its keys.
@@ -201,10 +401,8 @@ func (t *Transaction) DropStore(name []byte) error {
 		return err
 	}
 
-	err = t.tx.Delete(buildStoreKey([]byte(name)))
-	if err == badger.ErrKeyNotFound {
-		return engine.ErrStoreNotFound
-	}
-
-	return err
+	key := buildStoreKey(name)
+	return t.write(len(key), func(tx *badger.Txn) error {
+		return tx.Delete(key)
+	})
 }
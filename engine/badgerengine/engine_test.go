@@ -1,10 +1,15 @@
 package badgerengine_test
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/dgraph-io/badger/v3"
 	"github.com/genjidb/genji/engine"
@@ -25,10 +30,259 @@ func builder(t testing.TB) func() (engine.Engine, func()) {
 	}
 }
 
+func lowMemoryBuilder(t testing.TB) func() (engine.Engine, func()) {
+	return func() (engine.Engine, func()) {
+		dir, cleanup := tempDir(t)
+
+		opts := badgerengine.LowMemoryOptions(filepath.Join(dir, "badger"))
+		opts.Logger = nil
+
+		ng, err := badgerengine.NewEngine(opts)
+		require.NoError(t, err)
+		ng.PrefetchSize = badgerengine.LowMemoryPrefetchSize
+		return ng, cleanup
+	}
+}
+
 func TestBadgerEngine(t *testing.T) {
 	enginetest.TestSuite(t, builder(t))
 }
 
+func TestBadgerEngineLowMemory(t *testing.T) {
+	enginetest.TestSuite(t, lowMemoryBuilder(t))
+}
+
+func TestBadgerEngineConflict(t *testing.T) {
+	ng, cleanup := builder(t)()
+	defer cleanup()
+	defer ng.Close()
+
+	ctx := context.Background()
+
+	tx, err := ng.Begin(ctx, engine.TxOptions{Writable: true})
+	require.NoError(t, err)
+	err = tx.CreateStore([]byte("test"))
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	tx1, err := ng.Begin(ctx, engine.TxOptions{Writable: true})
+	require.NoError(t, err)
+	defer tx1.Rollback()
+	tx2, err := ng.Begin(ctx, engine.TxOptions{Writable: true})
+	require.NoError(t, err)
+	defer tx2.Rollback()
+
+	st1, err := tx1.GetStore([]byte("test"))
+	require.NoError(t, err)
+	_, err = st1.Get([]byte("foo"))
+	require.Equal(t, engine.ErrKeyNotFound, err)
+	require.NoError(t, st1.Put([]byte("foo"), []byte("1")))
+
+	st2, err := tx2.GetStore([]byte("test"))
+	require.NoError(t, err)
+	require.NoError(t, st2.Put([]byte("foo"), []byte("2")))
+	require.NoError(t, tx2.Commit())
+
+	require.Equal(t, engine.ErrConflict, tx1.Commit())
+}
+
+func TestBadgerEngineGroupCommit(t *testing.T) {
+	ng, cleanup := builder(t)()
+	defer cleanup()
+	defer ng.Close()
+
+	ng.(*badgerengine.Engine).GroupCommitLatency = 10 * time.Millisecond
+	require.NoError(t, engine.SetSynchronous(ng, engine.SyncFull))
+
+	ctx := context.Background()
+
+	tx, err := ng.Begin(ctx, engine.TxOptions{Writable: true})
+	require.NoError(t, err)
+	require.NoError(t, tx.CreateStore([]byte("test")))
+	require.NoError(t, tx.Commit())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			tx, err := ng.Begin(ctx, engine.TxOptions{Writable: true})
+			require.NoError(t, err)
+			defer tx.Rollback()
+
+			st, err := tx.GetStore([]byte("test"))
+			require.NoError(t, err)
+			require.NoError(t, st.Put([]byte{byte(i)}, []byte("v")))
+			require.NoError(t, tx.Commit())
+		}(i)
+	}
+	wg.Wait()
+
+	tx, err = ng.Begin(ctx, engine.TxOptions{})
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	st, err := tx.GetStore([]byte("test"))
+	require.NoError(t, err)
+	for i := 0; i < 20; i++ {
+		_, err = st.Get([]byte{byte(i)})
+		require.NoError(t, err)
+	}
+}
+
+func TestBadgerEngineGC(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	// small values log files and threshold, so that overwritten values
+	// are stored in files the garbage collection can rewrite
+	opts := badger.DefaultOptions(filepath.Join(dir, "badger")).
+		WithValueThreshold(64).
+		WithValueLogFileSize(1 << 20)
+	opts.Logger = nil
+
+	ng, err := badgerengine.NewEngine(opts)
+	require.NoError(t, err)
+	defer ng.Close()
+
+	ctx := context.Background()
+	write := func() {
+		tx, err := ng.Begin(ctx, engine.TxOptions{Writable: true})
+		require.NoError(t, err)
+		defer tx.Rollback()
+
+		st, err := tx.GetStore([]byte("a"))
+		if err == engine.ErrStoreNotFound {
+			require.NoError(t, tx.CreateStore([]byte("a")))
+			st, err = tx.GetStore([]byte("a"))
+		}
+		require.NoError(t, err)
+
+		for i := 0; i < 100; i++ {
+			require.NoError(t, st.Put([]byte(fmt.Sprintf("%04d", i)), bytes.Repeat([]byte("v"), 1024)))
+		}
+		require.NoError(t, tx.Commit())
+	}
+
+	write()
+	stats, err := ng.GCStats()
+	require.NoError(t, err)
+	require.Greater(t, stats.Size, int64(0))
+	require.EqualValues(t, -1, stats.Reclaimable)
+	require.Greater(t, stats.Written, int64(100*1024))
+
+	require.NoError(t, ng.RunGC())
+	stats, err = ng.GCStats()
+	require.NoError(t, err)
+	require.EqualValues(t, 1, stats.Runs)
+	require.Zero(t, stats.Written)
+	require.False(t, stats.LastRun.IsZero())
+
+	t.Run("Scheduled", func(t *testing.T) {
+		require.NoError(t, ng.ScheduleGC(10*time.Millisecond, 50<<10))
+		require.Error(t, ng.ScheduleGC(10*time.Millisecond, 50<<10))
+
+		write()
+		require.Eventually(t, func() bool {
+			stats, err := ng.GCStats()
+			require.NoError(t, err)
+			return stats.Runs == 2
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestBadgerEngineLargeTransaction(t *testing.T) {
+	open := func(t *testing.T, split bool) (*badgerengine.Engine, func()) {
+		dir, cleanup := tempDir(t)
+		opts := badger.DefaultOptions(filepath.Join(dir, "badger")).WithMemTableSize(1 << 20)
+		opts.Logger = nil
+
+		ng, err := badgerengine.NewEngine(opts)
+		require.NoError(t, err)
+		ng.SplitLargeTransactions = split
+		return ng, func() {
+			ng.Close()
+			cleanup()
+		}
+	}
+
+	ctx := context.Background()
+	const n = 20000
+	key := func(i int) []byte {
+		return []byte(fmt.Sprintf("%08d", i))
+	}
+	value := bytes.Repeat([]byte("v"), 100)
+
+	t.Run("Too large", func(t *testing.T) {
+		ng, cleanup := open(t, false)
+		defer cleanup()
+
+		tx, err := ng.Begin(ctx, engine.TxOptions{Writable: true})
+		require.NoError(t, err)
+		defer tx.Rollback()
+
+		require.NoError(t, tx.CreateStore([]byte("test")))
+		st, err := tx.GetStore([]byte("test"))
+		require.NoError(t, err)
+
+		for i := 0; i < n; i++ {
+			err = st.Put(key(i), value)
+			if err != nil {
+				break
+			}
+		}
+		require.Equal(t, engine.ErrTransactionTooLarge, err)
+	})
+
+	t.Run("Split", func(t *testing.T) {
+		ng, cleanup := open(t, true)
+		defer cleanup()
+
+		tx, err := ng.Begin(ctx, engine.TxOptions{Writable: true})
+		require.NoError(t, err)
+		require.NoError(t, tx.CreateStore([]byte("test")))
+		st, err := tx.GetStore([]byte("test"))
+		require.NoError(t, err)
+		for i := 0; i < n; i++ {
+			require.NoError(t, st.Put(key(i), value))
+		}
+		require.NoError(t, tx.Commit())
+
+		// deleting while iterating reopens the iterator after every split
+		tx, err = ng.Begin(ctx, engine.TxOptions{Writable: true})
+		require.NoError(t, err)
+		st, err = tx.GetStore([]byte("test"))
+		require.NoError(t, err)
+
+		it := st.Iterator(engine.IteratorOptions{})
+		var deleted int
+		for it.Seek(nil); it.Valid(); it.Next() {
+			if deleted%2 == 0 {
+				require.NoError(t, st.Delete(it.Item().Key()))
+			}
+			deleted++
+		}
+		require.NoError(t, it.Close())
+		require.Equal(t, n, deleted)
+
+		// rolling back keeps the chunks committed by the splits
+		require.Equal(t, badgerengine.ErrPartiallyCommitted, tx.Rollback())
+
+		tx, err = ng.Begin(ctx, engine.TxOptions{Writable: true})
+		require.NoError(t, err)
+		st, err = tx.GetStore([]byte("test"))
+		require.NoError(t, err)
+		_, err = st.Get(key(0))
+		require.Equal(t, engine.ErrKeyNotFound, err)
+		_, err = st.Get(key(1))
+		require.NoError(t, err)
+
+		require.NoError(t, tx.DropStore([]byte("test")))
+		require.NoError(t, tx.Commit())
+	})
+}
+
 func BenchmarkBadgerEngineStorePut(b *testing.B) {
 	enginetest.BenchmarkStorePut(b, builder(b))
 }
@@ -37,6 +291,14 @@ func BenchmarkBadgerEngineTableScan(b *testing.B) {
 	enginetest.BenchmarkStoreScan(b, builder(b))
 }
 
+func BenchmarkBadgerEngineLowMemoryStorePut(b *testing.B) {
+	enginetest.BenchmarkStorePut(b, lowMemoryBuilder(b))
+}
+
+func BenchmarkBadgerEngineLowMemoryTableScan(b *testing.B) {
+	enginetest.BenchmarkStoreScan(b, lowMemoryBuilder(b))
+}
+
 func tempDir(t require.TestingT) (string, func()) {
 	dir, err := ioutil.TempDir("", "genji")
 	require.NoError(t, err)
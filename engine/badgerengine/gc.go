@@ -0,0 +1,161 @@
+package badgerengine
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/genjidb/genji/engine"
+)
+
+// DefaultGCDiscardRatio is the discard ratio used by RunGC when GCDiscardRatio is zero.
+const DefaultGCDiscardRatio = 0.5
+
+// RunGC runs the garbage collection of the value log: Badger rewrites the value log files
+// in which at least GCDiscardRatio of the space is used by overwritten or deleted values,
+// until none is left. It implements the engine.GarbageCollector interface.
+func (e *Engine) RunGC() error {
+	e.gcMu.Lock()
+	defer e.gcMu.Unlock()
+
+	written := atomic.LoadInt64(&e.written)
+	before, err := dirSize(e.DB.Opts().ValueDir, ".vlog")
+	if err != nil {
+		return err
+	}
+
+	ratio := e.GCDiscardRatio
+	if ratio <= 0 {
+		ratio = DefaultGCDiscardRatio
+	}
+
+	for err == nil {
+		err = e.DB.RunValueLogGC(ratio)
+	}
+	if err != badger.ErrNoRewrite && err != badger.ErrGCInMemoryMode {
+		return err
+	}
+
+	after, err := dirSize(e.DB.Opts().ValueDir, ".vlog")
+	if err != nil {
+		return err
+	}
+
+	atomic.AddInt64(&e.written, -written)
+	e.gcStats.Runs++
+	if before > after {
+		e.gcStats.Reclaimed += before - after
+	}
+	e.gcStats.LastRun = time.Now()
+	return nil
+}
+
+// GCStats returns the size of the LSM tree and of the value log, and the number of bytes
+// written by the transactions committed since the last garbage collection.
+// Badger doesn't expose the space used by overwritten or deleted values,
+// the reclaimable space is reported as -1.
+// It implements the engine.GarbageCollector interface.
+func (e *Engine) GCStats() (engine.GCStats, error) {
+	e.gcMu.Lock()
+	defer e.gcMu.Unlock()
+
+	opts := e.DB.Opts()
+	lsm, err := dirSize(opts.Dir, ".sst")
+	if err != nil {
+		return engine.GCStats{}, err
+	}
+	vlog, err := dirSize(opts.ValueDir, ".vlog")
+	if err != nil {
+		return engine.GCStats{}, err
+	}
+
+	stats := e.gcStats
+	stats.Size = lsm + vlog
+	stats.Reclaimable = -1
+	stats.Written = atomic.LoadInt64(&e.written)
+	return stats, nil
+}
+
+// ScheduleGC runs the garbage collection of the value log in the background, every time
+// the transactions committed since the last one wrote at least threshold bytes.
+// Since Badger doesn't report how many of these bytes overwrote or deleted values,
+// the threshold is a write volume: workloads that only insert trigger garbage collections
+// that have nothing to reclaim, which only cost a scan of the discard statistics.
+// The write volume is checked at the given interval. The scheduling stops when the engine is closed.
+func (e *Engine) ScheduleGC(interval time.Duration, threshold int64) error {
+	if interval <= 0 {
+		return errors.New("gc interval must be positive")
+	}
+
+	e.gcMu.Lock()
+	defer e.gcMu.Unlock()
+
+	if e.stop != nil {
+		return errors.New("gc already scheduled")
+	}
+
+	e.stop = make(chan struct{})
+	e.done = make(chan struct{})
+	go e.runGCPeriodically(interval, threshold, e.stop, e.done)
+	return nil
+}
+
+// runGCPeriodically runs the garbage collection when the number of bytes written
+// since the last one reaches threshold.
+func (e *Engine) runGCPeriodically(interval time.Duration, threshold int64, stop, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		if atomic.LoadInt64(&e.written) >= threshold {
+			// a failed garbage collection is retried at the next tick
+			_ = e.RunGC()
+		}
+	}
+}
+
+// stopGC stops the scheduled garbage collection, if any, and waits until it returns.
+func (e *Engine) stopGC() {
+	e.gcMu.Lock()
+	stop, done := e.stop, e.done
+	e.stop = nil
+	e.gcMu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// dirSize returns the total size of the files of dir with the given extension.
+// In-memory databases have no directory and a size of zero.
+func dirSize(dir, ext string) (int64, error) {
+	if dir == "" {
+		return 0, nil
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var size int64
+	for _, f := range files {
+		if filepath.Ext(f.Name()) == ext {
+			size += f.Size()
+		}
+	}
+
+	return size, nil
+}
@@ -0,0 +1,55 @@
+package badgerengine
+
+import (
+	"sync"
+	"time"
+)
+
+// A syncGroup batches the syncs requested by transactions committed concurrently,
+// so that a single sync makes all of them durable.
+// The first commit of a group becomes its leader: it waits for the latency,
+// and for the sync of the previous group to complete, while the commits arriving
+// in the meantime join its group. It then syncs once for the whole group.
+type syncGroup struct {
+	mu sync.Mutex
+	// group waiting to be synced, nil if there is none.
+	pending *syncBatch
+
+	// held while a group is being synced.
+	syncMu sync.Mutex
+}
+
+type syncBatch struct {
+	done chan struct{}
+	err  error
+}
+
+// sync calls fn once for all the callers joining the same group, and returns its error.
+func (g *syncGroup) sync(latency time.Duration, fn func() error) error {
+	g.mu.Lock()
+	if b := g.pending; b != nil {
+		g.mu.Unlock()
+		<-b.done
+		return b.err
+	}
+
+	b := syncBatch{done: make(chan struct{})}
+	g.pending = &b
+	g.mu.Unlock()
+
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+
+	g.syncMu.Lock()
+	defer g.syncMu.Unlock()
+
+	// commits arriving from now on belong to the next group
+	g.mu.Lock()
+	g.pending = nil
+	g.mu.Unlock()
+
+	b.err = fn()
+	close(b.done)
+	return b.err
+}
@@ -0,0 +1,46 @@
+package badgerengine
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncGroup(t *testing.T) {
+	t.Run("Concurrent commits", func(t *testing.T) {
+		var g syncGroup
+		var syncs int32
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				err := g.sync(50*time.Millisecond, func() error {
+					atomic.AddInt32(&syncs, 1)
+					return nil
+				})
+				require.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		require.Less(t, atomic.LoadInt32(&syncs), int32(50))
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		var g syncGroup
+
+		err := g.sync(0, func() error { return errors.New("failed") })
+		require.EqualError(t, err, "failed")
+
+		// the failed group doesn't affect the next one
+		err = g.sync(0, func() error { return nil })
+		require.NoError(t, err)
+	})
+}
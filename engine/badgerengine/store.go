@@ -13,7 +13,7 @@ import (
 type Store struct {
 	ctx      context.Context
 	ng       *Engine
-	tx       *badger.Txn
+	t        *Transaction
 	prefix   []byte
 	writable bool
 	name     []byte
@@ -54,7 +54,10 @@ func (s *Store) Put(k, v []byte) error {
 		return errors.New("cannot store empty value")
 	}
 
-	return s.tx.Set(buildKey(s.prefix, k), v)
+	key := buildKey(s.prefix, k)
+	return s.t.write(len(key)+len(v), func(tx *badger.Txn) error {
+		return tx.Set(key, v)
+	})
 }
 
 // Get returns a value associated with the given key. If not found, returns engine.ErrKeyNotFound.
@@ -65,7 +68,7 @@ func (s *Store) Get(k []byte) ([]byte, error) {
 	default:
 	}
 
-	it, err := s.tx.Get(buildKey(s.prefix, k))
+	it, err := s.t.tx.Get(buildKey(s.prefix, k))
 	if err != nil {
 		if err == badger.ErrKeyNotFound {
 			return nil, engine.ErrKeyNotFound
@@ -90,7 +93,7 @@ func (s *Store) Delete(k []byte) error {
 	}
 
 	key := buildKey(s.prefix, k)
-	_, err := s.tx.Get(key)
+	_, err := s.t.tx.Get(key)
 	if err != nil {
 		if err == badger.ErrKeyNotFound {
 			return engine.ErrKeyNotFound
@@ -99,7 +102,9 @@ func (s *Store) Delete(k []byte) error {
 		return err
 	}
 
-	return s.tx.Delete(key)
+	return s.t.write(len(key), func(tx *badger.Txn) error {
+		return tx.Delete(key)
+	})
 }
 
 // Truncate deletes all the records of the store.
@@ -114,53 +119,107 @@ func (s *Store) Truncate() error {
 		return engine.ErrTransactionReadOnly
 	}
 
-	_, err := s.tx.Get(buildStoreKey(s.name))
+	_, err := s.t.tx.Get(buildStoreKey(s.name))
 	if err == badger.ErrKeyNotFound {
 		return engine.ErrStoreNotFound
 	}
 
-	it := s.tx.NewIterator(badger.DefaultIteratorOptions)
+	// the iterator is reopened if deleting the keys splits the transaction
+	it := s.Iterator(engine.IteratorOptions{KeysOnly: true})
 	defer it.Close()
 
-	prefix := buildStorePrefixKey(s.name)
-	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
-		err = s.tx.Delete(it.Item().Key())
+	for it.Seek(nil); it.Valid(); it.Next() {
+		key := buildKey(s.prefix, it.Item().Key())
+		err = s.t.write(len(key), func(tx *badger.Txn) error {
+			return tx.Delete(key)
+		})
 		if err != nil {
 			return err
 		}
 	}
 
-	return nil
+	return it.Err()
 }
 
-// Iterator uses a Badger iterator with default options.
+// Iterator uses a Badger iterator with default options, except for the
+// prefetch size if the engine sets one.
+// The prefix of the options, if any, is passed to Badger, which allows it
+// to skip the tables that don't contain any key starting with it.
+// If only keys are needed, values are not prefetched.
 // Only one iterator is allowed per read-write transaction.
 func (s *Store) Iterator(opts engine.IteratorOptions) engine.Iterator {
 	prefix := buildKey(s.prefix, nil)
 
 	opt := badger.DefaultIteratorOptions
-	opt.Prefix = prefix
+	opt.Prefix = buildKey(s.prefix, opts.Prefix)
 	opt.Reverse = opts.Reverse
-	it := s.tx.NewIterator(opt)
+	opt.PrefetchValues = !opts.KeysOnly
+	if s.ng.PrefetchSize > 0 {
+		opt.PrefetchSize = s.ng.PrefetchSize
+	}
 
-	return &iterator{
+	it := iterator{
 		ctx:         s.ctx,
+		t:           s.t,
 		storePrefix: s.prefix,
 		prefix:      prefix,
-		it:          it,
+		it:          s.t.tx.NewIterator(opt),
+		badgerOpts:  opt,
 		reverse:     opts.Reverse,
+		opts:        opts,
 		item:        badgerItem{prefix: prefix},
 	}
+
+	if s.t.writable {
+		if s.t.iterators == nil {
+			s.t.iterators = make(map[*iterator]struct{})
+		}
+		s.t.iterators[&it] = struct{}{}
+	}
+
+	return &it
 }
 
 type iterator struct {
 	ctx         context.Context
+	t           *Transaction
 	prefix      []byte
 	storePrefix []byte
 	it          *badger.Iterator
+	badgerOpts  badger.IteratorOptions
 	reverse     bool
+	opts        engine.IteratorOptions
 	item        badgerItem
 	err         error
+
+	// key the iterator was positioned on when the transaction was split.
+	resumeKey []byte
+	// true if the iterator was exhausted when the transaction was split.
+	exhausted bool
+}
+
+// suspend closes the Badger iterator before the transaction is split,
+// remembering its position.
+func (it *iterator) suspend() {
+	it.resumeKey = nil
+	it.exhausted = false
+	if it.it.Valid() {
+		it.resumeKey = it.it.Item().KeyCopy(nil)
+	} else {
+		it.exhausted = true
+	}
+
+	it.it.Close()
+}
+
+// resume opens a Badger iterator in the new transaction, at the position
+// of the iterator when it was suspended. Keys deleted by the committed changes
+// are skipped.
+func (it *iterator) resume(tx *badger.Txn) {
+	it.it = tx.NewIterator(it.badgerOpts)
+	if it.resumeKey != nil {
+		it.it.Seek(it.resumeKey)
+	}
 }
 
 func (it *iterator) Seek(pivot []byte) {
@@ -173,8 +232,12 @@ func (it *iterator) Seek(pivot []byte) {
 
 	var seek []byte
 
+	pivot, exclusive := it.opts.Start(pivot)
+
 	if !it.reverse {
 		seek = buildKey(it.storePrefix, pivot)
+	} else if exclusive {
+		seek = buildKey(it.storePrefix, pivot)
 	} else {
 		// if pivot is nil and reverse is true,
 		// seek the largest key by replacing 0
@@ -187,11 +250,20 @@ func (it *iterator) Seek(pivot []byte) {
 		}
 	}
 
+	it.exhausted = false
 	it.it.Seek(seek)
+
+	if exclusive && it.it.Valid() && bytes.Equal(it.it.Item().Key(), seek) {
+		it.it.Next()
+	}
 }
 
 func (it *iterator) Valid() bool {
-	return it.it.ValidForPrefix(it.prefix) && it.err == nil
+	if it.exhausted || !it.it.ValidForPrefix(it.prefix) || it.err != nil {
+		return false
+	}
+
+	return it.opts.Contains(bytes.TrimPrefix(it.it.Item().Key(), it.prefix))
 }
 
 func (it *iterator) Next() {
@@ -210,6 +282,7 @@ func (it *iterator) Item() engine.Item {
 
 func (it *iterator) Close() error {
 	it.it.Close()
+	delete(it.t.iterators, it)
 	return nil
 }
 
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/binary"
 	"os"
+	"sync"
 
 	"github.com/genjidb/genji/engine"
 	bolt "go.etcd.io/bbolt"
@@ -18,6 +19,12 @@ const (
 // Engine represents a BoltDB engine. Each store is stored in a dedicated bucket.
 type Engine struct {
 	DB *bolt.DB
+
+	mu sync.Mutex
+	// synchronous mode set by SetSynchronous, empty for engine.SyncNormal.
+	synchronous string
+	// value of DB.NoSync when the engine was created, used by engine.SyncNormal.
+	normalNoSync bool
 }
 
 // NewEngine creates a BoltDB engine. It takes the same argument as Bolt's Open function.
@@ -28,7 +35,8 @@ func NewEngine(path string, mode os.FileMode, opts *bolt.Options) (*Engine, erro
 	}
 
 	return &Engine{
-		DB: db,
+		DB:           db,
+		normalNoSync: db.NoSync,
 	}, nil
 }
 
@@ -42,7 +50,22 @@ func (e *Engine) Begin(ctx context.Context, opts engine.TxOptions) (engine.Trans
 
 	tx, err := e.DB.Begin(opts.Writable)
 	if err != nil {
-		return nil, err
+		return nil, convertError(err)
+	}
+
+	// Bolt reads NoSync when committing, and allows a single writable
+	// transaction at a time: it can be changed safely once it has begun.
+	if opts.Writable {
+		e.mu.Lock()
+		switch e.synchronous {
+		case engine.SyncOff:
+			e.DB.NoSync = true
+		case engine.SyncFull:
+			e.DB.NoSync = false
+		default:
+			e.DB.NoSync = e.normalNoSync
+		}
+		e.mu.Unlock()
 	}
 
 	return &Transaction{
@@ -52,6 +75,24 @@ func (e *Engine) Begin(ctx context.Context, opts engine.TxOptions) (engine.Trans
 	}, nil
 }
 
+// Isolation returns engine.IsolationSerializable: Bolt allows a single writable
+// transaction at a time, and read-only transactions read a snapshot.
+// It implements the engine.IsolationReporter interface.
+func (e *Engine) Isolation() string {
+	return engine.IsolationSerializable
+}
+
+// SetSynchronous sets the synchronous mode of the writable transactions begun after this call.
+// With engine.SyncOff, Bolt skips the fsync of every commit.
+// It implements the engine.Synchronizer interface.
+func (e *Engine) SetSynchronous(mode string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.synchronous = mode
+	return nil
+}
+
 // Close the engine and underlying Bolt database.
 func (e *Engine) Close() error {
 	return e.DB.Close()
@@ -70,11 +111,8 @@ type Transaction struct {
 // Rollback the transaction. Can be used safely after commit.
 func (t *Transaction) Rollback() error {
 	err := t.tx.Rollback()
-	if err == bolt.ErrTxClosed {
-		return engine.ErrTransactionDiscarded
-	}
 	if err != nil {
-		return err
+		return convertError(err)
 	}
 
 	select {
@@ -103,10 +141,21 @@ func (t *Transaction) Commit() error {
 		}
 	}
 
-	err := t.tx.Commit()
-	if err == bolt.ErrTxClosed {
+	return convertError(t.tx.Commit())
+}
+
+// convertError converts the errors returned by Bolt
+// to their engine package counterpart.
+// Bolt allows only one read-write transaction at a time,
+// which is why it never returns engine.ErrConflict.
+func convertError(err error) error {
+	switch err {
+	case bolt.ErrTxClosed:
 		return engine.ErrTransactionDiscarded
+	case bolt.ErrTxNotWritable, bolt.ErrDatabaseReadOnly:
+		return engine.ErrTransactionReadOnly
 	}
+
 	return err
 }
 
@@ -1,6 +1,7 @@
 package boltengine_test
 
 import (
+	"context"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -25,6 +26,43 @@ func TestBoltEngine(t *testing.T) {
 	enginetest.TestSuite(t, builder(t))
 }
 
+func TestStoreSize(t *testing.T) {
+	ng, cleanup := builder(t)()
+	defer cleanup()
+	defer ng.Close()
+
+	tx, err := ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	require.NoError(t, tx.CreateStore([]byte("test")))
+	st, err := tx.GetStore([]byte("test"))
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, st.Put([]byte{byte(i)}, []byte("value")))
+	}
+
+	count, size, err := st.(engine.StoreSizer).Size()
+	require.NoError(t, err)
+	require.Equal(t, int64(10), count)
+	require.Equal(t, int64(10*6), size)
+
+	require.NoError(t, tx.Commit())
+
+	// read-only transactions use the statistics of Bolt
+	tx, err = ng.Begin(context.Background(), engine.TxOptions{})
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	st, err = tx.GetStore([]byte("test"))
+	require.NoError(t, err)
+	count, size, err = st.(engine.StoreSizer).Size()
+	require.NoError(t, err)
+	require.Equal(t, int64(10), count)
+	require.GreaterOrEqual(t, size, int64(10*6))
+}
+
 func BenchmarkBoltEngineStorePut(b *testing.B) {
 	enginetest.BenchmarkStorePut(b, builder(b))
 }
@@ -41,3 +79,30 @@ func tempDir(t require.TestingT) (string, func()) {
 		os.RemoveAll(dir)
 	}
 }
+
+func TestSetSynchronous(t *testing.T) {
+	ng, cleanup := builder(t)()
+	defer cleanup()
+	defer ng.Close()
+
+	bng := ng.(*boltengine.Engine)
+
+	for _, test := range []struct {
+		mode   string
+		noSync bool
+	}{
+		{engine.SyncOff, true},
+		{engine.SyncNormal, false},
+		{engine.SyncFull, false},
+	} {
+		require.NoError(t, engine.SetSynchronous(ng, test.mode))
+
+		tx, err := ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+		require.NoError(t, err)
+		require.Equal(t, test.noSync, bng.DB.NoSync, test.mode)
+		require.NoError(t, tx.CreateStore([]byte(test.mode)))
+		require.NoError(t, tx.Commit())
+	}
+
+	require.Error(t, engine.SetSynchronous(ng, "always"))
+}
@@ -34,7 +34,7 @@ func (s *Store) Put(k, v []byte) error {
 		return errors.New("empty key or value")
 	}
 
-	return s.bucket.Put(k, v)
+	return convertError(s.bucket.Put(k, v))
 }
 
 // Get returns a value associated with the given key. If not found, returns engine.ErrKeyNotFound.
@@ -105,11 +105,32 @@ func (s *Store) Truncate() error {
 	return err
 }
 
+// Size returns the number of keys of the bucket and the number of bytes used
+// by its pages, as reported by Bolt. It implements the engine.StoreSizer interface.
+// Bolt doesn't include the uncommitted changes in its statistics: in writable
+// transactions, the size of the keys and values of the bucket is returned instead.
+func (s *Store) Size() (count int64, size int64, err error) {
+	if !s.bucket.Writable() {
+		stats := s.bucket.Stats()
+		return int64(stats.KeyN), int64(stats.BranchInuse + stats.LeafInuse + stats.InlineBucketInuse), nil
+	}
+
+	c := s.bucket.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		count++
+		size += int64(len(k) + len(v))
+	}
+
+	return count, size, nil
+}
+
 // Iterator uses the Bolt bucket cursor.
+// The iteration stops as soon as the cursor moves out of the bounds of the options.
 func (s *Store) Iterator(opts engine.IteratorOptions) engine.Iterator {
 	return &iterator{
 		c:       s.bucket.Cursor(),
 		reverse: opts.Reverse,
+		opts:    opts,
 		ctx:     s.ctx,
 	}
 }
@@ -117,6 +138,7 @@ func (s *Store) Iterator(opts engine.IteratorOptions) engine.Iterator {
 type iterator struct {
 	c       *bolt.Cursor
 	reverse bool
+	opts    engine.IteratorOptions
 	item    boltItem
 	err     error
 	ctx     context.Context
@@ -130,6 +152,8 @@ func (it *iterator) Seek(pivot []byte) {
 	default:
 	}
 
+	pivot, exclusive := it.opts.Start(pivot)
+
 	if !it.reverse {
 		it.item.k, it.item.v = it.c.Seek(pivot)
 		if it.item.v == nil {
@@ -147,15 +171,19 @@ func (it *iterator) Seek(pivot []byte) {
 	}
 
 	it.item.k, it.item.v = it.c.Seek(pivot)
+	// if the pivot is greater than every key, start from the last one
+	if it.item.k == nil {
+		it.item.k, it.item.v = it.c.Last()
+	}
 	if it.item.k != nil {
-		for bytes.Compare(it.item.k, pivot) > 0 || (len(it.item.k) > 0 && len(it.item.v) == 0) {
+		for bytes.Compare(it.item.k, pivot) > 0 || (exclusive && bytes.Equal(it.item.k, pivot)) || (len(it.item.k) > 0 && len(it.item.v) == 0) {
 			it.item.k, it.item.v = it.c.Prev()
 		}
 	}
 }
 
 func (it *iterator) Valid() bool {
-	return it.item.k != nil && it.err == nil
+	return it.item.k != nil && it.err == nil && it.opts.Contains(it.item.k)
 }
 
 func (it *iterator) Next() {
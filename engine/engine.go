@@ -2,8 +2,10 @@
 package engine
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"time"
 )
 
 // Common errors returned by the engine implementations.
@@ -23,6 +25,16 @@ var (
 
 	// ErrKeyNotFound is returned when the targeted key doesn't exist.
 	ErrKeyNotFound = errors.New("key not found")
+
+	// ErrConflict must be returned by Commit when the transaction conflicts with
+	// another one and was not committed. The transaction can then be retried.
+	// Engines that never allow conflicting transactions don't return it.
+	ErrConflict = errors.New("transaction conflict")
+
+	// ErrTransactionTooLarge must be returned by write methods when the transaction
+	// exceeds the size the engine allows. The write is not applied and the transaction
+	// remains usable, which lets the statement that made it fail on its own.
+	ErrTransactionTooLarge = errors.New("transaction is too large")
 )
 
 // An Engine is responsible for storing data.
@@ -63,6 +75,17 @@ type Transaction interface {
 	DropStore(name []byte) error
 }
 
+// A Preparer is a transaction able to ensure it can be committed before being committed.
+// Transactions spanning several engines prepare the transactions of every engine
+// before committing any of them, so that a conflict detected by one of the engines
+// doesn't leave the others committed.
+type Preparer interface {
+	// Prepare checks that the transaction can be committed, e.g. that it doesn't conflict
+	// with other transactions. Once it succeeded, Commit must only fail because of I/O errors.
+	// Only Commit or Rollback are called after Prepare.
+	Prepare() error
+}
+
 // A Store manages key value pairs. It is an abstraction on top of any data structure that can provide
 // random read, random write, and ordered sequential read.
 type Store interface {
@@ -80,12 +103,213 @@ type Store interface {
 	Iterator(opts IteratorOptions) Iterator
 }
 
+// A StoreSizer is a store able to report the space it uses without being read entirely.
+// Stores that don't implement it are measured by iterating over their content.
+type StoreSizer interface {
+	// Size returns the number of key value pairs of the store
+	// and the number of bytes they use in the engine.
+	Size() (count int64, bytes int64, err error)
+}
+
+// Isolation levels of the transactions of an engine, as reported by Isolation.
+const (
+	// Transactions may see the changes of uncommitted transactions. Writable transactions
+	// must not run concurrently with other transactions.
+	IsolationNone = "none"
+	// Transactions read a consistent snapshot of the stores, taken when they begin.
+	// Concurrent writable transactions may commit changes that no serial order could produce.
+	IsolationSnapshot = "snapshot"
+	// Transactions behave as if they ran one after the other. Engines may fail
+	// to commit conflicting transactions with ErrConflict.
+	IsolationSerializable = "serializable"
+	// The engine doesn't report its isolation level.
+	IsolationUnknown = "unknown"
+)
+
+// An IsolationReporter is an engine able to report the isolation level of its transactions.
+type IsolationReporter interface {
+	// Isolation returns one of the isolation levels defined in this package.
+	Isolation() string
+}
+
+// Isolation returns the isolation level of the transactions of ng,
+// or IsolationUnknown if it doesn't implement IsolationReporter.
+func Isolation(ng Engine) string {
+	if r, ok := ng.(IsolationReporter); ok {
+		return r.Isolation()
+	}
+
+	return IsolationUnknown
+}
+
+// Synchronous modes of the commits of an engine, as set by SetSynchronous.
+const (
+	// Commits are not synced to disk, if the engine allows it. Committed changes survive
+	// a crash of the process, but may be lost or corrupted if the operating system crashes.
+	SyncOff = "off"
+	// Commits are synced as configured when the engine was created. It is the default mode.
+	SyncNormal = "normal"
+	// Every commit is synced to disk before Commit returns.
+	SyncFull = "full"
+)
+
+// A Synchronizer is an engine whose commits can be synced to disk more or less often.
+type Synchronizer interface {
+	// SetSynchronous sets the synchronous mode of the transactions begun after this call.
+	// The mode is one of the synchronous modes defined in this package.
+	SetSynchronous(mode string) error
+}
+
+// SetSynchronous sets the synchronous mode of ng. Engines that don't implement Synchronizer
+// only support SyncNormal.
+func SetSynchronous(ng Engine, mode string) error {
+	switch mode {
+	case SyncOff, SyncNormal, SyncFull:
+	default:
+		return errors.New("unknown synchronous mode " + mode)
+	}
+
+	if s, ok := ng.(Synchronizer); ok {
+		return s.SetSynchronous(mode)
+	}
+
+	if mode != SyncNormal {
+		return errors.New("engine doesn't support synchronous mode " + mode)
+	}
+
+	return nil
+}
+
+// GCStats reports the space used by an engine and the effect of its garbage collection.
+type GCStats struct {
+	// Number of bytes used by the engine on disk.
+	Size int64
+	// Number of bytes used by overwritten or deleted values that a garbage collection
+	// could reclaim, or -1 if the engine cannot measure it.
+	Reclaimable int64
+	// Number of bytes written since the engine was opened or since the last
+	// garbage collection. Engines unable to measure the reclaimable space
+	// use it to decide when to run their garbage collection.
+	Written int64
+	// Number of garbage collections run since the engine was opened.
+	Runs int64
+	// Number of bytes reclaimed by these garbage collections.
+	Reclaimed int64
+	// Time at which the last garbage collection completed, zero if none did.
+	LastRun time.Time
+}
+
+// A GarbageCollector is an engine keeping overwritten or deleted values on disk
+// until a garbage collection reclaims their space.
+type GarbageCollector interface {
+	// RunGC reclaims the space used by overwritten or deleted values.
+	RunGC() error
+	// GCStats returns the space used by the engine and the effect of its garbage collections.
+	GCStats() (GCStats, error)
+}
+
+// RunGC runs the garbage collection of ng. It does nothing if ng doesn't
+// implement GarbageCollector.
+func RunGC(ng Engine) error {
+	if gc, ok := ng.(GarbageCollector); ok {
+		return gc.RunGC()
+	}
+
+	return nil
+}
+
+// ReadGCStats returns the garbage collection statistics of ng, or zero
+// statistics if it doesn't implement GarbageCollector.
+func ReadGCStats(ng Engine) (GCStats, error) {
+	if gc, ok := ng.(GarbageCollector); ok {
+		return gc.GCStats()
+	}
+
+	return GCStats{}, nil
+}
+
 // IteratorOptions is used to configure an iterator upon creation.
 type IteratorOptions struct {
 	Reverse bool
+
+	// If set, keys lower than LowerBound are never visited.
+	LowerBound []byte
+	// If set, keys greater than or equal to UpperBound are never visited.
+	UpperBound []byte
+	// If set, only keys starting with Prefix are visited.
+	Prefix []byte
+
+	// If set, the values are unlikely to be read. Engines may use it to avoid
+	// prefetching them, but ValueCopy must still return them.
+	KeysOnly bool
+}
+
+// Contains reports whether k is within the bounds and the prefix of the options.
+// Iterators must become invalid as soon as they reach a key for which it returns false.
+func (o *IteratorOptions) Contains(k []byte) bool {
+	if o.LowerBound != nil && bytes.Compare(k, o.LowerBound) < 0 {
+		return false
+	}
+	if o.UpperBound != nil && bytes.Compare(k, o.UpperBound) >= 0 {
+		return false
+	}
+
+	return bytes.HasPrefix(k, o.Prefix)
+}
+
+// Start returns the key from which an iterator created with these options
+// must start when Seek is called with pivot.
+// In ascending order, the iterator must move to the first key greater than or equal to start,
+// and start is empty if the iteration begins at the start of the store.
+// In reverse order, the iterator must move to the last key lower than or equal to start,
+// or strictly lower than start if exclusive is true, and start is empty if the iteration
+// begins at the end of the store.
+func (o *IteratorOptions) Start(pivot []byte) (start []byte, exclusive bool) {
+	if !o.Reverse {
+		start = pivot
+		if bytes.Compare(o.LowerBound, start) > 0 {
+			start = o.LowerBound
+		}
+		if bytes.Compare(o.Prefix, start) > 0 {
+			start = o.Prefix
+		}
+
+		return start, false
+	}
+
+	limit := o.UpperBound
+	if o.Prefix != nil {
+		if end := PrefixEnd(o.Prefix); end != nil && (limit == nil || bytes.Compare(end, limit) < 0) {
+			limit = end
+		}
+	}
+
+	if limit == nil || (len(pivot) > 0 && bytes.Compare(pivot, limit) < 0) {
+		return pivot, false
+	}
+
+	return limit, true
+}
+
+// PrefixEnd returns the smallest key greater than every key starting with prefix,
+// or nil if there is none. It can be used as an upper bound to iterate over
+// every key starting with prefix.
+func PrefixEnd(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] != 0xFF {
+			end := append([]byte{}, prefix[:i+1]...)
+			end[i]++
+			return end
+		}
+	}
+
+	return nil
 }
 
 // An Iterator iterates on keys of a store in lexicographic order.
+// Statements like UPDATE or DELETE write to the store while iterating over it:
+// replacing or deleting the current key must not invalidate the iterator,
+// and every key present when the iteration started must be visited exactly once.
 type Iterator interface {
 	// Seek moves the iterator to the selected key. If the key doesn't exist, it must move to the
 	// next smallest key greater than k.
@@ -5,6 +5,7 @@ package enginetest
 import (
 	"bytes"
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/genjidb/genji"
@@ -859,6 +860,52 @@ func TestStoreIterator(t *testing.T, builder Builder) {
 		require.Equal(t, it.Item().Key(), k)
 	})
 
+	t.Run("With bounds and prefix, should only iterate over the keys within them", func(t *testing.T) {
+		st, cleanup := storeBuilder(t, builder)
+		defer cleanup()
+
+		for i := 1; i <= 5; i++ {
+			for j := 1; j <= 5; j++ {
+				err := st.Put([]byte{uint8(i), uint8(j)}, []byte{1})
+				require.NoError(t, err)
+			}
+		}
+
+		tests := []struct {
+			name     string
+			opts     engine.IteratorOptions
+			pivot    []byte
+			expected [][]byte
+		}{
+			{"lower", engine.IteratorOptions{LowerBound: []byte{5, 4}}, nil, [][]byte{{5, 4}, {5, 5}}},
+			{"lower reverse", engine.IteratorOptions{LowerBound: []byte{1, 4}, Reverse: true}, []byte{2, 1}, [][]byte{{2, 1}, {1, 5}, {1, 4}}},
+			{"upper", engine.IteratorOptions{UpperBound: []byte{1, 3}}, nil, [][]byte{{1, 1}, {1, 2}}},
+			{"upper reverse", engine.IteratorOptions{UpperBound: []byte{1, 4}, Reverse: true}, nil, [][]byte{{1, 3}, {1, 2}, {1, 1}}},
+			{"upper reverse with pivot", engine.IteratorOptions{LowerBound: []byte{5}, UpperBound: []byte{6}, Reverse: true}, []byte{6, 1}, [][]byte{{5, 5}, {5, 4}, {5, 3}, {5, 2}, {5, 1}}},
+			{"bounds", engine.IteratorOptions{LowerBound: []byte{2, 4}, UpperBound: []byte{3, 2}}, nil, [][]byte{{2, 4}, {2, 5}, {3, 1}}},
+			{"bounds reverse", engine.IteratorOptions{LowerBound: []byte{2, 4}, UpperBound: []byte{3, 2}, Reverse: true}, nil, [][]byte{{3, 1}, {2, 5}, {2, 4}}},
+			{"prefix", engine.IteratorOptions{Prefix: []byte{3}}, nil, [][]byte{{3, 1}, {3, 2}, {3, 3}, {3, 4}, {3, 5}}},
+			{"prefix with pivot", engine.IteratorOptions{Prefix: []byte{3}}, []byte{3, 4}, [][]byte{{3, 4}, {3, 5}}},
+			{"prefix reverse", engine.IteratorOptions{Prefix: []byte{3}, Reverse: true}, nil, [][]byte{{3, 5}, {3, 4}, {3, 3}, {3, 2}, {3, 1}}},
+			{"prefix and bounds", engine.IteratorOptions{Prefix: []byte{3}, LowerBound: []byte{3, 2}, UpperBound: []byte{3, 4}}, nil, [][]byte{{3, 2}, {3, 3}}},
+			{"unknown prefix", engine.IteratorOptions{Prefix: []byte{6}}, nil, nil},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				it := st.Iterator(test.opts)
+				defer it.Close()
+
+				var keys [][]byte
+				for it.Seek(test.pivot); it.Valid(); it.Next() {
+					keys = append(keys, append([]byte{}, it.Item().Key()...))
+				}
+				require.NoError(t, it.Err())
+				require.Equal(t, test.expected, keys)
+			})
+		}
+	})
+
 	t.Run("Iterating while deleting current key should work", func(t *testing.T) {
 		st, cleanup := storeBuilder(t, builder)
 		defer cleanup()
@@ -880,6 +927,37 @@ func TestStoreIterator(t *testing.T, builder Builder) {
 			i++
 		}
 	})
+
+	t.Run("Iterating while replacing current key should work", func(t *testing.T) {
+		st, cleanup := storeBuilder(t, builder)
+		defer cleanup()
+
+		for i := 0; i < 200; i++ {
+			err := st.Put([]byte{byte(i)}, []byte{byte(i)})
+			require.NoError(t, err)
+		}
+
+		i := 0
+		it := st.Iterator(engine.IteratorOptions{})
+		defer it.Close()
+
+		for it.Seek(nil); it.Valid(); it.Next() {
+			require.Equal(t, []byte{byte(i)}, it.Item().Key())
+
+			// use a bigger value to force engines to reorganize their data
+			err := st.Put([]byte{byte(i)}, bytes.Repeat([]byte{byte(i)}, 100))
+			require.NoError(t, err)
+			i++
+		}
+		require.NoError(t, it.Err())
+		require.Equal(t, 200, i)
+
+		for i := 0; i < 200; i++ {
+			v, err := st.Get([]byte{byte(i)})
+			require.NoError(t, err)
+			require.Equal(t, bytes.Repeat([]byte{byte(i)}, 100), v)
+		}
+	})
 }
 
 // TestStorePut verifies Put behaviour.
@@ -1225,6 +1303,43 @@ func TestQueries(t *testing.T, builder Builder) {
 		require.JSONEq(t, `[{"a": 5},{"a": 5},{"a": 5},{"a": 5}]`, buf.String())
 	})
 
+	t.Run("UPDATE / Large number of documents", func(t *testing.T) {
+		ng, cleanup := builder()
+		defer cleanup()
+		defer func() {
+			require.NoError(t, ng.Close())
+		}()
+
+		db, err := genji.New(context.Background(), ng)
+		require.NoError(t, err)
+
+		err = db.Exec("CREATE TABLE test; CREATE INDEX on test(a)")
+		require.NoError(t, err)
+
+		err = db.Update(func(tx *genji.Tx) error {
+			for i := 0; i < 1000; i++ {
+				err = tx.Exec("INSERT INTO test (a) VALUES (?)", i)
+				require.NoError(t, err)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		// every document must be updated exactly once,
+		// regardless of the size of the new documents.
+		err = db.Exec("UPDATE test SET a = a + 1000, b = ?", strings.Repeat("b", 100))
+		require.NoError(t, err)
+
+		d, err := db.QueryDocument("SELECT COUNT(*), MIN(a), MAX(a) FROM test WHERE a >= 1000")
+		require.NoError(t, err)
+		var count, min, max int
+		err = document.Scan(d, &count, &min, &max)
+		require.NoError(t, err)
+		require.Equal(t, 1000, count)
+		require.Equal(t, 1000, min)
+		require.Equal(t, 1999, max)
+	})
+
 	t.Run("DELETE", func(t *testing.T) {
 		ng, cleanup := builder()
 		defer cleanup()
@@ -0,0 +1,443 @@
+// Package fileengine implements an engine storing data in a single file,
+// written in pure Go using only the standard library. Unlike the Bolt and Badger engines,
+// it builds for every platform supported by Go, including those without mmap.
+//
+// Data is kept in memory, using the memory engine, and every committed transaction
+// is appended to the file before the commit returns. The file is replayed when
+// the engine is opened and is rewritten as a snapshot when it is closed or compacted.
+// The whole database must fit in memory, and the file must not be opened
+// by several processes at the same time.
+package fileengine
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/engine/memoryengine"
+)
+
+// Engine is an engine storing data in memory and logging
+// every committed transaction to a file.
+type Engine struct {
+	path string
+	mem  *memoryengine.Engine
+
+	mu sync.Mutex
+	f  *os.File
+	// names of the stores, to write snapshots.
+	stores map[string]struct{}
+	// set if a commit couldn't be written to the file,
+	// after which the memory and the file differ.
+	err error
+	// true if transactions were logged since the file was last rewritten.
+	dirty bool
+	// if true, commits are not synced to the file.
+	noSync bool
+}
+
+// NewEngine opens the file at the given path, or creates it if it doesn't exist,
+// and loads its content in memory.
+// If the file ends with a transaction that was only partially written, because
+// of a crash, that transaction is discarded.
+func NewEngine(path string) (*Engine, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0660)
+	if err != nil {
+		return nil, err
+	}
+
+	ng := Engine{
+		path:   path,
+		mem:    memoryengine.NewEngine(),
+		f:      f,
+		stores: make(map[string]struct{}),
+	}
+
+	err = ng.load()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &ng, nil
+}
+
+// load replays the file and truncates it after the last valid record.
+func (ng *Engine) load() error {
+	tx, err := ng.mem.Begin(context.Background(), engine.TxOptions{Writable: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	end, err := readRecords(ng.f, func(ops []op) error {
+		return ng.apply(tx, ops)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	err = ng.f.Truncate(end)
+	if err != nil {
+		return err
+	}
+
+	_, err = ng.f.Seek(end, io.SeekStart)
+	return err
+}
+
+// apply the operations of a record to the memory engine.
+func (ng *Engine) apply(tx engine.Transaction, ops []op) error {
+	for _, o := range ops {
+		var err error
+
+		switch o.kind {
+		case opCreateStore:
+			err = tx.CreateStore(o.store)
+			ng.stores[string(o.store)] = struct{}{}
+		case opDropStore:
+			err = tx.DropStore(o.store)
+			delete(ng.stores, string(o.store))
+		default:
+			var st engine.Store
+			st, err = tx.GetStore(o.store)
+			if err != nil {
+				break
+			}
+
+			switch o.kind {
+			case opPut:
+				err = st.Put(o.key, o.value)
+			case opDelete:
+				err = st.Delete(o.key)
+			case opTruncate:
+				err = st.Truncate()
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Begin creates a transaction.
+func (ng *Engine) Begin(ctx context.Context, opts engine.TxOptions) (engine.Transaction, error) {
+	ng.mu.Lock()
+	err := ng.err
+	ng.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := ng.mem.Begin(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if !opts.Writable {
+		return tx, nil
+	}
+
+	return &transaction{Transaction: tx, ng: ng}, nil
+}
+
+// commit appends the operations of a committed transaction to the file.
+// If it fails, the engine cannot be used anymore.
+func (ng *Engine) commit(ops []op) error {
+	ng.mu.Lock()
+	defer ng.mu.Unlock()
+
+	for _, o := range ops {
+		switch o.kind {
+		case opCreateStore:
+			ng.stores[string(o.store)] = struct{}{}
+		case opDropStore:
+			delete(ng.stores, string(o.store))
+		}
+	}
+
+	err := writeRecord(ng.f, ops)
+	if err == nil && !ng.noSync {
+		err = ng.f.Sync()
+	}
+	if err != nil {
+		ng.err = errors.New("fileengine: failed to write to " + ng.path + ": " + err.Error())
+		return ng.err
+	}
+
+	ng.dirty = true
+	return nil
+}
+
+// Compact rewrites the file so that it only contains the current content
+// of the stores, instead of every transaction committed since it was last compacted.
+// It must not be called while a writable transaction is running.
+func (ng *Engine) Compact() error {
+	ng.mu.Lock()
+	defer ng.mu.Unlock()
+
+	if ng.err != nil {
+		return ng.err
+	}
+
+	return ng.compact()
+}
+
+func (ng *Engine) compact() error {
+	tmp := ng.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0660)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+
+	err = ng.writeSnapshot(f)
+	if err == nil {
+		err = f.Sync()
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	// the new file replaces the old one atomically.
+	// some platforms don't allow renaming over an open file.
+	err = ng.f.Close()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	err = os.Rename(tmp, ng.path)
+	if err != nil {
+		f.Close()
+		ng.f, ng.err = reopen(ng.path)
+		return err
+	}
+
+	ng.f = f
+	ng.dirty = false
+	return nil
+}
+
+// reopen the file at the given path to append records to it.
+func reopen(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND, 0660)
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// size of the records written in snapshots.
+const snapshotRecordSize = 1 << 20
+
+// writeSnapshot writes the content of every store in f, in records
+// of roughly snapshotRecordSize bytes.
+func (ng *Engine) writeSnapshot(f *os.File) error {
+	tx, err := ng.mem.Begin(context.Background(), engine.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	names := make([]string, 0, len(ng.stores))
+	for name := range ng.stores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var ops []op
+	var size int
+	flush := func() error {
+		err := writeRecord(f, ops)
+		ops, size = ops[:0], 0
+		return err
+	}
+
+	for _, name := range names {
+		ops = append(ops, op{kind: opCreateStore, store: []byte(name)})
+
+		st, err := tx.GetStore([]byte(name))
+		if err != nil {
+			return err
+		}
+
+		it := st.Iterator(engine.IteratorOptions{})
+		for it.Seek(nil); it.Valid(); it.Next() {
+			item := it.Item()
+			v, err := item.ValueCopy(nil)
+			if err != nil {
+				it.Close()
+				return err
+			}
+
+			ops = append(ops, op{kind: opPut, store: []byte(name), key: append([]byte{}, item.Key()...), value: v})
+			size += len(name) + len(item.Key()) + len(v)
+			if size >= snapshotRecordSize {
+				err = flush()
+				if err != nil {
+					it.Close()
+					return err
+				}
+			}
+		}
+		err = it.Err()
+		if err != nil {
+			it.Close()
+			return err
+		}
+		err = it.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(ops) > 0 {
+		return flush()
+	}
+
+	return nil
+}
+
+// Isolation returns the isolation level of the memory engine, engine.IsolationNone.
+// It implements the engine.IsolationReporter interface.
+func (ng *Engine) Isolation() string {
+	return ng.mem.Isolation()
+}
+
+// SetSynchronous sets the synchronous mode of the transactions committed after this call.
+// With engine.SyncOff, commits are written to the file without being synced.
+// It implements the engine.Synchronizer interface.
+func (ng *Engine) SetSynchronous(mode string) error {
+	ng.mu.Lock()
+	defer ng.mu.Unlock()
+
+	ng.noSync = mode == engine.SyncOff
+	return nil
+}
+
+// Close the engine. If transactions were committed since it was opened,
+// the file is compacted.
+func (ng *Engine) Close() error {
+	ng.mu.Lock()
+	defer ng.mu.Unlock()
+
+	var err error
+	if ng.err == nil && ng.dirty {
+		err = ng.compact()
+	}
+
+	if ng.f != nil {
+		cerr := ng.f.Close()
+		if err == nil {
+			err = cerr
+		}
+	}
+
+	cerr := ng.mem.Close()
+	if err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// transaction records the changes made by a writable transaction
+// of the memory engine, to log them when it is committed.
+type transaction struct {
+	engine.Transaction
+
+	ng  *Engine
+	ops []op
+}
+
+func (tx *transaction) Commit() error {
+	err := tx.Transaction.Commit()
+	if err != nil {
+		return err
+	}
+
+	if len(tx.ops) == 0 {
+		return nil
+	}
+
+	return tx.ng.commit(tx.ops)
+}
+
+func (tx *transaction) GetStore(name []byte) (engine.Store, error) {
+	st, err := tx.Transaction.GetStore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &store{Store: st, tx: tx, name: append([]byte{}, name...)}, nil
+}
+
+func (tx *transaction) CreateStore(name []byte) error {
+	err := tx.Transaction.CreateStore(name)
+	if err != nil {
+		return err
+	}
+
+	tx.ops = append(tx.ops, op{kind: opCreateStore, store: append([]byte{}, name...)})
+	return nil
+}
+
+func (tx *transaction) DropStore(name []byte) error {
+	err := tx.Transaction.DropStore(name)
+	if err != nil {
+		return err
+	}
+
+	tx.ops = append(tx.ops, op{kind: opDropStore, store: append([]byte{}, name...)})
+	return nil
+}
+
+// store records the changes made to a store of the memory engine.
+type store struct {
+	engine.Store
+
+	tx   *transaction
+	name []byte
+}
+
+func (s *store) Put(k, v []byte) error {
+	err := s.Store.Put(k, v)
+	if err != nil {
+		return err
+	}
+
+	s.tx.ops = append(s.tx.ops, op{kind: opPut, store: s.name, key: append([]byte{}, k...), value: append([]byte{}, v...)})
+	return nil
+}
+
+func (s *store) Delete(k []byte) error {
+	err := s.Store.Delete(k)
+	if err != nil {
+		return err
+	}
+
+	s.tx.ops = append(s.tx.ops, op{kind: opDelete, store: s.name, key: append([]byte{}, k...)})
+	return nil
+}
+
+func (s *store) Truncate() error {
+	err := s.Store.Truncate()
+	if err != nil {
+		return err
+	}
+
+	s.tx.ops = append(s.tx.ops, op{kind: opTruncate, store: s.name})
+	return nil
+}
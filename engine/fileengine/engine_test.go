@@ -0,0 +1,160 @@
+package fileengine_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/engine/enginetest"
+	"github.com/genjidb/genji/engine/fileengine"
+	"github.com/stretchr/testify/require"
+)
+
+func builder(t testing.TB) func() (engine.Engine, func()) {
+	return func() (engine.Engine, func()) {
+		dir, cleanup := tempDir(t)
+		ng, err := fileengine.NewEngine(filepath.Join(dir, "test.db"))
+		require.NoError(t, err)
+		return ng, cleanup
+	}
+}
+
+func TestFileEngine(t *testing.T) {
+	enginetest.TestSuite(t, builder(t))
+}
+
+func TestFileEnginePersistence(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "test.db")
+
+	update := func(ng engine.Engine, fn func(tx engine.Transaction)) {
+		tx, err := ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+		require.NoError(t, err)
+		defer tx.Rollback()
+		fn(tx)
+		require.NoError(t, tx.Commit())
+	}
+
+	get := func(ng engine.Engine, store, key string) ([]byte, error) {
+		tx, err := ng.Begin(context.Background(), engine.TxOptions{})
+		require.NoError(t, err)
+		defer tx.Rollback()
+
+		st, err := tx.GetStore([]byte(store))
+		if err != nil {
+			return nil, err
+		}
+		return st.Get([]byte(key))
+	}
+
+	ng, err := fileengine.NewEngine(path)
+	require.NoError(t, err)
+
+	update(ng, func(tx engine.Transaction) {
+		require.NoError(t, tx.CreateStore([]byte("a")))
+		require.NoError(t, tx.CreateStore([]byte("b")))
+		st, err := tx.GetStore([]byte("a"))
+		require.NoError(t, err)
+		require.NoError(t, st.Put([]byte("foo"), []byte("1")))
+		require.NoError(t, st.Put([]byte("bar"), []byte("2")))
+	})
+	update(ng, func(tx engine.Transaction) {
+		require.NoError(t, tx.DropStore([]byte("b")))
+		st, err := tx.GetStore([]byte("a"))
+		require.NoError(t, err)
+		require.NoError(t, st.Delete([]byte("bar")))
+		require.NoError(t, st.Put([]byte("foo"), []byte("3")))
+	})
+
+	// rolled back transactions are not logged
+	tx, err := ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+	require.NoError(t, err)
+	st, err := tx.GetStore([]byte("a"))
+	require.NoError(t, err)
+	require.NoError(t, st.Put([]byte("baz"), []byte("4")))
+	require.NoError(t, tx.Rollback())
+
+	check := func(ng engine.Engine) {
+		v, err := get(ng, "a", "foo")
+		require.NoError(t, err)
+		require.Equal(t, []byte("3"), v)
+		_, err = get(ng, "a", "bar")
+		require.Equal(t, engine.ErrKeyNotFound, err)
+		_, err = get(ng, "a", "baz")
+		require.Equal(t, engine.ErrKeyNotFound, err)
+		_, err = get(ng, "b", "foo")
+		require.Equal(t, engine.ErrStoreNotFound, err)
+	}
+
+	t.Run("Log", func(t *testing.T) {
+		// copy the log before it is compacted by Close
+		data, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+		logPath := filepath.Join(dir, "log.db")
+		require.NoError(t, ioutil.WriteFile(logPath, data, 0600))
+
+		ng, err := fileengine.NewEngine(logPath)
+		require.NoError(t, err)
+		defer ng.Close()
+		check(ng)
+	})
+
+	t.Run("Partial write", func(t *testing.T) {
+		// simulate a crash while appending the last transaction
+		data, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+		logPath := filepath.Join(dir, "partial.db")
+		require.NoError(t, ioutil.WriteFile(logPath, data[:len(data)-3], 0600))
+
+		ng, err := fileengine.NewEngine(logPath)
+		require.NoError(t, err)
+
+		// only the first transaction is loaded
+		v, err := get(ng, "a", "bar")
+		require.NoError(t, err)
+		require.Equal(t, []byte("2"), v)
+
+		// new transactions are appended after the last valid one
+		update(ng, func(tx engine.Transaction) {
+			st, err := tx.GetStore([]byte("b"))
+			require.NoError(t, err)
+			require.NoError(t, st.Put([]byte("foo"), []byte("5")))
+		})
+		require.NoError(t, ng.Close())
+
+		ng, err = fileengine.NewEngine(logPath)
+		require.NoError(t, err)
+		defer ng.Close()
+		v, err = get(ng, "b", "foo")
+		require.NoError(t, err)
+		require.Equal(t, []byte("5"), v)
+	})
+
+	t.Run("Compaction", func(t *testing.T) {
+		before, err := os.Stat(path)
+		require.NoError(t, err)
+		require.NoError(t, ng.Close())
+
+		after, err := os.Stat(path)
+		require.NoError(t, err)
+		require.Less(t, after.Size(), before.Size())
+
+		ng, err := fileengine.NewEngine(path)
+		require.NoError(t, err)
+		defer ng.Close()
+		check(ng)
+	})
+}
+
+func tempDir(t require.TestingT) (string, func()) {
+	dir, err := ioutil.TempDir("", "genji")
+	require.NoError(t, err)
+
+	return dir, func() {
+		os.RemoveAll(dir)
+	}
+}
@@ -0,0 +1,153 @@
+package fileengine
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+)
+
+// Kinds of operations logged in the file.
+const (
+	opCreateStore byte = iota + 1
+	opDropStore
+	opPut
+	opDelete
+	opTruncate
+)
+
+// op is an operation of a transaction.
+type op struct {
+	kind       byte
+	store      []byte
+	key, value []byte
+}
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+var errInvalidRecord = errors.New("invalid record")
+
+// writeRecord appends the operations of a transaction to f as a single record:
+// the length of the payload and its checksum, as 4 bytes little endian integers,
+// followed by the payload.
+func writeRecord(f *os.File, ops []op) error {
+	payload := make([]byte, 8, 8+64*len(ops))
+	for _, o := range ops {
+		payload = append(payload, o.kind)
+		payload = appendBytes(payload, o.store)
+		switch o.kind {
+		case opPut:
+			payload = appendBytes(payload, o.key)
+			payload = appendBytes(payload, o.value)
+		case opDelete:
+			payload = appendBytes(payload, o.key)
+		}
+	}
+
+	binary.LittleEndian.PutUint32(payload, uint32(len(payload)-8))
+	binary.LittleEndian.PutUint32(payload[4:], crc32.Checksum(payload[8:], crcTable))
+
+	_, err := f.Write(payload)
+	return err
+}
+
+func appendBytes(buf, b []byte) []byte {
+	var n [binary.MaxVarintLen64]byte
+	buf = append(buf, n[:binary.PutUvarint(n[:], uint64(len(b)))]...)
+	return append(buf, b...)
+}
+
+// readRecords reads the records of f from the beginning and calls fn with
+// the operations of each one. It stops at the first record that is incomplete
+// or corrupted and returns its offset, which is the size of the valid part of the file.
+func readRecords(f *os.File, fn func(ops []op) error) (int64, error) {
+	_, err := f.Seek(0, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+
+	r := bufio.NewReader(f)
+	var end int64
+	var header [8]byte
+	for {
+		_, err = io.ReadFull(r, header[:])
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return end, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		size := binary.LittleEndian.Uint32(header[:])
+		payload := make([]byte, size)
+		_, err = io.ReadFull(r, payload)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return end, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if crc32.Checksum(payload, crcTable) != binary.LittleEndian.Uint32(header[4:]) {
+			return end, nil
+		}
+
+		ops, err := decodeOps(payload)
+		if err != nil {
+			return end, nil
+		}
+
+		err = fn(ops)
+		if err != nil {
+			return 0, err
+		}
+
+		end += int64(len(header)) + int64(size)
+	}
+}
+
+// decodeOps decodes the payload of a record.
+func decodeOps(payload []byte) ([]op, error) {
+	var ops []op
+	for len(payload) > 0 {
+		o := op{kind: payload[0]}
+		payload = payload[1:]
+
+		var err error
+		o.store, payload, err = readBytes(payload)
+		if err != nil {
+			return nil, err
+		}
+
+		switch o.kind {
+		case opPut:
+			o.key, payload, err = readBytes(payload)
+			if err == nil {
+				o.value, payload, err = readBytes(payload)
+			}
+		case opDelete:
+			o.key, payload, err = readBytes(payload)
+		case opCreateStore, opDropStore, opTruncate:
+		default:
+			err = errInvalidRecord
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		ops = append(ops, o)
+	}
+
+	return ops, nil
+}
+
+func readBytes(buf []byte) ([]byte, []byte, error) {
+	l, n := binary.Uvarint(buf)
+	if n <= 0 || uint64(len(buf)-n) < l {
+		return nil, nil, errInvalidRecord
+	}
+
+	return buf[n : n+int(l)], buf[n+int(l):], nil
+}
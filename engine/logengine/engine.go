@@ -0,0 +1,720 @@
+// Package logengine implements a log-structured engine, optimized for write-heavy workloads.
+//
+// Every change is appended to segment files, which are never modified afterwards,
+// and the location of the latest value of each key is kept in an in-memory index.
+// Writes only cost a sequential append and reads a single random access.
+// Since overwritten and deleted values keep using space until the segments are compacted,
+// the engine can compact them periodically, by rewriting the live values in a new segment.
+// Keys must fit in memory, values don't need to.
+package logengine
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/engine/memoryengine"
+)
+
+// Default values of the options.
+const (
+	DefaultSegmentSize     = 64 << 20
+	DefaultCompactionRatio = 0.5
+)
+
+// Options of the engine.
+type Options struct {
+	// Size in bytes above which a new segment is started.
+	// Defaults to DefaultSegmentSize.
+	SegmentSize int64
+	// Interval at which the engine checks if it must compact the segments.
+	// Zero disables automatic compaction.
+	CompactionInterval time.Duration
+	// Fraction of the segments used by overwritten or deleted values above which
+	// the automatic compaction happens. Defaults to DefaultCompactionRatio.
+	CompactionRatio float64
+}
+
+// Engine is a log-structured engine.
+type Engine struct {
+	dir  string
+	opts Options
+
+	mu sync.Mutex
+	// signaled when the last running transaction ends.
+	idle *sync.Cond
+	// locations of the values of every store.
+	index *memoryengine.Engine
+	// names of the stores, to compact them.
+	stores map[string]struct{}
+	// segment records are appended to.
+	active   *segment
+	lastTxID uint64
+	// number of transactions running. Compaction waits until there are none.
+	running int
+	// total size of the segments, and size of the records still in use.
+	size, live int64
+	// set if the segments couldn't be written,
+	// after which they don't match the index anymore.
+	err    error
+	closed bool
+	buf    []byte
+	// if true, commits are not synced to the segments.
+	noSync bool
+	// compactions run since the engine was opened.
+	gcStats engine.GCStats
+	// size of the segments after the last compaction.
+	compactedSize int64
+
+	// segments by id, read by transactions concurrently.
+	segMu    sync.RWMutex
+	segments map[uint64]*segment
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewEngine opens the engine stored in the given directory, or creates it.
+// The segments are read to build the index. If the last one ends with a record that
+// was only partially written, because of a crash, that record is discarded.
+func NewEngine(dir string, opts *Options) (*Engine, error) {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.SegmentSize <= 0 {
+		o.SegmentSize = DefaultSegmentSize
+	}
+	if o.CompactionRatio <= 0 {
+		o.CompactionRatio = DefaultCompactionRatio
+	}
+
+	err := os.MkdirAll(dir, 0770)
+	if err != nil {
+		return nil, err
+	}
+
+	ng := Engine{
+		dir:      dir,
+		opts:     o,
+		index:    memoryengine.NewEngine(),
+		stores:   make(map[string]struct{}),
+		segments: make(map[uint64]*segment),
+	}
+	ng.idle = sync.NewCond(&ng.mu)
+
+	err = ng.load()
+	if err != nil {
+		ng.closeSegments()
+		return nil, err
+	}
+
+	if o.CompactionInterval > 0 {
+		ng.stop = make(chan struct{})
+		ng.done = make(chan struct{})
+		go ng.compactPeriodically()
+	}
+
+	return &ng, nil
+}
+
+// load replays the segments to build the index.
+func (ng *Engine) load() error {
+	ids, err := listSegments(ng.dir)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		ids = append(ids, 1)
+	}
+
+	// records of the transactions not committed yet, by transaction id
+	pending := make(map[uint64][]logged)
+
+	for i, id := range ids {
+		seg, err := openSegment(ng.dir, id)
+		if err != nil {
+			return err
+		}
+		ng.segments[id] = seg
+
+		end, err := seg.scan(func(r *record, loc location) error {
+			if r.txID > ng.lastTxID {
+				ng.lastTxID = r.txID
+			}
+
+			if r.kind != recordCommit {
+				pending[r.txID] = append(pending[r.txID], logged{r: *r, loc: loc})
+				return nil
+			}
+
+			records := pending[r.txID]
+			delete(pending, r.txID)
+			err := ng.replay(records)
+			if err == nil && len(records) > 0 && records[0].r.kind == recordReset {
+				// the records framing a compaction are rewritten by the next one
+				ng.live += int64(loc.length)
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		if end < seg.size {
+			if i < len(ids)-1 {
+				return errors.New("logengine: segment " + segmentPath(ng.dir, id) + " is corrupted")
+			}
+
+			err = seg.f.Truncate(end)
+			if err != nil {
+				return err
+			}
+			seg.size = end
+		}
+
+		ng.size += seg.size
+		ng.active = seg
+	}
+
+	return nil
+}
+
+// logged is a record along with its location.
+type logged struct {
+	r   record
+	loc location
+}
+
+// replay the records of a committed transaction on the index.
+func (ng *Engine) replay(records []logged) error {
+	if len(records) > 0 && records[0].r.kind == recordReset {
+		// the previous records were compacted in this transaction
+		ng.index = memoryengine.NewEngine()
+		ng.stores = make(map[string]struct{})
+		ng.live = int64(records[0].loc.length)
+	}
+
+	tx, err := ng.index.Begin(context.Background(), engine.TxOptions{Writable: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, l := range records {
+		var delta int64
+
+		switch l.r.kind {
+		case recordCreateStore:
+			err = tx.CreateStore(l.r.store)
+			ng.stores[string(l.r.store)] = struct{}{}
+			delta = int64(l.loc.length)
+		case recordDropStore:
+			delta, err = dropStore(tx, l.r.store)
+			delete(ng.stores, string(l.r.store))
+		case recordPut, recordDelete, recordTruncate:
+			var st engine.Store
+			st, err = tx.GetStore(l.r.store)
+			if err != nil {
+				break
+			}
+
+			switch l.r.kind {
+			case recordPut:
+				delta, err = putLocation(st, l.r.key, l.loc)
+			case recordDelete:
+				delta, err = deleteKey(st, l.r.key)
+			case recordTruncate:
+				delta, err = truncate(st)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		ng.live += delta
+	}
+
+	return tx.Commit()
+}
+
+// putLocation stores the location of the value of k in the index
+// and returns the change of the size of the live records.
+func putLocation(st engine.Store, k []byte, loc location) (int64, error) {
+	delta := int64(loc.length)
+	if old, err := st.Get(k); err == nil {
+		delta -= int64(decodeLocation(old).length)
+	}
+
+	return delta, st.Put(append([]byte{}, k...), loc.encode())
+}
+
+// deleteKey removes k from the index and returns the change of the size of the live records.
+func deleteKey(st engine.Store, k []byte) (int64, error) {
+	old, err := st.Get(k)
+	if err != nil {
+		return 0, err
+	}
+
+	err = st.Delete(k)
+	if err != nil {
+		return 0, err
+	}
+
+	return -int64(decodeLocation(old).length), nil
+}
+
+// truncate removes every key of the store from the index
+// and returns the change of the size of the live records.
+func truncate(st engine.Store) (int64, error) {
+	var keys [][]byte
+	var delta int64
+
+	it := st.Iterator(engine.IteratorOptions{})
+	for it.Seek(nil); it.Valid(); it.Next() {
+		item := it.Item()
+		v, err := item.ValueCopy(nil)
+		if err != nil {
+			it.Close()
+			return 0, err
+		}
+
+		keys = append(keys, append([]byte{}, item.Key()...))
+		delta -= int64(decodeLocation(v).length)
+	}
+	err := it.Err()
+	if err != nil {
+		it.Close()
+		return 0, err
+	}
+	err = it.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, k := range keys {
+		err = st.Delete(k)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return delta, nil
+}
+
+// dropStore removes the store from the index and returns the change of the size of the live records.
+func dropStore(tx engine.Transaction, name []byte) (int64, error) {
+	st, err := tx.GetStore(name)
+	if err != nil {
+		return 0, err
+	}
+
+	delta, err := truncate(st)
+	if err != nil {
+		return 0, err
+	}
+
+	return delta, tx.DropStore(name)
+}
+
+// Begin creates a transaction. It waits if the segments are being compacted.
+func (ng *Engine) Begin(ctx context.Context, opts engine.TxOptions) (engine.Transaction, error) {
+	ng.mu.Lock()
+	defer ng.mu.Unlock()
+
+	if ng.closed {
+		return nil, errors.New("engine closed")
+	}
+	if ng.err != nil {
+		return nil, ng.err
+	}
+
+	tx, err := ng.index.Begin(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ng.running++
+	t := transaction{Transaction: tx, ng: ng, ctx: ctx, writable: opts.Writable}
+	if opts.Writable {
+		ng.lastTxID++
+		t.id = ng.lastTxID
+	}
+
+	return &t, nil
+}
+
+// end is called when a transaction is committed or rolled back.
+func (ng *Engine) end() {
+	ng.mu.Lock()
+	ng.running--
+	if ng.running == 0 {
+		ng.idle.Broadcast()
+	}
+	ng.mu.Unlock()
+}
+
+// append a record to the active segment, and start a new segment
+// if it is full.
+func (ng *Engine) append(r *record) (location, error) {
+	ng.mu.Lock()
+	defer ng.mu.Unlock()
+
+	if ng.err != nil {
+		return location{}, ng.err
+	}
+
+	ng.buf = r.encode(ng.buf)
+	loc, err := ng.active.append(ng.buf, len(r.value))
+	if err != nil {
+		return location{}, err
+	}
+	ng.size += int64(loc.length)
+
+	if ng.active.size >= ng.opts.SegmentSize {
+		err = ng.rotate()
+		if err != nil {
+			return location{}, err
+		}
+	}
+
+	return loc, nil
+}
+
+// rotate syncs the active segment and starts a new one.
+func (ng *Engine) rotate() error {
+	err := ng.active.f.Sync()
+	if err != nil {
+		return err
+	}
+
+	seg, err := openSegment(ng.dir, ng.active.id+1)
+	if err != nil {
+		return err
+	}
+
+	ng.segMu.Lock()
+	ng.segments[seg.id] = seg
+	ng.segMu.Unlock()
+
+	ng.active = seg
+	return nil
+}
+
+// commit appends the commit record of a transaction and syncs the active segment.
+// If it fails, the engine cannot be used anymore.
+func (ng *Engine) commit(tx *transaction) error {
+	_, err := ng.append(&record{kind: recordCommit, txID: tx.id})
+
+	ng.mu.Lock()
+	defer ng.mu.Unlock()
+
+	if err == nil && !ng.noSync {
+		err = ng.active.f.Sync()
+	}
+	if err != nil {
+		if ng.err == nil {
+			ng.err = errors.New("logengine: failed to write to " + ng.dir + ": " + err.Error())
+		}
+		return ng.err
+	}
+
+	for name, created := range tx.stores {
+		if created {
+			ng.stores[name] = struct{}{}
+		} else {
+			delete(ng.stores, name)
+		}
+	}
+	ng.live += tx.delta
+
+	return nil
+}
+
+// readValue reads the value at the given location.
+func (ng *Engine) readValue(loc location, buf []byte) ([]byte, error) {
+	ng.segMu.RLock()
+	seg, ok := ng.segments[loc.segment]
+	ng.segMu.RUnlock()
+	if !ok {
+		return nil, errors.New("logengine: segment not found")
+	}
+
+	return seg.readValue(loc, buf)
+}
+
+// Compact rewrites the live values in a new segment and removes the others.
+// It waits until no transaction is running, and blocks new ones until it is done.
+// It must not be called while the calling goroutine is running a transaction.
+func (ng *Engine) Compact() error {
+	ng.mu.Lock()
+	defer ng.mu.Unlock()
+
+	for ng.running > 0 {
+		ng.idle.Wait()
+	}
+
+	if ng.closed {
+		return errors.New("engine closed")
+	}
+	if ng.err != nil {
+		return ng.err
+	}
+
+	return ng.compact()
+}
+
+// RunGC compacts the segments. It implements the engine.GarbageCollector interface.
+func (ng *Engine) RunGC() error {
+	return ng.Compact()
+}
+
+// GCStats returns the size of the segments and the space used by overwritten or deleted values.
+// It implements the engine.GarbageCollector interface.
+func (ng *Engine) GCStats() (engine.GCStats, error) {
+	ng.mu.Lock()
+	defer ng.mu.Unlock()
+
+	stats := ng.gcStats
+	stats.Size = ng.size
+	stats.Reclaimable = ng.size - ng.live
+	stats.Written = ng.size - ng.compactedSize
+	return stats, nil
+}
+
+// compactPeriodically compacts the segments when the fraction of space
+// used by obsolete records exceeds the compaction ratio.
+// Compaction is skipped if transactions are running.
+func (ng *Engine) compactPeriodically() {
+	defer close(ng.done)
+
+	ticker := time.NewTicker(ng.opts.CompactionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ng.stop:
+			return
+		case <-ticker.C:
+		}
+
+		ng.mu.Lock()
+		if ng.running == 0 && !ng.closed && ng.err == nil && ng.size > 0 &&
+			float64(ng.size-ng.live)/float64(ng.size) >= ng.opts.CompactionRatio {
+			// the error is reported by the next compaction or transaction
+			_ = ng.compact()
+		}
+		ng.mu.Unlock()
+	}
+}
+
+// compact writes the live values in a new segment, as a single transaction
+// starting with a reset record, then removes the previous segments.
+// If the process crashes before the end of this transaction,
+// it is ignored when the segments are loaded.
+func (ng *Engine) compact() error {
+	seg, err := openSegment(ng.dir, ng.active.id+1)
+	if err != nil {
+		return err
+	}
+
+	err = ng.writeCompacted(seg)
+	if err == nil {
+		err = seg.f.Sync()
+	}
+	if err != nil {
+		seg.f.Close()
+		os.Remove(segmentPath(ng.dir, seg.id))
+		return err
+	}
+
+	// the new segment contains everything, the others can be removed
+	ng.segMu.Lock()
+	old := ng.segments
+	ng.segments = map[uint64]*segment{seg.id: seg}
+	ng.segMu.Unlock()
+
+	for _, s := range old {
+		s.f.Close()
+		os.Remove(segmentPath(ng.dir, s.id))
+	}
+
+	ng.active = seg
+	ng.gcStats.Runs++
+	ng.gcStats.Reclaimed += ng.size - seg.size
+	ng.gcStats.LastRun = time.Now()
+	ng.size = seg.size
+	ng.compactedSize = seg.size
+	return nil
+}
+
+// writeCompacted writes the live values in seg and replaces the index
+// by one pointing to seg.
+func (ng *Engine) writeCompacted(seg *segment) error {
+	ctx := context.Background()
+
+	src, err := ng.index.Begin(ctx, engine.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer src.Rollback()
+
+	index := memoryengine.NewEngine()
+	dst, err := index.Begin(ctx, engine.TxOptions{Writable: true})
+	if err != nil {
+		return err
+	}
+	defer dst.Rollback()
+
+	ng.lastTxID++
+	txID := ng.lastTxID
+	var live int64
+
+	write := func(r *record) (location, error) {
+		ng.buf = r.encode(ng.buf)
+		return seg.append(ng.buf, len(r.value))
+	}
+
+	// the records framing the compaction are counted as live:
+	// the next compaction rewrites them
+	loc, err := write(&record{kind: recordReset, txID: txID})
+	if err != nil {
+		return err
+	}
+	live += int64(loc.length)
+
+	names := make([]string, 0, len(ng.stores))
+	for name := range ng.stores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var value []byte
+	for _, name := range names {
+		loc, err = write(&record{kind: recordCreateStore, txID: txID, store: []byte(name)})
+		if err != nil {
+			return err
+		}
+		live += int64(loc.length)
+
+		err = dst.CreateStore([]byte(name))
+		if err != nil {
+			return err
+		}
+		dstStore, err := dst.GetStore([]byte(name))
+		if err != nil {
+			return err
+		}
+		srcStore, err := src.GetStore([]byte(name))
+		if err != nil {
+			return err
+		}
+
+		it := srcStore.Iterator(engine.IteratorOptions{})
+		for it.Seek(nil); it.Valid(); it.Next() {
+			item := it.Item()
+			v, err := item.ValueCopy(nil)
+			if err == nil {
+				value, err = ng.readValue(decodeLocation(v), value)
+			}
+			if err == nil {
+				loc, err = write(&record{kind: recordPut, txID: txID, store: []byte(name), key: item.Key(), value: value})
+			}
+			if err == nil {
+				err = dstStore.Put(append([]byte{}, item.Key()...), loc.encode())
+			}
+			if err != nil {
+				it.Close()
+				return err
+			}
+
+			live += int64(loc.length)
+		}
+		err = it.Err()
+		if err != nil {
+			it.Close()
+			return err
+		}
+		err = it.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	loc, err = write(&record{kind: recordCommit, txID: txID})
+	if err != nil {
+		return err
+	}
+	live += int64(loc.length)
+
+	err = dst.Commit()
+	if err != nil {
+		return err
+	}
+
+	ng.index = index
+	ng.live = live
+	return nil
+}
+
+// Isolation returns engine.IsolationNone: like the memory engine used for
+// the index, changes are visible as soon as they are made.
+// It implements the engine.IsolationReporter interface.
+func (ng *Engine) Isolation() string {
+	return engine.IsolationNone
+}
+
+// SetSynchronous sets the synchronous mode of the transactions committed after this call.
+// With engine.SyncOff, commit records are appended to the active segment without being synced.
+// Segments are still synced when they are rotated.
+// It implements the engine.Synchronizer interface.
+func (ng *Engine) SetSynchronous(mode string) error {
+	ng.mu.Lock()
+	defer ng.mu.Unlock()
+
+	ng.noSync = mode == engine.SyncOff
+	return nil
+}
+
+// Close the engine. It stops the automatic compaction, if any.
+func (ng *Engine) Close() error {
+	if ng.stop != nil {
+		close(ng.stop)
+		<-ng.done
+		ng.stop = nil
+	}
+
+	ng.mu.Lock()
+	defer ng.mu.Unlock()
+
+	if ng.closed {
+		return errors.New("engine already closed")
+	}
+	ng.closed = true
+
+	err := ng.closeSegments()
+	if cerr := ng.index.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (ng *Engine) closeSegments() error {
+	var err error
+	for _, s := range ng.segments {
+		if ng.err == nil && s == ng.active {
+			if serr := s.f.Sync(); err == nil {
+				err = serr
+			}
+		}
+		if cerr := s.f.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,266 @@
+package logengine_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/engine/enginetest"
+	"github.com/genjidb/genji/engine/logengine"
+	"github.com/stretchr/testify/require"
+)
+
+func builder(t testing.TB) func() (engine.Engine, func()) {
+	return func() (engine.Engine, func()) {
+		dir, cleanup := tempDir(t)
+		ng, err := logengine.NewEngine(dir, &logengine.Options{SegmentSize: 4096})
+		require.NoError(t, err)
+		return ng, cleanup
+	}
+}
+
+func TestLogEngine(t *testing.T) {
+	enginetest.TestSuite(t, builder(t))
+}
+
+func update(t *testing.T, ng engine.Engine, fn func(tx engine.Transaction)) {
+	tx, err := ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+	require.NoError(t, err)
+	defer tx.Rollback()
+	fn(tx)
+	require.NoError(t, tx.Commit())
+}
+
+func get(t *testing.T, ng engine.Engine, store, key string) ([]byte, error) {
+	tx, err := ng.Begin(context.Background(), engine.TxOptions{})
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	st, err := tx.GetStore([]byte(store))
+	if err != nil {
+		return nil, err
+	}
+	return st.Get([]byte(key))
+}
+
+func countSegments(t *testing.T, dir string) int {
+	files, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	require.NoError(t, err)
+	return len(files)
+}
+
+func TestLogEngineReopen(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	ng, err := logengine.NewEngine(dir, &logengine.Options{SegmentSize: 1024})
+	require.NoError(t, err)
+
+	update(t, ng, func(tx engine.Transaction) {
+		require.NoError(t, tx.CreateStore([]byte("a")))
+		require.NoError(t, tx.CreateStore([]byte("b")))
+	})
+	for i := 0; i < 100; i++ {
+		update(t, ng, func(tx engine.Transaction) {
+			st, err := tx.GetStore([]byte("a"))
+			require.NoError(t, err)
+			require.NoError(t, st.Put([]byte(fmt.Sprintf("k%d", i%10)), []byte(fmt.Sprintf("v%d", i))))
+		})
+	}
+	update(t, ng, func(tx engine.Transaction) {
+		require.NoError(t, tx.DropStore([]byte("b")))
+		st, err := tx.GetStore([]byte("a"))
+		require.NoError(t, err)
+		require.NoError(t, st.Delete([]byte("k0")))
+	})
+
+	// rolled back transactions are ignored
+	tx, err := ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+	require.NoError(t, err)
+	st, err := tx.GetStore([]byte("a"))
+	require.NoError(t, err)
+	require.NoError(t, st.Put([]byte("k1"), []byte("rolled back")))
+	require.NoError(t, tx.Rollback())
+
+	require.Greater(t, countSegments(t, dir), 1)
+	require.NoError(t, ng.Close())
+
+	check := func(ng engine.Engine) {
+		_, err := get(t, ng, "a", "k0")
+		require.Equal(t, engine.ErrKeyNotFound, err)
+		for i := 1; i < 10; i++ {
+			v, err := get(t, ng, "a", fmt.Sprintf("k%d", i))
+			require.NoError(t, err)
+			require.Equal(t, fmt.Sprintf("v%d", 90+i), string(v))
+		}
+		_, err = get(t, ng, "b", "k1")
+		require.Equal(t, engine.ErrStoreNotFound, err)
+	}
+
+	ng, err = logengine.NewEngine(dir, &logengine.Options{SegmentSize: 1024})
+	require.NoError(t, err)
+	check(ng)
+
+	t.Run("Compaction", func(t *testing.T) {
+		require.NoError(t, ng.Compact())
+		require.Equal(t, 1, countSegments(t, dir))
+		check(ng)
+
+		// the compacted segment can be loaded
+		require.NoError(t, ng.Close())
+		ng, err = logengine.NewEngine(dir, &logengine.Options{SegmentSize: 1024})
+		require.NoError(t, err)
+		defer ng.Close()
+		check(ng)
+	})
+}
+
+func TestLogEnginePartialWrite(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	ng, err := logengine.NewEngine(dir, nil)
+	require.NoError(t, err)
+
+	update(t, ng, func(tx engine.Transaction) {
+		require.NoError(t, tx.CreateStore([]byte("a")))
+		st, err := tx.GetStore([]byte("a"))
+		require.NoError(t, err)
+		require.NoError(t, st.Put([]byte("foo"), []byte("1")))
+	})
+	update(t, ng, func(tx engine.Transaction) {
+		st, err := tx.GetStore([]byte("a"))
+		require.NoError(t, err)
+		require.NoError(t, st.Put([]byte("foo"), []byte("2")))
+	})
+	require.NoError(t, ng.Close())
+
+	// simulate a crash while writing the commit record of the last transaction
+	files, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	fi, err := os.Stat(files[0])
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(files[0], fi.Size()-2))
+
+	ng, err = logengine.NewEngine(dir, nil)
+	require.NoError(t, err)
+	defer ng.Close()
+
+	v, err := get(t, ng, "a", "foo")
+	require.NoError(t, err)
+	require.Equal(t, []byte("1"), v)
+
+	// new records are appended after the last valid one
+	update(t, ng, func(tx engine.Transaction) {
+		st, err := tx.GetStore([]byte("a"))
+		require.NoError(t, err)
+		require.NoError(t, st.Put([]byte("foo"), []byte("3")))
+	})
+	v, err = get(t, ng, "a", "foo")
+	require.NoError(t, err)
+	require.Equal(t, []byte("3"), v)
+}
+
+func TestLogEngineAutomaticCompaction(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	ng, err := logengine.NewEngine(dir, &logengine.Options{
+		SegmentSize:        512,
+		CompactionInterval: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer ng.Close()
+
+	update(t, ng, func(tx engine.Transaction) {
+		require.NoError(t, tx.CreateStore([]byte("a")))
+	})
+	for i := 0; i < 100; i++ {
+		update(t, ng, func(tx engine.Transaction) {
+			st, err := tx.GetStore([]byte("a"))
+			require.NoError(t, err)
+			require.NoError(t, st.Put([]byte("foo"), []byte(fmt.Sprintf("v%d", i))))
+		})
+	}
+
+	// the overwritten values are removed
+	require.Eventually(t, func() bool {
+		return countSegments(t, dir) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	v, err := get(t, ng, "a", "foo")
+	require.NoError(t, err)
+	require.Equal(t, []byte("v99"), v)
+}
+
+func TestLogEngineGC(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	ng, err := logengine.NewEngine(dir, nil)
+	require.NoError(t, err)
+	defer ng.Close()
+
+	update(t, ng, func(tx engine.Transaction) {
+		require.NoError(t, tx.CreateStore([]byte("a")))
+	})
+	for i := 0; i < 10; i++ {
+		update(t, ng, func(tx engine.Transaction) {
+			st, err := tx.GetStore([]byte("a"))
+			require.NoError(t, err)
+			require.NoError(t, st.Put([]byte("foo"), []byte(fmt.Sprintf("v%d", i))))
+		})
+	}
+
+	before, err := engine.ReadGCStats(ng)
+	require.NoError(t, err)
+	require.Greater(t, before.Reclaimable, int64(0))
+	require.Zero(t, before.Runs)
+
+	require.NoError(t, engine.RunGC(ng))
+
+	after, err := engine.ReadGCStats(ng)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, after.Runs)
+	require.Zero(t, after.Reclaimable)
+	require.Zero(t, after.Written)
+	require.Equal(t, before.Size-after.Size, after.Reclaimed)
+	require.False(t, after.LastRun.IsZero())
+
+	v, err := get(t, ng, "a", "foo")
+	require.NoError(t, err)
+	require.Equal(t, []byte("v9"), v)
+
+	// the compacted segment has nothing to reclaim once reloaded
+	require.NoError(t, ng.Close())
+	ng, err = logengine.NewEngine(dir, nil)
+	require.NoError(t, err)
+	defer ng.Close()
+
+	stats, err := engine.ReadGCStats(ng)
+	require.NoError(t, err)
+	require.Zero(t, stats.Reclaimable)
+}
+
+func BenchmarkLogEngineStorePut(b *testing.B) {
+	enginetest.BenchmarkStorePut(b, builder(b))
+}
+
+func BenchmarkLogEngineStoreScan(b *testing.B) {
+	enginetest.BenchmarkStoreScan(b, builder(b))
+}
+
+func tempDir(t require.TestingT) (string, func()) {
+	dir, err := ioutil.TempDir("", "genji")
+	require.NoError(t, err)
+
+	return dir, func() {
+		os.RemoveAll(dir)
+	}
+}
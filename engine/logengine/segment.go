@@ -0,0 +1,268 @@
+package logengine
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of records written in segments.
+const (
+	recordPut byte = iota + 1
+	recordDelete
+	recordCreateStore
+	recordDropStore
+	recordTruncate
+	// marks the end of a transaction, whose records can then be replayed.
+	recordCommit
+	// starts a compacted segment: every record written before it is obsolete.
+	recordReset
+)
+
+// size of the header of each record: the length of the payload and its checksum.
+const recordHeaderSize = 8
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+var errInvalidRecord = errors.New("invalid record")
+
+// A record is an operation of a transaction, appended to a segment.
+// Its payload contains the kind of the record, the id of the transaction, the
+// name of the store and the key, prefixed by their length, followed by the value.
+type record struct {
+	kind  byte
+	txID  uint64
+	store []byte
+	key   []byte
+	value []byte
+}
+
+func (r *record) encode(buf []byte) []byte {
+	buf = append(buf[:0], make([]byte, recordHeaderSize)...)
+	buf = append(buf, r.kind)
+	buf = appendUvarint(buf, r.txID)
+	buf = appendUvarint(buf, uint64(len(r.store)))
+	buf = append(buf, r.store...)
+	buf = appendUvarint(buf, uint64(len(r.key)))
+	buf = append(buf, r.key...)
+	buf = append(buf, r.value...)
+
+	binary.LittleEndian.PutUint32(buf, uint32(len(buf)-recordHeaderSize))
+	binary.LittleEndian.PutUint32(buf[4:], crc32.Checksum(buf[recordHeaderSize:], crcTable))
+	return buf
+}
+
+func (r *record) decode(payload []byte) error {
+	if len(payload) == 0 {
+		return errInvalidRecord
+	}
+	r.kind = payload[0]
+	payload = payload[1:]
+
+	var n int
+	r.txID, n = binary.Uvarint(payload)
+	if n <= 0 {
+		return errInvalidRecord
+	}
+	payload = payload[n:]
+
+	var err error
+	r.store, payload, err = readBytes(payload)
+	if err != nil {
+		return err
+	}
+	r.key, payload, err = readBytes(payload)
+	if err != nil {
+		return err
+	}
+	r.value = payload
+	return nil
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	var n [binary.MaxVarintLen64]byte
+	return append(buf, n[:binary.PutUvarint(n[:], v)]...)
+}
+
+func readBytes(buf []byte) ([]byte, []byte, error) {
+	l, n := binary.Uvarint(buf)
+	if n <= 0 || uint64(len(buf)-n) < l {
+		return nil, nil, errInvalidRecord
+	}
+
+	return buf[n : n+int(l)], buf[n+int(l):], nil
+}
+
+// A location points to a value stored in a segment.
+// It is stored as the value of the keys in the in-memory index.
+type location struct {
+	segment uint64
+	// offset and length of the whole record.
+	offset uint64
+	length uint64
+	// length of the value, at the end of the record.
+	valueLength uint64
+}
+
+func (l location) encode() []byte {
+	buf := make([]byte, 0, 4*binary.MaxVarintLen64)
+	buf = appendUvarint(buf, l.segment)
+	buf = appendUvarint(buf, l.offset)
+	buf = appendUvarint(buf, l.length)
+	return appendUvarint(buf, l.valueLength)
+}
+
+func decodeLocation(buf []byte) location {
+	var l location
+	var n int
+	l.segment, n = binary.Uvarint(buf)
+	buf = buf[n:]
+	l.offset, n = binary.Uvarint(buf)
+	buf = buf[n:]
+	l.length, n = binary.Uvarint(buf)
+	buf = buf[n:]
+	l.valueLength, _ = binary.Uvarint(buf)
+	return l
+}
+
+// A segment is a file records are appended to.
+// Only the last segment is written, the others are read-only.
+type segment struct {
+	id   uint64
+	f    *os.File
+	size int64
+}
+
+const segmentExt = ".log"
+
+func segmentPath(dir string, id uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%016d%s", id, segmentExt))
+}
+
+// listSegments returns the ids of the segments of dir, in ascending order.
+func listSegments(dir string) ([]uint64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uint64
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+
+		id, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+func openSegment(dir string, id uint64) (*segment, error) {
+	f, err := os.OpenFile(segmentPath(dir, id), os.O_RDWR|os.O_CREATE, 0660)
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &segment{id: id, f: f, size: fi.Size()}, nil
+}
+
+// append a record to the segment and return its location.
+func (s *segment) append(buf []byte, valueLength int) (location, error) {
+	_, err := s.f.WriteAt(buf, s.size)
+	if err != nil {
+		return location{}, err
+	}
+
+	loc := location{
+		segment:     s.id,
+		offset:      uint64(s.size),
+		length:      uint64(len(buf)),
+		valueLength: uint64(valueLength),
+	}
+	s.size += int64(len(buf))
+	return loc, nil
+}
+
+// readValue reads the value stored at the given location.
+func (s *segment) readValue(loc location, buf []byte) ([]byte, error) {
+	if uint64(cap(buf)) < loc.valueLength {
+		buf = make([]byte, loc.valueLength)
+	}
+	buf = buf[:loc.valueLength]
+
+	_, err := s.f.ReadAt(buf, int64(loc.offset+loc.length-loc.valueLength))
+	return buf, err
+}
+
+// scan reads the records of the segment and calls fn with each one, along with its location.
+// It stops at the first record that is incomplete or corrupted, and returns its offset.
+func (s *segment) scan(fn func(r *record, loc location) error) (int64, error) {
+	rd := bufio.NewReader(io.NewSectionReader(s.f, 0, s.size))
+
+	var offset int64
+	var header [recordHeaderSize]byte
+	var r record
+	for {
+		_, err := io.ReadFull(rd, header[:])
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return offset, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		size := binary.LittleEndian.Uint32(header[:])
+		if int64(size) > s.size-offset {
+			return offset, nil
+		}
+		payload := make([]byte, size)
+		_, err = io.ReadFull(rd, payload)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return offset, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if crc32.Checksum(payload, crcTable) != binary.LittleEndian.Uint32(header[4:]) {
+			return offset, nil
+		}
+		if r.decode(payload) != nil {
+			return offset, nil
+		}
+
+		length := int64(recordHeaderSize) + int64(size)
+		err = fn(&r, location{
+			segment:     s.id,
+			offset:      uint64(offset),
+			length:      uint64(length),
+			valueLength: uint64(len(r.value)),
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		offset += length
+	}
+}
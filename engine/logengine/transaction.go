@@ -0,0 +1,261 @@
+package logengine
+
+import (
+	"context"
+	"errors"
+
+	"github.com/genjidb/genji/engine"
+)
+
+// transaction uses a transaction of the in-memory index, and appends
+// the changes it makes to the segments.
+type transaction struct {
+	engine.Transaction
+
+	ng       *Engine
+	ctx      context.Context
+	id       uint64
+	writable bool
+	ended    bool
+	// true if records were appended.
+	dirty bool
+	// set if a record couldn't be appended, in which case
+	// the transaction can only be rolled back.
+	err error
+	// stores created or dropped, and change of the size of the live records.
+	stores map[string]bool
+	delta  int64
+}
+
+func (tx *transaction) end() {
+	if !tx.ended {
+		tx.ended = true
+		tx.ng.end()
+	}
+}
+
+func (tx *transaction) Rollback() error {
+	defer tx.end()
+
+	return tx.Transaction.Rollback()
+}
+
+// Commit the transaction. Its changes are visible once
+// the commit record is written to the segments and synced.
+func (tx *transaction) Commit() error {
+	if tx.err != nil && !tx.ended {
+		_ = tx.Rollback()
+		return tx.err
+	}
+
+	err := tx.Transaction.Commit()
+	if err != nil || !tx.dirty {
+		tx.end()
+		return err
+	}
+	defer tx.end()
+
+	return tx.ng.commit(tx)
+}
+
+// check returns an error if the transaction cannot be written.
+func (tx *transaction) check() error {
+	select {
+	case <-tx.ctx.Done():
+		return tx.ctx.Err()
+	default:
+	}
+
+	if !tx.writable {
+		return engine.ErrTransactionReadOnly
+	}
+
+	return tx.err
+}
+
+// log appends a record of the transaction to the segments.
+func (tx *transaction) log(r *record) (location, error) {
+	r.txID = tx.id
+	loc, err := tx.ng.append(r)
+	if err != nil {
+		tx.err = err
+		return location{}, err
+	}
+
+	tx.dirty = true
+	return loc, nil
+}
+
+func (tx *transaction) GetStore(name []byte) (engine.Store, error) {
+	st, err := tx.Transaction.GetStore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &store{Store: st, tx: tx, name: append([]byte{}, name...)}, nil
+}
+
+func (tx *transaction) CreateStore(name []byte) error {
+	err := tx.Transaction.CreateStore(name)
+	if err != nil {
+		return err
+	}
+
+	loc, err := tx.log(&record{kind: recordCreateStore, store: name})
+	if err != nil {
+		return err
+	}
+
+	tx.setStore(name, true)
+	tx.delta += int64(loc.length)
+	return nil
+}
+
+func (tx *transaction) DropStore(name []byte) error {
+	err := tx.check()
+	if err != nil {
+		return err
+	}
+
+	delta, err := dropStore(tx.Transaction, name)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.log(&record{kind: recordDropStore, store: name})
+	if err != nil {
+		return err
+	}
+
+	tx.setStore(name, false)
+	tx.delta += delta
+	return nil
+}
+
+func (tx *transaction) setStore(name []byte, created bool) {
+	if tx.stores == nil {
+		tx.stores = make(map[string]bool)
+	}
+	tx.stores[string(name)] = created
+}
+
+// store reads the values from the segments, using the locations
+// stored in a store of the index.
+type store struct {
+	engine.Store
+
+	tx   *transaction
+	name []byte
+}
+
+func (s *store) Get(k []byte) ([]byte, error) {
+	v, err := s.Store.Get(k)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tx.ng.readValue(decodeLocation(v), nil)
+}
+
+func (s *store) Put(k, v []byte) error {
+	err := s.tx.check()
+	if err != nil {
+		return err
+	}
+
+	if len(k) == 0 {
+		return errors.New("empty keys are forbidden")
+	}
+
+	if len(v) == 0 {
+		return errors.New("empty values are forbidden")
+	}
+
+	loc, err := s.tx.log(&record{kind: recordPut, store: s.name, key: k, value: v})
+	if err != nil {
+		return err
+	}
+
+	delta, err := putLocation(s.Store, k, loc)
+	if err != nil {
+		return err
+	}
+
+	s.tx.delta += delta
+	return nil
+}
+
+func (s *store) Delete(k []byte) error {
+	err := s.tx.check()
+	if err != nil {
+		return err
+	}
+
+	delta, err := deleteKey(s.Store, k)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.tx.log(&record{kind: recordDelete, store: s.name, key: k})
+	if err != nil {
+		return err
+	}
+
+	s.tx.delta += delta
+	return nil
+}
+
+func (s *store) Truncate() error {
+	err := s.tx.check()
+	if err != nil {
+		return err
+	}
+
+	delta, err := truncate(s.Store)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.tx.log(&record{kind: recordTruncate, store: s.name})
+	if err != nil {
+		return err
+	}
+
+	s.tx.delta += delta
+	return nil
+}
+
+func (s *store) Iterator(opts engine.IteratorOptions) engine.Iterator {
+	it := iterator{Iterator: s.Store.Iterator(opts)}
+	it.item.ng = s.tx.ng
+	return &it
+}
+
+// iterator iterates over the index and reads the values from the segments.
+type iterator struct {
+	engine.Iterator
+
+	item item
+}
+
+func (it *iterator) Item() engine.Item {
+	it.item.Item = it.Iterator.Item()
+	return &it.item
+}
+
+type item struct {
+	engine.Item
+
+	ng  *Engine
+	loc []byte
+}
+
+func (i *item) ValueCopy(buf []byte) ([]byte, error) {
+	loc, err := i.Item.ValueCopy(i.loc)
+	if err != nil {
+		return nil, err
+	}
+	i.loc = loc
+
+	return i.ng.readValue(decodeLocation(loc), buf)
+}
@@ -43,6 +43,20 @@ func (ng *Engine) Begin(ctx context.Context, opts engine.TxOptions) (engine.Tran
 	return &transaction{ctx: ctx, ng: ng, writable: opts.Writable}, nil
 }
 
+// Isolation returns engine.IsolationNone: changes are applied to the stores
+// as soon as they are made, and undone on rollback.
+// It implements the engine.IsolationReporter interface.
+func (ng *Engine) Isolation() string {
+	return engine.IsolationNone
+}
+
+// SetSynchronous does nothing, as the memory engine never writes to disk:
+// every synchronous mode is supported.
+// It implements the engine.Synchronizer interface.
+func (ng *Engine) SetSynchronous(mode string) error {
+	return nil
+}
+
 // Close the engine.
 func (ng *Engine) Close() error {
 	if ng.Closed {
@@ -198,6 +198,7 @@ func (s *storeTx) Iterator(opts engine.IteratorOptions) engine.Iterator {
 		tr:      s.tr,
 		buf:     make([]*item, 0, itBufSize),
 		reverse: opts.Reverse,
+		opts:    opts,
 	}
 }
 
@@ -209,6 +210,7 @@ type iterator struct {
 	ctx     context.Context
 	tx      *transaction
 	reverse bool
+	opts    engine.IteratorOptions
 	tr      *btree.BTree
 
 	// buf stores a batch of itBufSize items
@@ -226,8 +228,20 @@ type iterator struct {
 	err error
 }
 
-// Seek seeks the pivot and reads a batch of items from the tree.
+// Seek seeks the pivot, taking the bounds of the options into account,
+// and reads a batch of items from the tree.
 func (it *iterator) Seek(pivot []byte) {
+	pivot, exclusive := it.opts.Start(pivot)
+
+	it.seek(pivot)
+
+	if exclusive && len(it.buf) > 0 && bytes.Equal(it.buf[0].k, pivot) {
+		it.cursor++
+	}
+}
+
+// seek reads a batch of items from the tree, starting at the pivot.
+func (it *iterator) seek(pivot []byte) {
 	// reset the buffer and cursor
 	it.buf = it.buf[:0]
 	it.cursor = 0
@@ -287,7 +301,7 @@ func (it *iterator) Valid() bool {
 		// get the key of the last item of the buffer
 		// and preload from that key
 		pivot := it.buf[len(it.buf)-1].k
-		it.Seek(pivot)
+		it.seek(pivot)
 
 		// the pivot was part of the previous batch
 		// but is also part of the new batch, we need to
@@ -307,7 +321,7 @@ func (it *iterator) Valid() bool {
 		}
 	}
 
-	return len(it.buf) > 0 && it.cursor < len(it.buf) && it.err == nil
+	return len(it.buf) > 0 && it.cursor < len(it.buf) && it.err == nil && it.opts.Contains(it.buf[it.cursor].k)
 }
 
 func (it *iterator) Next() {
@@ -0,0 +1,229 @@
+package wrap
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Cache is a read-through cache of the values returned by Get.
+// It keeps the most recently read values of every store, up to a maximum number of entries.
+//
+// Values are only cached and served to read-only transactions, and only if no transaction
+// committed changes since they began, so that they never read values their snapshot wouldn't contain.
+// The keys written by a transaction are evicted when it commits.
+// The cache must only be used by one engine, and that engine must not be modified
+// by other means.
+type Cache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[cacheKey]*list.Element
+	lru     *list.List
+	// incremented every time a writable transaction commits.
+	generation uint64
+	// number of writable transactions being committed.
+	committing int
+	// states of the running transactions.
+	txs map[uint64]*cacheTx
+
+	hits, misses int64
+}
+
+type cacheKey struct {
+	store, key string
+}
+
+type cacheEntry struct {
+	key   cacheKey
+	value []byte
+	// generation of the transaction that read the value.
+	generation uint64
+}
+
+type cacheTx struct {
+	generation uint64
+	// keys written by a writable transaction.
+	written map[cacheKey]struct{}
+	// true if the transaction dropped or truncated a store.
+	clear bool
+}
+
+// NewCache creates a cache keeping at most size values.
+func NewCache(size int) *Cache {
+	return &Cache{
+		size:    size,
+		entries: make(map[cacheKey]*list.Element),
+		lru:     list.New(),
+		txs:     make(map[uint64]*cacheTx),
+	}
+}
+
+// Stats returns the number of reads served by the cache and the number of reads
+// that were passed to the wrapped engine.
+func (c *Cache) Stats() (hits, misses int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.hits, c.misses
+}
+
+// Len returns the number of values in the cache.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Len()
+}
+
+// Middleware returns a middleware serving Get from the cache.
+func (c *Cache) Middleware() Middleware {
+	return func(op *Op, next func() error) error {
+		switch op.Kind {
+		case OpBegin:
+			c.mu.Lock()
+			c.txs[op.TxID] = &cacheTx{generation: c.generation}
+			c.mu.Unlock()
+
+			err := next()
+			if err != nil {
+				c.endTx(op.TxID)
+			}
+			return err
+		case OpCommit:
+			return c.commit(op, next)
+		case OpRollback:
+			defer c.endTx(op.TxID)
+			return next()
+		case OpGet:
+			return c.get(op, next)
+		case OpPut, OpDelete, OpTruncate, OpDropStore:
+			err := next()
+			if err == nil && op.Writable {
+				c.written(op)
+			}
+			return err
+		}
+
+		return next()
+	}
+}
+
+func (c *Cache) endTx(id uint64) {
+	c.mu.Lock()
+	delete(c.txs, id)
+	c.mu.Unlock()
+}
+
+// commit evicts the keys written by the transaction, before and after it is committed.
+// No value is added to the cache while a commit is running, since it could be
+// read either before or after the commit.
+func (c *Cache) commit(op *Op, next func() error) error {
+	c.mu.Lock()
+	tx := c.txs[op.TxID]
+	delete(c.txs, op.TxID)
+	if tx == nil || (!tx.clear && len(tx.written) == 0) {
+		c.mu.Unlock()
+		return next()
+	}
+	c.committing++
+	c.evict(tx)
+	c.mu.Unlock()
+
+	err := next()
+
+	c.mu.Lock()
+	c.committing--
+	c.generation++
+	c.evict(tx)
+	c.mu.Unlock()
+
+	return err
+}
+
+func (c *Cache) evict(tx *cacheTx) {
+	if tx.clear {
+		c.entries = make(map[cacheKey]*list.Element)
+		c.lru.Init()
+		return
+	}
+
+	for k := range tx.written {
+		if e, ok := c.entries[k]; ok {
+			c.lru.Remove(e)
+			delete(c.entries, k)
+		}
+	}
+}
+
+func (c *Cache) written(op *Op) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tx := c.txs[op.TxID]
+	if tx == nil {
+		return
+	}
+
+	if op.Kind == OpTruncate || op.Kind == OpDropStore {
+		tx.clear = true
+		return
+	}
+
+	if tx.written == nil {
+		tx.written = make(map[cacheKey]struct{})
+	}
+	tx.written[cacheKey{string(op.Store), string(op.Key)}] = struct{}{}
+}
+
+func (c *Cache) get(op *Op, next func() error) error {
+	if op.Writable {
+		return next()
+	}
+
+	k := cacheKey{string(op.Store), string(op.Key)}
+
+	c.mu.Lock()
+	tx := c.txs[op.TxID]
+	if tx == nil {
+		c.mu.Unlock()
+		return next()
+	}
+	if e, ok := c.entries[k]; ok {
+		ce := e.Value.(*cacheEntry)
+		// the transaction may have begun before the value was read
+		if ce.generation <= tx.generation {
+			c.lru.MoveToFront(e)
+			c.hits++
+			c.mu.Unlock()
+			op.Value = append([]byte{}, ce.value...)
+			return nil
+		}
+	}
+	c.misses++
+	gen := tx.generation
+	c.mu.Unlock()
+
+	err := next()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// the value may be stale if a transaction committed since this one began
+	if gen != c.generation || c.committing > 0 || c.size <= 0 {
+		return nil
+	}
+
+	if e, ok := c.entries[k]; ok {
+		c.lru.Remove(e)
+	}
+	c.entries[k] = c.lru.PushFront(&cacheEntry{key: k, value: append([]byte{}, op.Value...), generation: gen})
+	for c.lru.Len() > c.size {
+		e := c.lru.Back()
+		c.lru.Remove(e)
+		delete(c.entries, e.Value.(*cacheEntry).key)
+	}
+
+	return nil
+}
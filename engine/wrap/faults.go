@@ -0,0 +1,205 @@
+package wrap
+
+import (
+	"bytes"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// A Fault describes how to disturb operations of the engine.
+// A zero Fault disturbs nothing.
+type Fault struct {
+	// Err is returned by the operations instead of running them,
+	// unless PartialWrite is set. Rollbacks still run, to release
+	// the transaction, but return Err.
+	// For example, failing OpCommit with engine.ErrConflict simulates conflicts.
+	Err error
+	// Latency is added before running the operations. If the context of the transaction
+	// is done in the meantime, the operations fail with the error of the context.
+	Latency time.Duration
+	// If set, only the operations on this store are disturbed.
+	Store []byte
+	// Number of operations to let through before disturbing them.
+	After int
+	// Maximum number of operations to disturb. If zero, there is no limit.
+	Times int
+	// Probability, between 0 and 1, for an operation to be disturbed.
+	// If zero, every operation is disturbed.
+	Probability float64
+	// PartialWrite only writes the first half of the values of OpPut,
+	// and then returns Err, if any.
+	PartialWrite bool
+	// Corrupt flips the bits of the last byte of the values returned by OpGet.
+	Corrupt bool
+}
+
+type faultState struct {
+	Fault
+
+	// number of matching operations, and number of disturbed ones.
+	seen, count int
+}
+
+// Faults injects errors, latencies, partial writes and corrupted values
+// in the operations of an engine, to test how the code using it handles failures.
+type Faults struct {
+	mu     sync.Mutex
+	faults map[OpKind][]*faultState
+	counts map[OpKind]int
+	rand   *rand.Rand
+}
+
+// NewFaults creates a Faults injecting nothing.
+// Faults with a probability are drawn from a source with a fixed seed,
+// so that tests are reproducible. Use Seed to change it.
+func NewFaults() *Faults {
+	return &Faults{
+		faults: make(map[OpKind][]*faultState),
+		counts: make(map[OpKind]int),
+		rand:   rand.New(rand.NewSource(1)),
+	}
+}
+
+// Seed changes the seed used to draw faults with a probability.
+func (f *Faults) Seed(seed int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.rand.Seed(seed)
+}
+
+// Inject adds a fault to the operations of the given kind.
+// If several faults match an operation, the first one injected is used.
+func (f *Faults) Inject(kind OpKind, ft Fault) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if ft.Store != nil {
+		ft.Store = append([]byte{}, ft.Store...)
+	}
+	f.faults[kind] = append(f.faults[kind], &faultState{Fault: ft})
+}
+
+// Fail replaces the faults of the given kind so that every subsequent operation
+// of that kind fails with err, without running it.
+// If err is nil, the operations run normally again.
+func (f *Faults) Fail(kind OpKind, err error) {
+	f.mu.Lock()
+	delete(f.faults, kind)
+	f.mu.Unlock()
+
+	if err != nil {
+		f.Inject(kind, Fault{Err: err})
+	}
+}
+
+// Count returns the number of operations of the given kind that were disturbed.
+func (f *Faults) Count(kind OpKind) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.counts[kind]
+}
+
+// Reset removes every fault and resets the counts.
+func (f *Faults) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.faults = make(map[OpKind][]*faultState)
+	f.counts = make(map[OpKind]int)
+}
+
+// match returns the fault disturbing op, if any.
+func (f *Faults) match(op *Op) *Fault {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, fs := range f.faults[op.Kind] {
+		if fs.Store != nil && !bytes.Equal(fs.Store, op.Store) {
+			continue
+		}
+
+		fs.seen++
+		if fs.seen <= fs.After || (fs.Times > 0 && fs.count >= fs.Times) {
+			continue
+		}
+		if fs.Probability > 0 && f.rand.Float64() >= fs.Probability {
+			continue
+		}
+
+		fs.count++
+		f.counts[op.Kind]++
+		ft := fs.Fault
+		return &ft
+	}
+
+	return nil
+}
+
+// Middleware returns a middleware injecting the faults.
+func (f *Faults) Middleware() Middleware {
+	return func(op *Op, next func() error) error {
+		ft := f.match(op)
+		if ft == nil {
+			return next()
+		}
+
+		if ft.Latency > 0 {
+			err := sleep(op, ft.Latency)
+			if err != nil {
+				if op.Kind == OpRollback {
+					_ = next()
+				}
+				return err
+			}
+		}
+
+		switch {
+		case ft.PartialWrite && op.Kind == OpPut:
+			op.Value = op.Value[:len(op.Value)/2]
+			err := next()
+			if err != nil {
+				return err
+			}
+			return ft.Err
+		case ft.Err != nil:
+			if op.Kind == OpRollback {
+				_ = next()
+			}
+			return ft.Err
+		case ft.Corrupt && op.Kind == OpGet:
+			err := next()
+			if err != nil || len(op.Value) == 0 {
+				return err
+			}
+
+			// the value may belong to the engine
+			v := append([]byte{}, op.Value...)
+			v[len(v)-1] ^= 0xff
+			op.Value = v
+			return nil
+		}
+
+		return next()
+	}
+}
+
+// sleep for the given duration, unless the context of the operation is done before.
+func sleep(op *Op, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	if op.Ctx == nil {
+		<-t.C
+		return nil
+	}
+
+	select {
+	case <-t.C:
+		return nil
+	case <-op.Ctx.Done():
+		return op.Ctx.Err()
+	}
+}
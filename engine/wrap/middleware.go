@@ -0,0 +1,90 @@
+package wrap
+
+import (
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// Logging returns a middleware logging every operation, along with its duration
+// and the error it returned, if any.
+func Logging(logger *log.Logger) Middleware {
+	return func(op *Op, next func() error) error {
+		start := time.Now()
+		err := next()
+		d := time.Since(start)
+
+		switch {
+		case op.Key != nil:
+			logger.Printf("tx=%d %s store=%q key=%q %s%s", op.TxID, op.Kind, op.Store, op.Key, d, errSuffix(err))
+		case op.Store != nil:
+			logger.Printf("tx=%d %s store=%q %s%s", op.TxID, op.Kind, op.Store, d, errSuffix(err))
+		default:
+			logger.Printf("tx=%d %s writable=%t %s%s", op.TxID, op.Kind, op.Writable, d, errSuffix(err))
+		}
+
+		return err
+	}
+}
+
+func errSuffix(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return ", error: " + err.Error()
+}
+
+// OpMetrics are the metrics collected for one kind of operation.
+type OpMetrics struct {
+	// Number of operations run, including the failed ones.
+	Count int64
+	// Number of operations that returned an error.
+	Errors int64
+	// Total time spent running the operations.
+	Duration time.Duration
+}
+
+// Metrics collects the number of operations run by an engine,
+// the number of errors they returned and the time they took.
+type Metrics struct {
+	ops [OpSeek + 1]OpMetrics
+}
+
+// NewMetrics creates an empty Metrics.
+func NewMetrics() *Metrics {
+	return new(Metrics)
+}
+
+// Middleware returns a middleware updating the metrics.
+func (m *Metrics) Middleware() Middleware {
+	return func(op *Op, next func() error) error {
+		start := time.Now()
+		err := next()
+
+		if op.Kind > 0 && int(op.Kind) < len(m.ops) {
+			om := &m.ops[op.Kind]
+			atomic.AddInt64(&om.Count, 1)
+			atomic.AddInt64((*int64)(&om.Duration), int64(time.Since(start)))
+			if err != nil {
+				atomic.AddInt64(&om.Errors, 1)
+			}
+		}
+
+		return err
+	}
+}
+
+// Get returns the metrics collected for the given kind of operation.
+func (m *Metrics) Get(kind OpKind) OpMetrics {
+	if kind <= 0 || int(kind) >= len(m.ops) {
+		return OpMetrics{}
+	}
+
+	om := &m.ops[kind]
+	return OpMetrics{
+		Count:    atomic.LoadInt64(&om.Count),
+		Errors:   atomic.LoadInt64(&om.Errors),
+		Duration: time.Duration(atomic.LoadInt64((*int64)(&om.Duration))),
+	}
+}
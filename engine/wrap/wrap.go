@@ -0,0 +1,279 @@
+// Package wrap provides engines wrapping other engines, to add behaviour around
+// their operations without modifying them, like logging, metrics, caching or fault injection.
+//
+// Each behaviour is a Middleware, called around every operation of the wrapped engine.
+// Middlewares can be composed:
+//
+//	metrics := wrap.NewMetrics()
+//	ng := wrap.New(boltEngine, wrap.Logging(logger), metrics.Middleware(), wrap.NewCache(1000).Middleware())
+package wrap
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/genjidb/genji/engine"
+)
+
+// An OpKind is the kind of an operation of an engine.
+type OpKind int
+
+// Operations run by the wrapped engine.
+const (
+	OpBegin OpKind = iota + 1
+	OpCommit
+	OpRollback
+	OpGetStore
+	OpCreateStore
+	OpDropStore
+	OpGet
+	OpPut
+	OpDelete
+	OpTruncate
+	// Seek of an iterator. The other methods of iterators
+	// are not intercepted, to keep iterations fast.
+	OpSeek
+)
+
+var opKindNames = []string{
+	OpBegin:       "begin",
+	OpCommit:      "commit",
+	OpRollback:    "rollback",
+	OpGetStore:    "getStore",
+	OpCreateStore: "createStore",
+	OpDropStore:   "dropStore",
+	OpGet:         "get",
+	OpPut:         "put",
+	OpDelete:      "delete",
+	OpTruncate:    "truncate",
+	OpSeek:        "seek",
+}
+
+func (k OpKind) String() string {
+	if k <= 0 || int(k) >= len(opKindNames) {
+		return "unknown"
+	}
+
+	return opKindNames[k]
+}
+
+// An Op describes an operation of the wrapped engine.
+type Op struct {
+	Kind OpKind
+	// Context of the transaction.
+	Ctx context.Context
+	// Identifies the transaction running the operation, unique per engine.
+	TxID     uint64
+	Writable bool
+	// Name of the store, if any.
+	Store []byte
+	// Key used by OpGet, OpPut and OpDelete, or pivot of OpSeek.
+	Key []byte
+	// Value written by OpPut, or returned by OpGet once the operation has run.
+	Value []byte
+}
+
+// A Middleware is called around every operation of the wrapped engine.
+// It must call next to run the operation, unless it handles it by itself
+// or wants it to fail. Middlewares are called concurrently by the transactions
+// of the engine.
+type Middleware func(op *Op, next func() error) error
+
+// New returns an engine calling the middlewares around every operation of ng.
+// The first middleware is the outermost one.
+func New(ng engine.Engine, mws ...Middleware) engine.Engine {
+	return &wrappedEngine{ng: ng, mw: chain(mws)}
+}
+
+// chain composes the middlewares into one.
+func chain(mws []Middleware) Middleware {
+	return func(op *Op, next func() error) error {
+		var run func(i int) error
+		run = func(i int) error {
+			if i == len(mws) {
+				return next()
+			}
+
+			return mws[i](op, func() error { return run(i + 1) })
+		}
+
+		return run(0)
+	}
+}
+
+type wrappedEngine struct {
+	ng       engine.Engine
+	mw       Middleware
+	lastTxID uint64
+}
+
+func (e *wrappedEngine) Begin(ctx context.Context, opts engine.TxOptions) (engine.Transaction, error) {
+	op := Op{Kind: OpBegin, Ctx: ctx, TxID: atomic.AddUint64(&e.lastTxID, 1), Writable: opts.Writable}
+
+	var tx engine.Transaction
+	err := e.mw(&op, func() error {
+		var err error
+		tx, err = e.ng.Begin(ctx, opts)
+		return err
+	})
+	if err != nil {
+		// a middleware can fail after the transaction was started
+		if tx != nil {
+			_ = tx.Rollback()
+		}
+		return nil, err
+	}
+
+	return &wrappedTx{tx: tx, mw: e.mw, ctx: ctx, id: op.TxID, writable: opts.Writable}, nil
+}
+
+// Isolation returns the isolation level of the wrapped engine.
+// It implements the engine.IsolationReporter interface.
+func (e *wrappedEngine) Isolation() string {
+	return engine.Isolation(e.ng)
+}
+
+// SetSynchronous sets the synchronous mode of the wrapped engine.
+// It implements the engine.Synchronizer interface.
+func (e *wrappedEngine) SetSynchronous(mode string) error {
+	return engine.SetSynchronous(e.ng, mode)
+}
+
+// RunGC runs the garbage collection of the wrapped engine.
+// It implements the engine.GarbageCollector interface.
+func (e *wrappedEngine) RunGC() error {
+	return engine.RunGC(e.ng)
+}
+
+// GCStats returns the garbage collection statistics of the wrapped engine.
+// It implements the engine.GarbageCollector interface.
+func (e *wrappedEngine) GCStats() (engine.GCStats, error) {
+	return engine.ReadGCStats(e.ng)
+}
+
+func (e *wrappedEngine) Close() error {
+	return e.ng.Close()
+}
+
+type wrappedTx struct {
+	tx       engine.Transaction
+	mw       Middleware
+	ctx      context.Context
+	id       uint64
+	writable bool
+}
+
+func (t *wrappedTx) op(kind OpKind, store []byte) Op {
+	return Op{Kind: kind, Ctx: t.ctx, TxID: t.id, Writable: t.writable, Store: store}
+}
+
+func (t *wrappedTx) Rollback() error {
+	op := t.op(OpRollback, nil)
+	return t.mw(&op, t.tx.Rollback)
+}
+
+func (t *wrappedTx) Commit() error {
+	op := t.op(OpCommit, nil)
+	return t.mw(&op, t.tx.Commit)
+}
+
+func (t *wrappedTx) GetStore(name []byte) (engine.Store, error) {
+	op := t.op(OpGetStore, name)
+
+	var st engine.Store
+	err := t.mw(&op, func() error {
+		var err error
+		st, err = t.tx.GetStore(name)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &wrappedStore{st: st, tx: t, name: name}, nil
+}
+
+func (t *wrappedTx) CreateStore(name []byte) error {
+	op := t.op(OpCreateStore, name)
+	return t.mw(&op, func() error { return t.tx.CreateStore(name) })
+}
+
+func (t *wrappedTx) DropStore(name []byte) error {
+	op := t.op(OpDropStore, name)
+	return t.mw(&op, func() error { return t.tx.DropStore(name) })
+}
+
+type wrappedStore struct {
+	st   engine.Store
+	tx   *wrappedTx
+	name []byte
+}
+
+func (s *wrappedStore) Get(k []byte) ([]byte, error) {
+	op := s.tx.op(OpGet, s.name)
+	op.Key = k
+
+	err := s.tx.mw(&op, func() error {
+		var err error
+		op.Value, err = s.st.Get(k)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return op.Value, nil
+}
+
+func (s *wrappedStore) Put(k, v []byte) error {
+	op := s.tx.op(OpPut, s.name)
+	op.Key, op.Value = k, v
+
+	return s.tx.mw(&op, func() error { return s.st.Put(op.Key, op.Value) })
+}
+
+func (s *wrappedStore) Delete(k []byte) error {
+	op := s.tx.op(OpDelete, s.name)
+	op.Key = k
+
+	return s.tx.mw(&op, func() error { return s.st.Delete(k) })
+}
+
+func (s *wrappedStore) Truncate() error {
+	op := s.tx.op(OpTruncate, s.name)
+	return s.tx.mw(&op, s.st.Truncate)
+}
+
+func (s *wrappedStore) Iterator(opts engine.IteratorOptions) engine.Iterator {
+	return &wrappedIterator{Iterator: s.st.Iterator(opts), store: s}
+}
+
+type wrappedIterator struct {
+	engine.Iterator
+
+	store *wrappedStore
+	// error returned by the middlewares around Seek
+	err error
+}
+
+func (it *wrappedIterator) Seek(pivot []byte) {
+	op := it.store.tx.op(OpSeek, it.store.name)
+	op.Key = pivot
+
+	it.err = it.store.tx.mw(&op, func() error {
+		it.Iterator.Seek(pivot)
+		return nil
+	})
+}
+
+func (it *wrappedIterator) Valid() bool {
+	return it.err == nil && it.Iterator.Valid()
+}
+
+func (it *wrappedIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+
+	return it.Iterator.Err()
+}
@@ -0,0 +1,305 @@
+package wrap_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/engine/enginetest"
+	"github.com/genjidb/genji/engine/memoryengine"
+	"github.com/genjidb/genji/engine/wrap"
+	"github.com/stretchr/testify/require"
+)
+
+func builder() (engine.Engine, func()) {
+	ng := wrap.New(memoryengine.NewEngine(),
+		wrap.Logging(log.New(ioutil.Discard, "", 0)),
+		wrap.NewMetrics().Middleware(),
+		wrap.NewCache(100).Middleware(),
+		wrap.NewFaults().Middleware(),
+	)
+	return ng, func() { ng.Close() }
+}
+
+func TestWrappedEngine(t *testing.T) {
+	enginetest.TestSuite(t, builder)
+}
+
+func BenchmarkWrappedEngineStorePut(b *testing.B) {
+	enginetest.BenchmarkStorePut(b, builder)
+}
+
+func BenchmarkWrappedEngineStoreScan(b *testing.B) {
+	enginetest.BenchmarkStoreScan(b, builder)
+}
+
+// put writes k and v in the store "test", creating it if necessary.
+func put(t *testing.T, ng engine.Engine, k, v string) {
+	t.Helper()
+
+	tx, err := ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	st, err := tx.GetStore([]byte("test"))
+	if err == engine.ErrStoreNotFound {
+		require.NoError(t, tx.CreateStore([]byte("test")))
+		st, err = tx.GetStore([]byte("test"))
+	}
+	require.NoError(t, err)
+	require.NoError(t, st.Put([]byte(k), []byte(v)))
+	require.NoError(t, tx.Commit())
+}
+
+// get reads k in the store "test" of a read-only transaction.
+func get(t *testing.T, tx engine.Transaction, k string) string {
+	t.Helper()
+
+	st, err := tx.GetStore([]byte("test"))
+	require.NoError(t, err)
+	v, err := st.Get([]byte(k))
+	require.NoError(t, err)
+	return string(v)
+}
+
+func TestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	ng := wrap.New(memoryengine.NewEngine(), wrap.Logging(log.New(&buf, "", 0)))
+	defer ng.Close()
+
+	put(t, ng, "a", "1")
+
+	out := buf.String()
+	require.Contains(t, out, "tx=1 begin writable=true")
+	require.Contains(t, out, `tx=1 getStore store="test"`)
+	require.Contains(t, out, `error: store not found`)
+	require.Contains(t, out, `tx=1 put store="test" key="a"`)
+	require.Contains(t, out, "tx=1 commit")
+}
+
+func TestMetrics(t *testing.T) {
+	m := wrap.NewMetrics()
+	ng := wrap.New(memoryengine.NewEngine(), m.Middleware())
+	defer ng.Close()
+
+	put(t, ng, "a", "1")
+	put(t, ng, "b", "2")
+
+	require.EqualValues(t, 2, m.Get(wrap.OpBegin).Count)
+	require.EqualValues(t, 2, m.Get(wrap.OpCommit).Count)
+	require.EqualValues(t, 2, m.Get(wrap.OpPut).Count)
+	require.EqualValues(t, 0, m.Get(wrap.OpPut).Errors)
+	require.EqualValues(t, 1, m.Get(wrap.OpCreateStore).Count)
+	require.EqualValues(t, 3, m.Get(wrap.OpGetStore).Count)
+	require.EqualValues(t, 1, m.Get(wrap.OpGetStore).Errors)
+	require.NotZero(t, m.Get(wrap.OpPut).Duration)
+	require.Zero(t, m.Get(wrap.OpGet))
+}
+
+func TestCache(t *testing.T) {
+	c := wrap.NewCache(2)
+	ng := wrap.New(memoryengine.NewEngine(), c.Middleware())
+	defer ng.Close()
+
+	begin := func() engine.Transaction {
+		tx, err := ng.Begin(context.Background(), engine.TxOptions{})
+		require.NoError(t, err)
+		return tx
+	}
+
+	put(t, ng, "a", "1")
+
+	t.Run("Read-through", func(t *testing.T) {
+		tx := begin()
+		defer tx.Rollback()
+
+		require.Equal(t, "1", get(t, tx, "a"))
+		require.Equal(t, "1", get(t, tx, "a"))
+		hits, misses := c.Stats()
+		require.EqualValues(t, 1, hits)
+		require.EqualValues(t, 1, misses)
+	})
+
+	t.Run("Eviction on commit", func(t *testing.T) {
+		put(t, ng, "a", "2")
+		require.Zero(t, c.Len())
+
+		tx := begin()
+		defer tx.Rollback()
+		require.Equal(t, "2", get(t, tx, "a"))
+		require.Equal(t, 1, c.Len())
+	})
+
+	t.Run("Size", func(t *testing.T) {
+		put(t, ng, "b", "1")
+		put(t, ng, "c", "1")
+
+		tx := begin()
+		defer tx.Rollback()
+		for _, k := range []string{"a", "b", "c"} {
+			get(t, tx, k)
+		}
+		require.Equal(t, 2, c.Len())
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		tx, err := ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+		require.NoError(t, err)
+		st, err := tx.GetStore([]byte("test"))
+		require.NoError(t, err)
+		require.NoError(t, st.Put([]byte("c"), []byte("2")))
+		// writable transactions don't use the cache
+		require.Equal(t, "2", get(t, tx, "c"))
+		require.NoError(t, tx.Rollback())
+
+		tx = begin()
+		defer tx.Rollback()
+		require.Equal(t, "1", get(t, tx, "c"))
+	})
+
+	t.Run("Truncate", func(t *testing.T) {
+		tx, err := ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+		require.NoError(t, err)
+		defer tx.Rollback()
+		st, err := tx.GetStore([]byte("test"))
+		require.NoError(t, err)
+		require.NoError(t, st.Truncate())
+		require.NoError(t, tx.Commit())
+		require.Zero(t, c.Len())
+	})
+}
+
+func TestFaults(t *testing.T) {
+	f := wrap.NewFaults()
+	ng := wrap.New(memoryengine.NewEngine(), f.Middleware())
+	defer ng.Close()
+
+	put(t, ng, "a", "hello")
+
+	begin := func(ctx context.Context, writable bool) (engine.Transaction, engine.Store) {
+		tx, err := ng.Begin(ctx, engine.TxOptions{Writable: writable})
+		require.NoError(t, err)
+		st, err := tx.GetStore([]byte("test"))
+		require.NoError(t, err)
+		return tx, st
+	}
+
+	errFault := errors.New("fault")
+
+	t.Run("Errors", func(t *testing.T) {
+		defer f.Reset()
+
+		f.Fail(wrap.OpGet, errFault)
+		tx, st := begin(context.Background(), false)
+		_, err := st.Get([]byte("a"))
+		require.Equal(t, errFault, err)
+
+		f.Fail(wrap.OpSeek, errFault)
+		it := st.Iterator(engine.IteratorOptions{})
+		it.Seek(nil)
+		require.False(t, it.Valid())
+		require.Equal(t, errFault, it.Err())
+		require.NoError(t, it.Close())
+
+		f.Fail(wrap.OpRollback, errFault)
+		require.Equal(t, errFault, tx.Rollback())
+		require.Equal(t, 1, f.Count(wrap.OpGet))
+
+		f.Fail(wrap.OpGet, nil)
+		tx, st = begin(context.Background(), false)
+		defer tx.Rollback()
+		_, err = st.Get([]byte("a"))
+		require.NoError(t, err)
+	})
+
+	t.Run("Conflicts", func(t *testing.T) {
+		defer f.Reset()
+
+		f.Inject(wrap.OpCommit, wrap.Fault{Err: engine.ErrConflict, After: 1, Times: 2})
+		for i, want := range []error{nil, engine.ErrConflict, engine.ErrConflict, nil} {
+			tx, _ := begin(context.Background(), true)
+			require.Equal(t, want, tx.Commit(), "commit %d", i)
+			_ = tx.Rollback()
+		}
+		require.Equal(t, 2, f.Count(wrap.OpCommit))
+	})
+
+	t.Run("Latency", func(t *testing.T) {
+		defer f.Reset()
+
+		f.Inject(wrap.OpGet, wrap.Fault{Latency: 50 * time.Millisecond})
+		tx, st := begin(context.Background(), false)
+		start := time.Now()
+		_, err := st.Get([]byte("a"))
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, int64(time.Since(start)), int64(50*time.Millisecond))
+		require.NoError(t, tx.Rollback())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		f.Fail(wrap.OpGet, nil)
+		f.Inject(wrap.OpGet, wrap.Fault{Latency: time.Hour})
+		tx, st = begin(ctx, false)
+		defer tx.Rollback()
+		_, err = st.Get([]byte("a"))
+		require.Equal(t, context.DeadlineExceeded, err)
+	})
+
+	t.Run("Partial write", func(t *testing.T) {
+		defer f.Reset()
+
+		f.Inject(wrap.OpPut, wrap.Fault{PartialWrite: true, Err: errFault})
+		tx, st := begin(context.Background(), true)
+		require.Equal(t, errFault, st.Put([]byte("b"), []byte("world!")))
+		v, err := st.Get([]byte("b"))
+		require.NoError(t, err)
+		require.Equal(t, "wor", string(v))
+		require.NoError(t, tx.Rollback())
+	})
+
+	t.Run("Corruption", func(t *testing.T) {
+		defer f.Reset()
+
+		f.Inject(wrap.OpGet, wrap.Fault{Corrupt: true, Store: []byte("test")})
+		tx, st := begin(context.Background(), false)
+		v, err := st.Get([]byte("a"))
+		require.NoError(t, err)
+		require.NotEqual(t, "hello", string(v))
+		require.Equal(t, "hell", string(v[:4]))
+		require.NoError(t, tx.Rollback())
+
+		// the stored value is left untouched
+		f.Reset()
+		tx, _ = begin(context.Background(), false)
+		defer tx.Rollback()
+		require.Equal(t, "hello", get(t, tx, "a"))
+	})
+
+	t.Run("Store", func(t *testing.T) {
+		defer f.Reset()
+
+		f.Inject(wrap.OpGetStore, wrap.Fault{Err: errFault, Store: []byte("other")})
+		tx, _ := begin(context.Background(), false)
+		defer tx.Rollback()
+		_, err := tx.GetStore([]byte("other"))
+		require.Equal(t, errFault, err)
+	})
+
+	t.Run("Probability", func(t *testing.T) {
+		defer f.Reset()
+
+		f.Inject(wrap.OpGet, wrap.Fault{Err: errFault, Probability: 0.5})
+		tx, st := begin(context.Background(), false)
+		defer tx.Rollback()
+		for i := 0; i < 100; i++ {
+			_, _ = st.Get([]byte("a"))
+		}
+		n := f.Count(wrap.OpGet)
+		require.True(t, n > 20 && n < 80, "%d faults", n)
+	})
+}
@@ -0,0 +1,82 @@
+package genji_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/engine/memoryengine"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetEngine(t *testing.T) {
+	dir, err := ioutil.TempDir("", "genji")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	hot := memoryengine.NewEngine()
+	defer hot.Close()
+
+	db, err := genji.Open(filepath.Join(dir, "test.db"))
+	require.NoError(t, err)
+	db.SetEngine("hot", hot)
+
+	err = db.Exec(`
+		CREATE TABLE sessions (id INTEGER PRIMARY KEY, user TEXT) WITH (engine = 'hot');
+		CREATE INDEX idx_sessions_user ON sessions (user);
+		CREATE TABLE users (name TEXT PRIMARY KEY);
+		BEGIN;
+		INSERT INTO users (name) VALUES ('foo');
+		INSERT INTO sessions (id, user) VALUES (1, 'foo'), (2, 'foo');
+		COMMIT;
+	`)
+	require.NoError(t, err)
+
+	d, err := db.QueryDocument("SELECT COUNT(*) FROM sessions WHERE user = 'foo'")
+	require.NoError(t, err)
+	var n int
+	require.NoError(t, document.Scan(d, &n))
+	require.Equal(t, 2, n)
+
+	// rolled back transactions don't modify any engine
+	err = db.Update(func(tx *genji.Tx) error {
+		err := tx.Exec("INSERT INTO users (name) VALUES ('bar'); INSERT INTO sessions (id, user) VALUES (3, 'bar')")
+		require.NoError(t, err)
+		return tx.Exec("INSERT INTO users (name) VALUES ('foo')")
+	})
+	require.Error(t, err)
+
+	d, err = db.QueryDocument("SELECT COUNT(*) FROM sessions")
+	require.NoError(t, err)
+	require.NoError(t, document.Scan(d, &n))
+	require.Equal(t, 2, n)
+
+	d, err = db.QueryDocument("SELECT COUNT(*) FROM users")
+	require.NoError(t, err)
+	require.NoError(t, document.Scan(d, &n))
+	require.Equal(t, 1, n)
+
+	// the engine must be registered again when the database is reopened
+	require.NoError(t, db.Close())
+	db, err = genji.Open(filepath.Join(dir, "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.QueryDocument("SELECT COUNT(*) FROM sessions")
+	require.Error(t, err)
+
+	db.SetEngine("hot", hot)
+	d, err = db.QueryDocument("SELECT COUNT(*) FROM sessions")
+	require.NoError(t, err)
+	require.NoError(t, document.Scan(d, &n))
+	require.Equal(t, 2, n)
+
+	schema, err := db.Schema()
+	require.NoError(t, err)
+	data, err := document.MarshalJSON(schema)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `WITH (engine = \"hot\")`)
+}
@@ -2,7 +2,9 @@ package errors
 
 import (
 	"errors"
+	"strings"
 
+	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/internal/stringutil"
 )
 
@@ -13,6 +15,10 @@ var (
 	// ErrDuplicateDocument is returned when another document is already associated with a given key, primary key,
 	// or if there is a unique index violation.
 	ErrDuplicateDocument = errors.New("duplicate document")
+
+	// ErrTransactionTimeout is returned when using a transaction that was rolled back
+	// automatically because it was open or idle for too long.
+	ErrTransactionTimeout = errors.New("transaction timed out")
 )
 
 // AlreadyExistsError is returned when to create a table, an index or a sequence
@@ -44,3 +50,159 @@ func IsNotFoundError(err error) bool {
 	_, ok := err.(NotFoundError)
 	return ok
 }
+
+// LimitExceededError is returned when a statement consumes more resources
+// than allowed by the limits of the database.
+type LimitExceededError struct {
+	// Name of the limit, i.e. max_scanned_documents.
+	Limit string
+	// Value of the limit.
+	Max int64
+}
+
+func (e LimitExceededError) Error() string {
+	return stringutil.Sprintf("statement exceeded the %s limit of %d", e.Limit, e.Max)
+}
+
+func IsLimitExceededError(err error) bool {
+	_, ok := err.(LimitExceededError)
+	return ok
+}
+
+// CorruptedDocumentError is returned when reading a document whose checksum
+// doesn't match its content, in a table created with checksums.
+type CorruptedDocumentError struct {
+	TableName string
+	// Key of the document in the table store.
+	Key []byte
+}
+
+func (e CorruptedDocumentError) Error() string {
+	return stringutil.Sprintf("document %q of table %q is corrupted: checksum mismatch", e.Key, e.TableName)
+}
+
+func IsCorruptedDocumentError(err error) bool {
+	_, ok := err.(CorruptedDocumentError)
+	return ok
+}
+
+// ConstraintViolationError is returned when a document doesn't satisfy
+// the NOT NULL, type, primary key or unique constraints of a table.
+type ConstraintViolationError struct {
+	// Constraint that was violated: NOT NULL, TYPE, PRIMARY KEY or UNIQUE.
+	Constraint string
+	// Name given to the constraint with CONSTRAINT name, if any.
+	Name string
+	// Name of the table, if known.
+	TableName string
+	// Name of the unique index, for UNIQUE constraints.
+	IndexName string
+	// Path of the offending field. It is empty for unique indexes
+	// and primary keys on multiple paths, whose Value is the array
+	// of the indexed values.
+	Path document.Path
+	// Type required by the field constraint, for TYPE constraints.
+	ExpectedType document.ValueType
+	// Offending value, if known and not redacted.
+	Value document.Value
+	// Redacted is true if the value was removed by Redact.
+	Redacted bool
+}
+
+// Redact returns a copy of the error without the offending value,
+// to avoid leaking sensitive data in messages returned to users.
+func (e ConstraintViolationError) Redact() ConstraintViolationError {
+	e.Value = document.Value{}
+	e.Redacted = true
+	return e
+}
+
+// HasValue returns true if the error carries the offending value.
+func (e ConstraintViolationError) HasValue() bool {
+	return e.Value.Type != document.AnyType
+}
+
+func (e ConstraintViolationError) Error() string {
+	var sb strings.Builder
+
+	if e.Name != "" {
+		stringutil.Fprintf(&sb, "%s constraint %q error: ", e.Constraint, e.Name)
+	} else {
+		stringutil.Fprintf(&sb, "%s constraint error: ", e.Constraint)
+	}
+
+	switch {
+	case len(e.Path) > 0:
+		stringutil.Fprintf(&sb, "field %q", e.Path)
+	case e.IndexName != "":
+		stringutil.Fprintf(&sb, "index %q", e.IndexName)
+	default:
+		sb.WriteString("document")
+	}
+
+	if e.TableName != "" {
+		stringutil.Fprintf(&sb, " of table %q", e.TableName)
+	}
+
+	switch e.Constraint {
+	case "NOT NULL":
+		sb.WriteString(" cannot be null")
+	case "TYPE":
+		stringutil.Fprintf(&sb, " must be of type %q", e.ExpectedType)
+		if e.HasValue() {
+			stringutil.Fprintf(&sb, ", got %s of type %q", e.Value, e.Value.Type)
+		}
+	case "PRIMARY KEY", "UNIQUE":
+		sb.WriteString(" must be unique")
+		if e.HasValue() {
+			stringutil.Fprintf(&sb, ", got duplicate %s", e.Value)
+		}
+	}
+
+	return sb.String()
+}
+
+// Is makes primary key and unique constraint violations match ErrDuplicateDocument
+// when using errors.Is.
+func (e ConstraintViolationError) Is(target error) bool {
+	return (e.Constraint == "PRIMARY KEY" || e.Constraint == "UNIQUE") && target == ErrDuplicateDocument
+}
+
+func IsConstraintViolationError(err error) bool {
+	_, ok := err.(ConstraintViolationError)
+	return ok
+}
+
+// ValidationError is returned when validating a document in a mode reporting
+// every constraint the document violates, instead of only the first one.
+type ValidationError struct {
+	TableName  string
+	Violations []ConstraintViolationError
+}
+
+// Redact returns a copy of the error without the offending values.
+func (e ValidationError) Redact() ValidationError {
+	violations := make([]ConstraintViolationError, len(e.Violations))
+	for i := range e.Violations {
+		violations[i] = e.Violations[i].Redact()
+	}
+	e.Violations = violations
+	return e
+}
+
+func (e ValidationError) Error() string {
+	var sb strings.Builder
+
+	stringutil.Fprintf(&sb, "document violates %d constraints of table %q", len(e.Violations), e.TableName)
+	for _, v := range e.Violations {
+		sb.WriteString("\n\t")
+		sb.WriteString(v.Error())
+	}
+
+	return sb.String()
+}
+
+func IsValidationError(err error) bool {
+	_, ok := err.(ValidationError)
+	return ok
+}
@@ -0,0 +1,43 @@
+package genji
+
+import (
+	"errors"
+
+	"github.com/genjidb/genji/internal/query/statement"
+	"github.com/genjidb/genji/internal/sql/parser"
+)
+
+// Estimate returns the number of documents the query is estimated to return,
+// without running it. It lets applications warn before running queries returning
+// large numbers of documents.
+// The query must be a single SELECT, INSERT, UPDATE or DELETE statement.
+// Estimates rely on the statistics collected by the ANALYZE statement:
+// tables whose indexes were not analyzed are assumed to contain 1000 documents.
+// The estimates of every operation of the query are returned by
+//   EXPLAIN (FORMAT JSON) query
+func (db *DB) Estimate(q string) (int64, error) {
+	pq, err := parser.ParseQuery(q)
+	if err != nil {
+		return 0, err
+	}
+	if len(pq.Statements) != 1 {
+		return 0, errors.New("Estimate only works on a single statement")
+	}
+	if _, ok := pq.Statements[0].(*statement.StreamStmt); !ok {
+		return 0, errors.New("Estimate only works on INSERT, SELECT, UPDATE and DELETE statements")
+	}
+	pq.Statements[0] = &statement.ExplainStmt{Statement: pq.Statements[0], JSON: true}
+
+	stmt := Statement{pq: pq, db: db}
+	d, err := stmt.QueryDocument()
+	if err != nil {
+		return 0, err
+	}
+
+	v, err := d.GetByField("estimated_documents")
+	if err != nil {
+		return 0, err
+	}
+
+	return v.V.(int64), nil
+}
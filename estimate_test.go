@@ -0,0 +1,67 @@
+package genji_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimate(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE test (k INTEGER PRIMARY KEY, a INTEGER);
+		CREATE INDEX idx_a ON test (a);
+		INSERT INTO test (k, a) VALUES (1, 1), (2, 1), (3, 2), (4, 2), (5, 3);
+	`)
+	require.NoError(t, err)
+
+	// without statistics, tables are assumed to contain 1000 documents
+	n, err := db.Estimate("SELECT * FROM test")
+	require.NoError(t, err)
+	require.EqualValues(t, 1000, n)
+
+	err = db.Exec("ANALYZE")
+	require.NoError(t, err)
+
+	tests := []struct {
+		query    string
+		expected int64
+	}{
+		{"SELECT * FROM test", 5},
+		{"SELECT * FROM test WHERE k = 1", 1},
+		{"SELECT * FROM test WHERE a = 1", 1},
+		{"SELECT * FROM test LIMIT 2", 2},
+		{"SELECT COUNT(*) FROM test", 1},
+		{"UPDATE test SET a = 1", 5},
+		{"SELECT * FROM test WHERE a = ?", 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			n, err := db.Estimate(test.query)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, n)
+		})
+	}
+
+	// the query is not run
+	n, err = db.Estimate("DELETE FROM test")
+	require.NoError(t, err)
+	require.EqualValues(t, 5, n)
+	d, err := db.QueryDocument("SELECT COUNT(*) FROM test")
+	require.NoError(t, err)
+	v, err := d.GetByField("COUNT(*)")
+	require.NoError(t, err)
+	require.EqualValues(t, 5, v.V)
+
+	_, err = db.Estimate("SELECT 1; SELECT 2")
+	require.Error(t, err)
+	_, err = db.Estimate("CREATE TABLE foo")
+	require.Error(t, err)
+	_, err = db.Estimate("SELECT * FROM unknown")
+	require.Error(t, err)
+}
@@ -19,28 +19,43 @@ type catalogCache struct {
 	tables    map[string]Relation
 	indexes   map[string]Relation
 	sequences map[string]Relation
+	views     map[string]Relation
+
+	// list of indexes of each table, sorted by name.
+	// it is kept in sync with the indexes map to avoid
+	// looking up every index each time a table is accessed.
+	// slices stored in this map are never modified in place,
+	// they are replaced every time an index is added or removed.
+	tableIndexes map[string][]*database.IndexInfo
 }
 
 func newCatalogCache() *catalogCache {
 	return &catalogCache{
-		tables:    make(map[string]Relation),
-		indexes:   make(map[string]Relation),
-		sequences: make(map[string]Relation),
+		tables:       make(map[string]Relation),
+		indexes:      make(map[string]Relation),
+		sequences:    make(map[string]Relation),
+		views:        make(map[string]Relation),
+		tableIndexes: make(map[string][]*database.IndexInfo),
 	}
 }
 
-func (c *catalogCache) load(tables []database.TableInfo, indexes []database.IndexInfo, sequences []database.Sequence) {
+func (c *catalogCache) load(tables []database.TableInfo, indexes []database.IndexInfo, sequences []database.Sequence, views []database.ViewInfo) {
 	for i := range tables {
 		c.tables[tables[i].TableName] = &tables[i]
 	}
 
 	for i := range indexes {
 		c.indexes[indexes[i].IndexName] = &indexes[i]
+		c.addTableIndex(&indexes[i])
 	}
 
 	for i := range sequences {
 		c.sequences[sequences[i].Info.Name] = &sequences[i]
 	}
+
+	for i := range views {
+		c.views[views[i].ViewName] = &views[i]
+	}
 }
 
 // TODO put in tests
@@ -53,9 +68,15 @@ func (c *catalogCache) Clone() *catalogCache {
 	for k, v := range c.indexes {
 		clone.indexes[k] = v
 	}
+	for k, v := range c.tableIndexes {
+		clone.tableIndexes[k] = v
+	}
 	for k, v := range c.sequences {
 		clone.sequences[k] = v
 	}
+	for k, v := range c.views {
+		clone.views[k] = v
+	}
 
 	return clone
 }
@@ -76,6 +97,11 @@ func (c *catalogCache) objectExists(name string) bool {
 		return true
 	}
 
+	// checking if view exists with the same name
+	if _, ok := c.views[name]; ok {
+		return true
+	}
+
 	return false
 }
 
@@ -102,6 +128,8 @@ func (c *catalogCache) getMapByType(tp string) map[string]Relation {
 		return c.indexes
 	case RelationSequenceType:
 		return c.sequences
+	case RelationViewType:
+		return c.views
 	}
 
 	panic(stringutil.Sprintf("unknown catalog object type %q", tp))
@@ -123,9 +151,11 @@ func (c *catalogCache) Add(tx *database.Transaction, o Relation) error {
 
 	m := c.getMapByType(o.Type())
 	m[name] = o
+	c.addTableIndex(o)
 
 	tx.OnRollbackHooks = append(tx.OnRollbackHooks, func() {
 		delete(m, name)
+		c.removeTableIndex(o)
 	})
 
 	return nil
@@ -140,9 +170,13 @@ func (c *catalogCache) Replace(tx *database.Transaction, o Relation) error {
 	}
 
 	m[o.Name()] = o
+	c.removeTableIndex(old)
+	c.addTableIndex(o)
 
 	tx.OnRollbackHooks = append(tx.OnRollbackHooks, func() {
 		m[o.Name()] = old
+		c.removeTableIndex(o)
+		c.addTableIndex(old)
 	})
 
 	return nil
@@ -157,9 +191,11 @@ func (c *catalogCache) Delete(tx *database.Transaction, tp, name string) (Relati
 	}
 
 	delete(m, name)
+	c.removeTableIndex(o)
 
 	tx.OnRollbackHooks = append(tx.OnRollbackHooks, func() {
 		m[name] = o
+		c.addTableIndex(o)
 	})
 
 	return o, nil
@@ -188,15 +224,49 @@ func (c *catalogCache) ListObjects(tp string) []string {
 	return list
 }
 
+// GetTableIndexes returns the indexes of the given table, sorted by name.
+// The returned slice must not be modified.
 func (c *catalogCache) GetTableIndexes(tableName string) []*database.IndexInfo {
-	var indexes []*database.IndexInfo
-	for _, o := range c.indexes {
-		idx := o.(*database.IndexInfo)
-		if idx.TableName != tableName {
-			continue
+	return c.tableIndexes[tableName]
+}
+
+// addTableIndex adds o to the list of indexes of its table, if o is an index.
+func (c *catalogCache) addTableIndex(o Relation) {
+	info, ok := o.(*database.IndexInfo)
+	if !ok {
+		return
+	}
+
+	old := c.tableIndexes[info.TableName]
+	indexes := make([]*database.IndexInfo, 0, len(old)+1)
+	indexes = append(indexes, old...)
+	indexes = append(indexes, info)
+	sort.Slice(indexes, func(i, j int) bool {
+		return indexes[i].IndexName < indexes[j].IndexName
+	})
+
+	c.tableIndexes[info.TableName] = indexes
+}
+
+// removeTableIndex removes o from the list of indexes of its table, if o is an index.
+func (c *catalogCache) removeTableIndex(o Relation) {
+	info, ok := o.(*database.IndexInfo)
+	if !ok {
+		return
+	}
+
+	old := c.tableIndexes[info.TableName]
+	indexes := make([]*database.IndexInfo, 0, len(old))
+	for _, idx := range old {
+		if idx != info {
+			indexes = append(indexes, idx)
 		}
-		indexes = append(indexes, idx)
 	}
 
-	return indexes
+	if len(indexes) == 0 {
+		delete(c.tableIndexes, info.TableName)
+		return
+	}
+
+	c.tableIndexes[info.TableName] = indexes
 }
@@ -1,10 +1,12 @@
 package catalog
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"math"
 	"sort"
+	"strings"
 
 	"github.com/genjidb/genji/document"
 	errs "github.com/genjidb/genji/errors"
@@ -17,10 +19,11 @@ const (
 	RelationTableType    = "table"
 	RelationIndexType    = "index"
 	RelationSequenceType = "sequence"
+	RelationViewType     = "view"
 	StoreSequence        = database.InternalPrefix + "store_seq"
 )
 
-// Catalog manages all database objects such as tables, indexes and sequences.
+// Catalog manages all database objects such as tables, indexes, sequences and views.
 // It stores all these objects in memory for fast access. Any modification
 // is persisted into the __genji_catalog table.
 type Catalog struct {
@@ -70,7 +73,7 @@ func (c *Catalog) Load(tx *database.Transaction) error {
 }
 
 func (c *Catalog) loadCatalog(tx *database.Transaction) error {
-	tables, indexes, sequences, err := c.CatalogTable.Load(tx)
+	tables, indexes, sequences, views, err := c.CatalogTable.Load(tx)
 	if err != nil {
 		return err
 	}
@@ -93,8 +96,8 @@ func (c *Catalog) loadCatalog(tx *database.Transaction) error {
 	ti.ReadOnly = true
 	tables = append(tables, *ti)
 
-	// load tables and indexes first
-	c.Cache.load(tables, indexes, nil)
+	// load tables, indexes and views first
+	c.Cache.load(tables, indexes, nil, views)
 
 	if len(sequences) > 0 {
 		var seqList []database.Sequence
@@ -103,7 +106,7 @@ func (c *Catalog) loadCatalog(tx *database.Transaction) error {
 			return err
 		}
 
-		c.Cache.load(nil, nil, seqList)
+		c.Cache.load(nil, nil, seqList, nil)
 	}
 
 	return nil
@@ -186,6 +189,21 @@ func (c *Catalog) GetTableInfo(tableName string) (*database.TableInfo, error) {
 	return r.(*database.TableInfo), nil
 }
 
+// ListTables returns all table names sorted lexicographically.
+// Internal tables are not listed.
+func (c *Catalog) ListTables() []string {
+	all := c.Cache.ListObjects(RelationTableType)
+	list := make([]string, 0, len(all))
+	for _, name := range all {
+		if strings.HasPrefix(name, database.InternalPrefix) {
+			continue
+		}
+		list = append(list, name)
+	}
+
+	return list
+}
+
 // CreateTable creates a table with the given name.
 // If it already exists, returns ErrTableAlreadyExists.
 func (c *Catalog) CreateTable(tx *database.Transaction, tableName string, info *database.TableInfo) error {
@@ -217,6 +235,10 @@ func (c *Catalog) CreateTable(tx *database.Transaction, tableName string, info *
 		if err != nil {
 			return err
 		}
+
+		if info.Engine != "" {
+			info.StoreName = database.PlacedStoreName(info.Engine, info.StoreName)
+		}
 	}
 
 	// bind default values with catalog
@@ -275,6 +297,12 @@ func (c *Catalog) DropTable(tx *database.Transaction, tableName string) error {
 		return err
 	}
 
+	t := database.Table{Tx: tx, Info: ti, Catalog: c}
+	err = t.DeleteAttachments()
+	if err != nil {
+		return err
+	}
+
 	return tx.Tx.DropStore(ti.StoreName)
 }
 
@@ -314,6 +342,11 @@ OUTER:
 		if err != nil {
 			return err
 		}
+
+		// indexes are stored in the same engine as their table
+		if ti.Engine != "" {
+			info.StoreName = database.PlacedStoreName(ti.Engine, info.StoreName)
+		}
 	}
 
 	err = c.Cache.Add(tx, info)
@@ -375,7 +408,6 @@ func (c *Catalog) ListIndexes(tableName string) []string {
 		list = append(list, idx.IndexName)
 	}
 
-	sort.Strings(list)
 	return list
 }
 
@@ -433,6 +465,89 @@ func (c *Catalog) AddFieldConstraint(tx *database.Transaction, tableName string,
 	return c.CatalogTable.Replace(tx, tableName, clone)
 }
 
+// SetReadOnly sets whether a table can be written to or not.
+func (c *Catalog) SetReadOnly(tx *database.Transaction, tableName string, readOnly bool) error {
+	if strings.HasPrefix(tableName, database.InternalPrefix) {
+		return stringutil.Errorf("cannot alter internal table %q", tableName)
+	}
+
+	r, err := c.Cache.Get(RelationTableType, tableName)
+	if err != nil {
+		return err
+	}
+	ti := r.(*database.TableInfo)
+
+	clone := ti.Clone()
+	clone.ReadOnly = readOnly
+
+	err = c.Cache.Replace(tx, clone)
+	if err != nil {
+		return err
+	}
+
+	return c.CatalogTable.Replace(tx, tableName, clone)
+}
+
+// SetFieldMask sets the mask of a field of a table, replacing the existing one if any.
+func (c *Catalog) SetFieldMask(tx *database.Transaction, tableName string, mask database.FieldMask) error {
+	if strings.HasPrefix(tableName, database.InternalPrefix) {
+		return stringutil.Errorf("cannot alter internal table %q", tableName)
+	}
+
+	r, err := c.Cache.Get(RelationTableType, tableName)
+	if err != nil {
+		return err
+	}
+	ti := r.(*database.TableInfo)
+
+	for _, pk := range ti.FieldConstraints.GetPrimaryKeys() {
+		if pk.Path.IsEqual(mask.Path) {
+			return stringutil.Errorf("cannot mask primary key %q", mask.Path)
+		}
+	}
+
+	clone := ti.Clone()
+	if m := clone.GetMask(mask.Path); m != nil {
+		*m = mask
+	} else {
+		clone.Masks = append(clone.Masks, mask)
+	}
+
+	err = c.Cache.Replace(tx, clone)
+	if err != nil {
+		return err
+	}
+
+	return c.CatalogTable.Replace(tx, tableName, clone)
+}
+
+// DropFieldMask removes the mask of a field of a table.
+func (c *Catalog) DropFieldMask(tx *database.Transaction, tableName string, path document.Path) error {
+	r, err := c.Cache.Get(RelationTableType, tableName)
+	if err != nil {
+		return err
+	}
+	ti := r.(*database.TableInfo)
+
+	clone := ti.Clone()
+	clone.Masks = clone.Masks[:0]
+	for _, m := range ti.Masks {
+		if !m.Path.IsEqual(path) {
+			clone.Masks = append(clone.Masks, m)
+		}
+	}
+	if len(clone.Masks) == len(ti.Masks) {
+		return stringutil.Errorf("field %q of table %q has no mask", path, tableName)
+	}
+
+	err = c.Cache.Replace(tx, clone)
+	if err != nil {
+		return err
+	}
+
+	return c.CatalogTable.Replace(tx, tableName, clone)
+}
+
 // RenameTable renames a table.
 // If it doesn't exist, it returns errs.ErrTableNotFound.
 func (c *Catalog) RenameTable(tx *database.Transaction, oldName, newName string) error {
@@ -506,30 +621,108 @@ func (c *Catalog) ReIndex(tx *database.Transaction, indexName string) error {
 		return err
 	}
 
-	return c.buildIndex(tx, idx, tb)
+	err = c.buildIndex(tx, idx, tb)
+	if err != nil || !idx.Info.Rebuilding {
+		return err
+	}
+
+	// the index is complete again if it was being rebuilt by ReIndexBatch
+	clone := idx.Info.Clone()
+	clone.Rebuilding = false
+	return c.Cache.Replace(tx, clone)
 }
 
 func (c *Catalog) buildIndex(tx *database.Transaction, idx *database.Index, table *database.Table) error {
 	return table.Iterate(func(d document.Document) error {
-		var err error
-		values := make([]document.Value, len(idx.Info.Paths))
-		for i, path := range idx.Info.Paths {
-			values[i], err = path.GetValueFromDocument(d)
-			if err == document.ErrFieldNotFound {
-				return nil
-			}
-			if err != nil {
-				return err
-			}
+		return indexDocument(idx, d, false)
+	})
+}
+
+// indexDocument adds the values of d to the index.
+// If skipIndexed is true, documents already present in a unique index are ignored.
+func indexDocument(idx *database.Index, d document.Document, skipIndexed bool) error {
+	var err error
+	values := make([]document.Value, len(idx.Info.Paths))
+	for i, path := range idx.Info.Paths {
+		values[i], err = path.GetValueFromDocument(d)
+		if err == document.ErrFieldNotFound {
+			return nil
 		}
+		if err != nil {
+			return err
+		}
+	}
 
-		err = idx.Set(values, d.(document.Keyer).RawKey())
+	key := d.(document.Keyer).RawKey()
+	err = idx.Set(values, key)
+	if err == database.ErrIndexDuplicateValue && skipIndexed {
+		ok, k, err := idx.Exists(values)
+		if err != nil || (ok && bytes.Equal(k, key)) {
+			return err
+		}
+	}
+	if err != nil {
+		return stringutil.Errorf("error while building the index: %w", err)
+	}
+
+	return nil
+}
+
+// errBatchFull is used to stop the iteration once a batch is full.
+var errBatchFull = errors.New("batch full")
+
+// ReIndexBatch rebuilds the selected index in chunks, to avoid indexing a large
+// table in a single transaction. It indexes at most limit documents, starting from the
+// document stored under the key from, and returns the number of documents indexed and
+// the key to pass to the next call, or nil once the whole table has been indexed.
+// If from is nil, the index is truncated first.
+// Between two calls, the index is flagged as rebuilding: it is kept up to date by
+// the writes made to the table, but not used by queries.
+func (c *Catalog) ReIndexBatch(tx *database.Transaction, indexName string, from []byte, limit int) ([]byte, int, error) {
+	idx, err := c.GetIndex(tx, indexName)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	tb, err := c.GetTable(tx, idx.Info.TableName)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if from == nil {
+		err = idx.Truncate()
 		if err != nil {
-			return stringutil.Errorf("error while building the index: %w", err)
+			return nil, 0, err
 		}
+	}
 
-		return nil
+	var next []byte
+	var n int
+	err = tb.AscendFromKey(from, func(d document.Document) error {
+		if n == limit {
+			next = append([]byte{}, d.(document.Keyer).RawKey()...)
+			return errBatchFull
+		}
+
+		n++
+		// documents written since the previous batch are already indexed
+		return indexDocument(idx, d, from != nil)
 	})
+	if err != nil && err != errBatchFull {
+		return nil, 0, err
+	}
+
+	// flag the index until the last batch
+	if rebuilding := next != nil; rebuilding != idx.Info.Rebuilding {
+		clone := idx.Info.Clone()
+		clone.Rebuilding = rebuilding
+		err = c.Cache.Replace(tx, clone)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return next, n, nil
 }
 
 // ReIndexAll truncates and recreates all indexes of the database from scratch.
@@ -546,6 +739,24 @@ func (c *Catalog) ReIndexAll(tx *database.Transaction) error {
 	return nil
 }
 
+// Analyze collects statistics about the content of the selected index
+// and stores them in the index information, to be used by the planner.
+func (c *Catalog) Analyze(tx *database.Transaction, indexName string) error {
+	idx, err := c.GetIndex(tx, indexName)
+	if err != nil {
+		return err
+	}
+
+	stats, err := idx.Analyze()
+	if err != nil {
+		return err
+	}
+
+	clone := idx.Info.Clone()
+	clone.Statistics = stats
+	return c.Cache.Replace(tx, clone)
+}
+
 func (c *Catalog) GetSequence(name string) (*database.Sequence, error) {
 	r, err := c.Cache.Get(RelationSequenceType, name)
 	if err != nil {
@@ -602,3 +813,46 @@ func (c *Catalog) DropSequence(tx *database.Transaction, name string) error {
 func (c *Catalog) ListSequences() []string {
 	return c.Cache.ListObjects(RelationSequenceType)
 }
+
+// GetViewInfo returns the information of the view with the given name.
+func (c *Catalog) GetViewInfo(name string) (*database.ViewInfo, error) {
+	r, err := c.Cache.Get(RelationViewType, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.(*database.ViewInfo), nil
+}
+
+// CreateView creates a view with the given name.
+func (c *Catalog) CreateView(tx *database.Transaction, info *database.ViewInfo) error {
+	if info.ViewName == "" {
+		return errors.New("view name not provided")
+	}
+
+	if strings.HasPrefix(info.ViewName, database.InternalPrefix) {
+		return stringutil.Errorf("view name must not start with %s", database.InternalPrefix)
+	}
+
+	err := c.Cache.Add(tx, info)
+	if err != nil {
+		return err
+	}
+
+	return c.CatalogTable.Insert(tx, info)
+}
+
+// DropView deletes a view from the catalog.
+func (c *Catalog) DropView(tx *database.Transaction, name string) error {
+	_, err := c.Cache.Delete(tx, RelationViewType, name)
+	if err != nil {
+		return err
+	}
+
+	return c.CatalogTable.Delete(tx, name)
+}
+
+// ListViews returns all view names sorted lexicographically.
+func (c *Catalog) ListViews() []string {
+	return c.Cache.ListObjects(RelationViewType)
+}
@@ -451,10 +451,13 @@ func TestTxDropIndex(t *testing.T) {
 			_, err = catalog.GetTable(tx, "test")
 			require.NoError(t, err)
 
+			require.Equal(t, []string{"idxBar"}, catalog.ListIndexes("test"))
+
 			return errDontCommit
 		})
 
 		require.Equal(t, clone, db.Catalog)
+		require.Equal(t, []string{"idxBar", "idxFoo"}, db.Catalog.ListIndexes("test"))
 	})
 
 	t.Run("Should fail if it doesn't exist", func(t *testing.T) {
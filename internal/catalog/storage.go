@@ -1,12 +1,14 @@
 package catalog
 
 import (
+	"errors"
 	"strings"
 
 	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/engine"
 	errs "github.com/genjidb/genji/errors"
 	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/expr"
 	"github.com/genjidb/genji/internal/query/statement"
 	"github.com/genjidb/genji/internal/sql/parser"
 	"github.com/genjidb/genji/internal/stringutil"
@@ -20,6 +22,8 @@ func relationToDocument(r Relation) document.Document {
 		return indexInfoToDocument(t)
 	case *database.Sequence:
 		return sequenceInfoToDocument(t.Info)
+	case *database.ViewInfo:
+		return viewInfoToDocument(t)
 	}
 
 	panic(stringutil.Sprintf("objectToDocument: unknown type %q", r.Type()))
@@ -34,6 +38,29 @@ func tableInfoToDocument(ti *database.TableInfo) document.Document {
 	if ti.DocidSequenceName != "" {
 		buf.Add("docid_sequence_name", document.NewTextValue(ti.DocidSequenceName))
 	}
+	if ti.ReadOnly {
+		buf.Add("read_only", document.NewBoolValue(true))
+	}
+	identities := document.NewValueBuffer()
+	for _, fc := range ti.FieldConstraints {
+		if fc.Identity != nil && fc.Identity.SequenceName != "" {
+			identities = identities.Append(document.NewDocumentValue(document.NewFieldBuffer().
+				Add("path", document.NewTextValue(fc.Path.String())).
+				Add("sequence_name", document.NewTextValue(fc.Identity.SequenceName))))
+		}
+	}
+	if identities.Len() > 0 {
+		buf.Add("identities", document.NewArrayValue(identities))
+	}
+	if len(ti.Masks) > 0 {
+		masks := document.NewValueBuffer()
+		for _, m := range ti.Masks {
+			masks = masks.Append(document.NewDocumentValue(document.NewFieldBuffer().
+				Add("path", document.NewTextValue(m.Path.String())).
+				Add("expr", document.NewTextValue(m.Expr.String()))))
+		}
+		buf.Add("masks", document.NewArrayValue(masks))
+	}
 
 	return buf
 }
@@ -65,9 +92,98 @@ func tableInfoFromDocument(d document.Document) (*database.TableInfo, error) {
 		ti.DocidSequenceName = v.V.(string)
 	}
 
+	v, err = d.GetByField("read_only")
+	if err != nil && err != document.ErrFieldNotFound {
+		return nil, err
+	}
+	if err == nil {
+		ti.ReadOnly = v.V.(bool)
+	}
+
+	v, err = d.GetByField("identities")
+	if err != nil && err != document.ErrFieldNotFound {
+		return nil, err
+	}
+	if err == nil {
+		err = identitiesFromArray(ti.FieldConstraints, v.V.(document.Array))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	v, err = d.GetByField("masks")
+	if err != nil && err != document.ErrFieldNotFound {
+		return nil, err
+	}
+	if err == nil {
+		ti.Masks, err = masksFromArray(v.V.(document.Array))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &ti, nil
 }
 
+// identitiesFromArray sets the name of the sequences of the identity fields.
+func identitiesFromArray(fcs database.FieldConstraints, a document.Array) error {
+	return a.Iterate(func(i int, v document.Value) error {
+		d := v.V.(document.Document)
+
+		p, err := d.GetByField("path")
+		if err != nil {
+			return err
+		}
+		path, err := parser.ParsePath(p.V.(string))
+		if err != nil {
+			return err
+		}
+
+		name, err := d.GetByField("sequence_name")
+		if err != nil {
+			return err
+		}
+
+		fc := fcs.Get(path)
+		if fc == nil || fc.Identity == nil {
+			return stringutil.Errorf("no AUTOINCREMENT field %q", path)
+		}
+		fc.Identity.SequenceName = name.V.(string)
+		return nil
+	})
+}
+
+func masksFromArray(a document.Array) ([]database.FieldMask, error) {
+	var masks []database.FieldMask
+
+	err := a.Iterate(func(i int, v document.Value) error {
+		d := v.V.(document.Document)
+
+		p, err := d.GetByField("path")
+		if err != nil {
+			return err
+		}
+		path, err := parser.ParsePath(p.V.(string))
+		if err != nil {
+			return err
+		}
+
+		e, err := d.GetByField("expr")
+		if err != nil {
+			return err
+		}
+		ex, err := parser.ParseExpr(e.V.(string))
+		if err != nil {
+			return err
+		}
+
+		masks = append(masks, database.FieldMask{Path: path, Expr: expr.Mask(ex)})
+		return nil
+	})
+
+	return masks, err
+}
+
 func indexInfoToDocument(i *database.IndexInfo) document.Document {
 	buf := document.NewFieldBuffer()
 	buf.Add("name", document.NewTextValue(i.IndexName))
@@ -162,6 +278,30 @@ func sequenceInfoFromDocument(d document.Document) (*database.SequenceInfo, erro
 	return &i, nil
 }
 
+func viewInfoToDocument(v *database.ViewInfo) document.Document {
+	buf := document.NewFieldBuffer()
+	buf.Add("name", document.NewTextValue(v.ViewName))
+	buf.Add("type", document.NewTextValue(RelationViewType))
+	buf.Add("sql", document.NewTextValue(v.String()))
+
+	return buf
+}
+
+func viewInfoFromDocument(d document.Document) (*database.ViewInfo, error) {
+	s, err := d.GetByField("sql")
+	if err != nil {
+		return nil, err
+	}
+
+	stmt, err := parser.NewParser(strings.NewReader(s.V.(string))).ParseStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	i := stmt.(*statement.CreateViewStmt).Info
+	return &i, nil
+}
+
 func ownerToDocument(owner *database.Owner) document.Document {
 	buf := document.NewFieldBuffer().Add("table_name", document.NewTextValue(owner.TableName))
 	if owner.Path != nil {
@@ -262,7 +402,7 @@ func (s *CatalogTable) Init(tx *database.Transaction) error {
 	return err
 }
 
-func (s *CatalogTable) Load(tx *database.Transaction) (tables []database.TableInfo, indexes []database.IndexInfo, sequences []database.SequenceInfo, err error) {
+func (s *CatalogTable) Load(tx *database.Transaction) (tables []database.TableInfo, indexes []database.IndexInfo, sequences []database.SequenceInfo, views []database.ViewInfo, err error) {
 	tb := s.Table(tx)
 
 	err = tb.AscendGreaterOrEqual(document.Value{}, func(d document.Document) error {
@@ -291,6 +431,12 @@ func (s *CatalogTable) Load(tx *database.Transaction) (tables []database.TableIn
 				return err
 			}
 			sequences = append(sequences, *i)
+		case RelationViewType:
+			v, err := viewInfoFromDocument(d)
+			if err != nil {
+				return err
+			}
+			views = append(views, *v)
 		}
 
 		return nil
@@ -317,7 +463,7 @@ func (s *CatalogTable) Insert(tx *database.Transaction, r Relation) error {
 	tb := s.Table(tx)
 
 	_, err := tb.Insert(relationToDocument(r))
-	if err == errs.ErrDuplicateDocument {
+	if errors.Is(err, errs.ErrDuplicateDocument) {
 		return errs.AlreadyExistsError{Name: r.Name()}
 	}
 
@@ -0,0 +1,198 @@
+package database
+
+import (
+	"encoding/binary"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/engine"
+)
+
+const (
+	// attachmentStoreName is the name of the store holding the values
+	// stored out of row, for every table.
+	attachmentStoreName = InternalPrefix + "attachments"
+
+	// attachmentField is the only field of the placeholder document stored
+	// in the table in place of an attached value. It holds the type of the value.
+	attachmentField = InternalPrefix + "attachment"
+)
+
+// attachmentPrefix returns the prefix of the keys of the attachments of the table
+// stored in storeName and, if docKey is not nil, of the document identified by docKey.
+func attachmentPrefix(storeName, docKey []byte) []byte {
+	buf := make([]byte, 0, 2*binary.MaxVarintLen64+len(storeName)+len(docKey))
+
+	buf = appendUvarint(buf, uint64(len(storeName)))
+	buf = append(buf, storeName...)
+	if docKey == nil {
+		return buf
+	}
+
+	buf = appendUvarint(buf, uint64(len(docKey)))
+	return append(buf, docKey...)
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	return append(buf, tmp[:n]...)
+}
+
+// detachValues stores the top-level blob and text values of d larger than the attachment threshold
+// of the transaction in the attachment store, and returns a document where they are replaced
+// by placeholders. The previous attachments of the document are deleted.
+// If the threshold is zero or if no value is large enough, d is returned.
+func (t *Table) detachValues(key []byte, d document.Document) (document.Document, error) {
+	prefix := attachmentPrefix(t.Info.StoreName, key)
+
+	err := t.deleteAttachments(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold := t.Tx.AttachmentThreshold
+	if threshold <= 0 {
+		return d, nil
+	}
+
+	var fb document.FieldBuffer
+	var st engine.Store
+
+	err = d.Iterate(func(field string, v document.Value) error {
+		var data []byte
+		switch v.Type {
+		case document.BlobValue:
+			data = v.V.([]byte)
+		case document.TextValue:
+			data = []byte(v.V.(string))
+		}
+
+		if len(data) <= threshold {
+			fb.Add(field, v)
+			return nil
+		}
+
+		if st == nil {
+			var err error
+			st, err = getOrCreateStore(t.Tx.Tx, []byte(attachmentStoreName))
+			if err != nil {
+				return err
+			}
+		}
+
+		err := st.Put(append(prefix[:len(prefix):len(prefix)], field...), data)
+		if err != nil {
+			return err
+		}
+
+		fb.Add(field, document.NewDocumentValue(
+			document.NewFieldBuffer().Add(attachmentField, document.NewIntegerValue(int64(v.Type)))))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if st == nil {
+		return d, nil
+	}
+
+	return &fb, nil
+}
+
+// attachedValue returns the value stored out of row for the given field of the document
+// identified by key, if v is a placeholder. Otherwise, it returns v.
+func (t *Table) attachedValue(key []byte, field string, v document.Value) (document.Value, error) {
+	if v.Type != document.DocumentValue {
+		return v, nil
+	}
+
+	tp, err := v.V.(document.Document).GetByField(attachmentField)
+	if err != nil || tp.Type != document.IntegerValue {
+		return v, nil
+	}
+
+	st, err := t.Tx.Tx.GetStore([]byte(attachmentStoreName))
+	if err != nil {
+		return v, err
+	}
+
+	data, err := st.Get(append(attachmentPrefix(t.Info.StoreName, key), field...))
+	if err != nil {
+		return v, err
+	}
+
+	if document.ValueType(tp.V.(int64)) == document.TextValue {
+		return document.NewTextValue(string(data)), nil
+	}
+
+	return document.NewBlobValue(data), nil
+}
+
+// deleteAttachments deletes every attachment whose key starts with prefix.
+func (t *Table) deleteAttachments(prefix []byte) error {
+	st, err := t.Tx.Tx.GetStore([]byte(attachmentStoreName))
+	if err == engine.ErrStoreNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var keys [][]byte
+	it := st.Iterator(engine.IteratorOptions{Prefix: prefix})
+	for it.Seek(nil); it.Valid(); it.Next() {
+		keys = append(keys, append([]byte{}, it.Item().Key()...))
+	}
+	err = it.Err()
+	if cerr := it.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		err = st.Delete(k)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteAttachments deletes the values of every document of the table stored out of row.
+// It must be called when the table is dropped.
+func (t *Table) DeleteAttachments() error {
+	return t.deleteAttachments(attachmentPrefix(t.Info.StoreName, nil))
+}
+
+// attachedDocument resolves the placeholders of the values
+// of a document stored out of row.
+type attachedDocument struct {
+	document.Document
+
+	table *Table
+	key   []byte
+}
+
+func (d *attachedDocument) GetByField(field string) (document.Value, error) {
+	v, err := d.Document.GetByField(field)
+	if err != nil {
+		return v, err
+	}
+
+	return d.table.attachedValue(d.key, field, v)
+}
+
+func (d *attachedDocument) Iterate(fn func(field string, value document.Value) error) error {
+	return d.Document.Iterate(func(field string, v document.Value) error {
+		v, err := d.table.attachedValue(d.key, field, v)
+		if err != nil {
+			return err
+		}
+
+		return fn(field, v)
+	})
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+
+	"github.com/genjidb/genji/document"
+)
+
+// AuditTimeFormat is the format of the "at" field of the audit entries.
+// Times are stored in UTC, which allows comparing them as text.
+const AuditTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+type userContextKey struct{}
+
+// WithUser returns a copy of ctx associated with the given user.
+// Transactions started with this context record the user in the audit entries
+// they write.
+func WithUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, userContextKey{}, user)
+}
+
+// UserFromContext returns the user associated with ctx, if any.
+func UserFromContext(ctx context.Context) string {
+	user, _ := ctx.Value(userContextKey{}).(string)
+	return user
+}
+
+// AuditTableName returns the name of the table storing the audit trail
+// of the given table.
+func AuditTableName(tableName string) string {
+	return InternalPrefix + "audit_" + tableName
+}
+
+// audit records a mutation of the document identified by key in the audit table,
+// if the table is audited. Old is nil for insertions and new is nil for deletions.
+func (t *Table) audit(op string, key []byte, old, new document.Document) error {
+	if !t.Info.Audit {
+		return nil
+	}
+
+	at, err := t.Catalog.GetTable(t.Tx, AuditTableName(t.Info.TableName))
+	if err != nil {
+		return err
+	}
+
+	d := new
+	if d == nil {
+		d = old
+	}
+	k, err := documentWithKey{Document: d, key: key, pks: t.Info.FieldConstraints.GetPrimaryKeys(), docidStrategy: t.Info.DocidStrategy}.Key()
+	if err != nil {
+		return err
+	}
+
+	entry := document.NewFieldBuffer().
+		Add("op", document.NewTextValue(op)).
+		Add("pk", k).
+		Add("at", document.NewTextValue(t.Tx.Now().UTC().Format(AuditTimeFormat)))
+
+	if t.Tx.User != "" {
+		entry.Add("user", document.NewTextValue(t.Tx.User))
+	} else {
+		entry.Add("user", document.NewNullValue())
+	}
+
+	if old != nil {
+		entry.Add("old", document.NewDocumentValue(old))
+	}
+	if new != nil {
+		entry.Add("new", document.NewDocumentValue(new))
+	}
+
+	_, err = at.Insert(entry)
+	return err
+}
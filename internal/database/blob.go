@@ -0,0 +1,223 @@
+package database
+
+import (
+	"errors"
+	"io"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// size of the chunks in which blobs are modified.
+const blobChunkSize = 64 << 10
+
+// A Blob gives streaming access to a blob field of a document.
+// Reads are served directly from the value stored in the engine, without copying it,
+// and writes are buffered in chunks, so that only the modified parts of a large blob
+// are held in memory. Modifications are written to the table when the Blob is closed.
+// A Blob is only valid until its transaction ends and is not safe for concurrent use.
+type Blob struct {
+	table *Table
+	key   []byte
+	path  document.Path
+
+	// data of the stored blob. It must never be modified.
+	data []byte
+	// modified chunks, indexed by position in the blob.
+	chunks map[int64][]byte
+	size   int64
+	off    int64
+	closed bool
+}
+
+// OpenBlob opens the blob stored at the given path of the document identified by key.
+// If the value at path is NULL, the blob is empty.
+func (t *Table) OpenBlob(key []byte, path document.Path) (*Blob, error) {
+	d, err := t.GetDocument(key)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := path.GetValueFromDocument(d)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	switch v.Type {
+	case document.BlobValue:
+		data = v.V.([]byte)
+	case document.NullValue:
+	default:
+		return nil, stringutil.Errorf("cannot open value of type %s at path %s as blob", v.Type, path)
+	}
+
+	return &Blob{
+		table: t,
+		key:   key,
+		path:  path,
+		data:  data,
+		size:  int64(len(data)),
+	}, nil
+}
+
+// Size returns the current size of the blob.
+func (b *Blob) Size() int64 {
+	return b.size
+}
+
+// chunk returns the content of the n-th chunk, as stored or as modified.
+// Chunks beyond the end of the stored data are empty.
+func (b *Blob) chunk(n int64) []byte {
+	if c, ok := b.chunks[n]; ok {
+		return c
+	}
+
+	start := n * blobChunkSize
+	if start >= int64(len(b.data)) {
+		return nil
+	}
+
+	end := start + blobChunkSize
+	if end > int64(len(b.data)) {
+		end = int64(len(b.data))
+	}
+
+	return b.data[start:end]
+}
+
+// Read implements the io.Reader interface.
+func (b *Blob) Read(p []byte) (int, error) {
+	if b.closed {
+		return 0, errors.New("blob closed")
+	}
+
+	var n int
+	for n < len(p) && b.off < b.size {
+		c := b.chunk(b.off / blobChunkSize)
+		pos := b.off % blobChunkSize
+
+		// bytes available in the chunk. Parts of the blob that
+		// were never written, past the stored data, read as zeros.
+		avail := int64(blobChunkSize) - pos
+		if rem := b.size - b.off; rem < avail {
+			avail = rem
+		}
+		if avail > int64(len(p)-n) {
+			avail = int64(len(p) - n)
+		}
+
+		var m int64
+		if pos < int64(len(c)) {
+			m = int64(copy(p[n:n+int(avail)], c[pos:]))
+		}
+		for ; m < avail; m++ {
+			p[n+int(m)] = 0
+		}
+
+		n += int(avail)
+		b.off += avail
+	}
+
+	if n == 0 && len(p) > 0 {
+		return 0, io.EOF
+	}
+
+	return n, nil
+}
+
+// Write implements the io.Writer interface.
+// Writing past the end of the blob extends it.
+func (b *Blob) Write(p []byte) (int, error) {
+	if b.closed {
+		return 0, errors.New("blob closed")
+	}
+	if !b.table.Tx.Writable {
+		return 0, errors.New("cannot write blob in a read-only transaction")
+	}
+
+	var n int
+	for n < len(p) {
+		idx := b.off / blobChunkSize
+		pos := b.off % blobChunkSize
+
+		c, ok := b.chunks[idx]
+		if !ok {
+			c = make([]byte, blobChunkSize)
+			copy(c, b.chunk(idx))
+
+			if b.chunks == nil {
+				b.chunks = make(map[int64][]byte)
+			}
+			b.chunks[idx] = c
+		}
+
+		m := copy(c[pos:], p[n:])
+		n += m
+		b.off += int64(m)
+	}
+
+	if b.off > b.size {
+		b.size = b.off
+	}
+
+	return n, nil
+}
+
+// Seek implements the io.Seeker interface.
+func (b *Blob) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += b.off
+	case io.SeekEnd:
+		offset += b.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	b.off = offset
+	return offset, nil
+}
+
+// Close writes the modifications, if any, to the document.
+// Closing a blob more than once has no effect.
+func (b *Blob) Close() error {
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+
+	if len(b.chunks) == 0 && b.size == int64(len(b.data)) {
+		return nil
+	}
+
+	data := make([]byte, b.size)
+	for i := int64(0); i*blobChunkSize < b.size; i++ {
+		copy(data[i*blobChunkSize:], b.chunk(i))
+	}
+	b.chunks = nil
+
+	d, err := b.table.GetDocument(b.key)
+	if err != nil {
+		return err
+	}
+
+	var fb document.FieldBuffer
+	err = fb.Copy(d)
+	if err != nil {
+		return err
+	}
+
+	err = fb.Set(b.path, document.NewBlobValue(data))
+	if err != nil {
+		return err
+	}
+
+	_, err = b.table.Replace(b.key, &fb)
+	return err
+}
@@ -0,0 +1,121 @@
+package database_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/database"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlob(t *testing.T) {
+	// larger than a chunk
+	data := bytes.Repeat([]byte("0123456789"), 10000)
+
+	insert := func(t *testing.T, v document.Value) (*testBlobTable, func()) {
+		tb, cleanup := newTestTable(t)
+
+		d, err := tb.Insert(document.NewFieldBuffer().
+			Add("a", document.NewDocumentValue(document.NewFieldBuffer().Add("b", v))).
+			Add("c", document.NewTextValue("foo")))
+		require.NoError(t, err)
+
+		return &testBlobTable{t: t, tb: tb, key: d.(document.Keyer).RawKey()}, cleanup
+	}
+
+	t.Run("Read", func(t *testing.T) {
+		tb, cleanup := insert(t, document.NewBlobValue(data))
+		defer cleanup()
+
+		b := tb.open()
+		require.EqualValues(t, len(data), b.Size())
+
+		got, err := ioutil.ReadAll(b)
+		require.NoError(t, err)
+		require.Equal(t, data, got)
+
+		buf := make([]byte, 5)
+		_, err = b.Seek(-3, io.SeekEnd)
+		require.NoError(t, err)
+		n, err := b.Read(buf)
+		require.NoError(t, err)
+		require.Equal(t, "789", string(buf[:n]))
+		_, err = b.Read(buf)
+		require.Equal(t, io.EOF, err)
+
+		require.NoError(t, b.Close())
+	})
+
+	t.Run("Write", func(t *testing.T) {
+		tb, cleanup := insert(t, document.NewBlobValue(data))
+		defer cleanup()
+
+		b := tb.open()
+		_, err := b.Seek(65535, io.SeekStart)
+		require.NoError(t, err)
+		_, err = b.Write([]byte("abc"))
+		require.NoError(t, err)
+
+		// past the end, the gap is filled with zeros
+		_, err = b.Seek(int64(len(data)+2), io.SeekStart)
+		require.NoError(t, err)
+		_, err = b.Write([]byte("xyz"))
+		require.NoError(t, err)
+		require.EqualValues(t, len(data)+5, b.Size())
+		require.NoError(t, b.Close())
+
+		expected := append([]byte{}, data...)
+		copy(expected[65535:], "abc")
+		expected = append(expected, 0, 0, 'x', 'y', 'z')
+
+		d, err := tb.tb.GetDocument(tb.key)
+		require.NoError(t, err)
+		v, err := document.NewPath("a", "b").GetValueFromDocument(d)
+		require.NoError(t, err)
+		require.Equal(t, document.NewBlobValue(expected), v)
+
+		// other fields are kept
+		v, err = d.GetByField("c")
+		require.NoError(t, err)
+		require.Equal(t, document.NewTextValue("foo"), v)
+	})
+
+	t.Run("NULL", func(t *testing.T) {
+		tb, cleanup := insert(t, document.NewNullValue())
+		defer cleanup()
+
+		b := tb.open()
+		require.EqualValues(t, 0, b.Size())
+		_, err := b.Write([]byte("foo"))
+		require.NoError(t, err)
+		require.NoError(t, b.Close())
+
+		b = tb.open()
+		got, err := ioutil.ReadAll(b)
+		require.NoError(t, err)
+		require.Equal(t, "foo", string(got))
+	})
+
+	t.Run("Not a blob", func(t *testing.T) {
+		tb, cleanup := insert(t, document.NewTextValue("foo"))
+		defer cleanup()
+
+		_, err := tb.tb.OpenBlob(tb.key, document.NewPath("a", "b"))
+		require.Error(t, err)
+	})
+}
+
+type testBlobTable struct {
+	t   *testing.T
+	tb  *database.Table
+	key []byte
+}
+
+func (tb *testBlobTable) open() *database.Blob {
+	b, err := tb.tb.OpenBlob(tb.key, document.NewPath("a", "b"))
+	require.NoError(tb.t, err)
+	return b
+}
@@ -1,13 +1,19 @@
 package database
 
+import "github.com/genjidb/genji/document"
+
 type Catalog interface {
 	Load(tx *Transaction) error
 	GetTable(tx *Transaction, tableName string) (*Table, error)
 	GetTableInfo(tableName string) (*TableInfo, error)
+	ListTables() []string
 	CreateTable(tx *Transaction, tableName string, info *TableInfo) error
 	DropTable(tx *Transaction, tableName string) error
 	RenameTable(tx *Transaction, oldName, newName string) error
 	AddFieldConstraint(tx *Transaction, tableName string, fc FieldConstraint) error
+	SetReadOnly(tx *Transaction, tableName string, readOnly bool) error
+	SetFieldMask(tx *Transaction, tableName string, mask FieldMask) error
+	DropFieldMask(tx *Transaction, tableName string, path document.Path) error
 	GetIndex(tx *Transaction, indexName string) (*Index, error)
 	GetIndexInfo(indexName string) (*IndexInfo, error)
 	ListIndexes(tableName string) []string
@@ -15,8 +21,14 @@ type Catalog interface {
 	DropIndex(tx *Transaction, name string) error
 	ReIndex(tx *Transaction, indexName string) error
 	ReIndexAll(tx *Transaction) error
+	ReIndexBatch(tx *Transaction, indexName string, from []byte, limit int) ([]byte, int, error)
+	Analyze(tx *Transaction, indexName string) error
 	GetSequence(name string) (*Sequence, error)
 	CreateSequence(tx *Transaction, info *SequenceInfo) error
 	DropSequence(tx *Transaction, name string) error
 	ListSequences() []string
+	GetViewInfo(name string) (*ViewInfo, error)
+	CreateView(tx *Transaction, info *ViewInfo) error
+	DropView(tx *Transaction, name string) error
+	ListViews() []string
 }
@@ -0,0 +1,113 @@
+package database
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/genjidb/genji/document"
+)
+
+// Kinds of inconsistencies reported by Index.Check.
+const (
+	// An entry of the index refers to a document that doesn't exist.
+	IndexDanglingEntry = "dangling entry"
+	// An entry of the index doesn't match the values of the document it refers to.
+	IndexStaleEntry = "stale entry"
+	// A document is not indexed.
+	IndexMissingEntry = "missing entry"
+	// A unique index associates the same values with several documents.
+	IndexDuplicateValue = "duplicate value"
+)
+
+// An IndexInconsistency describes a discrepancy between an index and its table.
+type IndexInconsistency struct {
+	Kind string
+	// Raw key of the document concerned.
+	Key []byte
+}
+
+// Check verifies that every entry of the index refers to an existing document
+// with the same values, that every document of the table t is indexed, and that
+// unique indexes don't contain duplicate values.
+// It returns all the inconsistencies found instead of stopping at the first one.
+func (idx *Index) Check(t *Table) ([]IndexInconsistency, error) {
+	var problems []IndexInconsistency
+
+	// load the values of every entry, by document key
+	entries := make(map[string][][]byte)
+	var prev []byte
+	err := idx.AscendGreaterOrEqual(nil, func(val, key []byte) error {
+		if idx.Info.Unique && prev != nil && bytes.Equal(prev, val) {
+			problems = append(problems, IndexInconsistency{Kind: IndexDuplicateValue, Key: append([]byte{}, key...)})
+		}
+		prev = append(prev[:0], val...)
+
+		entries[string(key)] = append(entries[string(key)], append([]byte{}, val...))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = t.Iterate(func(d document.Document) error {
+		key := d.(document.Keyer).RawKey()
+		vals := entries[string(key)]
+		delete(entries, string(key))
+
+		expected, err := idx.encodeDocumentValues(d)
+		if err != nil {
+			return err
+		}
+
+		found := false
+		for _, val := range vals {
+			if expected != nil && !found && bytes.Equal(val, expected) {
+				found = true
+				continue
+			}
+
+			problems = append(problems, IndexInconsistency{Kind: IndexStaleEntry, Key: append([]byte{}, key...)})
+		}
+
+		if expected != nil && !found {
+			problems = append(problems, IndexInconsistency{Kind: IndexMissingEntry, Key: append([]byte{}, key...)})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the remaining entries refer to documents that don't exist
+	dangling := make([]string, 0, len(entries))
+	for key := range entries {
+		dangling = append(dangling, key)
+	}
+	sort.Strings(dangling)
+	for _, key := range dangling {
+		for range entries[key] {
+			problems = append(problems, IndexInconsistency{Kind: IndexDanglingEntry, Key: []byte(key)})
+		}
+	}
+
+	return problems, nil
+}
+
+// encodeDocumentValues returns the encoded values of d stored in the index,
+// or nil if d is not indexed because one of the indexed paths doesn't exist.
+func (idx *Index) encodeDocumentValues(d document.Document) ([]byte, error) {
+	vb := document.NewValueBuffer()
+	for _, path := range idx.Info.Paths {
+		v, err := path.GetValueFromDocument(d)
+		if err == document.ErrFieldNotFound {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		vb = vb.Append(v)
+	}
+
+	return idx.EncodeValueBuffer(vb)
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+
+	errs "github.com/genjidb/genji/errors"
+)
+
+// size of the checksum appended to encoded documents.
+const checksumSize = 4
+
+var checksumTable = crc32.MakeTable(crc32.Castagnoli)
+
+// appendChecksum appends the CRC-32 of the encoded document to it,
+// if the table was created with checksums.
+func (t *Table) appendChecksum(data []byte) []byte {
+	if !t.Info.Checksum {
+		return data
+	}
+
+	var sum [checksumSize]byte
+	binary.BigEndian.PutUint32(sum[:], crc32.Checksum(data, checksumTable))
+	return append(data, sum[:]...)
+}
+
+// verifyChecksum verifies the checksum of the document stored under key,
+// if the table was created with checksums, and returns the encoded document without it.
+// It returns a CorruptedDocumentError if the checksum doesn't match.
+func (t *Table) verifyChecksum(key, data []byte) ([]byte, error) {
+	if !t.Info.Checksum {
+		return data, nil
+	}
+
+	if len(data) < checksumSize {
+		return nil, errs.CorruptedDocumentError{TableName: t.Info.TableName, Key: key}
+	}
+
+	n := len(data) - checksumSize
+	if binary.BigEndian.Uint32(data[n:]) != crc32.Checksum(data[:n], checksumTable) {
+		return nil, errs.CorruptedDocumentError{TableName: t.Info.TableName, Key: key}
+	}
+
+	return data[:n], nil
+}
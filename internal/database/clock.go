@@ -0,0 +1,84 @@
+package database
+
+import (
+	"crypto/rand"
+	"io"
+	"sync"
+	"time"
+)
+
+// A Clock returns the current time. It is used to generate the values
+// depending on time, like ULID and snowflake docids and the timestamps of audit entries.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// SystemClock returns the time of the system.
+var SystemClock Clock = systemClock{}
+
+// lockedReader makes a source of random bytes safe for concurrent use.
+type lockedReader struct {
+	mu sync.Mutex
+	r  io.Reader
+}
+
+func (l *lockedReader) Read(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.r.Read(p)
+}
+
+// Now returns the time of the clock of the transaction.
+func (tx *Transaction) Now() time.Time {
+	if tx.Clock == nil {
+		return time.Now()
+	}
+
+	return tx.Clock.Now()
+}
+
+// Random returns the source of random bytes of the transaction.
+func (tx *Transaction) Random() io.Reader {
+	if tx.Rand == nil {
+		return rand.Reader
+	}
+
+	return tx.Rand
+}
+
+// SetClock sets the clock used to generate the values depending on time.
+// If nil, the time of the system is used.
+// It applies to the transactions started after this call.
+func (db *Database) SetClock(c Clock) {
+	db.sourcesMu.Lock()
+	defer db.sourcesMu.Unlock()
+
+	db.clock = c
+}
+
+// SetRand sets the source of random bytes used to generate random docids, ULIDs
+// and the nonces of encrypted values. It doesn't need to be safe for concurrent use.
+// If nil, crypto/rand is used.
+// It applies to the transactions started after this call.
+func (db *Database) SetRand(r io.Reader) {
+	db.sourcesMu.Lock()
+	defer db.sourcesMu.Unlock()
+
+	db.setRand(r)
+}
+
+func (db *Database) setRand(r io.Reader) {
+	if r == nil {
+		db.rand = nil
+		db.snowflakes = snowflakes
+		return
+	}
+
+	db.rand = &lockedReader{r: r}
+	db.snowflakes = newSnowflakeGenerator(db.rand)
+}
@@ -2,6 +2,8 @@ package database
 
 import (
 	"github.com/genjidb/genji/document"
+	errs "github.com/genjidb/genji/errors"
+	"github.com/genjidb/genji/internal/stringutil"
 )
 
 // OnInsertConflictAction is a function triggered when trying to insert a document that already exists.
@@ -26,8 +28,27 @@ func OnInsertConflictDoReplace(t *Table, key []byte, d document.Document, err er
 	}
 
 	return documentWithKey{
-		Document: d,
-		key:      key,
-		pk:       t.Info.FieldConstraints.GetPrimaryKey(),
+		Document:      d,
+		key:           key,
+		pks:           t.Info.FieldConstraints.GetPrimaryKeys(),
+		docidStrategy: t.Info.DocidStrategy,
 	}, nil
 }
+
+// OnInsertConflictOnConstraint returns an action that runs action only if the conflict
+// is caused by the constraint with the given name.
+// Other conflicts are returned as errors.
+func OnInsertConflictOnConstraint(name string, action OnInsertConflictAction) OnInsertConflictAction {
+	return func(t *Table, key []byte, d document.Document, err error) (document.Document, error) {
+		ce, ok := err.(errs.ConstraintViolationError)
+		if ok && ce.Name == name {
+			return action(t, key, d, err)
+		}
+
+		if !t.Info.FieldConstraints.HasConstraint(name) {
+			return nil, stringutil.Errorf("constraint %q not found in table %q", name, t.Info.TableName)
+		}
+
+		return nil, err
+	}
+}
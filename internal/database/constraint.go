@@ -1,21 +1,14 @@
 package database
 
 import (
+	"sort"
 	"strings"
 
 	"github.com/genjidb/genji/document"
+	errs "github.com/genjidb/genji/errors"
 	"github.com/genjidb/genji/internal/stringutil"
 )
 
-type ConstraintViolationError struct {
-	Constraint string
-	Path       document.Path
-}
-
-func (c *ConstraintViolationError) Error() string {
-	return stringutil.Sprintf("%s constraint error: %s", c.Constraint, c.Path)
-}
-
 // FieldConstraint describes constraints on a particular field.
 type FieldConstraint struct {
 	Path         document.Path
@@ -23,10 +16,18 @@ type FieldConstraint struct {
 	IsPrimaryKey bool
 	IsNotNull    bool
 	IsUnique     bool
-	DefaultValue TableExpression
-	Identity     *FieldConstraintIdentity
-	IsInferred   bool
-	InferredBy   []document.Path
+	// Names given to the primary key and unique constraints
+	// with CONSTRAINT name, if any.
+	PrimaryKeyName string
+	UniqueName     string
+	// Position of the field in a primary key made of several fields,
+	// i.e PRIMARY KEY (a, b). The name of such a primary key, if any,
+	// is held by the field at position 0.
+	PrimaryKeyPosition int
+	DefaultValue       TableExpression
+	Identity           *FieldConstraintIdentity
+	IsInferred         bool
+	InferredBy         []document.Path
 }
 
 // IsEqual compares f with other member by member.
@@ -40,7 +41,7 @@ func (f *FieldConstraint) IsEqual(other *FieldConstraint) bool {
 		return false
 	}
 
-	if f.IsPrimaryKey != other.IsPrimaryKey {
+	if f.IsPrimaryKey != other.IsPrimaryKey || f.PrimaryKeyPosition != other.PrimaryKeyPosition {
 		return false
 	}
 
@@ -48,6 +49,10 @@ func (f *FieldConstraint) IsEqual(other *FieldConstraint) bool {
 		return false
 	}
 
+	if f.PrimaryKeyName != other.PrimaryKeyName || f.UniqueName != other.UniqueName {
+		return false
+	}
+
 	if f.HasDefaultValue() != other.HasDefaultValue() {
 		return false
 	}
@@ -77,10 +82,12 @@ func (f *FieldConstraint) String() string {
 	}
 
 	if f.IsPrimaryKey {
+		writeConstraintName(&s, f.PrimaryKeyName)
 		s.WriteString(" PRIMARY KEY")
 	}
 
 	if f.IsUnique {
+		writeConstraintName(&s, f.UniqueName)
 		s.WriteString(" UNIQUE")
 	}
 
@@ -89,9 +96,20 @@ func (f *FieldConstraint) String() string {
 		s.WriteString(f.DefaultValue.String())
 	}
 
+	if f.Identity != nil {
+		s.WriteString(" AUTOINCREMENT")
+	}
+
 	return s.String()
 }
 
+func writeConstraintName(s *strings.Builder, name string) {
+	if name != "" {
+		s.WriteString(" CONSTRAINT ")
+		s.WriteString(stringutil.NormalizeIdentifier(name, '`'))
+	}
+}
+
 // MergeInferred adds the other.InferredBy to f.InferredBy and ensures there are no duplicates.
 func (f *FieldConstraint) MergeInferred(other *FieldConstraint) {
 	for _, by := range other.InferredBy {
@@ -135,15 +153,49 @@ func (f FieldConstraints) Get(path document.Path) *FieldConstraint {
 }
 
 // GetPrimaryKey returns the field constraint of the primary key.
-// Returns nil if there is no primary key.
+// Returns nil if there is no primary key or if it is made of several fields,
+// in which case GetPrimaryKeys must be used.
 func (f FieldConstraints) GetPrimaryKey() *FieldConstraint {
+	var pk *FieldConstraint
 	for _, fc := range f {
 		if fc.IsPrimaryKey {
-			return fc
+			if pk != nil {
+				return nil
+			}
+			pk = fc
 		}
 	}
 
-	return nil
+	return pk
+}
+
+// GetPrimaryKeys returns the field constraints of the fields the primary key
+// is made of, in the order they are encoded in the key.
+// Returns nil if there is no primary key.
+func (f FieldConstraints) GetPrimaryKeys() FieldConstraints {
+	var pks FieldConstraints
+	for _, fc := range f {
+		if fc.IsPrimaryKey {
+			pks = append(pks, fc)
+		}
+	}
+
+	sort.SliceStable(pks, func(i, j int) bool {
+		return pks[i].PrimaryKeyPosition < pks[j].PrimaryKeyPosition
+	})
+
+	return pks
+}
+
+// HasConstraint returns true if one of the constraints is named name.
+func (f FieldConstraints) HasConstraint(name string) bool {
+	for _, fc := range f {
+		if fc.PrimaryKeyName == name || fc.UniqueName == name {
+			return name != ""
+		}
+	}
+
+	return false
 }
 
 // Infer additional constraints based on user defined ones.
@@ -222,6 +274,7 @@ func (f *FieldConstraints) Add(newFc *FieldConstraint) error {
 			inferredFc.DefaultValue = nonInferredFc.DefaultValue
 			inferredFc.IsNotNull = nonInferredFc.IsNotNull
 			inferredFc.IsPrimaryKey = nonInferredFc.IsPrimaryKey
+			inferredFc.PrimaryKeyPosition = nonInferredFc.PrimaryKeyPosition
 
 			// detect if constraints are different
 			if !c.IsEqual(newFc) {
@@ -245,8 +298,9 @@ func (f *FieldConstraints) Add(newFc *FieldConstraint) error {
 			return nil
 		}
 
-		// ensure we don't have duplicate primary keys
-		if c.IsPrimaryKey && newFc.IsPrimaryKey {
+		// ensure we don't have duplicate primary keys.
+		// the fields of a composite primary key have different positions.
+		if c.IsPrimaryKey && newFc.IsPrimaryKey && c.PrimaryKeyPosition == newFc.PrimaryKeyPosition {
 			return stringutil.Errorf(
 				"multiple primary keys are not allowed (%q is primary key)",
 				c.Path.String(),
@@ -318,7 +372,98 @@ func (f FieldConstraints) ValidateDocument(tx *Transaction, d document.Document)
 		return nil, err
 	}
 
-	// ensure no field is missing
+	err = f.checkNotNull(fb, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return fb, nil
+}
+
+// ValidateDocument converts the document using the field constraints of the table
+// and ensures it validates against them, without requiring a transaction.
+// Default values are not generated, fields having one are allowed to be missing.
+func ValidateDocument(ti *TableInfo, d document.Document) (*document.FieldBuffer, error) {
+	fb, err := ti.FieldConstraints.ConvertDocument(d)
+	if err != nil {
+		return nil, withTableName(err, ti.TableName)
+	}
+
+	err = ti.FieldConstraints.checkNotNull(fb, true)
+	if err != nil {
+		return nil, withTableName(err, ti.TableName)
+	}
+
+	return fb, nil
+}
+
+// ValidateDocumentAll is like ValidateDocument, but instead of failing on the first
+// constraint violation, it returns an errors.ValidationError reporting all of them.
+// Values violating a type constraint are left unconverted in the returned document.
+func ValidateDocumentAll(ti *TableInfo, d document.Document) (*document.FieldBuffer, error) {
+	var violations []errs.ConstraintViolationError
+
+	fb, err := ti.FieldConstraints.convertDocumentAtPath(nil, d, func(v document.Value, path document.Path, targetType document.ValueType) (document.Value, error) {
+		newV, err := CastConversion(v, path, targetType)
+		if ce, ok := err.(errs.ConstraintViolationError); ok {
+			violations = append(violations, ce)
+			return v, nil
+		}
+
+		return newV, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	notNull, err := ti.FieldConstraints.notNullViolations(fb, true)
+	if err != nil {
+		return nil, err
+	}
+	violations = append(violations, notNull...)
+
+	if len(violations) == 0 {
+		return fb, nil
+	}
+
+	for i := range violations {
+		violations[i].TableName = ti.TableName
+	}
+
+	return fb, errs.ValidationError{TableName: ti.TableName, Violations: violations}
+}
+
+// withTableName sets the table of constraint violation errors,
+// which field constraints don't know about.
+func withTableName(err error, tableName string) error {
+	if ce, ok := err.(errs.ConstraintViolationError); ok {
+		ce.TableName = tableName
+		return ce
+	}
+
+	return err
+}
+
+// checkNotNull ensures no required field is missing or null.
+// If allowDefaults is true, missing fields with a default value or an identity are allowed.
+func (f FieldConstraints) checkNotNull(fb *document.FieldBuffer, allowDefaults bool) error {
+	violations, err := f.notNullViolations(fb, allowDefaults)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return violations[0]
+	}
+
+	return nil
+}
+
+// notNullViolations returns the required fields that are missing or null, in the order
+// of the field constraints.
+// If allowDefaults is true, missing fields with a default value or an identity are allowed.
+func (f FieldConstraints) notNullViolations(fb *document.FieldBuffer, allowDefaults bool) ([]errs.ConstraintViolationError, error) {
+	var violations []errs.ConstraintViolationError
+
 	for _, fc := range f {
 		if !fc.IsNotNull {
 			continue
@@ -330,7 +475,7 @@ func (f FieldConstraints) ValidateDocument(tx *Transaction, d document.Document)
 			// to the right type above.
 			// check if it is required but null.
 			if v.Type == document.NullValue {
-				return nil, &ConstraintViolationError{"NOT NULL", fc.Path}
+				violations = append(violations, errs.ConstraintViolationError{Constraint: "NOT NULL", Path: fc.Path, Value: v})
 			}
 
 			continue
@@ -340,10 +485,14 @@ func (f FieldConstraints) ValidateDocument(tx *Transaction, d document.Document)
 			return nil, err
 		}
 
-		return nil, &ConstraintViolationError{"NOT NULL", fc.Path}
+		if allowDefaults && (fc.HasDefaultValue() || fc.Identity != nil) {
+			continue
+		}
+
+		violations = append(violations, errs.ConstraintViolationError{Constraint: "NOT NULL", Path: fc.Path})
 	}
 
-	return fb, nil
+	return violations, nil
 }
 
 // ConvertDocument the document using the field constraints.
@@ -362,7 +511,7 @@ type ConversionFunc func(v document.Value, path document.Path, targetType docume
 func CastConversion(v document.Value, path document.Path, targetType document.ValueType) (document.Value, error) {
 	newV, err := v.CastAs(targetType)
 	if err != nil {
-		return v, stringutil.Errorf("field %q must be of type %q, got %q", path, targetType, v.Type)
+		return v, errs.ConstraintViolationError{Constraint: "TYPE", Path: path, ExpectedType: targetType, Value: v}
 	}
 
 	return newV, nil
@@ -405,13 +554,7 @@ func (f FieldConstraints) convertScalarAtPath(path document.Path, v document.Val
 	}
 
 	// no constraint have been found for this path.
-	// check if this is an integer and convert it to double.
-	if v.Type == document.IntegerValue {
-		newV, _ := v.CastAsDouble()
-		return newV, nil
-	}
-
-	return v, nil
+	return document.ConvertUntyped(v), nil
 }
 
 func (f FieldConstraints) convertDocumentAtPath(path document.Path, d document.Document, conversionFn ConversionFunc) (*document.FieldBuffer, error) {
@@ -445,7 +588,10 @@ func (f FieldConstraints) convertArrayAtPath(path document.Path, a document.Arra
 	return vb, err
 }
 
+// FieldConstraintIdentity is the AUTOINCREMENT constraint of an integer field,
+// whose missing values are generated by a sequence owned by the field.
 type FieldConstraintIdentity struct {
+	// Name of the sequence, set when the table is created.
 	SequenceName string
 	Always       bool
 }
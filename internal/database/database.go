@@ -4,7 +4,10 @@ package database
 import (
 	"context"
 	"errors"
+	"io"
+	"log"
 	"sync"
+	"time"
 
 	"github.com/genjidb/genji/document/encoding"
 	"github.com/genjidb/genji/engine"
@@ -15,7 +18,7 @@ const (
 )
 
 type Database struct {
-	ng      engine.Engine
+	ng      *placementEngine
 	Catalog Catalog
 
 	// If this is non-nil, the user is running an explicit transaction
@@ -30,11 +33,110 @@ type Database struct {
 
 	// This controls concurrency on read-only and read/write transactions.
 	txmu *sync.RWMutex
+
+	// Resources each statement is allowed to consume.
+	limits   Limits
+	limitsMu sync.RWMutex
+
+	// Memory used by all the running statements.
+	memory *MemoryTracker
+	// Storage used by statements to spill data that doesn't fit in memory.
+	temp *tempStorage
+
+	attachmentThreshold int
+
+	atomicStatements bool
+
+	txTimeout     time.Duration
+	txIdleTimeout time.Duration
+	logger        *log.Logger
+
+	keyProvider   KeyProvider
+	keyProviderMu sync.RWMutex
+
+	strict   bool
+	strictMu sync.RWMutex
+
+	// Queries being run.
+	processes processList
+
+	// Limits the concurrency and the write rate of the statements.
+	throttle *Throttle
+
+	// plans chosen for the statements run by the database.
+	plans *PlanHistory
+
+	// Sources of time and randomness of the transactions.
+	clock      Clock
+	rand       io.Reader
+	snowflakes *snowflakeGenerator
+	sourcesMu  sync.RWMutex
 }
 
 type Options struct {
 	Codec   encoding.Codec
 	Catalog Catalog
+	// Limits enforced on every statement. Zero values mean no limit.
+	Limits Limits
+	// Number of bytes the operators of all the running statements can keep
+	// in memory. Operators that can, like sort and distinct, spill to TempEngine
+	// once it is exhausted, others fail. Zero means no budget.
+	MemoryBudget int64
+	// Engine used to store spilled data. It must be safe for concurrent use
+	// and must not be the engine of the database. Temporary stores orphaned by
+	// a crash are dropped when the database is opened. Closing it is the
+	// responsibility of the caller.
+	// If nil, every statement that spills uses its own in-memory engine.
+	TempEngine engine.Engine
+	// Size in bytes above which the top-level blob and text values of documents
+	// are stored in a separate store, and replaced in the document by a placeholder.
+	// They are loaded transparently when accessed, which keeps the documents of
+	// tables containing a few very large values small and fast to scan.
+	// Zero disables it.
+	AttachmentThreshold int
+	// Maximum duration a transaction can stay open. Transactions open
+	// for longer are rolled back automatically once no statement is running in them,
+	// which prevents abandoned transactions from blocking the other writers.
+	// Zero means no timeout.
+	TxTimeout time.Duration
+	// Maximum duration a transaction can stay open without running any statement
+	// before being rolled back automatically. Zero means no timeout.
+	TxIdleTimeout time.Duration
+	// If true, a statement failing inside an explicit transaction only undoes its own
+	// changes, instead of leaving them partially applied in the transaction.
+	// It costs an additional read for every write made by those statements.
+	AtomicStatements bool
+	// Provider of the keys used by the encrypt and decrypt functions.
+	// If nil, these functions fail.
+	KeyProvider KeyProvider
+	// Maximum number of queries running their own transaction concurrently.
+	// Queries exceeding it wait until another one closes its result. Queries run within
+	// an explicit transaction are not limited. Zero means no limit.
+	MaxConcurrentStatements int
+	// Maximum number of write statements started every second, outside
+	// of explicit transactions. Statements exceeding it are delayed,
+	// bursts of up to one second worth of writes are not. Zero means no limit.
+	MaxWritesPerSecond int
+	// Clock used to generate the values depending on time, like ULID and snowflake
+	// docids and the timestamps of audit entries. Replacing it makes these values
+	// deterministic in tests. If nil, the time of the system is used.
+	Clock Clock
+	// Source of random bytes used to generate random docids, ULIDs, the node ids
+	// of snowflakes and the nonces of encrypted values. It doesn't need to be safe
+	// for concurrent use. If nil, crypto/rand is used.
+	Rand io.Reader
+	// If true, the transactions run in strict mode: comparing values of incompatible types
+	// fails instead of evaluating to false, and NULL follows three-valued logic
+	// in every operator, including AND, OR and NOT, and in index and primary key lookups.
+	Strict bool
+	// Synchronous mode of the commits, one of engine.SyncOff, engine.SyncNormal and engine.SyncFull.
+	// Turning it off speeds up bulk loads, at the risk of losing committed changes if the
+	// operating system crashes. If empty, the engine is left as configured.
+	Synchronous string
+	// Logger used to report the transactions rolled back after a timeout,
+	// along with the last statement they ran, and the statements whose plan changed.
+	// If nil, the standard logger is used.
+	Logger *log.Logger
 }
 
 // TxOptions are passed to Begin to configure transactions.
@@ -57,10 +159,46 @@ func New(ctx context.Context, ng engine.Engine, opts Options) (*Database, error)
 	}
 
 	db := Database{
-		ng:      ng,
+		ng:      newPlacementEngine(ng),
 		Codec:   opts.Codec,
 		Catalog: opts.Catalog,
 		txmu:    &sync.RWMutex{},
+		limits:  opts.Limits,
+
+		memory: NewMemoryTracker(opts.MemoryBudget),
+
+		attachmentThreshold: opts.AttachmentThreshold,
+
+		atomicStatements: opts.AtomicStatements,
+		strict:           opts.Strict,
+
+		txTimeout:     opts.TxTimeout,
+		txIdleTimeout: opts.TxIdleTimeout,
+		logger:        opts.Logger,
+
+		keyProvider: opts.KeyProvider,
+
+		throttle: NewThrottle(opts.MaxConcurrentStatements, opts.MaxWritesPerSecond),
+
+		plans: NewPlanHistory(opts.Logger),
+
+		clock: opts.Clock,
+	}
+	db.setRand(opts.Rand)
+
+	if opts.Synchronous != "" {
+		err := db.SetSynchronous(opts.Synchronous)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.TempEngine != nil {
+		var err error
+		db.temp, err = newTempStorage(opts.TempEngine)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	tx, err := db.Begin(true)
@@ -74,6 +212,11 @@ func New(ctx context.Context, ng engine.Engine, opts Options) (*Database, error)
 		return nil, err
 	}
 
+	err = db.plans.load(tx.Tx)
+	if err != nil {
+		return nil, err
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return nil, err
@@ -82,6 +225,72 @@ func New(ctx context.Context, ng engine.Engine, opts Options) (*Database, error)
 	return &db, nil
 }
 
+// Limits returns the resources each statement is allowed to consume.
+func (db *Database) Limits() Limits {
+	db.limitsMu.RLock()
+	defer db.limitsMu.RUnlock()
+
+	return db.limits
+}
+
+// SetLimit sets the limit with the given name.
+// It applies to the statements run after this call.
+func (db *Database) SetLimit(name string, v int64) error {
+	db.limitsMu.Lock()
+	defer db.limitsMu.Unlock()
+
+	return db.limits.Set(name, v)
+}
+
+// SetEngine registers a secondary engine under the given name. The tables created
+// with the engine option set to that name, and their indexes, are stored in it.
+// It must be registered before these tables are accessed, every time the database is opened.
+// If ng is nil, the engine is unregistered. Closing it is the responsibility of the caller.
+func (db *Database) SetEngine(name string, ng engine.Engine) {
+	db.ng.setEngine(name, ng)
+}
+
+// SetKeyProvider sets the provider of the keys used by the encrypt and decrypt functions.
+// It applies to the transactions started after this call.
+func (db *Database) SetKeyProvider(p KeyProvider) {
+	db.keyProviderMu.Lock()
+	defer db.keyProviderMu.Unlock()
+
+	db.keyProvider = p
+}
+
+// SetStrict enables or disables the strict mode of the transactions.
+// It applies to the transactions started after this call.
+func (db *Database) SetStrict(strict bool) {
+	db.strictMu.Lock()
+	defer db.strictMu.Unlock()
+
+	db.strict = strict
+}
+
+// SetSynchronous sets the synchronous mode of the engine of the database,
+// one of engine.SyncOff, engine.SyncNormal and engine.SyncFull.
+// It applies to the transactions begun after this call.
+func (db *Database) SetSynchronous(mode string) error {
+	return engine.SetSynchronous(db.ng, mode)
+}
+
+// RunGC runs the garbage collection of the engine of the database.
+// It does nothing if the engine doesn't implement engine.GarbageCollector.
+func (db *Database) RunGC() error {
+	return engine.RunGC(db.ng)
+}
+
+// GCStats returns the garbage collection statistics of the engine of the database.
+func (db *Database) GCStats() (engine.GCStats, error) {
+	return engine.ReadGCStats(db.ng)
+}
+
+// Memory returns the tracker of the memory used by all the running statements.
+func (db *Database) Memory() *MemoryTracker {
+	return db.memory
+}
+
 // Close the database.
 func (db *Database) Close() error {
 	// If there is an attached transaction
@@ -111,6 +320,11 @@ func (db *Database) Close() error {
 		}
 	}
 
+	err = db.plans.save(tx.Tx)
+	if err != nil {
+		return err
+	}
+
 	err = tx.Tx.Commit()
 	if err != nil {
 		return err
@@ -161,7 +375,16 @@ func (db *Database) BeginTx(ctx context.Context, opts *TxOptions) (*Transaction,
 		return nil, errors.New("cannot open a transaction within a transaction")
 	}
 
-	return db.beginTx(ctx, opts)
+	tx, err := db.beginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if db.txTimeout > 0 || db.txIdleTimeout > 0 {
+		tx.watchdog = newTxWatchdog(tx, db.txTimeout, db.txIdleTimeout, db.logger)
+	}
+
+	return tx, nil
 }
 
 // beginTx creates a transaction without locks.
@@ -182,9 +405,32 @@ func (db *Database) beginTx(ctx context.Context, opts *TxOptions) (*Transaction,
 		Writable: !opts.ReadOnly,
 		DBMu:     db.txmu,
 		Codec:    db.Codec,
+
+		AttachmentThreshold: db.attachmentThreshold,
+		AtomicStatements:    db.atomicStatements,
+		StartedAt:           time.Now(),
+		EngineIsolation:     engine.Isolation(db.ng),
+		User:                UserFromContext(ctx),
+		Unprivileged:        IsUnprivileged(ctx),
 	}
 
+	tx.Throttle = db.throttle
+	tx.Plans = db.plans
+
+	db.keyProviderMu.RLock()
+	tx.KeyProvider = db.keyProvider
+	db.keyProviderMu.RUnlock()
+
+	db.strictMu.RLock()
+	tx.Strict = db.strict
+	db.strictMu.RUnlock()
+
+	db.sourcesMu.RLock()
+	tx.Clock, tx.Rand, tx.snowflakes = db.clock, db.rand, db.snowflakes
+	db.sourcesMu.RUnlock()
+
 	if opts.Attached {
+		tx.Attached = true
 		db.attachedTransaction = &tx
 		tx.OnRollbackHooks = append(tx.OnRollbackHooks, db.releaseAttachedTx)
 		tx.OnCommitHooks = append(tx.OnCommitHooks, db.releaseAttachedTx)
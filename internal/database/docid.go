@@ -0,0 +1,208 @@
+package database
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// Strategies used to generate the docids of the tables without primary key.
+const (
+	// DocidSequence generates increasing integers using the docid sequence of the table.
+	// It is the default strategy.
+	DocidSequence = "sequence"
+	// DocidRandom generates random positive integers, which spreads the inserts
+	// over the whole key space instead of always appending to the end of the table.
+	DocidRandom = "random"
+	// DocidULID generates ULIDs: 128-bit keys made of a millisecond timestamp
+	// followed by 80 random bits. They are globally unique, and their
+	// text representation is used as the key of the documents.
+	DocidULID = "ulid"
+	// DocidSnowflake generates 63-bit integers made of a millisecond timestamp,
+	// the node id of the process and a per-millisecond counter.
+	DocidSnowflake = "snowflake"
+)
+
+// DocidStrategies lists the valid docid strategies.
+var DocidStrategies = []string{DocidSequence, DocidRandom, DocidULID, DocidSnowflake}
+
+// size of the binary representation of a ULID.
+const ulidSize = 16
+
+// alphabet of the Crockford's base32 encoding used by ULIDs.
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// generateDocid generates a new docid using the strategy of the table.
+func (t *Table) generateDocid() ([]byte, error) {
+	var docid int64
+
+	switch t.Info.DocidStrategy {
+	case DocidRandom:
+		n, err := rand.Int(t.Tx.Random(), big.NewInt(1<<63-1))
+		if err != nil {
+			return nil, err
+		}
+		// zero is never used as a docid
+		docid = n.Int64() + 1
+	case DocidULID:
+		return newULID(t.Tx.Now(), t.Tx.Random())
+	case DocidSnowflake:
+		g := t.Tx.snowflakes
+		if g == nil {
+			g = snowflakes
+		}
+		docid = g.Next(t.Tx.Now())
+	default:
+		seq, err := t.Catalog.GetSequence(t.Info.DocidSequenceName)
+		if err != nil {
+			return nil, err
+		}
+		docid, err = seq.Next(t.Tx, t.Catalog)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, uint64(docid))
+	return buf[:n], nil
+}
+
+// decodeDocid returns the value of a docid generated with the given strategy.
+func decodeDocid(strategy string, key []byte) document.Value {
+	if strategy == DocidULID {
+		return document.NewTextValue(encodeULID(key))
+	}
+
+	docid, _ := binary.Uvarint(key)
+	return document.NewIntegerValue(int64(docid))
+}
+
+// encodeDocid converts v to a docid generated with the given strategy.
+func encodeDocid(strategy string, v document.Value) ([]byte, error) {
+	if strategy == DocidULID {
+		if v.Type != document.TextValue {
+			return nil, stringutil.Errorf("cannot convert %q to a ulid", v.Type)
+		}
+
+		return decodeULID(v.V.(string))
+	}
+
+	// convert the value to an integer then to an unsigned integer
+	// and encode it as a varint
+	v, ok := document.ConvertKey(v, document.IntegerValue)
+	if !ok {
+		return nil, stringutil.Errorf("cannot convert %s to a docid", v.Type)
+	}
+
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, uint64(v.V.(int64)))
+	return buf[:n], nil
+}
+
+// newULID returns the binary representation of a new ULID: the timestamp in milliseconds,
+// on 48 bits, followed by 80 random bits read from r. Both are big endian, so that ULIDs are sorted by time.
+func newULID(now time.Time, r io.Reader) ([]byte, error) {
+	id := make([]byte, ulidSize)
+
+	ms := uint64(now.UnixNano() / int64(time.Millisecond))
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], ms)
+	copy(id, ts[2:])
+
+	_, err := io.ReadFull(r, id[6:])
+	if err != nil {
+		return nil, err
+	}
+
+	return id, nil
+}
+
+// encodeULID returns the 26 characters text representation of a binary ULID.
+func encodeULID(id []byte) string {
+	var n big.Int
+	n.SetBytes(id)
+
+	var s [26]byte
+	for i := len(s) - 1; i >= 0; i-- {
+		s[i] = crockfordAlphabet[n.Uint64()&31]
+		n.Rsh(&n, 5)
+	}
+
+	return string(s[:])
+}
+
+// decodeULID parses the text representation of a ULID.
+func decodeULID(s string) ([]byte, error) {
+	if len(s) != 26 || s[0] > '7' {
+		return nil, stringutil.Errorf("invalid ulid %q", s)
+	}
+
+	var n big.Int
+	for _, c := range strings.ToUpper(s) {
+		i := strings.IndexRune(crockfordAlphabet, c)
+		if i < 0 {
+			return nil, stringutil.Errorf("invalid ulid %q", s)
+		}
+		n.Lsh(&n, 5)
+		n.Or(&n, big.NewInt(int64(i)))
+	}
+
+	id := make([]byte, ulidSize)
+	return n.FillBytes(id), nil
+}
+
+// snowflakes generates the snowflake ids of the databases using crypto/rand.
+var snowflakes = newSnowflakeGenerator(rand.Reader)
+
+// epoch of the snowflake timestamps, in milliseconds since the Unix epoch: 2021-01-01T00:00:00Z.
+const snowflakeEpoch = 1609459200000
+
+// snowflakeGenerator generates 63-bit ids made of a 41-bit timestamp in milliseconds,
+// a 10-bit node id and a 12-bit counter, reset every millisecond.
+// The node id is chosen randomly when the generator is created, to make it unlikely
+// for two databases to generate the same ids.
+type snowflakeGenerator struct {
+	mu     sync.Mutex
+	node   int64
+	last   int64
+	serial int64
+}
+
+func newSnowflakeGenerator(r io.Reader) *snowflakeGenerator {
+	var node [2]byte
+	_, _ = io.ReadFull(r, node[:])
+
+	return &snowflakeGenerator{
+		node: int64(binary.BigEndian.Uint16(node[:]) & 0x3FF),
+	}
+}
+
+// Next returns a new id. If more than 4096 ids are generated during the same millisecond,
+// the timestamp of the next ones is moved forward.
+func (g *snowflakeGenerator) Next(now time.Time) int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := now.UnixNano()/int64(time.Millisecond) - snowflakeEpoch
+	// never go back in time, even if the clock does
+	if ms <= g.last {
+		g.serial++
+		if g.serial > 0xFFF {
+			g.last++
+			g.serial = 0
+		}
+	} else {
+		g.last = ms
+		g.serial = 0
+	}
+
+	return g.last<<22 | g.node<<12 | g.serial
+}
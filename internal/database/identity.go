@@ -0,0 +1,77 @@
+package database
+
+import (
+	"github.com/genjidb/genji/document"
+)
+
+// hasIdentities returns true if at least one field of the table is an identity.
+func (t *Table) hasIdentities() bool {
+	for _, fc := range t.Info.FieldConstraints {
+		if fc.Identity != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// generateIdentities sets the missing or null identity fields of d to the next value
+// of their sequence. Explicit values reaching the next value of the sequence restart it
+// after them, so that it never generates a value already used.
+func (t *Table) generateIdentities(d document.Document) (document.Document, error) {
+	fb := document.NewFieldBuffer()
+	err := fb.Copy(d)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fc := range t.Info.FieldConstraints {
+		if fc.Identity == nil {
+			continue
+		}
+
+		seq, err := t.Catalog.GetSequence(fc.Identity.SequenceName)
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := fc.Path.GetValueFromDocument(fb)
+		if err != nil && err != document.ErrFieldNotFound {
+			return nil, err
+		}
+
+		if err == document.ErrFieldNotFound || v.Type == document.NullValue {
+			n, err := seq.Next(t.Tx, t.Catalog)
+			if err != nil {
+				return nil, err
+			}
+
+			err = fb.Set(fc.Path, document.NewIntegerValue(n))
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if v.Type != document.IntegerValue {
+			continue
+		}
+
+		next := seq.Info.Start
+		if seq.CurrentValue != nil {
+			next = *seq.CurrentValue + seq.Info.IncrementBy
+		}
+
+		explicit := v.V.(int64)
+		if explicit < next || explicit >= seq.Info.Max {
+			continue
+		}
+
+		err = seq.Restart(t.Tx, t.Catalog, explicit+1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return fb, nil
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"io"
+	"sort"
 
 	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/engine"
@@ -128,41 +129,75 @@ func (idx *Index) Arity() int {
 //   k: <encoded values><primary key>
 //   v: length of the encoded value, as an unsigned varint
 func (idx *Index) Set(vs []document.Value, k []byte) error {
+	storeKey, storeValue, err := idx.encodeRecord(vs, k)
+	if err != nil {
+		return err
+	}
+
+	st, err := getOrCreateStore(idx.tx, idx.Info.StoreName)
+	if err != nil {
+		return nil
+	}
+
+	return idx.put(st, storeKey, storeValue)
+}
+
+// encodeRecord validates the values and returns the key and the value of the record
+// associating them with k in the store, following the index format.
+// The type of each value must match the type of the index, if any.
+func (idx *Index) encodeRecord(vs []document.Value, k []byte) (storeKey, storeValue []byte, err error) {
 	if len(k) == 0 {
-		return errors.New("cannot index value without a key")
+		return nil, nil, errors.New("cannot index value without a key")
 	}
 
 	if len(vs) == 0 {
-		return errors.New("cannot index without a value")
+		return nil, nil, errors.New("cannot index without a value")
 	}
 
 	if len(vs) != idx.Arity() {
-		return stringutil.Errorf("cannot index %d values on an index of arity %d", len(vs), len(idx.Info.Types))
-	}
-
-	for i, typ := range idx.Info.Types {
-		if !typ.IsAny() && typ != vs[i].Type {
-			return stringutil.Errorf("cannot index value of type %s in %s index", vs[i].Type, typ)
-		}
+		return nil, nil, stringutil.Errorf("cannot index %d values on an index of arity %d", len(vs), len(idx.Info.Types))
 	}
 
-	st, err := getOrCreateStore(idx.tx, idx.Info.StoreName)
+	err = idx.checkTypes(vs)
 	if err != nil {
-		return nil
+		return nil, nil, err
 	}
 
-	var storeKey, storeValue []byte
-
 	// encode the value we are going to use as a key
 	vb := document.NewValueBuffer(vs...)
 	storeKey, err = idx.EncodeValueBuffer(vb)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+
+	// we append the pk at the end of the encoded value
+	// store the length of the encoded value in the storeValue
+	vbuf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(vbuf, uint64(len(storeKey)))
+	storeValue = vbuf[:n]
+	storeKey = append(storeKey, k...)
+
+	return storeKey, storeValue, nil
+}
+
+// checkTypes ensures vs can be stored in a typed index.
+func (idx *Index) checkTypes(vs []document.Value) error {
+	for i, typ := range idx.Info.Types {
+		if i < len(vs) && !typ.IsAny() && typ != vs[i].Type {
+			return stringutil.Errorf("cannot index value of type %s in %s index", vs[i].Type, typ)
+		}
 	}
 
-	// if the index is unique, we need to check if the value is already associated with the key
+	return nil
+}
+
+// put writes a record encoded by encodeRecord to the store.
+// If the index is unique, it ensures the encoded values are not already
+// associated with another key.
+func (idx *Index) put(st engine.Store, storeKey, storeValue []byte) error {
 	if idx.Info.Unique {
-		ok, _, err := idx.exists(st, storeKey)
+		size, _ := binary.Uvarint(storeValue)
+		ok, _, err := idx.exists(st, storeKey[:size])
 		if err != nil {
 			return err
 		}
@@ -171,13 +206,6 @@ func (idx *Index) Set(vs []document.Value, k []byte) error {
 		}
 	}
 
-	// we append the pk at the end of the encoded value
-	// store the length of the encoded value in the storeValue
-	vbuf := make([]byte, binary.MaxVarintLen64)
-	n := binary.PutUvarint(vbuf, uint64(len(storeKey)))
-	storeValue = vbuf[:n]
-	storeKey = append(storeKey, k...)
-
 	return st.Put(storeKey, storeValue)
 }
 
@@ -233,7 +261,7 @@ func (idx *Index) Delete(vs []document.Value, k []byte) error {
 	}
 
 	var buf []byte
-	err = idx.iterate(st, vs, false, func(item engine.Item) error {
+	err = idx.iterate(st, vs, engine.IteratorOptions{}, func(item engine.Item) error {
 		buf, err = item.ValueCopy(buf)
 		if err != nil {
 			return err
@@ -317,7 +345,7 @@ func (pivot Pivot) IsAny() bool {
 //
 // Any other variation of a pivot are invalid and will panic.
 func (idx *Index) AscendGreaterOrEqual(pivot Pivot, fn func(val, key []byte) error) error {
-	return idx.iterateOnStore(pivot, false, fn)
+	return idx.iterateOnStore(pivot, IndexBounds{}, false, fn)
 }
 
 // DescendLessOrEqual seeks for the pivot and then goes through all the subsequent key value pairs in descreasing order and calls the given function for each pair.
@@ -337,10 +365,31 @@ func (idx *Index) AscendGreaterOrEqual(pivot Pivot, fn func(val, key []byte) err
 //
 // Any other variation of a pivot are invalid and will panic.
 func (idx *Index) DescendLessOrEqual(pivot Pivot, fn func(val, key []byte) error) error {
-	return idx.iterateOnStore(pivot, true, fn)
+	return idx.iterateOnStore(pivot, IndexBounds{}, true, fn)
+}
+
+// IndexBounds restrict an iteration to the values of an index whose encoding,
+// as returned by EncodeValueBuffer, is between Min and Max, inclusive.
+// Values are compared to Max on its length: values starting with Max,
+// like the values of a composite index whose first values are equal to Max,
+// are within the bounds. A nil bound is ignored.
+type IndexBounds struct {
+	Min, Max []byte
+}
+
+// AscendRange is like AscendGreaterOrEqual, but the iteration stops in the engine
+// as soon as the values are out of the bounds.
+func (idx *Index) AscendRange(pivot Pivot, bounds IndexBounds, fn func(val, key []byte) error) error {
+	return idx.iterateOnStore(pivot, bounds, false, fn)
+}
+
+// DescendRange is like DescendLessOrEqual, but the iteration stops in the engine
+// as soon as the values are out of the bounds.
+func (idx *Index) DescendRange(pivot Pivot, bounds IndexBounds, fn func(val, key []byte) error) error {
+	return idx.iterateOnStore(pivot, bounds, true, fn)
 }
 
-func (idx *Index) iterateOnStore(pivot Pivot, reverse bool, fn func(val, key []byte) error) error {
+func (idx *Index) iterateOnStore(pivot Pivot, bounds IndexBounds, reverse bool, fn func(val, key []byte) error) error {
 	pivot.validate(idx)
 
 	// If index and pivot values are typed but not of the same type, return no results.
@@ -358,8 +407,13 @@ func (idx *Index) iterateOnStore(pivot Pivot, reverse bool, fn func(val, key []b
 		return nil
 	}
 
+	opts := engine.IteratorOptions{Reverse: reverse, LowerBound: bounds.Min}
+	if bounds.Max != nil {
+		opts.UpperBound = engine.PrefixEnd(bounds.Max)
+	}
+
 	var buf []byte
-	return idx.iterate(st, pivot, reverse, func(item engine.Item) error {
+	return idx.iterate(st, pivot, opts, func(item engine.Item) error {
 		var err error
 
 		record := item.Key()
@@ -481,26 +535,24 @@ func (idx *Index) buildSeek(pivot Pivot, reverse bool) ([]byte, error) {
 	return seek, nil
 }
 
-func (idx *Index) iterate(st engine.Store, pivot Pivot, reverse bool, fn func(item engine.Item) error) error {
+func (idx *Index) iterate(st engine.Store, pivot Pivot, opts engine.IteratorOptions, fn func(item engine.Item) error) error {
 	var err error
 
-	seek, err := idx.buildSeek(pivot, reverse)
+	seek, err := idx.buildSeek(pivot, opts.Reverse)
 	if err != nil {
 		return err
 	}
 
-	it := st.Iterator(engine.IteratorOptions{Reverse: reverse})
+	// If index is untyped and pivot first element is typed, only iterate on values with the same type as the first pivot
+	if len(pivot) > 0 && idx.Info.Types[0].IsAny() && !pivot[0].Type.IsAny() {
+		opts.Prefix = []byte{byte(pivot[0].Type)}
+	}
+
+	it := st.Iterator(opts)
 	defer it.Close()
 
 	for it.Seek(seek); it.Valid(); it.Next() {
-		itm := it.Item()
-
-		// If index is untyped and pivot first element is typed, only iterate on values with the same type as the first pivot
-		if len(pivot) > 0 && idx.Info.Types[0].IsAny() && !pivot[0].Type.IsAny() && itm.Key()[0] != byte(pivot[0].Type) {
-			return nil
-		}
-
-		err := fn(itm)
+		err := fn(it.Item())
 		if err != nil {
 			return err
 		}
@@ -527,3 +579,109 @@ func (list Indexes) GetIndex(name string) *Index {
 func (list Indexes) Len() int           { return len(list) }
 func (list Indexes) Swap(i, j int)      { list[i], list[j] = list[j], list[i] }
 func (list Indexes) Less(i, j int) bool { return list[i].Info.IndexName < list[j].Info.IndexName }
+
+// An indexBatch accumulates the mutations of an index
+// and applies them all at once, in key order.
+// Mutations that cancel each other, like removing a record
+// and adding it back, are never written to the store.
+type indexBatch struct {
+	idx *Index
+
+	// records to delete, by store key
+	deleted map[string]struct{}
+	// records to write, by store key
+	set map[string][]byte
+}
+
+func newIndexBatch(idx *Index) *indexBatch {
+	return &indexBatch{
+		idx:     idx,
+		deleted: make(map[string]struct{}),
+		set:     make(map[string][]byte),
+	}
+}
+
+// Set records the association of vs with k.
+func (b *indexBatch) Set(vs []document.Value, k []byte) error {
+	storeKey, storeValue, err := b.idx.encodeRecord(vs, k)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := b.deleted[string(storeKey)]; ok {
+		delete(b.deleted, string(storeKey))
+		return nil
+	}
+
+	b.set[string(storeKey)] = storeValue
+	return nil
+}
+
+// Delete records the removal of the association of vs with k.
+func (b *indexBatch) Delete(vs []document.Value, k []byte) error {
+	storeKey, _, err := b.idx.encodeRecord(vs, k)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := b.set[string(storeKey)]; ok {
+		delete(b.set, string(storeKey))
+		return nil
+	}
+
+	b.deleted[string(storeKey)] = struct{}{}
+	return nil
+}
+
+// Flush applies every deletion, then every write, in key order.
+func (b *indexBatch) Flush() error {
+	if len(b.deleted) == 0 && len(b.set) == 0 {
+		return nil
+	}
+
+	st, err := getOrCreateStore(b.idx.tx, b.idx.Info.StoreName)
+	if err != nil {
+		return err
+	}
+
+	for _, k := range sortedKeys(b.deleted) {
+		err = st.Delete([]byte(k))
+		if err == engine.ErrKeyNotFound && b.idx.Info.Rebuilding {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, k := range sortedKeys(b.set) {
+		err = b.idx.put(st, []byte(k), b.set[k])
+		if err != nil {
+			return err
+		}
+	}
+
+	b.deleted = make(map[string]struct{})
+	b.set = make(map[string][]byte)
+	return nil
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+
+	switch t := m.(type) {
+	case map[string]struct{}:
+		keys = make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+	case map[string][]byte:
+		keys = make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -1747,6 +1747,45 @@ func TestIndexDescendLessOrEqual(t *testing.T) {
 	}
 }
 
+func TestIndexRange(t *testing.T) {
+	idx, cleanup := getIndex(t, false, document.IntegerValue, document.IntegerValue)
+	defer cleanup()
+
+	for i := 0; i < 5; i++ {
+		for j := 0; j < 3; j++ {
+			err := idx.Set(values(document.NewIntegerValue(int64(i)), document.NewIntegerValue(int64(j))), []byte{'a' + uint8(i*3+j)})
+			require.NoError(t, err)
+		}
+	}
+
+	encode := func(vs ...document.Value) []byte {
+		b, err := idx.EncodeValueBuffer(document.NewValueBuffer(vs...))
+		require.NoError(t, err)
+		return b
+	}
+
+	bounds := database.IndexBounds{
+		Min: encode(document.NewIntegerValue(1), document.NewIntegerValue(2)),
+		Max: encode(document.NewIntegerValue(3)),
+	}
+
+	var keys []byte
+	err := idx.AscendRange(nil, bounds, func(val, key []byte) error {
+		keys = append(keys, key...)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "fghijkl", string(keys))
+
+	keys = keys[:0]
+	err = idx.DescendRange(nil, bounds, func(val, key []byte) error {
+		keys = append(keys, key...)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "lkjihgf", string(keys))
+}
+
 // BenchmarkIndexSet benchmarks the Set method with 1, 10, 1000 and 10000 successive insertions.
 func BenchmarkIndexSet(b *testing.B) {
 	for size := 10; size <= 10000; size *= 10 {
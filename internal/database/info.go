@@ -15,13 +15,44 @@ type TableInfo struct {
 	// name of the store associated with the table.
 	StoreName []byte
 	ReadOnly  bool
+	// If set, a checksum is stored with every document
+	// and verified when the document is read.
+	Checksum bool
+	// If set, every mutation is recorded in the audit table
+	// of the table, as returned by AuditTableName.
+	Audit bool
 
 	FieldConstraints FieldConstraints
+	// Masks applied to the documents read by unprivileged transactions.
+	Masks []FieldMask
 
 	// Name of the docid sequence if any.
 	DocidSequenceName string
+	// Strategy used to generate the docids if there is no primary key,
+	// one of DocidStrategies. If empty, DocidSequence is used.
+	DocidStrategy string
+	// Order of the fields of the stored documents, one of FieldOrders.
+	// If empty, FieldOrderInsertion is used.
+	FieldOrder string
+	// Name of the secondary engine storing the table and its indexes,
+	// registered with SetEngine. If empty, the engine of the database is used.
+	Engine string
 }
 
+// Orders in which the fields of the documents are stored.
+const (
+	// FieldOrderInsertion stores the fields in the order they were inserted,
+	// which is the order in which they are returned when the document is read.
+	// It is the default order.
+	FieldOrderInsertion = "insertion"
+	// FieldOrderCanonical sorts the fields by name, recursively, before storing the documents,
+	// so that documents with the same content are always encoded the same way.
+	FieldOrderCanonical = "canonical"
+)
+
+// FieldOrders lists the valid field orders.
+var FieldOrders = []string{FieldOrderInsertion, FieldOrderCanonical}
+
 func (ti *TableInfo) Type() string {
 	return "table"
 }
@@ -47,22 +78,82 @@ func (ti *TableInfo) String() string {
 		s.WriteString(" (")
 	}
 
-	for i, fc := range ti.FieldConstraints {
+	// the fields of a primary key made of several fields
+	// are listed in a PRIMARY KEY table constraint.
+	pks := ti.FieldConstraints.GetPrimaryKeys()
+	composite := len(pks) > 1
+
+	var n int
+	for _, fc := range ti.FieldConstraints {
 		if fc.IsInferred {
 			continue
 		}
 
-		if i > 0 {
+		if composite && fc.IsPrimaryKey {
+			cp := *fc
+			cp.IsPrimaryKey = false
+			cp.PrimaryKeyName = ""
+			cp.PrimaryKeyPosition = 0
+			// fields without any other constraint are only listed in the table constraint
+			if cp.Type.IsAny() && !cp.IsNotNull && !cp.IsUnique && !cp.HasDefaultValue() {
+				continue
+			}
+			fc = &cp
+		}
+
+		if n > 0 {
 			s.WriteString(", ")
 		}
+		n++
 
 		s.WriteString(fc.String())
 	}
 
+	if composite {
+		if n > 0 {
+			s.WriteString(", ")
+		}
+		if name := pks[0].PrimaryKeyName; name != "" {
+			stringutil.Fprintf(&s, "CONSTRAINT %s ", stringutil.NormalizeIdentifier(name, '`'))
+		}
+		s.WriteString("PRIMARY KEY (")
+		for i, pk := range pks {
+			if i > 0 {
+				s.WriteString(", ")
+			}
+			s.WriteString(pk.Path.String())
+		}
+		s.WriteString(")")
+	}
+
 	if len(ti.FieldConstraints) > 0 {
 		s.WriteString(")")
 	}
 
+	var opts []string
+	if ti.Checksum {
+		opts = append(opts, "checksum = true")
+	}
+	if ti.Audit {
+		opts = append(opts, "audit = true")
+	}
+	if ti.DocidStrategy != "" && ti.DocidStrategy != DocidSequence {
+		opts = append(opts, stringutil.Sprintf("docid = %q", ti.DocidStrategy))
+	}
+	if ti.FieldOrder != "" && ti.FieldOrder != FieldOrderInsertion {
+		opts = append(opts, stringutil.Sprintf("field_order = %q", ti.FieldOrder))
+	}
+	if ti.Engine != "" {
+		opts = append(opts, stringutil.Sprintf("engine = %q", ti.Engine))
+	}
+
+	switch {
+	case len(opts) == 1 && ti.Checksum:
+		s.WriteString(" WITH CHECKSUM")
+	case len(opts) > 0:
+		stringutil.Fprintf(&s, " WITH (%s)", strings.Join(opts, ", "))
+	}
+
 	return s.String()
 }
 
@@ -71,6 +162,8 @@ func (ti *TableInfo) Clone() *TableInfo {
 	cp := *ti
 	cp.FieldConstraints = nil
 	cp.FieldConstraints = append(cp.FieldConstraints, ti.FieldConstraints...)
+	cp.Masks = nil
+	cp.Masks = append(cp.Masks, ti.Masks...)
 	return &cp
 }
 
@@ -92,6 +185,17 @@ type IndexInfo struct {
 	// i.e CREATE TABLE tbl(a INT UNIQUE)
 	// The path refers to the path this index is related to.
 	Owner Owner
+
+	// Statistics collected by the ANALYZE statement, if any.
+	// They are not persisted and must be collected again
+	// after the database is reopened.
+	Statistics *IndexStatistics
+
+	// Set while the index is rebuilt in several transactions by Catalog.ReIndexBatch.
+	// The index is then incomplete: it is not used by the planner and removing
+	// entries that are not indexed yet is not an error.
+	// It is not persisted.
+	Rebuilding bool
 }
 
 func (i *IndexInfo) Type() string {
@@ -216,6 +320,34 @@ func (s SequenceInfo) Clone() *SequenceInfo {
 	return &s
 }
 
+// ViewInfo holds the configuration of a view.
+type ViewInfo struct {
+	ViewName string
+	// SELECT statement returning the documents of the view.
+	Query string
+}
+
+func (v *ViewInfo) Type() string {
+	return "view"
+}
+
+func (v *ViewInfo) Name() string {
+	return v.ViewName
+}
+
+func (v *ViewInfo) SetName(name string) {
+	v.ViewName = name
+}
+
+func (v *ViewInfo) GenerateBaseName() string {
+	return v.ViewName
+}
+
+// String returns a SQL representation.
+func (v *ViewInfo) String() string {
+	return stringutil.Sprintf("CREATE VIEW %s AS %s", stringutil.NormalizeIdentifier(v.ViewName, '`'), v.Query)
+}
+
 // Owner is used to determine who owns a relation.
 // If the relation has been created by a table (for docids for example),
 // only the TableName is filled.
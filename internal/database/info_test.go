@@ -4,6 +4,7 @@ import (
 	"math"
 	"testing"
 
+	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/internal/database"
 	"github.com/stretchr/testify/require"
 )
@@ -53,3 +54,35 @@ func TestSequenceInfoString(t *testing.T) {
 	seq.Cycle = true
 	require.Equal(t, `CREATE SEQUENCE seq INCREMENT BY -1 CACHE 100 CYCLE`, seq.String())
 }
+
+func TestTableInfoString(t *testing.T) {
+	ti := database.TableInfo{TableName: "test"}
+	require.Equal(t, "CREATE TABLE test", ti.String())
+
+	ti.Checksum = true
+	require.Equal(t, "CREATE TABLE test WITH CHECKSUM", ti.String())
+
+	ti.DocidStrategy = database.DocidULID
+	require.Equal(t, `CREATE TABLE test WITH (checksum = true, docid = "ulid")`, ti.String())
+
+	ti.Checksum = false
+	ti.Audit = true
+	ti.DocidStrategy = database.DocidSequence
+	require.Equal(t, "CREATE TABLE test WITH (audit = true)", ti.String())
+
+	ti.FieldOrder = database.FieldOrderCanonical
+	require.Equal(t, `CREATE TABLE test WITH (audit = true, field_order = "canonical")`, ti.String())
+
+	ti.FieldOrder = database.FieldOrderInsertion
+	require.Equal(t, "CREATE TABLE test WITH (audit = true)", ti.String())
+
+	ti.Engine = "memory"
+	require.Equal(t, `CREATE TABLE test WITH (audit = true, engine = "memory")`, ti.String())
+
+	ti = database.TableInfo{TableName: "test", FieldConstraints: database.FieldConstraints{
+		{Path: document.NewPath("a"), Type: document.IntegerValue, IsPrimaryKey: true, PrimaryKeyPosition: 1},
+		{Path: document.NewPath("b"), IsPrimaryKey: true, PrimaryKeyName: "pk"},
+		{Path: document.NewPath("c"), Type: document.TextValue},
+	}}
+	require.Equal(t, "CREATE TABLE test (a INTEGER, c TEXT, CONSTRAINT pk PRIMARY KEY (b, a))", ti.String())
+}
@@ -0,0 +1,8 @@
+package database
+
+// A KeyProvider returns the keys used by the encrypt and decrypt functions.
+type KeyProvider interface {
+	// Key returns the key with the given id.
+	// It must be 16, 24 or 32 bytes long, to select AES-128, AES-192 or AES-256.
+	Key(id string) ([]byte, error)
+}
@@ -0,0 +1,283 @@
+package database
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/genjidb/genji/engine/memoryengine"
+	errs "github.com/genjidb/genji/errors"
+	"github.com/genjidb/genji/internal/stringutil"
+	"github.com/google/btree"
+)
+
+// Names of the limits, as used by the SET statement.
+const (
+	MaxScannedDocumentsLimit = "max_scanned_documents"
+	MaxMemoryLimit           = "max_memory"
+)
+
+// SynchronousSetting is the name of the synchronous mode of the database,
+// as used by the SET statement.
+const SynchronousSetting = "synchronous"
+
+// MemoryBudgetLimit is the name of the global memory budget
+// reported by LimitExceededError. It can only be set with Options.
+const MemoryBudgetLimit = "memory_budget"
+
+// Limits restrict the resources a single statement can consume.
+// A limit set to zero is disabled.
+type Limits struct {
+	// Maximum number of documents read from tables and indexes.
+	MaxScannedDocuments int64
+	// Maximum number of bytes held in memory by operators
+	// buffering documents, like sorting, grouping or deduplication.
+	MaxMemory int64
+}
+
+// Set the limit with the given name.
+func (l *Limits) Set(name string, v int64) error {
+	if v < 0 {
+		return stringutil.Errorf("invalid value %d for limit %s", v, name)
+	}
+
+	switch name {
+	case MaxScannedDocumentsLimit:
+		l.MaxScannedDocuments = v
+	case MaxMemoryLimit:
+		l.MaxMemory = v
+	default:
+		return stringutil.Errorf("unknown limit %q", name)
+	}
+
+	return nil
+}
+
+// A MemoryTracker accounts for the memory used by the operators
+// of all the statements run concurrently, and keeps it under a global budget.
+// It is safe for concurrent use. A nil MemoryTracker has no budget.
+type MemoryTracker struct {
+	budget int64
+	used   int64
+}
+
+// NewMemoryTracker creates a tracker with the given budget, in bytes.
+// A budget of zero means no budget.
+func NewMemoryTracker(budget int64) *MemoryTracker {
+	return &MemoryTracker{budget: budget}
+}
+
+// Budget returns the maximum number of bytes that can be reserved.
+func (m *MemoryTracker) Budget() int64 {
+	if m == nil {
+		return 0
+	}
+
+	return m.budget
+}
+
+// Used returns the number of bytes currently reserved.
+func (m *MemoryTracker) Used() int64 {
+	if m == nil {
+		return 0
+	}
+
+	return atomic.LoadInt64(&m.used)
+}
+
+// Reserve n bytes. It returns false and reserves nothing if
+// it would exceed the budget.
+func (m *MemoryTracker) Reserve(n int64) bool {
+	if m == nil || m.budget == 0 {
+		return true
+	}
+
+	if atomic.AddInt64(&m.used, n) > m.budget {
+		atomic.AddInt64(&m.used, -n)
+		return false
+	}
+
+	return true
+}
+
+// Release n bytes previously reserved.
+func (m *MemoryTracker) Release(n int64) {
+	if m == nil || m.budget == 0 {
+		return
+	}
+
+	atomic.AddInt64(&m.used, -n)
+}
+
+// A ResourceTracker counts the resources consumed by a statement
+// and returns a LimitExceededError when they exceed the limits.
+// Operators buffering data can also use it to spill to temporary stores
+// when the global memory budget is exhausted.
+// A nil ResourceTracker doesn't track anything.
+type ResourceTracker struct {
+	limits Limits
+	global *MemoryTracker
+
+	scanned int64
+	memory  int64
+
+	// context of the statement, checked every time a document is scanned.
+	ctx context.Context
+
+	// storage of the temporary stores.
+	// If nil, an in-memory engine is created on demand.
+	temp       *tempStorage
+	ownsTemp   bool
+	tempStores []*TempStore
+}
+
+// NewResourceTracker creates a tracker enforcing the given limits.
+func NewResourceTracker(limits Limits) *ResourceTracker {
+	return &ResourceTracker{limits: limits}
+}
+
+// NewResourceTracker creates a tracker for a statement run by the database.
+// It enforces the current limits and the global memory budget.
+// It must be closed once the statement is done.
+func (db *Database) NewResourceTracker() *ResourceTracker {
+	return &ResourceTracker{
+		limits: db.Limits(),
+		global: db.memory,
+		temp:   db.temp,
+	}
+}
+
+// WithContext makes ScanDocument return the error of ctx once it is canceled.
+// It returns t.
+func (t *ResourceTracker) WithContext(ctx context.Context) *ResourceTracker {
+	t.ctx = ctx
+	return t
+}
+
+// ScanDocument must be called every time a document is read from
+// a table or an index.
+func (t *ResourceTracker) ScanDocument() error {
+	if t == nil {
+		return nil
+	}
+
+	// stop the statement as soon as it is canceled
+	if t.ctx != nil {
+		if err := t.ctx.Err(); err != nil {
+			return err
+		}
+	}
+
+	if t.limits.MaxScannedDocuments == 0 {
+		return nil
+	}
+
+	t.scanned++
+	if t.scanned > t.limits.MaxScannedDocuments {
+		return errs.LimitExceededError{Limit: MaxScannedDocumentsLimit, Max: t.limits.MaxScannedDocuments}
+	}
+
+	return nil
+}
+
+// TracksMemory returns whether memory usage is limited.
+// Operators can use it to avoid computing the size of what they buffer.
+func (t *ResourceTracker) TracksMemory() bool {
+	return t != nil && (t.limits.MaxMemory != 0 || t.global.Budget() != 0)
+}
+
+// Grow must be called when n bytes are buffered in memory.
+// It returns an error if the statement exceeds its memory limit
+// or if the global memory budget is exhausted.
+func (t *ResourceTracker) Grow(n int64) error {
+	ok, err := t.TryGrow(n)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errs.LimitExceededError{Limit: MemoryBudgetLimit, Max: t.global.Budget()}
+	}
+
+	return nil
+}
+
+// TryGrow is like Grow but returns false instead of an error when
+// the global memory budget is exhausted, in which case nothing is accounted.
+// Operators that can spill their data to a temporary store must do so
+// when it returns false.
+func (t *ResourceTracker) TryGrow(n int64) (bool, error) {
+	if !t.TracksMemory() {
+		return true, nil
+	}
+
+	if t.limits.MaxMemory != 0 && t.memory+n > t.limits.MaxMemory {
+		return false, errs.LimitExceededError{Limit: MaxMemoryLimit, Max: t.limits.MaxMemory}
+	}
+
+	if !t.global.Reserve(n) {
+		return false, nil
+	}
+
+	t.memory += n
+	return true, nil
+}
+
+// Shrink must be called when n bytes previously passed to Grow
+// or TryGrow are released.
+func (t *ResourceTracker) Shrink(n int64) {
+	if !t.TracksMemory() {
+		return
+	}
+
+	t.memory -= n
+	t.global.Release(n)
+}
+
+// NewTempStore creates a temporary store to which operators can write
+// intermediate results. Its data is spilled to the temp engine once the
+// global memory budget is exhausted. It is closed when the tracker is closed.
+// If t is nil, the store is kept in memory.
+func (t *ResourceTracker) NewTempStore() *TempStore {
+	s := TempStore{
+		tracker: t,
+		mem:     btree.New(tempStoreBtreeDegree),
+	}
+	if t == nil {
+		return &s
+	}
+
+	if t.temp == nil {
+		t.temp = &tempStorage{ng: memoryengine.NewEngine()}
+		t.ownsTemp = true
+	}
+	s.storage = t.temp
+
+	t.tempStores = append(t.tempStores, &s)
+	return &s
+}
+
+// Close drops the temporary stores and releases the memory still accounted
+// by the tracker. It must be called once the statement is done.
+func (t *ResourceTracker) Close() error {
+	if t == nil {
+		return nil
+	}
+
+	var err error
+	for _, s := range t.tempStores {
+		if cerr := s.Close(); err == nil {
+			err = cerr
+		}
+	}
+	t.tempStores = nil
+
+	if t.ownsTemp {
+		if cerr := t.temp.ng.Close(); err == nil {
+			err = cerr
+		}
+		t.temp = nil
+		t.ownsTemp = false
+	}
+
+	t.Shrink(t.memory)
+	return err
+}
@@ -0,0 +1,52 @@
+package database_test
+
+import (
+	"testing"
+
+	errs "github.com/genjidb/genji/errors"
+	"github.com/genjidb/genji/internal/database"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryTracker(t *testing.T) {
+	m := database.NewMemoryTracker(10)
+
+	require.True(t, m.Reserve(6))
+	require.False(t, m.Reserve(6))
+	require.EqualValues(t, 6, m.Used())
+	require.True(t, m.Reserve(4))
+	m.Release(10)
+	require.Zero(t, m.Used())
+
+	// a nil tracker has no budget
+	var nilTracker *database.MemoryTracker
+	require.True(t, nilTracker.Reserve(100))
+	require.Zero(t, nilTracker.Used())
+}
+
+func TestResourceTracker(t *testing.T) {
+	t.Run("Scanned documents", func(t *testing.T) {
+		tr := database.NewResourceTracker(database.Limits{MaxScannedDocuments: 2})
+		require.NoError(t, tr.ScanDocument())
+		require.NoError(t, tr.ScanDocument())
+		require.True(t, errs.IsLimitExceededError(tr.ScanDocument()))
+	})
+
+	t.Run("Memory", func(t *testing.T) {
+		tr := database.NewResourceTracker(database.Limits{MaxMemory: 10})
+		require.True(t, tr.TracksMemory())
+		require.NoError(t, tr.Grow(10))
+		require.True(t, errs.IsLimitExceededError(tr.Grow(1)))
+		tr.Shrink(10)
+		require.NoError(t, tr.Grow(1))
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		var tr *database.ResourceTracker
+		require.False(t, tr.TracksMemory())
+		require.NoError(t, tr.ScanDocument())
+		require.NoError(t, tr.Grow(100))
+		require.NoError(t, tr.Close())
+	})
+
+}
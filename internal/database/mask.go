@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+
+	"github.com/genjidb/genji/document"
+)
+
+// A FieldMask hides the value of a field from unprivileged transactions.
+// The documents they read are returned with the value of the field replaced
+// by the result of the mask expression.
+type FieldMask struct {
+	Path document.Path
+	Expr MaskExpression
+}
+
+// A MaskExpression computes the masked value of a field,
+// from the document it belongs to.
+type MaskExpression interface {
+	Mask(tx *Transaction, d document.Document) (document.Value, error)
+	String() string
+}
+
+type unprivilegedContextKey struct{}
+
+// WithUnprivileged returns a copy of ctx marked as unprivileged.
+// Transactions started with this context only see masked values
+// of the fields having a mask.
+func WithUnprivileged(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unprivilegedContextKey{}, true)
+}
+
+// IsUnprivileged reports whether ctx was marked as unprivileged.
+func IsUnprivileged(ctx context.Context) bool {
+	ok, _ := ctx.Value(unprivilegedContextKey{}).(bool)
+	return ok
+}
+
+// GetMask returns the mask of the field with the given path, or nil if it has none.
+func (ti *TableInfo) GetMask(path document.Path) *FieldMask {
+	for i := range ti.Masks {
+		if ti.Masks[i].Path.IsEqual(path) {
+			return &ti.Masks[i]
+		}
+	}
+
+	return nil
+}
+
+// MaskDocument returns a copy of d where the value of every masked field
+// is replaced by its masked value. Fields absent from d are ignored.
+func (ti *TableInfo) MaskDocument(tx *Transaction, d document.Document) (document.Document, error) {
+	fb := document.NewFieldBuffer()
+	err := fb.Copy(d)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range ti.Masks {
+		_, err := m.Path.GetValueFromDocument(d)
+		if err == document.ErrFieldNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := m.Expr.Mask(tx, d)
+		if err != nil {
+			return nil, err
+		}
+
+		err = fb.Set(m.Path, v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// masks cannot be set on the primary key,
+	// so the key of the document is left untouched.
+	if k, ok := d.(document.Keyer); ok {
+		return maskedDocument{FieldBuffer: fb, Keyer: k}, nil
+	}
+
+	return fb, nil
+}
+
+type maskedDocument struct {
+	*document.FieldBuffer
+	document.Keyer
+}
+
+func (d maskedDocument) MarshalJSON() ([]byte, error) {
+	return d.FieldBuffer.MarshalJSON()
+}
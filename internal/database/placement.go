@@ -0,0 +1,242 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// placementMarker starts the names of the stores placed in a secondary engine.
+// Generated store names are uvarints, which are either one byte long or start
+// with a byte greater than 0x7F, and internal store names start with InternalPrefix,
+// so none of them can be mistaken for a placed store.
+const placementMarker = '@'
+
+// PlacedStoreName returns the name of a store stored in the secondary engine
+// with the given name. The engine is part of the name so that transactions
+// can find it without reading the catalog.
+func PlacedStoreName(engineName string, name []byte) []byte {
+	placed := make([]byte, 0, len(engineName)+len(name)+2)
+	placed = append(placed, placementMarker)
+	placed = append(placed, engineName...)
+	placed = append(placed, 0)
+	return append(placed, name...)
+}
+
+// storePlacement returns the name of the secondary engine storing the store,
+// or an empty string if it's stored in the primary engine.
+func storePlacement(name []byte) string {
+	if len(name) < 3 || name[0] != placementMarker {
+		return ""
+	}
+
+	i := bytes.IndexByte(name, 0)
+	if i < 2 {
+		return ""
+	}
+
+	return string(name[1:i])
+}
+
+// placementEngine routes the stores of the tables placed in secondary engines
+// to these engines, and the other ones to the primary engine.
+// Transactions begin a transaction on a secondary engine the first time they
+// access one of its stores, and commit all of them using a two-phase commit.
+type placementEngine struct {
+	engine.Engine
+
+	mu      sync.RWMutex
+	engines map[string]engine.Engine
+}
+
+func newPlacementEngine(ng engine.Engine) *placementEngine {
+	return &placementEngine{Engine: ng}
+}
+
+// setEngine registers a secondary engine under the given name.
+// If ng is nil, the engine is unregistered.
+func (e *placementEngine) setEngine(name string, ng engine.Engine) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if ng == nil {
+		delete(e.engines, name)
+		return
+	}
+
+	if e.engines == nil {
+		e.engines = make(map[string]engine.Engine)
+	}
+	e.engines[name] = ng
+}
+
+func (e *placementEngine) getEngine(name string) engine.Engine {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.engines[name]
+}
+
+func (e *placementEngine) Begin(ctx context.Context, opts engine.TxOptions) (engine.Transaction, error) {
+	tx, err := e.Engine.Begin(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &placementTx{Transaction: tx, engine: e, ctx: ctx, opts: opts}, nil
+}
+
+// Isolation returns the isolation level of the primary engine.
+// It implements the engine.IsolationReporter interface.
+func (e *placementEngine) Isolation() string {
+	return engine.Isolation(e.Engine)
+}
+
+// SetSynchronous sets the synchronous mode of the primary engine.
+// It implements the engine.Synchronizer interface.
+func (e *placementEngine) SetSynchronous(mode string) error {
+	return engine.SetSynchronous(e.Engine, mode)
+}
+
+// RunGC runs the garbage collection of the primary engine. Secondary engines
+// are collected by their owner.
+// It implements the engine.GarbageCollector interface.
+func (e *placementEngine) RunGC() error {
+	return engine.RunGC(e.Engine)
+}
+
+// GCStats returns the garbage collection statistics of the primary engine.
+// It implements the engine.GarbageCollector interface.
+func (e *placementEngine) GCStats() (engine.GCStats, error) {
+	return engine.ReadGCStats(e.Engine)
+}
+
+type placementTx struct {
+	engine.Transaction
+
+	engine *placementEngine
+	ctx    context.Context
+	opts   engine.TxOptions
+	// transactions of the secondary engines, in the order they began.
+	names []string
+	txs   map[string]engine.Transaction
+}
+
+// txOf returns the transaction of the engine storing the given store.
+func (t *placementTx) txOf(name []byte) (engine.Transaction, error) {
+	engineName := storePlacement(name)
+	if engineName == "" {
+		return t.Transaction, nil
+	}
+
+	if tx, ok := t.txs[engineName]; ok {
+		return tx, nil
+	}
+
+	ng := t.engine.getEngine(engineName)
+	if ng == nil {
+		return nil, stringutil.Errorf("unknown engine %q", engineName)
+	}
+
+	tx, err := ng.Begin(t.ctx, t.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.txs == nil {
+		t.txs = make(map[string]engine.Transaction)
+	}
+	t.txs[engineName] = tx
+	t.names = append(t.names, engineName)
+	return tx, nil
+}
+
+func (t *placementTx) GetStore(name []byte) (engine.Store, error) {
+	tx, err := t.txOf(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return tx.GetStore(name)
+}
+
+func (t *placementTx) CreateStore(name []byte) error {
+	tx, err := t.txOf(name)
+	if err != nil {
+		return err
+	}
+
+	return tx.CreateStore(name)
+}
+
+func (t *placementTx) DropStore(name []byte) error {
+	tx, err := t.txOf(name)
+	if err != nil {
+		return err
+	}
+
+	return tx.DropStore(name)
+}
+
+// Rollback the transactions of every engine.
+func (t *placementTx) Rollback() error {
+	err := t.Transaction.Rollback()
+	for _, name := range t.names {
+		if er := t.txs[name].Rollback(); err == nil {
+			err = er
+		}
+	}
+
+	return err
+}
+
+// Commit the transactions of every engine with a two-phase commit.
+// The transactions implementing engine.Preparer are prepared first, and
+// everything is rolled back if one of them fails.
+// They are then committed, the one of the primary engine last, as it contains
+// the catalog referencing the stores of the other engines.
+// There is no recovery log: if a commit fails during the second phase,
+// the changes made to the engines committed before are kept.
+func (t *placementTx) Commit() error {
+	if len(t.names) == 0 {
+		return t.Transaction.Commit()
+	}
+
+	txs := make([]engine.Transaction, 0, len(t.names)+1)
+	for _, name := range t.names {
+		txs = append(txs, t.txs[name])
+	}
+	txs = append(txs, t.Transaction)
+
+	for _, tx := range txs {
+		if p, ok := tx.(engine.Preparer); ok {
+			if err := p.Prepare(); err != nil {
+				_ = t.Rollback()
+				return err
+			}
+		}
+	}
+
+	for i, tx := range txs {
+		err := tx.Commit()
+		if err == nil {
+			continue
+		}
+
+		for _, tx := range txs[i+1:] {
+			_ = tx.Rollback()
+		}
+
+		if i == 0 {
+			return err
+		}
+		// the error isn't wrapped: a partially committed transaction
+		// must not be retried, even if it failed because of a conflict.
+		return stringutil.Errorf("transaction partially committed: %v", err)
+	}
+
+	return nil
+}
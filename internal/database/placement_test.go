@@ -0,0 +1,112 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/engine/memoryengine"
+	"github.com/genjidb/genji/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// preparingEngine returns engines whose transactions fail to be prepared
+// when err is set.
+type preparingEngine struct {
+	engine.Engine
+
+	err error
+}
+
+func (e *preparingEngine) Begin(ctx context.Context, opts engine.TxOptions) (engine.Transaction, error) {
+	tx, err := e.Engine.Begin(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &preparingTx{Transaction: tx, err: e.err}, nil
+}
+
+type preparingTx struct {
+	engine.Transaction
+
+	err error
+}
+
+func (tx *preparingTx) Prepare() error {
+	return tx.err
+}
+
+func TestPlacement(t *testing.T) {
+	db, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	secondary := &preparingEngine{Engine: memoryengine.NewEngine()}
+	db.SetEngine("secondary", secondary)
+
+	exec := func(q string) error {
+		tx, err := db.Begin(true)
+		require.NoError(t, err)
+		defer tx.Rollback()
+
+		err = testutil.Exec(db, tx, q)
+		if err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	err := exec(`
+		CREATE TABLE foo (a INTEGER) WITH (engine = 'secondary');
+		CREATE INDEX idx_foo_a ON foo (a);
+		CREATE TABLE bar (a INTEGER);
+	`)
+	require.NoError(t, err)
+
+	require.NoError(t, exec(`INSERT INTO foo (a) VALUES (1); INSERT INTO bar (a) VALUES (1)`))
+
+	// the stores of the table and its index are in the secondary engine
+	tx, err := secondary.Begin(context.Background(), engine.TxOptions{})
+	require.NoError(t, err)
+	ti, err := db.Catalog.GetTableInfo("foo")
+	require.NoError(t, err)
+	_, err = tx.GetStore(ti.StoreName)
+	require.NoError(t, err)
+	info, err := db.Catalog.GetIndexInfo("idx_foo_a")
+	require.NoError(t, err)
+	_, err = tx.GetStore(info.StoreName)
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+
+	// failing to prepare the secondary transaction rolls back every engine
+	secondary.err = errors.New("prepare failed")
+	err = exec(`INSERT INTO foo (a) VALUES (2); INSERT INTO bar (a) VALUES (2)`)
+	require.EqualError(t, err, "prepare failed")
+	secondary.err = nil
+
+	count := func(table string) int64 {
+		tx, err := db.Begin(false)
+		require.NoError(t, err)
+		defer tx.Rollback()
+
+		res := testutil.MustQuery(t, db, tx, "SELECT COUNT(*) FROM "+table)
+		defer res.Close()
+
+		var n int64
+		err = res.Iterate(func(d document.Document) error {
+			return document.Scan(d, &n)
+		})
+		require.NoError(t, err)
+		return n
+	}
+	require.EqualValues(t, 1, count("foo"))
+	require.EqualValues(t, 1, count("bar"))
+
+	// unregistered engines can't be used
+	db.SetEngine("secondary", nil)
+	err = exec(`INSERT INTO foo (a) VALUES (3)`)
+	require.Error(t, err)
+	require.EqualValues(t, 1, count("bar"))
+}
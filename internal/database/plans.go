@@ -0,0 +1,177 @@
+package database
+
+import (
+	"hash/fnv"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/genjidb/genji/engine"
+)
+
+// PlanChangesTableName is the name of the virtual table
+// listing the statements whose plan changed since the database was opened.
+const PlanChangesTableName = InternalPrefix + "plan_changes"
+
+// plansStoreName is the name of the store persisting the last plan
+// chosen for every statement, keyed by normalized statement.
+const plansStoreName = InternalPrefix + "plans"
+
+const (
+	// maximum number of statements whose plan is recorded.
+	// Plans of new statements are ignored once it is reached.
+	maxPlanStatements = 1000
+	// maximum number of changes kept, the oldest ones are dropped first.
+	maxPlanChanges = 100
+)
+
+// A PlanChange describes a statement whose plan changed,
+// usually after a change of the schema or of the statistics.
+type PlanChange struct {
+	Statement string
+	OldPlan   string
+	NewPlan   string
+	At        time.Time
+}
+
+// A PlanHistory records the plan chosen by the optimizer for every statement,
+// and reports the statements whose plan changed, which helps catching
+// performance regressions. Plans are persisted when the database is closed.
+// It is safe for concurrent use.
+type PlanHistory struct {
+	logger *log.Logger
+
+	mu      sync.Mutex
+	plans   map[string]string
+	dirty   map[string]bool
+	changes []PlanChange
+}
+
+// NewPlanHistory creates an empty plan history logging the plan changes to logger.
+// If logger is nil, the standard logger is used.
+func NewPlanHistory(logger *log.Logger) *PlanHistory {
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	return &PlanHistory{
+		logger: logger,
+		plans:  make(map[string]string),
+		dirty:  make(map[string]bool),
+	}
+}
+
+// PlanFingerprint returns a short hash identifying the given plan.
+func PlanFingerprint(plan string) string {
+	h := fnv.New64a()
+	h.Write([]byte(plan))
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// Record stores the plan chosen for the given normalized statement at the given time.
+// If it differs from the previous plan of the statement, the change is logged and
+// reported by Changes.
+func (h *PlanHistory) Record(stmt, plan string, at time.Time) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	old, ok := h.plans[stmt]
+	if ok && old == plan {
+		return
+	}
+	if !ok && len(h.plans) >= maxPlanStatements {
+		return
+	}
+
+	h.plans[stmt] = plan
+	h.dirty[stmt] = true
+	if !ok {
+		return
+	}
+
+	if len(h.changes) >= maxPlanChanges {
+		h.changes = append(h.changes[:0], h.changes[1:]...)
+	}
+	h.changes = append(h.changes, PlanChange{Statement: stmt, OldPlan: old, NewPlan: plan, At: at})
+
+	h.logger.Printf("genji: plan of statement %q changed from %q (%s) to %q (%s)",
+		stmt, old, PlanFingerprint(old), plan, PlanFingerprint(plan))
+}
+
+// Changes returns the plan changes recorded since the database was opened,
+// oldest first.
+func (h *PlanHistory) Changes() []PlanChange {
+	if h == nil {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return append([]PlanChange(nil), h.changes...)
+}
+
+// load reads the plans persisted in the store, if any.
+func (h *PlanHistory) load(tx engine.Transaction) error {
+	st, err := tx.GetStore([]byte(plansStoreName))
+	if err == engine.ErrStoreNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	it := st.Iterator(engine.IteratorOptions{})
+	defer it.Close()
+
+	var buf []byte
+	for it.Seek(nil); it.Valid(); it.Next() {
+		item := it.Item()
+		buf, err = item.ValueCopy(buf[:0])
+		if err != nil {
+			return err
+		}
+
+		h.plans[string(item.Key())] = string(buf)
+	}
+
+	return it.Err()
+}
+
+// save persists the plans recorded since the last call.
+func (h *PlanHistory) save(tx engine.Transaction) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.dirty) == 0 {
+		return nil
+	}
+
+	st, err := getOrCreateStore(tx, []byte(plansStoreName))
+	if err != nil {
+		return err
+	}
+
+	for stmt := range h.dirty {
+		err = st.Put([]byte(stmt), []byte(h.plans[stmt]))
+		if err != nil {
+			return err
+		}
+	}
+
+	h.dirty = make(map[string]bool)
+	return nil
+}
+
+// Plans returns the history of the plans chosen for the statements run by the database.
+func (db *Database) Plans() *PlanHistory {
+	return db.plans
+}
@@ -0,0 +1,128 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// A Process is a query being run by the database. It is registered
+// when the query starts and remains until its result is closed.
+type Process struct {
+	ID int64
+	// SQL text of the query, if known.
+	SQL       string
+	StartedAt time.Time
+
+	cancel context.CancelFunc
+	list   *processList
+	// throttle of the database, and whether the process holds one of its slots.
+	throttle *Throttle
+	slot     bool
+}
+
+// End unregisters the process and releases its context.
+// It is safe to call it multiple times.
+func (p *Process) End() {
+	if p == nil {
+		return
+	}
+
+	p.cancel()
+
+	p.list.mu.Lock()
+	delete(p.list.procs, p.ID)
+	slot := p.slot
+	p.slot = false
+	p.list.mu.Unlock()
+
+	if slot {
+		p.throttle.ReleaseStatement()
+	}
+}
+
+// AcquireSlot waits until the throttle of the database allows the process
+// to run its statements, or until ctx is canceled.
+// The slot is released when the process ends.
+func (p *Process) AcquireSlot(ctx context.Context) error {
+	err := p.throttle.AcquireStatement(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.list.mu.Lock()
+	p.slot = true
+	p.list.mu.Unlock()
+
+	return nil
+}
+
+// processList keeps track of the running processes.
+type processList struct {
+	mu     sync.Mutex
+	lastID int64
+	procs  map[int64]*Process
+}
+
+// StartProcess registers a new process running the given query.
+// The returned context is canceled when the process is killed or ended,
+// and must be used by the process to run the query.
+func (db *Database) StartProcess(ctx context.Context, sql string) (context.Context, *Process) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	l := &db.processes
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.procs == nil {
+		l.procs = make(map[int64]*Process)
+	}
+
+	l.lastID++
+	p := Process{
+		ID:        l.lastID,
+		SQL:       sql,
+		StartedAt: time.Now(),
+		cancel:    cancel,
+		list:      l,
+		throttle:  db.throttle,
+	}
+	l.procs[p.ID] = &p
+
+	return ctx, &p
+}
+
+// Processes returns the processes currently running, ordered by ID.
+func (db *Database) Processes() []Process {
+	l := &db.processes
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	procs := make([]Process, 0, len(l.procs))
+	for _, p := range l.procs {
+		procs = append(procs, Process{ID: p.ID, SQL: p.SQL, StartedAt: p.StartedAt})
+	}
+	sort.Slice(procs, func(i, j int) bool { return procs[i].ID < procs[j].ID })
+
+	return procs
+}
+
+// Kill cancels the context of the selected process. The statement it runs fails
+// with context.Canceled the next time it reads a document, and its transaction
+// is rolled back if the process owns it.
+func (db *Database) Kill(id int64) error {
+	l := &db.processes
+	l.mu.Lock()
+	p, ok := l.procs[id]
+	l.mu.Unlock()
+
+	if !ok {
+		return stringutil.Errorf("process %d not found", id)
+	}
+
+	p.cancel()
+	return nil
+}
@@ -0,0 +1,269 @@
+package database
+
+import (
+	"strings"
+
+	"github.com/genjidb/genji/engine"
+)
+
+// A savepoint records how to undo the changes made to the stores of a transaction,
+// so that they can be undone without rolling back the whole transaction.
+// Changes made to internal stores, like the catalog or the sequences, are not recorded,
+// as they are mirrored by in-memory structures that are not restored.
+// The attachment store is the exception, as it holds parts of the documents.
+type savepoint struct {
+	tx     engine.Transaction
+	active bool
+	undo   []func() error
+}
+
+// recorded returns whether the changes made to the given store are recorded.
+func (sp *savepoint) recorded(name []byte) bool {
+	return !strings.HasPrefix(string(name), InternalPrefix) || string(name) == attachmentStoreName
+}
+
+// snapshot returns a function restoring the current content of the store.
+func (sp *savepoint) snapshot(name []byte, st engine.Store) (func() error, error) {
+	var keys, values [][]byte
+
+	it := st.Iterator(engine.IteratorOptions{})
+	for it.Seek(nil); it.Valid(); it.Next() {
+		v, err := it.Item().ValueCopy(nil)
+		if err != nil {
+			it.Close()
+			return nil, err
+		}
+
+		keys = append(keys, append([]byte{}, it.Item().Key()...))
+		values = append(values, v)
+	}
+	err := it.Err()
+	if cerr := it.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		st, err := sp.tx.GetStore(name)
+		if err != nil {
+			return err
+		}
+
+		for i := range keys {
+			err = st.Put(keys[i], values[i])
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, nil
+}
+
+// rollback undoes the recorded changes, in reverse order.
+func (sp *savepoint) rollback() error {
+	for i := len(sp.undo) - 1; i >= 0; i-- {
+		err := sp.undo[i]()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// savepointTx records the changes made through it in a savepoint.
+type savepointTx struct {
+	engine.Transaction
+
+	sp *savepoint
+}
+
+func (tx *savepointTx) GetStore(name []byte) (engine.Store, error) {
+	st, err := tx.Transaction.GetStore(name)
+	if err != nil || !tx.sp.recorded(name) {
+		return st, err
+	}
+
+	return &savepointStore{Store: st, name: name, sp: tx.sp}, nil
+}
+
+func (tx *savepointTx) CreateStore(name []byte) error {
+	err := tx.Transaction.CreateStore(name)
+	if err != nil || !tx.sp.active || !tx.sp.recorded(name) {
+		return err
+	}
+
+	tx.sp.undo = append(tx.sp.undo, func() error {
+		return tx.Transaction.DropStore(name)
+	})
+	return nil
+}
+
+func (tx *savepointTx) DropStore(name []byte) error {
+	if !tx.sp.active || !tx.sp.recorded(name) {
+		return tx.Transaction.DropStore(name)
+	}
+
+	st, err := tx.Transaction.GetStore(name)
+	if err != nil {
+		return err
+	}
+
+	restore, err := tx.sp.snapshot(name, st)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Transaction.DropStore(name)
+	if err != nil {
+		return err
+	}
+
+	tx.sp.undo = append(tx.sp.undo, func() error {
+		err := tx.Transaction.CreateStore(name)
+		if err != nil {
+			return err
+		}
+
+		return restore()
+	})
+	return nil
+}
+
+// savepointStore records the changes made to a store in a savepoint.
+type savepointStore struct {
+	engine.Store
+
+	name []byte
+	sp   *savepoint
+}
+
+// previous returns a function restoring the current value of k.
+func (s *savepointStore) previous(k []byte) (func() error, error) {
+	k = append([]byte{}, k...)
+
+	v, err := s.Store.Get(k)
+	if err == engine.ErrKeyNotFound {
+		return func() error {
+			st, err := s.sp.tx.GetStore(s.name)
+			if err != nil {
+				return err
+			}
+
+			return st.Delete(k)
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	v = append([]byte{}, v...)
+	return func() error {
+		st, err := s.sp.tx.GetStore(s.name)
+		if err != nil {
+			return err
+		}
+
+		return st.Put(k, v)
+	}, nil
+}
+
+func (s *savepointStore) Put(k, v []byte) error {
+	if !s.sp.active {
+		return s.Store.Put(k, v)
+	}
+
+	undo, err := s.previous(k)
+	if err != nil {
+		return err
+	}
+
+	err = s.Store.Put(k, v)
+	if err != nil {
+		return err
+	}
+
+	s.sp.undo = append(s.sp.undo, undo)
+	return nil
+}
+
+func (s *savepointStore) Delete(k []byte) error {
+	if !s.sp.active {
+		return s.Store.Delete(k)
+	}
+
+	undo, err := s.previous(k)
+	if err != nil {
+		return err
+	}
+
+	err = s.Store.Delete(k)
+	if err != nil {
+		return err
+	}
+
+	s.sp.undo = append(s.sp.undo, undo)
+	return nil
+}
+
+func (s *savepointStore) Truncate() error {
+	if !s.sp.active {
+		return s.Store.Truncate()
+	}
+
+	undo, err := s.sp.snapshot(s.name, s.Store)
+	if err != nil {
+		return err
+	}
+
+	err = s.Store.Truncate()
+	if err != nil {
+		return err
+	}
+
+	s.sp.undo = append(s.sp.undo, undo)
+	return nil
+}
+
+// Savepoint starts recording the changes made by the transaction, so that they
+// can be undone by RollbackToSavepoint without rolling back the whole transaction.
+// If a savepoint is already active, it is replaced by the new one.
+// Every write made while the savepoint is active costs an additional read.
+func (tx *Transaction) Savepoint() {
+	if tx.savepoint == nil {
+		// the engine transaction is wrapped once and for all, as the stores
+		// may be referenced after the savepoint is released.
+		tx.savepoint = &savepoint{tx: tx.Tx}
+		tx.Tx = &savepointTx{Transaction: tx.Tx, sp: tx.savepoint}
+	}
+
+	tx.savepoint.active = true
+	tx.savepoint.undo = nil
+}
+
+// ReleaseSavepoint stops recording the changes made by the transaction
+// and keeps the changes made since the call to Savepoint.
+func (tx *Transaction) ReleaseSavepoint() {
+	if tx.savepoint == nil {
+		return
+	}
+
+	tx.savepoint.active = false
+	tx.savepoint.undo = nil
+}
+
+// RollbackToSavepoint undoes the changes made since the call to Savepoint
+// and releases the savepoint.
+func (tx *Transaction) RollbackToSavepoint() error {
+	if tx.savepoint == nil || !tx.savepoint.active {
+		return nil
+	}
+
+	tx.savepoint.active = false
+	err := tx.savepoint.rollback()
+	tx.savepoint.undo = nil
+	return err
+}
@@ -167,6 +167,41 @@ func (s *Sequence) SetLease(tx *Transaction, catalog Catalog, name string, v int
 	return err
 }
 
+// Lease returns the last value leased by the sequence, as stored in the sequence table.
+// Values between the current value and the lease are lost if the database
+// is not closed properly. It returns nil if the sequence has no lease.
+func (s *Sequence) Lease(tx *Transaction, catalog Catalog) (*int64, error) {
+	tb, err := catalog.GetTable(tx, SequenceTableName)
+	if err != nil {
+		if errs.IsNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	d, err := tb.GetDocument([]byte(s.Info.Name))
+	if err != nil {
+		if err == errs.ErrDocumentNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	v, err := d.GetByField("seq")
+	if err != nil {
+		if err == document.ErrFieldNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if v.Type != document.IntegerValue {
+		return nil, nil
+	}
+
+	lease := v.V.(int64)
+	return &lease, nil
+}
+
 func (s *Sequence) GetOrCreateTable(tx *Transaction, catalog Catalog) (*Table, error) {
 	tb, err := catalog.GetTable(tx, SequenceTableName)
 	if err == nil || !errs.IsNotFoundError(err) {
@@ -219,3 +254,37 @@ func (s *Sequence) Release(tx *Transaction, catalog Catalog) error {
 	s.Cached = s.Info.Cache
 	return nil
 }
+
+// Restart the sequence so that the next call to Next returns v.
+// The in-memory state of the sequence is restored if the transaction is rolled back.
+func (s *Sequence) Restart(tx *Transaction, catalog Catalog, v int64) error {
+	if !tx.Writable {
+		return errors.New("cannot restart sequence on read-only transaction")
+	}
+
+	if v < s.Info.Min || v > s.Info.Max {
+		return stringutil.Errorf("restart value %d is out of the bounds of sequence %s", v, s.Info.Name)
+	}
+
+	// the current value is the one preceding v
+	cur := v - s.Info.IncrementBy
+	if (cur < v) != (s.Info.IncrementBy > 0) {
+		return stringutil.Errorf("restart value %d is out of the bounds of sequence %s", v, s.Info.Name)
+	}
+
+	err := s.SetLease(tx, catalog, s.Info.Name, cur)
+	if err != nil {
+		return err
+	}
+
+	prevValue, prevCached := s.CurrentValue, s.Cached
+	tx.OnRollbackHooks = append(tx.OnRollbackHooks, func() {
+		s.CurrentValue, s.Cached = prevValue, prevCached
+	})
+
+	// the lease is equal to the current value, the next call to Next
+	// must extend it.
+	s.CurrentValue = &cur
+	s.Cached = s.Info.Cache
+	return nil
+}
@@ -0,0 +1,183 @@
+package database
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/engine"
+)
+
+// StatisticsPrefixLen is the number of characters used to group
+// the text values of an index when collecting statistics.
+const StatisticsPrefixLen = 3
+
+// IndexStatistics describes the content of an index.
+// They are used by the planner to estimate how many documents
+// a range scan would read.
+type IndexStatistics struct {
+	// Number of entries of the index.
+	Count int64
+
+	// Histogram of the text values of the first indexed path,
+	// grouped by their lowercased first PrefixLen characters.
+	// Texts shorter than PrefixLen are stored entirely.
+	// Nil if the first path of the index cannot contain text values.
+	Prefixes  map[string]int64
+	PrefixLen int
+}
+
+// PrefixSelectivity estimates the fraction of the entries of the index
+// whose first value is a text starting with prefix, ignoring case.
+// If prefix is longer than the histogram prefixes, the returned value
+// is the selectivity of its first PrefixLen characters.
+func (s *IndexStatistics) PrefixSelectivity(prefix string) float64 {
+	if s.Count == 0 {
+		return 0
+	}
+
+	prefix = strings.ToLower(prefix)
+
+	var n int64
+	if utf8.RuneCountInString(prefix) >= s.PrefixLen {
+		n = s.Prefixes[truncateRunes(prefix, s.PrefixLen)]
+	} else {
+		for k, c := range s.Prefixes {
+			if strings.HasPrefix(k, prefix) {
+				n += c
+			}
+		}
+	}
+
+	return float64(n) / float64(s.Count)
+}
+
+// Analyze reads the entire index and returns statistics about its content.
+func (idx *Index) Analyze() (*IndexStatistics, error) {
+	stats := IndexStatistics{
+		PrefixLen: StatisticsPrefixLen,
+	}
+
+	typ := idx.Info.Types[0]
+	if typ.IsAny() || typ == document.TextValue {
+		stats.Prefixes = make(map[string]int64)
+	}
+
+	err := idx.AscendGreaterOrEqual(nil, func(val, key []byte) error {
+		stats.Count++
+
+		if stats.Prefixes == nil {
+			return nil
+		}
+
+		// untyped indexes prefix each value with its type
+		if typ.IsAny() {
+			if len(val) == 0 || val[0] != byte(document.TextValue) {
+				return nil
+			}
+			val = val[1:]
+		}
+
+		// the values of composite indexes are separated by a delimiter
+		if idx.IsComposite() {
+			if i := bytes.IndexByte(val, document.ArrayValueDelim); i >= 0 {
+				val = val[:i]
+			}
+		}
+
+		stats.Prefixes[strings.ToLower(truncateRunes(string(val), stats.PrefixLen))]++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+// truncateRunes returns the first n characters of s.
+func truncateRunes(s string, n int) string {
+	for i := range s {
+		if n == 0 {
+			return s[:i]
+		}
+		n--
+	}
+
+	return s
+}
+
+// TablesStatsTableName is the name of the virtual table
+// returning the statistics of every table, as returned by Table.Stats.
+const TablesStatsTableName = InternalPrefix + "tables_stats"
+
+// TableStats describes the space used by a table and its indexes.
+type TableStats struct {
+	// Number of documents of the table.
+	Documents int64
+	// Number of bytes used by the documents, keys included.
+	Size int64
+	// Number of bytes used by the indexes of the table.
+	IndexSize int64
+}
+
+// Stats returns the number of documents of the table and the space used
+// by the table and its indexes. The sizes are reported by the engine
+// if its stores implement engine.StoreSizer, otherwise they are the sum
+// of the sizes of the keys and values they contain.
+func (t *Table) Stats() (*TableStats, error) {
+	var stats TableStats
+	var err error
+
+	stats.Documents, stats.Size, err = storeSize(t.Store)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes, err := t.GetIndexes()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, idx := range indexes {
+		st, err := idx.tx.GetStore(idx.Info.StoreName)
+		if err == engine.ErrStoreNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		_, size, err := storeSize(st)
+		if err != nil {
+			return nil, err
+		}
+		stats.IndexSize += size
+	}
+
+	return &stats, nil
+}
+
+func storeSize(st engine.Store) (count int64, size int64, err error) {
+	if s, ok := st.(engine.StoreSizer); ok {
+		return s.Size()
+	}
+
+	it := st.Iterator(engine.IteratorOptions{})
+	defer it.Close()
+
+	var buf []byte
+	for it.Seek(nil); it.Valid(); it.Next() {
+		item := it.Item()
+		buf, err = item.ValueCopy(buf[:0])
+		if err != nil {
+			return 0, 0, err
+		}
+
+		count++
+		size += int64(len(item.Key()) + len(buf))
+	}
+
+	return count, size, it.Err()
+}
@@ -2,8 +2,9 @@ package database
 
 import (
 	"bytes"
-	"encoding/binary"
 	"errors"
+	"sort"
+	"strings"
 
 	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/document/encoding"
@@ -28,10 +29,92 @@ type Table struct {
 
 	Catalog Catalog
 	Codec   encoding.Codec
+
+	// if non nil, index mutations done by Replace and Delete
+	// are stored in these batches until FlushIndexes is called.
+	indexBatches map[string]*indexBatch
+}
+
+// BatchIndexes defers every index mutation performed by Replace and Delete
+// until FlushIndexes is called. Mutations are then applied index by index, in key order,
+// and mutations canceling each other, like replacing a document without modifying
+// its indexed values, are skipped.
+// It is meant to be used when updating or deleting a large number of documents.
+// Insert is not affected and always updates indexes immediately.
+func (t *Table) BatchIndexes() {
+	if t.indexBatches == nil {
+		t.indexBatches = make(map[string]*indexBatch)
+	}
+}
+
+// FlushIndexes applies the index mutations deferred since the call to BatchIndexes
+// and stops batching.
+func (t *Table) FlushIndexes() error {
+	if t.indexBatches == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(t.indexBatches))
+	for name := range t.indexBatches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		err := t.indexBatches[name].Flush()
+		if err != nil {
+			if err == ErrIndexDuplicateValue {
+				// the batch doesn't keep the values, only their encoding
+				return t.uniqueViolationError(t.indexBatches[name].idx, nil)
+			}
+
+			return err
+		}
+	}
+
+	t.indexBatches = nil
+	return nil
+}
+
+// setIndex associates vs with key in idx, or defers it if index mutations are batched.
+func (t *Table) setIndex(idx *Index, vs []document.Value, key []byte) error {
+	if t.indexBatches == nil {
+		return idx.Set(vs, key)
+	}
+
+	return t.getIndexBatch(idx).Set(vs, key)
+}
+
+// deleteFromIndex removes the association of vs with key from idx, or defers it if index mutations are batched.
+func (t *Table) deleteFromIndex(idx *Index, vs []document.Value, key []byte) error {
+	if t.indexBatches == nil {
+		err := idx.Delete(vs, key)
+		if err == engine.ErrKeyNotFound && idx.Info.Rebuilding {
+			return nil
+		}
+		return err
+	}
+
+	return t.getIndexBatch(idx).Delete(vs, key)
+}
+
+func (t *Table) getIndexBatch(idx *Index) *indexBatch {
+	b, ok := t.indexBatches[idx.Info.IndexName]
+	if !ok {
+		b = newIndexBatch(idx)
+		t.indexBatches[idx.Info.IndexName] = b
+	}
+
+	return b
 }
 
 // Truncate deletes all the documents from the table.
 func (t *Table) Truncate() error {
+	err := t.DeleteAttachments()
+	if err != nil {
+		return err
+	}
+
 	return t.Store.Truncate()
 }
 
@@ -57,17 +140,32 @@ func (t *Table) InsertWithConflictResolution(d document.Document, onConflict OnI
 		return nil, errors.New("cannot write to read-only table")
 	}
 
+	if t.hasIdentities() {
+		var err error
+		d, err = t.generateIdentities(d)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	fb, err := t.Info.FieldConstraints.ValidateDocument(t.Tx, d)
 	if err != nil {
+		err = withTableName(err, t.Info.TableName)
 		if onConflict != nil {
-			if ce, ok := err.(*ConstraintViolationError); ok && ce.Constraint == "NOT NULL" {
+			if ce, ok := err.(errs.ConstraintViolationError); ok && ce.Constraint == "NOT NULL" {
 				return onConflict(t, nil, d, err)
 			}
 		}
 		return nil, err
 	}
 
-	key, err := t.generateKey(t.Info, d)
+	if t.Info.FieldOrder == FieldOrderCanonical {
+		fb.SortFields()
+	}
+
+	// the key is generated from the converted document,
+	// so that it is encoded with the type of the primary key.
+	key, err := t.generateKey(t.Info, fb)
 	if err != nil {
 		return nil, err
 	}
@@ -75,11 +173,12 @@ func (t *Table) InsertWithConflictResolution(d document.Document, onConflict OnI
 	// ensure the key is not already present in the table
 	_, err = t.Store.Get(key)
 	if err == nil {
+		err = t.primaryKeyViolationError(fb)
 		if onConflict != nil {
 			return onConflict(t, key, d, err)
 		}
 
-		return nil, errs.ErrDuplicateDocument
+		return nil, err
 	}
 
 	indexes, err := t.GetIndexes()
@@ -110,27 +209,35 @@ func (t *Table) InsertWithConflictResolution(d document.Document, onConflict OnI
 			return nil, err
 		}
 		if duplicate {
+			err = t.uniqueViolationError(idx, vs)
 			if onConflict != nil {
 				return onConflict(t, dKey, d, err)
 			}
 
-			return nil, errs.ErrDuplicateDocument
+			return nil, err
 		}
 	}
 
+	// move large values out of row
+	stored, err := t.detachValues(key, fb)
+	if err != nil {
+		return nil, err
+	}
+
 	// insert into the table
 	var buf bytes.Buffer
 	enc := t.Tx.Codec.NewEncoder(&buf)
 	defer enc.Close()
-	err = enc.EncodeDocument(fb)
+	err = enc.EncodeDocument(stored)
 	if err != nil {
 		return nil, stringutil.Errorf("failed to encode document: %w", err)
 	}
 
-	err = t.Store.Put(key, buf.Bytes())
+	err = t.Store.Put(key, t.appendChecksum(buf.Bytes()))
 	if err != nil {
 		return nil, err
 	}
+	t.countWrite()
 
 	// update indexes
 	for _, idx := range indexes {
@@ -151,13 +258,27 @@ func (t *Table) InsertWithConflictResolution(d document.Document, onConflict OnI
 		}
 	}
 
+	err = t.audit("insert", key, nil, fb)
+	if err != nil {
+		return nil, err
+	}
+
 	return documentWithKey{
-		Document: fb,
-		key:      key,
-		pk:       t.Info.FieldConstraints.GetPrimaryKey(),
+		Document:      fb,
+		key:           key,
+		pks:           t.Info.FieldConstraints.GetPrimaryKeys(),
+		docidStrategy: t.Info.DocidStrategy,
 	}, nil
 }
 
+// countWrite records that a document of the table was written by the transaction.
+// The writes made to the internal tables, like the catalog, are not counted.
+func (t *Table) countWrite() {
+	if !strings.HasPrefix(t.Info.TableName, InternalPrefix) {
+		t.Tx.documentWrites++
+	}
+}
+
 // GetIndexes returns all indexes of the table.
 func (t *Table) GetIndexes() (Indexes, error) {
 	if t.Indexes != nil {
@@ -190,6 +311,19 @@ func (t *Table) Delete(key []byte) error {
 		return err
 	}
 
+	if t.Info.Audit {
+		old := document.NewFieldBuffer()
+		err = old.Copy(d)
+		if err != nil {
+			return err
+		}
+
+		err = t.audit("delete", key, old, nil)
+		if err != nil {
+			return err
+		}
+	}
+
 	indexes, err := t.GetIndexes()
 	if err != nil {
 		return err
@@ -210,13 +344,24 @@ func (t *Table) Delete(key []byte) error {
 			vs = append(vs, v)
 		}
 
-		err = idx.Delete(vs, key)
+		err = t.deleteFromIndex(idx, vs, key)
 		if err != nil {
 			return err
 		}
 	}
 
-	return t.Store.Delete(key)
+	err = t.deleteAttachments(attachmentPrefix(t.Info.StoreName, key))
+	if err != nil {
+		return err
+	}
+
+	err = t.Store.Delete(key)
+	if err != nil {
+		return err
+	}
+
+	t.countWrite()
+	return nil
 }
 
 // Replace a document by key.
@@ -227,9 +372,30 @@ func (t *Table) Replace(key []byte, d document.Document) (document.Document, err
 		return nil, errors.New("cannot write to read-only table")
 	}
 
-	d, err := t.Info.FieldConstraints.ValidateDocument(t.Tx, d)
+	fb, err := t.Info.FieldConstraints.ValidateDocument(t.Tx, d)
 	if err != nil {
-		return nil, err
+		return nil, withTableName(err, t.Info.TableName)
+	}
+	if t.Info.FieldOrder == FieldOrderCanonical {
+		fb.SortFields()
+	}
+	d = fb
+
+	if t.Info.Audit {
+		old, err := t.GetDocument(key)
+		if err != nil {
+			return nil, err
+		}
+		fb := document.NewFieldBuffer()
+		err = fb.Copy(old)
+		if err != nil {
+			return nil, err
+		}
+
+		err = t.audit("update", key, fb, d)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return d, t.replace(key, d)
@@ -258,26 +424,33 @@ func (t *Table) replace(key []byte, d document.Document) error {
 			vs = append(vs, v)
 		}
 
-		err := idx.Delete(vs, key)
+		err := t.deleteFromIndex(idx, vs, key)
 		if err != nil {
 			return err
 		}
 	}
 
+	// move large values out of row
+	stored, err := t.detachValues(key, d)
+	if err != nil {
+		return err
+	}
+
 	// encode new document
 	var buf bytes.Buffer
 	enc := t.Tx.Codec.NewEncoder(&buf)
 	defer enc.Close()
-	err = enc.EncodeDocument(d)
+	err = enc.EncodeDocument(stored)
 	if err != nil {
 		return stringutil.Errorf("failed to encode document: %w", err)
 	}
 
 	// replace old document with new document
-	err = t.Store.Put(key, buf.Bytes())
+	err = t.Store.Put(key, t.appendChecksum(buf.Bytes()))
 	if err != nil {
 		return err
 	}
+	t.countWrite()
 
 	// update indexes
 	for _, idx := range indexes {
@@ -291,10 +464,10 @@ func (t *Table) replace(key []byte, d document.Document) error {
 			vs = append(vs, v)
 		}
 
-		err = idx.Set(vs, key)
+		err = t.setIndex(idx, vs, key)
 		if err != nil {
 			if err == ErrIndexDuplicateValue {
-				return errs.ErrDuplicateDocument
+				return t.uniqueViolationError(idx, vs)
 			}
 
 			return err
@@ -304,11 +477,87 @@ func (t *Table) replace(key []byte, d document.Document) error {
 	return nil
 }
 
+// uniqueViolationError returns the error describing the violation of the unique index idx
+// by the values vs. If vs is nil, the values are unknown.
+func (t *Table) uniqueViolationError(idx *Index, vs []document.Value) error {
+	e := errs.ConstraintViolationError{
+		Constraint: "UNIQUE",
+		TableName:  t.Info.TableName,
+		IndexName:  idx.Info.IndexName,
+	}
+
+	if len(idx.Info.Paths) == 1 {
+		e.Path = idx.Info.Paths[0]
+
+		if fc := t.Info.FieldConstraints.Get(e.Path); fc != nil && fc.UniqueName == idx.Info.IndexName {
+			e.Name = fc.UniqueName
+		}
+	}
+
+	switch {
+	case vs == nil:
+	case len(vs) == 1:
+		e.Value = vs[0]
+	default:
+		e.Value = document.NewArrayValue(document.NewValueBuffer(vs...))
+	}
+
+	return e
+}
+
+// primaryKeyViolationError returns the error reported when the primary key of d
+// already exists in the table. It matches errors.ErrDuplicateDocument.
+func (t *Table) primaryKeyViolationError(d document.Document) error {
+	pks := t.Info.FieldConstraints.GetPrimaryKeys()
+	if len(pks) == 0 {
+		return errs.ErrDuplicateDocument
+	}
+
+	e := errs.ConstraintViolationError{
+		Constraint: "PRIMARY KEY",
+		Name:       pks[0].PrimaryKeyName,
+		TableName:  t.Info.TableName,
+	}
+	if len(pks) == 1 {
+		e.Path = pks[0].Path
+	}
+
+	if v, err := primaryKeyValue(pks, d); err == nil {
+		e.Value = v
+	}
+
+	return e
+}
+
+// primaryKeyValue returns the value of the primary key of d.
+// The value of a primary key made of several fields is the array
+// of the values of these fields.
+func primaryKeyValue(pks FieldConstraints, d document.Document) (document.Value, error) {
+	if len(pks) == 1 {
+		return pks[0].Path.GetValueFromDocument(d)
+	}
+
+	vb := document.NewValueBuffer()
+	for _, pk := range pks {
+		v, err := pk.Path.GetValueFromDocument(d)
+		if err != nil {
+			return document.Value{}, err
+		}
+
+		vb = vb.Append(v)
+	}
+
+	return document.NewArrayValue(vb), nil
+}
+
 type documentWithKey struct {
 	document.Document
 
 	key []byte
-	pk  *FieldConstraint
+	// fields of the primary key, if any.
+	pks FieldConstraints
+	// strategy used to generate the key if there is no primary key.
+	docidStrategy string
 }
 
 func (e documentWithKey) MarshalJSON() ([]byte, error) {
@@ -320,12 +569,11 @@ func (e documentWithKey) RawKey() []byte {
 }
 
 func (e documentWithKey) Key() (document.Value, error) {
-	if e.pk == nil {
-		docid, _ := binary.Uvarint(e.key)
-		return document.NewIntegerValue(int64(docid)), nil
+	if len(e.pks) == 0 {
+		return decodeDocid(e.docidStrategy, e.key), nil
 	}
 
-	return e.pk.Path.GetValueFromDocument(&e)
+	return primaryKeyValue(e.pks, &e)
 }
 
 // This document implementation waits until
@@ -339,8 +587,10 @@ type lazilyDecodedDocument struct {
 	buf     []byte
 	codec   encoding.Codec
 	decoder encoding.Decoder
-	pk      *FieldConstraint
+	pks     FieldConstraints
 	dirty   bool
+	// table used to load the values stored out of row.
+	table *Table
 }
 
 func (d *lazilyDecodedDocument) GetByField(field string) (v document.Value, err error) {
@@ -358,7 +608,12 @@ func (d *lazilyDecodedDocument) GetByField(field string) (v document.Value, err
 		}
 	}
 
-	return d.decoder.GetByField(field)
+	v, err = d.decoder.GetByField(field)
+	if err != nil {
+		return
+	}
+
+	return d.table.attachedValue(d.item.Key(), field, v)
 }
 
 func (d *lazilyDecodedDocument) Iterate(fn func(field string, value document.Value) error) error {
@@ -376,7 +631,14 @@ func (d *lazilyDecodedDocument) Iterate(fn func(field string, value document.Val
 		}
 	}
 
-	return d.decoder.Iterate(fn)
+	return d.decoder.Iterate(func(field string, v document.Value) error {
+		v, err := d.table.attachedValue(d.item.Key(), field, v)
+		if err != nil {
+			return err
+		}
+
+		return fn(field, v)
+	})
 }
 
 func (d *lazilyDecodedDocument) RawKey() []byte {
@@ -384,13 +646,11 @@ func (d *lazilyDecodedDocument) RawKey() []byte {
 }
 
 func (d *lazilyDecodedDocument) Key() (document.Value, error) {
-	k := d.item.Key()
-	if d.pk == nil {
-		docid, _ := binary.Uvarint(k)
-		return document.NewIntegerValue(int64(docid)), nil
+	if len(d.pks) == 0 {
+		return decodeDocid(d.table.Info.DocidStrategy, d.item.Key()), nil
 	}
 
-	return d.pk.Path.GetValueFromDocument(d)
+	return primaryKeyValue(d.pks, d)
 }
 
 func (d *lazilyDecodedDocument) Reset() {
@@ -401,7 +661,11 @@ func (d *lazilyDecodedDocument) Reset() {
 func (d *lazilyDecodedDocument) copyFromItem() error {
 	var err error
 	d.buf, err = d.item.ValueCopy(d.buf)
+	if err != nil {
+		return err
+	}
 
+	d.buf, err = d.table.verifyChecksum(d.item.Key(), d.buf)
 	return err
 }
 
@@ -426,42 +690,42 @@ func (t *Table) EncodeValue(v document.Value) ([]byte, error) {
 func (t *Table) encodeValueToKey(info *TableInfo, v document.Value) ([]byte, error) {
 	var err error
 
-	pk := t.Info.FieldConstraints.GetPrimaryKey()
-	if pk == nil {
-		// if no primary key was defined, convert the pivot to an integer then to an unsigned integer
-		// and encode it as a varint
-		v, err = v.CastAsInteger()
+	pks := t.Info.FieldConstraints.GetPrimaryKeys()
+	if len(pks) == 0 {
+		return encodeDocid(t.Info.DocidStrategy, v)
+	}
+
+	// the value of a primary key made of several fields is an array.
+	// any other value is the value of its first field.
+	if len(pks) > 1 {
+		if v.Type != document.ArrayValue {
+			return encodeCompositeKey(pks, []document.Value{v})
+		}
+
+		var vs []document.Value
+		err = v.V.(document.Array).Iterate(func(i int, v document.Value) error {
+			vs = append(vs, v)
+			return nil
+		})
 		if err != nil {
 			return nil, err
 		}
+		return encodeCompositeKey(pks, vs)
+	}
 
-		docid := uint64(v.V.(int64))
+	pk := pks[0]
 
-		buf := make([]byte, binary.MaxVarintLen64)
-		n := binary.PutUvarint(buf, docid)
-		return buf[:n], nil
+	// convert the value to the type of the primary key, if any.
+	// it no primary key type is specified, integers are converted to doubles.
+	v, ok := document.ConvertKey(v, pk.Type)
+	if !ok {
+		return nil, stringutil.Errorf("cannot convert %s to a primary key of type %s", v.Type, pk.Type)
 	}
 
-	// if a primary key was defined and the primary is typed, convert the value to the right type.
 	if !pk.Type.IsAny() {
-		v, err = v.CastAs(pk.Type)
-		if err != nil {
-			return nil, err
-		}
-
 		return v.MarshalBinary()
 	}
 
-	// it no primary key type is specified,
-	// and the value to encode is an integer
-	// convert it to a double.
-	if v.Type == document.IntegerValue {
-		v, err = v.CastAsDouble()
-		if err != nil {
-			return nil, err
-		}
-	}
-
 	// encode key regardless of type.
 	var buf bytes.Buffer
 	err = document.NewValueEncoder(&buf).Encode(v)
@@ -476,7 +740,7 @@ func (t *Table) encodeValueToKey(info *TableInfo, v document.Value) ([]byte, err
 // The pivot is converted to the type of the primary key, if any, prior to iteration.
 // If the pivot is empty, it iterates from the beginning of the table.
 func (t *Table) AscendGreaterOrEqual(pivot document.Value, fn func(d document.Document) error) error {
-	return t.iterate(pivot, false, fn)
+	return t.iterate(pivot, engine.IteratorOptions{}, fn)
 }
 
 // DescendLessOrEqual iterates over the documents of the table whose key
@@ -484,10 +748,17 @@ func (t *Table) AscendGreaterOrEqual(pivot document.Value, fn func(d document.Do
 // The pivot is converted to the type of the primary key, if any, prior to iteration.
 // If the pivot is empty, it iterates from the end of the table in reverse order.
 func (t *Table) DescendLessOrEqual(pivot document.Value, fn func(d document.Document) error) error {
-	return t.iterate(pivot, true, fn)
+	return t.iterate(pivot, engine.IteratorOptions{Reverse: true}, fn)
 }
 
-func (t *Table) iterate(pivot document.Value, reverse bool, fn func(d document.Document) error) error {
+// IterateKeys is like AscendGreaterOrEqual, or DescendLessOrEqual if reverse is true,
+// but tells the engine that only the keys of the documents are needed, which allows it
+// to skip prefetching their content. The documents can still be read, but more slowly.
+func (t *Table) IterateKeys(pivot document.Value, reverse bool, fn func(d document.Document) error) error {
+	return t.iterate(pivot, engine.IteratorOptions{Reverse: reverse, KeysOnly: true}, fn)
+}
+
+func (t *Table) iterate(pivot document.Value, opts engine.IteratorOptions, fn func(d document.Document) error) error {
 	var seek []byte
 
 	// if there is a pivot, convert it to the right type
@@ -499,15 +770,27 @@ func (t *Table) iterate(pivot document.Value, reverse bool, fn func(d document.D
 		}
 	}
 
+	return t.iterateFromKey(seek, opts, fn)
+}
+
+// AscendFromKey iterates over the documents of the table, starting from the
+// document stored under the given raw key, or the one following it if it doesn't exist.
+// If key is nil, it iterates over the whole table.
+func (t *Table) AscendFromKey(key []byte, fn func(d document.Document) error) error {
+	return t.iterateFromKey(key, engine.IteratorOptions{}, fn)
+}
+
+func (t *Table) iterateFromKey(seek []byte, opts engine.IteratorOptions, fn func(d document.Document) error) error {
 	// To avoid unnecessary allocations, we create the struct once and reuse
 	// it during each iteration.
 	d := lazilyDecodedDocument{
 		codec: t.Tx.Codec,
+		table: t,
 	}
 
-	d.pk = t.Info.FieldConstraints.GetPrimaryKey()
+	d.pks = t.Info.FieldConstraints.GetPrimaryKeys()
 
-	it := t.Store.Iterator(engine.IteratorOptions{Reverse: reverse})
+	it := t.Store.Iterator(opts)
 	defer it.Close()
 
 	for it.Seek(seek); it.Valid(); it.Next() {
@@ -538,20 +821,111 @@ func (t *Table) GetDocument(key []byte) (document.Document, error) {
 		return nil, stringutil.Errorf("failed to fetch document %q: %w", key, err)
 	}
 
+	v, err = t.verifyChecksum(key, v)
+	if err != nil {
+		return nil, err
+	}
+
 	var d documentWithKey
-	d.Document = t.Tx.Codec.NewDecoder(v)
+	d.Document = &attachedDocument{
+		Document: t.Tx.Codec.NewDecoder(v),
+		table:    t,
+		key:      key,
+	}
 	d.key = key
-	d.pk = t.Info.FieldConstraints.GetPrimaryKey()
+	d.pks = t.Info.FieldConstraints.GetPrimaryKeys()
+	d.docidStrategy = t.Info.DocidStrategy
 	return &d, err
 }
 
+// LazyDocument returns the document identified by key without reading it from the store.
+// It is only fetched the first time one of its fields is read, and the errors, including
+// errs.ErrDocumentNotFound, are returned when reading it.
+func (t *Table) LazyDocument(key []byte) document.Document {
+	return &lazyDocument{
+		table: t,
+		key:   key,
+	}
+}
+
+// lazyDocument fetches its content when one of its fields
+// is read for the first time.
+type lazyDocument struct {
+	table *Table
+	key   []byte
+	d     document.Document
+}
+
+func (d *lazyDocument) fetch() error {
+	if d.d != nil {
+		return nil
+	}
+
+	var err error
+	d.d, err = d.table.GetDocument(d.key)
+	return err
+}
+
+func (d *lazyDocument) GetByField(field string) (document.Value, error) {
+	if err := d.fetch(); err != nil {
+		return document.Value{}, err
+	}
+
+	return d.d.GetByField(field)
+}
+
+func (d *lazyDocument) Iterate(fn func(field string, value document.Value) error) error {
+	if err := d.fetch(); err != nil {
+		return err
+	}
+
+	return d.d.Iterate(fn)
+}
+
+func (d *lazyDocument) RawKey() []byte {
+	return d.key
+}
+
+// Key only fetches the document if the key is a primary key.
+func (d *lazyDocument) Key() (document.Value, error) {
+	pks := d.table.Info.FieldConstraints.GetPrimaryKeys()
+	if len(pks) == 0 {
+		return documentWithKey{key: d.key, docidStrategy: d.table.Info.DocidStrategy}.Key()
+	}
+
+	return primaryKeyValue(pks, d)
+}
+
+func (d *lazyDocument) MarshalJSON() ([]byte, error) {
+	return document.MarshalJSON(d)
+}
+
 // generate a key for d based on the table configuration.
 // if the table has a primary key, it extracts the field from
 // the document, converts it to the targeted type and returns
 // its encoded version.
+// if the primary key is made of several fields, their values
+// are encoded with encodeCompositeKey.
 // if there are no primary key in the table, a default
 // key is generated, called the docid.
 func (t *Table) generateKey(info *TableInfo, d document.Document) ([]byte, error) {
+	if pks := t.Info.FieldConstraints.GetPrimaryKeys(); len(pks) > 1 {
+		vs := make([]document.Value, 0, len(pks))
+		for _, pk := range pks {
+			v, err := pk.Path.GetValueFromDocument(d)
+			if err == document.ErrFieldNotFound {
+				return nil, stringutil.Errorf("missing primary key at path %q", pk.Path)
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			vs = append(vs, v)
+		}
+
+		return encodeCompositeKey(pks, vs)
+	}
+
 	if pk := t.Info.FieldConstraints.GetPrimaryKey(); pk != nil {
 		v, err := pk.Path.GetValueFromDocument(d)
 		if err == document.ErrFieldNotFound {
@@ -577,16 +951,34 @@ func (t *Table) generateKey(info *TableInfo, d document.Document) ([]byte, error
 		return buf.Bytes(), nil
 	}
 
-	seq, err := t.Catalog.GetSequence(t.Info.DocidSequenceName)
-	if err != nil {
-		return nil, err
+	return t.generateDocid()
+}
+
+// encodeCompositeKey encodes the values of a primary key made of several fields.
+// Each value is converted to the type of its field and the values are encoded
+// together as an array, whose encoding preserves the order of the keys:
+// they are sorted by their first field, then by the second one, and so on.
+// vs may contain fewer values than there are fields, to encode a prefix of the key,
+// which is sorted before all the keys it is a prefix of.
+func encodeCompositeKey(pks FieldConstraints, vs []document.Value) ([]byte, error) {
+	if len(vs) > len(pks) {
+		return nil, stringutil.Errorf("primary key has %d fields, got %d values", len(pks), len(vs))
+	}
+
+	vb := document.NewValueBuffer()
+	for i, v := range vs {
+		cv, ok := document.ConvertKey(v, pks[i].Type)
+		if !ok {
+			return nil, stringutil.Errorf("cannot convert %s to a primary key of type %s", v.Type, pks[i].Type)
+		}
+
+		vb = vb.Append(cv)
 	}
-	docid, err := seq.Next(t.Tx, t.Catalog)
+
+	var buf bytes.Buffer
+	err := document.NewValueEncoder(&buf).Encode(document.NewArrayValue(vb))
 	if err != nil {
 		return nil, err
 	}
-
-	buf := make([]byte, binary.MaxVarintLen64)
-	n := binary.PutUvarint(buf, uint64(docid))
-	return buf[:n], nil
+	return buf.Bytes(), nil
 }
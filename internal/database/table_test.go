@@ -5,10 +5,12 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/document/encoding/msgpack"
+	"github.com/genjidb/genji/engine"
 	"github.com/genjidb/genji/engine/memoryengine"
 	errs "github.com/genjidb/genji/errors"
 	"github.com/genjidb/genji/internal/binarysort"
@@ -273,7 +275,55 @@ func TestTableInsert(t *testing.T) {
 
 		// insert again
 		_, err = tb.Insert(doc)
-		require.Equal(t, errs.ErrDuplicateDocument, err)
+		require.ErrorIs(t, err, errs.ErrDuplicateDocument)
+	})
+
+	t.Run("Should order the documents by the fields of a composite primary key", func(t *testing.T) {
+		db, tx, cleanup := newTestTx(t)
+		defer cleanup()
+
+		tb := createTable(t, tx, db.Catalog, database.TableInfo{
+			TableName: "test",
+			FieldConstraints: []*database.FieldConstraint{
+				{Path: testutil.ParseDocumentPath(t, "a"), Type: document.TextValue, IsPrimaryKey: true, PrimaryKeyPosition: 1},
+				{Path: testutil.ParseDocumentPath(t, "b"), Type: document.IntegerValue, IsPrimaryKey: true},
+			},
+		})
+
+		for _, doc := range []string{
+			`{"a": "bc", "b": 2}`,
+			`{"a": "c", "b": 1}`,
+			`{"a": "b", "b": 2}`,
+			`{"a": "a", "b": 10}`,
+		} {
+			_, err := tb.Insert(testutil.MakeDocument(t, doc))
+			require.NoError(t, err)
+		}
+
+		// same values in another order
+		_, err := tb.Insert(testutil.MakeDocument(t, `{"a": "c", "b": 1.0}`))
+		require.ErrorIs(t, err, errs.ErrDuplicateDocument)
+
+		var keys []string
+		err = tb.Iterate(func(d document.Document) error {
+			k, err := d.(document.Keyer).Key()
+			require.NoError(t, err)
+			keys = append(keys, k.String())
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{`[1, "c"]`, `[2, "b"]`, `[2, "bc"]`, `[10, "a"]`}, keys)
+
+		// seek using a prefix of the key
+		keys = keys[:0]
+		err = tb.AscendGreaterOrEqual(document.NewIntegerValue(2), func(d document.Document) error {
+			k, err := d.(document.Keyer).Key()
+			require.NoError(t, err)
+			keys = append(keys, k.String())
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{`[2, "b"]`, `[2, "bc"]`, `[10, "a"]`}, keys)
 	})
 
 	t.Run("Should convert values into the right types if there are constraints", func(t *testing.T) {
@@ -387,8 +437,8 @@ func TestTableInsert(t *testing.T) {
 		tb := createTable(t, tx, db.Catalog, database.TableInfo{
 			TableName: "test",
 			FieldConstraints: []*database.FieldConstraint{
-				{testutil.ParseDocumentPath(t, "foo"), document.DocumentValue, false, false, false, nil, nil, true, []document.Path{testutil.ParseDocumentPath(t, "foo.bar")}},
-				{testutil.ParseDocumentPath(t, "foo.bar"), document.IntegerValue, false, false, false, nil, nil, true, []document.Path{testutil.ParseDocumentPath(t, "foo")}},
+				{testutil.ParseDocumentPath(t, "foo"), document.DocumentValue, false, false, false, "", "", nil, nil, true, []document.Path{testutil.ParseDocumentPath(t, "foo.bar")}},
+				{testutil.ParseDocumentPath(t, "foo.bar"), document.IntegerValue, false, false, false, "", "", nil, nil, true, []document.Path{testutil.ParseDocumentPath(t, "foo")}},
 			},
 		})
 
@@ -429,7 +479,7 @@ func TestTableInsert(t *testing.T) {
 
 		err := db.Catalog.CreateTable(tx, "test", &database.TableInfo{
 			FieldConstraints: []*database.FieldConstraint{
-				{testutil.ParseDocumentPath(t, "foo"), document.DoubleValue, false, false, false, nil, nil, false, nil},
+				{testutil.ParseDocumentPath(t, "foo"), document.DoubleValue, false, false, false, "", "", nil, nil, false, nil},
 			},
 		})
 		require.NoError(t, err)
@@ -452,7 +502,7 @@ func TestTableInsert(t *testing.T) {
 		tb1 := createTable(t, tx, db.Catalog, database.TableInfo{
 			TableName: "test1",
 			FieldConstraints: []*database.FieldConstraint{
-				{testutil.ParseDocumentPath(t, "foo"), 0, false, true, false, nil, nil, false, nil},
+				{testutil.ParseDocumentPath(t, "foo"), 0, false, true, false, "", "", nil, nil, false, nil},
 			},
 		})
 
@@ -460,7 +510,7 @@ func TestTableInsert(t *testing.T) {
 		tb2 := createTable(t, tx, db.Catalog, database.TableInfo{
 			TableName: "test2",
 			FieldConstraints: []*database.FieldConstraint{
-				{testutil.ParseDocumentPath(t, "foo"), document.IntegerValue, false, true, false, nil, nil, false, nil},
+				{testutil.ParseDocumentPath(t, "foo"), document.IntegerValue, false, true, false, "", "", nil, nil, false, nil},
 			},
 		})
 
@@ -503,7 +553,7 @@ func TestTableInsert(t *testing.T) {
 		tb1 := createTable(t, tx, db.Catalog, database.TableInfo{
 			TableName: "test1",
 			FieldConstraints: []*database.FieldConstraint{
-				{testutil.ParseDocumentPath(t, "foo"), 0, false, true, false, expr.Constraint(testutil.IntegerValue(42)), nil, false, nil},
+				{testutil.ParseDocumentPath(t, "foo"), 0, false, true, false, "", "", expr.Constraint(testutil.IntegerValue(42)), nil, false, nil},
 			},
 		})
 
@@ -511,7 +561,7 @@ func TestTableInsert(t *testing.T) {
 		tb2 := createTable(t, tx, db.Catalog, database.TableInfo{
 			TableName: "test2",
 			FieldConstraints: []*database.FieldConstraint{
-				{testutil.ParseDocumentPath(t, "foo"), document.IntegerValue, false, true, false, expr.Constraint(testutil.IntegerValue(42)), nil, false, nil},
+				{testutil.ParseDocumentPath(t, "foo"), document.IntegerValue, false, true, false, "", "", expr.Constraint(testutil.IntegerValue(42)), nil, false, nil},
 			},
 		})
 
@@ -565,7 +615,7 @@ func TestTableInsert(t *testing.T) {
 		tb := createTable(t, tx, db.Catalog, database.TableInfo{
 			TableName: "test1",
 			FieldConstraints: []*database.FieldConstraint{
-				{testutil.ParseDocumentPath(t, "foo[1]"), 0, false, true, false, nil, nil, false, nil},
+				{testutil.ParseDocumentPath(t, "foo[1]"), 0, false, true, false, "", "", nil, nil, false, nil},
 			},
 		})
 
@@ -586,7 +636,7 @@ func TestTableInsert(t *testing.T) {
 		tb := createTable(t, tx, db.Catalog, database.TableInfo{
 			TableName: "test",
 			FieldConstraints: []*database.FieldConstraint{
-				{testutil.ParseDocumentPath(t, "foo"), 0, true, true, false, nil, nil, false, nil},
+				{testutil.ParseDocumentPath(t, "foo"), 0, true, true, false, "", "", nil, nil, false, nil},
 			}})
 
 		doc := document.NewFieldBuffer().
@@ -598,7 +648,7 @@ func TestTableInsert(t *testing.T) {
 
 		// insert again, should fail
 		_, err = tb.Insert(doc)
-		require.Equal(t, errs.ErrDuplicateDocument, err)
+		require.ErrorIs(t, err, errs.ErrDuplicateDocument)
 	})
 
 	t.Run("Should run the onConflict function if the pk is duplicated", func(t *testing.T) {
@@ -608,7 +658,7 @@ func TestTableInsert(t *testing.T) {
 		tb := createTable(t, tx, db.Catalog, database.TableInfo{
 			TableName: "test",
 			FieldConstraints: []*database.FieldConstraint{
-				{testutil.ParseDocumentPath(t, "foo"), 0, true, true, false, nil, nil, false, nil},
+				{testutil.ParseDocumentPath(t, "foo"), 0, true, true, false, "", "", nil, nil, false, nil},
 			}})
 
 		doc := document.NewFieldBuffer().
@@ -656,7 +706,15 @@ func TestTableInsert(t *testing.T) {
 
 		// insert again, should fail
 		_, err = tb.Insert(doc)
-		require.Equal(t, errs.ErrDuplicateDocument, err)
+		require.ErrorIs(t, err, errs.ErrDuplicateDocument)
+		require.Equal(t, errs.ConstraintViolationError{
+			Constraint: "UNIQUE",
+			TableName:  "test",
+			IndexName:  "idx_test_foo",
+			Path:       document.NewPath("foo"),
+			Value:      document.NewDoubleValue(10),
+		}, err)
+		require.EqualError(t, err, `UNIQUE constraint error: field "foo" of table "test" must be unique, got duplicate 10`)
 	})
 
 	t.Run("Should run the onConflict function if there is a unique constraint violation", func(t *testing.T) {
@@ -702,7 +760,7 @@ func TestTableInsert(t *testing.T) {
 		tb := createTable(t, tx, db.Catalog, database.TableInfo{
 			TableName: "test",
 			FieldConstraints: []*database.FieldConstraint{
-				{testutil.ParseDocumentPath(t, "foo"), 0, false, true, false, nil, nil, false, nil},
+				{testutil.ParseDocumentPath(t, "foo"), 0, false, true, false, "", "", nil, nil, false, nil},
 			},
 		})
 
@@ -727,7 +785,7 @@ func TestTableInsert(t *testing.T) {
 
 		err := db.Catalog.CreateTable(tx, "test", &database.TableInfo{
 			FieldConstraints: []*database.FieldConstraint{
-				{testutil.ParseDocumentPath(t, "foo"), 0, true, true, false, nil, nil, false, nil},
+				{testutil.ParseDocumentPath(t, "foo"), 0, true, true, false, "", "", nil, nil, false, nil},
 			}})
 		require.NoError(t, err)
 
@@ -753,7 +811,7 @@ func TestTableInsert(t *testing.T) {
 
 		err := db.Catalog.CreateTable(tx, "test", &database.TableInfo{
 			FieldConstraints: []*database.FieldConstraint{
-				{testutil.ParseDocumentPath(t, "foo"), 0, false, true, false, nil, nil, false, nil},
+				{testutil.ParseDocumentPath(t, "foo"), 0, false, true, false, "", "", nil, nil, false, nil},
 			}})
 		require.NoError(t, err)
 
@@ -917,7 +975,7 @@ func TestTableReplace(t *testing.T) {
 		_, err = tb.Replace(d1.(document.Keyer).RawKey(), testutil.MakeDocument(t, `{"a": 3, "b": 3}`))
 
 		// index should be the same as before
-		require.Equal(t, errs.ErrDuplicateDocument, err)
+		require.ErrorIs(t, err, errs.ErrDuplicateDocument)
 
 		// --- x, y
 		tb, err = db.Catalog.GetTable(tx, "test2")
@@ -949,11 +1007,107 @@ func TestTableReplace(t *testing.T) {
 		_, err = tb.Replace(dc1.(document.Keyer).RawKey(), testutil.MakeDocument(t, `{"x": 3, "y": 3, "z": 3}`))
 
 		// index should be the same as before
-		require.Equal(t, errs.ErrDuplicateDocument, err)
+		require.ErrorIs(t, err, errs.ErrDuplicateDocument)
+		require.EqualError(t, err, `UNIQUE constraint error: index "idx_foo_x_y" of table "test2" must be unique, got duplicate [3, 3]`)
 	})
 }
 
 // TestTableTruncate verifies Truncate behaviour.
+func TestTableBatchIndexes(t *testing.T) {
+	setup := func(t *testing.T) (*database.Database, *database.Transaction, *database.Table, []document.Document, func()) {
+		db, tx, cleanup := newTestTx(t)
+
+		createTable(t, tx, db.Catalog, database.TableInfo{TableName: "test"})
+
+		err := db.Catalog.CreateIndex(tx, &database.IndexInfo{
+			Paths:     []document.Path{document.NewPath("a")},
+			Unique:    true,
+			TableName: "test",
+			IndexName: "idx_test_a",
+		})
+		require.NoError(t, err)
+
+		tb, err := db.Catalog.GetTable(tx, "test")
+		require.NoError(t, err)
+
+		var docs []document.Document
+		for i := 1; i <= 3; i++ {
+			d, err := tb.Insert(testutil.MakeDocument(t, fmt.Sprintf(`{"a": %d, "b": %d}`, i, i)))
+			require.NoError(t, err)
+			docs = append(docs, d)
+		}
+
+		return db, tx, tb, docs, cleanup
+	}
+
+	t.Run("Should defer index mutations until flush", func(t *testing.T) {
+		db, tx, tb, docs, cleanup := setup(t)
+		defer cleanup()
+
+		before := testutil.GetIndexContent(t, tx, db.Catalog, "idx_test_a")
+
+		tb.BatchIndexes()
+
+		// swap the indexed values of the first two documents
+		_, err := tb.Replace(docs[0].(document.Keyer).RawKey(), testutil.MakeDocument(t, `{"a": 2, "b": 1}`))
+		require.NoError(t, err)
+		_, err = tb.Replace(docs[1].(document.Keyer).RawKey(), testutil.MakeDocument(t, `{"a": 1, "b": 2}`))
+		require.NoError(t, err)
+		// replace the third one without modifying the indexed value
+		_, err = tb.Replace(docs[2].(document.Keyer).RawKey(), testutil.MakeDocument(t, `{"a": 3, "b": 4}`))
+		require.NoError(t, err)
+
+		// nothing must have been written to the index yet
+		require.Equal(t, before, testutil.GetIndexContent(t, tx, db.Catalog, "idx_test_a"))
+
+		err = tb.FlushIndexes()
+		require.NoError(t, err)
+
+		got := testutil.GetIndexContent(t, tx, db.Catalog, "idx_test_a")
+		require.Len(t, got, 3)
+		require.Equal(t, docs[1].(document.Keyer).RawKey(), got[0].Value)
+		require.Equal(t, docs[0].(document.Keyer).RawKey(), got[1].Value)
+		require.Equal(t, docs[2].(document.Keyer).RawKey(), got[2].Value)
+	})
+
+	t.Run("Should remove deleted documents from indexes", func(t *testing.T) {
+		db, tx, tb, docs, cleanup := setup(t)
+		defer cleanup()
+
+		tb.BatchIndexes()
+
+		for _, d := range docs[:2] {
+			err := tb.Delete(d.(document.Keyer).RawKey())
+			require.NoError(t, err)
+		}
+
+		require.Len(t, testutil.GetIndexContent(t, tx, db.Catalog, "idx_test_a"), 3)
+
+		err := tb.FlushIndexes()
+		require.NoError(t, err)
+
+		got := testutil.GetIndexContent(t, tx, db.Catalog, "idx_test_a")
+		require.Len(t, got, 1)
+		require.Equal(t, docs[2].(document.Keyer).RawKey(), got[0].Value)
+	})
+
+	t.Run("Should fail on flush if unique indexes are violated", func(t *testing.T) {
+		_, _, tb, docs, cleanup := setup(t)
+		defer cleanup()
+
+		tb.BatchIndexes()
+
+		_, err := tb.Replace(docs[0].(document.Keyer).RawKey(), testutil.MakeDocument(t, `{"a": 10}`))
+		require.NoError(t, err)
+		_, err = tb.Replace(docs[1].(document.Keyer).RawKey(), testutil.MakeDocument(t, `{"a": 10}`))
+		require.NoError(t, err)
+
+		err = tb.FlushIndexes()
+		require.ErrorIs(t, err, errs.ErrDuplicateDocument)
+		require.EqualError(t, err, `UNIQUE constraint error: field "a" of table "test" must be unique`)
+	})
+}
+
 func TestTableTruncate(t *testing.T) {
 	t.Run("Should succeed if table empty", func(t *testing.T) {
 		tb, cleanup := newTestTable(t)
@@ -987,6 +1141,194 @@ func TestTableTruncate(t *testing.T) {
 	})
 }
 
+func TestTableAttachments(t *testing.T) {
+	big := strings.Repeat("a", 100)
+
+	countAttachments := func(t *testing.T, tb *database.Table) int {
+		st, err := tb.Tx.Tx.GetStore([]byte(database.InternalPrefix + "attachments"))
+		require.NoError(t, err)
+
+		var n int
+		it := st.Iterator(engine.IteratorOptions{})
+		defer it.Close()
+		for it.Seek(nil); it.Valid(); it.Next() {
+			n++
+		}
+		require.NoError(t, it.Err())
+		return n
+	}
+
+	tb, cleanup := newTestTable(t)
+	defer cleanup()
+	tb.Tx.AttachmentThreshold = 10
+
+	d, err := tb.Insert(document.NewFieldBuffer().
+		Add("a", document.NewTextValue(big)).
+		Add("b", document.NewBlobValue([]byte(big))).
+		Add("c", document.NewTextValue("small")))
+	require.NoError(t, err)
+	key := d.(document.Keyer).RawKey()
+
+	// large values are not stored in the document
+	raw, err := tb.Store.Get(key)
+	require.NoError(t, err)
+	require.Less(t, len(raw), 100)
+	require.Equal(t, 2, countAttachments(t, tb))
+
+	// but are loaded transparently
+	d, err = tb.GetDocument(key)
+	require.NoError(t, err)
+	v, err := d.GetByField("a")
+	require.NoError(t, err)
+	require.Equal(t, document.NewTextValue(big), v)
+
+	err = tb.Iterate(func(d document.Document) error {
+		v, err := d.GetByField("b")
+		require.NoError(t, err)
+		require.Equal(t, document.NewBlobValue([]byte(big)), v)
+
+		var fb document.FieldBuffer
+		require.NoError(t, fb.Copy(d))
+		v, err = fb.GetByField("a")
+		require.NoError(t, err)
+		require.Equal(t, document.NewTextValue(big), v)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// replacing the document deletes the attachments that are not needed anymore
+	_, err = tb.Replace(key, document.NewFieldBuffer().
+		Add("a", document.NewTextValue(big)).
+		Add("b", document.NewBlobValue([]byte("small"))))
+	require.NoError(t, err)
+	require.Equal(t, 1, countAttachments(t, tb))
+
+	err = tb.Delete(key)
+	require.NoError(t, err)
+	require.Equal(t, 0, countAttachments(t, tb))
+}
+
+func TestTableChecksum(t *testing.T) {
+	db, tx, cleanup := testutil.NewTestTx(t)
+	defer cleanup()
+
+	tb := createTable(t, tx, db.Catalog, database.TableInfo{TableName: "test", Checksum: true})
+
+	d, err := tb.Insert(newDocument())
+	require.NoError(t, err)
+	key := d.(document.Keyer).RawKey()
+
+	_, err = tb.GetDocument(key)
+	require.NoError(t, err)
+
+	// corrupt the document
+	raw, err := tb.Store.Get(key)
+	require.NoError(t, err)
+	raw = append([]byte{}, raw...)
+	raw[0]++
+	err = tb.Store.Put(key, raw)
+	require.NoError(t, err)
+
+	_, err = tb.GetDocument(key)
+	require.True(t, errs.IsCorruptedDocumentError(err))
+
+	err = tb.Iterate(func(d document.Document) error {
+		_, err := d.GetByField("fielda")
+		return err
+	})
+	require.Equal(t, errs.CorruptedDocumentError{TableName: "test", Key: key}, err)
+}
+
+func TestTableDocidStrategies(t *testing.T) {
+	tests := []struct {
+		strategy string
+		keyType  document.ValueType
+	}{
+		{database.DocidSequence, document.IntegerValue},
+		{database.DocidRandom, document.IntegerValue},
+		{database.DocidULID, document.TextValue},
+		{database.DocidSnowflake, document.IntegerValue},
+	}
+
+	for _, test := range tests {
+		t.Run(test.strategy, func(t *testing.T) {
+			db, tx, cleanup := testutil.NewTestTx(t)
+			defer cleanup()
+
+			tb := createTable(t, tx, db.Catalog, database.TableInfo{TableName: "test", DocidStrategy: test.strategy})
+
+			keys := make(map[string]bool)
+			for i := 0; i < 100; i++ {
+				d, err := tb.Insert(newDocument())
+				require.NoError(t, err)
+
+				k, err := d.(document.Keyer).Key()
+				require.NoError(t, err)
+				require.Equal(t, test.keyType, k.Type)
+
+				// the key can be converted back to the raw key
+				raw, err := tb.EncodeValue(k)
+				require.NoError(t, err)
+				require.Equal(t, d.(document.Keyer).RawKey(), raw)
+				keys[string(raw)] = true
+
+				_, err = tb.GetDocument(raw)
+				require.NoError(t, err)
+			}
+			require.Len(t, keys, 100)
+
+			err := tb.Iterate(func(d document.Document) error {
+				k, err := d.(document.Keyer).Key()
+				require.NoError(t, err)
+				require.Equal(t, test.keyType, k.Type)
+				return nil
+			})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestTableFieldOrder(t *testing.T) {
+	doc := `{"z": 1, "a": {"y": 2, "b": 3}, "m": [{"x": 4, "c": 5}]}`
+
+	tests := []struct {
+		order    string
+		expected string
+	}{
+		{"", doc},
+		{database.FieldOrderInsertion, doc},
+		{database.FieldOrderCanonical, `{"a": {"b": 3, "y": 2}, "m": [{"c": 5, "x": 4}], "z": 1}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.order, func(t *testing.T) {
+			db, tx, cleanup := testutil.NewTestTx(t)
+			defer cleanup()
+
+			tb := createTable(t, tx, db.Catalog, database.TableInfo{TableName: "test", FieldOrder: test.order})
+
+			d, err := tb.Insert(document.NewFromJSON([]byte(doc)))
+			require.NoError(t, err)
+			key := d.(document.Keyer).RawKey()
+
+			got, err := tb.GetDocument(key)
+			require.NoError(t, err)
+			data, err := document.MarshalJSON(got)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, string(data))
+
+			_, err = tb.Replace(key, document.NewFromJSON([]byte(doc)))
+			require.NoError(t, err)
+
+			got, err = tb.GetDocument(key)
+			require.NoError(t, err)
+			data, err = document.MarshalJSON(got)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, string(data))
+		})
+	}
+}
+
 func TestTableIndexes(t *testing.T) {
 	t.Run("Should succeed if table has no indexes", func(t *testing.T) {
 		tb, cleanup := newTestTable(t)
@@ -0,0 +1,357 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"sync/atomic"
+
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/internal/stringutil"
+	"github.com/google/btree"
+)
+
+const (
+	// tempStorePrefix is the prefix of the name of the temporary stores.
+	tempStorePrefix = InternalPrefix + "temp_"
+
+	// tempStoresRegistry is the name of the store listing the temporary stores
+	// of a temp engine, so that the ones orphaned by a crash can be dropped.
+	tempStoresRegistry = InternalPrefix + "temp_stores"
+
+	// degree of the btrees of temporary stores.
+	tempStoreBtreeDegree = 12
+
+	// estimated memory used by an item of a temporary store,
+	// in addition to its key and value, in bytes.
+	tempItemSize = 48
+)
+
+// tempStorage creates and drops temporary stores in an engine.
+type tempStorage struct {
+	ng  engine.Engine
+	seq uint64
+}
+
+// newTempStorage drops the temporary stores orphaned in ng,
+// if any, and returns a storage using it.
+func newTempStorage(ng engine.Engine) (*tempStorage, error) {
+	err := dropOrphanedTempStores(ng)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tempStorage{ng: ng}, nil
+}
+
+// dropOrphanedTempStores drops the stores listed in the registry.
+// Stores are listed until they are dropped, so any store still listed
+// when the engine is opened was orphaned by a crash.
+func dropOrphanedTempStores(ng engine.Engine) error {
+	tx, err := ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	registry, err := tx.GetStore([]byte(tempStoresRegistry))
+	if err == engine.ErrStoreNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var names [][]byte
+	it := registry.Iterator(engine.IteratorOptions{})
+	for it.Seek(nil); it.Valid(); it.Next() {
+		names = append(names, append([]byte{}, it.Item().Key()...))
+	}
+	err = it.Err()
+	if cerr := it.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		err = tx.DropStore(name)
+		if err != nil && err != engine.ErrStoreNotFound {
+			return err
+		}
+	}
+
+	err = registry.Truncate()
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// create a store with a unique name and register it.
+func (ts *tempStorage) create(tx engine.Transaction) ([]byte, error) {
+	name := []byte(stringutil.Sprintf("%s%d", tempStorePrefix, atomic.AddUint64(&ts.seq, 1)))
+
+	registry, err := tx.GetStore([]byte(tempStoresRegistry))
+	if err == engine.ErrStoreNotFound {
+		err = tx.CreateStore([]byte(tempStoresRegistry))
+		if err != nil {
+			return nil, err
+		}
+		registry, err = tx.GetStore([]byte(tempStoresRegistry))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = registry.Put(name, []byte{0})
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.CreateStore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return name, nil
+}
+
+// drop the store and unregister it.
+func (ts *tempStorage) drop(name []byte) error {
+	tx, err := ts.ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.DropStore(name)
+	if err != nil {
+		return err
+	}
+
+	registry, err := tx.GetStore([]byte(tempStoresRegistry))
+	if err != nil {
+		return err
+	}
+
+	err = registry.Delete(name)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// A TempStore stores intermediate results of a statement, like sorted
+// or deduplicated documents. Key-value pairs are kept in memory until the
+// memory budget is exhausted, then they are written to a store of the
+// temp engine, which is dropped when the TempStore is closed.
+// A TempStore is not safe for concurrent use.
+type TempStore struct {
+	tracker *ResourceTracker
+	storage *tempStorage
+
+	mem *btree.BTree
+	// memory accounted in the tracker for the items of mem.
+	size int64
+
+	// name of the engine store, once data has been spilled.
+	name   []byte
+	closed bool
+}
+
+type tempItem struct {
+	k, v []byte
+}
+
+func (i *tempItem) Less(than btree.Item) bool {
+	return bytes.Compare(i.k, than.(*tempItem).k) < 0
+}
+
+func (i *tempItem) size() int64 {
+	return int64(len(i.k) + len(i.v) + tempItemSize)
+}
+
+// Put stores a key-value pair. If the key already exists, its value is replaced.
+// k and v are copied and must be non empty.
+func (s *TempStore) Put(k, v []byte) error {
+	it := tempItem{
+		k: append([]byte{}, k...),
+		v: append([]byte{}, v...),
+	}
+
+	n := it.size()
+	ok, err := s.tracker.TryGrow(n)
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		s.size += n
+	}
+	// items that are not accounted for are flushed right away,
+	// so the replaced item is always accounted for
+	if old := s.mem.ReplaceOrInsert(&it); old != nil {
+		s.size -= old.(*tempItem).size()
+		s.tracker.Shrink(old.(*tempItem).size())
+	}
+
+	// the memory budget is exhausted, write everything to the engine
+	if !ok {
+		return s.flush()
+	}
+
+	return nil
+}
+
+// Get returns the value associated with the key.
+// If the key is not found, it returns engine.ErrKeyNotFound.
+func (s *TempStore) Get(k []byte) ([]byte, error) {
+	if it := s.mem.Get(&tempItem{k: k}); it != nil {
+		return it.(*tempItem).v, nil
+	}
+
+	if s.name == nil {
+		return nil, engine.ErrKeyNotFound
+	}
+
+	tx, err := s.storage.ng.Begin(context.Background(), engine.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	st, err := tx.GetStore(s.name)
+	if err != nil {
+		return nil, err
+	}
+
+	return st.Get(k)
+}
+
+// Iterate over the key-value pairs of the store, in order.
+// k and v are only valid until fn returns.
+func (s *TempStore) Iterate(reverse bool, fn func(k, v []byte) error) error {
+	if s.name == nil {
+		var err error
+		iter := func(i btree.Item) bool {
+			it := i.(*tempItem)
+			err = fn(it.k, it.v)
+			return err == nil
+		}
+
+		if reverse {
+			s.mem.Descend(iter)
+		} else {
+			s.mem.Ascend(iter)
+		}
+
+		return err
+	}
+
+	// part of the data was spilled, write the rest
+	// to iterate over the engine store only
+	err := s.flush()
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.storage.ng.Begin(context.Background(), engine.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	st, err := tx.GetStore(s.name)
+	if err != nil {
+		return err
+	}
+
+	it := st.Iterator(engine.IteratorOptions{Reverse: reverse})
+	defer it.Close()
+
+	var v []byte
+	for it.Seek(nil); it.Valid(); it.Next() {
+		item := it.Item()
+		v, err = item.ValueCopy(v[:0])
+		if err != nil {
+			return err
+		}
+
+		err = fn(item.Key(), v)
+		if err != nil {
+			return err
+		}
+	}
+
+	return it.Err()
+}
+
+// flush writes the items kept in memory to the engine store
+// in a single transaction and releases their memory.
+func (s *TempStore) flush() error {
+	if s.mem.Len() == 0 {
+		return nil
+	}
+
+	tx, err := s.storage.ng.Begin(context.Background(), engine.TxOptions{Writable: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	name := s.name
+	if name == nil {
+		name, err = s.storage.create(tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	st, err := tx.GetStore(name)
+	if err != nil {
+		return err
+	}
+
+	s.mem.Ascend(func(i btree.Item) bool {
+		it := i.(*tempItem)
+		err = st.Put(it.k, it.v)
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	s.name = name
+	s.mem.Clear(false)
+	s.tracker.Shrink(s.size)
+	s.size = 0
+	return nil
+}
+
+// Close releases the memory used by the store and drops
+// its engine store, if any.
+// Closing a store more than once has no effect.
+func (s *TempStore) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	s.mem.Clear(false)
+	s.tracker.Shrink(s.size)
+	s.size = 0
+
+	if s.name == nil {
+		return nil
+	}
+
+	return s.storage.drop(s.name)
+}
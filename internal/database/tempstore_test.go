@@ -0,0 +1,131 @@
+package database_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/genjidb/genji/document/encoding/msgpack"
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/engine/memoryengine"
+	"github.com/genjidb/genji/internal/catalog"
+	"github.com/genjidb/genji/internal/database"
+	"github.com/stretchr/testify/require"
+)
+
+func newTempStoreTestDB(t *testing.T, budget int64, tempEngine engine.Engine) *database.Database {
+	t.Helper()
+
+	db, err := database.New(context.Background(), memoryengine.NewEngine(), database.Options{
+		Codec:        msgpack.NewCodec(),
+		Catalog:      catalog.New(),
+		MemoryBudget: budget,
+		TempEngine:   tempEngine,
+	})
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestTempStore(t *testing.T) {
+	fill := func(t *testing.T, st *database.TempStore) {
+		for i := 0; i < 100; i++ {
+			k := []byte(strconv.Itoa(i % 50))
+			require.NoError(t, st.Put(k, []byte(strconv.Itoa(i))))
+		}
+	}
+
+	check := func(t *testing.T, st *database.TempStore) {
+		v, err := st.Get([]byte("42"))
+		require.NoError(t, err)
+		require.Equal(t, "92", string(v))
+
+		_, err = st.Get([]byte("foo"))
+		require.Equal(t, engine.ErrKeyNotFound, err)
+
+		var keys []string
+		err = st.Iterate(false, func(k, v []byte) error {
+			keys = append(keys, string(k))
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, keys, 50)
+		require.Equal(t, "0", keys[0])
+		require.Equal(t, "9", keys[49])
+
+		var last string
+		err = st.Iterate(true, func(k, v []byte) error {
+			last = string(k)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, "0", last)
+	}
+
+	t.Run("Memory", func(t *testing.T) {
+		db := newTempStoreTestDB(t, 0, nil)
+		defer db.Close()
+
+		tr := db.NewResourceTracker()
+		st := tr.NewTempStore()
+		fill(t, st)
+		check(t, st)
+		require.NoError(t, tr.Close())
+	})
+
+	t.Run("Nil tracker", func(t *testing.T) {
+		var tr *database.ResourceTracker
+		st := tr.NewTempStore()
+		fill(t, st)
+		check(t, st)
+		require.NoError(t, st.Close())
+	})
+
+	t.Run("Spill", func(t *testing.T) {
+		ng := memoryengine.NewEngine()
+		db := newTempStoreTestDB(t, 500, ng)
+		defer db.Close()
+
+		tr := db.NewResourceTracker()
+		st := tr.NewTempStore()
+		fill(t, st)
+		require.LessOrEqual(t, db.Memory().Used(), int64(500))
+		check(t, st)
+
+		// the spilled data lives in the temp engine until the statement ends
+		require.True(t, storeExists(t, ng, "__genji_temp_1"))
+		require.NoError(t, tr.Close())
+		require.False(t, storeExists(t, ng, "__genji_temp_1"))
+		require.Zero(t, db.Memory().Used())
+	})
+
+	t.Run("Orphaned stores", func(t *testing.T) {
+		ng := memoryengine.NewEngine()
+		db := newTempStoreTestDB(t, 500, ng)
+
+		// simulate a crash by never closing the tracker
+		tr := db.NewResourceTracker()
+		fill(t, tr.NewTempStore())
+		require.True(t, storeExists(t, ng, "__genji_temp_1"))
+		db.Close()
+
+		db = newTempStoreTestDB(t, 500, ng)
+		defer db.Close()
+		require.False(t, storeExists(t, ng, "__genji_temp_1"))
+	})
+}
+
+func storeExists(t *testing.T, ng engine.Engine, name string) bool {
+	t.Helper()
+
+	tx, err := ng.Begin(context.Background(), engine.TxOptions{})
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	_, err = tx.GetStore([]byte(name))
+	if err == engine.ErrStoreNotFound {
+		return false
+	}
+	require.NoError(t, err)
+	return true
+}
@@ -0,0 +1,203 @@
+package database
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"strings"
+
+	"github.com/genjidb/genji/engine"
+)
+
+type tenantContextKey struct{}
+
+// WithTenant returns a copy of ctx associated with the given tenant.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant associated with ctx, if any.
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantContextKey{}).(string)
+	return tenant
+}
+
+// NewTenantEngine returns an engine isolating the data of several tenants stored in ng.
+// The keys of the stores of the tables and indexes are transparently prefixed with
+// the tenant associated with the context of the transaction, and transactions only
+// see the keys of their tenant. Transactions whose context has no tenant use a namespace
+// of their own.
+// Internal stores, like the catalog and the sequences, are shared by every tenant:
+// the tables and indexes are the same for all of them, only their content differs.
+func NewTenantEngine(ng engine.Engine) engine.Engine {
+	return &tenantEngine{ng: ng}
+}
+
+type tenantEngine struct {
+	ng engine.Engine
+}
+
+func (e *tenantEngine) Begin(ctx context.Context, opts engine.TxOptions) (engine.Transaction, error) {
+	tx, err := e.ng.Begin(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tenantTx{Transaction: tx, prefix: tenantPrefix(TenantFromContext(ctx)), writable: opts.Writable}, nil
+}
+
+// Isolation returns the isolation level of the wrapped engine.
+// It implements the engine.IsolationReporter interface.
+func (e *tenantEngine) Isolation() string {
+	return engine.Isolation(e.ng)
+}
+
+// SetSynchronous sets the synchronous mode of the wrapped engine.
+// It implements the engine.Synchronizer interface.
+func (e *tenantEngine) SetSynchronous(mode string) error {
+	return engine.SetSynchronous(e.ng, mode)
+}
+
+// RunGC runs the garbage collection of the wrapped engine.
+// It implements the engine.GarbageCollector interface.
+func (e *tenantEngine) RunGC() error {
+	return engine.RunGC(e.ng)
+}
+
+// GCStats returns the garbage collection statistics of the wrapped engine.
+// It implements the engine.GarbageCollector interface.
+func (e *tenantEngine) GCStats() (engine.GCStats, error) {
+	return engine.ReadGCStats(e.ng)
+}
+
+func (e *tenantEngine) Close() error {
+	return e.ng.Close()
+}
+
+// tenantPrefix returns the prefix of the keys of the tenant.
+// The tenant is preceded by its length, so that no prefix is
+// the prefix of the keys of another tenant.
+func tenantPrefix(tenant string) []byte {
+	buf := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(tenant))
+	n := binary.PutUvarint(buf, uint64(len(tenant)))
+	return append(buf[:n], tenant...)
+}
+
+// tenantScoped returns whether the keys of the given store belong to tenants.
+// The attachment store holds parts of the documents, it is the only internal
+// store that isn't shared.
+func tenantScoped(name []byte) bool {
+	return !strings.HasPrefix(string(name), InternalPrefix) || string(name) == attachmentStoreName
+}
+
+type tenantTx struct {
+	engine.Transaction
+
+	prefix   []byte
+	writable bool
+}
+
+func (t *tenantTx) GetStore(name []byte) (engine.Store, error) {
+	st, err := t.Transaction.GetStore(name)
+	if err != nil || !tenantScoped(name) {
+		return st, err
+	}
+
+	return &tenantStore{st: st, prefix: t.prefix, writable: t.writable}, nil
+}
+
+type tenantStore struct {
+	st       engine.Store
+	prefix   []byte
+	writable bool
+}
+
+func (s *tenantStore) key(k []byte) []byte {
+	key := make([]byte, 0, len(s.prefix)+len(k))
+	key = append(key, s.prefix...)
+	return append(key, k...)
+}
+
+func (s *tenantStore) Get(k []byte) ([]byte, error) {
+	return s.st.Get(s.key(k))
+}
+
+func (s *tenantStore) Put(k, v []byte) error {
+	// the prefix alone would be a valid key
+	if len(k) == 0 {
+		return errors.New("empty keys are forbidden")
+	}
+
+	return s.st.Put(s.key(k), v)
+}
+
+func (s *tenantStore) Delete(k []byte) error {
+	return s.st.Delete(s.key(k))
+}
+
+// Truncate deletes the keys of the tenant, leaving the ones
+// of the other tenants untouched.
+func (s *tenantStore) Truncate() error {
+	if !s.writable {
+		return engine.ErrTransactionReadOnly
+	}
+
+	it := s.st.Iterator(engine.IteratorOptions{Prefix: s.prefix, KeysOnly: true})
+	defer it.Close()
+
+	for it.Seek(nil); it.Valid(); it.Next() {
+		err := s.st.Delete(append([]byte{}, it.Item().Key()...))
+		if err != nil {
+			return err
+		}
+	}
+
+	return it.Err()
+}
+
+func (s *tenantStore) Iterator(opts engine.IteratorOptions) engine.Iterator {
+	o := engine.IteratorOptions{
+		Reverse:  opts.Reverse,
+		Prefix:   s.key(opts.Prefix),
+		KeysOnly: opts.KeysOnly,
+	}
+	if opts.LowerBound != nil {
+		o.LowerBound = s.key(opts.LowerBound)
+	}
+	if opts.UpperBound != nil {
+		o.UpperBound = s.key(opts.UpperBound)
+	}
+
+	return &tenantIterator{Iterator: s.st.Iterator(o), store: s}
+}
+
+type tenantIterator struct {
+	engine.Iterator
+
+	store *tenantStore
+}
+
+func (it *tenantIterator) Seek(pivot []byte) {
+	// an empty pivot starts the iteration at either end of the keys of the tenant,
+	// which the wrapped iterator determines using the prefix of the options.
+	if len(pivot) == 0 {
+		it.Iterator.Seek(nil)
+		return
+	}
+
+	it.Iterator.Seek(it.store.key(pivot))
+}
+
+func (it *tenantIterator) Item() engine.Item {
+	return tenantItem{Item: it.Iterator.Item(), n: len(it.store.prefix)}
+}
+
+type tenantItem struct {
+	engine.Item
+
+	n int
+}
+
+func (i tenantItem) Key() []byte {
+	return i.Item.Key()[i.n:]
+}
@@ -0,0 +1,91 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/engine/enginetest"
+	"github.com/genjidb/genji/engine/memoryengine"
+	"github.com/genjidb/genji/internal/database"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantEngine(t *testing.T) {
+	enginetest.TestSuite(t, func() (engine.Engine, func()) {
+		ng := database.NewTenantEngine(memoryengine.NewEngine())
+		return ng, func() { ng.Close() }
+	})
+}
+
+func TestTenantEngineIsolation(t *testing.T) {
+	ng := database.NewTenantEngine(memoryengine.NewEngine())
+	defer ng.Close()
+
+	// runs fn in a transaction of the given tenant
+	run := func(tenant string, fn func(st engine.Store)) {
+		tx, err := ng.Begin(database.WithTenant(context.Background(), tenant), engine.TxOptions{Writable: true})
+		require.NoError(t, err)
+		defer tx.Rollback()
+
+		st, err := tx.GetStore([]byte("test"))
+		if err == engine.ErrStoreNotFound {
+			require.NoError(t, tx.CreateStore([]byte("test")))
+			st, err = tx.GetStore([]byte("test"))
+		}
+		require.NoError(t, err)
+
+		fn(st)
+		require.NoError(t, tx.Commit())
+	}
+
+	keys := func(st engine.Store, opts engine.IteratorOptions) []string {
+		var keys []string
+		it := st.Iterator(opts)
+		defer it.Close()
+		for it.Seek(nil); it.Valid(); it.Next() {
+			keys = append(keys, string(it.Item().Key()))
+		}
+		require.NoError(t, it.Err())
+		return keys
+	}
+
+	run("a", func(st engine.Store) {
+		require.NoError(t, st.Put([]byte("k1"), []byte("a1")))
+		require.NoError(t, st.Put([]byte("k2"), []byte("a2")))
+	})
+	run("ab", func(st engine.Store) {
+		require.NoError(t, st.Put([]byte("k1"), []byte("ab1")))
+	})
+	run("", func(st engine.Store) {
+		require.NoError(t, st.Put([]byte("k3"), []byte("3")))
+	})
+
+	run("a", func(st engine.Store) {
+		v, err := st.Get([]byte("k1"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("a1"), v)
+
+		require.Equal(t, []string{"k1", "k2"}, keys(st, engine.IteratorOptions{}))
+		require.Equal(t, []string{"k2", "k1"}, keys(st, engine.IteratorOptions{Reverse: true}))
+		require.Equal(t, []string{"k2"}, keys(st, engine.IteratorOptions{LowerBound: []byte("k2")}))
+		require.Equal(t, []string{"k1"}, keys(st, engine.IteratorOptions{Reverse: true, UpperBound: []byte("k2")}))
+
+		_, err = st.Get([]byte("k3"))
+		require.Equal(t, engine.ErrKeyNotFound, err)
+	})
+
+	run("ab", func(st engine.Store) {
+		require.Equal(t, []string{"k1"}, keys(st, engine.IteratorOptions{}))
+		require.NoError(t, st.Truncate())
+		require.Empty(t, keys(st, engine.IteratorOptions{}))
+	})
+
+	// truncating the store of a tenant doesn't affect the others
+	run("a", func(st engine.Store) {
+		require.Equal(t, []string{"k1", "k2"}, keys(st, engine.IteratorOptions{}))
+	})
+	run("", func(st engine.Store) {
+		require.Equal(t, []string{"k3"}, keys(st, engine.IteratorOptions{}))
+	})
+}
@@ -0,0 +1,177 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ThrottleStatsTableName is the name of the virtual table
+// reporting the state of the throttle of the database.
+const ThrottleStatsTableName = InternalPrefix + "throttle_stats"
+
+// A Throttle limits the number of statements run concurrently and the
+// rate at which write statements are run, to protect hosts with few resources
+// from bursty workloads. Statements exceeding the limits wait for their turn.
+// It is safe for concurrent use. A zero limit is disabled.
+type Throttle struct {
+	maxConcurrentStatements int
+	maxWritesPerSecond      int
+
+	// one token per running statement, nil if there is no limit.
+	slots chan struct{}
+
+	mu    sync.Mutex
+	stats ThrottleStats
+	// earliest time at which the next write can be run without being delayed,
+	// minus the burst allowed.
+	nextWrite time.Time
+}
+
+// ThrottleStats describes the state of a Throttle.
+type ThrottleStats struct {
+	MaxConcurrentStatements int
+	MaxWritesPerSecond      int
+	// Number of statements running and waiting for a slot.
+	RunningStatements int64
+	WaitingStatements int64
+	// Number of write statements delayed because of the rate limit.
+	WaitingWrites int64
+	// Total number of statements and write statements that had to wait.
+	ThrottledStatements int64
+	ThrottledWrites     int64
+}
+
+// NewThrottle creates a throttle allowing at most maxConcurrentStatements
+// statements to run at the same time and maxWritesPerSecond write statements
+// to start every second.
+func NewThrottle(maxConcurrentStatements, maxWritesPerSecond int) *Throttle {
+	t := Throttle{
+		maxConcurrentStatements: maxConcurrentStatements,
+		maxWritesPerSecond:      maxWritesPerSecond,
+	}
+	t.stats.MaxConcurrentStatements = maxConcurrentStatements
+	t.stats.MaxWritesPerSecond = maxWritesPerSecond
+
+	if maxConcurrentStatements > 0 {
+		t.slots = make(chan struct{}, maxConcurrentStatements)
+	}
+
+	return &t
+}
+
+// Stats returns the current state of the throttle.
+func (t *Throttle) Stats() ThrottleStats {
+	if t == nil {
+		return ThrottleStats{}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.stats
+}
+
+// AcquireStatement waits until a statement can be run, or until ctx is canceled.
+// If it returns no error, ReleaseStatement must be called once the statement is done.
+func (t *Throttle) AcquireStatement(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+
+	if t.slots == nil {
+		t.update(func(s *ThrottleStats) { s.RunningStatements++ })
+		return nil
+	}
+
+	select {
+	case t.slots <- struct{}{}:
+		t.update(func(s *ThrottleStats) { s.RunningStatements++ })
+		return nil
+	default:
+	}
+
+	t.update(func(s *ThrottleStats) {
+		s.WaitingStatements++
+		s.ThrottledStatements++
+	})
+
+	select {
+	case t.slots <- struct{}{}:
+		t.update(func(s *ThrottleStats) {
+			s.WaitingStatements--
+			s.RunningStatements++
+		})
+		return nil
+	case <-ctx.Done():
+		t.update(func(s *ThrottleStats) { s.WaitingStatements-- })
+		return ctx.Err()
+	}
+}
+
+// ReleaseStatement frees the slot taken by AcquireStatement.
+func (t *Throttle) ReleaseStatement() {
+	if t == nil {
+		return
+	}
+
+	if t.slots != nil {
+		<-t.slots
+	}
+	t.update(func(s *ThrottleStats) { s.RunningStatements-- })
+}
+
+// WaitWrite delays the caller until a write statement can be run without exceeding
+// the rate limit, or until ctx is canceled. Bursts of up to one second worth of writes
+// are run without delay.
+func (t *Throttle) WaitWrite(ctx context.Context) error {
+	if t == nil || t.maxWritesPerSecond <= 0 {
+		return nil
+	}
+
+	interval := time.Second / time.Duration(t.maxWritesPerSecond)
+
+	t.mu.Lock()
+	now := time.Now()
+	if t.nextWrite.Before(now) {
+		t.nextWrite = now
+	}
+	wait := t.nextWrite.Sub(now) - time.Second + interval
+	t.nextWrite = t.nextWrite.Add(interval)
+	if wait > 0 {
+		t.stats.WaitingWrites++
+		t.stats.ThrottledWrites++
+	}
+	t.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		t.update(func(s *ThrottleStats) { s.WaitingWrites-- })
+		return nil
+	case <-ctx.Done():
+		// give the turn back to the next writers
+		t.mu.Lock()
+		t.nextWrite = t.nextWrite.Add(-interval)
+		t.stats.WaitingWrites--
+		t.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (t *Throttle) update(fn func(s *ThrottleStats)) {
+	t.mu.Lock()
+	fn(&t.stats)
+	t.mu.Unlock()
+}
+
+// Throttle returns the throttle limiting the statements run by the database.
+func (db *Database) Throttle() *Throttle {
+	return db.throttle
+}
@@ -1,10 +1,13 @@
 package database
 
 import (
+	"io"
 	"sync"
+	"time"
 
 	"github.com/genjidb/genji/document/encoding"
 	"github.com/genjidb/genji/engine"
+	errs "github.com/genjidb/genji/errors"
 )
 
 // Transaction represents a database transaction. It provides methods for managing the
@@ -16,6 +19,48 @@ type Transaction struct {
 	Writable bool
 	DBMu     *sync.RWMutex
 	Codec    encoding.Codec
+	// Size in bytes above which top-level blob and text values
+	// are stored out of row. Zero disables it.
+	AttachmentThreshold int
+	// Time at which the transaction was started.
+	StartedAt time.Time
+	// True if the transaction is attached to the database, i.e. started with BEGIN.
+	Attached bool
+	// Isolation level of the transactions of the engine, as reported by engine.Isolation.
+	EngineIsolation string
+	// User associated with the context of the transaction, if any.
+	// It is recorded in the audit entries written by the transaction.
+	User string
+	// Provider of the keys used by the encrypt and decrypt functions, if any.
+	KeyProvider KeyProvider
+	// Throttle of the database, used to report its state.
+	Throttle *Throttle
+	// History of the plans chosen for the statements, if any.
+	Plans *PlanHistory
+	// Clock and source of random bytes of the database, if any.
+	// Use the Now and Random methods to read them.
+	Clock Clock
+	Rand  io.Reader
+	// If true, the queries run by the transaction only see
+	// the masked values of the fields having a mask.
+	Unprivileged bool
+
+	// If true, statements run in explicit transactions that fail
+	// only undo their own changes, using a savepoint.
+	AtomicStatements bool
+
+	// If true, comparing values of incompatible types fails and NULL
+	// follows three-valued logic. See Options.Strict.
+	Strict bool
+
+	// rolls back the transaction if it exceeds its timeouts, if any.
+	watchdog *txWatchdog
+	// records the changes made since the last call to Savepoint, if any.
+	savepoint *savepoint
+	// generates the snowflake docids, if set.
+	snowflakes *snowflakeGenerator
+	// number of documents inserted, replaced or deleted.
+	documentWrites int64
 
 	// these functions are run after a successful rollback.
 	OnRollbackHooks []func()
@@ -25,34 +70,49 @@ type Transaction struct {
 
 // Rollback the transaction. Can be used safely after commit.
 func (tx *Transaction) Rollback() error {
-	err := tx.Tx.Rollback()
-	if err != nil {
-		return err
+	if tx.watchdog != nil {
+		tx.watchdog.stop()
 	}
 
-	defer func() {
-		if tx.Writable {
-			tx.DBMu.Unlock()
-		} else {
-			tx.DBMu.RUnlock()
-		}
-	}()
-
-	for i := len(tx.OnRollbackHooks) - 1; i >= 0; i-- {
-		tx.OnRollbackHooks[i]()
+	err := tx.Tx.Rollback()
+	if err == engine.ErrTransactionDiscarded {
+		return err
 	}
 
-	return nil
+	// engines report context errors after rolling back,
+	// the database must be released anyway.
+	tx.release(tx.OnRollbackHooks)
+	return err
 }
 
 // Commit the transaction. Calling this method on read-only transactions
 // will return an error.
+// If the commit fails, the transaction is rolled back.
+// If the transaction was rolled back because of a timeout, it returns errs.ErrTransactionTimeout.
 func (tx *Transaction) Commit() error {
+	if tx.watchdog != nil && tx.watchdog.stop() {
+		return errs.ErrTransactionTimeout
+	}
+
 	err := tx.Tx.Commit()
+	if err == engine.ErrTransactionDiscarded {
+		return err
+	}
 	if err != nil {
+		// the engine transaction cannot be used anymore, roll it back
+		// to release the database. Subsequent calls to Rollback will
+		// return engine.ErrTransactionDiscarded.
+		_ = tx.Tx.Rollback()
+		tx.release(tx.OnRollbackHooks)
 		return err
 	}
 
+	tx.release(tx.OnCommitHooks)
+	return nil
+}
+
+// release unlocks the database and runs the given hooks.
+func (tx *Transaction) release(hooks []func()) {
 	defer func() {
 		if tx.Writable {
 			tx.DBMu.Unlock()
@@ -61,9 +121,41 @@ func (tx *Transaction) Commit() error {
 		}
 	}()
 
-	for i := len(tx.OnCommitHooks) - 1; i >= 0; i-- {
-		tx.OnCommitHooks[i]()
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i]()
 	}
+}
 
-	return nil
+// TxStatus describes the state of a transaction.
+type TxStatus struct {
+	Writable bool
+	// True if the transaction was started with BEGIN.
+	Attached bool
+	// Isolation level guaranteed by the database. Writable transactions
+	// never run concurrently with other transactions, which makes
+	// transactions serializable, whatever the engine.
+	Isolation string
+	// Isolation level of the transactions of the engine.
+	EngineIsolation string
+	StartedAt       time.Time
+	// Number of documents inserted, replaced or deleted by the transaction
+	// that are not committed yet.
+	PendingWrites int64
+}
+
+// Status returns the state of the transaction.
+func (tx *Transaction) Status() TxStatus {
+	isolation := tx.EngineIsolation
+	if isolation == "" {
+		isolation = engine.IsolationUnknown
+	}
+
+	return TxStatus{
+		Writable:        tx.Writable,
+		Attached:        tx.Attached,
+		Isolation:       engine.IsolationSerializable,
+		EngineIsolation: isolation,
+		StartedAt:       tx.StartedAt,
+		PendingWrites:   tx.documentWrites,
+	}
 }
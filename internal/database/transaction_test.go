@@ -1,11 +1,16 @@
 package database_test
 
 import (
+	"bytes"
 	"context"
+	"log"
 	"testing"
+	"time"
 
 	"github.com/genjidb/genji/document/encoding/msgpack"
+	"github.com/genjidb/genji/engine"
 	"github.com/genjidb/genji/engine/memoryengine"
+	errs "github.com/genjidb/genji/errors"
 	"github.com/genjidb/genji/internal/catalog"
 	"github.com/genjidb/genji/internal/database"
 	"github.com/stretchr/testify/require"
@@ -38,3 +43,70 @@ func newTestTx(t testing.TB) (*database.Database, *database.Transaction, func())
 		cleanup()
 	}
 }
+
+func TestTransactionTimeout(t *testing.T) {
+	newDB := func(t *testing.T, lifetime, idle time.Duration) (*database.Database, *bytes.Buffer) {
+		var buf bytes.Buffer
+
+		db, err := database.New(context.Background(), memoryengine.NewEngine(), database.Options{
+			Codec:         msgpack.NewCodec(),
+			Catalog:       catalog.New(),
+			TxTimeout:     lifetime,
+			TxIdleTimeout: idle,
+			Logger:        log.New(&buf, "", 0),
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		return db, &buf
+	}
+
+	t.Run("idle", func(t *testing.T) {
+		db, buf := newDB(t, 0, 10*time.Millisecond)
+
+		tx, err := db.Begin(true)
+		require.NoError(t, err)
+		require.NoError(t, tx.Enter("INSERT INTO foo (a) VALUES (1)"))
+		tx.Leave()
+
+		time.Sleep(50 * time.Millisecond)
+
+		require.Equal(t, errs.ErrTransactionTimeout, tx.Enter(""))
+		require.Equal(t, errs.ErrTransactionTimeout, tx.Commit())
+		require.Contains(t, buf.String(), "idle for more than 10ms")
+		require.Contains(t, buf.String(), "INSERT INTO foo (a) VALUES (1)")
+
+		// the database must have been released
+		tx, err = db.Begin(true)
+		require.NoError(t, err)
+		require.NoError(t, tx.Rollback())
+	})
+
+	t.Run("lifetime", func(t *testing.T) {
+		db, buf := newDB(t, 20*time.Millisecond, 0)
+
+		tx, err := db.Begin(true)
+		require.NoError(t, err)
+		require.NoError(t, tx.Enter("SELECT 1"))
+
+		// running statements are never interrupted
+		time.Sleep(50 * time.Millisecond)
+		require.Empty(t, buf.String())
+		tx.Leave()
+
+		require.Equal(t, errs.ErrTransactionTimeout, tx.Enter(""))
+		require.Contains(t, buf.String(), "open for more than 20ms")
+		require.Equal(t, engine.ErrTransactionDiscarded, tx.Rollback())
+	})
+
+	t.Run("commit", func(t *testing.T) {
+		db, buf := newDB(t, 10*time.Millisecond, 10*time.Millisecond)
+
+		tx, err := db.Begin(true)
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+
+		time.Sleep(30 * time.Millisecond)
+		require.Empty(t, buf.String())
+	})
+}
@@ -0,0 +1,175 @@
+package database
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	errs "github.com/genjidb/genji/errors"
+)
+
+// txWatchdog rolls back a transaction once it has been open for longer than
+// its lifetime timeout, or idle for longer than its idle timeout.
+// A transaction is idle when no statement is running in it.
+// Transactions are never rolled back while a statement is running: if the lifetime
+// timeout expires during a statement, the transaction is rolled back when it ends.
+type txWatchdog struct {
+	tx       *Transaction
+	lifetime time.Duration
+	idle     time.Duration
+	logger   *log.Logger
+
+	mu        sync.Mutex
+	timer     *time.Timer
+	running   int
+	lastUsed  time.Time
+	statement string
+	// reason of the timeout, if it expired while statements were running.
+	expired string
+	// set to true once the transaction was rolled back by the watchdog.
+	timedOut bool
+	// set to true once the transaction was committed or rolled back.
+	stopped bool
+}
+
+func newTxWatchdog(tx *Transaction, lifetime, idle time.Duration, logger *log.Logger) *txWatchdog {
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	w := txWatchdog{
+		tx:       tx,
+		lifetime: lifetime,
+		idle:     idle,
+		logger:   logger,
+		lastUsed: tx.StartedAt,
+	}
+
+	w.timer = time.AfterFunc(w.nextCheck(tx.StartedAt), w.check)
+	return &w
+}
+
+// nextCheck returns the duration after which the timeouts must be checked again.
+func (w *txWatchdog) nextCheck(now time.Time) time.Duration {
+	var d time.Duration
+
+	if w.lifetime > 0 {
+		d = w.lifetime - now.Sub(w.tx.StartedAt)
+	}
+
+	if w.idle > 0 {
+		idle := w.idle
+		if w.running == 0 {
+			idle -= now.Sub(w.lastUsed)
+		}
+
+		if d <= 0 || idle < d {
+			d = idle
+		}
+	}
+
+	return d
+}
+
+func (w *txWatchdog) check() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stopped {
+		return
+	}
+
+	now := time.Now()
+
+	var reason string
+	switch {
+	case w.lifetime > 0 && now.Sub(w.tx.StartedAt) >= w.lifetime:
+		reason = "open for more than " + w.lifetime.String()
+	case w.idle > 0 && w.running == 0 && now.Sub(w.lastUsed) >= w.idle:
+		reason = "idle for more than " + w.idle.String()
+	}
+
+	switch {
+	case reason == "":
+		w.timer.Reset(w.nextCheck(now))
+	case w.running > 0:
+		w.expired = reason
+	default:
+		w.rollback(reason)
+	}
+}
+
+// rollback the transaction. It must be called with w.mu held.
+func (w *txWatchdog) rollback(reason string) {
+	w.stopped = true
+	w.timedOut = true
+
+	w.logger.Printf("genji: rolling back transaction started at %s, %s, last statement: %q",
+		w.tx.StartedAt.Format(time.RFC3339), reason, w.statement)
+
+	_ = w.tx.Tx.Rollback()
+	w.tx.release(w.tx.OnRollbackHooks)
+}
+
+func (w *txWatchdog) enter(statement string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut {
+		return errs.ErrTransactionTimeout
+	}
+
+	w.running++
+	if statement != "" {
+		w.statement = statement
+	}
+
+	return nil
+}
+
+func (w *txWatchdog) leave() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.running--
+	w.lastUsed = time.Now()
+
+	if w.expired != "" && w.running == 0 && !w.stopped {
+		w.rollback(w.expired)
+	}
+}
+
+// stop the watchdog and report whether the transaction
+// was already rolled back because of a timeout.
+func (w *txWatchdog) stop() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.stopped = true
+	w.timer.Stop()
+
+	return w.timedOut
+}
+
+// Enter must be called before running a statement in the transaction,
+// and Leave once it stops using the transaction.
+// While statements are running, the transaction is not considered idle and
+// is never rolled back automatically.
+// If the transaction was rolled back because of a timeout, it returns errs.ErrTransactionTimeout.
+// The statement is reported if the transaction times out later.
+func (tx *Transaction) Enter(statement string) error {
+	if tx.watchdog == nil {
+		return nil
+	}
+
+	return tx.watchdog.enter(statement)
+}
+
+// Leave must be called once a statement stops using the transaction.
+func (tx *Transaction) Leave() {
+	if tx.watchdog == nil {
+		return
+	}
+
+	tx.watchdog.leave()
+}
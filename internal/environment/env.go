@@ -23,6 +23,10 @@ type Environment struct {
 	Doc     document.Document
 	Catalog database.Catalog
 	Tx      *database.Transaction
+	Tracker *database.ResourceTracker
+	// Documents returned by the previous iteration of the recursive
+	// common table expressions being evaluated, by name.
+	WorkingTables map[string][]document.Document
 
 	Outer *Environment
 }
@@ -141,12 +145,49 @@ func (e *Environment) GetCatalog() database.Catalog {
 	return nil
 }
 
+// SetWorkingTable sets the documents of the working table of a recursive
+// common table expression.
+func (e *Environment) SetWorkingTable(name string, docs []document.Document) {
+	if e.WorkingTables == nil {
+		e.WorkingTables = make(map[string][]document.Document)
+	}
+
+	e.WorkingTables[name] = docs
+}
+
+// GetWorkingTable returns the documents of the working table with the given name.
+func (e *Environment) GetWorkingTable(name string) ([]document.Document, bool) {
+	if docs, ok := e.WorkingTables[name]; ok {
+		return docs, true
+	}
+	if outer := e.GetOuter(); outer != nil {
+		return outer.GetWorkingTable(name)
+	}
+
+	return nil, false
+}
+
+// GetResourceTracker returns the tracker of the resources consumed by the statement
+// being run, or nil if there is none.
+func (e *Environment) GetResourceTracker() *database.ResourceTracker {
+	if e.Tracker != nil {
+		return e.Tracker
+	}
+	if outer := e.GetOuter(); outer != nil {
+		return outer.GetResourceTracker()
+	}
+
+	return nil
+}
+
 func (e *Environment) Clone() (*Environment, error) {
 	var newEnv Environment
 
 	newEnv.Params = e.Params
 	newEnv.Tx = e.Tx
 	newEnv.Catalog = e.Catalog
+	newEnv.Tracker = e.Tracker
+	newEnv.WorkingTables = e.WorkingTables
 
 	if e.Doc != nil {
 		fb := document.NewFieldBuffer()
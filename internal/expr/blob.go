@@ -0,0 +1,260 @@
+package expr
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/environment"
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// BlobLengthFunc represents the blob_length() function.
+// It returns the size in bytes of a blob, or NULL if the value is not a blob.
+type BlobLengthFunc struct {
+	Expr Expr
+}
+
+// Eval returns the length of the blob.
+func (b *BlobLengthFunc) Eval(env *environment.Environment) (document.Value, error) {
+	v, err := b.Expr.Eval(env)
+	if err != nil {
+		return NullLiteral, err
+	}
+	if v.Type != document.BlobValue {
+		return NullLiteral, nil
+	}
+
+	return document.NewIntegerValue(int64(len(v.V.([]byte)))), nil
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (b *BlobLengthFunc) IsEqual(other Expr) bool {
+	o, ok := other.(*BlobLengthFunc)
+	if !ok {
+		return false
+	}
+
+	return Equal(b.Expr, o.Expr)
+}
+
+func (b *BlobLengthFunc) Params() []Expr { return []Expr{b.Expr} }
+
+func (b *BlobLengthFunc) String() string {
+	return stringutil.Sprintf("blob_length(%v)", b.Expr)
+}
+
+// SubstrBlobFunc represents the substr_blob() function.
+// It returns the part of a blob starting at the given position, counted from 1,
+// and of at most the given length, or up to the end of the blob if the length is omitted.
+// It returns NULL if the value is not a blob.
+type SubstrBlobFunc struct {
+	Expr   Expr
+	Start  Expr
+	Length Expr
+}
+
+// Eval returns the selected part of the blob.
+func (s *SubstrBlobFunc) Eval(env *environment.Environment) (document.Value, error) {
+	v, err := s.Expr.Eval(env)
+	if err != nil {
+		return NullLiteral, err
+	}
+	if v.Type != document.BlobValue {
+		return NullLiteral, nil
+	}
+	data := v.V.([]byte)
+
+	start, err := evalPositiveInteger(env, s.Start, "start")
+	if err != nil {
+		return NullLiteral, err
+	}
+	if start == 0 {
+		return NullLiteral, stringutil.Errorf("substr_blob() start must be greater than 0")
+	}
+	if start > int64(len(data)) {
+		return document.NewBlobValue([]byte{}), nil
+	}
+	data = data[start-1:]
+
+	if s.Length != nil {
+		length, err := evalPositiveInteger(env, s.Length, "length")
+		if err != nil {
+			return NullLiteral, err
+		}
+
+		if length < int64(len(data)) {
+			data = data[:length]
+		}
+	}
+
+	return document.NewBlobValue(data), nil
+}
+
+func evalPositiveInteger(env *environment.Environment, e Expr, name string) (int64, error) {
+	v, err := e.Eval(env)
+	if err != nil {
+		return 0, err
+	}
+	if v.Type != document.IntegerValue || v.V.(int64) < 0 {
+		return 0, stringutil.Errorf("substr_blob() %s must be a positive integer, got %v", name, v)
+	}
+
+	return v.V.(int64), nil
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (s *SubstrBlobFunc) IsEqual(other Expr) bool {
+	o, ok := other.(*SubstrBlobFunc)
+	if !ok {
+		return false
+	}
+
+	if (s.Length == nil) != (o.Length == nil) {
+		return false
+	}
+
+	return Equal(s.Expr, o.Expr) && Equal(s.Start, o.Start) && (s.Length == nil || Equal(s.Length, o.Length))
+}
+
+func (s *SubstrBlobFunc) Params() []Expr {
+	if s.Length == nil {
+		return []Expr{s.Expr, s.Start}
+	}
+
+	return []Expr{s.Expr, s.Start, s.Length}
+}
+
+func (s *SubstrBlobFunc) String() string {
+	if s.Length == nil {
+		return stringutil.Sprintf("substr_blob(%v, %v)", s.Expr, s.Start)
+	}
+
+	return stringutil.Sprintf("substr_blob(%v, %v, %v)", s.Expr, s.Start, s.Length)
+}
+
+// binary-to-text encodings supported by encode() and decode().
+var blobEncodings = map[string]struct {
+	encode func([]byte) string
+	decode func(string) ([]byte, error)
+}{
+	"base64": {base64.StdEncoding.EncodeToString, base64.StdEncoding.DecodeString},
+	"hex":    {hex.EncodeToString, hex.DecodeString},
+}
+
+func evalBlobEncoding(env *environment.Environment, e Expr, fname string) (string, error) {
+	v, err := e.Eval(env)
+	if err != nil {
+		return "", err
+	}
+	if v.Type == document.TextValue {
+		name := strings.ToLower(v.V.(string))
+		if _, ok := blobEncodings[name]; ok {
+			return name, nil
+		}
+	}
+
+	return "", stringutil.Errorf("%s() format must be 'base64' or 'hex', got %v", fname, v)
+}
+
+// EncodeFunc represents the encode() function.
+// It returns the textual representation of a blob, or of the bytes of a text,
+// in the given format: 'base64' or 'hex'.
+// It returns NULL if the value is not a blob or a text.
+type EncodeFunc struct {
+	Expr   Expr
+	Format Expr
+}
+
+// Eval returns the encoded value.
+func (e *EncodeFunc) Eval(env *environment.Environment) (document.Value, error) {
+	v, err := e.Expr.Eval(env)
+	if err != nil {
+		return NullLiteral, err
+	}
+
+	var data []byte
+	switch v.Type {
+	case document.BlobValue:
+		data = v.V.([]byte)
+	case document.TextValue:
+		data = []byte(v.V.(string))
+	default:
+		return NullLiteral, nil
+	}
+
+	format, err := evalBlobEncoding(env, e.Format, "encode")
+	if err != nil {
+		return NullLiteral, err
+	}
+
+	return document.NewTextValue(blobEncodings[format].encode(data)), nil
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (e *EncodeFunc) IsEqual(other Expr) bool {
+	o, ok := other.(*EncodeFunc)
+	if !ok {
+		return false
+	}
+
+	return Equal(e.Expr, o.Expr) && Equal(e.Format, o.Format)
+}
+
+func (e *EncodeFunc) Params() []Expr { return []Expr{e.Expr, e.Format} }
+
+func (e *EncodeFunc) String() string {
+	return stringutil.Sprintf("encode(%v, %v)", e.Expr, e.Format)
+}
+
+// DecodeFunc represents the decode() function.
+// It returns the blob represented by a text in the given format: 'base64' or 'hex'.
+// It returns NULL if the value is not a text.
+type DecodeFunc struct {
+	Expr   Expr
+	Format Expr
+}
+
+// Eval returns the decoded blob.
+func (d *DecodeFunc) Eval(env *environment.Environment) (document.Value, error) {
+	v, err := d.Expr.Eval(env)
+	if err != nil {
+		return NullLiteral, err
+	}
+	if v.Type != document.TextValue {
+		return NullLiteral, nil
+	}
+
+	format, err := evalBlobEncoding(env, d.Format, "decode")
+	if err != nil {
+		return NullLiteral, err
+	}
+
+	data, err := blobEncodings[format].decode(v.V.(string))
+	if err != nil {
+		return NullLiteral, stringutil.Errorf("decode(): invalid %s value: %w", format, err)
+	}
+
+	return document.NewBlobValue(data), nil
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (d *DecodeFunc) IsEqual(other Expr) bool {
+	o, ok := other.(*DecodeFunc)
+	if !ok {
+		return false
+	}
+
+	return Equal(d.Expr, o.Expr) && Equal(d.Format, o.Format)
+}
+
+func (d *DecodeFunc) Params() []Expr { return []Expr{d.Expr, d.Format} }
+
+func (d *DecodeFunc) String() string {
+	return stringutil.Sprintf("decode(%v, %v)", d.Expr, d.Format)
+}
@@ -17,15 +17,45 @@ func newCmpOp(a, b Expr, t scanner.Token) *cmpOp {
 	return &cmpOp{&simpleOperator{a, b, t}}
 }
 
+// isStrict returns true if the expression is evaluated by a transaction
+// running in strict mode.
+func isStrict(env *environment.Environment) bool {
+	if env == nil {
+		return false
+	}
+
+	tx := env.GetTx()
+	return tx != nil && tx.Strict
+}
+
+// CheckComparable returns an error if values of types a and b cannot be compared
+// in strict mode, following the rules of document.Comparable.
+func CheckComparable(a, b document.ValueType) error {
+	if document.Comparable(a, b) {
+		return nil
+	}
+
+	return stringutil.Errorf("cannot compare %s with %s", a, b)
+}
+
 // Eval compares a and b together using the operator specified when constructing the CmpOp
 // and returns the result of the comparison.
 // Comparing with NULL always evaluates to NULL.
+// In strict mode, comparing values of incompatible types returns an error.
 func (op *cmpOp) Eval(env *environment.Environment) (document.Value, error) {
+	strict := isStrict(env)
+
 	return op.simpleOperator.eval(env, func(a, b document.Value) (document.Value, error) {
 		if a.Type == document.NullValue || b.Type == document.NullValue {
 			return NullLiteral, nil
 		}
 
+		if strict {
+			if err := CheckComparable(a.Type, b.Type); err != nil {
+				return NullLiteral, err
+			}
+		}
+
 		ok, err := op.compare(a, b)
 		if ok {
 			return TrueLiteral, err
@@ -103,11 +133,25 @@ func (op *BetweenOperator) Eval(env *environment.Environment) (document.Value, e
 		return FalseLiteral, err
 	}
 
+	strict := isStrict(env)
+
 	return op.simpleOperator.eval(env, func(a, b document.Value) (document.Value, error) {
 		if a.Type == document.NullValue || b.Type == document.NullValue {
 			return NullLiteral, nil
 		}
 
+		if strict {
+			if x.Type == document.NullValue {
+				return NullLiteral, nil
+			}
+			if err := CheckComparable(x.Type, a.Type); err != nil {
+				return NullLiteral, err
+			}
+			if err := CheckComparable(x.Type, b.Type); err != nil {
+				return NullLiteral, err
+			}
+		}
+
 		ok, err := x.IsGreaterThanOrEqual(a)
 		if !ok || err != nil {
 			return FalseLiteral, err
@@ -147,15 +191,30 @@ func In(a, b Expr) Expr {
 }
 
 func (op *InOperator) Eval(env *environment.Environment) (document.Value, error) {
-	return op.simpleOperator.eval(env, func(a, b document.Value) (document.Value, error) {
+	strict := isStrict(env)
+
+	// a subquery returns the array of its values
+	sop := op.simpleOperator
+	if sq, ok := op.b.(*Subquery); ok {
+		sop = &simpleOperator{op.a, subqueryArray{sq}, op.Tok}
+	}
+
+	return sop.eval(env, func(a, b document.Value) (document.Value, error) {
 		if a.Type == document.NullValue || b.Type == document.NullValue {
 			return NullLiteral, nil
 		}
 
 		if b.Type != document.ArrayValue {
+			if strict {
+				return NullLiteral, stringutil.Errorf("IN expects an array, got %s", b.Type)
+			}
 			return FalseLiteral, nil
 		}
 
+		if strict {
+			return strictIn(a, b.V.(document.Array))
+		}
+
 		ok, err := document.ArrayContains(b.V.(document.Array), a)
 		if err != nil {
 			return NullLiteral, err
@@ -168,6 +227,41 @@ func (op *InOperator) Eval(env *environment.Environment) (document.Value, error)
 	})
 }
 
+// strictIn returns true if arr contains a, false if it doesn't,
+// or NULL if it doesn't but contains NULL, following three-valued logic.
+// It returns an error if arr contains values that cannot be compared with a.
+func strictIn(a document.Value, arr document.Array) (document.Value, error) {
+	res := FalseLiteral
+
+	err := arr.Iterate(func(i int, v document.Value) error {
+		if v.Type == document.NullValue {
+			if res != TrueLiteral {
+				res = NullLiteral
+			}
+			return nil
+		}
+
+		if err := CheckComparable(a.Type, v.Type); err != nil {
+			return err
+		}
+
+		if res == TrueLiteral {
+			return nil
+		}
+
+		ok, err := a.IsEqual(v)
+		if ok {
+			res = TrueLiteral
+		}
+		return err
+	})
+	if err != nil {
+		return NullLiteral, err
+	}
+
+	return res, nil
+}
+
 type NotInOperator struct {
 	InOperator
 }
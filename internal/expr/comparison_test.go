@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/database"
 	"github.com/genjidb/genji/internal/environment"
 )
 
@@ -182,3 +183,48 @@ func TestComparisonBetweenExpr(t *testing.T) {
 		})
 	}
 }
+
+func TestStrictMode(t *testing.T) {
+	env := environment.New(doc)
+	env.Tx = &database.Transaction{Strict: true}
+
+	tests := []struct {
+		expr  string
+		res   document.Value
+		fails bool
+	}{
+		{"1 = a", document.NewBoolValue(true), false},
+		{"1.0 < a", document.NewBoolValue(false), false},
+		{"1 = 'a'", nullLiteral, true},
+		{"'1' > a", nullLiteral, true},
+		{"1 = NULL", nullLiteral, false},
+		{"a = notFound", nullLiteral, false},
+		{"NULL = NULL", nullLiteral, false},
+		{"NULL IS NULL", document.NewBoolValue(true), false},
+		{"1 IS 'a'", document.NewBoolValue(false), false},
+		{"1 BETWEEN 0 AND 'foo'", nullLiteral, true},
+		{"NULL BETWEEN 0 AND 2", nullLiteral, false},
+		{"1 IN [1, 2]", document.NewBoolValue(true), false},
+		{"1 IN [2, NULL]", nullLiteral, false},
+		{"1 IN [1, NULL]", document.NewBoolValue(true), false},
+		{"1 NOT IN [2, NULL]", nullLiteral, false},
+		{"1 IN [2, 'a']", nullLiteral, true},
+		{"1 IN 1", nullLiteral, true},
+		{"1 LIKE 'a'", nullLiteral, true},
+		{"NULL LIKE 'a'", nullLiteral, false},
+		{"NULL AND true", nullLiteral, false},
+		{"NULL AND false", document.NewBoolValue(false), false},
+		{"false AND NULL", document.NewBoolValue(false), false},
+		{"NULL OR true", document.NewBoolValue(true), false},
+		{"NULL OR false", nullLiteral, false},
+		{"false OR NULL", nullLiteral, false},
+		{"NOT NULL", nullLiteral, false},
+		{"NOT (1 = NULL)", nullLiteral, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			testExpr(t, test.expr, env, test.res, test.fails)
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package expr
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/document/encoding/msgpack"
+	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/environment"
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// version of the format of the blobs returned by encrypt().
+const encryptedValueVersion = 1
+
+var errInvalidEncryptedValue = errors.New("decrypt(): invalid encrypted value")
+
+// EncryptFunc represents the encrypt() function.
+// It encrypts any value with AES-GCM, using the key with the given id returned
+// by the key provider of the database, and returns a blob. The id of the key
+// is stored in the blob, which allows decrypt to find it.
+// It returns NULL if the value is NULL.
+type EncryptFunc struct {
+	Expr  Expr
+	KeyID Expr
+}
+
+// Eval encrypts the value.
+func (e *EncryptFunc) Eval(env *environment.Environment) (document.Value, error) {
+	v, err := e.Expr.Eval(env)
+	if err != nil {
+		return NullLiteral, err
+	}
+	if v.Type == document.NullValue {
+		return NullLiteral, nil
+	}
+
+	id, err := e.KeyID.Eval(env)
+	if err != nil {
+		return NullLiteral, err
+	}
+	if id.Type != document.TextValue {
+		return NullLiteral, stringutil.Errorf("encrypt() key id must be a text, got %q", id.Type)
+	}
+	keyID := id.V.(string)
+
+	aead, err := newAEAD(env, keyID)
+	if err != nil {
+		return NullLiteral, err
+	}
+
+	var plaintext bytes.Buffer
+	enc := msgpack.NewEncoder(&plaintext)
+	defer enc.Close()
+	err = enc.EncodeValue(v)
+	if err != nil {
+		return NullLiteral, err
+	}
+
+	// version | key id length | key id | nonce | ciphertext
+	out := make([]byte, 1+binary.MaxVarintLen64, 1+binary.MaxVarintLen64+len(keyID)+aead.NonceSize()+plaintext.Len()+aead.Overhead())
+	out[0] = encryptedValueVersion
+	n := binary.PutUvarint(out[1:], uint64(len(keyID)))
+	out = append(out[:1+n], keyID...)
+
+	random := rand.Reader
+	if tx := env.GetTx(); tx != nil {
+		random = tx.Random()
+	}
+	nonce := make([]byte, aead.NonceSize())
+	_, err = io.ReadFull(random, nonce)
+	if err != nil {
+		return NullLiteral, err
+	}
+	out = append(out, nonce...)
+
+	// the key id is authenticated along with the value
+	out = aead.Seal(out, nonce, plaintext.Bytes(), []byte(keyID))
+	return document.NewBlobValue(out), nil
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (e *EncryptFunc) IsEqual(other Expr) bool {
+	o, ok := other.(*EncryptFunc)
+	if !ok {
+		return false
+	}
+
+	return Equal(e.Expr, o.Expr) && Equal(e.KeyID, o.KeyID)
+}
+
+func (e *EncryptFunc) Params() []Expr { return []Expr{e.Expr, e.KeyID} }
+
+func (e *EncryptFunc) String() string {
+	return stringutil.Sprintf("encrypt(%v, %v)", e.Expr, e.KeyID)
+}
+
+// DecryptFunc represents the decrypt() function.
+// It decrypts a blob returned by encrypt() and returns the original value.
+// It returns NULL if the value is NULL.
+type DecryptFunc struct {
+	Expr Expr
+}
+
+// Eval decrypts the value.
+func (d *DecryptFunc) Eval(env *environment.Environment) (document.Value, error) {
+	v, err := d.Expr.Eval(env)
+	if err != nil {
+		return NullLiteral, err
+	}
+	if v.Type == document.NullValue {
+		return NullLiteral, nil
+	}
+	if v.Type != document.BlobValue {
+		return NullLiteral, stringutil.Errorf("decrypt() expects a blob, got %q", v.Type)
+	}
+	data := v.V.([]byte)
+
+	if len(data) == 0 || data[0] != encryptedValueVersion {
+		return NullLiteral, errInvalidEncryptedValue
+	}
+	l, n := binary.Uvarint(data[1:])
+	if n <= 0 || uint64(len(data)-1-n) < l {
+		return NullLiteral, errInvalidEncryptedValue
+	}
+	data = data[1+n:]
+	keyID := string(data[:l])
+	data = data[l:]
+
+	aead, err := newAEAD(env, keyID)
+	if err != nil {
+		return NullLiteral, err
+	}
+	if len(data) < aead.NonceSize() {
+		return NullLiteral, errInvalidEncryptedValue
+	}
+
+	plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(keyID))
+	if err != nil {
+		return NullLiteral, stringutil.Errorf("decrypt(): %w", err)
+	}
+
+	dec := msgpack.NewDecoder(bytes.NewReader(plaintext))
+	defer dec.Close()
+	return dec.DecodeValue()
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (d *DecryptFunc) IsEqual(other Expr) bool {
+	o, ok := other.(*DecryptFunc)
+	if !ok {
+		return false
+	}
+
+	return Equal(d.Expr, o.Expr)
+}
+
+func (d *DecryptFunc) Params() []Expr { return []Expr{d.Expr} }
+
+func (d *DecryptFunc) String() string {
+	return stringutil.Sprintf("decrypt(%v)", d.Expr)
+}
+
+// newAEAD returns the cipher using the key with the given id.
+func newAEAD(env *environment.Environment, keyID string) (cipher.AEAD, error) {
+	var kp database.KeyProvider
+	if tx := env.GetTx(); tx != nil {
+		kp = tx.KeyProvider
+	}
+	if kp == nil {
+		return nil, errors.New("no key provider configured")
+	}
+
+	key, err := kp.Key(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -121,6 +121,10 @@ func Walk(e Expr, fn func(Expr) bool) bool {
 		}
 	case *NamedExpr:
 		return Walk(t.Expr, fn)
+	case Parentheses:
+		return Walk(t.E, fn)
+	case Exists:
+		return Walk(t.Subquery, fn)
 	case Function:
 		for _, p := range t.Params() {
 			if !Walk(p, fn) {
@@ -61,6 +61,8 @@ func TestString(t *testing.T) {
 		"true",
 		"500",
 		`foo.bar[1]`,
+		`foo[$a].bar`,
+		`foo[a + 1]`,
 		`"hello"`,
 		`[1, 2, "foo"]`,
 		`{a: "foo", b: 10}`,
@@ -61,6 +61,96 @@ func BuiltinFunctions() map[string]func(args ...Expr) (Expr, error) {
 			}
 			return &AvgFunc{Expr: args[0]}, nil
 		},
+		"bool_and": func(args ...Expr) (Expr, error) {
+			if len(args) != 1 {
+				return nil, stringutil.Errorf("BOOL_AND() takes 1 argument")
+			}
+			return &BoolAndFunc{Expr: args[0]}, nil
+		},
+		"bool_or": func(args ...Expr) (Expr, error) {
+			if len(args) != 1 {
+				return nil, stringutil.Errorf("BOOL_OR() takes 1 argument")
+			}
+			return &BoolOrFunc{Expr: args[0]}, nil
+		},
+		"any_value": func(args ...Expr) (Expr, error) {
+			if len(args) != 1 {
+				return nil, stringutil.Errorf("ANY_VALUE() takes 1 argument")
+			}
+			return &AnyValueFunc{Expr: args[0]}, nil
+		},
+		"ref": func(args ...Expr) (Expr, error) {
+			if len(args) != 2 {
+				return nil, stringutil.Errorf("ref() takes 2 arguments")
+			}
+			return &RefFunc{Table: args[0], Key: args[1]}, nil
+		},
+		"deref": func(args ...Expr) (Expr, error) {
+			if len(args) != 1 {
+				return nil, stringutil.Errorf("deref() takes 1 argument")
+			}
+			return &DerefFunc{Expr: args[0]}, nil
+		},
+		"blob_length": func(args ...Expr) (Expr, error) {
+			if len(args) != 1 {
+				return nil, stringutil.Errorf("blob_length() takes 1 argument")
+			}
+			return &BlobLengthFunc{Expr: args[0]}, nil
+		},
+		"substr_blob": func(args ...Expr) (Expr, error) {
+			switch len(args) {
+			case 2:
+				return &SubstrBlobFunc{Expr: args[0], Start: args[1]}, nil
+			case 3:
+				return &SubstrBlobFunc{Expr: args[0], Start: args[1], Length: args[2]}, nil
+			}
+			return nil, stringutil.Errorf("substr_blob() takes 2 or 3 arguments")
+		},
+		"encode": func(args ...Expr) (Expr, error) {
+			if len(args) != 2 {
+				return nil, stringutil.Errorf("encode() takes 2 arguments")
+			}
+			return &EncodeFunc{Expr: args[0], Format: args[1]}, nil
+		},
+		"decode": func(args ...Expr) (Expr, error) {
+			if len(args) != 2 {
+				return nil, stringutil.Errorf("decode() takes 2 arguments")
+			}
+			return &DecodeFunc{Expr: args[0], Format: args[1]}, nil
+		},
+		"json_extract": func(args ...Expr) (Expr, error) {
+			if len(args) != 2 {
+				return nil, stringutil.Errorf("json_extract() takes 2 arguments")
+			}
+			return &JSONExtractFunc{Expr: args[0], Path: args[1]}, nil
+		},
+		"mask": func(args ...Expr) (Expr, error) {
+			switch len(args) {
+			case 1:
+				return &MaskFunc{Expr: args[0]}, nil
+			case 2:
+				return &MaskFunc{Expr: args[0], Keep: args[1]}, nil
+			}
+			return nil, stringutil.Errorf("mask() takes 1 or 2 arguments")
+		},
+		"md5":         hashFuncBuilder("md5"),
+		"sha256":      hashFuncBuilder("sha256"),
+		"crc32":       hashFuncBuilder("crc32"),
+		"xxhash":      hashFuncBuilder("xxhash"),
+		"date_bin":    dateBinFuncBuilder("date_bin"),
+		"time_bucket": dateBinFuncBuilder("time_bucket"),
+		"encrypt": func(args ...Expr) (Expr, error) {
+			if len(args) != 2 {
+				return nil, stringutil.Errorf("encrypt() takes 2 arguments")
+			}
+			return &EncryptFunc{Expr: args[0], KeyID: args[1]}, nil
+		},
+		"decrypt": func(args ...Expr) (Expr, error) {
+			if len(args) != 1 {
+				return nil, stringutil.Errorf("decrypt() takes 1 argument")
+			}
+			return &DecryptFunc{Expr: args[0]}, nil
+		},
 	}
 }
 
@@ -656,3 +746,222 @@ func (s *AvgAggregator) Eval(env *environment.Environment) (document.Value, erro
 func (s *AvgAggregator) String() string {
 	return s.Fn.String()
 }
+
+// BoolAndFunc is the BOOL_AND aggregator function.
+type BoolAndFunc struct {
+	Expr Expr
+}
+
+// Eval extracts the result of the aggregation from the given document and returns it.
+func (b *BoolAndFunc) Eval(env *environment.Environment) (document.Value, error) {
+	d, ok := env.GetDocument()
+	if !ok {
+		return document.Value{}, errors.New("misuse of aggregation function BOOL_AND()")
+	}
+
+	return d.GetByField(b.String())
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (b *BoolAndFunc) IsEqual(other Expr) bool {
+	if other == nil {
+		return false
+	}
+
+	o, ok := other.(*BoolAndFunc)
+	if !ok {
+		return false
+	}
+
+	return Equal(b.Expr, o.Expr)
+}
+
+func (b *BoolAndFunc) Params() []Expr { return []Expr{b.Expr} }
+
+func (b *BoolAndFunc) String() string {
+	return stringutil.Sprintf("BOOL_AND(%v)", b.Expr)
+}
+
+// Aggregator returns a BoolAggregator. It implements the AggregatorBuilder interface.
+func (b *BoolAndFunc) Aggregator() Aggregator {
+	return &BoolAggregator{
+		Fn:   b,
+		Expr: b.Expr,
+		And:  true,
+	}
+}
+
+// BoolOrFunc is the BOOL_OR aggregator function.
+type BoolOrFunc struct {
+	Expr Expr
+}
+
+// Eval extracts the result of the aggregation from the given document and returns it.
+func (b *BoolOrFunc) Eval(env *environment.Environment) (document.Value, error) {
+	d, ok := env.GetDocument()
+	if !ok {
+		return document.Value{}, errors.New("misuse of aggregation function BOOL_OR()")
+	}
+
+	return d.GetByField(b.String())
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (b *BoolOrFunc) IsEqual(other Expr) bool {
+	if other == nil {
+		return false
+	}
+
+	o, ok := other.(*BoolOrFunc)
+	if !ok {
+		return false
+	}
+
+	return Equal(b.Expr, o.Expr)
+}
+
+func (b *BoolOrFunc) Params() []Expr { return []Expr{b.Expr} }
+
+func (b *BoolOrFunc) String() string {
+	return stringutil.Sprintf("BOOL_OR(%v)", b.Expr)
+}
+
+// Aggregator returns a BoolAggregator. It implements the AggregatorBuilder interface.
+func (b *BoolOrFunc) Aggregator() Aggregator {
+	return &BoolAggregator{
+		Fn:   b,
+		Expr: b.Expr,
+	}
+}
+
+// BoolAggregator is an aggregator that returns whether all the boolean values
+// of the group are true, if And is true, or whether any of them is true otherwise.
+// Values that are not booleans, including NULL, are ignored.
+// If the group contains no boolean, it returns NULL.
+type BoolAggregator struct {
+	Fn     AggregatorBuilder
+	Expr   Expr
+	And    bool
+	Result *bool
+}
+
+// Aggregate combines the boolean value of the expression with the result.
+func (b *BoolAggregator) Aggregate(env *environment.Environment) error {
+	v, err := b.Expr.Eval(env)
+	if err != nil && err != document.ErrFieldNotFound {
+		return err
+	}
+	if v.Type != document.BoolValue {
+		return nil
+	}
+
+	x := v.V.(bool)
+	if b.Result == nil {
+		b.Result = &x
+		return nil
+	}
+
+	if b.And {
+		*b.Result = *b.Result && x
+	} else {
+		*b.Result = *b.Result || x
+	}
+
+	return nil
+}
+
+// Eval returns the result of the aggregation as a boolean.
+func (b *BoolAggregator) Eval(env *environment.Environment) (document.Value, error) {
+	if b.Result == nil {
+		return document.NewNullValue(), nil
+	}
+
+	return document.NewBoolValue(*b.Result), nil
+}
+
+func (b *BoolAggregator) String() string {
+	return stringutil.Sprintf("%s", b.Fn)
+}
+
+// AnyValueFunc is the ANY_VALUE aggregator function.
+// It allows selecting fields that are not part of the GROUP BY clause.
+type AnyValueFunc struct {
+	Expr Expr
+}
+
+// Eval extracts the selected value from the given document and returns it.
+func (a *AnyValueFunc) Eval(env *environment.Environment) (document.Value, error) {
+	d, ok := env.GetDocument()
+	if !ok {
+		return document.Value{}, errors.New("misuse of aggregation function ANY_VALUE()")
+	}
+
+	return d.GetByField(a.String())
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (a *AnyValueFunc) IsEqual(other Expr) bool {
+	if other == nil {
+		return false
+	}
+
+	o, ok := other.(*AnyValueFunc)
+	if !ok {
+		return false
+	}
+
+	return Equal(a.Expr, o.Expr)
+}
+
+func (a *AnyValueFunc) Params() []Expr { return []Expr{a.Expr} }
+
+func (a *AnyValueFunc) String() string {
+	return stringutil.Sprintf("ANY_VALUE(%v)", a.Expr)
+}
+
+// Aggregator returns an AnyValueAggregator. It implements the AggregatorBuilder interface.
+func (a *AnyValueFunc) Aggregator() Aggregator {
+	return &AnyValueAggregator{
+		Fn: a,
+	}
+}
+
+// AnyValueAggregator is an aggregator that returns the first non-null value of the group.
+type AnyValueAggregator struct {
+	Fn    *AnyValueFunc
+	Value document.Value
+}
+
+// Aggregate stores the value if none was stored yet.
+func (a *AnyValueAggregator) Aggregate(env *environment.Environment) error {
+	if a.Value.Type != 0 {
+		return nil
+	}
+
+	v, err := a.Fn.Expr.Eval(env)
+	if err != nil && err != document.ErrFieldNotFound {
+		return err
+	}
+	if v.Type == document.NullValue {
+		return nil
+	}
+
+	a.Value = v
+	return nil
+}
+
+// Eval returns the stored value.
+func (a *AnyValueAggregator) Eval(env *environment.Environment) (document.Value, error) {
+	if a.Value.Type == 0 {
+		return document.NewNullValue(), nil
+	}
+
+	return a.Value, nil
+}
+
+func (a *AnyValueAggregator) String() string {
+	return a.Fn.String()
+}
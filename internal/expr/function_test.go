@@ -1,10 +1,13 @@
 package expr_test
 
 import (
+	"encoding/hex"
 	"testing"
 
 	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/internal/environment"
+	"github.com/genjidb/genji/internal/sql/parser"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPkExpr(t *testing.T) {
@@ -24,3 +27,195 @@ func TestPkExpr(t *testing.T) {
 		})
 	}
 }
+
+func TestBlobFuncs(t *testing.T) {
+	env := environment.New(document.NewFieldBuffer().Add("a", document.NewBlobValue([]byte("hello"))))
+
+	tests := []struct {
+		expr  string
+		res   document.Value
+		fails bool
+	}{
+		{"blob_length(a)", document.NewIntegerValue(5), false},
+		{"blob_length('hello')", nullLiteral, false},
+		{"substr_blob(a, 2)", document.NewBlobValue([]byte("ello")), false},
+		{"substr_blob(a, 2, 3)", document.NewBlobValue([]byte("ell")), false},
+		{"substr_blob(a, 2, 10)", document.NewBlobValue([]byte("ello")), false},
+		{"substr_blob(a, 10)", document.NewBlobValue([]byte{}), false},
+		{"substr_blob('hello', 1)", nullLiteral, false},
+		{"substr_blob(a, 0)", nullLiteral, true},
+		{"substr_blob(a, 1, -1)", nullLiteral, true},
+		{"encode(a, 'hex')", document.NewTextValue("68656c6c6f"), false},
+		{"encode(a, 'BASE64')", document.NewTextValue("aGVsbG8="), false},
+		{"encode('hello', 'base64')", document.NewTextValue("aGVsbG8="), false},
+		{"encode(1, 'hex')", nullLiteral, false},
+		{"encode(a, 'base32')", nullLiteral, true},
+		{"decode('68656c6c6f', 'hex')", document.NewBlobValue([]byte("hello")), false},
+		{"decode('aGVsbG8=', 'base64')", document.NewBlobValue([]byte("hello")), false},
+		{"decode(encode(X'00FF', 'base64'), 'base64')", document.NewBlobValue([]byte{0, 0xff}), false},
+		{"decode(a, 'hex')", nullLiteral, false},
+		{"decode('zz', 'hex')", nullLiteral, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			testExpr(t, test.expr, env, test.res, test.fails)
+		})
+	}
+}
+
+func TestMaskFunc(t *testing.T) {
+	env := environment.New(document.NewFieldBuffer().Add("a", document.NewTextValue("123-45-6789")))
+
+	tests := []struct {
+		expr  string
+		res   document.Value
+		fails bool
+	}{
+		{"mask(a)", document.NewTextValue("XXXXXXXXXXX"), false},
+		{"mask(a, 4)", document.NewTextValue("XXXXXXX6789"), false},
+		{"mask(a, 20)", document.NewTextValue("123-45-6789"), false},
+		{"mask('héllo', 2)", document.NewTextValue("XXXlo"), false},
+		{"mask(10, 1)", nullLiteral, false},
+		{"mask(NULL)", nullLiteral, false},
+		{"mask(a, -1)", nullLiteral, true},
+		{"mask(a, 'a')", nullLiteral, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			testExpr(t, test.expr, env, test.res, test.fails)
+		})
+	}
+}
+
+func TestHashFuncs(t *testing.T) {
+	env := environment.New(document.NewFieldBuffer().
+		Add("a", document.NewTextValue("hello")).
+		Add("b", document.NewBlobValue([]byte("hello"))))
+
+	unhex := func(s string) document.Value {
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return document.NewBlobValue(b)
+	}
+
+	tests := []struct {
+		expr  string
+		res   document.Value
+		fails bool
+	}{
+		{"md5(a)", unhex("5d41402abc4b2a76b9719d911017c592"), false},
+		{"md5(b)", unhex("5d41402abc4b2a76b9719d911017c592"), false},
+		{"sha256(a)", unhex("2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"), false},
+		{"crc32(a)", document.NewIntegerValue(907060870), false},
+		{"xxhash('abc')", document.NewIntegerValue(4952883123889572249), false},
+		{"xxhash('')", document.NewIntegerValue(-1205034819632174695), false},
+		{"md5(10)", nullLiteral, false},
+		{"sha256(NULL)", nullLiteral, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			testExpr(t, test.expr, env, test.res, test.fails)
+		})
+	}
+}
+
+func TestJSONExtractFunc(t *testing.T) {
+	env := environment.New(document.NewFromJSON([]byte(`{
+		"a": {
+			"items": [{"name": "foo", "price": 5}, {"name": "bar", "price": 15, "tags": ["x"]}],
+			"b": {"name": "baz"},
+			"c.d": 1
+		},
+		"s": "{\"a\": [1, 2, 3]}"
+	}`)))
+
+	tests := []struct {
+		expr  string
+		res   string
+		fails bool
+	}{
+		{`json_extract(a, '$')`, `{"items": [{"name": "foo", "price": 5}, {"name": "bar", "price": 15, "tags": ["x"]}], "b": {"name": "baz"}, "c.d": 1}`, false},
+		{`json_extract(a, '$.items[0].name')`, `"foo"`, false},
+		{`json_extract(a, '$.items[-1].price')`, `15`, false},
+		{`json_extract(a, "$['b'][\"name\"]")`, `"baz"`, false},
+		{`json_extract(a, "$['c.d']")`, `1`, false},
+		{`json_extract(a, '$.items[5]')`, `null`, false},
+		{`json_extract(a, '$.unknown.name')`, `null`, false},
+		{`json_extract(a, '$.items[*].name')`, `["foo", "bar"]`, false},
+		{`json_extract(a, '$.items.*.price')`, `[5, 15]`, false},
+		{`json_extract(a, '$..name')`, `["foo", "bar", "baz"]`, false},
+		{`json_extract(a, '$.items[?(@.price < 10)].name')`, `["foo"]`, false},
+		{`json_extract(a, '$.items[?(@.name == "bar")].price')`, `[15]`, false},
+		{`json_extract(a, '$.items[?(@.tags)].name')`, `["bar"]`, false},
+		{`json_extract(a, '$.items[?(@.price > 100)]')`, `[]`, false},
+		{`json_extract(s, '$.a[1]')`, `2`, false},
+		{`json_extract(NULL, '$.a')`, `null`, false},
+		{`json_extract(a, 'items')`, ``, true},
+		{`json_extract(a, '$.items[')`, ``, true},
+		{`json_extract(a, '$.items[?(@..name)]')`, ``, true},
+		{`json_extract(a, 1)`, ``, true},
+		{`json_extract('foo', '$')`, ``, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			e, err := parser.ParseExpr(test.expr)
+			require.NoError(t, err)
+
+			res, err := e.Eval(env)
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			data, err := res.MarshalJSON()
+			require.NoError(t, err)
+			require.JSONEq(t, test.res, string(data))
+		})
+	}
+}
+
+func TestDateBinFunc(t *testing.T) {
+	env := environment.New(document.NewFieldBuffer().
+		Add("at", document.NewTextValue("2021-03-04T10:37:12.5+01:00")).
+		Add("ts", document.NewIntegerValue(1614852000)).
+		Add("f", document.NewDoubleValue(-7.5)))
+
+	tests := []struct {
+		expr  string
+		res   document.Value
+		fails bool
+	}{
+		{"date_bin('15 minutes', at)", document.NewTextValue("2021-03-04T09:30:00Z"), false},
+		{"date_bin('1h', at)", document.NewTextValue("2021-03-04T09:00:00Z"), false},
+		{"date_bin('1 day', at)", document.NewTextValue("2021-03-04T00:00:00Z"), false},
+		{"date_bin('1 hour', at, '2021-01-01T00:20:00Z')", document.NewTextValue("2021-03-04T09:20:00Z"), false},
+		{"date_bin('1h', '1969-12-31 23:30:00')", document.NewTextValue("1969-12-31T23:00:00Z"), false},
+		{"time_bucket('2 weeks', '2021-03-10')", document.NewTextValue("2021-03-04T00:00:00Z"), false},
+		{"date_bin(3600, ts)", document.NewIntegerValue(1614852000), false},
+		{"date_bin(7, ts)", document.NewIntegerValue(1614851994), false},
+		{"date_bin(60, ts, 30)", document.NewIntegerValue(1614851970), false},
+		{"date_bin(5, f)", document.NewDoubleValue(-10), false},
+		{"date_bin(-5, -7)", document.NewIntegerValue(-10), true},
+		{"date_bin(5, -7)", document.NewIntegerValue(-10), false},
+		{"date_bin('1h', NULL)", nullLiteral, false},
+		{"date_bin('1 month', at)", nullLiteral, true},
+		{"date_bin('0s', at)", nullLiteral, true},
+		{"date_bin(60, at)", nullLiteral, true},
+		{"date_bin('1h', ts)", nullLiteral, true},
+		{"date_bin('1h', 'yesterday')", nullLiteral, true},
+		{"date_bin('1h', true)", nullLiteral, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			testExpr(t, test.expr, env, test.res, test.fails)
+		})
+	}
+}
@@ -4,6 +4,7 @@
 package glob
 
 import (
+	"strings"
 	"unicode"
 	"unicode/utf8"
 )
@@ -185,3 +186,37 @@ func MatchLike(pattern, s string) bool {
 	}
 	return true
 }
+
+// LikePrefix returns the literal characters pattern starts with,
+// up to the first unescaped wildcard. Any string matched by pattern
+// starts with the returned prefix, modulo case folding.
+func LikePrefix(pattern string) string {
+	var prefix strings.Builder
+	var prevEscape bool
+
+	for len(pattern) != 0 {
+		p, size := utf8.DecodeRuneInString(pattern)
+		// invalid bytes are matched with runes of the same value
+		// which are encoded differently, stop there.
+		if p == utf8.RuneError && size == 1 {
+			break
+		}
+		pattern = pattern[size:]
+
+		if !prevEscape {
+			if p == matchAll || p == matchOne {
+				break
+			}
+
+			if p == matchEsc {
+				prevEscape = true
+				continue
+			}
+		}
+
+		prevEscape = false
+		prefix.WriteRune(p)
+	}
+
+	return prefix.String()
+}
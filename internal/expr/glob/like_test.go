@@ -200,3 +200,30 @@ func TestMatchLike(t *testing.T) {
 		}
 	}
 }
+
+func TestLikePrefix(t *testing.T) {
+	tests := []struct {
+		pattern, want string
+	}{
+		{"", ""},
+		{"%", ""},
+		{"_abc", ""},
+		{"abc", "abc"},
+		{"abc%", "abc"},
+		{"abc%def", "abc"},
+		{"ab_d%", "ab"},
+		{"ab\\%c%", "ab%c"},
+		{"ab\\\\%", "ab\\"},
+		{"héllo%", "héllo"},
+		{"ab\x80c%", "ab"},
+	}
+
+	for _, test := range tests {
+		if got := LikePrefix(test.pattern); got != test.want {
+			t.Errorf(
+				"LikePrefix(%#v): expected %#v, got %#v",
+				test.pattern, test.want, got,
+			)
+		}
+	}
+}
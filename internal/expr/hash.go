@@ -0,0 +1,158 @@
+package expr
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash/crc32"
+	"math/bits"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/environment"
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// HashFunc represents the hash functions: md5(), sha256(), crc32() and xxhash().
+// They hash the bytes of a text or a blob. md5() and sha256() return the digest
+// as a blob, crc32() (IEEE) and xxhash() (XXH64) return it as an integer.
+// They return NULL if the value is not a text or a blob.
+type HashFunc struct {
+	Name string
+	Expr Expr
+}
+
+var hashFuncs = map[string]func(data []byte) document.Value{
+	"md5": func(data []byte) document.Value {
+		sum := md5.Sum(data)
+		return document.NewBlobValue(sum[:])
+	},
+	"sha256": func(data []byte) document.Value {
+		sum := sha256.Sum256(data)
+		return document.NewBlobValue(sum[:])
+	},
+	"crc32": func(data []byte) document.Value {
+		return document.NewIntegerValue(int64(crc32.ChecksumIEEE(data)))
+	},
+	"xxhash": func(data []byte) document.Value {
+		return document.NewIntegerValue(int64(xxhash64(data)))
+	},
+}
+
+func hashFuncBuilder(name string) func(args ...Expr) (Expr, error) {
+	return func(args ...Expr) (Expr, error) {
+		if len(args) != 1 {
+			return nil, stringutil.Errorf("%s() takes 1 argument", name)
+		}
+		return &HashFunc{Name: name, Expr: args[0]}, nil
+	}
+}
+
+// Eval returns the hash of the value.
+func (h *HashFunc) Eval(env *environment.Environment) (document.Value, error) {
+	v, err := h.Expr.Eval(env)
+	if err != nil {
+		return NullLiteral, err
+	}
+
+	var data []byte
+	switch v.Type {
+	case document.TextValue:
+		data = []byte(v.V.(string))
+	case document.BlobValue:
+		data = v.V.([]byte)
+	default:
+		return NullLiteral, nil
+	}
+
+	return hashFuncs[h.Name](data), nil
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (h *HashFunc) IsEqual(other Expr) bool {
+	o, ok := other.(*HashFunc)
+	if !ok {
+		return false
+	}
+
+	return h.Name == o.Name && Equal(h.Expr, o.Expr)
+}
+
+func (h *HashFunc) Params() []Expr { return []Expr{h.Expr} }
+
+func (h *HashFunc) String() string {
+	return stringutil.Sprintf("%s(%v)", h.Name, h.Expr)
+}
+
+// variables rather than constants, to let the arithmetic on them wrap around.
+var (
+	xxPrime1 uint64 = 11400714785074694791
+	xxPrime2 uint64 = 14029467366897019727
+	xxPrime3 uint64 = 1609587929392839161
+	xxPrime4 uint64 = 9650029242287828579
+	xxPrime5 uint64 = 2870177450012600261
+)
+
+// xxhash64 returns the XXH64 hash of data, with a seed of 0.
+func xxhash64(data []byte) uint64 {
+	n := len(data)
+	var h uint64
+
+	if n >= 32 {
+		v1 := xxPrime1 + xxPrime2
+		v2 := xxPrime2
+		v3 := uint64(0)
+		v4 := -xxPrime1
+		for len(data) >= 32 {
+			v1 = xxRound(v1, binary.LittleEndian.Uint64(data[0:8]))
+			v2 = xxRound(v2, binary.LittleEndian.Uint64(data[8:16]))
+			v3 = xxRound(v3, binary.LittleEndian.Uint64(data[16:24]))
+			v4 = xxRound(v4, binary.LittleEndian.Uint64(data[24:32]))
+			data = data[32:]
+		}
+
+		h = bits.RotateLeft64(v1, 1) + bits.RotateLeft64(v2, 7) + bits.RotateLeft64(v3, 12) + bits.RotateLeft64(v4, 18)
+		h = xxMergeRound(h, v1)
+		h = xxMergeRound(h, v2)
+		h = xxMergeRound(h, v3)
+		h = xxMergeRound(h, v4)
+	} else {
+		h = xxPrime5
+	}
+
+	h += uint64(n)
+
+	for ; len(data) >= 8; data = data[8:] {
+		h ^= xxRound(0, binary.LittleEndian.Uint64(data[:8]))
+		h = bits.RotateLeft64(h, 27)*xxPrime1 + xxPrime4
+	}
+	if len(data) >= 4 {
+		h ^= uint64(binary.LittleEndian.Uint32(data[:4])) * xxPrime1
+		h = bits.RotateLeft64(h, 23)*xxPrime2 + xxPrime3
+		data = data[4:]
+	}
+	for _, b := range data {
+		h ^= uint64(b) * xxPrime5
+		h = bits.RotateLeft64(h, 11) * xxPrime1
+	}
+
+	h ^= h >> 33
+	h *= xxPrime2
+	h ^= h >> 29
+	h *= xxPrime3
+	h ^= h >> 32
+
+	return h
+}
+
+func xxRound(acc, input uint64) uint64 {
+	acc += input * xxPrime2
+	acc = bits.RotateLeft64(acc, 31)
+	return acc * xxPrime1
+}
+
+func xxMergeRound(acc, val uint64) uint64 {
+	val = xxRound(0, val)
+	acc ^= val
+	return acc*xxPrime1 + xxPrime4
+}
@@ -0,0 +1,562 @@
+package expr
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/environment"
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// JSONExtractFunc represents the json_extract() function.
+// It returns the values selected by a JSONPath expression in a document, an array,
+// or a text containing a JSON object or array, e.g.:
+//
+//	json_extract(a, '$.b[0].c')
+//	json_extract(a, '$.items[?(@.price < 10)].name')
+//
+// The following subset of JSONPath is supported:
+//
+//	$                 the root value
+//	.name, ['name']   a field of a document
+//	[n]               an element of an array, counted from the end if negative
+//	.*, [*]           every field of a document or element of an array
+//	..name, ..*       the fields with the given name, or every value, at any depth
+//	[?(@.path)]       the elements having the given path
+//	[?(@.path op v)]  the elements whose value at the given path compares to v
+//	                  using ==, !=, <, <=, > or >=
+//
+// If the path selects at most one value, that value is returned, or NULL if it doesn't exist.
+// Otherwise, i.e. if it uses wildcards, recursion or filters, an array of the selected values is returned.
+// It returns NULL if the value is NULL.
+type JSONExtractFunc struct {
+	Expr Expr
+	Path Expr
+}
+
+// Eval returns the values selected by the path.
+func (j *JSONExtractFunc) Eval(env *environment.Environment) (document.Value, error) {
+	v, err := j.Expr.Eval(env)
+	if err != nil {
+		return NullLiteral, err
+	}
+	if v.Type == document.NullValue {
+		return NullLiteral, nil
+	}
+
+	p, err := j.Path.Eval(env)
+	if err != nil {
+		return NullLiteral, err
+	}
+	if p.Type != document.TextValue {
+		return NullLiteral, stringutil.Errorf("json_extract() path must be a text, got %q", p.Type)
+	}
+
+	path, err := parseJSONPath(p.V.(string))
+	if err != nil {
+		return NullLiteral, stringutil.Errorf("json_extract(): invalid path %q: %w", p.V, err)
+	}
+
+	if v.Type == document.TextValue {
+		v, err = parseJSONText(v.V.(string))
+		if err != nil {
+			return NullLiteral, stringutil.Errorf("json_extract(): %w", err)
+		}
+	}
+
+	values, err := path.eval(v)
+	if err != nil {
+		return NullLiteral, err
+	}
+
+	if path.definite {
+		if len(values) == 0 {
+			return NullLiteral, nil
+		}
+		return values[0], nil
+	}
+
+	return document.NewArrayValue(document.NewValueBuffer(values...)), nil
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (j *JSONExtractFunc) IsEqual(other Expr) bool {
+	o, ok := other.(*JSONExtractFunc)
+	if !ok {
+		return false
+	}
+
+	return Equal(j.Expr, o.Expr) && Equal(j.Path, o.Path)
+}
+
+func (j *JSONExtractFunc) Params() []Expr { return []Expr{j.Expr, j.Path} }
+
+func (j *JSONExtractFunc) String() string {
+	return stringutil.Sprintf("json_extract(%v, %v)", j.Expr, j.Path)
+}
+
+func parseJSONText(s string) (document.Value, error) {
+	s = strings.TrimSpace(s)
+
+	switch {
+	case strings.HasPrefix(s, "{"):
+		var fb document.FieldBuffer
+		err := fb.UnmarshalJSON([]byte(s))
+		return document.NewDocumentValue(&fb), err
+	case strings.HasPrefix(s, "["):
+		var vb document.ValueBuffer
+		err := vb.UnmarshalJSON([]byte(s))
+		return document.NewArrayValue(&vb), err
+	}
+
+	return NullLiteral, errors.New("text must contain a JSON object or array")
+}
+
+type jsonPathStepKind int
+
+const (
+	jsonPathFieldStep jsonPathStepKind = iota
+	jsonPathIndexStep
+	jsonPathWildcardStep
+	jsonPathFilterStep
+)
+
+type jsonPathStep struct {
+	kind      jsonPathStepKind
+	recursive bool
+	field     string
+	index     int
+	filter    *jsonPathFilter
+}
+
+// jsonPathFilter selects the values having a path and, if op is set,
+// whose value at that path compares to the given value.
+type jsonPathFilter struct {
+	path  []jsonPathStep
+	op    string
+	value document.Value
+}
+
+type jsonPath struct {
+	steps []jsonPathStep
+	// true if the path selects at most one value.
+	definite bool
+}
+
+// jsonPathParser parses JSONPath expressions.
+type jsonPathParser struct {
+	s   string
+	pos int
+}
+
+func parseJSONPath(s string) (*jsonPath, error) {
+	p := jsonPathParser{s: s}
+
+	p.skipSpaces()
+	if !p.consume("$") {
+		return nil, errors.New("path must start with $")
+	}
+
+	steps, err := p.parseSteps(true)
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+	if p.pos < len(p.s) {
+		return nil, stringutil.Errorf("unexpected %q", p.s[p.pos:])
+	}
+
+	path := jsonPath{steps: steps, definite: true}
+	for _, st := range steps {
+		if st.recursive || st.kind == jsonPathWildcardStep || st.kind == jsonPathFilterStep {
+			path.definite = false
+		}
+	}
+
+	return &path, nil
+}
+
+// parseSteps parses the steps following $ or @.
+// Wildcards, recursion and filters are only allowed if indefinite is true.
+func (p *jsonPathParser) parseSteps(indefinite bool) ([]jsonPathStep, error) {
+	var steps []jsonPathStep
+
+	for p.pos < len(p.s) {
+		var st jsonPathStep
+		var err error
+
+		switch {
+		case p.consume(".."):
+			if !indefinite {
+				return nil, errors.New("recursive descent is not allowed in filters")
+			}
+			st, err = p.parseDotStep()
+			st.recursive = true
+		case p.consume("."):
+			st, err = p.parseDotStep()
+		case p.consume("["):
+			st, err = p.parseBracketStep()
+		default:
+			return steps, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !indefinite && (st.kind == jsonPathWildcardStep || st.kind == jsonPathFilterStep) {
+			return nil, errors.New("wildcards and filters are not allowed in filters")
+		}
+
+		steps = append(steps, st)
+	}
+
+	return steps, nil
+}
+
+func (p *jsonPathParser) parseDotStep() (jsonPathStep, error) {
+	if p.consume("*") {
+		return jsonPathStep{kind: jsonPathWildcardStep}, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.s) && isJSONPathNameChar(p.s[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return jsonPathStep{}, errors.New("missing field name")
+	}
+
+	return jsonPathStep{kind: jsonPathFieldStep, field: p.s[start:p.pos]}, nil
+}
+
+func isJSONPathNameChar(c byte) bool {
+	return c == '_' || c == '-' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func (p *jsonPathParser) parseBracketStep() (jsonPathStep, error) {
+	var st jsonPathStep
+
+	p.skipSpaces()
+	switch {
+	case p.consume("*"):
+		st.kind = jsonPathWildcardStep
+	case p.consume("?("):
+		f, err := p.parseFilter()
+		if err != nil {
+			return st, err
+		}
+		st.kind = jsonPathFilterStep
+		st.filter = f
+	case p.peek() == '\'' || p.peek() == '"':
+		s, err := p.parseQuoted()
+		if err != nil {
+			return st, err
+		}
+		st.kind = jsonPathFieldStep
+		st.field = s
+	default:
+		n, err := p.parseInteger()
+		if err != nil {
+			return st, err
+		}
+		st.kind = jsonPathIndexStep
+		st.index = n
+	}
+
+	p.skipSpaces()
+	if !p.consume("]") {
+		return st, errors.New("missing ]")
+	}
+
+	return st, nil
+}
+
+var jsonPathOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+func (p *jsonPathParser) parseFilter() (*jsonPathFilter, error) {
+	var f jsonPathFilter
+
+	p.skipSpaces()
+	if !p.consume("@") {
+		return nil, errors.New("filter must start with @")
+	}
+
+	var err error
+	f.path, err = p.parseSteps(false)
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+	for _, op := range jsonPathOperators {
+		if p.consume(op) {
+			f.op = op
+			break
+		}
+	}
+
+	if f.op != "" {
+		p.skipSpaces()
+		f.value, err = p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	p.skipSpaces()
+	if !p.consume(")") {
+		return nil, errors.New("missing )")
+	}
+
+	return &f, nil
+}
+
+func (p *jsonPathParser) parseLiteral() (document.Value, error) {
+	if p.peek() == '\'' || p.peek() == '"' {
+		s, err := p.parseQuoted()
+		return document.NewTextValue(s), err
+	}
+
+	for _, kw := range []struct {
+		s string
+		v document.Value
+	}{
+		{"true", document.NewBoolValue(true)},
+		{"false", document.NewBoolValue(false)},
+		{"null", document.NewNullValue()},
+	} {
+		if p.consume(kw.s) {
+			return kw.v, nil
+		}
+	}
+
+	start := p.pos
+	for p.pos < len(p.s) && strings.IndexByte("+-.eE0123456789", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+	lit := p.s[start:p.pos]
+
+	if i, err := strconv.ParseInt(lit, 10, 64); err == nil {
+		return document.NewIntegerValue(i), nil
+	}
+	if f, err := strconv.ParseFloat(lit, 64); err == nil {
+		return document.NewDoubleValue(f), nil
+	}
+
+	return NullLiteral, stringutil.Errorf("invalid value at position %d", start)
+}
+
+func (p *jsonPathParser) parseQuoted() (string, error) {
+	quote := p.s[p.pos]
+	p.pos++
+
+	var b strings.Builder
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		p.pos++
+
+		switch {
+		case c == quote:
+			return b.String(), nil
+		case c == '\\' && p.pos < len(p.s):
+			b.WriteByte(p.s[p.pos])
+			p.pos++
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", errors.New("unterminated string")
+}
+
+func (p *jsonPathParser) parseInteger() (int, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+		p.pos++
+	}
+
+	n, err := strconv.Atoi(p.s[start:p.pos])
+	if err != nil {
+		return 0, stringutil.Errorf("invalid index at position %d", start)
+	}
+
+	return n, nil
+}
+
+func (p *jsonPathParser) peek() byte {
+	if p.pos < len(p.s) {
+		return p.s[p.pos]
+	}
+
+	return 0
+}
+
+func (p *jsonPathParser) consume(s string) bool {
+	if strings.HasPrefix(p.s[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+
+	return false
+}
+
+func (p *jsonPathParser) skipSpaces() {
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// eval returns the values selected by the path in v.
+func (p *jsonPath) eval(v document.Value) ([]document.Value, error) {
+	return evalJSONPathSteps(p.steps, []document.Value{v})
+}
+
+func evalJSONPathSteps(steps []jsonPathStep, values []document.Value) ([]document.Value, error) {
+	for _, st := range steps {
+		var next []document.Value
+
+		for _, v := range values {
+			var err error
+
+			if st.recursive {
+				err = walkJSONValue(v, func(v document.Value) error {
+					var err error
+					next, err = st.selectFrom(v, next)
+					return err
+				})
+			} else {
+				next, err = st.selectFrom(v, next)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		values = next
+	}
+
+	return values, nil
+}
+
+// walkJSONValue calls fn for v and for each of the values it contains, at any depth.
+func walkJSONValue(v document.Value, fn func(v document.Value) error) error {
+	err := fn(v)
+	if err != nil {
+		return err
+	}
+
+	switch v.Type {
+	case document.DocumentValue:
+		return v.V.(document.Document).Iterate(func(_ string, v document.Value) error {
+			return walkJSONValue(v, fn)
+		})
+	case document.ArrayValue:
+		return v.V.(document.Array).Iterate(func(_ int, v document.Value) error {
+			return walkJSONValue(v, fn)
+		})
+	}
+
+	return nil
+}
+
+// selectFrom appends the values of v selected by the step to dst.
+func (st *jsonPathStep) selectFrom(v document.Value, dst []document.Value) ([]document.Value, error) {
+	switch st.kind {
+	case jsonPathFieldStep:
+		if v.Type != document.DocumentValue {
+			return dst, nil
+		}
+		fv, err := v.V.(document.Document).GetByField(st.field)
+		if err == document.ErrFieldNotFound {
+			return dst, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return append(dst, fv), nil
+	case jsonPathIndexStep:
+		if v.Type != document.ArrayValue {
+			return dst, nil
+		}
+		a := v.V.(document.Array)
+		i := st.index
+		if i < 0 {
+			n, err := document.ArrayLength(a)
+			if err != nil {
+				return nil, err
+			}
+			i += n
+			if i < 0 {
+				return dst, nil
+			}
+		}
+		ev, err := a.GetByIndex(i)
+		if err == document.ErrValueNotFound || err == document.ErrFieldNotFound {
+			return dst, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return append(dst, ev), nil
+	}
+
+	// wildcards and filters select the values of documents and arrays
+	var err error
+	appendValue := func(v document.Value) error {
+		if st.kind == jsonPathFilterStep {
+			ok, err := st.filter.match(v)
+			if err != nil || !ok {
+				return err
+			}
+		}
+
+		dst = append(dst, v)
+		return nil
+	}
+
+	switch v.Type {
+	case document.DocumentValue:
+		err = v.V.(document.Document).Iterate(func(_ string, v document.Value) error {
+			return appendValue(v)
+		})
+	case document.ArrayValue:
+		err = v.V.(document.Array).Iterate(func(_ int, v document.Value) error {
+			return appendValue(v)
+		})
+	}
+
+	return dst, err
+}
+
+func (f *jsonPathFilter) match(v document.Value) (bool, error) {
+	values, err := evalJSONPathSteps(f.path, []document.Value{v})
+	if err != nil || len(values) == 0 {
+		return false, err
+	}
+	if f.op == "" {
+		return true, nil
+	}
+
+	v = values[0]
+	switch f.op {
+	case "==":
+		return v.IsEqual(f.value)
+	case "!=":
+		return v.IsNotEqual(f.value)
+	case "<":
+		return v.IsLesserThan(f.value)
+	case "<=":
+		return v.IsLesserThanOrEqual(f.value)
+	case ">":
+		return v.IsGreaterThan(f.value)
+	default:
+		return v.IsGreaterThanOrEqual(f.value)
+	}
+}
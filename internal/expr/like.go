@@ -21,9 +21,16 @@ func Like(a, b Expr) Expr {
 	return &LikeOperator{&simpleOperator{a, b, scanner.LIKE}}
 }
 
+// Eval implements the Expr interface. If any of the operands is not a text,
+// it evaluates to NULL or, in strict mode, returns an error unless it is NULL.
 func (op *LikeOperator) Eval(env *environment.Environment) (document.Value, error) {
+	strict := isStrict(env)
+
 	return op.simpleOperator.eval(env, func(a, b document.Value) (document.Value, error) {
 		if a.Type != document.TextValue || b.Type != document.TextValue {
+			if strict && a.Type != document.NullValue && b.Type != document.NullValue {
+				return NullLiteral, stringutil.Errorf("cannot match %s with %s", a.Type, b.Type)
+			}
 			return NullLiteral, nil
 		}
 
@@ -19,25 +19,41 @@ func And(a, b Expr) Expr {
 
 // Eval implements the Expr interface. It evaluates a and b and returns true if both evaluate
 // to true.
+// In strict mode, it follows three-valued logic: it returns false if any of them is falsy,
+// otherwise NULL if any of them is NULL.
 func (op *AndOp) Eval(env *environment.Environment) (document.Value, error) {
+	var hasNull bool
+	strict := isStrict(env)
+
 	s, err := op.a.Eval(env)
 	if err != nil {
 		return FalseLiteral, err
 	}
-	isTruthy, err := s.IsTruthy()
-	if !isTruthy || err != nil {
-		return FalseLiteral, err
+	if strict && s.Type == document.NullValue {
+		hasNull = true
+	} else {
+		isTruthy, err := s.IsTruthy()
+		if !isTruthy || err != nil {
+			return FalseLiteral, err
+		}
 	}
 
 	s, err = op.b.Eval(env)
 	if err != nil {
 		return FalseLiteral, err
 	}
-	isTruthy, err = s.IsTruthy()
+	if strict && s.Type == document.NullValue {
+		return NullLiteral, nil
+	}
+	isTruthy, err := s.IsTruthy()
 	if !isTruthy || err != nil {
 		return FalseLiteral, err
 	}
 
+	if hasNull {
+		return NullLiteral, nil
+	}
+
 	return TrueLiteral, nil
 }
 
@@ -53,24 +69,36 @@ func Or(a, b Expr) Expr {
 
 // Eval implements the Expr interface. It evaluates a and b and returns true if a or b evalutate
 // to true.
+// In strict mode, it follows three-valued logic: it returns true if any of them is truthy,
+// otherwise NULL if any of them is NULL.
 func (op *OrOp) Eval(env *environment.Environment) (document.Value, error) {
+	var hasNull bool
+	strict := isStrict(env)
+
 	s, err := op.a.Eval(env)
 	if err != nil {
 		return FalseLiteral, err
 	}
-	isTruthy, err := s.IsTruthy()
-	if err != nil {
-		return FalseLiteral, err
-	}
-	if isTruthy {
-		return TrueLiteral, nil
+	if strict && s.Type == document.NullValue {
+		hasNull = true
+	} else {
+		isTruthy, err := s.IsTruthy()
+		if err != nil {
+			return FalseLiteral, err
+		}
+		if isTruthy {
+			return TrueLiteral, nil
+		}
 	}
 
 	s, err = op.b.Eval(env)
 	if err != nil {
 		return FalseLiteral, err
 	}
-	isTruthy, err = s.IsTruthy()
+	if strict && s.Type == document.NullValue {
+		return NullLiteral, nil
+	}
+	isTruthy, err := s.IsTruthy()
 	if err != nil {
 		return FalseLiteral, err
 	}
@@ -78,6 +106,10 @@ func (op *OrOp) Eval(env *environment.Environment) (document.Value, error) {
 		return TrueLiteral, nil
 	}
 
+	if hasNull {
+		return NullLiteral, nil
+	}
+
 	return FalseLiteral, nil
 }
 
@@ -91,13 +123,18 @@ func Not(e Expr) Expr {
 	return &NotOp{&simpleOperator{a: e}}
 }
 
-// Eval implements the Expr interface. It evaluates e and returns true if b is falsy
+// Eval implements the Expr interface. It evaluates e and returns true if b is falsy.
+// In strict mode, NOT NULL evaluates to NULL.
 func (op *NotOp) Eval(env *environment.Environment) (document.Value, error) {
 	s, err := op.a.Eval(env)
 	if err != nil {
 		return FalseLiteral, err
 	}
 
+	if s.Type == document.NullValue && isStrict(env) {
+		return NullLiteral, nil
+	}
+
 	isTruthy, err := s.IsTruthy()
 	if err != nil {
 		return FalseLiteral, err
@@ -0,0 +1,114 @@
+package expr
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/environment"
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// MaskExpr is the expression of a field mask.
+// It is evaluated with the document being masked.
+type MaskExpr struct {
+	Expr Expr
+}
+
+// Mask creates a MaskExpr.
+func Mask(e Expr) *MaskExpr {
+	return &MaskExpr{Expr: e}
+}
+
+// Mask evaluates the expression with d and returns the masked value.
+// It implements the database.MaskExpression interface.
+func (m *MaskExpr) Mask(tx *database.Transaction, d document.Document) (document.Value, error) {
+	env := environment.New(d)
+	env.Tx = tx
+
+	return m.Expr.Eval(env)
+}
+
+func (m *MaskExpr) String() string {
+	return m.Expr.String()
+}
+
+// MaskFunc represents the mask() function.
+// It replaces every character of a text by 'X', except the given
+// number of trailing characters, which defaults to 0.
+// It returns NULL if the value is not a text.
+type MaskFunc struct {
+	Expr Expr
+	Keep Expr
+}
+
+// Eval returns the masked text.
+func (m *MaskFunc) Eval(env *environment.Environment) (document.Value, error) {
+	v, err := m.Expr.Eval(env)
+	if err != nil {
+		return NullLiteral, err
+	}
+	if v.Type != document.TextValue {
+		return NullLiteral, nil
+	}
+	s := v.V.(string)
+
+	var keep int64
+	if m.Keep != nil {
+		k, err := m.Keep.Eval(env)
+		if err != nil {
+			return NullLiteral, err
+		}
+		if k.Type != document.IntegerValue || k.V.(int64) < 0 {
+			return NullLiteral, stringutil.Errorf("mask() number of characters to keep must be a positive integer, got %v", k)
+		}
+		keep = k.V.(int64)
+	}
+
+	n := int64(utf8.RuneCountInString(s))
+	if keep >= n {
+		return v, nil
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.Repeat("X", int(n-keep)))
+	for i := int64(0); i < n-keep; i++ {
+		_, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+	}
+	b.WriteString(s)
+
+	return document.NewTextValue(b.String()), nil
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (m *MaskFunc) IsEqual(other Expr) bool {
+	o, ok := other.(*MaskFunc)
+	if !ok {
+		return false
+	}
+
+	if (m.Keep == nil) != (o.Keep == nil) {
+		return false
+	}
+
+	return Equal(m.Expr, o.Expr) && (m.Keep == nil || Equal(m.Keep, o.Keep))
+}
+
+func (m *MaskFunc) Params() []Expr {
+	if m.Keep == nil {
+		return []Expr{m.Expr}
+	}
+
+	return []Expr{m.Expr, m.Keep}
+}
+
+func (m *MaskFunc) String() string {
+	if m.Keep == nil {
+		return stringutil.Sprintf("mask(%v)", m.Expr)
+	}
+
+	return stringutil.Sprintf("mask(%v, %v)", m.Expr, m.Keep)
+}
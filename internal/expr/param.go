@@ -1,6 +1,8 @@
 package expr
 
 import (
+	"strings"
+
 	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/internal/environment"
 	"github.com/genjidb/genji/internal/stringutil"
@@ -47,3 +49,80 @@ func (p PositionalParam) IsEqual(other Expr) bool {
 func (p PositionalParam) String() string {
 	return "?"
 }
+
+// ParamWithFields is an expression which turns a parameter into a document
+// with the given fields. If the parameter is an array, its values are
+// assigned to the fields in order. If it is a document, only the given fields are kept.
+type ParamWithFields struct {
+	Fields []string
+	Param  Expr
+}
+
+// Eval evaluates the parameter and returns a document.
+func (p *ParamWithFields) Eval(env *environment.Environment) (document.Value, error) {
+	v, err := p.Param.Eval(env)
+	if err != nil {
+		return document.Value{}, err
+	}
+
+	var fb document.FieldBuffer
+
+	switch v.Type {
+	case document.ArrayValue:
+		a := v.V.(document.Array)
+		n, err := document.ArrayLength(a)
+		if err != nil {
+			return document.Value{}, err
+		}
+		if n != len(p.Fields) {
+			return document.Value{}, stringutil.Errorf("%d values for %d fields", n, len(p.Fields))
+		}
+
+		err = a.Iterate(func(i int, v document.Value) error {
+			fb.Add(p.Fields[i], v)
+			return nil
+		})
+		if err != nil {
+			return document.Value{}, err
+		}
+	case document.DocumentValue:
+		d := v.V.(document.Document)
+		for _, f := range p.Fields {
+			v, err := d.GetByField(f)
+			if err == document.ErrFieldNotFound {
+				continue
+			}
+			if err != nil {
+				return document.Value{}, err
+			}
+
+			fb.Add(f, v)
+		}
+	default:
+		return document.Value{}, stringutil.Errorf("parameter %s must be an array or a document, got %s", p.Param, v.Type)
+	}
+
+	return document.NewDocumentValue(&fb), nil
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (p *ParamWithFields) IsEqual(other Expr) bool {
+	o, ok := other.(*ParamWithFields)
+	if !ok || len(p.Fields) != len(o.Fields) {
+		return false
+	}
+
+	for i := range p.Fields {
+		if p.Fields[i] != o.Fields[i] {
+			return false
+		}
+	}
+
+	return Equal(p.Param, o.Param)
+}
+
+// String implements the stringutil.Stringer interface.
+func (p *ParamWithFields) String() string {
+	return stringutil.Sprintf("(%s) %s", strings.Join(p.Fields, ", "), p.Param)
+}
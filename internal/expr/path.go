@@ -2,6 +2,10 @@ package expr
 
 import (
 	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
 
 	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/internal/environment"
@@ -55,6 +59,120 @@ func (p Path) String() string {
 	return document.Path(p).String()
 }
 
+// A DynamicPath is a path whose fragments may be expressions,
+// evaluated for each document, e.g. a[$field] or b[i + 1].
+// Fragments evaluating to a text select a field, those evaluating
+// to an integral number select an array element.
+type DynamicPath []DynamicPathFragment
+
+// A DynamicPathFragment is a fragment of a DynamicPath.
+// If Expr is nil, the fragment is constant.
+type DynamicPathFragment struct {
+	document.PathFragment
+	Expr Expr
+}
+
+// Path returns the path if all the fragments are constants.
+func (p DynamicPath) Path() (document.Path, bool) {
+	path := make(document.Path, len(p))
+	for i := range p {
+		if p[i].Expr != nil {
+			return nil, false
+		}
+		path[i] = p[i].PathFragment
+	}
+
+	return path, true
+}
+
+// Eval evaluates the fragments of p and returns the value stored at the resulting path.
+// If one of the fragments evaluates to NULL, it returns NULL.
+// It implements the Expr interface.
+func (p DynamicPath) Eval(env *environment.Environment) (document.Value, error) {
+	path := make(Path, len(p))
+	for i := range p {
+		if p[i].Expr == nil {
+			path[i] = p[i].PathFragment
+			continue
+		}
+
+		v, err := p[i].Expr.Eval(env)
+		if err != nil {
+			return NullLiteral, err
+		}
+
+		switch v.Type {
+		case document.NullValue:
+			return NullLiteral, nil
+		case document.TextValue:
+			path[i].FieldName = v.V.(string)
+			if path[i].FieldName == "" {
+				return NullLiteral, nil
+			}
+		case document.IntegerValue, document.DoubleValue:
+			// numbers without a schema are stored as doubles,
+			// accept them as long as they have no fractional part
+			if v.Type == document.DoubleValue && v.V.(float64) != math.Trunc(v.V.(float64)) {
+				return NullLiteral, fmt.Errorf("path fragment %s must evaluate to an integer, got %s", p[i].Expr, v)
+			}
+			v, err = v.CastAsInteger()
+			if err != nil {
+				return NullLiteral, err
+			}
+			idx := v.V.(int64)
+			if idx < 0 {
+				return NullLiteral, nil
+			}
+			path[i].ArrayIndex = int(idx)
+		default:
+			return NullLiteral, fmt.Errorf("path fragment %s must evaluate to a text or an integer, got %s", p[i].Expr, v.Type)
+		}
+	}
+
+	return path.Eval(env)
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (p DynamicPath) IsEqual(other Expr) bool {
+	if other == nil {
+		return false
+	}
+
+	o, ok := other.(DynamicPath)
+	if !ok || len(o) != len(p) {
+		return false
+	}
+
+	for i := range p {
+		if p[i].PathFragment != o[i].PathFragment || !Equal(p[i].Expr, o[i].Expr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p DynamicPath) String() string {
+	var b strings.Builder
+
+	for i := range p {
+		switch {
+		case p[i].Expr != nil:
+			b.WriteString("[" + p[i].Expr.String() + "]")
+		case p[i].FieldName != "":
+			if i != 0 {
+				b.WriteRune('.')
+			}
+			b.WriteString(p[i].FieldName)
+		default:
+			b.WriteString("[" + strconv.Itoa(p[i].ArrayIndex) + "]")
+		}
+	}
+
+	return b.String()
+}
+
 // A Wildcard is an expression that iterates over all the fields of a document.
 type Wildcard struct{}
 
@@ -54,6 +54,43 @@ func TestPathExpr(t *testing.T) {
 	})
 }
 
+func TestDynamicPathExpr(t *testing.T) {
+	tests := []struct {
+		expr  string
+		res   document.Value
+		fails bool
+	}{
+		{"b[$field][0]", document.NewIntegerValue(1), false},
+		{"b[k]", document.NewIntegerValue(2), false},
+		{"c[a]", document.NewDocumentValue(document.NewFieldBuffer().Add("foo", document.NewTextValue("bar"))), false},
+		{"c[a + 1][a]", document.NewIntegerValue(2), false},
+		{"c[$idx].foo", document.NewTextValue("bar"), false},
+		{"c[a - 2]", nullLiteral, false},
+		{"c[a * 10]", nullLiteral, false},
+		{"c[d]", nullLiteral, false},
+		{"c[1.0].foo", document.NewTextValue("bar"), false},
+		{"c[1.5]", nullLiteral, true},
+		{"c[b]", nullLiteral, true},
+	}
+
+	d := document.NewFromJSON([]byte(`{
+		"a": 1,
+		"b": {"foo bar": [1, 2], "bar": 2},
+		"c": [1, {"foo": "bar"}, [1, 2]],
+		"k": "bar"
+	}`))
+	env := environment.New(d,
+		environment.Param{Name: "field", Value: "foo bar"},
+		environment.Param{Name: "idx", Value: 1},
+	)
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			testExpr(t, test.expr, env, test.res, test.fails)
+		})
+	}
+}
+
 func TestPathIsEqual(t *testing.T) {
 	tests := []struct {
 		a, b    string
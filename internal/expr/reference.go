@@ -0,0 +1,168 @@
+package expr
+
+import (
+	"github.com/genjidb/genji/document"
+	errs "github.com/genjidb/genji/errors"
+	"github.com/genjidb/genji/internal/environment"
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// RefFunc represents the ref() function.
+// It returns a reference to the document of a table with the given primary key.
+type RefFunc struct {
+	Table Expr
+	Key   Expr
+}
+
+// Eval returns a reference built from the table name and the key.
+func (r *RefFunc) Eval(env *environment.Environment) (document.Value, error) {
+	table, err := r.Table.Eval(env)
+	if err != nil {
+		return NullLiteral, err
+	}
+	if table.Type != document.TextValue {
+		return NullLiteral, stringutil.Errorf("ref() expects a table name, got %s", table.Type)
+	}
+
+	key, err := r.Key.Eval(env)
+	if err != nil {
+		return NullLiteral, err
+	}
+	if key.Type == document.NullValue {
+		return NullLiteral, nil
+	}
+
+	return document.NewReferenceValue(table.V.(string), key), nil
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (r *RefFunc) IsEqual(other Expr) bool {
+	o, ok := other.(*RefFunc)
+	if !ok {
+		return false
+	}
+
+	return Equal(r.Table, o.Table) && Equal(r.Key, o.Key)
+}
+
+func (r *RefFunc) Params() []Expr { return []Expr{r.Table, r.Key} }
+
+func (r *RefFunc) String() string {
+	return stringutil.Sprintf("ref(%v, %v)", r.Table, r.Key)
+}
+
+// DerefFunc represents the deref() function.
+// It returns the document pointed to by a reference, or NULL if it doesn't exist.
+type DerefFunc struct {
+	Expr Expr
+}
+
+// Eval evaluates the reference and fetches the document it points to.
+func (d *DerefFunc) Eval(env *environment.Environment) (document.Value, error) {
+	v, err := d.Expr.Eval(env)
+	if err != nil {
+		return NullLiteral, err
+	}
+
+	return dereference(env, v)
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (d *DerefFunc) IsEqual(other Expr) bool {
+	o, ok := other.(*DerefFunc)
+	if !ok {
+		return false
+	}
+
+	return Equal(d.Expr, o.Expr)
+}
+
+func (d *DerefFunc) Params() []Expr { return []Expr{d.Expr} }
+
+func (d *DerefFunc) String() string {
+	return stringutil.Sprintf("deref(%v)", d.Expr)
+}
+
+// A Dereference is an expression that dereferences the value of an expression
+// and extracts the value at the given path from the document it points to.
+// It is the expression produced by the -> operator, e.g. author->name.
+type Dereference struct {
+	Expr Expr
+	Path document.Path
+}
+
+// Eval dereferences the value of the expression and returns the value stored at the path.
+// It returns NULL if the document or the path doesn't exist.
+func (d Dereference) Eval(env *environment.Environment) (document.Value, error) {
+	v, err := d.Expr.Eval(env)
+	if err != nil {
+		return NullLiteral, err
+	}
+
+	v, err = dereference(env, v)
+	if err != nil || v.Type != document.DocumentValue {
+		return v, err
+	}
+
+	v, err = d.Path.GetValueFromDocument(v.V.(document.Document))
+	if err == document.ErrFieldNotFound {
+		return NullLiteral, nil
+	}
+
+	return v, err
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (d Dereference) IsEqual(other Expr) bool {
+	o, ok := other.(Dereference)
+	if !ok {
+		return false
+	}
+
+	return Equal(d.Expr, o.Expr) && d.Path.IsEqual(o.Path)
+}
+
+func (d Dereference) String() string {
+	return stringutil.Sprintf("%v->%v", d.Expr, d.Path)
+}
+
+// dereference returns the document the reference v points to.
+// Dereferencing NULL, or a reference to a document that doesn't exist, returns NULL.
+func dereference(env *environment.Environment, v document.Value) (document.Value, error) {
+	if v.Type == document.NullValue {
+		return NullLiteral, nil
+	}
+	if v.Type != document.ReferenceValue {
+		return NullLiteral, stringutil.Errorf("cannot dereference value of type %s", v.Type)
+	}
+
+	catalog := env.GetCatalog()
+	tx := env.GetTx()
+	if catalog == nil || tx == nil {
+		return NullLiteral, stringutil.Errorf("references cannot be dereferenced")
+	}
+
+	ref := v.V.(document.Reference)
+	table, err := catalog.GetTable(tx, ref.Table)
+	if err != nil {
+		return NullLiteral, err
+	}
+
+	key, err := table.EncodeValue(ref.Key)
+	if err != nil {
+		return NullLiteral, err
+	}
+
+	d, err := table.GetDocument(key)
+	if err == errs.ErrDocumentNotFound {
+		return NullLiteral, nil
+	}
+	if err != nil {
+		return NullLiteral, err
+	}
+
+	return document.NewDocumentValue(d), nil
+}
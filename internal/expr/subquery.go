@@ -0,0 +1,202 @@
+package expr
+
+import (
+	"errors"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/environment"
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// errSubqueryDone is used to stop the iteration of a subquery
+// once its result is known.
+var errSubqueryDone = errors.New("subquery done")
+
+// A Streamer returns the documents of a subquery.
+// It is implemented by the stream package, which can't be imported by this package.
+type Streamer interface {
+	// Iterate calls fn for every document of the stream.
+	// It returns nil if the stream is closed early, i.e. by a LIMIT clause.
+	Iterate(in *environment.Environment, fn func(out *environment.Environment) error) error
+	String() string
+}
+
+// A Subquery is a SELECT statement used as an expression, i.e. (SELECT max(a) FROM foo).
+// It runs the statement against the transaction of the environment every time it is evaluated
+// and returns the value of the only field of the document it returns, or NULL if it doesn't
+// return any. Used as the right operand of IN, it returns an array containing the value of
+// each document instead.
+//
+// If OuterName is set, the subquery can refer to the fields of the document being evaluated
+// by the statement using it, like OuterName.a, which makes it a correlated subquery.
+// These paths take precedence over the fields of the subquery documents with the same name.
+type Subquery struct {
+	Stream Streamer
+
+	// Name of the table of the statement using the subquery.
+	OuterName string
+}
+
+// Eval runs the statement and returns the value of the document it returns.
+// It returns an error if the statement returns more than one document.
+func (s *Subquery) Eval(env *environment.Environment) (document.Value, error) {
+	v := NullLiteral
+	n := 0
+
+	err := s.iterate(env, func(sv document.Value) error {
+		n++
+		if n > 1 {
+			return errors.New("subquery returned more than one document")
+		}
+
+		v = sv
+		return nil
+	})
+	if err != nil {
+		return NullLiteral, err
+	}
+
+	return v, nil
+}
+
+// EvalArray runs the statement and returns the values of all the documents it returns
+// as an array.
+func (s *Subquery) EvalArray(env *environment.Environment) (document.Value, error) {
+	vb := document.NewValueBuffer()
+
+	err := s.iterate(env, func(v document.Value) error {
+		vb.Append(v)
+		return nil
+	})
+	if err != nil {
+		return NullLiteral, err
+	}
+
+	return document.NewArrayValue(vb), nil
+}
+
+// iterate runs the statement and calls fn with the value of every document it returns.
+func (s *Subquery) iterate(env *environment.Environment, fn func(v document.Value) error) error {
+	newEnv := s.newEnv(env)
+
+	return s.Stream.Iterate(newEnv, func(out *environment.Environment) error {
+		d, ok := out.GetDocument()
+		if !ok {
+			return nil
+		}
+
+		v, err := subqueryValue(d)
+		if err != nil {
+			return err
+		}
+
+		return fn(v)
+	})
+}
+
+// newEnv returns the environment in which the statement is run.
+// The document of env, if any, is bound to OuterName.
+func (s *Subquery) newEnv(env *environment.Environment) *environment.Environment {
+	var newEnv environment.Environment
+	newEnv.SetOuter(env)
+
+	if s.OuterName != "" {
+		if d, ok := env.GetDocument(); ok {
+			newEnv.Set(s.OuterName, document.NewDocumentValue(d))
+		}
+	}
+
+	return &newEnv
+}
+
+// subqueryValue returns the value of the only field of d.
+func subqueryValue(d document.Document) (document.Value, error) {
+	var v document.Value
+	var n int
+
+	err := d.Iterate(func(field string, value document.Value) error {
+		n++
+		if n > 1 {
+			return errors.New("subquery must return documents with a single field")
+		}
+
+		v = value
+		return nil
+	})
+	if err != nil {
+		return NullLiteral, err
+	}
+	if n == 0 {
+		return NullLiteral, nil
+	}
+
+	return v, nil
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (s *Subquery) IsEqual(other Expr) bool {
+	o, ok := other.(*Subquery)
+	if !ok {
+		return false
+	}
+
+	return s.Stream.String() == o.Stream.String()
+}
+
+func (s *Subquery) String() string {
+	return stringutil.Sprintf("(%s)", s.Stream)
+}
+
+// subqueryArray evaluates a subquery to the array of its values.
+type subqueryArray struct {
+	*Subquery
+}
+
+func (s subqueryArray) Eval(env *environment.Environment) (document.Value, error) {
+	return s.EvalArray(env)
+}
+
+// Exists is an expression that evaluates to true if the subquery
+// returns at least one document. It stops the subquery as soon as
+// a document is returned.
+type Exists struct {
+	Subquery *Subquery
+}
+
+// Eval runs the subquery until it returns a document.
+func (e Exists) Eval(env *environment.Environment) (document.Value, error) {
+	found := false
+	err := e.Subquery.Stream.Iterate(e.Subquery.newEnv(env), func(out *environment.Environment) error {
+		if _, ok := out.GetDocument(); !ok {
+			return nil
+		}
+
+		found = true
+		return errSubqueryDone
+	})
+	if err != nil && err != errSubqueryDone {
+		return NullLiteral, err
+	}
+
+	if found {
+		return TrueLiteral, nil
+	}
+
+	return FalseLiteral, nil
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (e Exists) IsEqual(other Expr) bool {
+	o, ok := other.(Exists)
+	if !ok {
+		return false
+	}
+
+	return e.Subquery.IsEqual(o.Subquery)
+}
+
+func (e Exists) String() string {
+	return stringutil.Sprintf("EXISTS %s", e.Subquery)
+}
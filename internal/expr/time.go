@@ -0,0 +1,265 @@
+package expr
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/environment"
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// layouts accepted for timestamps stored as text.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// units accepted by intervals written as "<number> <unit>", such as "15 minutes".
+var intervalUnits = map[string]time.Duration{
+	"microsecond": time.Microsecond,
+	"millisecond": time.Millisecond,
+	"second":      time.Second,
+	"minute":      time.Minute,
+	"hour":        time.Hour,
+	"day":         24 * time.Hour,
+	"week":        7 * 24 * time.Hour,
+}
+
+// parseTimestamp parses a timestamp stored as text.
+func parseTimestamp(s string) (time.Time, error) {
+	for _, layout := range timestampLayouts {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, stringutil.Errorf("invalid timestamp %q", s)
+}
+
+// parseInterval parses an interval written either using the Go duration
+// syntax, such as "1h30m", or as "<number> <unit>", such as "15 minutes".
+func parseInterval(s string) (time.Duration, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+
+	fields := strings.Fields(s)
+	if len(fields) == 2 {
+		n, err := strconv.ParseInt(fields[0], 10, 64)
+		unit, ok := intervalUnits[strings.TrimSuffix(strings.ToLower(fields[1]), "s")]
+		if err == nil && ok {
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	return 0, stringutil.Errorf("invalid interval %q", s)
+}
+
+// DateBinFunc represents the date_bin() function and its time_bucket() alias.
+// It truncates a timestamp to the start of the bucket of size Stride containing it,
+// buckets being aligned on Origin, or on the Unix epoch if Origin is nil.
+// Timestamps stored as text must use the RFC 3339 format, and the stride must be
+// an interval such as '15 minutes' or '1h'. The bucket is returned as a text, in UTC.
+// Timestamps stored as numbers, such as Unix timestamps, are bucketed using a numeric
+// stride expressed in the same unit.
+type DateBinFunc struct {
+	Name   string
+	Stride Expr
+	Source Expr
+	Origin Expr
+}
+
+func dateBinFuncBuilder(name string) func(args ...Expr) (Expr, error) {
+	return func(args ...Expr) (Expr, error) {
+		switch len(args) {
+		case 2:
+			return &DateBinFunc{Name: name, Stride: args[0], Source: args[1]}, nil
+		case 3:
+			return &DateBinFunc{Name: name, Stride: args[0], Source: args[1], Origin: args[2]}, nil
+		}
+		return nil, stringutil.Errorf("%s() takes 2 or 3 arguments", name)
+	}
+}
+
+// Eval returns the start of the bucket containing the source timestamp.
+// It returns NULL if the source is NULL.
+func (d *DateBinFunc) Eval(env *environment.Environment) (document.Value, error) {
+	src, err := d.Source.Eval(env)
+	if err != nil || src.Type == document.NullValue {
+		return NullLiteral, err
+	}
+
+	stride, err := d.Stride.Eval(env)
+	if err != nil {
+		return NullLiteral, err
+	}
+
+	switch {
+	case src.Type == document.TextValue:
+		t, err := parseTimestamp(src.V.(string))
+		if err != nil {
+			return NullLiteral, stringutil.Errorf("%s(): %w", d.Name, err)
+		}
+		step, err := d.interval(stride)
+		if err != nil {
+			return NullLiteral, err
+		}
+		origin, err := d.originTime(env)
+		if err != nil {
+			return NullLiteral, err
+		}
+
+		// compute the offset in nanoseconds, rounding towards the past
+		// for timestamps before the origin.
+		diff := t.Sub(origin)
+		bucket := diff - diff%step
+		if diff%step < 0 {
+			bucket -= step
+		}
+		return document.NewTextValue(origin.Add(bucket).UTC().Format(time.RFC3339Nano)), nil
+	case src.Type.IsNumber():
+		if !stride.Type.IsNumber() {
+			return NullLiteral, stringutil.Errorf("%s(): the stride of a numeric timestamp must be a number, got %s", d.Name, stride)
+		}
+		origin := document.NewIntegerValue(0)
+		if d.Origin != nil {
+			origin, err = d.Origin.Eval(env)
+			if err != nil {
+				return NullLiteral, err
+			}
+			if !origin.Type.IsNumber() {
+				return NullLiteral, stringutil.Errorf("%s(): the origin of a numeric timestamp must be a number, got %s", d.Name, origin)
+			}
+		}
+
+		return d.binNumber(src, stride, origin)
+	}
+
+	return NullLiteral, stringutil.Errorf("%s(): timestamp must be a text or a number, got %s", d.Name, src)
+}
+
+// binNumber truncates a numeric timestamp. If all the values are integers,
+// the result is an integer.
+func (d *DateBinFunc) binNumber(src, stride, origin document.Value) (document.Value, error) {
+	if src.Type == document.IntegerValue && stride.Type == document.IntegerValue && origin.Type == document.IntegerValue {
+		s, o, n := stride.V.(int64), origin.V.(int64), src.V.(int64)
+		if s <= 0 {
+			return NullLiteral, stringutil.Errorf("%s(): stride must be greater than zero", d.Name)
+		}
+		diff := n - o
+		bucket := diff - diff%s
+		if diff%s < 0 {
+			bucket -= s
+		}
+		return document.NewIntegerValue(o + bucket), nil
+	}
+
+	var f [3]float64
+	for i, v := range []document.Value{src, stride, origin} {
+		v, err := v.CastAsDouble()
+		if err != nil {
+			return NullLiteral, err
+		}
+		f[i] = v.V.(float64)
+	}
+	if f[1] <= 0 {
+		return NullLiteral, stringutil.Errorf("%s(): stride must be greater than zero", d.Name)
+	}
+
+	return document.NewDoubleValue(f[2] + math.Floor((f[0]-f[2])/f[1])*f[1]), nil
+}
+
+// Next returns the start of the bucket following the given one.
+// It is used to enumerate the buckets between two values returned by Eval.
+func (d *DateBinFunc) Next(env *environment.Environment, bucket document.Value) (document.Value, error) {
+	stride, err := d.Stride.Eval(env)
+	if err != nil {
+		return NullLiteral, err
+	}
+
+	switch {
+	case bucket.Type == document.TextValue:
+		t, err := parseTimestamp(bucket.V.(string))
+		if err != nil {
+			return NullLiteral, stringutil.Errorf("%s(): %w", d.Name, err)
+		}
+		step, err := d.interval(stride)
+		if err != nil {
+			return NullLiteral, err
+		}
+		return document.NewTextValue(t.Add(step).UTC().Format(time.RFC3339Nano)), nil
+	case bucket.Type.IsNumber() && stride.Type.IsNumber():
+		return Add(LiteralValue(bucket), LiteralValue(stride)).Eval(env)
+	}
+
+	return NullLiteral, stringutil.Errorf("%s(): invalid bucket %s", d.Name, bucket)
+}
+
+func (d *DateBinFunc) interval(stride document.Value) (time.Duration, error) {
+	if stride.Type != document.TextValue {
+		return 0, stringutil.Errorf("%s(): the stride of a text timestamp must be an interval, got %s", d.Name, stride)
+	}
+
+	step, err := parseInterval(stride.V.(string))
+	if err != nil {
+		return 0, stringutil.Errorf("%s(): %w", d.Name, err)
+	}
+	if step <= 0 {
+		return 0, stringutil.Errorf("%s(): stride must be greater than zero", d.Name)
+	}
+
+	return step, nil
+}
+
+func (d *DateBinFunc) originTime(env *environment.Environment) (time.Time, error) {
+	if d.Origin == nil {
+		return time.Unix(0, 0).UTC(), nil
+	}
+
+	v, err := d.Origin.Eval(env)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if v.Type != document.TextValue {
+		return time.Time{}, stringutil.Errorf("%s(): the origin of a text timestamp must be a text, got %s", d.Name, v)
+	}
+
+	t, err := parseTimestamp(v.V.(string))
+	if err != nil {
+		return time.Time{}, stringutil.Errorf("%s(): %w", d.Name, err)
+	}
+
+	return t, nil
+}
+
+// IsEqual compares this expression with the other expression and returns
+// true if they are equal.
+func (d *DateBinFunc) IsEqual(other Expr) bool {
+	o, ok := other.(*DateBinFunc)
+	if !ok {
+		return false
+	}
+
+	return d.Name == o.Name && Equal(d.Stride, o.Stride) && Equal(d.Source, o.Source) && Equal(d.Origin, o.Origin)
+}
+
+func (d *DateBinFunc) Params() []Expr {
+	if d.Origin == nil {
+		return []Expr{d.Stride, d.Source}
+	}
+
+	return []Expr{d.Stride, d.Source, d.Origin}
+}
+
+func (d *DateBinFunc) String() string {
+	if d.Origin == nil {
+		return stringutil.Sprintf("%s(%v, %v)", d.Name, d.Stride, d.Source)
+	}
+
+	return stringutil.Sprintf("%s(%v, %v, %v)", d.Name, d.Stride, d.Source, d.Origin)
+}
@@ -0,0 +1,208 @@
+package planner
+
+import (
+	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/expr"
+	"github.com/genjidb/genji/internal/sql/scanner"
+	"github.com/genjidb/genji/internal/stream"
+)
+
+const (
+	// number of documents assumed for a table without statistics
+	// or for a source whose size cannot be determined.
+	defaultTableSize = 1000
+	// fraction of the documents matching an equality.
+	eqSelectivity = 0.1
+	// fraction of the documents matching a range, i.e. a > 10.
+	rangeSelectivity = 0.3
+	// fraction of the documents matching any other condition.
+	defaultSelectivity = 0.5
+	// fraction of the documents kept by a GROUP BY clause.
+	groupSelectivity = 0.1
+)
+
+// An OperatorEstimate is the estimated number of documents
+// returned by an operator of a stream.
+type OperatorEstimate struct {
+	Operator  stream.Operator
+	Documents int64
+}
+
+// EstimateOperators estimates the number of documents returned by every operator
+// of the stream, in order. The estimates rely on the statistics collected by ANALYZE:
+// tables whose indexes were not analyzed are assumed to contain 1000 documents.
+func EstimateOperators(s *stream.Stream, catalog database.Catalog) []OperatorEstimate {
+	var estimates []OperatorEstimate
+	var n int64
+	var grouped bool
+
+	for op := s.First(); op != nil; op = op.GetNext() {
+		switch t := op.(type) {
+		case *stream.SeqScanOperator:
+			n = tableSize(catalog, t.TableName)
+		case *stream.PkScanOperator:
+			n = estimatePkScan(catalog, t)
+		case *stream.IndexScanOperator:
+			n = estimateIndexScan(catalog, t)
+		case *stream.ExprsOperator:
+			n = int64(len(t.Exprs))
+		case *stream.DocumentsOperator:
+			n = int64(len(t.Docs))
+		case *stream.ConcatOperator:
+			n = Estimate(t.S1, catalog) + Estimate(t.S2, catalog)
+		case *stream.JoinOperator:
+			n = max64(Estimate(t.Left, catalog), Estimate(t.Right, catalog))
+		case *stream.FilterOperator:
+			n = applySelectivity(n, conditionSelectivity(t.E))
+		case *stream.TakeOperator:
+			if t.N < n {
+				n = t.N
+			}
+		case *stream.SkipOperator:
+			n = max64(n-t.N, 0)
+		case *stream.GroupByOperator:
+			grouped = true
+		case *stream.HashAggregateOperator:
+			if grouped {
+				n = applySelectivity(n, groupSelectivity)
+			} else {
+				n = 1
+			}
+		default:
+			// operators without a previous operator produce documents,
+			// the others are assumed to return every document they receive.
+			if op.GetPrev() == nil {
+				n = defaultTableSize
+			}
+		}
+
+		estimates = append(estimates, OperatorEstimate{Operator: op, Documents: n})
+	}
+
+	return estimates
+}
+
+// Estimate returns the estimated number of documents returned by the stream.
+// See EstimateOperators for details.
+func Estimate(s *stream.Stream, catalog database.Catalog) int64 {
+	estimates := EstimateOperators(s, catalog)
+	if len(estimates) == 0 {
+		return 0
+	}
+
+	return estimates[len(estimates)-1].Documents
+}
+
+// tableSize returns the number of documents of a table as reported
+// by the statistics of its indexes, or defaultTableSize.
+func tableSize(catalog database.Catalog, tableName string) int64 {
+	if n, ok := estimateTableSize(catalog, tableName); ok {
+		return n
+	}
+
+	return defaultTableSize
+}
+
+func estimatePkScan(catalog database.Catalog, op *stream.PkScanOperator) int64 {
+	size := tableSize(catalog, op.TableName)
+	if len(op.Ranges) == 0 {
+		return size
+	}
+
+	var n int64
+	for _, rng := range op.Ranges {
+		if rng.Exact {
+			// primary keys are unique
+			n++
+			continue
+		}
+
+		n += applySelectivity(size, rangeSelectivity)
+	}
+
+	return min64(n, size)
+}
+
+func estimateIndexScan(catalog database.Catalog, op *stream.IndexScanOperator) int64 {
+	info, err := catalog.GetIndexInfo(op.IndexName)
+	if err != nil {
+		return defaultTableSize
+	}
+
+	size := tableSize(catalog, info.TableName)
+	if len(op.Ranges) == 0 {
+		return size
+	}
+
+	var n int64
+	for _, rng := range op.Ranges {
+		switch {
+		case rng.Exact && info.Unique && len(rng.Min) == len(info.Paths):
+			n++
+		case rng.Exact:
+			n += applySelectivity(size, eqSelectivity)
+		default:
+			n += applySelectivity(size, rangeSelectivity)
+		}
+	}
+
+	return min64(n, size)
+}
+
+// conditionSelectivity returns the estimated fraction of the documents
+// for which the condition is truthy.
+func conditionSelectivity(e expr.Expr) float64 {
+	if p, ok := e.(expr.Parentheses); ok {
+		return conditionSelectivity(p.E)
+	}
+
+	op, ok := e.(expr.Operator)
+	if !ok {
+		return defaultSelectivity
+	}
+
+	switch op.Token() {
+	case scanner.AND:
+		return conditionSelectivity(op.LeftHand()) * conditionSelectivity(op.RightHand())
+	case scanner.OR:
+		s := conditionSelectivity(op.LeftHand()) + conditionSelectivity(op.RightHand())
+		if s > 1 {
+			return 1
+		}
+		return s
+	case scanner.EQ, scanner.IN, scanner.IS:
+		return eqSelectivity
+	case scanner.GT, scanner.GTE, scanner.LT, scanner.LTE, scanner.BETWEEN:
+		return rangeSelectivity
+	}
+
+	return defaultSelectivity
+}
+
+// applySelectivity returns the fraction of n, rounded to the nearest integer.
+// It only returns zero if n is zero: a filter is never assumed to drop every document.
+func applySelectivity(n int64, selectivity float64) int64 {
+	if n == 0 {
+		return 0
+	}
+
+	m := int64(float64(n)*selectivity + 0.5)
+	if m < 1 {
+		return 1
+	}
+	return m
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,67 @@
+package planner_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/internal/environment"
+	"github.com/genjidb/genji/internal/planner"
+	"github.com/genjidb/genji/internal/sql/parser"
+	st "github.com/genjidb/genji/internal/stream"
+	"github.com/genjidb/genji/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimate(t *testing.T) {
+	db, tx, cleanup := testutil.NewTestTx(t)
+	defer cleanup()
+	testutil.MustExec(t, db, tx, `
+		CREATE TABLE foo(k INTEGER PRIMARY KEY, a INTEGER, b INTEGER);
+		CREATE TABLE bar;
+		CREATE INDEX idx_foo_a ON foo(a);
+		CREATE UNIQUE INDEX idx_foo_b ON foo(b);
+	`)
+	for i := 0; i < 100; i++ {
+		testutil.MustExec(t, db, tx, "INSERT INTO foo (k, a, b) VALUES (?, ?, ?)", environment.Param{Value: i}, environment.Param{Value: i % 10}, environment.Param{Value: i})
+	}
+
+	tests := []struct {
+		name     string
+		s        *st.Stream
+		expected int64
+	}{
+		{"seqScan", st.New(st.SeqScan("foo")), 100},
+		{"seqScan without statistics", st.New(st.SeqScan("bar")), 1000},
+		{"pkScan exact", st.New(st.PkScan("foo", st.ValueRange{Min: testutil.IntegerValue(1), Exact: true})), 1},
+		{"pkScan range", st.New(st.PkScan("foo", st.ValueRange{Min: testutil.IntegerValue(1)})), 30},
+		{"indexScan exact", st.New(st.IndexScan("idx_foo_a", st.IndexRange{Min: testutil.ExprList(t, `[1]`), Exact: true})), 10},
+		{"unique indexScan exact", st.New(st.IndexScan("idx_foo_b", st.IndexRange{Min: testutil.ExprList(t, `[1]`), Exact: true})), 1},
+		{"filter =", st.New(st.SeqScan("foo")).Pipe(st.Filter(parser.MustParseExpr("c = 1"))), 10},
+		{"filter >", st.New(st.SeqScan("foo")).Pipe(st.Filter(parser.MustParseExpr("c > 1"))), 30},
+		{"filter AND", st.New(st.SeqScan("foo")).Pipe(st.Filter(parser.MustParseExpr("c = 1 AND d > 1"))), 3},
+		{"filter OR", st.New(st.SeqScan("foo")).Pipe(st.Filter(parser.MustParseExpr("c = 1 OR d > 1"))), 40},
+		{"take", st.New(st.SeqScan("foo")).Pipe(st.Take(5)), 5},
+		{"skip", st.New(st.SeqScan("foo")).Pipe(st.Skip(95)).Pipe(st.Take(10)), 5},
+		{"aggregate", st.New(st.SeqScan("foo")).Pipe(st.HashAggregate()), 1},
+		{"group by", st.New(st.SeqScan("foo")).Pipe(st.GroupBy(parser.MustParseExpr("a"))).Pipe(st.HashAggregate()), 10},
+		{"concat", st.New(st.Concat(st.New(st.SeqScan("foo")), st.New(st.SeqScan("bar")))), 1100},
+		{"writer", st.New(st.SeqScan("foo")).Pipe(st.TableDelete("foo")), 100},
+	}
+
+	testutil.MustExec(t, db, tx, "ANALYZE")
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, planner.Estimate(test.s, db.Catalog))
+		})
+	}
+
+	t.Run("operators", func(t *testing.T) {
+		s := st.New(st.SeqScan("foo")).Pipe(st.Filter(parser.MustParseExpr("c > 1"))).Pipe(st.Take(5))
+
+		var got []int64
+		for _, e := range planner.EstimateOperators(s, db.Catalog) {
+			got = append(got, e.Documents)
+		}
+		require.Equal(t, []int64{100, 30, 5}, got)
+	})
+}
@@ -1,22 +1,53 @@
 package planner
 
 import (
+	"sort"
+	"sync"
+	"unicode"
+
 	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/internal/database"
 	"github.com/genjidb/genji/internal/environment"
 	"github.com/genjidb/genji/internal/expr"
+	"github.com/genjidb/genji/internal/expr/glob"
 	"github.com/genjidb/genji/internal/sql/scanner"
 	"github.com/genjidb/genji/internal/stream"
 	"github.com/genjidb/genji/internal/stringutil"
 )
 
-var optimizerRules = []func(s *stream.Stream, catalog database.Catalog) (*stream.Stream, error){
+// A Rule rewrites a stream into an optimized one.
+// Depending on the rule, the stream may be modified in place or
+// replaced by a new one.
+type Rule func(s *stream.Stream, catalog database.Catalog) (*stream.Stream, error)
+
+var optimizerRules = []Rule{
 	SplitANDConditionRule,
 	RemoveUnnecessaryProjection,
 	RemoveUnnecessaryDistinctNodeRule,
 	RemoveUnnecessaryFilterNodesRule,
 	UseIndexBasedOnFilterNodeRule,
 	PrecalculateExprRule,
+	ScanKeysOnlyRule,
+	SelectJoinBuildSideRule,
+}
+
+var (
+	customRulesMu sync.RWMutex
+	customRules   []Rule
+)
+
+// RegisterRule adds a rule to the list of optimization rules.
+// Registered rules are run in registration order, after the built-in rules.
+// It is meant to be called during initialization, before any query is run.
+func RegisterRule(rule Rule) {
+	if rule == nil {
+		panic("planner: RegisterRule rule is nil")
+	}
+
+	customRulesMu.Lock()
+	defer customRulesMu.Unlock()
+
+	customRules = append(customRules, rule)
 }
 
 // Optimize takes a tree, applies a list of optimization rules
@@ -24,7 +55,12 @@ var optimizerRules = []func(s *stream.Stream, catalog database.Catalog) (*stream
 // Depending on the rule, the tree may be modified in place or
 // replaced by a new one.
 func Optimize(s *stream.Stream, catalog database.Catalog) (*stream.Stream, error) {
-	var err error
+	// views are expanded first, their queries are then optimized
+	// like the ones of common table expressions.
+	s, err := ExpandViewRule(s, catalog)
+	if err != nil {
+		return nil, err
+	}
 
 	if firstNode, ok := s.First().(*stream.ConcatOperator); ok {
 		// If the first operation is a concat, optimize both streams individually.
@@ -41,19 +77,154 @@ func Optimize(s *stream.Stream, catalog database.Catalog) (*stream.Stream, error
 		return s, nil
 	}
 
-	for _, rule := range optimizerRules {
-		s, err = rule(s, catalog)
+	if cte, ok := s.First().(*stream.CTEScanOperator); ok {
+		// If the first operation reads a common table expression,
+		// optimize its streams individually, then optimize the rest of the stream.
+		err = optimizeCTE(cte.CTE, catalog)
 		if err != nil {
 			return nil, err
 		}
-		if s.Op == nil {
-			break
+	}
+
+	if join, ok := s.First().(*stream.JoinOperator); ok {
+		// If the first operation is a join, optimize both sides individually,
+		// then optimize the rest of the stream.
+		join.Left, err = Optimize(join.Left, catalog)
+		if err != nil {
+			return nil, err
+		}
+		join.Right, err = Optimize(join.Right, catalog)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = optimizeSubqueries(s, catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	customRulesMu.RLock()
+	rules := customRules
+	customRulesMu.RUnlock()
+
+	for _, list := range [][]Rule{optimizerRules, rules} {
+		for _, rule := range list {
+			s, err = rule(s, catalog)
+			if err != nil {
+				return nil, err
+			}
+			if s.Op == nil {
+				return s, nil
+			}
+		}
+	}
+
+	return s, nil
+}
+
+// optimizeCTE optimizes the streams of a common table expression.
+func optimizeCTE(cte *stream.CommonTableExpr, catalog database.Catalog) error {
+	var err error
+
+	cte.Stream, err = Optimize(cte.Stream, catalog)
+	if err != nil {
+		return err
+	}
+
+	if cte.Recursive != nil {
+		cte.Recursive, err = Optimize(cte.Recursive, catalog)
+	}
+
+	return err
+}
+
+// optimizeSubqueries optimizes the streams of the subqueries
+// used by the operators of s.
+func optimizeSubqueries(s *stream.Stream, catalog database.Catalog) error {
+	var err error
+
+	for n := s.Op; n != nil; n = n.GetPrev() {
+		var exprs []expr.Expr
+		switch t := n.(type) {
+		case *stream.FilterOperator:
+			exprs = []expr.Expr{t.E}
+		case *stream.ProjectOperator:
+			exprs = t.Exprs
+		case *stream.SetOperator:
+			exprs = []expr.Expr{t.E}
 		}
+
+		for _, e := range exprs {
+			expr.Walk(e, func(e expr.Expr) bool {
+				sq, ok := e.(*expr.Subquery)
+				if !ok {
+					return true
+				}
+
+				ss, ok := sq.Stream.(*stream.SubqueryStream)
+				if !ok {
+					return true
+				}
+
+				ss.Stream, err = Optimize(ss.Stream, catalog)
+				return err == nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// SelectJoinBuildSideRule selects the side of a join which is loaded in memory:
+// the side with the fewest documents is loaded, the other one is iterated over.
+// The number of documents of a table is estimated using the statistics collected
+// by ANALYZE on its indexes. If the size of a side is unknown, the right side is loaded.
+// Example, if a is smaller than b:
+//   this:
+//     hashJoin(seqScan(a), seqScan(b), a.x = b.y)
+//   becomes this:
+//     hashJoin(seqScan(a), seqScan(b), a.x = b.y, build left)
+func SelectJoinBuildSideRule(s *stream.Stream, catalog database.Catalog) (*stream.Stream, error) {
+	join, ok := s.First().(*stream.JoinOperator)
+	if !ok {
+		return s, nil
 	}
 
+	left, lok := estimateTableSize(catalog, join.LeftName)
+	right, rok := estimateTableSize(catalog, join.RightName)
+	join.BuildLeft = lok && rok && left < right
+
 	return s, nil
 }
 
+// estimateTableSize returns the number of documents of a table,
+// as reported by the statistics of its indexes, if any.
+func estimateTableSize(catalog database.Catalog, tableName string) (int64, bool) {
+	if tableName == "" {
+		return 0, false
+	}
+
+	var size int64
+	var found bool
+	for _, name := range catalog.ListIndexes(tableName) {
+		info, err := catalog.GetIndexInfo(name)
+		if err != nil || info.Statistics == nil {
+			continue
+		}
+
+		if !found || info.Statistics.Count > size {
+			size = info.Statistics.Count
+		}
+		found = true
+	}
+
+	return size, found
+}
+
 // SplitANDConditionRule splits any filter node whose condition
 // is one or more AND operators into one or more filter nodes.
 // The condition won't be split if the expression tree contains an OR
@@ -112,6 +283,60 @@ func splitANDExpr(cond expr.Expr) (exprs []expr.Expr) {
 	return
 }
 
+// ScanKeysOnlyRule tells the operator reading the table that only the keys of the documents
+// are needed, if the operator following it never reads their content. The documents are then
+// read lazily, which allows the engine to avoid fetching them.
+// It applies to COUNT(*) aggregations and pk() projections.
+// Examples:
+//   seqScan(foo) | hashAggregate(COUNT(*))
+//   indexScan("idx_foo_a", [1, 2]) | project(pk())
+func ScanKeysOnlyRule(s *stream.Stream, _ database.Catalog) (*stream.Stream, error) {
+	firstNode := s.First()
+	if firstNode == nil || !onlyNeedsKeys(firstNode.GetNext()) {
+		return s, nil
+	}
+
+	switch t := firstNode.(type) {
+	case *stream.SeqScanOperator:
+		t.KeysOnly = true
+	case *stream.PkScanOperator:
+		t.KeysOnly = true
+	case *stream.IndexScanOperator:
+		t.KeysOnly = true
+	}
+
+	return s, nil
+}
+
+// onlyNeedsKeys returns true if the operator never reads
+// the content of the documents of its input.
+func onlyNeedsKeys(op stream.Operator) bool {
+	switch t := op.(type) {
+	case *stream.HashAggregateOperator:
+		for _, b := range t.Builders {
+			if c, ok := b.(*expr.CountFunc); !ok || !c.Wildcard {
+				return false
+			}
+		}
+
+		return true
+	case *stream.ProjectOperator:
+		for _, e := range t.Exprs {
+			if ne, ok := e.(*expr.NamedExpr); ok {
+				e = ne.Expr
+			}
+
+			if _, ok := e.(*expr.PKFunc); !ok {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
 // PrecalculateExprRule evaluates any constant sub-expression that can be evaluated
 // before running the query and replaces it by the result of the evaluation.
 // The result of constant sub-expressions, like "3 + 4", is always the same and thus
@@ -212,6 +437,17 @@ func precalculateExpr(e expr.Expr) (expr.Expr, error) {
 		t.SetLeftHandExpr(lh)
 		t.SetRightHandExpr(rh)
 
+		// the BETWEEN operator has a third operand, which must also be a literal
+		if bt, ok := t.(*expr.BetweenOperator); ok {
+			bt.X, err = precalculateExpr(bt.X)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := bt.X.(expr.LiteralValue); !ok {
+				return e, nil
+			}
+		}
+
 		_, leftIsLit := lh.(expr.LiteralValue)
 		_, rightIsLit := rh.(expr.LiteralValue)
 		// if both operands are literals, we can precalculate them now
@@ -221,6 +457,18 @@ func precalculateExpr(e expr.Expr) (expr.Expr, error) {
 			if err != nil {
 				panic(err)
 			}
+
+			// the result of comparisons between incompatible types or with NULL
+			// depends on the strict mode of the transaction running the query.
+			// In that case, keep the expression to evaluate it at run time.
+			sv, err := t.Eval(&environment.Environment{Tx: &database.Transaction{Strict: true}})
+			if err != nil || sv.Type != v.Type {
+				return e, nil
+			}
+			if ok, err := sv.IsEqual(v); !ok || err != nil {
+				return e, nil
+			}
+
 			// we replace this expression with the result of its evaluation
 			return expr.LiteralValue(v), nil
 		}
@@ -283,12 +531,13 @@ func RemoveUnnecessaryFilterNodesRule(s *stream.Stream, _ database.Catalog) (*st
 }
 
 // RemoveUnnecessaryProjection removes any project node whose
-// expression is a wildcard only.
+// expression is a wildcard only, unless it returns the documents
+// written by the previous node, i.e. RETURNING *.
 func RemoveUnnecessaryProjection(s *stream.Stream, _ database.Catalog) (*stream.Stream, error) {
 	n := s.Op
 
 	for n != nil {
-		if p, ok := n.(*stream.ProjectOperator); ok {
+		if p, ok := n.(*stream.ProjectOperator); ok && !isTableWriter(n.GetPrev()) {
 			if len(p.Exprs) == 1 {
 				if _, ok := p.Exprs[0].(expr.Wildcard); ok {
 					prev := n.GetPrev()
@@ -304,6 +553,16 @@ func RemoveUnnecessaryProjection(s *stream.Stream, _ database.Catalog) (*stream.
 	return s, nil
 }
 
+// isTableWriter reports whether op inserts, replaces or deletes documents.
+func isTableWriter(op stream.Operator) bool {
+	switch op.(type) {
+	case *stream.TableInsertOperator, *stream.TableReplaceOperator, *stream.TableDeleteOperator:
+		return true
+	}
+
+	return false
+}
+
 // RemoveUnnecessaryDistinctNodeRule removes any Dedup nodes
 // where projection is already unique.
 func RemoveUnnecessaryDistinctNodeRule(s *stream.Stream, catalog database.Catalog) (*stream.Stream, error) {
@@ -430,6 +689,7 @@ func UseIndexBasedOnFilterNodeRule(s *stream.Stream, catalog database.Catalog) (
 
 	var candidates []*candidate
 	var filterNodes []filterNode
+	var likeNodes []likeFilterNode
 
 	// then we collect all usable filter nodes, in order to see what index (or PK) can be
 	// used to replace them.
@@ -439,6 +699,13 @@ func UseIndexBasedOnFilterNodeRule(s *stream.Stream, catalog database.Catalog) (
 				continue
 			}
 
+			if like, ok := f.E.(*expr.LikeOperator); ok {
+				if lfn, ok := likeOperatorCanUseIndex(like); ok {
+					likeNodes = append(likeNodes, lfn)
+				}
+				continue
+			}
+
 			op, ok := f.E.(expr.Operator)
 			if !ok {
 				continue
@@ -515,6 +782,10 @@ outer:
 		if err != nil {
 			return nil, err
 		}
+		// incomplete indexes cannot be read
+		if idxInfo.Rebuilding {
+			continue
+		}
 		// order filter nodes by how the index paths order them; if absent, nil in still inserted
 		found := make([]*filterNode, len(idxInfo.Paths))
 		for i, path := range idxInfo.Paths {
@@ -603,6 +874,26 @@ outer:
 		candidates = append(candidates, &cd)
 	}
 
+	// LIKE operators whose pattern starts with a constant prefix can read
+	// the range of values of an index starting with that prefix.
+	// Their filter nodes are kept to match the rest of the pattern.
+	for _, lfn := range likeNodes {
+		for _, idxName := range catalog.ListIndexes(st.TableName) {
+			idxInfo, err := catalog.GetIndexInfo(idxName)
+			if err != nil {
+				return nil, err
+			}
+			if idxInfo.Rebuilding {
+				continue
+			}
+
+			cd := likeCandidate(idxInfo, lfn)
+			if cd != nil {
+				candidates = append(candidates, cd)
+			}
+		}
+	}
+
 	// determine which index is the most interesting and replace it in the tree.
 	// we will assume that unique indexes are more interesting than list indexes
 	// because they usually have less elements.
@@ -676,6 +967,118 @@ type candidate struct {
 	priority int
 }
 
+const (
+	// maxLikePrefixSelectivity is the fraction of the entries of an index
+	// above which a sequential scan is preferred over reading the values
+	// matching the prefix of a LIKE pattern.
+	maxLikePrefixSelectivity = 0.3
+
+	// maxLikePrefixRanges is the maximum number of ranges generated
+	// to read the case variants of the prefix of a LIKE pattern.
+	maxLikePrefixRanges = 8
+)
+
+type likeFilterNode struct {
+	path   document.Path
+	prefix string
+}
+
+// likeOperatorCanUseIndex returns whether the operator is of the form
+// path LIKE 'prefix%...', with a non empty prefix.
+func likeOperatorCanUseIndex(op *expr.LikeOperator) (likeFilterNode, bool) {
+	p, ok := op.LeftHand().(expr.Path)
+	if !ok {
+		return likeFilterNode{}, false
+	}
+
+	lv, ok := op.RightHand().(expr.LiteralValue)
+	if !ok || lv.Type != document.TextValue {
+		return likeFilterNode{}, false
+	}
+
+	prefix := glob.LikePrefix(lv.V.(string))
+	if prefix == "" {
+		return likeFilterNode{}, false
+	}
+
+	return likeFilterNode{path: document.Path(p), prefix: prefix}, true
+}
+
+// likeCandidate returns a candidate reading the values of the index starting
+// with the prefix of the LIKE pattern, or nil if the index cannot be used.
+// If statistics were collected on the index and indicate the prefix
+// is not selective enough, the index is not used.
+func likeCandidate(idxInfo *database.IndexInfo, lfn likeFilterNode) *candidate {
+	if !idxInfo.Paths[0].IsEqual(lfn.path) {
+		return nil
+	}
+
+	if typ := idxInfo.Types[0]; !typ.IsAny() && typ != document.TextValue {
+		return nil
+	}
+
+	if stats := idxInfo.Statistics; stats != nil && stats.PrefixSelectivity(lfn.prefix) > maxLikePrefixSelectivity {
+		return nil
+	}
+
+	ranges := getRangesFromLikePrefix(lfn.path, lfn.prefix)
+
+	cd := candidate{
+		newOp:   stream.IndexScan(idxInfo.IndexName, ranges...),
+		cost:    ranges.Cost(),
+		isIndex: true,
+	}
+
+	if idxInfo.Unique {
+		cd.priority = 2
+	} else {
+		cd.priority = 1
+	}
+
+	return &cd
+}
+
+// getRangesFromLikePrefix returns one range per case variant of the prefix,
+// as the LIKE operator ignores case.
+// The prefix is shortened to keep the number of ranges under maxLikePrefixRanges.
+func getRangesFromLikePrefix(path document.Path, prefix string) stream.IndexRanges {
+	variants := []string{""}
+
+	for _, r := range prefix {
+		folds := []rune{r}
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			folds = append(folds, f)
+		}
+
+		if len(variants)*len(folds) > maxLikePrefixRanges {
+			break
+		}
+
+		next := make([]string, 0, len(variants)*len(folds))
+		for _, v := range variants {
+			for _, f := range folds {
+				next = append(next, v+string(f))
+			}
+		}
+		variants = next
+	}
+
+	sort.Strings(variants)
+
+	var ranges stream.IndexRanges
+	for _, v := range variants {
+		// no valid UTF-8 text contains the 0xFF byte, every text
+		// starting with v is thus lower than v followed by 0xFF.
+		ranges = ranges.Append(stream.IndexRange{
+			Paths: []document.Path{path},
+			Min:   expr.LiteralExprList{expr.LiteralValue(document.NewTextValue(v))},
+			Max:   expr.LiteralExprList{expr.LiteralValue(document.NewTextValue(v + "\xff"))},
+		})
+	}
+
+	return ranges
+}
+
 func operatorCanUseIndex(op expr.Operator) (bool, document.Path, expr.Expr) {
 	lf, leftIsPath := op.LeftHand().(expr.Path)
 	rf, rightIsPath := op.RightHand().(expr.Path)
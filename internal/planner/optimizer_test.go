@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/database"
 	"github.com/genjidb/genji/internal/expr"
 	"github.com/genjidb/genji/internal/planner"
 	"github.com/genjidb/genji/internal/sql/parser"
@@ -283,6 +284,64 @@ func TestRemoveUnnecessaryDedupNodeRule(t *testing.T) {
 	}
 }
 
+func TestScanKeysOnlyRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		root     *st.Stream
+		keysOnly bool
+	}{
+		{
+			"count wildcard",
+			st.New(st.SeqScan("foo")).
+				Pipe(st.HashAggregate(parser.MustParseExpr("COUNT(*)").(expr.AggregatorBuilder))),
+			true,
+		},
+		{
+			"count path",
+			st.New(st.SeqScan("foo")).
+				Pipe(st.HashAggregate(parser.MustParseExpr("COUNT(a)").(expr.AggregatorBuilder))),
+			false,
+		},
+		{
+			"pk() projection",
+			st.New(st.PkScan("foo", st.ValueRange{Min: testutil.IntegerValue(1), Exact: true})).
+				Pipe(st.Project(parser.MustParseExpr("pk() AS k"))),
+			true,
+		},
+		{
+			"path projection",
+			st.New(st.IndexScan("idx_foo_b", st.IndexRange{Min: exprList(testutil.IntegerValue(1)), Exact: true})).
+				Pipe(st.Project(parser.MustParseExpr("pk()"), parser.MustParseExpr("b"))),
+			false,
+		},
+		{
+			"filter",
+			st.New(st.SeqScan("foo")).
+				Pipe(st.Filter(parser.MustParseExpr("b > 1"))).
+				Pipe(st.HashAggregate(parser.MustParseExpr("COUNT(*)").(expr.AggregatorBuilder))),
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := planner.ScanKeysOnlyRule(test.root, nil)
+			require.NoError(t, err)
+
+			var keysOnly bool
+			switch op := res.First().(type) {
+			case *st.SeqScanOperator:
+				keysOnly = op.KeysOnly
+			case *st.PkScanOperator:
+				keysOnly = op.KeysOnly
+			case *st.IndexScanOperator:
+				keysOnly = op.KeysOnly
+			}
+			require.Equal(t, test.keysOnly, keysOnly)
+		})
+	}
+}
+
 func exprList(list ...expr.Expr) expr.LiteralExprList {
 	return expr.LiteralExprList(list)
 }
@@ -503,6 +562,115 @@ func TestUseIndexBasedOnSelectionNodeRule_Simple(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("like prefix", func(t *testing.T) {
+		prefixRanges := func(variants ...string) []st.IndexRange {
+			var ranges []st.IndexRange
+			for _, v := range variants {
+				ranges = append(ranges, st.IndexRange{
+					Min: exprList(testutil.TextValue(v)),
+					Max: exprList(testutil.TextValue(v + "\xff")),
+				})
+			}
+			return ranges
+		}
+
+		tests := []struct {
+			name           string
+			analyze        bool
+			root, expected *st.Stream
+		}{
+			{
+				"non-indexed path",
+				false,
+				st.New(st.SeqScan("foo")).Pipe(st.Filter(parser.MustParseExpr("d LIKE 'ba%'"))),
+				st.New(st.SeqScan("foo")).Pipe(st.Filter(parser.MustParseExpr("d LIKE 'ba%'"))),
+			},
+			{
+				"no prefix",
+				false,
+				st.New(st.SeqScan("foo")).Pipe(st.Filter(parser.MustParseExpr("a LIKE '%ba'"))),
+				st.New(st.SeqScan("foo")).Pipe(st.Filter(parser.MustParseExpr("a LIKE '%ba'"))),
+			},
+			{
+				"NOT LIKE",
+				false,
+				st.New(st.SeqScan("foo")).Pipe(st.Filter(parser.MustParseExpr("a NOT LIKE 'ba%'"))),
+				st.New(st.SeqScan("foo")).Pipe(st.Filter(parser.MustParseExpr("a NOT LIKE 'ba%'"))),
+			},
+			{
+				"typed index",
+				false,
+				st.New(st.SeqScan("foo")).Pipe(st.Filter(parser.MustParseExpr("c LIKE '1%'"))),
+				st.New(st.SeqScan("foo")).Pipe(st.Filter(parser.MustParseExpr("c LIKE '1%'"))),
+			},
+			{
+				"without statistics",
+				false,
+				st.New(st.SeqScan("foo")).Pipe(st.Filter(parser.MustParseExpr("a LIKE 'ap%'"))),
+				st.New(st.IndexScan("idx_foo_a", prefixRanges("AP", "Ap", "aP", "ap")...)).
+					Pipe(st.Filter(parser.MustParseExpr("a LIKE 'ap%'"))),
+			},
+			{
+				"selective prefix",
+				true,
+				st.New(st.SeqScan("foo")).Pipe(st.Filter(parser.MustParseExpr("a LIKE 'ba_a%'"))),
+				st.New(st.IndexScan("idx_foo_a", prefixRanges("BA", "Ba", "bA", "ba")...)).
+					Pipe(st.Filter(parser.MustParseExpr("a LIKE 'ba_a%'"))),
+			},
+			{
+				"unselective prefix",
+				true,
+				st.New(st.SeqScan("foo")).Pipe(st.Filter(parser.MustParseExpr("a LIKE 'ap%'"))),
+				st.New(st.SeqScan("foo")).Pipe(st.Filter(parser.MustParseExpr("a LIKE 'ap%'"))),
+			},
+			{
+				"long prefix",
+				false,
+				st.New(st.SeqScan("foo")).Pipe(st.Filter(parser.MustParseExpr("a LIKE '1banana%'"))),
+				st.New(st.IndexScan("idx_foo_a", prefixRanges(
+					"1BAN", "1BAn", "1BaN", "1Ban", "1bAN", "1bAn", "1baN", "1ban",
+				)...)).
+					Pipe(st.Filter(parser.MustParseExpr("a LIKE '1banana%'"))),
+			},
+			{
+				"with comparison",
+				false,
+				st.New(st.SeqScan("foo")).
+					Pipe(st.Filter(parser.MustParseExpr("a LIKE 'ba%'"))).
+					Pipe(st.Filter(parser.MustParseExpr("b = 2"))),
+				st.New(st.IndexScan("idx_foo_b", st.IndexRange{Min: exprList(testutil.IntegerValue(2)), Exact: true})).
+					Pipe(st.Filter(parser.MustParseExpr("a LIKE 'ba%'"))),
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				db, tx, cleanup := testutil.NewTestTx(t)
+				defer cleanup()
+
+				testutil.MustExec(t, db, tx, `
+					CREATE TABLE foo (k INT PRIMARY KEY, c INT);
+					CREATE INDEX idx_foo_a ON foo(a);
+					CREATE INDEX idx_foo_b ON foo(b);
+					CREATE INDEX idx_foo_c ON foo(c);
+					INSERT INTO foo (k, a, b, c) VALUES
+						(1, 'apple', 1, 1),
+						(2, 'Apricot', 2, 2),
+						(3, 'apple pie', 3, 3),
+						(4, 'banana', 4, 4)
+				`)
+
+				if test.analyze {
+					testutil.MustExec(t, db, tx, "ANALYZE foo")
+				}
+
+				res, err := planner.UseIndexBasedOnFilterNodeRule(test.root, db.Catalog)
+				require.NoError(t, err)
+				require.Equal(t, test.expected.String(), res.String())
+			})
+		}
+	})
 }
 
 func TestUseIndexBasedOnSelectionNodeRule_Composite(t *testing.T) {
@@ -812,6 +980,47 @@ func TestUseIndexBasedOnSelectionNodeRule_Composite(t *testing.T) {
 	})
 }
 
+func TestSelectJoinBuildSideRule(t *testing.T) {
+	db, tx, cleanup := testutil.NewTestTx(t)
+	defer cleanup()
+	testutil.MustExec(t, db, tx, `
+		CREATE TABLE foo(a INTEGER);
+		CREATE TABLE bar(a INTEGER);
+		CREATE TABLE baz;
+		CREATE INDEX idx_foo_a ON foo(a);
+		CREATE INDEX idx_bar_a ON bar(a);
+		INSERT INTO foo (a) VALUES (1), (2), (3);
+		INSERT INTO bar (a) VALUES (1);
+	`)
+
+	join := func(left, right string) *st.Stream {
+		return st.New(st.Join(st.New(st.SeqScan(left)), st.New(st.SeqScan(right)), left, right, parser.MustParseExpr(left+".a = "+right+".a")))
+	}
+
+	buildLeft := func(t *testing.T, s *st.Stream) bool {
+		t.Helper()
+
+		s, err := planner.SelectJoinBuildSideRule(s, db.Catalog)
+		require.NoError(t, err)
+		return s.First().(*st.JoinOperator).BuildLeft
+	}
+
+	// without statistics, the right side is loaded
+	require.False(t, buildLeft(t, join("foo", "bar")))
+	require.False(t, buildLeft(t, join("bar", "foo")))
+
+	testutil.MustExec(t, db, tx, "ANALYZE")
+
+	require.False(t, buildLeft(t, join("foo", "bar")))
+	require.True(t, buildLeft(t, join("bar", "foo")))
+	require.False(t, buildLeft(t, join("bar", "baz")))
+
+	// both sides of nested joins are optimized
+	got, err := planner.Optimize(st.New(st.Join(join("bar", "foo"), st.New(st.SeqScan("baz")), "", "baz", parser.MustParseExpr("baz.a = foo.a"))), db.Catalog)
+	require.NoError(t, err)
+	require.Equal(t, "hashJoin(hashJoin(seqScan(bar), seqScan(foo), bar.a = foo.a, build left), seqScan(baz), baz.a = foo.a)", got.String())
+}
+
 func TestOptimize(t *testing.T) {
 	t.Run("concat operator operands are optimized", func(t *testing.T) {
 		t.Run("PrecalculateExprRule", func(t *testing.T) {
@@ -929,3 +1138,32 @@ func TestOptimize(t *testing.T) {
 		require.Equal(t, want.String(), got.String())
 	})
 }
+
+func TestRegisterRule(t *testing.T) {
+	// route every query on the tenant table to the tenant_1 table
+	planner.RegisterRule(func(s *st.Stream, catalog database.Catalog) (*st.Stream, error) {
+		if scan, ok := s.First().(*st.SeqScanOperator); ok && scan.TableName == "tenant" {
+			scan.TableName = "tenant_1"
+		}
+
+		return s, nil
+	})
+
+	db, tx, cleanup := testutil.NewTestTx(t)
+	defer cleanup()
+	testutil.MustExec(t, db, tx, `
+		CREATE TABLE tenant;
+		CREATE TABLE tenant_1;
+		CREATE TABLE foo;
+	`)
+
+	got, err := planner.Optimize(st.New(st.SeqScan("tenant")).Pipe(st.Filter(parser.MustParseExpr("a = 1 + 2"))), db.Catalog)
+	require.NoError(t, err)
+	require.Equal(t, st.New(st.SeqScan("tenant_1")).Pipe(st.Filter(parser.MustParseExpr("a = 3"))).String(), got.String())
+
+	got, err = planner.Optimize(st.New(st.SeqScan("foo")), db.Catalog)
+	require.NoError(t, err)
+	require.Equal(t, st.New(st.SeqScan("foo")).String(), got.String())
+
+	require.Panics(t, func() { planner.RegisterRule(nil) })
+}
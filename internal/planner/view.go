@@ -0,0 +1,182 @@
+package planner
+
+import (
+	"errors"
+
+	errs "github.com/genjidb/genji/errors"
+	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/expr"
+	"github.com/genjidb/genji/internal/stream"
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// ParseViewQuery parses the SELECT statement of a view and returns its stream.
+// It is set by the parser package, which depends on the planner.
+var ParseViewQuery func(q string) (*stream.Stream, error)
+
+// viewStream returns a new stream reading the view with the given name,
+// or nil if there is no such view.
+func viewStream(name string, catalog database.Catalog) (*stream.Stream, error) {
+	if catalog == nil {
+		return nil, nil
+	}
+
+	info, err := catalog.GetViewInfo(name)
+	if err != nil {
+		if errs.IsNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if ParseViewQuery == nil {
+		return nil, errors.New("views are not supported: no parser registered")
+	}
+
+	return ParseViewQuery(info.Query)
+}
+
+// ExpandViewRule replaces the operator reading a view by the query of the view,
+// which is evaluated like a common table expression named after the view.
+// It returns an error if the stream writes to a view.
+// Example, if v is a view defined as SELECT a FROM foo WHERE a > 1:
+//   this:
+//     seqScan(v) | project(a)
+//   becomes this:
+//     cteScan(v AS (seqScan(foo) | filter(a > 1) | project(a))) | project(a)
+func ExpandViewRule(s *stream.Stream, catalog database.Catalog) (*stream.Stream, error) {
+	for n := s.Op; n != nil; n = n.GetPrev() {
+		var name string
+		switch t := n.(type) {
+		case *stream.JoinOperator:
+			var err error
+			if t.Left, err = ExpandViewRule(t.Left, catalog); err != nil {
+				return nil, err
+			}
+			if t.Right, err = ExpandViewRule(t.Right, catalog); err != nil {
+				return nil, err
+			}
+			continue
+		case *stream.TableInsertOperator:
+			name = t.Name
+		case *stream.TableReplaceOperator:
+			name = t.Name
+		case *stream.TableDeleteOperator:
+			name = t.Name
+		default:
+			continue
+		}
+
+		vs, err := viewStream(name, catalog)
+		if err != nil {
+			return nil, err
+		}
+		if vs != nil {
+			return nil, stringutil.Errorf("cannot modify view %q", name)
+		}
+	}
+
+	scan, ok := s.First().(*stream.SeqScanOperator)
+	if !ok {
+		return s, nil
+	}
+
+	vs, err := viewStream(scan.TableName, catalog)
+	if err != nil || vs == nil {
+		return s, err
+	}
+
+	op := stream.CTEScan(&stream.CommonTableExpr{Name: scan.TableName, Stream: vs})
+	if s.Op == scan {
+		return stream.New(op), nil
+	}
+
+	stream.InsertBefore(scan, op)
+	s.Remove(scan)
+	return s, nil
+}
+
+// ReadRelations returns the names of the tables and views read by s,
+// including the ones read by its joins, unions, common table expressions
+// and subqueries. Names may be returned more than once.
+func ReadRelations(s *stream.Stream) []string {
+	var names []string
+
+	for n := s.Op; n != nil; n = n.GetPrev() {
+		var exprs []expr.Expr
+		switch t := n.(type) {
+		case *stream.SeqScanOperator:
+			names = append(names, t.TableName)
+		case *stream.HistoryScanOperator:
+			names = append(names, t.TableName)
+		case *stream.JoinOperator:
+			names = append(names, ReadRelations(t.Left)...)
+			names = append(names, ReadRelations(t.Right)...)
+		case *stream.ConcatOperator:
+			names = append(names, ReadRelations(t.S1)...)
+			names = append(names, ReadRelations(t.S2)...)
+		case *stream.CTEScanOperator:
+			names = append(names, ReadRelations(t.CTE.Stream)...)
+			if t.CTE.Recursive != nil {
+				names = append(names, ReadRelations(t.CTE.Recursive)...)
+			}
+		case *stream.FilterOperator:
+			exprs = []expr.Expr{t.E}
+		case *stream.ProjectOperator:
+			exprs = t.Exprs
+		case *stream.SetOperator:
+			exprs = []expr.Expr{t.E}
+		}
+
+		for _, e := range exprs {
+			expr.Walk(e, func(e expr.Expr) bool {
+				if sq, ok := e.(*expr.Subquery); ok {
+					if ss, ok := sq.Stream.(*stream.SubqueryStream); ok {
+						names = append(names, ReadRelations(ss.Stream)...)
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	return names
+}
+
+// CheckView returns an error if the stream of the view with the given name
+// reads a table or a view that doesn't exist, or reads the view itself,
+// directly or through other views.
+func CheckView(name string, s *stream.Stream, catalog database.Catalog) error {
+	return checkView(name, s, catalog, make(map[string]bool))
+}
+
+func checkView(name string, s *stream.Stream, catalog database.Catalog, checked map[string]bool) error {
+	for _, rel := range ReadRelations(s) {
+		if rel == name {
+			return stringutil.Errorf("view %q cannot read itself", name)
+		}
+		if checked[rel] {
+			continue
+		}
+		checked[rel] = true
+
+		vs, err := viewStream(rel, catalog)
+		if err != nil {
+			return err
+		}
+		if vs != nil {
+			err = checkView(name, vs, catalog, checked)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		_, err = catalog.GetTableInfo(rel)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
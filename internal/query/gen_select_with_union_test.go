@@ -5,7 +5,9 @@
 package query_test
 
 import (
+	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/genjidb/genji"
 	"github.com/genjidb/genji/internal/testutil"
@@ -34,6 +36,10 @@ INSERT INTO baz (x,y) VALUES ("a", "a"), ("b", "b");
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`SELECT * FROM foo`, func(t *testing.T) {
@@ -62,6 +68,10 @@ SELECT * FROM bar;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`SELECT * FROM foo`, func(t *testing.T) {
@@ -90,6 +100,10 @@ SELECT * FROM baz;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`SELECT * FROM foo WHERE a > 1`, func(t *testing.T) {
@@ -116,6 +130,10 @@ SELECT * FROM baz WHERE x != "b";
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`SELECT * FROM foo WHERE a > 1`, func(t *testing.T) {
@@ -142,6 +160,10 @@ SELECT * FROM foo WHERE a <= 1;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`SELECT * FROM foo`, func(t *testing.T) {
@@ -173,6 +195,10 @@ UNION ALL SELECT * FROM baz;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`SELECT * FROM foo`, func(t *testing.T) {
@@ -0,0 +1,27 @@
+package query
+
+import (
+	"context"
+	"errors"
+
+	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/query/statement"
+)
+
+// KillStmt is a statement that cancels the query run by a process.
+type KillStmt struct {
+	ProcessID int64
+}
+
+func (stmt KillStmt) alterQuery(ctx context.Context, db *database.Database, q *Query) error {
+	return db.Kill(stmt.ProcessID)
+}
+
+// IsReadOnly always returns true. It implements the Statement interface.
+func (stmt KillStmt) IsReadOnly() bool {
+	return true
+}
+
+func (stmt KillStmt) Run(ctx *statement.Context) (statement.Result, error) {
+	return statement.Result{}, errors.New("cannot kill a process from within a statement")
+}
@@ -0,0 +1,98 @@
+package query_test
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"path/filepath"
+	"testing"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/document/encoding/msgpack"
+	"github.com/genjidb/genji/engine/boltengine"
+	"github.com/genjidb/genji/internal/catalog"
+	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	var logs bytes.Buffer
+
+	open := func(t *testing.T) *database.Database {
+		ng, err := boltengine.NewEngine(path, 0600, nil)
+		require.NoError(t, err)
+
+		db, err := database.New(context.Background(), ng, database.Options{
+			Codec:   msgpack.NewCodec(),
+			Catalog: catalog.New(),
+			Logger:  log.New(&logs, "", 0),
+		})
+		require.NoError(t, err)
+		return db
+	}
+
+	exec := func(t *testing.T, db *database.Database, q string) {
+		res := testutil.MustQuery(t, db, nil, q)
+		require.NoError(t, res.Iterate(func(d document.Document) error { return nil }))
+		require.NoError(t, res.Close())
+	}
+
+	changes := func(t *testing.T, db *database.Database) []document.Document {
+		var docs []document.Document
+		res := testutil.MustQuery(t, db, nil, "SELECT * FROM __genji_plan_changes")
+		defer res.Close()
+
+		err := res.Iterate(func(d document.Document) error {
+			fb := document.NewFieldBuffer()
+			err := fb.Copy(d)
+			docs = append(docs, fb)
+			return err
+		})
+		require.NoError(t, err)
+		return docs
+	}
+
+	db := open(t)
+	exec(t, db, "CREATE TABLE test")
+	exec(t, db, "SELECT * FROM test WHERE a = 1")
+
+	// statements only differing by their values share the same plan
+	exec(t, db, "SELECT * FROM test WHERE a = 2")
+	require.Empty(t, changes(t, db))
+	require.Empty(t, logs.String())
+
+	exec(t, db, "CREATE INDEX idx_test_a ON test(a)")
+	exec(t, db, "SELECT * FROM test WHERE a = 3")
+
+	docs := changes(t, db)
+	require.Len(t, docs, 1)
+	for field, want := range map[string]string{
+		"statement":       "seqScan(test) | filter(a = ?) | project(*)",
+		"old_plan":        "seqScan(test) | filter(a = ?)",
+		"old_fingerprint": database.PlanFingerprint("seqScan(test) | filter(a = ?)"),
+		"new_plan":        `indexScan("idx_test_a", ?)`,
+		"new_fingerprint": database.PlanFingerprint(`indexScan("idx_test_a", ?)`),
+	} {
+		v, err := docs[0].GetByField(field)
+		require.NoError(t, err)
+		require.Equal(t, want, v.V.(string), field)
+	}
+	require.Contains(t, logs.String(), `plan of statement "seqScan(test) | filter(a = ?) | project(*)" changed`)
+
+	// plans are persisted when the database is closed
+	require.NoError(t, db.Close())
+	db = open(t)
+	defer db.Close()
+	require.Empty(t, changes(t, db))
+
+	exec(t, db, "DROP INDEX idx_test_a")
+	exec(t, db, "SELECT * FROM test WHERE a = 4")
+
+	docs = changes(t, db)
+	require.Len(t, docs, 1)
+	v, err := docs[0].GetByField("old_plan")
+	require.NoError(t, err)
+	require.Equal(t, `indexScan("idx_test_a", ?)`, v.V.(string))
+}
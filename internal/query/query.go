@@ -14,6 +14,9 @@ import (
 // Results are returned as streams.
 type Query struct {
 	Statements []statement.Statement
+	// SQL text of the query, if known. It is reported
+	// when the transaction running the query times out.
+	SQL        string
 	tx         *database.Transaction
 	autoCommit bool
 }
@@ -48,7 +51,17 @@ func (q Query) Run(context *Context) (*statement.Result, error) {
 		q.autoCommit = true
 	}
 
-	ctx := context.Ctx
+	// register the query until its result is closed, so that it can be killed.
+	ctx, proc := context.DB.StartProcess(context.Ctx, q.SQL)
+	defer func() {
+		if res.Process == nil {
+			proc.End()
+		}
+	}()
+	newTracker := func() *database.ResourceTracker {
+		return context.DB.NewResourceTracker().WithContext(ctx)
+	}
+	var throttled bool
 
 	for i, stmt := range q.Statements {
 		select {
@@ -61,7 +74,8 @@ func (q Query) Run(context *Context) (*statement.Result, error) {
 		res = statement.Result{}
 
 		if qa, ok := stmt.(queryAlterer); ok {
-			err = qa.alterQuery(ctx, context.DB, &q)
+			// transactions started by BEGIN outlive the query
+			err = qa.alterQuery(context.Ctx, context.DB, &q)
 			if err != nil {
 				if tx := context.GetTx(); tx != nil {
 					tx.Rollback()
@@ -73,7 +87,25 @@ func (q Query) Run(context *Context) (*statement.Result, error) {
 		}
 
 		if q.tx == nil {
-			q.tx, err = context.DB.BeginTx(ctx, &database.TxOptions{
+			// only the statements running their own transaction are throttled,
+			// explicit transactions already hold the database.
+			if !throttled {
+				err = proc.AcquireSlot(ctx)
+				if err != nil {
+					return nil, err
+				}
+				throttled = true
+			}
+			if !stmt.IsReadOnly() {
+				err = context.DB.Throttle().WaitWrite(ctx)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			// the transaction is not bound to the process, killing it
+			// must not prevent the result from rolling it back cleanly.
+			q.tx, err = context.DB.BeginTx(context.Ctx, &database.TxOptions{
 				ReadOnly: stmt.IsReadOnly(),
 			})
 			if err != nil {
@@ -81,19 +113,43 @@ func (q Query) Run(context *Context) (*statement.Result, error) {
 			}
 		}
 
+		err = q.tx.Enter(q.SQL)
+		if err != nil {
+			if q.autoCommit {
+				q.tx.Rollback()
+			}
+
+			return nil, err
+		}
+
+		// in explicit transactions, failing statements can undo their own changes
+		// instead of leaving them partially applied.
+		savepoint := !q.autoCommit && q.tx.AtomicStatements && isWriteStream(stmt)
+		if savepoint {
+			q.tx.Savepoint()
+		}
+
 		res, err = stmt.Run(&statement.Context{
-			Tx:      q.tx,
-			Catalog: context.DB.Catalog,
-			Params:  context.Params,
+			Tx:         q.tx,
+			Catalog:    context.DB.Catalog,
+			Params:     context.Params,
+			NewTracker: newTracker,
 		})
+		q.tx.Leave()
 		if err != nil {
 			if q.autoCommit {
 				q.tx.Rollback()
 			}
+			if savepoint {
+				q.tx.RollbackToSavepoint()
+			}
 
 			return nil, err
 		}
 
+		res.RunTx = q.tx
+		res.Savepoint = savepoint
+
 		// if there are still statements to be executed,
 		// and the current statement is not read-only,
 		// iterate over the result.
@@ -131,10 +187,18 @@ func (q Query) Run(context *Context) (*statement.Result, error) {
 		// its Close method is expected to be called.
 		res.Tx = q.tx
 	}
+	res.Process = proc
 
 	return &res, nil
 }
 
+// isWriteStream returns whether the statement modifies documents,
+// like INSERT, UPDATE or DELETE.
+func isWriteStream(stmt statement.Statement) bool {
+	s, ok := stmt.(*statement.StreamStmt)
+	return ok && !s.IsReadOnly()
+}
+
 type queryAlterer interface {
 	alterQuery(ctx context.Context, db *database.Database, q *Query) error
 }
@@ -0,0 +1,36 @@
+package query
+
+import (
+	"context"
+	"errors"
+
+	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/query/statement"
+)
+
+// SetStmt is a statement that sets a limit on the resources
+// every subsequent statement is allowed to consume, or the synchronous
+// mode of the database.
+// Setting a limit to zero disables it.
+type SetStmt struct {
+	Name  string
+	Value int64
+	// Value of the settings that aren't integers, like the synchronous mode.
+	Text string
+}
+
+func (stmt SetStmt) alterQuery(ctx context.Context, db *database.Database, q *Query) error {
+	if stmt.Name == database.SynchronousSetting {
+		return db.SetSynchronous(stmt.Text)
+	}
+
+	return db.SetLimit(stmt.Name, stmt.Value)
+}
+
+func (stmt SetStmt) IsReadOnly() bool {
+	return true
+}
+
+func (stmt SetStmt) Run(ctx *statement.Context) (statement.Result, error) {
+	return statement.Result{}, errors.New("cannot run SET statement within a statement")
+}
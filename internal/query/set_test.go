@@ -0,0 +1,97 @@
+package query_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	errs "github.com/genjidb/genji/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		set      string
+		query    string
+		exceeded bool
+	}{
+		{"No limit", ``, `SELECT * FROM test ORDER BY a`, false},
+		{"Scanned documents/ Under", `SET max_scanned_documents = 3`, `SELECT * FROM test`, false},
+		{"Scanned documents/ Over", `SET max_scanned_documents = 2`, `SELECT * FROM test`, true},
+		{"Scanned documents/ Pk", `SET max_scanned_documents = 2`, `SELECT * FROM test WHERE pk() > 0`, true},
+		{"Scanned documents/ Index", `SET max_scanned_documents = 2`, `SELECT * FROM test WHERE a > 0`, true},
+		{"Scanned documents/ Disabled", `SET max_scanned_documents = 2; SET max_scanned_documents = 0`, `SELECT * FROM test`, false},
+		{"Memory/ Sort", `SET max_memory = 16`, `SELECT * FROM test ORDER BY b`, true},
+		{"Memory/ Group by", `SET max_memory = 16`, `SELECT COUNT(*) FROM test GROUP BY b`, true},
+		{"Memory/ Distinct", `SET max_memory = 8`, `SELECT DISTINCT b FROM test`, true},
+		{"Memory/ Enough", `SET max_memory = 100000`, `SELECT * FROM test ORDER BY b`, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, err := genji.Open(":memory:")
+			require.NoError(t, err)
+			defer db.Close()
+
+			err = db.Exec(`
+				CREATE TABLE test;
+				CREATE INDEX test_a ON test(a);
+				INSERT INTO test (a, b) VALUES (1, 'foo'), (2, 'bar'), (3, 'baz');
+			`)
+			require.NoError(t, err)
+
+			if test.set != "" {
+				err = db.Exec(test.set)
+				require.NoError(t, err)
+			}
+
+			res, err := db.Query(test.query)
+			require.NoError(t, err)
+			defer res.Close()
+
+			var n int
+			err = res.Iterate(func(d document.Document) error {
+				n++
+				return nil
+			})
+			if test.exceeded {
+				require.True(t, errs.IsLimitExceededError(err), "expected limit error, got %v", err)
+				return
+			}
+			require.NoError(t, err)
+			require.NotZero(t, n)
+		})
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		require.Error(t, db.Exec(`SET unknown_limit = 10`))
+		require.Error(t, db.Exec(`SET max_memory = -1`))
+	})
+}
+
+func TestSetSynchronous(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		SET synchronous = off;
+		CREATE TABLE test;
+		INSERT INTO test (a) VALUES (1), (2);
+		SET synchronous = full;
+	`)
+	require.NoError(t, err)
+
+	d, err := db.QueryDocument("SELECT COUNT(*) AS n FROM test")
+	require.NoError(t, err)
+	var n int
+	require.NoError(t, document.Scan(d, &n))
+	require.Equal(t, 2, n)
+
+	require.Error(t, db.Exec(`SET synchronous = always`))
+}
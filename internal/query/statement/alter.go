@@ -2,7 +2,9 @@ package statement
 
 import (
 	"errors"
+	"strings"
 
+	"github.com/genjidb/genji/document"
 	errs "github.com/genjidb/genji/errors"
 	"github.com/genjidb/genji/internal/database"
 )
@@ -35,7 +37,20 @@ func (stmt AlterStmt) Run(ctx *Context) (Result, error) {
 		return res, errs.AlreadyExistsError{Name: stmt.NewTableName}
 	}
 
-	err := ctx.Catalog.RenameTable(ctx.Tx, stmt.TableName, stmt.NewTableName)
+	ti, err := ctx.Catalog.GetTableInfo(stmt.TableName)
+	if err != nil {
+		return res, err
+	}
+
+	err = ctx.Catalog.RenameTable(ctx.Tx, stmt.TableName, stmt.NewTableName)
+	if err != nil {
+		return res, err
+	}
+
+	if ti.Audit {
+		err = ctx.Catalog.RenameTable(ctx.Tx, database.AuditTableName(stmt.TableName), database.AuditTableName(stmt.NewTableName))
+	}
+
 	return res, err
 }
 
@@ -65,3 +80,113 @@ func (stmt AlterTableAddField) Run(ctx *Context) (Result, error) {
 	err := ctx.Catalog.AddFieldConstraint(ctx.Tx, stmt.TableName, stmt.Constraint)
 	return res, err
 }
+
+// AlterTableSetReadOnly is a DSL that allows creating a full ALTER TABLE SET READ ONLY
+// or ALTER TABLE SET READ WRITE query.
+type AlterTableSetReadOnly struct {
+	TableName string
+	ReadOnly  bool
+}
+
+// IsReadOnly always returns false. It implements the Statement interface.
+func (stmt AlterTableSetReadOnly) IsReadOnly() bool {
+	return false
+}
+
+// Run runs the ALTER TABLE SET READ ONLY or SET READ WRITE statement in the given transaction.
+// It implements the Statement interface.
+func (stmt AlterTableSetReadOnly) Run(ctx *Context) (Result, error) {
+	var res Result
+
+	if stmt.TableName == "" {
+		return res, errors.New("missing table name")
+	}
+
+	err := ctx.Catalog.SetReadOnly(ctx.Tx, stmt.TableName, stmt.ReadOnly)
+	return res, err
+}
+
+// AlterTableSetMask is a DSL that allows creating a full ALTER TABLE SET MASK query.
+type AlterTableSetMask struct {
+	TableName string
+	Mask      database.FieldMask
+}
+
+// IsReadOnly always returns false. It implements the Statement interface.
+func (stmt AlterTableSetMask) IsReadOnly() bool {
+	return false
+}
+
+// Run runs the ALTER TABLE SET MASK statement in the given transaction.
+// It implements the Statement interface.
+func (stmt AlterTableSetMask) Run(ctx *Context) (Result, error) {
+	var res Result
+
+	if stmt.TableName == "" {
+		return res, errors.New("missing table name")
+	}
+
+	if stmt.Mask.Path == nil {
+		return res, errors.New("missing field name")
+	}
+
+	err := ctx.Catalog.SetFieldMask(ctx.Tx, stmt.TableName, stmt.Mask)
+	return res, err
+}
+
+// AlterTableDropMask is a DSL that allows creating a full ALTER TABLE DROP MASK query.
+type AlterTableDropMask struct {
+	TableName string
+	Path      document.Path
+}
+
+// IsReadOnly always returns false. It implements the Statement interface.
+func (stmt AlterTableDropMask) IsReadOnly() bool {
+	return false
+}
+
+// Run runs the ALTER TABLE DROP MASK statement in the given transaction.
+// It implements the Statement interface.
+func (stmt AlterTableDropMask) Run(ctx *Context) (Result, error) {
+	var res Result
+
+	if stmt.TableName == "" {
+		return res, errors.New("missing table name")
+	}
+
+	err := ctx.Catalog.DropFieldMask(ctx.Tx, stmt.TableName, stmt.Path)
+	return res, err
+}
+
+// AlterSequenceRestart is a DSL that allows creating a full ALTER SEQUENCE RESTART query.
+type AlterSequenceRestart struct {
+	SequenceName string
+	Value        int64
+}
+
+// IsReadOnly always returns false. It implements the Statement interface.
+func (stmt AlterSequenceRestart) IsReadOnly() bool {
+	return false
+}
+
+// Run runs the ALTER SEQUENCE RESTART statement in the given transaction.
+// It implements the Statement interface.
+func (stmt AlterSequenceRestart) Run(ctx *Context) (Result, error) {
+	var res Result
+
+	if stmt.SequenceName == "" {
+		return res, errors.New("missing sequence name")
+	}
+
+	if strings.HasPrefix(stmt.SequenceName, database.InternalPrefix) {
+		return res, errors.New("cannot restart internal sequence " + stmt.SequenceName)
+	}
+
+	seq, err := ctx.Catalog.GetSequence(stmt.SequenceName)
+	if err != nil {
+		return res, err
+	}
+
+	err = seq.Restart(ctx.Tx, ctx.Catalog, stmt.Value)
+	return res, err
+}
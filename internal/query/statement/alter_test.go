@@ -7,6 +7,7 @@ import (
 	"github.com/genjidb/genji"
 	"github.com/genjidb/genji/document"
 	errs "github.com/genjidb/genji/errors"
+	"github.com/genjidb/genji/internal/testutil"
 	"github.com/stretchr/testify/require"
 )
 
@@ -45,3 +46,114 @@ func TestAlterTable(t *testing.T) {
 	err = db.Exec("ALTER TABLE __genji_catalog RENAME TO bar")
 	require.Error(t, err)
 }
+
+func TestAlterTableSetReadOnly(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`CREATE TABLE foo; INSERT INTO foo (a) VALUES (1)`)
+	require.NoError(t, err)
+
+	err = db.Exec("ALTER TABLE foo SET READ ONLY")
+	require.NoError(t, err)
+
+	// the flag is stored in the catalog
+	d, err := db.QueryDocument("SELECT read_only FROM __genji_catalog WHERE name = 'foo'")
+	require.NoError(t, err)
+	testutil.RequireDocJSONEq(t, d, `{"read_only": true}`)
+
+	for _, q := range []string{
+		"INSERT INTO foo (a) VALUES (2)",
+		"UPDATE foo SET a = 2",
+		"DELETE FROM foo",
+		"DROP TABLE foo",
+	} {
+		err = db.Exec(q)
+		require.Error(t, err, q)
+	}
+
+	// the table can still be read
+	d, err = db.QueryDocument("SELECT COUNT(*) AS n FROM foo")
+	require.NoError(t, err)
+	testutil.RequireDocJSONEq(t, d, `{"n": 1}`)
+
+	err = db.Exec("ALTER TABLE foo SET READ WRITE")
+	require.NoError(t, err)
+
+	err = db.Exec("INSERT INTO foo (a) VALUES (2)")
+	require.NoError(t, err)
+
+	// internal tables cannot be altered
+	err = db.Exec("ALTER TABLE __genji_catalog SET READ WRITE")
+	require.Error(t, err)
+}
+
+func TestAlterTableSetMask(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`CREATE TABLE foo(id INTEGER PRIMARY KEY); INSERT INTO foo (id, ssn) VALUES (1, '123-45-6789')`)
+	require.NoError(t, err)
+
+	err = db.Exec("ALTER TABLE foo SET MASK ON ssn USING 'hidden'")
+	require.NoError(t, err)
+	err = db.Exec("ALTER TABLE foo SET MASK ON ssn USING mask(ssn, 4)")
+	require.NoError(t, err)
+
+	// the masks are stored in the catalog
+	d, err := db.QueryDocument("SELECT masks FROM __genji_catalog WHERE name = 'foo'")
+	require.NoError(t, err)
+	testutil.RequireDocJSONEq(t, d, `{"masks": [{"path": "ssn", "expr": "mask(ssn, 4)"}]}`)
+
+	// the primary key cannot be masked
+	err = db.Exec("ALTER TABLE foo SET MASK ON id USING 0")
+	require.Error(t, err)
+
+	err = db.Exec("ALTER TABLE foo DROP MASK ON ssn")
+	require.NoError(t, err)
+	err = db.Exec("ALTER TABLE foo DROP MASK ON ssn")
+	require.Error(t, err)
+
+	d, err = db.QueryDocument("SELECT masks FROM __genji_catalog WHERE name = 'foo'")
+	require.NoError(t, err)
+	testutil.RequireDocJSONEq(t, d, `{"masks": null}`)
+}
+
+func TestAlterSequenceRestart(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`CREATE SEQUENCE seq MAXVALUE 100; SELECT NEXT VALUE FOR seq`)
+	require.NoError(t, err)
+
+	err = db.Exec("ALTER SEQUENCE seq RESTART WITH 42")
+	require.NoError(t, err)
+
+	d, err := db.QueryDocument("SELECT NEXT VALUE FOR seq AS n")
+	require.NoError(t, err)
+	testutil.RequireDocJSONEq(t, d, `{"n": 42}`)
+
+	// restarting is rolled back with the transaction
+	tx, err := db.Begin(true)
+	require.NoError(t, err)
+	err = tx.Exec("ALTER SEQUENCE seq RESTART WITH 10")
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+
+	d, err = db.QueryDocument("SELECT NEXT VALUE FOR seq AS n")
+	require.NoError(t, err)
+	testutil.RequireDocJSONEq(t, d, `{"n": 43}`)
+
+	for _, q := range []string{
+		"ALTER SEQUENCE seq RESTART WITH 0",
+		"ALTER SEQUENCE seq RESTART WITH 101",
+		"ALTER SEQUENCE unknown RESTART WITH 1",
+		"ALTER SEQUENCE __genji_store_seq RESTART WITH 1",
+	} {
+		err = db.Exec(q)
+		require.Error(t, err, q)
+	}
+}
@@ -0,0 +1,51 @@
+package statement
+
+import (
+	errs "github.com/genjidb/genji/errors"
+)
+
+// AnalyzeStmt is a DSL that allows creating a full ANALYZE statement.
+type AnalyzeStmt struct {
+	TableOrIndexName string
+}
+
+// IsReadOnly always returns false. It implements the Statement interface.
+func (stmt AnalyzeStmt) IsReadOnly() bool {
+	return false
+}
+
+// Run collects statistics about the selected indexes in the given transaction.
+// If no table or index is selected, statistics are collected for every index of the database.
+// It implements the Statement interface.
+func (stmt AnalyzeStmt) Run(ctx *Context) (Result, error) {
+	var res Result
+
+	if stmt.TableOrIndexName == "" {
+		for _, idxName := range ctx.Catalog.ListIndexes("") {
+			err := ctx.Catalog.Analyze(ctx.Tx, idxName)
+			if err != nil {
+				return res, err
+			}
+		}
+
+		return res, nil
+	}
+
+	_, err := ctx.Catalog.GetTableInfo(stmt.TableOrIndexName)
+	if err == nil {
+		for _, idxName := range ctx.Catalog.ListIndexes(stmt.TableOrIndexName) {
+			err = ctx.Catalog.Analyze(ctx.Tx, idxName)
+			if err != nil {
+				return res, err
+			}
+		}
+
+		return res, nil
+	}
+	if !errs.IsNotFoundError(err) {
+		return res, err
+	}
+
+	err = ctx.Catalog.Analyze(ctx.Tx, stmt.TableOrIndexName)
+	return res, err
+}
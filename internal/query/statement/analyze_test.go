@@ -0,0 +1,118 @@
+package statement_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/genjidb/genji/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectAnalyzed []string
+		fails          bool
+	}{
+		{"Analyze all", `ANALYZE`, []string{"idx_test1_a", "idx_test1_b", "idx_test2_a"}, false},
+		{"Analyze table", `ANALYZE test1`, []string{"idx_test1_a", "idx_test1_b"}, false},
+		{"Analyze index", `ANALYZE idx_test2_a`, []string{"idx_test2_a"}, false},
+		{"Analyze unknown", `ANALYZE doesntexist`, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, tx, cleanup := testutil.NewTestTx(t)
+			defer cleanup()
+
+			testutil.MustExec(t, db, tx, `
+				CREATE TABLE test1;
+				CREATE TABLE test2(a TEXT);
+
+				CREATE INDEX idx_test1_a ON test1(a);
+				CREATE INDEX idx_test1_b ON test1(b);
+				CREATE INDEX idx_test2_a ON test2(a);
+
+				INSERT INTO test1(a, b) VALUES ('Apple', 1), ('apricot', 2), ('banana', 3), (10, 4);
+				INSERT INTO test2(a) VALUES ('cherry');
+			`)
+
+			err := testutil.Exec(db, tx, test.query)
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			for _, idxName := range db.Catalog.ListIndexes("") {
+				info, err := db.Catalog.GetIndexInfo(idxName)
+				require.NoError(t, err)
+
+				shouldBeAnalyzed := false
+				for _, name := range test.expectAnalyzed {
+					if name == idxName {
+						shouldBeAnalyzed = true
+						break
+					}
+				}
+
+				if !shouldBeAnalyzed {
+					require.Nil(t, info.Statistics)
+					continue
+				}
+
+				require.NotNil(t, info.Statistics)
+				switch idxName {
+				case "idx_test1_a":
+					require.EqualValues(t, 4, info.Statistics.Count)
+					require.Equal(t, map[string]int64{"app": 1, "apr": 1, "ban": 1}, info.Statistics.Prefixes)
+					require.Equal(t, 0.5, info.Statistics.PrefixSelectivity("AP"))
+					require.Equal(t, 0.25, info.Statistics.PrefixSelectivity("Apple pie"))
+					require.Equal(t, 0.0, info.Statistics.PrefixSelectivity("c"))
+				case "idx_test1_b":
+					require.EqualValues(t, 4, info.Statistics.Count)
+				case "idx_test2_a":
+					require.EqualValues(t, 1, info.Statistics.Count)
+					require.Equal(t, map[string]int64{"che": 1}, info.Statistics.Prefixes)
+				}
+			}
+		})
+	}
+
+	t.Run("LIKE with prefix", func(t *testing.T) {
+		db, tx, cleanup := testutil.NewTestTx(t)
+		defer cleanup()
+
+		testutil.MustExec(t, db, tx, `
+			CREATE TABLE test;
+			CREATE INDEX idx_test_a ON test(a);
+			INSERT INTO test(a) VALUES ('Apple'), ('apricot'), ('banana'), ('APPLE PIE'), ('ap'), (10);
+		`)
+
+		query := `SELECT a FROM test WHERE a LIKE 'ap_%' ORDER BY a`
+		expected := `[{"a": "APPLE PIE"}, {"a": "Apple"}, {"a": "apricot"}]`
+
+		check := func(plan string) {
+			t.Helper()
+
+			for q, exp := range map[string]string{query: expected, "EXPLAIN " + query: plan} {
+				res := testutil.MustQuery(t, db, tx, q)
+
+				var buf bytes.Buffer
+				err := testutil.IteratorToJSONArray(&buf, res)
+				res.Close()
+				require.NoError(t, err)
+				require.JSONEq(t, exp, buf.String())
+			}
+		}
+
+		// without statistics, the index is used
+		check(`[{"plan": "indexScan(\"idx_test_a\", [\"AP\", \"AP\\xff\"], [\"Ap\", \"Ap\\xff\"], [\"aP\", \"aP\\xff\"], [\"ap\", \"ap\\xff\"]) | filter(a LIKE \"ap_%\") | project(a) | sort(a)"}]`)
+
+		// with statistics, the prefix is not selective enough
+		// and the table is read sequentially
+		testutil.MustExec(t, db, tx, "ANALYZE test")
+		check(`[{"plan": "seqScan(test) | filter(a LIKE \"ap_%\") | project(a) | sort(a)"}]`)
+	})
+}
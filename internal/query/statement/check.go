@@ -0,0 +1,61 @@
+package statement
+
+import (
+	"github.com/genjidb/genji/document"
+	errs "github.com/genjidb/genji/errors"
+	"github.com/genjidb/genji/internal/stream"
+)
+
+// CheckIndexStmt is a statement that verifies the consistency of an index
+// with its table. It returns a document per inconsistency found, with the
+// kind of problem, the raw key of the document concerned and its primary key,
+// or NULL if the document doesn't exist.
+type CheckIndexStmt struct {
+	IndexName string
+}
+
+// IsReadOnly always returns true. It implements the Statement interface.
+func (stmt CheckIndexStmt) IsReadOnly() bool {
+	return true
+}
+
+// Run checks the index. It implements the Statement interface.
+func (stmt CheckIndexStmt) Run(ctx *Context) (Result, error) {
+	idx, err := ctx.Catalog.GetIndex(ctx.Tx, stmt.IndexName)
+	if err != nil {
+		return Result{}, err
+	}
+
+	tb, err := ctx.Catalog.GetTable(ctx.Tx, idx.Info.TableName)
+	if err != nil {
+		return Result{}, err
+	}
+
+	problems, err := idx.Check(tb)
+	if err != nil {
+		return Result{}, err
+	}
+
+	docs := make([]document.Document, 0, len(problems))
+	for _, p := range problems {
+		pk := document.NewNullValue()
+		d, err := tb.GetDocument(p.Key)
+		if err == nil {
+			pk, err = d.(document.Keyer).Key()
+		}
+		if err != nil && err != errs.ErrDocumentNotFound {
+			return Result{}, err
+		}
+
+		docs = append(docs, document.NewFieldBuffer().
+			Add("problem", document.NewTextValue(p.Kind)).
+			Add("key", document.NewBlobValue(p.Key)).
+			Add("pk", pk))
+	}
+
+	st := StreamStmt{
+		PreparedStream: stream.New(stream.Documents(docs...)),
+		ReadOnly:       true,
+	}
+	return st.Run(ctx)
+}
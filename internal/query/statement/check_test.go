@@ -0,0 +1,79 @@
+package statement_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckIndex(t *testing.T) {
+	db, tx, cleanup := testutil.NewTestTx(t)
+	defer cleanup()
+
+	testutil.MustExec(t, db, tx, `
+		CREATE TABLE foo(a INTEGER PRIMARY KEY, b INTEGER UNIQUE);
+		INSERT INTO foo (a, b) VALUES (1, 10), (2, 20), (3, 30), (4, 40);
+	`)
+
+	check := func() []string {
+		res := testutil.MustQuery(t, db, tx, "CHECK INDEX foo_b_idx")
+		defer res.Close()
+
+		var problems []string
+		err := res.Iterate(func(d document.Document) error {
+			p, err := d.GetByField("problem")
+			require.NoError(t, err)
+			pk, err := d.GetByField("pk")
+			require.NoError(t, err)
+			problems = append(problems, p.V.(string)+" "+pk.String())
+			return nil
+		})
+		require.NoError(t, err)
+		return problems
+	}
+
+	require.Empty(t, check())
+
+	tb, err := db.Catalog.GetTable(tx, "foo")
+	require.NoError(t, err)
+	idx, err := db.Catalog.GetIndex(tx, "foo_b_idx")
+	require.NoError(t, err)
+	// same index, without the unique constraint
+	info := *idx.Info
+	info.Unique = false
+	nonUnique := database.NewIndex(tx.Tx, info.IndexName, &info)
+
+	key := func(pk int64) []byte {
+		k, err := tb.EncodeValue(document.NewIntegerValue(pk))
+		require.NoError(t, err)
+		return k
+	}
+	values := func(v int64) []document.Value {
+		return []document.Value{document.NewIntegerValue(v)}
+	}
+
+	// delete a document without updating the index
+	require.NoError(t, tb.Store.Delete(key(1)))
+	// move the entries of documents 2 and 3
+	require.NoError(t, idx.Delete(values(20), key(2)))
+	require.NoError(t, nonUnique.Set(values(40), key(2)))
+	require.NoError(t, idx.Delete(values(30), key(3)))
+	require.NoError(t, idx.Set(values(35), key(3)))
+
+	require.Equal(t, []string{
+		"duplicate value 4",
+		"stale entry 2",
+		"missing entry 2",
+		"stale entry 3",
+		"missing entry 3",
+		"dangling entry NULL",
+	}, check())
+
+	t.Run("Unknown index", func(t *testing.T) {
+		err := testutil.Exec(db, tx, "CHECK INDEX unknown")
+		require.Error(t, err)
+	})
+}
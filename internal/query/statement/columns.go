@@ -0,0 +1,211 @@
+package statement
+
+import (
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/expr"
+	"github.com/genjidb/genji/internal/stream"
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// A Column describes a field of the documents returned by a statement.
+type Column struct {
+	// Name of the field. "*" stands for every field of the documents
+	// read by the statement.
+	Name string
+	// Type of the values of the field, inferred from the projected expression
+	// and the field constraints of the table. Zero if it cannot be inferred.
+	Type document.ValueType
+}
+
+// Columns returns the fields of the documents returned by the result, before iterating over it.
+// It returns nil if the statement doesn't return documents.
+func (r *Result) Columns() []Column {
+	if r.Iterator == nil {
+		return nil
+	}
+
+	it, ok := r.Iterator.(*StreamStmtIterator)
+	if !ok || it.Stream.Op == nil {
+		return nil
+	}
+
+	var info *database.TableInfo
+	// field constraints of the joined tables, prefixed with their names
+	var joined database.FieldConstraints
+	var project *stream.ProjectOperator
+	// documents built from expressions, when there is no FROM clause
+	var kvs *expr.KVPairs
+	// statements writing to a table only return documents if followed by a projection,
+	// i.e. with a RETURNING clause
+	returnsDocuments := true
+	for op := it.Stream.First(); op != nil; op = op.GetNext() {
+		switch t := op.(type) {
+		case *stream.ProjectOperator:
+			project, returnsDocuments = t, true
+		case *stream.ExprsOperator:
+			kvs = nil
+			if len(t.Exprs) == 1 {
+				kvs, _ = t.Exprs[0].(*expr.KVPairs)
+			}
+		case *stream.JoinOperator:
+			joined = joinedFieldConstraints(it.Context, t.Tables())
+		case *stream.SeqScanOperator:
+			info = tableInfo(it.Context, t.TableName)
+		case *stream.PkScanOperator:
+			info = tableInfo(it.Context, t.TableName)
+		case *stream.IndexScanOperator:
+			if it.Context.Catalog == nil {
+				break
+			}
+			idx, err := it.Context.Catalog.GetIndexInfo(t.IndexName)
+			if err == nil {
+				info = tableInfo(it.Context, idx.TableName)
+			}
+		case *stream.TableInsertOperator:
+			info, project, returnsDocuments = tableInfo(it.Context, t.Name), nil, false
+		case *stream.TableReplaceOperator:
+			info, project, returnsDocuments = tableInfo(it.Context, t.Name), nil, false
+		case *stream.TableDeleteOperator:
+			info, project, returnsDocuments = tableInfo(it.Context, t.Name), nil, false
+		}
+	}
+
+	if !returnsDocuments {
+		return nil
+	}
+
+	fcs := joined
+	if info != nil {
+		fcs = info.FieldConstraints
+	}
+
+	if project == nil && kvs != nil {
+		columns := make([]Column, len(kvs.Pairs))
+		for i, kv := range kvs.Pairs {
+			columns[i] = Column{Name: kv.K, Type: exprType(kv.V, info, fcs)}
+		}
+		return columns
+	}
+
+	// without projection, the stream outputs whole documents
+	if project == nil || len(project.Exprs) == 0 {
+		return []Column{{Name: "*"}}
+	}
+
+	columns := make([]Column, len(project.Exprs))
+	for i, e := range project.Exprs {
+		switch t := e.(type) {
+		case expr.Wildcard:
+			columns[i].Name = "*"
+		case *expr.NamedExpr:
+			columns[i] = Column{Name: t.Name(), Type: exprType(t.Expr, info, fcs)}
+		default:
+			columns[i] = Column{Name: stringutil.Sprintf("%s", e), Type: exprType(e, info, fcs)}
+		}
+	}
+
+	return columns
+}
+
+func tableInfo(ctx *Context, tableName string) *database.TableInfo {
+	if ctx.Catalog == nil {
+		return nil
+	}
+
+	info, err := ctx.Catalog.GetTableInfo(tableName)
+	if err != nil {
+		return nil
+	}
+
+	return info
+}
+
+// joinedFieldConstraints returns the field constraints of the given tables,
+// whose paths are prefixed with the name of their table, as in the documents returned by a join.
+func joinedFieldConstraints(ctx *Context, tables []string) database.FieldConstraints {
+	var fcs database.FieldConstraints
+	for _, name := range tables {
+		info := tableInfo(ctx, name)
+		if info == nil {
+			continue
+		}
+
+		for _, fc := range info.FieldConstraints {
+			path := append(document.Path{document.PathFragment{FieldName: name}}, fc.Path...)
+			fcs = append(fcs, &database.FieldConstraint{Path: path, Type: fc.Type})
+		}
+	}
+
+	return fcs
+}
+
+// exprType returns the type of the values returned by e, if it can be inferred
+// without evaluating it. Otherwise, it returns zero.
+// info is the table read by the statement, if any, and fcs its field constraints.
+func exprType(e expr.Expr, info *database.TableInfo, fcs database.FieldConstraints) document.ValueType {
+	switch t := e.(type) {
+	case expr.LiteralValue:
+		return t.Type
+	case expr.Path:
+		if fc := fcs.Get(document.Path(t)); fc != nil {
+			return fc.Type
+		}
+	case expr.Parentheses:
+		return exprType(t.E, info, fcs)
+	case *expr.NamedExpr:
+		return exprType(t.Expr, info, fcs)
+	case expr.CastFunc:
+		return t.CastAs
+	case *expr.PKFunc:
+		if info == nil {
+			break
+		}
+		if pks := fcs.GetPrimaryKeys(); len(pks) > 1 {
+			return document.ArrayValue
+		}
+		if pk := fcs.GetPrimaryKey(); pk != nil {
+			return pk.Type
+		}
+		if info.DocidStrategy == database.DocidULID {
+			return document.TextValue
+		}
+		return document.IntegerValue
+	case *expr.CountFunc:
+		return document.IntegerValue
+	case *expr.AvgFunc:
+		return document.DoubleValue
+	case *expr.SumFunc:
+		return numericType(exprType(t.Expr, info, fcs), document.IntegerValue)
+	case *expr.MinFunc:
+		return exprType(t.Expr, info, fcs)
+	case *expr.MaxFunc:
+		return exprType(t.Expr, info, fcs)
+	case *expr.AndOp, *expr.OrOp, *expr.NotOp, *expr.BoolAndFunc, *expr.BoolOrFunc:
+		return document.BoolValue
+	case *expr.ConcatOperator:
+		return document.TextValue
+	case expr.Operator:
+		if expr.IsComparisonOperator(t) {
+			return document.BoolValue
+		}
+		if expr.IsArithmeticOperator(t) {
+			return numericType(exprType(t.LeftHand(), info, fcs), exprType(t.RightHand(), info, fcs))
+		}
+	}
+
+	return 0
+}
+
+// numericType returns the type of the result of an arithmetic operation
+// between values of types a and b, or zero if it is unknown.
+func numericType(a, b document.ValueType) document.ValueType {
+	switch {
+	case a == document.IntegerValue && b == document.IntegerValue:
+		return document.IntegerValue
+	case a.IsNumber() && b.IsNumber():
+		return document.DoubleValue
+	}
+
+	return 0
+}
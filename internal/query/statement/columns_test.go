@@ -0,0 +1,78 @@
+package statement_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/query/statement"
+	"github.com/genjidb/genji/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultColumns(t *testing.T) {
+	db, tx, cleanup := testutil.NewTestTx(t)
+	defer cleanup()
+
+	testutil.MustExec(t, db, tx, `
+		CREATE TABLE test(a INTEGER PRIMARY KEY, b TEXT, c DOUBLE);
+		CREATE TABLE nopk;
+		CREATE TABLE ulids WITH (docid = 'ulid');
+		CREATE INDEX idx_test_b ON test(b);
+	`)
+
+	tests := []struct {
+		query    string
+		expected []statement.Column
+	}{
+		{"SELECT * FROM test", []statement.Column{{Name: "*"}}},
+		{"SELECT a, b, c, d FROM test", []statement.Column{
+			{Name: "a", Type: document.IntegerValue},
+			{Name: "b", Type: document.TextValue},
+			{Name: "c", Type: document.DoubleValue},
+			{Name: "d"},
+		}},
+		{"SELECT a AS x, *, b || 'foo' AS y FROM test", []statement.Column{
+			{Name: "x", Type: document.IntegerValue},
+			{Name: "*"},
+			{Name: "y", Type: document.TextValue},
+		}},
+		{"SELECT a FROM test WHERE b = 'foo'", []statement.Column{{Name: "a", Type: document.IntegerValue}}},
+		{"SELECT a FROM test WHERE a = 1", []statement.Column{{Name: "a", Type: document.IntegerValue}}},
+		{"SELECT a + 1, a + c, c > 1, CAST(d AS TEXT) FROM test", []statement.Column{
+			{Name: "a + 1", Type: document.IntegerValue},
+			{Name: "a + c", Type: document.DoubleValue},
+			{Name: "c > 1", Type: document.BoolValue},
+			{Name: "CAST(d AS text)", Type: document.TextValue},
+		}},
+		{"SELECT COUNT(*), AVG(a), SUM(a), MAX(b) FROM test", []statement.Column{
+			{Name: "COUNT(*)", Type: document.IntegerValue},
+			{Name: "AVG(a)", Type: document.DoubleValue},
+			{Name: "SUM(a)", Type: document.IntegerValue},
+			{Name: "MAX(b)", Type: document.TextValue},
+		}},
+		{"SELECT pk() FROM test", []statement.Column{{Name: "pk()", Type: document.IntegerValue}}},
+		{"SELECT pk() FROM nopk", []statement.Column{{Name: "pk()", Type: document.IntegerValue}}},
+		{"SELECT pk() FROM ulids", []statement.Column{{Name: "pk()", Type: document.TextValue}}},
+		{"SELECT 1, 'a', true", []statement.Column{
+			{Name: "1", Type: document.IntegerValue},
+			{Name: `"a"`, Type: document.TextValue},
+			{Name: "true", Type: document.BoolValue},
+		}},
+		{"SELECT test.b, nopk.b, test.a + 1 FROM test JOIN nopk ON test.a = nopk.a", []statement.Column{
+			{Name: "test.b", Type: document.TextValue},
+			{Name: "nopk.b"},
+			{Name: "test.a + 1", Type: document.IntegerValue},
+		}},
+		{"INSERT INTO test (a) VALUES (1)", nil},
+		{"INSERT INTO test (a) VALUES (2) RETURNING b", []statement.Column{{Name: "b", Type: document.TextValue}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			res := testutil.MustQuery(t, db, tx, test.query)
+			defer res.Close()
+
+			require.Equal(t, test.expected, res.Columns())
+		})
+	}
+}
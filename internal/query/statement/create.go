@@ -1,19 +1,30 @@
 package statement
 
 import (
+	"errors"
 	"math"
 
 	"github.com/genjidb/genji/document"
 	errs "github.com/genjidb/genji/errors"
 	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/planner"
+	"github.com/genjidb/genji/internal/stream"
 )
 
 // CreateTableStmt represents a parsed CREATE TABLE statement.
 type CreateTableStmt struct {
 	IfNotExists bool
 	Info        database.TableInfo
+	// Number of docids leased at once by the docid sequence of the table.
+	// Values leased but not used are lost if the database is not closed properly,
+	// a value of 1 stores the lease with every allocation to avoid any gap.
+	// If zero, DefaultDocidCache is used.
+	DocidCache uint64
 }
 
+// DefaultDocidCache is the default number of docids leased at once by tables without primary key.
+const DefaultDocidCache = 64
+
 // IsReadOnly always returns false. It implements the Statement interface.
 func (stmt *CreateTableStmt) IsReadOnly() bool {
 	return false
@@ -24,13 +35,27 @@ func (stmt *CreateTableStmt) IsReadOnly() bool {
 func (stmt *CreateTableStmt) Run(ctx *Context) (Result, error) {
 	var res Result
 
+	hasPK := len(stmt.Info.FieldConstraints.GetPrimaryKeys()) > 0
+	sequence := stmt.Info.DocidStrategy == "" || stmt.Info.DocidStrategy == database.DocidSequence
+	switch {
+	case hasPK && stmt.Info.DocidStrategy != "":
+		return res, errors.New("docid cannot be used on a table with a primary key")
+	case !sequence && stmt.DocidCache != 0:
+		return res, errors.New("docid_cache can only be used with the sequence docid strategy")
+	}
+
 	// if there is no primary key, create a docid sequence
-	if stmt.Info.FieldConstraints.GetPrimaryKey() == nil {
+	if !hasPK && sequence {
+		cache := stmt.DocidCache
+		if cache == 0 {
+			cache = DefaultDocidCache
+		}
+
 		seq := database.SequenceInfo{
 			IncrementBy: 1,
 			Min:         1, Max: math.MaxInt64,
 			Start: 1,
-			Cache: 64,
+			Cache: cache,
 			Owner: database.Owner{
 				TableName: stmt.Info.TableName,
 			},
@@ -41,6 +66,32 @@ func (stmt *CreateTableStmt) Run(ctx *Context) (Result, error) {
 		}
 
 		stmt.Info.DocidSequenceName = seq.Name
+	} else if stmt.DocidCache != 0 {
+		return res, errors.New("docid_cache cannot be used on a table with a primary key")
+	}
+
+	// create a sequence for every AUTOINCREMENT field
+	for _, fc := range stmt.Info.FieldConstraints {
+		if fc.Identity == nil {
+			continue
+		}
+
+		seq := database.SequenceInfo{
+			IncrementBy: 1,
+			Min:         1, Max: math.MaxInt64,
+			Start: 1,
+			Cache: DefaultDocidCache,
+			Owner: database.Owner{
+				TableName: stmt.Info.TableName,
+				Path:      fc.Path,
+			},
+		}
+		err := ctx.Catalog.CreateSequence(ctx.Tx, &seq)
+		if err != nil {
+			return res, err
+		}
+
+		fc.Identity.SequenceName = seq.Name
 	}
 
 	err := ctx.Catalog.CreateTable(ctx.Tx, stmt.Info.TableName, &stmt.Info)
@@ -50,10 +101,12 @@ func (stmt *CreateTableStmt) Run(ctx *Context) (Result, error) {
 		}
 	}
 
-	// create a unique index for every unique constraint
+	// create a unique index for every unique constraint.
+	// named constraints give their name to the index.
 	for _, fc := range stmt.Info.FieldConstraints {
 		if fc.IsUnique {
 			err = ctx.Catalog.CreateIndex(ctx.Tx, &database.IndexInfo{
+				IndexName: fc.UniqueName,
 				TableName: stmt.Info.TableName,
 				Paths:     []document.Path{fc.Path},
 				Unique:    true,
@@ -68,6 +121,15 @@ func (stmt *CreateTableStmt) Run(ctx *Context) (Result, error) {
 			}
 		}
 	}
+	if err != nil {
+		return res, err
+	}
+
+	// create the table storing the audit trail
+	if stmt.Info.Audit {
+		audit := CreateTableStmt{Info: database.TableInfo{TableName: database.AuditTableName(stmt.Info.TableName)}}
+		_, err = audit.Run(ctx)
+	}
 
 	return res, err
 }
@@ -126,3 +188,36 @@ func (stmt *CreateSequenceStmt) Run(ctx *Context) (Result, error) {
 	}
 	return res, err
 }
+
+// CreateViewStmt represents a parsed CREATE VIEW statement.
+type CreateViewStmt struct {
+	IfNotExists bool
+	Info        database.ViewInfo
+	// Stream of the SELECT statement of the view.
+	Stream *stream.Stream
+}
+
+// IsReadOnly always returns false. It implements the Statement interface.
+func (stmt *CreateViewStmt) IsReadOnly() bool {
+	return false
+}
+
+// Run the statement in the given transaction.
+// It implements the Statement interface.
+func (stmt *CreateViewStmt) Run(ctx *Context) (Result, error) {
+	var res Result
+
+	if stmt.IfNotExists {
+		if _, err := ctx.Catalog.GetViewInfo(stmt.Info.ViewName); err == nil {
+			return res, nil
+		}
+	}
+
+	// ensure the view only reads existing tables and views
+	err := planner.CheckView(stmt.Info.ViewName, stmt.Stream, ctx.Catalog)
+	if err != nil {
+		return res, err
+	}
+
+	return res, ctx.Catalog.CreateView(ctx.Tx, &stmt.Info)
+}
@@ -1,6 +1,7 @@
 package statement_test
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/genjidb/genji/document"
@@ -40,6 +41,9 @@ func TestCreateTable(t *testing.T) {
 		{"With incoherent constraint(document)", "CREATE TABLE test(a INTEGER, a.b TEXT);", true},
 		{"With incoherent constraint(array)", "CREATE TABLE test(a INTEGER, a[0] TEXT);", true},
 		{"With duplicate constraints", "CREATE TABLE test(a INTEGER, a TEXT);", true},
+		{"With docid strategy", "CREATE TABLE test WITH (docid = 'snowflake')", false},
+		{"With docid strategy and primary key", "CREATE TABLE test(a INTEGER PRIMARY KEY) WITH (docid = 'random')", true},
+		{"With docid strategy and docid cache", "CREATE TABLE test WITH (docid = 'ulid', docid_cache = 10)", true},
 	}
 
 	for _, test := range tests {
@@ -396,3 +400,75 @@ func TestCreateSequence(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateView(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		fails bool
+	}{
+		{"Basic", "CREATE VIEW v AS SELECT * FROM test", false},
+		{"If not exists", "CREATE VIEW IF NOT EXISTS v AS SELECT * FROM test", false},
+		{"If not exists, existing view", "CREATE VIEW IF NOT EXISTS vtest AS SELECT a FROM test", false},
+		{"Existing view", "CREATE VIEW vtest AS SELECT a FROM test", true},
+		{"Existing table", "CREATE VIEW test AS SELECT a FROM test", true},
+		{"Other view", "CREATE VIEW v AS SELECT a FROM vtest", false},
+		{"Join", "CREATE VIEW v AS SELECT * FROM test JOIN vtest ON test.a = vtest.a", false},
+		{"Unknown table", "CREATE VIEW v AS SELECT * FROM unknown", true},
+		{"Unknown table in subquery", "CREATE VIEW v AS SELECT * FROM test WHERE a IN (SELECT a FROM unknown)", true},
+		{"Self reference", "CREATE VIEW vtest2 AS SELECT * FROM vtest2", true},
+		{"Internal name", "CREATE VIEW __genji_v AS SELECT * FROM test", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, tx, cleanup := testutil.NewTestTx(t)
+			defer cleanup()
+
+			testutil.MustExec(t, db, tx, "CREATE TABLE test; CREATE VIEW vtest AS SELECT a FROM test")
+
+			err := testutil.Exec(db, tx, test.query)
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("Query", func(t *testing.T) {
+		db, tx, cleanup := testutil.NewTestTx(t)
+		defer cleanup()
+
+		testutil.MustExec(t, db, tx, `
+			CREATE TABLE test;
+			INSERT INTO test (a, b) VALUES (1, 'a'), (2, 'b'), (3, 'c');
+			CREATE VIEW v1 AS SELECT a, b FROM test WHERE a > 1;
+			CREATE VIEW v2 AS SELECT b FROM v1 WHERE a < 3;
+		`)
+
+		res := testutil.MustQuery(t, db, tx, "SELECT * FROM v1")
+		var buf bytes.Buffer
+		err := testutil.IteratorToJSONArray(&buf, res)
+		require.NoError(t, err)
+		require.NoError(t, res.Close())
+		require.JSONEq(t, `[{"a": 2, "b": "b"}, {"a": 3, "b": "c"}]`, buf.String())
+
+		// views read the current content of the tables
+		testutil.MustExec(t, db, tx, "INSERT INTO test (a, b) VALUES (2, 'd')")
+		res = testutil.MustQuery(t, db, tx, "SELECT * FROM v2")
+		buf.Reset()
+		err = testutil.IteratorToJSONArray(&buf, res)
+		require.NoError(t, err)
+		require.NoError(t, res.Close())
+		require.JSONEq(t, `[{"b": "b"}, {"b": "d"}]`, buf.String())
+
+		// views cannot be modified
+		err = testutil.Exec(db, tx, "INSERT INTO v1 (a) VALUES (10)")
+		require.Error(t, err)
+		err = testutil.Exec(db, tx, "DELETE FROM v1")
+		require.Error(t, err)
+		err = testutil.Exec(db, tx, "UPDATE v1 SET a = 10")
+		require.Error(t, err)
+	})
+}
@@ -8,7 +8,9 @@ import (
 	"github.com/genjidb/genji/internal/stringutil"
 )
 
-// DeleteConfig holds DELETE configuration.
+// DeleteStmt holds DELETE configuration.
+// If ORDER BY, LIMIT or OFFSET are set, the documents are sorted and limited
+// before being deleted, which allows trimming tables, e.g. deleting the oldest N documents.
 type DeleteStmt struct {
 	TableName        string
 	WhereExpr        expr.Expr
@@ -16,11 +18,16 @@ type DeleteStmt struct {
 	OrderBy          expr.Path
 	LimitExpr        expr.Expr
 	OrderByDirection scanner.Token
+
+	// Returning holds the expressions projected on the deleted documents, if any.
+	Returning []expr.Expr
 }
 
 func (stmt *DeleteStmt) ToStream() (*StreamStmt, error) {
 	s := stream.New(stream.SeqScan(stmt.TableName))
 
+	bindSubqueries(stmt.TableName, stmt.WhereExpr)
+
 	if stmt.WhereExpr != nil {
 		s = s.Pipe(stream.Filter(stmt.WhereExpr))
 	}
@@ -71,6 +78,11 @@ func (stmt *DeleteStmt) ToStream() (*StreamStmt, error) {
 
 	s = s.Pipe(stream.TableDelete(stmt.TableName))
 
+	if len(stmt.Returning) > 0 {
+		bindSubqueries(stmt.TableName, stmt.Returning...)
+		s = s.Pipe(stream.Project(stmt.Returning...))
+	}
+
 	return &StreamStmt{
 		Stream:   s,
 		ReadOnly: false,
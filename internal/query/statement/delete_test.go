@@ -22,6 +22,7 @@ func TestDeleteStmt(t *testing.T) {
 		{"With offset", "DELETE FROM test OFFSET 1", false, `[{"a":"foo1", "b":"bar1", "c":"baz1", "n": 3}]`, nil},
 		{"With order by then offset", "DELETE FROM test ORDER BY n OFFSET 1", false, `[{"d":"foo3", "b":"bar2", "e":"bar3", "n": 1}]`, nil},
 		{"With order by DESC then offset", "DELETE FROM test ORDER BY n DESC OFFSET 1", false, `[{"a": "foo1", "b": "bar1", "c": "baz1", "n": 3}]`, nil},
+		{"With limit only", "DELETE FROM test LIMIT 2", false, `[{"d":"foo3", "b":"bar2", "e":"bar3", "n": 1}]`, nil},
 		{"With limit", "DELETE FROM test ORDER BY n LIMIT 2", false, `[{"a":"foo1", "b":"bar1", "c":"baz1", "n": 3}]`, nil},
 		{"With order by then limit then offset", "DELETE FROM test ORDER BY n LIMIT 1 OFFSET 1", false, `[{"a": "foo1", "b": "bar1", "c": "baz1", "n": 3}, {"d": "foo3", "b": "bar2", "e": "bar3", "n": 1}]`, nil},
 		{"Table not found", "DELETE FROM foo WHERE b = 'bar1'", true, "[]", nil},
@@ -60,4 +61,62 @@ func TestDeleteStmt(t *testing.T) {
 			require.JSONEq(t, test.expected, buf.String())
 		})
 	}
+
+	t.Run("trim", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec(`
+			CREATE TABLE test (k INTEGER PRIMARY KEY, ts INTEGER);
+			CREATE INDEX idx_test_ts ON test (ts);
+			INSERT INTO test (k, ts) VALUES (1, 50), (2, 40), (3, 30), (4, 20), (5, 10);
+		`)
+		require.NoError(t, err)
+
+		// delete the two oldest documents
+		st, err := db.Query("DELETE FROM test WHERE ts > 0 ORDER BY ts LIMIT 2 RETURNING k")
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		err = testutil.IteratorToJSONArray(&buf, st)
+		require.NoError(t, err)
+		require.NoError(t, st.Close())
+		require.JSONEq(t, `[{"k": 5}, {"k": 4}]`, buf.String())
+
+		// the index is kept in sync
+		st, err = db.Query("SELECT k FROM test WHERE ts < 100")
+		require.NoError(t, err)
+		defer st.Close()
+
+		buf.Reset()
+		err = testutil.IteratorToJSONArray(&buf, st)
+		require.NoError(t, err)
+		require.JSONEq(t, `[{"k": 3}, {"k": 2}, {"k": 1}]`, buf.String())
+	})
+
+	t.Run("RETURNING", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec(`
+			CREATE TABLE test;
+			INSERT INTO test (a) VALUES (1), (2), (3);
+		`)
+		require.NoError(t, err)
+
+		st, err := db.Query("DELETE FROM test WHERE a > 1 RETURNING *, pk()")
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		err = testutil.IteratorToJSONArray(&buf, st)
+		require.NoError(t, err)
+		require.NoError(t, st.Close())
+		require.JSONEq(t, `[{"a": 2, "pk()": 2}, {"a": 3, "pk()": 3}]`, buf.String())
+
+		d, err := db.QueryDocument("SELECT COUNT(*) FROM test")
+		require.NoError(t, err)
+		testutil.RequireDocJSONEq(t, d, `{"COUNT(*)": 1}`)
+	})
 }
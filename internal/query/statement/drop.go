@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	errs "github.com/genjidb/genji/errors"
+	"github.com/genjidb/genji/internal/database"
 )
 
 // DropTableStmt is a DSL that allows creating a DROP TABLE query.
@@ -40,14 +41,31 @@ func (stmt DropTableStmt) Run(ctx *Context) (Result, error) {
 		return res, err
 	}
 
-	// if there is no primary key, drop the docid sequence
-	if tb.Info.FieldConstraints.GetPrimaryKey() == nil {
+	// drop the docid sequence, if any
+	if tb.Info.DocidSequenceName != "" {
 		err = ctx.Catalog.DropSequence(ctx.Tx, tb.Info.DocidSequenceName)
 		if err != nil {
 			return res, err
 		}
 	}
 
+	// drop the sequences of the AUTOINCREMENT fields
+	for _, fc := range tb.Info.FieldConstraints {
+		if fc.Identity == nil || fc.Identity.SequenceName == "" {
+			continue
+		}
+
+		err = ctx.Catalog.DropSequence(ctx.Tx, fc.Identity.SequenceName)
+		if err != nil {
+			return res, err
+		}
+	}
+
+	// drop the audit trail along with the table
+	if tb.Info.Audit {
+		_, err = DropTableStmt{TableName: database.AuditTableName(stmt.TableName)}.Run(ctx)
+	}
+
 	return res, err
 }
 
@@ -78,3 +96,31 @@ func (stmt DropIndexStmt) Run(ctx *Context) (Result, error) {
 
 	return res, err
 }
+
+// DropViewStmt is a DSL that allows creating a DROP VIEW query.
+type DropViewStmt struct {
+	ViewName string
+	IfExists bool
+}
+
+// IsReadOnly always returns false. It implements the Statement interface.
+func (stmt DropViewStmt) IsReadOnly() bool {
+	return false
+}
+
+// Run runs the DropView statement in the given transaction.
+// It implements the Statement interface.
+func (stmt DropViewStmt) Run(ctx *Context) (Result, error) {
+	var res Result
+
+	if stmt.ViewName == "" {
+		return res, errors.New("missing view name")
+	}
+
+	err := ctx.Catalog.DropView(ctx.Tx, stmt.ViewName)
+	if errs.IsNotFoundError(err) && stmt.IfExists {
+		err = nil
+	}
+
+	return res, err
+}
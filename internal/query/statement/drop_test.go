@@ -5,6 +5,7 @@ import (
 
 	"github.com/genjidb/genji"
 	"github.com/genjidb/genji/document"
+	errs "github.com/genjidb/genji/errors"
 	"github.com/genjidb/genji/internal/testutil"
 	"github.com/stretchr/testify/require"
 )
@@ -87,3 +88,31 @@ func TestDropIndex(t *testing.T) {
 	err = testutil.Exec(db, tx, "DROP INDEX test1_bar_idx")
 	require.Error(t, err)
 }
+
+func TestDropView(t *testing.T) {
+	db, tx, cleanup := testutil.NewTestTx(t)
+	defer cleanup()
+
+	testutil.MustExec(t, db, tx, `
+		CREATE TABLE test;
+		CREATE VIEW v1 AS SELECT * FROM test;
+		CREATE VIEW v2 AS SELECT * FROM test;
+	`)
+
+	testutil.MustExec(t, db, tx, "DROP VIEW v1")
+	require.Equal(t, []string{"v2"}, db.Catalog.ListViews())
+
+	_, err := db.Catalog.GetViewInfo("v1")
+	require.True(t, errs.IsNotFoundError(err))
+
+	// Dropping a non existing view with IF EXISTS should not fail.
+	err = testutil.Exec(db, tx, "DROP VIEW IF EXISTS v1")
+	require.NoError(t, err)
+
+	err = testutil.Exec(db, tx, "DROP VIEW v1")
+	require.Error(t, err)
+
+	// Tables cannot be dropped with DROP VIEW.
+	err = testutil.Exec(db, tx, "DROP VIEW test")
+	require.Error(t, err)
+}
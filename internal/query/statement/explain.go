@@ -5,6 +5,7 @@ import (
 
 	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/internal/expr"
+	"github.com/genjidb/genji/internal/planner"
 	"github.com/genjidb/genji/internal/stream"
 )
 
@@ -13,11 +14,18 @@ import (
 // is going to be executed, without executing it.
 type ExplainStmt struct {
 	Statement Statement
+
+	// If set, the plan is returned as a list of operators along with
+	// the number of documents each of them is estimated to return.
+	JSON bool
 }
 
 // Run analyses the inner statement and displays its execution plan.
 // If the statement is a stream, Optimize will be called prior to
 // displaying all the operations.
+// If JSON is set, the plan field is a list of documents describing every operation,
+// and the estimated_documents field is the number of documents the statement
+// is estimated to return.
 // Explain currently only works on SELECT, UPDATE, INSERT and DELETE statements.
 func (stmt *ExplainStmt) Run(ctx *Context) (Result, error) {
 	st, ok := stmt.Statement.(*StreamStmt)
@@ -30,6 +38,10 @@ func (stmt *ExplainStmt) Run(ctx *Context) (Result, error) {
 		return Result{}, err
 	}
 
+	if stmt.JSON {
+		return explainJSON(ctx, st.PreparedStream)
+	}
+
 	var plan string
 	if st.PreparedStream != nil {
 		plan = st.PreparedStream.String()
@@ -50,6 +62,39 @@ func (stmt *ExplainStmt) Run(ctx *Context) (Result, error) {
 	return newStatement.Run(ctx)
 }
 
+// explainJSON returns the operations of the prepared stream as a list of documents,
+// along with the estimated number of documents returned by each of them.
+func explainJSON(ctx *Context, prepared *stream.Stream) (Result, error) {
+	plan := document.NewValueBuffer()
+	var estimated int64
+
+	if prepared != nil {
+		for _, e := range planner.EstimateOperators(prepared, ctx.Catalog) {
+			fb := document.NewFieldBuffer().
+				Add("operator", document.NewTextValue(e.Operator.String())).
+				Add("estimated_documents", document.NewIntegerValue(e.Documents))
+			plan = plan.Append(document.NewDocumentValue(fb))
+			estimated = e.Documents
+		}
+	}
+
+	newStatement := StreamStmt{
+		PreparedStream: &stream.Stream{
+			Op: stream.Project(
+				&expr.NamedExpr{
+					ExprName: "plan",
+					Expr:     expr.LiteralValue(document.NewArrayValue(plan)),
+				},
+				&expr.NamedExpr{
+					ExprName: "estimated_documents",
+					Expr:     expr.LiteralValue(document.NewIntegerValue(estimated)),
+				}),
+		},
+		ReadOnly: true,
+	}
+	return newStatement.Run(ctx)
+}
+
 // IsReadOnly indicates that this statement doesn't write anything into
 // the database.
 func (s *ExplainStmt) IsReadOnly() bool {
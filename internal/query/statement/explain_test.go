@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
 	"github.com/stretchr/testify/require"
 )
 
@@ -67,3 +68,47 @@ func TestExplainStmt(t *testing.T) {
 		})
 	}
 }
+
+func TestExplainStmtJSON(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE test (k INTEGER PRIMARY KEY, a INTEGER);
+		CREATE INDEX idx_a ON test (a);
+		INSERT INTO test (k, a) VALUES (1, 1), (2, 1), (3, 2), (4, 2), (5, 3);
+		ANALYZE;
+	`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"EXPLAIN (FORMAT JSON) SELECT 1 + 1", `{"plan": [{"operator": "exprs({\"1 + 1\": 1 + 1})", "estimated_documents": 1}], "estimated_documents": 1}`},
+		{"EXPLAIN (FORMAT JSON) SELECT * FROM test", `{"plan": [{"operator": "seqScan(test)", "estimated_documents": 5}], "estimated_documents": 5}`},
+		{"EXPLAIN (FORMAT JSON) SELECT * FROM test WHERE k = 1", `{"plan": [{"operator": "pkScan(\"test\", 1)", "estimated_documents": 1}], "estimated_documents": 1}`},
+		{"EXPLAIN (FORMAT JSON) SELECT b FROM test WHERE b > 1 LIMIT 1", `{"plan": [
+			{"operator": "seqScan(test)", "estimated_documents": 5},
+			{"operator": "filter(b > 1)", "estimated_documents": 2},
+			{"operator": "project(b)", "estimated_documents": 2},
+			{"operator": "take(1)", "estimated_documents": 1}
+		], "estimated_documents": 1}`},
+		{"EXPLAIN (FORMAT JSON) DELETE FROM test", `{"plan": [
+			{"operator": "seqScan(test)", "estimated_documents": 5},
+			{"operator": "tableDelete('test')", "estimated_documents": 5}
+		], "estimated_documents": 5}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			d, err := db.QueryDocument(test.query)
+			require.NoError(t, err)
+
+			data, err := document.MarshalJSON(d)
+			require.NoError(t, err)
+			require.JSONEq(t, test.expected, string(data))
+		})
+	}
+}
@@ -5,8 +5,10 @@
 package statement_test
 
 import (
+	"math/rand"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/genjidb/genji"
 	"github.com/genjidb/genji/internal/testutil"
@@ -34,6 +36,10 @@ CREATE INDEX idx_c ON test_idx (c);
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO test VALUES ("a", 'b', 'c');`, func(t *testing.T) {
@@ -52,6 +58,10 @@ INSERT INTO test VALUES ("a", 'b', 'c');
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO test (a, b, c) VALUES ('a', 'b', 'c');`, func(t *testing.T) {
@@ -81,6 +91,10 @@ SELECT pk(), * FROM test;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO test (a) VALUES (a);`, func(t *testing.T) {
@@ -100,6 +114,10 @@ INSERT INTO test (a) VALUES (a);
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO test (a) VALUES (`+"`"+`a`+"`"+`);`, func(t *testing.T) {
@@ -119,6 +137,10 @@ INSERT INTO test (a) VALUES (` + "`" + `a` + "`" + `);
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO test (a, `+"`"+`foo bar`+"`"+`) VALUES ('c', 'd');`, func(t *testing.T) {
@@ -147,6 +169,10 @@ SELECT pk(), * FROM test;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO test (a, b, c) VALUES ("a", 'b', [1, 2, 3]);`, func(t *testing.T) {
@@ -176,6 +202,10 @@ SELECT pk(), * FROM test;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO test (a, b, c) VALUES ("a", 'b', {c: 1, d: c + 1});`, func(t *testing.T) {
@@ -208,6 +238,10 @@ SELECT pk(), * FROM test;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO test VALUES {a: 'a', b: 2.3, c: 1 = 1};`, func(t *testing.T) {
@@ -237,6 +271,10 @@ SELECT pk(), * FROM test;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO test VALUES {a: [1, 2, 3]};`, func(t *testing.T) {
@@ -268,6 +306,10 @@ SELECT pk(), * FROM test;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO test VALUES {'a': 'a', b: 2.3};`, func(t *testing.T) {
@@ -295,6 +337,10 @@ SELECT pk(), * FROM test;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO test VALUES {"a": "b"};`, func(t *testing.T) {
@@ -322,6 +368,10 @@ SELECT pk(), * FROM test;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO test VALUES {a: 400, b: a * 4};`, func(t *testing.T) {
@@ -346,6 +396,10 @@ SELECT pk(), * FROM test;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO test_idx VALUES ("a", 'b', 'c');`, func(t *testing.T) {
@@ -364,6 +418,10 @@ INSERT INTO test_idx VALUES ("a", 'b', 'c');
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO test_idx (a, b, c) VALUES ('a', 'b', 'c');`, func(t *testing.T) {
@@ -393,6 +451,10 @@ SELECT pk(), * FROM test_idx;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO test_idx (a) VALUES (a);`, func(t *testing.T) {
@@ -412,6 +474,10 @@ INSERT INTO test_idx (a) VALUES (a);
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO test_idx (a) VALUES (`+"`"+`a`+"`"+`);`, func(t *testing.T) {
@@ -431,6 +497,10 @@ INSERT INTO test_idx (a) VALUES (` + "`" + `a` + "`" + `);
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO test_idx (a, `+"`"+`foo bar`+"`"+`) VALUES ('c', 'd');`, func(t *testing.T) {
@@ -459,6 +529,10 @@ SELECT pk(), * FROM test_idx;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO test_idx (a, b, c) VALUES ("a", 'b', [1, 2, 3]);`, func(t *testing.T) {
@@ -488,6 +562,10 @@ SELECT pk(), * FROM test_idx;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO test_idx (a, b, c) VALUES ("a", 'b', {c: 1, d: c + 1});`, func(t *testing.T) {
@@ -520,6 +598,10 @@ SELECT pk(), * FROM test_idx;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO test_idx VALUES {a: 'a', b: 2.3, c: 1 = 1};`, func(t *testing.T) {
@@ -549,6 +631,10 @@ SELECT pk(), * FROM test_idx;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO test_idx VALUES {a: [1, 2, 3]};`, func(t *testing.T) {
@@ -580,6 +666,10 @@ SELECT pk(), * FROM test_idx;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO test_idx VALUES {'a': 'a', b: 2.3};`, func(t *testing.T) {
@@ -607,6 +697,10 @@ SELECT pk(), * FROM test_idx;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO test_idx VALUES {"a": "b"};`, func(t *testing.T) {
@@ -634,6 +728,10 @@ SELECT pk(), * FROM test_idx;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO test_idx VALUES {a: 400, b: a * 4};`, func(t *testing.T) {
@@ -658,6 +756,10 @@ SELECT pk(), * FROM test_idx;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO __genji_catalog VALUES {a: 400, b: a * 4};`, func(t *testing.T) {
@@ -677,6 +779,10 @@ INSERT INTO __genji_catalog VALUES {a: 400, b: a * 4};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE testpk (foo INTEGER PRIMARY KEY);`, func(t *testing.T) {
@@ -706,6 +812,10 @@ INSERT INTO testpk (bar, foo) VALUES (1, 2);
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO test (`+"`"+`pk()`+"`"+`, `+"`"+`key`+"`"+`) VALUES (1, 2);`, func(t *testing.T) {
@@ -725,6 +835,10 @@ INSERT INTO test (` + "`" + `pk()` + "`" + `, ` + "`" + `key` + "`" + `) VALUES
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_tc(`, func(t *testing.T) {
@@ -774,6 +888,10 @@ SELECT * FROM test_tc;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_ic(a INTEGER, s.b TEXT);`, func(t *testing.T) {
@@ -793,6 +911,10 @@ INSERT INTO test_ic VALUES {s: 1};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_oc(a INTEGER UNIQUE, b INTEGER PRIMARY KEY, c INTEGER UNIQUE DEFAULT 10);`, func(t *testing.T) {
@@ -831,6 +953,10 @@ SELECT * FROM test_oc;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_oc(a INTEGER PRIMARY KEY);`, func(t *testing.T) {
@@ -861,6 +987,10 @@ SELECT * FROM test_oc;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_oc(a INTEGER UNIQUE);`, func(t *testing.T) {
@@ -891,6 +1021,10 @@ SELECT * FROM test_oc;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_oc(a INTEGER NOT NULL);`, func(t *testing.T) {
@@ -910,6 +1044,10 @@ INSERT INTO test_oc (b, c) VALUES (1, 1) ON CONFLICT DO REPLACE;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_oc(a INTEGER UNIQUE);`, func(t *testing.T) {
@@ -933,6 +1071,56 @@ SELECT * FROM test_oc;
 {
   a: 3
 }
+`
+			testutil.RequireStreamEq(t, raw, res)
+		})
+
+	})
+
+	// --------------------------------------------------------------------------
+	t.Run("ulid and snowflake docids", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
+		setup(t, db)
+
+		t.Run(`CREATE TABLE test_ulid WITH (docid = 'ulid');`, func(t *testing.T) {
+			q := `
+CREATE TABLE test_ulid WITH (docid = 'ulid');
+CREATE TABLE test_snowflake WITH (docid = 'snowflake');
+INSERT INTO test_ulid (a) VALUES (1);
+INSERT INTO test_snowflake (a) VALUES (1);
+SELECT pk(), a FROM test_ulid;
+`
+			res, err := db.Query(q)
+			require.NoError(t, err)
+			defer res.Close()
+			raw := `
+{
+  "pk()": "01ETXKWW00ZG3J30K59WB3YQRF",
+  "a": 1.0
+}
+`
+			testutil.RequireStreamEq(t, raw, res)
+		})
+
+		t.Run(`SELECT pk(), a FROM test_snowflake;`, func(t *testing.T) {
+			q := `
+SELECT pk(), a FROM test_snowflake;
+`
+			res, err := db.Query(q)
+			require.NoError(t, err)
+			defer res.Close()
+			raw := `
+{
+  "pk()": 3133441,
+  "a": 1.0
+}
 `
 			testutil.RequireStreamEq(t, raw, res)
 		})
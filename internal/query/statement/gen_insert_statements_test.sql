@@ -361,4 +361,24 @@ SELECT * FROM test_oc;
 {
   a: 3
 }
-*/
\ No newline at end of file
+*/
+-- test: ulid and snowflake docids
+CREATE TABLE test_ulid WITH (docid = 'ulid');
+CREATE TABLE test_snowflake WITH (docid = 'snowflake');
+INSERT INTO test_ulid (a) VALUES (1);
+INSERT INTO test_snowflake (a) VALUES (1);
+SELECT pk(), a FROM test_ulid;
+/* result:
+{
+  "pk()": "01ETXKWW00ZG3J30K59WB3YQRF",
+  "a": 1.0
+}
+*/
+
+SELECT pk(), a FROM test_snowflake;
+/* result:
+{
+  "pk()": 3133441,
+  "a": 1.0
+}
+*/
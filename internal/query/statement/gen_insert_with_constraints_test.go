@@ -5,7 +5,9 @@
 package statement_test
 
 import (
+	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/genjidb/genji"
 	"github.com/stretchr/testify/require"
@@ -27,6 +29,10 @@ func TestGenInsertWithConstraints(t *testing.T) {
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a NOT NULL);`, func(t *testing.T) {
@@ -46,6 +52,10 @@ INSERT INTO test_e VALUES {};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a ARRAY NOT NULL);`, func(t *testing.T) {
@@ -65,6 +75,10 @@ INSERT INTO test_e VALUES {};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a ARRAY NOT NULL);`, func(t *testing.T) {
@@ -84,6 +98,10 @@ INSERT INTO test_e VALUES {a: 42};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a BLOB);`, func(t *testing.T) {
@@ -103,6 +121,10 @@ INSERT INTO test_e {a: true};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a BLOB NOT NULL);`, func(t *testing.T) {
@@ -122,6 +144,10 @@ INSERT INTO test_e {};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a BLOB NOT NULL);`, func(t *testing.T) {
@@ -141,6 +167,10 @@ INSERT INTO test_e {a: 42};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a BOOL NOT NULL);`, func(t *testing.T) {
@@ -160,6 +190,10 @@ INSERT INTO test_e {};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a BYTES);`, func(t *testing.T) {
@@ -179,6 +213,10 @@ INSERT INTO test_e {a: [1,2,3]};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a BYTES NOT NULL);`, func(t *testing.T) {
@@ -198,6 +236,10 @@ INSERT INTO test_e {};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a BYTES NOT NULL);`, func(t *testing.T) {
@@ -217,6 +259,10 @@ INSERT INTO test_e {a: 42};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a DOCUMENT);`, func(t *testing.T) {
@@ -236,6 +282,10 @@ INSERT INTO test_e {"a": "foo"};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a DOCUMENT NOT NULL);`, func(t *testing.T) {
@@ -255,6 +305,10 @@ INSERT INTO test_e {};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a DOCUMENT NOT NULL);`, func(t *testing.T) {
@@ -274,6 +328,10 @@ INSERT INTO test_e {a: false};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a DOUBLE);`, func(t *testing.T) {
@@ -293,6 +351,10 @@ INSERT INTO test_e {a: "foo"};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a DOUBLE NOT NULL);`, func(t *testing.T) {
@@ -312,6 +374,10 @@ INSERT INTO test_e {};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a DOUBLE NOT NULL);`, func(t *testing.T) {
@@ -331,6 +397,10 @@ INSERT INTO test_e {a: [1,2,3]};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a DOUBLE PRECISION);`, func(t *testing.T) {
@@ -350,6 +420,10 @@ INSERT INTO test_e {a: "foo"};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a DOUBLE PRECISION NOT NULL);`, func(t *testing.T) {
@@ -369,6 +443,10 @@ INSERT INTO test_e {};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a DOUBLE PRECISION NOT NULL);`, func(t *testing.T) {
@@ -388,6 +466,10 @@ INSERT INTO test_e {a: [1,2,3]};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a REAL);`, func(t *testing.T) {
@@ -407,6 +489,10 @@ INSERT INTO test_e {a: "foo"};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a REAL NOT NULL);`, func(t *testing.T) {
@@ -426,6 +512,10 @@ INSERT INTO test_e {};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a REAL NOT NULL);`, func(t *testing.T) {
@@ -445,6 +535,10 @@ INSERT INTO test_e {a: [1,2,3]};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a INTEGER);`, func(t *testing.T) {
@@ -464,6 +558,10 @@ INSERT INTO test_e {a: "foo"};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a INTEGER NOT NULL);`, func(t *testing.T) {
@@ -483,6 +581,10 @@ INSERT INTO test_e {};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a INTEGER NOT NULL);`, func(t *testing.T) {
@@ -502,6 +604,10 @@ INSERT INTO test_e {a: [1,2,3]};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a INT2);`, func(t *testing.T) {
@@ -521,6 +627,10 @@ INSERT INTO test_e {a: "foo"};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a INT2 NOT NULL);`, func(t *testing.T) {
@@ -540,6 +650,10 @@ INSERT INTO test_e {};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a INT NOT NULL);`, func(t *testing.T) {
@@ -559,6 +673,10 @@ INSERT INTO test_e {a: [1,2,3]};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a INT8);`, func(t *testing.T) {
@@ -578,6 +696,10 @@ INSERT INTO test_e {a: "foo"};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a INT8 NOT NULL);`, func(t *testing.T) {
@@ -597,6 +719,10 @@ INSERT INTO test_e {};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a INT8 NOT NULL);`, func(t *testing.T) {
@@ -616,6 +742,10 @@ INSERT INTO test_e {a: [1,2,3]};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a TINYINT);`, func(t *testing.T) {
@@ -635,6 +765,10 @@ INSERT INTO test_e {a: "foo"};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a TINYINT NOT NULL);`, func(t *testing.T) {
@@ -654,6 +788,10 @@ INSERT INTO test_e {};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a TINYINT NOT NULL);`, func(t *testing.T) {
@@ -673,6 +811,10 @@ INSERT INTO test_e {a: [1,2,3]};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a BIGINT);`, func(t *testing.T) {
@@ -692,6 +834,10 @@ INSERT INTO test_e {a: "foo"};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a BIGINT NOT NULL);`, func(t *testing.T) {
@@ -711,6 +857,10 @@ INSERT INTO test_e {};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a BIGINT NOT NULL);`, func(t *testing.T) {
@@ -730,6 +880,10 @@ INSERT INTO test_e {a: [1,2,3]};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a SMALLINT);`, func(t *testing.T) {
@@ -749,6 +903,10 @@ INSERT INTO test_e {a: "foo"};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a SMALLINT NOT NULL);`, func(t *testing.T) {
@@ -768,6 +926,10 @@ INSERT INTO test_e {};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a SMALLINT NOT NULL);`, func(t *testing.T) {
@@ -787,6 +949,10 @@ INSERT INTO test_e {a: [1,2,3]};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a MEDIUMINT);`, func(t *testing.T) {
@@ -806,6 +972,10 @@ INSERT INTO test_e {a: "foo"};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a MEDIUMINT NOT NULL);`, func(t *testing.T) {
@@ -825,6 +995,10 @@ INSERT INTO test_e {};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a MEDIUMINT NOT NULL);`, func(t *testing.T) {
@@ -844,6 +1018,10 @@ INSERT INTO test_e {a: [1,2,3]};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a TEXT NOT NULL);`, func(t *testing.T) {
@@ -863,6 +1041,10 @@ INSERT INTO test_e {};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a VARCHAR(255) NOT NULL);`, func(t *testing.T) {
@@ -882,6 +1064,10 @@ INSERT INTO test_e {};
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`CREATE TABLE test_e (a CHARACTER(64) NOT NULL);`, func(t *testing.T) {
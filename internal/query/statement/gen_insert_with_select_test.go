@@ -5,7 +5,9 @@
 package statement_test
 
 import (
+	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/genjidb/genji"
 	"github.com/genjidb/genji/internal/testutil"
@@ -31,6 +33,10 @@ INSERT INTO bar (a, b) VALUES (1, 10);
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO foo SELECT * FROM foo;`, func(t *testing.T) {
@@ -49,6 +55,10 @@ INSERT INTO foo SELECT * FROM foo;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO foo SELECT * FROM bar;`, func(t *testing.T) {
@@ -73,6 +83,10 @@ SELECT pk(), * FROM foo;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO foo SELECT a FROM bar;`, func(t *testing.T) {
@@ -97,6 +111,10 @@ SELECT pk(), * FROM foo;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO foo (a, b) SELECT * FROM bar;`, func(t *testing.T) {
@@ -121,6 +139,10 @@ SELECT pk(), * FROM foo;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO foo (c, d) SELECT a, b FROM bar;`, func(t *testing.T) {
@@ -145,6 +167,10 @@ SELECT pk(), * FROM foo;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO foo (c) SELECT * FROM bar;`, func(t *testing.T) {
@@ -163,6 +189,10 @@ INSERT INTO foo (c) SELECT * FROM bar;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO foo (c, d) SELECT a, b, c FROM bar;`, func(t *testing.T) {
@@ -181,6 +211,10 @@ INSERT INTO foo (c, d) SELECT a, b, c FROM bar;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO foo (c, d, e) SELECT * FROM bar;`, func(t *testing.T) {
@@ -199,6 +233,10 @@ INSERT INTO foo (c, d, e) SELECT * FROM bar;
 		require.NoError(t, err)
 		defer db.Close()
 
+		// values depending on time or randomness are the same on every run
+		db.SetClock(genji.NewManualClock(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		db.SetRand(rand.New(rand.NewSource(1)))
+
 		setup(t, db)
 
 		t.Run(`INSERT INTO foo (c, d) SELECT a FROM bar`+"`"+`;`, func(t *testing.T) {
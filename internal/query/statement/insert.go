@@ -27,7 +27,7 @@ func (stmt *InsertStmt) ToStream() (*StreamStmt, error) {
 		s = stmt.SelectStmt.Stream
 
 		// ensure we are not reading and writing to the same table.
-		if s.First().(*stream.SeqScanOperator).TableName == stmt.TableName {
+		if scan, ok := s.First().(*stream.SeqScanOperator); ok && scan.TableName == stmt.TableName {
 			return nil, errors.New("cannot read and write to the same table")
 		}
 
@@ -210,3 +210,83 @@ func TestInsertSelect(t *testing.T) {
 		})
 	}
 }
+
+func TestInsertOnConflictOnConstraint(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		fails    bool
+		expected string
+	}{
+		{"Unique / Do nothing", `INSERT INTO foo (a, b) VALUES (2, 10) ON CONFLICT ON CONSTRAINT uq_b DO NOTHING`, false, `[{"a":1, "b":10}]`},
+		{"Primary key / Do nothing", `INSERT INTO foo (a, b) VALUES (1, 20) ON CONFLICT ON CONSTRAINT pk_a DO NOTHING`, false, `[{"a":1, "b":10}]`},
+		{"Primary key / Do replace", `INSERT INTO foo (a, b, c) VALUES (1, 20, 3) ON CONFLICT ON CONSTRAINT pk_a DO REPLACE`, false, `[{"a":1, "b":20, "c":3}]`},
+		{"Other constraint", `INSERT INTO foo (a, b) VALUES (1, 20) ON CONFLICT ON CONSTRAINT uq_b DO NOTHING`, true, ``},
+		{"Unknown constraint", `INSERT INTO foo (a, b) VALUES (1, 20) ON CONFLICT ON CONSTRAINT uq_c DO NOTHING`, true, ``},
+		{"No conflict", `INSERT INTO foo (a, b) VALUES (2, 20) ON CONFLICT ON CONSTRAINT uq_b DO NOTHING`, false, `[{"a":1, "b":10}, {"a":2, "b":20}]`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, err := genji.Open(":memory:")
+			require.NoError(t, err)
+			defer db.Close()
+
+			err = db.Exec(`
+				CREATE TABLE foo (a INTEGER, b INTEGER, CONSTRAINT pk_a PRIMARY KEY (a), CONSTRAINT uq_b UNIQUE (b));
+				INSERT INTO foo (a, b) VALUES (1, 10)
+			`)
+			require.NoError(t, err)
+
+			err = db.Exec(test.query)
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			st, err := db.Query("SELECT * FROM foo")
+			require.NoError(t, err)
+			defer st.Close()
+
+			var buf bytes.Buffer
+			err = testutil.IteratorToJSONArray(&buf, st)
+			require.NoError(t, err)
+			require.JSONEq(t, test.expected, buf.String())
+		})
+	}
+}
+
+func TestInsertAutoincrement(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE foo (id INTEGER PRIMARY KEY AUTOINCREMENT, a TEXT);
+		INSERT INTO foo (a) VALUES ('a'), ('b');
+		INSERT INTO foo (id, a) VALUES (10, 'c');
+		INSERT INTO foo (id, a) VALUES (NULL, 'd');
+		INSERT INTO foo (id, a) VALUES (5, 'e');
+		INSERT INTO foo VALUES {a: 'f'};
+	`)
+	require.NoError(t, err)
+
+	st, err := db.Query("SELECT * FROM foo")
+	require.NoError(t, err)
+	defer st.Close()
+
+	var buf bytes.Buffer
+	err = testutil.IteratorToJSONArray(&buf, st)
+	require.NoError(t, err)
+	require.JSONEq(t, `[
+		{"id": 1, "a": "a"}, {"id": 2, "a": "b"}, {"id": 5, "a": "e"},
+		{"id": 10, "a": "c"}, {"id": 11, "a": "d"}, {"id": 12, "a": "f"}
+	]`, buf.String())
+
+	// the sequence is dropped along with the table
+	err = db.Exec("DROP TABLE foo")
+	require.NoError(t, err)
+	_, err = db.QueryDocument("SELECT * FROM __genji_catalog WHERE name = 'foo_id_seq'")
+	require.Error(t, err)
+}
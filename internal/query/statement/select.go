@@ -3,6 +3,8 @@ package statement
 import (
 	"errors"
 
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/database"
 	"github.com/genjidb/genji/internal/environment"
 	"github.com/genjidb/genji/internal/expr"
 	"github.com/genjidb/genji/internal/sql/scanner"
@@ -13,9 +15,13 @@ import (
 // SelectStmt holds SELECT configuration.
 type SelectStmt struct {
 	TableName        string
+	TableFunction    stream.Operator
+	AsOfExpr         expr.Expr
+	Joins            []JoinClause
 	Distinct         bool
 	WhereExpr        expr.Expr
 	GroupByExpr      expr.Expr
+	GapFill          bool
 	OrderBy          expr.Path
 	OrderByDirection scanner.Token
 	OffsetExpr       expr.Expr
@@ -25,6 +31,19 @@ type SelectStmt struct {
 		All        bool
 		SelectStmt *StreamStmt
 	}
+
+	// Common table expressions defined by the WITH clause, which are read
+	// instead of the tables with the same name.
+	CTEs map[string]*stream.CommonTableExpr
+	// Name of the recursive common table expression whose recursive part
+	// is this statement, if any. It refers to the documents of the previous iteration.
+	WorkingTable string
+}
+
+// JoinClause holds the configuration of a JOIN clause.
+type JoinClause struct {
+	TableName string
+	On        expr.Expr
 }
 
 func (stmt *SelectStmt) ToStream() (*StreamStmt, error) {
@@ -32,8 +51,40 @@ func (stmt *SelectStmt) ToStream() (*StreamStmt, error) {
 
 	var s *stream.Stream
 
-	if stmt.TableName != "" {
-		s = stream.New(stream.SeqScan(stmt.TableName))
+	switch {
+	case stmt.TableFunction != nil:
+		s = stream.New(stmt.TableFunction)
+	case stmt.TableName != "":
+		if stmt.AsOfExpr != nil && stmt.isCTE(stmt.TableName) {
+			return nil, stringutil.Errorf("FOR SYSTEM_TIME cannot be used with common table expression %q", stmt.TableName)
+		}
+		s = stream.New(stmt.scanTable(stmt.TableName, stmt.AsOfExpr))
+	}
+
+	if len(stmt.Joins) > 0 {
+		if stmt.AsOfExpr != nil {
+			return nil, errors.New("FOR SYSTEM_TIME cannot be used with JOIN")
+		}
+
+		// the documents of each table are stored in a field named after it,
+		// the left side of the following joins returns joined documents.
+		leftName := stmt.TableName
+		tables := map[string]bool{stmt.TableName: true}
+		for _, j := range stmt.Joins {
+			if tables[j.TableName] {
+				return nil, stringutil.Errorf("table %q is specified more than once", j.TableName)
+			}
+			tables[j.TableName] = true
+
+			s = stream.New(stream.Join(s, stream.New(stmt.scanTable(j.TableName, nil)), leftName, j.TableName, j.On))
+			leftName = ""
+		}
+	}
+
+	// subqueries can refer to the documents of the table, but not to joined documents
+	if stmt.TableName != "" && len(stmt.Joins) == 0 {
+		bindSubqueries(stmt.TableName, stmt.WhereExpr)
+		bindSubqueries(stmt.TableName, stmt.ProjectionExprs...)
 	}
 
 	if stmt.WhereExpr != nil {
@@ -64,6 +115,12 @@ func (stmt *SelectStmt) ToStream() (*StreamStmt, error) {
 
 			// check if this is the same expression as the one used in the GROUP BY clause
 			if expr.Equal(e, stmt.GroupByExpr) {
+				// the value of the group is stored in a field named after the expression,
+				// read it rather than evaluating the expression against the aggregated document.
+				// Nested paths like a.b are read from a field named "a.b" as well.
+				if p, ok := e.(expr.Path); !ok || len(p) > 1 {
+					ne.Expr = expr.Path{document.PathFragment{FieldName: stmt.GroupByExpr.String()}}
+				}
 				continue
 			}
 
@@ -78,6 +135,14 @@ func (stmt *SelectStmt) ToStream() (*StreamStmt, error) {
 
 		// add Aggregation node
 		s = s.Pipe(stream.HashAggregate(aggregators...))
+
+		if stmt.GapFill {
+			bucket, ok := stmt.GroupByExpr.(*expr.DateBinFunc)
+			if !ok {
+				return nil, errors.New("GAPFILL requires grouping by date_bin() or time_bucket()")
+			}
+			s = s.Pipe(stream.GapFill(bucket, aggregators...))
+		}
 	} else {
 		// if there is no GROUP BY clause, check if there are any aggregation function
 		// and if so add an aggregation node
@@ -103,7 +168,7 @@ func (stmt *SelectStmt) ToStream() (*StreamStmt, error) {
 	}
 
 	// If there is no FROM clause ensure there is no wildcard or path
-	if stmt.TableName == "" {
+	if stmt.TableName == "" && stmt.TableFunction == nil {
 		var err error
 
 		for _, e := range stmt.ProjectionExprs {
@@ -208,3 +273,46 @@ func (stmt *SelectStmt) ToStream() (*StreamStmt, error) {
 		ReadOnly: isReadOnly,
 	}, nil
 }
+
+// bindSubqueries allows the subqueries used by exprs to refer to the documents
+// of the given table.
+func bindSubqueries(tableName string, exprs ...expr.Expr) {
+	for _, e := range exprs {
+		expr.Walk(e, func(e expr.Expr) bool {
+			if sq, ok := e.(*expr.Subquery); ok {
+				sq.OuterName = tableName
+			}
+			return true
+		})
+	}
+}
+
+// isCTE returns true if tableName refers to a common table expression or to its working table.
+func (stmt *SelectStmt) isCTE(tableName string) bool {
+	_, ok := stmt.CTEs[tableName]
+	return ok || tableName == stmt.WorkingTable
+}
+
+// scanTable returns the operator reading the given table, as of the given time if not nil.
+// Common table expressions are read instead of the tables with the same name.
+func (stmt *SelectStmt) scanTable(tableName string, asOf expr.Expr) stream.Operator {
+	if tableName == stmt.WorkingTable {
+		return stream.WorkingTableScan(tableName)
+	}
+	if cte, ok := stmt.CTEs[tableName]; ok {
+		return stream.CTEScan(cte)
+	}
+
+	switch {
+	case tableName == database.TablesStatsTableName && asOf == nil:
+		return stream.TablesStats()
+	case tableName == database.ThrottleStatsTableName && asOf == nil:
+		return stream.ThrottleStats()
+	case tableName == database.PlanChangesTableName && asOf == nil:
+		return stream.PlanChanges()
+	case asOf != nil:
+		return stream.HistoryScan(tableName, asOf)
+	}
+
+	return stream.SeqScan(tableName)
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/genjidb/genji"
 	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/environment"
 	"github.com/genjidb/genji/internal/testutil"
 	"github.com/stretchr/testify/require"
 )
@@ -57,6 +58,9 @@ func TestSelectStmt(t *testing.T) {
 		{"With group by and count", "SELECT COUNT(k) FROM test GROUP BY size", false, `[{"COUNT(k)":2},{"COUNT(k)":1}]`, nil},
 		{"With group by and count wildcard", "SELECT COUNT(*  ) FROM test GROUP BY size", false, `[{"COUNT(*)":2},{"COUNT(*)":1}]`, nil},
 		{"With order by", "SELECT * FROM test ORDER BY color", false, `[{"k":3,"height":100,"weight":200},{"k":2,"color":"blue","size":10,"weight":100},{"k":1,"color":"red","size":10,"shape":"square"}]`, nil},
+		{"With group by expr", "SELECT size % 3 AS m, COUNT(*) AS c FROM test GROUP BY size % 3", false, `[{"m":1,"c":2},{"m":null,"c":1}]`, nil},
+		{"With group by date_bin and gapfill", "SELECT date_bin(50, weight) AS b, COUNT(*) AS c FROM test GROUP BY date_bin(50, weight) GAPFILL", false, `[{"b":null,"c":1},{"b":100,"c":1},{"b":150,"c":0},{"b":200,"c":1}]`, nil},
+		{"With invalid gapfill", "SELECT COUNT(*) FROM test GROUP BY size GAPFILL", true, ``, nil},
 		{"With invalid group by / wildcard", "SELECT * FROM test WHERE age = 10 GROUP BY a.b.c", true, ``, nil},
 		{"With invalid group by / a.b", "SELECT a.b FROM test WHERE age = 10 GROUP BY a.b.c", true, ``, nil},
 		{"With order by", "SELECT * FROM test ORDER BY color", false, `[{"k":3,"height":100,"weight":200},{"k":2,"color":"blue","size":10,"weight":100},{"k":1,"color":"red","size":10,"shape":"square"}]`, nil},
@@ -100,6 +104,9 @@ func TestSelectStmt(t *testing.T) {
 		{"Invalid use of MIN() aggregator", "SELECT * FROM test LIMIT min(0)", true, ``, nil},
 		{"Invalid use of COUNT() aggregator", "SELECT * FROM test OFFSET x(*)", true, ``, nil},
 		{"Invalid use of MAX() aggregator", "SELECT * FROM test LIMIT max(0)", true, ``, nil},
+		{"With bool_and", "SELECT BOOL_AND(size > 5), BOOL_AND(k > 1), BOOL_AND(shape) FROM test", false, `[{"BOOL_AND(size > 5)": true, "BOOL_AND(k > 1)": false, "BOOL_AND(shape)": null}]`, nil},
+		{"With bool_or", "SELECT BOOL_OR(color = 'red'), BOOL_OR(k > 3) FROM test", false, `[{"BOOL_OR(color = \"red\")": true, "BOOL_OR(k > 3)": false}]`, nil},
+		{"With any_value and group by", "SELECT size, ANY_VALUE(color) AS c, BOOL_OR(color = 'blue') AS b FROM test GROUP BY size", false, `[{"size": 10, "c": "red", "b": true},{"size": null, "c": null, "b": null}]`, nil},
 		{"Invalid use of SUM() aggregator", "SELECT * FROM test LIMIT sum(0)", true, ``, nil},
 		{"Invalid use of AVG() aggregator", "SELECT * FROM test LIMIT avg(0)", true, ``, nil},
 	}
@@ -211,6 +218,48 @@ func TestSelectStmt(t *testing.T) {
 		call("SELECT a[2][1] FROM test", `{"a[2][1]": null}`, `{"a[2][1]": null}`, `{"a[2][1]": 9}`)
 	})
 
+	t.Run("with references", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec(`
+			CREATE TABLE users (id INTEGER PRIMARY KEY);
+			CREATE TABLE posts;
+			INSERT INTO users (id, name, team) VALUES (1, 'foo', {name: 'a'}), (2, 'bar', {name: 'b'});
+			INSERT INTO posts (title, author) VALUES ('x', ref('users', 2)), ('y', ref('users', 3)), ('z', NULL);
+		`)
+		require.NoError(t, err)
+
+		call := func(q string, res ...string) {
+			st, err := db.Query(q)
+			require.NoError(t, err)
+			defer st.Close()
+
+			var i int
+			err = st.Iterate(func(d document.Document) error {
+				data, err := document.MarshalJSON(d)
+				require.NoError(t, err)
+				require.JSONEq(t, res[i], string(data))
+				i++
+				return nil
+			})
+			require.NoError(t, err)
+			require.Equal(t, len(res), i)
+		}
+
+		call("SELECT author, author->name, author -> team.name FROM posts",
+			`{"author": {"$ref": "users", "$id": 2}, "author->name": "bar", "author->team.name": "b"}`,
+			`{"author": {"$ref": "users", "$id": 3}, "author->name": null, "author->team.name": null}`,
+			`{"author": null, "author->name": null, "author->team.name": null}`,
+		)
+		call("SELECT deref(author) AS a FROM posts WHERE title = 'x'", `{"a": {"id": 2, "name": "bar", "team": {"name": "b"}}}`)
+		call("SELECT title FROM posts WHERE author = ref('users', 2)", `{"title": "x"}`)
+
+		_, err = db.QueryDocument("SELECT title->name FROM posts")
+		require.Error(t, err)
+	})
+
 	t.Run("table not found", func(t *testing.T) {
 		db, err := genji.Open(":memory:")
 		require.NoError(t, err)
@@ -415,3 +464,318 @@ func TestDistinct(t *testing.T) {
 		})
 	}
 }
+
+func TestSelectJoin(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE users(id INTEGER PRIMARY KEY, name TEXT);
+		CREATE TABLE orders(id INTEGER PRIMARY KEY, user_id DOUBLE, total INTEGER);
+		CREATE TABLE items(order_id INTEGER, product TEXT);
+		CREATE INDEX idx_orders_user_id ON orders(user_id);
+		INSERT INTO users (id, name) VALUES (1, 'foo'), (2, 'bar'), (3, 'baz');
+		INSERT INTO orders (id, user_id, total) VALUES (10, 1, 100), (11, 1, 50), (12, 2, 10), (13, 4, 20);
+		INSERT INTO items (order_id, product) VALUES (10, 'a'), (10, 'b'), (12, 'c');
+	`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+		params   []interface{}
+	}{
+		{"Wildcard", "SELECT * FROM users JOIN orders ON users.id = orders.user_id WHERE orders.total > 50",
+			`[{"users": {"id": 1, "name": "foo"}, "orders": {"id": 10, "user_id": 1.0, "total": 100}}]`, nil},
+		{"Fields", "SELECT users.name, orders.id FROM users INNER JOIN orders ON orders.user_id = users.id ORDER BY orders.id",
+			`[{"users.name": "foo", "orders.id": 10}, {"users.name": "foo", "orders.id": 11}, {"users.name": "bar", "orders.id": 12}]`, nil},
+		{"Group by", "SELECT users.name, SUM(orders.total) AS total FROM users JOIN orders ON users.id = orders.user_id GROUP BY users.name ORDER BY total",
+			`[{"users.name": "bar", "total": 10}, {"users.name": "foo", "total": 150}]`, nil},
+		{"Multiple joins", "SELECT users.name, items.product FROM users JOIN orders ON users.id = orders.user_id JOIN items ON items.order_id = orders.id ORDER BY items.product",
+			`[{"users.name": "foo", "items.product": "a"}, {"users.name": "foo", "items.product": "b"}, {"users.name": "bar", "items.product": "c"}]`, nil},
+		{"Non equality condition", "SELECT users.id AS u, orders.id AS o FROM users JOIN orders ON users.id = orders.user_id + 1 ORDER BY o",
+			`[{"u": 2, "o": 10}, {"u": 2, "o": 11}, {"u": 3, "o": 12}]`, nil},
+		{"Params", "SELECT orders.id FROM users JOIN orders ON users.id = orders.user_id AND users.name = ?",
+			`[{"orders.id": 12}]`, []interface{}{"bar"}},
+		{"No match", "SELECT * FROM users JOIN items ON users.id = items.order_id", `[]`, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			st, err := db.Query(test.query, test.params...)
+			require.NoError(t, err)
+			defer st.Close()
+
+			var buf bytes.Buffer
+			err = testutil.IteratorToJSONArray(&buf, st)
+			require.NoError(t, err)
+			require.JSONEq(t, test.expected, buf.String())
+		})
+	}
+
+	// with statistics, the smaller table is loaded in memory
+	err = db.Exec("ANALYZE")
+	require.NoError(t, err)
+
+	d, err := db.QueryDocument("EXPLAIN SELECT * FROM orders JOIN users ON users.id = orders.user_id")
+	require.NoError(t, err)
+	testutil.RequireDocJSONEq(t, d, `{"plan": "hashJoin(seqScan(orders), seqScan(users), users.id = orders.user_id)"}`)
+}
+
+func TestSelectSubquery(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE users(id INTEGER PRIMARY KEY, name TEXT);
+		CREATE TABLE orders(id INTEGER PRIMARY KEY, user_id DOUBLE, total INTEGER);
+		CREATE INDEX idx_orders_user_id ON orders(user_id);
+		INSERT INTO users (id, name) VALUES (1, 'foo'), (2, 'bar'), (3, 'baz');
+		INSERT INTO orders (id, user_id, total) VALUES (10, 1, 100), (11, 1, 50), (12, 2, 10);
+	`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+		params   []interface{}
+		fails    bool
+	}{
+		{"IN", "SELECT name FROM users WHERE id IN (SELECT user_id FROM orders WHERE total >= 50)",
+			`[{"name": "foo"}]`, nil, false},
+		{"NOT IN", "SELECT name FROM users WHERE id NOT IN (SELECT user_id FROM orders)",
+			`[{"name": "baz"}]`, nil, false},
+		{"IN with LIMIT", "SELECT name FROM users WHERE id IN (SELECT user_id FROM orders ORDER BY total LIMIT 1)",
+			`[{"name": "bar"}]`, nil, false},
+		{"IN with params", "SELECT name FROM users WHERE name != ? AND id IN (SELECT user_id FROM orders WHERE total < ?)",
+			`[{"name": "foo"}]`, []interface{}{"bar", 100}, false},
+		{"EXISTS", "SELECT name FROM users WHERE id > 2 AND EXISTS (SELECT * FROM orders WHERE user_id = 2)",
+			`[{"name": "baz"}]`, nil, false},
+		{"NOT EXISTS", "SELECT name FROM users WHERE NOT EXISTS (SELECT * FROM orders WHERE total > 100)",
+			`[{"name": "foo"}, {"name": "bar"}, {"name": "baz"}]`, nil, false},
+		{"Empty", "SELECT name FROM users WHERE id IN (SELECT user_id FROM orders WHERE total > 100)",
+			`[]`, nil, false},
+		{"Multiple fields", "SELECT name FROM users WHERE id IN (SELECT user_id, total FROM orders)",
+			``, nil, true},
+		{"Scalar", "SELECT id, (SELECT max(total) FROM orders) AS m FROM users WHERE id < 3",
+			`[{"id": 1, "m": 100}, {"id": 2, "m": 100}]`, nil, false},
+		{"Correlated", "SELECT id, (SELECT SUM(total) FROM orders WHERE user_id = users.id) AS s FROM users",
+			`[{"id": 1, "s": 150}, {"id": 2, "s": 10}, {"id": 3, "s": null}]`, nil, false},
+		{"Correlated in WHERE", "SELECT id FROM users WHERE (SELECT COUNT(*) FROM orders WHERE user_id = users.id) = 1",
+			`[{"id": 2}]`, nil, false},
+		{"Correlated EXISTS", "SELECT id FROM users WHERE NOT EXISTS (SELECT * FROM orders WHERE user_id = users.id)",
+			`[{"id": 3}]`, nil, false},
+		{"Scalar with multiple documents", "SELECT id, (SELECT total FROM orders WHERE user_id = users.id) AS t FROM users",
+			``, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			st, err := db.Query(test.query, test.params...)
+			require.NoError(t, err)
+			defer st.Close()
+
+			var buf bytes.Buffer
+			err = testutil.IteratorToJSONArray(&buf, st)
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, test.expected, buf.String())
+		})
+	}
+
+	err = db.Exec("UPDATE users SET name = 'qux' WHERE id IN (SELECT user_id FROM orders WHERE total = 10)")
+	require.NoError(t, err)
+	d, err := db.QueryDocument("SELECT name FROM users WHERE id = 2")
+	require.NoError(t, err)
+	testutil.RequireDocJSONEq(t, d, `{"name": "qux"}`)
+
+	err = db.Exec("UPDATE users SET total = (SELECT SUM(total) FROM orders WHERE user_id = users.id)")
+	require.NoError(t, err)
+	d, err = db.QueryDocument("SELECT total FROM users WHERE id = 1")
+	require.NoError(t, err)
+	testutil.RequireDocJSONEq(t, d, `{"total": 150}`)
+
+	// subqueries are optimized with the statement
+	d, err = db.QueryDocument("EXPLAIN SELECT name FROM users WHERE id IN (SELECT id FROM orders WHERE user_id = 1)")
+	require.NoError(t, err)
+	testutil.RequireDocJSONEq(t, d, `{"plan": "seqScan(users) | filter(id IN (indexScan(\"idx_orders_user_id\", 1) | project(id))) | project(name)"}`)
+}
+
+func TestSelectWith(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE emp(id INTEGER PRIMARY KEY, name TEXT, manager INTEGER);
+		INSERT INTO emp (id, name, manager) VALUES (1, 'a', null), (2, 'b', 1), (3, 'c', 1), (4, 'd', 2), (5, 'e', 4), (6, 'f', null);
+		CREATE TABLE edges(src INTEGER, dst INTEGER);
+		INSERT INTO edges (src, dst) VALUES (1, 2), (2, 3), (3, 1);
+	`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+		fails    bool
+	}{
+		{"Simple", "WITH top AS (SELECT id, name FROM emp WHERE manager IS NULL) SELECT name FROM top",
+			`[{"name": "a"}, {"name": "f"}]`, false},
+		{"Field names", "WITH top(i, n) AS (SELECT id, name FROM emp WHERE manager IS NULL) SELECT n FROM top WHERE i > 1",
+			`[{"n": "f"}]`, false},
+		{"Multiple", "WITH top AS (SELECT id FROM emp WHERE manager IS NULL), sub AS (SELECT emp.name FROM emp JOIN top ON emp.manager = top.id) SELECT * FROM sub",
+			`[{"emp.name": "b"}, {"emp.name": "c"}]`, false},
+		{"Wrong number of fields", "WITH top(i) AS (SELECT id, name FROM emp) SELECT * FROM top",
+			``, true},
+		{"Counter", "WITH RECURSIVE cnt(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM cnt WHERE n < 5) SELECT n FROM cnt",
+			`[{"n": 1}, {"n": 2}, {"n": 3}, {"n": 4}, {"n": 5}]`, false},
+		{"Hierarchy", `WITH RECURSIVE sub(id, name, depth) AS (
+				SELECT id, name, 0 FROM emp WHERE id = 2
+				UNION ALL
+				SELECT emp.id, emp.name, sub.depth + 1 FROM emp JOIN sub ON emp.manager = sub.id
+			) SELECT name, depth FROM sub`,
+			`[{"name": "b", "depth": 0}, {"name": "d", "depth": 1}, {"name": "e", "depth": 2}]`, false},
+		{"Cycle with UNION", `WITH RECURSIVE reach(id) AS (
+				SELECT 1
+				UNION
+				SELECT edges.dst FROM edges JOIN reach ON edges.src = reach.id
+			) SELECT id FROM reach`,
+			`[{"id": 1}, {"id": 2}, {"id": 3}]`, false},
+		{"Cycle with UNION ALL", `WITH RECURSIVE reach(id) AS (
+				SELECT 1
+				UNION ALL
+				SELECT edges.dst FROM edges JOIN reach ON edges.src = reach.id
+			) SELECT id FROM reach`,
+			``, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			st, err := db.Query(test.query)
+			require.NoError(t, err)
+			defer st.Close()
+
+			var buf bytes.Buffer
+			err = testutil.IteratorToJSONArray(&buf, st)
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, test.expected, buf.String())
+		})
+	}
+
+	d, err := db.QueryDocument("EXPLAIN WITH RECURSIVE cnt(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM cnt WHERE n < 5) SELECT n FROM cnt")
+	require.NoError(t, err)
+	testutil.RequireDocJSONEq(t, d, `{"plan": "cteScan(cnt(n) AS (exprs({1: 1}) UNION ALL workingTableScan(cnt) | filter(n < 5) | project(n + 1))) | project(n)"}`)
+}
+
+func TestSelectTablesStats(t *testing.T) {
+	db, tx, cleanup := testutil.NewTestTx(t)
+	defer cleanup()
+
+	testutil.MustExec(t, db, tx, `
+		CREATE TABLE foo;
+		CREATE INDEX foo_a ON foo(a);
+		INSERT INTO foo (a) VALUES (1), (2), (3);
+		CREATE TABLE bar(a TEXT PRIMARY KEY);
+	`)
+
+	res := testutil.MustQuery(t, db, tx, "SELECT * FROM __genji_tables_stats")
+	defer res.Close()
+
+	var docs []document.Document
+	err := res.Iterate(func(d document.Document) error {
+		fb := document.NewFieldBuffer()
+		err := fb.Copy(d)
+		docs = append(docs, fb)
+		return err
+	})
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+
+	get := func(d document.Document, field string) document.Value {
+		v, err := d.GetByField(field)
+		require.NoError(t, err)
+		return v
+	}
+
+	// tables are sorted by name
+	require.Equal(t, document.NewTextValue("bar"), get(docs[0], "table_name"))
+	require.Equal(t, document.NewIntegerValue(0), get(docs[0], "documents"))
+	require.Equal(t, document.NewIntegerValue(0), get(docs[0], "size"))
+	require.Equal(t, document.NewIntegerValue(0), get(docs[0], "index_size"))
+	require.Equal(t, document.NewNullValue(), get(docs[0], "avg_document_size"))
+
+	require.Equal(t, document.NewTextValue("foo"), get(docs[1], "table_name"))
+	require.Equal(t, document.NewIntegerValue(3), get(docs[1], "documents"))
+	size := get(docs[1], "size").V.(int64)
+	require.Greater(t, size, int64(0))
+	require.Greater(t, get(docs[1], "index_size").V.(int64), int64(0))
+	require.Equal(t, document.NewDoubleValue(float64(size)/3), get(docs[1], "avg_document_size"))
+}
+
+// The documents selected using an index or a primary key must be the same as
+// the ones selected by comparing every document of the table.
+func TestSelectIndexMatchesSeqScan(t *testing.T) {
+	db, tx, cleanup := testutil.NewTestTx(t)
+	defer cleanup()
+
+	for _, typ := range []string{"INTEGER", "DOUBLE"} {
+		testutil.MustExec(t, db, tx, `
+			CREATE TABLE seq_`+typ+`(a `+typ+`);
+			CREATE TABLE idx_`+typ+`(a `+typ+`);
+			CREATE INDEX on idx_`+typ+`(a);
+			CREATE TABLE pk_`+typ+`(a `+typ+` PRIMARY KEY);
+		`)
+
+		for i := -3; i <= 3; i++ {
+			for _, tb := range []string{"seq_", "idx_", "pk_"} {
+				testutil.MustExec(t, db, tx, "INSERT INTO "+tb+typ+" (a) VALUES (?)", environment.Param{Value: i})
+			}
+		}
+	}
+
+	selectAll := func(t *testing.T, q string) string {
+		t.Helper()
+
+		res := testutil.MustQuery(t, db, tx, q)
+		defer res.Close()
+
+		var buf bytes.Buffer
+		err := testutil.IteratorToJSONArray(&buf, res)
+		require.NoError(t, err)
+		return buf.String()
+	}
+
+	var conditions []string
+	for _, op := range []string{"=", "!=", ">", ">=", "<", "<="} {
+		for _, v := range []string{"1", "1.0", "1.5", "-1.5", "'1'", "true", "NULL"} {
+			conditions = append(conditions, "a "+op+" "+v)
+		}
+	}
+	conditions = append(conditions,
+		"a IN [1, 1.5, 2.0, '3']",
+		"a BETWEEN -1.5 AND 1.5",
+		"a BETWEEN 1 AND 2.0",
+	)
+
+	for _, typ := range []string{"INTEGER", "DOUBLE"} {
+		for _, cond := range conditions {
+			t.Run(typ+"/"+cond, func(t *testing.T) {
+				expected := selectAll(t, "SELECT a FROM seq_"+typ+" WHERE "+cond+" ORDER BY a")
+				require.Equal(t, expected, selectAll(t, "SELECT a FROM idx_"+typ+" WHERE "+cond+" ORDER BY a"))
+				require.Equal(t, expected, selectAll(t, "SELECT a FROM pk_"+typ+" WHERE "+cond+" ORDER BY a"))
+			})
+		}
+	}
+}
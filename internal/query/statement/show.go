@@ -0,0 +1,108 @@
+package statement
+
+import (
+	"sort"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/stream"
+)
+
+// ShowSequencesStmt is a statement that returns the state of the sequences:
+// their current value, their lease and the number of values reserved by the lease
+// that would be lost if the database were not closed properly.
+type ShowSequencesStmt struct {
+	// If set, only this sequence is returned.
+	Name string
+}
+
+// IsReadOnly always returns true. It implements the Statement interface.
+func (stmt ShowSequencesStmt) IsReadOnly() bool {
+	return true
+}
+
+// Run returns a document per sequence. It implements the Statement interface.
+func (stmt ShowSequencesStmt) Run(ctx *Context) (Result, error) {
+	names := []string{stmt.Name}
+	if stmt.Name == "" {
+		names = ctx.Catalog.ListSequences()
+		sort.Strings(names)
+	}
+
+	docs := make([]document.Document, 0, len(names))
+	for _, name := range names {
+		seq, err := ctx.Catalog.GetSequence(name)
+		if err != nil {
+			return Result{}, err
+		}
+
+		lease, err := seq.Lease(ctx.Tx, ctx.Catalog)
+		if err != nil {
+			return Result{}, err
+		}
+
+		fb := document.NewFieldBuffer().
+			Add("name", document.NewTextValue(seq.Info.Name))
+		if seq.Info.Owner.TableName != "" {
+			fb.Add("owner", document.NewTextValue(seq.Info.Owner.TableName))
+		} else {
+			fb.Add("owner", document.NewNullValue())
+		}
+
+		current, leased, reserved := document.NewNullValue(), document.NewNullValue(), document.NewIntegerValue(0)
+		if seq.CurrentValue != nil {
+			current = document.NewIntegerValue(*seq.CurrentValue)
+		}
+		if lease != nil {
+			leased = document.NewIntegerValue(*lease)
+			if seq.CurrentValue != nil {
+				n := (*lease - *seq.CurrentValue) / seq.Info.IncrementBy
+				if n > 0 {
+					reserved = document.NewIntegerValue(n)
+				}
+			}
+		}
+
+		fb.Add("current_value", current).
+			Add("lease", leased).
+			Add("cache", document.NewIntegerValue(int64(seq.Info.Cache))).
+			Add("reserved", reserved)
+		docs = append(docs, fb)
+	}
+
+	st := StreamStmt{
+		PreparedStream: stream.New(stream.Documents(docs...)),
+		ReadOnly:       true,
+	}
+	return st.Run(ctx)
+}
+
+// ShowTransactionStatusStmt is a statement that returns the state of the current transaction:
+// whether it is writable, the isolation levels of the database and of the engine,
+// when it started and the number of documents it wrote that are not committed yet.
+// Outside of an explicit transaction, it describes the transaction run by the statement itself.
+type ShowTransactionStatusStmt struct{}
+
+// IsReadOnly always returns true. It implements the Statement interface.
+func (stmt ShowTransactionStatusStmt) IsReadOnly() bool {
+	return true
+}
+
+// Run returns a document describing the transaction. It implements the Statement interface.
+func (stmt ShowTransactionStatusStmt) Run(ctx *Context) (Result, error) {
+	status := ctx.Tx.Status()
+
+	fb := document.NewFieldBuffer().
+		Add("writable", document.NewBoolValue(status.Writable)).
+		Add("explicit", document.NewBoolValue(status.Attached)).
+		Add("isolation", document.NewTextValue(status.Isolation)).
+		Add("engine_isolation", document.NewTextValue(status.EngineIsolation)).
+		Add("started_at", document.NewTextValue(status.StartedAt.UTC().Format(database.AuditTimeFormat))).
+		Add("pending_writes", document.NewIntegerValue(status.PendingWrites))
+
+	st := StreamStmt{
+		PreparedStream: stream.New(stream.Documents(fb)),
+		ReadOnly:       true,
+	}
+	return st.Run(ctx)
+}
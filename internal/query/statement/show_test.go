@@ -0,0 +1,111 @@
+package statement_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShowSequences(t *testing.T) {
+	db, tx, cleanup := testutil.NewTestTx(t)
+	defer cleanup()
+
+	testutil.MustExec(t, db, tx, `
+		CREATE TABLE test1;
+		CREATE TABLE test2 WITH (docid_cache = 1);
+		CREATE SEQUENCE seq CACHE 10;
+		CREATE SEQUENCE unused;
+		CREATE SEQUENCE down INCREMENT BY -2 CACHE 5;
+		INSERT INTO test1 (a) VALUES (1), (2);
+		INSERT INTO test2 (a) VALUES (1), (2);
+		SELECT NEXT VALUE FOR seq, NEXT VALUE FOR down;
+	`)
+
+	tests := []struct {
+		query    string
+		expected string
+		fails    bool
+	}{
+		{"SHOW SEQUENCE seq", `[{"name": "seq", "owner": null, "current_value": 1, "lease": 10, "cache": 10, "reserved": 9}]`, false},
+		{"SHOW SEQUENCE down", `[{"name": "down", "owner": null, "current_value": -1, "lease": -5, "cache": 5, "reserved": 2}]`, false},
+		{"SHOW SEQUENCE unused", `[{"name": "unused", "owner": null, "current_value": null, "lease": null, "cache": 1, "reserved": 0}]`, false},
+		{"SHOW SEQUENCE test1_seq", `[{"name": "test1_seq", "owner": "test1", "current_value": 2, "lease": 64, "cache": 64, "reserved": 62}]`, false},
+		{"SHOW SEQUENCE test2_seq", `[{"name": "test2_seq", "owner": "test2", "current_value": 2, "lease": 2, "cache": 1, "reserved": 0}]`, false},
+		{"SHOW SEQUENCE doesntexist", ``, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			res, err := testutil.Query(db, tx, test.query)
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer res.Close()
+
+			var buf bytes.Buffer
+			err = testutil.IteratorToJSONArray(&buf, res)
+			require.NoError(t, err)
+			require.JSONEq(t, test.expected, buf.String())
+		})
+	}
+
+	t.Run("SHOW SEQUENCES", func(t *testing.T) {
+		res := testutil.MustQuery(t, db, tx, "SHOW SEQUENCES")
+		defer res.Close()
+
+		var names []string
+		err := res.Iterate(func(d document.Document) error {
+			v, err := d.GetByField("name")
+			if err != nil {
+				return err
+			}
+			names = append(names, v.V.(string))
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"__genji_store_seq", "down", "seq", "test1_seq", "test2_seq", "unused"}, names)
+	})
+}
+
+func TestShowTransactionStatus(t *testing.T) {
+	db, tx, cleanup := testutil.NewTestTx(t)
+	defer cleanup()
+
+	testutil.MustExec(t, db, tx, `
+		CREATE TABLE test(a INTEGER PRIMARY KEY);
+		INSERT INTO test (a) VALUES (1), (2), (3);
+		UPDATE test SET a = 10 WHERE a = 1;
+		DELETE FROM test WHERE a = 2;
+	`)
+
+	res := testutil.MustQuery(t, db, tx, "SHOW TRANSACTION STATUS")
+	defer res.Close()
+
+	var buf bytes.Buffer
+	err := testutil.IteratorToJSONArray(&buf, res)
+	require.NoError(t, err)
+
+	var docs []map[string]interface{}
+	err = json.Unmarshal(buf.Bytes(), &docs)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+
+	startedAt := docs[0]["started_at"]
+	require.Equal(t, tx.StartedAt.UTC().Format(database.AuditTimeFormat), startedAt)
+	delete(docs[0], "started_at")
+	require.Equal(t, map[string]interface{}{
+		"writable":         true,
+		"explicit":         false,
+		"isolation":        "serializable",
+		"engine_isolation": "none",
+		// 3 inserts, 1 replace and 1 delete
+		"pending_writes": float64(5),
+	}, docs[0])
+}
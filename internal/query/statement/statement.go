@@ -18,6 +18,9 @@ type Context struct {
 	Tx      *database.Transaction
 	Catalog database.Catalog
 	Params  []environment.Param
+	// Creates the tracker of the resources consumed by the statement.
+	// If nil, resources are not tracked.
+	NewTracker func() *database.ResourceTracker
 }
 
 type Preparer interface {
@@ -27,9 +30,18 @@ type Preparer interface {
 // Result of a query.
 type Result struct {
 	Iterator document.Iterator
-	Tx       *database.Transaction
-	closed   bool
-	err      error
+	// Transaction owned by the result, committed or rolled back by Close.
+	Tx *database.Transaction
+	// Transaction used by the statement, whether the result owns it or not.
+	// The iterations mark it as in use.
+	RunTx *database.Transaction
+	// If true, a savepoint was created on RunTx before running the statement.
+	// It is rolled back if the iteration fails, and released otherwise.
+	Savepoint bool
+	// Process running the statement, ended by Close.
+	Process *database.Process
+	closed  bool
+	err     error
 }
 
 func (r *Result) Iterate(fn func(d document.Document) error) error {
@@ -37,7 +49,29 @@ func (r *Result) Iterate(fn func(d document.Document) error) error {
 		return nil
 	}
 
+	if r.RunTx != nil {
+		err := r.RunTx.Enter("")
+		if err != nil {
+			return err
+		}
+		defer r.RunTx.Leave()
+	}
+
 	r.err = r.Iterator.Iterate(fn)
+
+	if r.Savepoint {
+		r.Savepoint = false
+
+		if r.err != nil {
+			err := r.RunTx.RollbackToSavepoint()
+			if err != nil {
+				return err
+			}
+		} else {
+			r.RunTx.ReleaseSavepoint()
+		}
+	}
+
 	return r.err
 }
 
@@ -54,6 +88,12 @@ func (r *Result) Close() (err error) {
 	}
 
 	r.closed = true
+	defer r.Process.End()
+
+	if r.Savepoint {
+		r.Savepoint = false
+		r.RunTx.ReleaseSavepoint()
+	}
 
 	if r.Tx != nil {
 		if r.Tx.Writable && r.err == nil {
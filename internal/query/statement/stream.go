@@ -1,9 +1,13 @@
 package statement
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/internal/environment"
 	"github.com/genjidb/genji/internal/planner"
+	"github.com/genjidb/genji/internal/sql/scanner"
 	"github.com/genjidb/genji/internal/stream"
 )
 
@@ -16,10 +20,57 @@ type StreamStmt struct {
 }
 
 // Prepare optimizes the stream and stores it in s.
+// The chosen plan is recorded in the plan history of the transaction, if any.
 func (s *StreamStmt) Prepare(ctx *Context) error {
+	// the statement is identified by its stream before optimization,
+	// which may modify it.
+	stmt := s.Stream.String()
+
 	var err error
 	s.PreparedStream, err = planner.Optimize(s.Stream, ctx.Catalog)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if ctx.Tx != nil && ctx.Tx.Plans != nil {
+		if n, err := scanner.Normalize(stmt); err == nil {
+			stmt = n
+		}
+		ctx.Tx.Plans.Record(stmt, normalizePlan(s.PreparedStream.String()), ctx.Tx.Now())
+	}
+
+	return nil
+}
+
+// normalizePlan replaces the literal values of the given plan by ?,
+// so that statements only differing by their values share the same plans.
+// Unlike scanner.Normalize, the quoted values directly following a parenthesis
+// are kept, as operators use them to name the indexes they read.
+func normalizePlan(plan string) string {
+	var sb strings.Builder
+
+	s := scanner.NewScanner(strings.NewReader(plan))
+	prev := scanner.ILLEGAL
+	for {
+		tok, _, lit := s.Scan()
+		switch tok {
+		case scanner.EOF:
+			return sb.String()
+		case scanner.NUMBER, scanner.INTEGER, scanner.BLOB, scanner.REGEX:
+			sb.WriteByte('?')
+		case scanner.STRING:
+			if prev == scanner.LPAREN {
+				sb.WriteString(strconv.Quote(lit))
+			} else {
+				sb.WriteByte('?')
+			}
+		case scanner.WS:
+			sb.WriteByte(' ')
+		default:
+			sb.WriteString(scanner.Tokstr(tok, lit))
+		}
+		prev = tok
+	}
 }
 
 // Run returns a result containing the stream. The stream will be executed by calling the Iterate method of
@@ -59,6 +110,9 @@ func (s *StreamStmtIterator) Iterate(fn func(d document.Document) error) error {
 	var env environment.Environment
 	env.Tx = s.Context.Tx
 	env.Catalog = s.Context.Catalog
+	if s.Context.NewTracker != nil {
+		env.Tracker = s.Context.NewTracker()
+	}
 	env.SetParams(s.Context.Params)
 
 	err := s.Stream.Iterate(&env, func(env *environment.Environment) error {
@@ -74,5 +128,9 @@ func (s *StreamStmtIterator) Iterate(fn func(d document.Document) error) error {
 	if err == stream.ErrStreamClosed {
 		err = nil
 	}
+
+	if cerr := env.Tracker.Close(); err == nil {
+		err = cerr
+	}
 	return err
 }
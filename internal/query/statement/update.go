@@ -20,6 +20,9 @@ type UpdateStmt struct {
 	UnsetFields []string
 
 	WhereExpr expr.Expr
+
+	// Returning holds the expressions projected on the updated documents, if any.
+	Returning []expr.Expr
 }
 
 type UpdateSetPair struct {
@@ -31,6 +34,11 @@ type UpdateSetPair struct {
 func (stmt *UpdateStmt) ToStream() *StreamStmt {
 	s := stream.New(stream.SeqScan(stmt.TableName))
 
+	bindSubqueries(stmt.TableName, stmt.WhereExpr)
+	for _, pair := range stmt.SetPairs {
+		bindSubqueries(stmt.TableName, pair.E)
+	}
+
 	if stmt.WhereExpr != nil {
 		s = s.Pipe(stream.Filter(stmt.WhereExpr))
 	}
@@ -47,6 +55,11 @@ func (stmt *UpdateStmt) ToStream() *StreamStmt {
 
 	s = s.Pipe(stream.TableReplace(stmt.TableName))
 
+	if len(stmt.Returning) > 0 {
+		bindSubqueries(stmt.TableName, stmt.Returning...)
+		s = s.Pipe(stream.Project(stmt.Returning...))
+	}
+
 	return &StreamStmt{
 		Stream:   s,
 		ReadOnly: false,
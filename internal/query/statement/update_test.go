@@ -136,4 +136,104 @@ func TestUpdateStmt(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("with nested paths", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			query    string
+			expected string
+		}{
+			{"arithmetic", `UPDATE foo SET a.b[2].c = a.b[2].c + 1`, `[{"a": {"b": [1, 2, {"c": 11}]}}, {"a": {"b": [3, 4, {"c": 21}]}}]`},
+			{"arithmetic on array index", `UPDATE foo SET a.b[0] = a.b[0] * a.b[1]`, `[{"a": {"b": [2, 2, {"c": 10}]}}, {"a": {"b": [12, 4, {"c": 20}]}}]`},
+			{"multiple paths", `UPDATE foo SET a.b[0] = a.b[1], a.b[1] = a.b[0] + 1`, `[{"a": {"b": [2, 3, {"c": 10}]}}, {"a": {"b": [4, 5, {"c": 20}]}}]`},
+			{"new field in nested document", `UPDATE foo SET a.b[2].d = a.b[2].c - 1`, `[{"a": {"b": [1, 2, {"c": 10, "d": 9}]}}, {"a": {"b": [3, 4, {"c": 20, "d": 19}]}}]`},
+			{"with cond", `UPDATE foo SET a.b[2].c = a.b[2].c * 2 WHERE a.b[0] = 3`, `[{"a": {"b": [1, 2, {"c": 10}]}}, {"a": {"b": [3, 4, {"c": 40}]}}]`},
+			{"unknown intermediate field", `UPDATE foo SET a.x.y = 1`, `[{"a": {"b": [1, 2, {"c": 10}]}}, {"a": {"b": [3, 4, {"c": 20}]}}]`},
+			{"unknown root field", `UPDATE foo SET x[0] = 1`, `[{"a": {"b": [1, 2, {"c": 10}]}}, {"a": {"b": [3, 4, {"c": 20}]}}]`},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				db, err := genji.Open(":memory:")
+				require.NoError(t, err)
+				defer db.Close()
+
+				err = db.Exec(`
+					CREATE TABLE foo;
+					INSERT INTO foo (a) VALUES ({b: [1, 2, {c: 10}]}), ({b: [3, 4, {c: 20}]});
+				`)
+				require.NoError(t, err)
+
+				err = db.Exec(tt.query)
+				require.NoError(t, err)
+
+				st, err := db.Query("SELECT * FROM foo")
+				require.NoError(t, err)
+				defer st.Close()
+
+				var buf bytes.Buffer
+
+				err = testutil.IteratorToJSONArray(&buf, st)
+				require.NoError(t, err)
+				require.JSONEq(t, tt.expected, buf.String())
+			})
+		}
+	})
+
+	t.Run("with unique indexes", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec(`CREATE TABLE foo(a INTEGER UNIQUE); INSERT INTO foo (a) VALUES (1), (2), (3);`)
+		require.NoError(t, err)
+
+		// indexes are only updated once every document has been modified,
+		// so swapping unique values is allowed.
+		err = db.Exec(`UPDATE foo SET a = 3 - a WHERE a < 3`)
+		require.NoError(t, err)
+
+		err = db.Exec(`UPDATE foo SET a = 1`)
+		require.Error(t, err)
+
+		st, err := db.Query("SELECT a FROM foo WHERE a > 0")
+		require.NoError(t, err)
+		defer st.Close()
+
+		var buf bytes.Buffer
+		err = testutil.IteratorToJSONArray(&buf, st)
+		require.NoError(t, err)
+		require.JSONEq(t, `[{"a": 1}, {"a": 2}, {"a": 3}]`, buf.String())
+	})
+
+	t.Run("RETURNING", func(t *testing.T) {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		err = db.Exec(`
+			CREATE TABLE foo (a INTEGER, b TEXT DEFAULT 'x');
+			INSERT INTO foo (a, b) VALUES (1, 'y'), (2, 'y');
+		`)
+		require.NoError(t, err)
+
+		// the returned documents are the stored ones, with their default values
+		st, err := db.Query(`UPDATE foo UNSET b WHERE a = 1 RETURNING *, pk()`)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		err = testutil.IteratorToJSONArray(&buf, st)
+		require.NoError(t, err)
+		require.NoError(t, st.Close())
+		require.JSONEq(t, `[{"a": 1, "b": "x", "pk()": 1}]`, buf.String())
+
+		st, err = db.Query(`UPDATE foo SET a = a * 10 RETURNING a`)
+		require.NoError(t, err)
+
+		buf.Reset()
+		err = testutil.IteratorToJSONArray(&buf, st)
+		require.NoError(t, err)
+		require.NoError(t, st.Close())
+		require.JSONEq(t, `[{"a": 10}, {"a": 20}]`, buf.String())
+	})
 }
@@ -0,0 +1,131 @@
+package query_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/document/encoding/msgpack"
+	"github.com/genjidb/genji/engine/memoryengine"
+	"github.com/genjidb/genji/internal/catalog"
+	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/query"
+	"github.com/genjidb/genji/internal/sql/parser"
+	"github.com/genjidb/genji/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThrottle(t *testing.T) {
+	newDB := func(t *testing.T, maxStatements, maxWrites int) *database.Database {
+		db, err := database.New(context.Background(), memoryengine.NewEngine(), database.Options{
+			Codec:                   msgpack.NewCodec(),
+			Catalog:                 catalog.New(),
+			MaxConcurrentStatements: maxStatements,
+			MaxWritesPerSecond:      maxWrites,
+		})
+		require.NoError(t, err)
+
+		res := testutil.MustQuery(t, db, nil, "CREATE TABLE test")
+		require.NoError(t, res.Close())
+		return db
+	}
+
+	queryWithContext := func(ctx context.Context, db *database.Database, tx *database.Transaction, q string) error {
+		pq, err := parser.ParseQuery(q)
+		if err != nil {
+			return err
+		}
+
+		qctx := &query.Context{Ctx: ctx, DB: db, Tx: tx}
+		err = pq.Prepare(qctx)
+		if err != nil {
+			return err
+		}
+
+		res, err := pq.Run(qctx)
+		if err != nil {
+			return err
+		}
+		return res.Close()
+	}
+
+	t.Run("Concurrent statements", func(t *testing.T) {
+		db := newDB(t, 1, 0)
+		defer db.Close()
+
+		res := testutil.MustQuery(t, db, nil, "SELECT * FROM test")
+
+		// no slot left
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := queryWithContext(ctx, db, nil, "SELECT * FROM test")
+		require.Equal(t, context.DeadlineExceeded, err)
+
+		stats := db.Throttle().Stats()
+		require.Equal(t, int64(1), stats.RunningStatements)
+		require.Equal(t, int64(0), stats.WaitingStatements)
+		require.Equal(t, int64(1), stats.ThrottledStatements)
+
+		// statements run in explicit transactions are not limited
+		tx, err := db.Begin(false)
+		require.NoError(t, err)
+		err = queryWithContext(context.Background(), db, tx, "SELECT * FROM test")
+		require.NoError(t, err)
+		require.NoError(t, tx.Rollback())
+
+		// the slot is released once the result is closed
+		require.NoError(t, res.Close())
+		err = queryWithContext(context.Background(), db, nil, "SELECT * FROM test")
+		require.NoError(t, err)
+		require.Equal(t, int64(0), db.Throttle().Stats().RunningStatements)
+	})
+
+	t.Run("Writes per second", func(t *testing.T) {
+		db := newDB(t, 0, 20)
+		defer db.Close()
+
+		// with the creation of the table, 22 writes are run
+		start := time.Now()
+		for i := 0; i < 21; i++ {
+			err := queryWithContext(context.Background(), db, nil, "INSERT INTO test (a) VALUES (1)")
+			require.NoError(t, err)
+		}
+		require.GreaterOrEqual(t, int64(time.Since(start)), int64(80*time.Millisecond))
+
+		// read-only statements are not delayed
+		err := queryWithContext(context.Background(), db, nil, "SELECT * FROM test")
+		require.NoError(t, err)
+
+		stats := db.Throttle().Stats()
+		require.Equal(t, int64(2), stats.ThrottledWrites)
+		require.Equal(t, int64(0), stats.WaitingWrites)
+	})
+
+	t.Run("Stats table", func(t *testing.T) {
+		db := newDB(t, 5, 100)
+		defer db.Close()
+
+		res := testutil.MustQuery(t, db, nil, "SELECT * FROM __genji_throttle_stats")
+		defer res.Close()
+
+		var doc document.Document
+		err := res.Iterate(func(d document.Document) error {
+			fb := document.NewFieldBuffer()
+			err := fb.Copy(d)
+			doc = fb
+			return err
+		})
+		require.NoError(t, err)
+
+		testutil.RequireDocJSONEq(t, doc, `{
+			"max_concurrent_statements": 5,
+			"max_writes_per_second": 100,
+			"running_statements": 1,
+			"waiting_statements": 0,
+			"waiting_writes": 0,
+			"throttled_statements": 0,
+			"throttled_writes": 0
+		}`)
+	})
+}
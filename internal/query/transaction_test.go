@@ -1,9 +1,18 @@
 package query_test
 
 import (
+	"context"
+	"fmt"
 	"testing"
 
 	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/document/encoding/msgpack"
+	"github.com/genjidb/genji/engine/memoryengine"
+	errs "github.com/genjidb/genji/errors"
+	"github.com/genjidb/genji/internal/catalog"
+	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/testutil"
 	"github.com/stretchr/testify/require"
 )
 
@@ -46,3 +55,61 @@ func TestTransactionRun(t *testing.T) {
 		})
 	}
 }
+
+func TestAtomicStatements(t *testing.T) {
+	tests := []struct {
+		atomic   bool
+		expected []int64
+	}{
+		{false, []int64{1, 2, 3}},
+		{true, []int64{1}},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("atomic=%v", test.atomic), func(t *testing.T) {
+			db, err := database.New(context.Background(), memoryengine.NewEngine(), database.Options{
+				Codec:            msgpack.NewCodec(),
+				Catalog:          catalog.New(),
+				AtomicStatements: test.atomic,
+			})
+			require.NoError(t, err)
+			defer db.Close()
+
+			res := testutil.MustQuery(t, db, nil, "CREATE TABLE test(a INTEGER UNIQUE)")
+			require.NoError(t, res.Close())
+
+			tx, err := db.Begin(true)
+			require.NoError(t, err)
+			defer tx.Rollback()
+
+			testutil.MustExec(t, db, tx, "INSERT INTO test (a) VALUES (1)")
+
+			err = testutil.Exec(db, tx, "INSERT INTO test (a) VALUES (2), (3), (1), (4)")
+			require.ErrorIs(t, err, errs.ErrDuplicateDocument)
+
+			// the transaction can still be used and committed
+			require.NoError(t, tx.Commit())
+
+			values := func(q string) []int64 {
+				res := testutil.MustQuery(t, db, nil, q)
+				defer res.Close()
+
+				var values []int64
+				err := res.Iterate(func(d document.Document) error {
+					v, err := d.GetByField("a")
+					if err != nil {
+						return err
+					}
+					values = append(values, v.V.(int64))
+					return nil
+				})
+				require.NoError(t, err)
+				return values
+			}
+
+			require.Equal(t, test.expected, values("SELECT a FROM test"))
+			// the index must be consistent with the table
+			require.Equal(t, test.expected, values("SELECT a FROM test WHERE a > 0"))
+		})
+	}
+}
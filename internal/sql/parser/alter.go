@@ -1,6 +1,9 @@
 package parser
 
 import (
+	"strings"
+
+	"github.com/genjidb/genji/internal/expr"
 	"github.com/genjidb/genji/internal/query/statement"
 	"github.com/genjidb/genji/internal/sql/scanner"
 )
@@ -42,17 +45,136 @@ func (p *Parser) parseAlterTableAddFieldStatement(tableName string) (_ statement
 		return stmt, &ParseError{Message: "cannot add a PRIMARY KEY constraint"}
 	}
 
+	if stmt.Constraint.Identity != nil {
+		return stmt, &ParseError{Message: "cannot add an AUTOINCREMENT field"}
+	}
+
+	return stmt, nil
+}
+
+func (p *Parser) parseAlterTableSetStatement(tableName string) (statement.Statement, error) {
+	// MASK is not a keyword, to allow using it as an identifier.
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	switch {
+	case tok == scanner.READ:
+		return p.parseAlterTableSetReadOnlyStatement(tableName)
+	case tok == scanner.IDENT && strings.EqualFold(lit, "MASK"):
+		return p.parseAlterTableSetMaskStatement(tableName)
+	}
+
+	return nil, newParseError(scanner.Tokstr(tok, lit), []string{"READ", "MASK"}, pos)
+}
+
+// parseAlterTableSetReadOnlyStatement parses the end of an ALTER TABLE SET READ ONLY
+// or SET READ WRITE statement. It assumes the READ token has already been consumed.
+func (p *Parser) parseAlterTableSetReadOnlyStatement(tableName string) (_ statement.AlterTableSetReadOnly, err error) {
+	var stmt statement.AlterTableSetReadOnly
+	stmt.TableName = tableName
+
+	// Parse "ONLY" or "WRITE".
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	switch tok {
+	case scanner.ONLY:
+		stmt.ReadOnly = true
+	case scanner.WRITE:
+	default:
+		return stmt, newParseError(scanner.Tokstr(tok, lit), []string{"ONLY", "WRITE"}, pos)
+	}
+
+	return stmt, nil
+}
+
+// parseAlterTableSetMaskStatement parses the end of an ALTER TABLE SET MASK ON path USING expr
+// statement. It assumes the MASK token has already been consumed.
+func (p *Parser) parseAlterTableSetMaskStatement(tableName string) (_ statement.AlterTableSetMask, err error) {
+	var stmt statement.AlterTableSetMask
+	stmt.TableName = tableName
+
+	// Parse "ON".
+	if err := p.parseTokens(scanner.ON); err != nil {
+		return stmt, err
+	}
+
+	stmt.Mask.Path, err = p.parsePath()
+	if err != nil {
+		return stmt, err
+	}
+
+	// Parse "USING".
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	if tok != scanner.IDENT || !strings.EqualFold(lit, "USING") {
+		return stmt, newParseError(scanner.Tokstr(tok, lit), []string{"USING"}, pos)
+	}
+
+	e, err := p.ParseExpr()
+	if err != nil {
+		return stmt, err
+	}
+	stmt.Mask.Expr = expr.Mask(e)
+
 	return stmt, nil
 }
 
+// parseAlterTableDropMaskStatement parses ALTER TABLE DROP MASK ON path.
+// It assumes the DROP token has already been consumed.
+func (p *Parser) parseAlterTableDropMaskStatement(tableName string) (_ statement.AlterTableDropMask, err error) {
+	var stmt statement.AlterTableDropMask
+	stmt.TableName = tableName
+
+	// Parse "MASK".
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	if tok != scanner.IDENT || !strings.EqualFold(lit, "MASK") {
+		return stmt, newParseError(scanner.Tokstr(tok, lit), []string{"MASK"}, pos)
+	}
+
+	// Parse "ON".
+	if err := p.parseTokens(scanner.ON); err != nil {
+		return stmt, err
+	}
+
+	stmt.Path, err = p.parsePath()
+	return stmt, err
+}
+
+// parseAlterSequenceStatement parses ALTER SEQUENCE name RESTART [WITH] integer.
+// It assumes the SEQUENCE token has already been consumed.
+func (p *Parser) parseAlterSequenceStatement() (_ statement.AlterSequenceRestart, err error) {
+	var stmt statement.AlterSequenceRestart
+
+	// Parse sequence name.
+	stmt.SequenceName, err = p.parseIdent()
+	if err != nil {
+		pErr := err.(*ParseError)
+		pErr.Expected = []string{"sequence_name"}
+		return stmt, pErr
+	}
+
+	// Parse "RESTART".
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	if tok != scanner.IDENT || !strings.EqualFold(lit, "RESTART") {
+		return stmt, newParseError(scanner.Tokstr(tok, lit), []string{"RESTART"}, pos)
+	}
+
+	// parse optional WITH token
+	_, _ = p.parseOptional(scanner.WITH)
+
+	stmt.Value, err = p.parseInteger()
+	return stmt, err
+}
+
 // parseAlterStatement parses a Alter query string and returns a Statement AST object.
 // This function assumes the ALTER token has already been consumed.
 func (p *Parser) parseAlterStatement() (statement.Statement, error) {
 	var err error
 
-	// Parse "TABLE".
-	if err := p.parseTokens(scanner.TABLE); err != nil {
-		return nil, err
+	// Parse "TABLE" or "SEQUENCE".
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	switch tok {
+	case scanner.TABLE:
+	case scanner.SEQUENCE:
+		return p.parseAlterSequenceStatement()
+	default:
+		return nil, newParseError(scanner.Tokstr(tok, lit), []string{"TABLE", "SEQUENCE"}, pos)
 	}
 
 	// Parse table name.
@@ -63,13 +185,17 @@ func (p *Parser) parseAlterStatement() (statement.Statement, error) {
 		return nil, pErr
 	}
 
-	tok, pos, lit := p.ScanIgnoreWhitespace()
+	tok, pos, lit = p.ScanIgnoreWhitespace()
 	switch tok {
 	case scanner.RENAME:
 		return p.parseAlterTableRenameStatement(tableName)
 	case scanner.ADD_KEYWORD:
 		return p.parseAlterTableAddFieldStatement(tableName)
+	case scanner.SET:
+		return p.parseAlterTableSetStatement(tableName)
+	case scanner.DROP:
+		return p.parseAlterTableDropMaskStatement(tableName)
 	}
 
-	return nil, newParseError(scanner.Tokstr(tok, lit), []string{"ADD", "RENAME"}, pos)
+	return nil, newParseError(scanner.Tokstr(tok, lit), []string{"ADD", "DROP", "RENAME", "SET"}, pos)
 }
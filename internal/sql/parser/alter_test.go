@@ -23,6 +23,22 @@ func TestParserAlterTable(t *testing.T) {
 		{"With error / missing TABLE keyword", "ALTER foo RENAME TO bar", statement.AlterStmt{}, true},
 		{"With error / two identifiers for table name", "ALTER TABLE foo baz RENAME TO bar", statement.AlterStmt{}, true},
 		{"With error / two identifiers for new table name", "ALTER TABLE foo RENAME TO bar baz", statement.AlterStmt{}, true},
+		{"Set read only", "ALTER TABLE foo SET READ ONLY", statement.AlterTableSetReadOnly{TableName: "foo", ReadOnly: true}, false},
+		{"Set read write", "ALTER TABLE foo SET READ WRITE", statement.AlterTableSetReadOnly{TableName: "foo"}, false},
+		{"With error / missing READ keyword", "ALTER TABLE foo SET ONLY", nil, true},
+		{"With error / missing ONLY or WRITE keyword", "ALTER TABLE foo SET READ", nil, true},
+		{"Set mask", "ALTER TABLE foo SET MASK ON a.b USING mask(a.b, 4)", statement.AlterTableSetMask{TableName: "foo", Mask: database.FieldMask{
+			Path: document.Path(testutil.ParsePath(t, "a.b")),
+			Expr: expr.Mask(parser.MustParseExpr("mask(a.b, 4)")),
+		}}, false},
+		{"Drop mask", "ALTER TABLE foo DROP MASK ON a.b", statement.AlterTableDropMask{TableName: "foo", Path: document.Path(testutil.ParsePath(t, "a.b"))}, false},
+		{"With error / missing USING keyword", "ALTER TABLE foo SET MASK ON a 'x'", nil, true},
+		{"With error / missing mask expression", "ALTER TABLE foo SET MASK ON a USING", nil, true},
+		{"With error / missing MASK keyword", "ALTER TABLE foo DROP ON a", nil, true},
+		{"Restart sequence", "ALTER SEQUENCE foo RESTART WITH 10", statement.AlterSequenceRestart{SequenceName: "foo", Value: 10}, false},
+		{"Restart sequence without WITH", "ALTER SEQUENCE foo RESTART -10", statement.AlterSequenceRestart{SequenceName: "foo", Value: -10}, false},
+		{"With error / missing RESTART keyword", "ALTER SEQUENCE foo WITH 10", nil, true},
+		{"With error / missing restart value", "ALTER SEQUENCE foo RESTART WITH", nil, true},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,21 @@
+package parser
+
+import (
+	"github.com/genjidb/genji/internal/query/statement"
+	"github.com/genjidb/genji/internal/sql/scanner"
+)
+
+// parseCheckStatement parses a CHECK INDEX statement.
+// This function assumes the CHECK identifier has already been consumed.
+func (p *Parser) parseCheckStatement() (statement.Statement, error) {
+	if err := p.parseTokens(scanner.INDEX); err != nil {
+		return nil, err
+	}
+
+	name, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	return statement.CheckIndexStmt{IndexName: name}, nil
+}
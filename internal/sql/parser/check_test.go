@@ -0,0 +1,36 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/internal/query/statement"
+	"github.com/genjidb/genji/internal/sql/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserCheck(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected statement.Statement
+		errored  bool
+	}{
+		{"CHECK INDEX foo", statement.CheckIndexStmt{IndexName: "foo"}, false},
+		{"check index `foo bar`", statement.CheckIndexStmt{IndexName: "foo bar"}, false},
+		{"CHECK INDEX", nil, true},
+		{"CHECK TABLE foo", nil, true},
+		{"CHECK", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			q, err := parser.ParseQuery(test.s)
+			if test.errored {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, q.Statements, 1)
+			require.EqualValues(t, test.expected, q.Statements[0])
+		})
+	}
+}
@@ -1,8 +1,11 @@
 package parser
 
 import (
+	"errors"
 	"math"
+	"strings"
 
+	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/internal/database"
 	"github.com/genjidb/genji/internal/expr"
 	"github.com/genjidb/genji/internal/query/statement"
@@ -27,9 +30,14 @@ func (p *Parser) parseCreateStatement() (statement.Statement, error) {
 		return p.parseCreateIndexStatement(false)
 	case scanner.SEQUENCE:
 		return p.parseCreateSequenceStatement()
+	case scanner.IDENT:
+		// VIEW is not a keyword, to allow using it as an identifier.
+		if strings.EqualFold(lit, "VIEW") {
+			return p.parseCreateViewStatement()
+		}
 	}
 
-	return nil, newParseError(scanner.Tokstr(tok, lit), []string{"TABLE", "INDEX", "SEQUENCE"}, pos)
+	return nil, newParseError(scanner.Tokstr(tok, lit), []string{"TABLE", "INDEX", "SEQUENCE", "VIEW"}, pos)
 }
 
 // parseCreateTableStatement parses a create table string and returns a Statement AST object.
@@ -52,9 +60,136 @@ func (p *Parser) parseCreateTableStatement() (*statement.CreateTableStmt, error)
 
 	// parse field constraints
 	err = p.parseConstraints(&stmt)
+	if err != nil {
+		return &stmt, err
+	}
+
+	// parse WITH CHECKSUM or WITH (option = value, ...)
+	err = p.parseTableOptions(&stmt)
 	return &stmt, err
 }
 
+// parseTableOptions parses the optional WITH clause of a create table statement.
+// It is either WITH CHECKSUM or a list of options, e.g. WITH (checksum = true, audit = true, docid = 'ulid', field_order = 'canonical', engine = 'memory').
+func (p *Parser) parseTableOptions(stmt *statement.CreateTableStmt) error {
+	info := &stmt.Info
+
+	if ok, err := p.parseOptional(scanner.WITH); !ok || err != nil {
+		return err
+	}
+
+	// option names are not keywords, to allow using them as identifiers.
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	if tok == scanner.IDENT && strings.EqualFold(lit, "checksum") {
+		info.Checksum = true
+		return nil
+	}
+	if tok != scanner.LPAREN {
+		return newParseError(scanner.Tokstr(tok, lit), []string{"CHECKSUM", "("}, pos)
+	}
+
+	for {
+		tok, pos, lit := p.ScanIgnoreWhitespace()
+		if tok != scanner.IDENT {
+			return newParseError(scanner.Tokstr(tok, lit), []string{"option"}, pos)
+		}
+
+		var opt *bool
+		switch strings.ToLower(lit) {
+		case "checksum":
+			opt = &info.Checksum
+		case "audit":
+			opt = &info.Audit
+		case "docid_cache":
+			if err := p.parseTokens(scanner.EQ); err != nil {
+				return err
+			}
+
+			cache, err := p.parseInteger()
+			if err != nil {
+				return err
+			}
+			if cache < 1 {
+				return &ParseError{Message: "docid_cache must be greater than zero"}
+			}
+			stmt.DocidCache = uint64(cache)
+		case "docid":
+			if err := p.parseTokens(scanner.EQ); err != nil {
+				return err
+			}
+
+			tok, pos, lit := p.ScanIgnoreWhitespace()
+			if tok != scanner.STRING {
+				return newParseError(scanner.Tokstr(tok, lit), []string{"string"}, pos)
+			}
+
+			for _, strategy := range database.DocidStrategies {
+				if strings.EqualFold(lit, strategy) {
+					info.DocidStrategy = strategy
+				}
+			}
+			if info.DocidStrategy == "" {
+				return &ParseError{Message: stringutil.Sprintf("unknown docid strategy %q", lit)}
+			}
+		case "field_order":
+			if err := p.parseTokens(scanner.EQ); err != nil {
+				return err
+			}
+
+			tok, pos, lit := p.ScanIgnoreWhitespace()
+			if tok != scanner.STRING {
+				return newParseError(scanner.Tokstr(tok, lit), []string{"string"}, pos)
+			}
+
+			for _, order := range database.FieldOrders {
+				if strings.EqualFold(lit, order) {
+					info.FieldOrder = order
+				}
+			}
+			if info.FieldOrder == "" {
+				return &ParseError{Message: stringutil.Sprintf("unknown field order %q", lit)}
+			}
+		case "engine":
+			if err := p.parseTokens(scanner.EQ); err != nil {
+				return err
+			}
+
+			tok, pos, lit := p.ScanIgnoreWhitespace()
+			if tok != scanner.STRING {
+				return newParseError(scanner.Tokstr(tok, lit), []string{"string"}, pos)
+			}
+			if lit == "" || strings.IndexByte(lit, 0) >= 0 {
+				return &ParseError{Message: stringutil.Sprintf("invalid engine name %q", lit)}
+			}
+			info.Engine = lit
+		default:
+			return newParseError(scanner.Tokstr(tok, lit), []string{"CHECKSUM", "AUDIT", "DOCID_CACHE", "DOCID", "FIELD_ORDER", "ENGINE"}, pos)
+		}
+
+		if opt != nil {
+			if err := p.parseTokens(scanner.EQ); err != nil {
+				return err
+			}
+
+			switch tok, pos, lit := p.ScanIgnoreWhitespace(); tok {
+			case scanner.TRUE:
+				*opt = true
+			case scanner.FALSE:
+				*opt = false
+			default:
+				return newParseError(scanner.Tokstr(tok, lit), []string{"TRUE", "FALSE"}, pos)
+			}
+		}
+
+		if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.COMMA {
+			p.Unscan()
+			break
+		}
+	}
+
+	return p.parseTokens(scanner.RPAREN)
+}
+
 func (p *Parser) parseFieldDefinition(fc *database.FieldConstraint) (err error) {
 	fc.Path, err = p.parsePath()
 	if err != nil {
@@ -71,6 +206,17 @@ func (p *Parser) parseFieldDefinition(fc *database.FieldConstraint) (err error)
 		return err
 	}
 
+	if fc.Identity != nil {
+		switch {
+		case fc.HasDefaultValue():
+			return &ParseError{Message: "AUTOINCREMENT cannot be used with DEFAULT"}
+		case fc.Type.IsAny():
+			fc.Type = document.IntegerValue
+		case fc.Type != document.IntegerValue:
+			return &ParseError{Message: "AUTOINCREMENT can only be used on INTEGER fields"}
+		}
+	}
+
 	if fc.Type.IsAny() && fc.DefaultValue == nil && !fc.IsNotNull && !fc.IsPrimaryKey && !fc.IsUnique {
 		tok, pos, lit := p.ScanIgnoreWhitespace()
 		return newParseError(scanner.Tokstr(tok, lit), []string{"CONSTRAINT", "TYPE"}, pos)
@@ -135,10 +281,11 @@ func (p *Parser) parseConstraints(stmt *statement.CreateTableStmt) error {
 		return err
 	}
 
-	// ensure only one primary key
+	// ensure only one primary key.
+	// the fields of a composite primary key have different positions.
 	var pkFound bool
 	for _, fc := range stmt.Info.FieldConstraints {
-		if fc.IsPrimaryKey {
+		if fc.IsPrimaryKey && fc.PrimaryKeyPosition == 0 {
 			if pkFound {
 				return stringutil.Errorf("table %q has more than one primary key", stmt.Info.TableName)
 			}
@@ -147,6 +294,20 @@ func (p *Parser) parseConstraints(stmt *statement.CreateTableStmt) error {
 		}
 	}
 
+	// ensure constraint names are unique
+	names := make(map[string]struct{})
+	for _, fc := range stmt.Info.FieldConstraints {
+		for _, name := range []string{fc.PrimaryKeyName, fc.UniqueName} {
+			if name == "" {
+				continue
+			}
+			if _, ok := names[name]; ok {
+				return stringutil.Errorf("constraint %q already exists in table %q", name, stmt.Info.TableName)
+			}
+			names[name] = struct{}{}
+		}
+	}
+
 	return nil
 }
 
@@ -224,6 +385,44 @@ func (p *Parser) parseFieldConstraint(fc *database.FieldConstraint) error {
 			}
 
 			fc.IsUnique = true
+		case scanner.IDENT:
+			if strings.EqualFold(lit, "AUTOINCREMENT") {
+				// if it's already an identity we return an error
+				if fc.Identity != nil {
+					return newParseError(scanner.Tokstr(tok, lit), []string{"CONSTRAINT", ")"}, pos)
+				}
+
+				// the sequence is created along with the table
+				fc.Identity = &database.FieldConstraintIdentity{}
+				continue
+			}
+
+			if !strings.EqualFold(lit, "CONSTRAINT") {
+				p.Unscan()
+				return nil
+			}
+
+			// Parse "name" and the constraint it refers to
+			name, err := p.parseIdent()
+			if err != nil {
+				return err
+			}
+
+			tok, pos, lit = p.ScanIgnoreWhitespace()
+			switch {
+			case tok == scanner.PRIMARY && !fc.IsPrimaryKey:
+				if err := p.parseTokens(scanner.KEY); err != nil {
+					return err
+				}
+
+				fc.IsPrimaryKey = true
+				fc.PrimaryKeyName = name
+			case tok == scanner.UNIQUE && !fc.IsUnique:
+				fc.IsUnique = true
+				fc.UniqueName = name
+			default:
+				return newParseError(scanner.Tokstr(tok, lit), []string{"PRIMARY KEY", "UNIQUE"}, pos)
+			}
 		default:
 			p.Unscan()
 			return nil
@@ -231,43 +430,89 @@ func (p *Parser) parseFieldConstraint(fc *database.FieldConstraint) error {
 	}
 }
 
+// parseConstraintName parses the optional "CONSTRAINT name" prefix of a table constraint.
+// If CONSTRAINT is not followed by a name, it is the name of a field
+// and the tokens are put back onto the buffer.
+func (p *Parser) parseConstraintName() (string, bool) {
+	tok, _, lit := p.ScanIgnoreWhitespace()
+	if tok != scanner.IDENT || !strings.EqualFold(lit, "CONSTRAINT") {
+		p.Unscan()
+		return "", false
+	}
+
+	n := 1
+	tok, _, lit = p.Scan()
+	if tok == scanner.WS {
+		n++
+		tok, _, lit = p.Scan()
+	}
+	if tok == scanner.IDENT {
+		return lit, true
+	}
+
+	for i := 0; i <= n; i++ {
+		p.Unscan()
+	}
+	return "", false
+}
+
 func (p *Parser) parseTableConstraint(stmt *statement.CreateTableStmt) (bool, error) {
 	var err error
 
-	tok, _, _ := p.ScanIgnoreWhitespace()
+	name, named := p.parseConstraintName()
+
+	tok, pos, lit := p.ScanIgnoreWhitespace()
 	switch tok {
 	case scanner.PRIMARY:
-		// Parse "KEY ("
-		err = p.parseTokens(scanner.KEY, scanner.LPAREN)
+		// Parse "KEY"
+		err = p.parseTokens(scanner.KEY)
 		if err != nil {
 			return false, err
 		}
 
-		primaryKeyPath, err := p.parsePath()
+		// Parse "(path, path, ...)"
+		primaryKeyPaths, err := p.parsePathList()
 		if err != nil {
 			return false, err
 		}
-
-		// Parse ")"
-		err = p.parseTokens(scanner.RPAREN)
-		if err != nil {
-			return false, err
+		if len(primaryKeyPaths) == 0 {
+			tok, pos, lit := p.ScanIgnoreWhitespace()
+			return false, newParseError(scanner.Tokstr(tok, lit), []string{"("}, pos)
 		}
 
-		if pk := stmt.Info.FieldConstraints.GetPrimaryKey(); pk != nil {
+		if pks := stmt.Info.FieldConstraints.GetPrimaryKeys(); len(pks) > 0 {
 			return false, stringutil.Errorf("table %q has more than one primary key", stmt.Info.TableName)
 		}
-		fc := stmt.Info.FieldConstraints.Get(primaryKeyPath)
-		if fc == nil {
-			err = stmt.Info.FieldConstraints.Add(&database.FieldConstraint{
-				Path:         primaryKeyPath,
-				IsPrimaryKey: true,
-			})
-			if err != nil {
-				return false, err
+
+		for i, primaryKeyPath := range primaryKeyPaths {
+			for _, other := range primaryKeyPaths[:i] {
+				if other.IsEqual(primaryKeyPath) {
+					return false, stringutil.Errorf("field %q appears more than once in the primary key", primaryKeyPath)
+				}
+			}
+
+			// the name of the primary key is held by its first field
+			var pkName string
+			if i == 0 {
+				pkName = name
+			}
+
+			fc := stmt.Info.FieldConstraints.Get(primaryKeyPath)
+			if fc == nil {
+				err = stmt.Info.FieldConstraints.Add(&database.FieldConstraint{
+					Path:               primaryKeyPath,
+					IsPrimaryKey:       true,
+					PrimaryKeyName:     pkName,
+					PrimaryKeyPosition: i,
+				})
+				if err != nil {
+					return false, err
+				}
+			} else {
+				fc.IsPrimaryKey = true
+				fc.PrimaryKeyName = pkName
+				fc.PrimaryKeyPosition = i
 			}
-		} else {
-			fc.IsPrimaryKey = true
 		}
 
 		return true, nil
@@ -292,18 +537,30 @@ func (p *Parser) parseTableConstraint(stmt *statement.CreateTableStmt) (bool, er
 		fc := stmt.Info.FieldConstraints.Get(uniquePath)
 		if fc == nil {
 			err = stmt.Info.FieldConstraints.Add(&database.FieldConstraint{
-				Path:     uniquePath,
-				IsUnique: true,
+				Path:       uniquePath,
+				IsUnique:   true,
+				UniqueName: name,
 			})
 			if err != nil {
 				return false, err
 			}
 		} else {
+			if fc.IsUnique && named && fc.UniqueName != "" {
+				return false, stringutil.Errorf("field %q has more than one unique constraint", uniquePath)
+			}
+
 			fc.IsUnique = true
+			if named {
+				fc.UniqueName = name
+			}
 		}
 
 		return true, nil
 	default:
+		if named {
+			return false, newParseError(scanner.Tokstr(tok, lit), []string{"PRIMARY KEY", "UNIQUE"}, pos)
+		}
+
 		p.Unscan()
 		return false, nil
 	}
@@ -585,3 +842,54 @@ func (p *Parser) parseCreateSequenceStatement() (*statement.CreateSequenceStmt,
 	}
 	return &stmt, err
 }
+
+// parseCreateViewStatement parses a create view string and returns a Statement AST object.
+// This function assumes the CREATE VIEW tokens have already been consumed.
+func (p *Parser) parseCreateViewStatement() (*statement.CreateViewStmt, error) {
+	var stmt statement.CreateViewStmt
+	var err error
+
+	// Parse IF NOT EXISTS
+	stmt.IfNotExists, err = p.parseOptional(scanner.IF, scanner.NOT, scanner.EXISTS)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse view name
+	stmt.Info.ViewName, err = p.parseIdent()
+	if err != nil {
+		pErr := err.(*ParseError)
+		pErr.Expected = []string{"view_name"}
+		return nil, pErr
+	}
+
+	if err := p.parseTokens(scanner.AS); err != nil {
+		return nil, err
+	}
+
+	// Parse the query, which is stored as written
+	var query *statement.StreamStmt
+	tok, start, lit := p.ScanIgnoreWhitespace()
+	switch tok {
+	case scanner.SELECT:
+		query, err = p.parseSelectStatement()
+	case scanner.WITH:
+		query, err = p.parseWithStatement()
+	default:
+		return nil, newParseError(scanner.Tokstr(tok, lit), []string{"SELECT", "WITH"}, start)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !query.ReadOnly {
+		return nil, errors.New("the query of a view must be read-only")
+	}
+
+	_, end, _ := p.ScanIgnoreWhitespace()
+	p.Unscan()
+
+	stmt.Info.Query = strings.TrimSpace(p.sourceText(start, end))
+	stmt.Stream = query.Stream
+
+	return &stmt, nil
+}
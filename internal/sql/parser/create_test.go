@@ -23,6 +23,32 @@ func TestParserCreateTable(t *testing.T) {
 		{"Basic", "CREATE TABLE test", &statement.CreateTableStmt{Info: database.TableInfo{TableName: "test"}}, false},
 		{"If not exists", "CREATE TABLE IF NOT EXISTS test", &statement.CreateTableStmt{Info: database.TableInfo{TableName: "test"}, IfNotExists: true}, false},
 		{"Path only", "CREATE TABLE test(a)", nil, true},
+		{"With checksum", "CREATE TABLE test WITH CHECKSUM", &statement.CreateTableStmt{Info: database.TableInfo{TableName: "test", Checksum: true}}, false},
+		{"With options", "CREATE TABLE test WITH (audit = true, CHECKSUM = false)", &statement.CreateTableStmt{Info: database.TableInfo{TableName: "test", Audit: true}}, false},
+		{"With error / unknown option", "CREATE TABLE test WITH (foo = true)", nil, true},
+		{"With docid cache", "CREATE TABLE test WITH (audit = true, docid_cache = 1)", &statement.CreateTableStmt{Info: database.TableInfo{TableName: "test", Audit: true}, DocidCache: 1}, false},
+		{"With error / invalid docid cache", "CREATE TABLE test WITH (docid_cache = 0)", nil, true},
+		{"With error / missing docid cache", "CREATE TABLE test WITH (docid_cache = true)", nil, true},
+		{"With docid strategy", "CREATE TABLE test WITH (docid = 'ULID')", &statement.CreateTableStmt{Info: database.TableInfo{TableName: "test", DocidStrategy: database.DocidULID}}, false},
+		{"With error / unknown docid strategy", "CREATE TABLE test WITH (docid = 'uuid')", nil, true},
+		{"With error / invalid docid strategy", "CREATE TABLE test WITH (docid = random)", nil, true},
+		{"With field order", "CREATE TABLE test WITH (field_order = 'Canonical')", &statement.CreateTableStmt{Info: database.TableInfo{TableName: "test", FieldOrder: database.FieldOrderCanonical}}, false},
+		{"With error / unknown field order", "CREATE TABLE test WITH (field_order = 'sorted')", nil, true},
+		{"With engine", "CREATE TABLE test WITH (engine = 'memory')", &statement.CreateTableStmt{Info: database.TableInfo{TableName: "test", Engine: "memory"}}, false},
+		{"With error / empty engine", "CREATE TABLE test WITH (engine = '')", nil, true},
+		{"With error / missing option value", "CREATE TABLE test WITH (audit)", nil, true},
+		{"With error / missing closing parenthesis", "CREATE TABLE test WITH (audit = true", nil, true},
+		{"With checksum and constraints", "CREATE TABLE test(foo INTEGER) WITH checksum",
+			&statement.CreateTableStmt{
+				Info: database.TableInfo{
+					TableName: "test",
+					Checksum:  true,
+					FieldConstraints: []*database.FieldConstraint{
+						{Path: document.Path(testutil.ParsePath(t, "foo")), Type: document.IntegerValue},
+					},
+				},
+			}, false},
+		{"With unknown option", "CREATE TABLE test WITH foo", nil, true},
 		{"With primary key", "CREATE TABLE test(foo INTEGER PRIMARY KEY)",
 			&statement.CreateTableStmt{
 				Info: database.TableInfo{
@@ -61,6 +87,27 @@ func TestParserCreateTable(t *testing.T) {
 				},
 			}, false},
 		{"With default twice", "CREATE TABLE test(foo DEFAULT 10 DEFAULT 10)", nil, true},
+		{"With autoincrement", "CREATE TABLE test(id INTEGER PRIMARY KEY AUTOINCREMENT)",
+			&statement.CreateTableStmt{
+				Info: database.TableInfo{
+					TableName: "test",
+					FieldConstraints: []*database.FieldConstraint{
+						{Path: document.Path(testutil.ParsePath(t, "id")), Type: document.IntegerValue, IsPrimaryKey: true, Identity: &database.FieldConstraintIdentity{}},
+					},
+				},
+			}, false},
+		{"With autoincrement without type", "CREATE TABLE test(id autoincrement)",
+			&statement.CreateTableStmt{
+				Info: database.TableInfo{
+					TableName: "test",
+					FieldConstraints: []*database.FieldConstraint{
+						{Path: document.Path(testutil.ParsePath(t, "id")), Type: document.IntegerValue, Identity: &database.FieldConstraintIdentity{}},
+					},
+				},
+			}, false},
+		{"With autoincrement twice", "CREATE TABLE test(id INTEGER AUTOINCREMENT AUTOINCREMENT)", nil, true},
+		{"With autoincrement on text", "CREATE TABLE test(id TEXT AUTOINCREMENT)", nil, true},
+		{"With autoincrement and default", "CREATE TABLE test(id INTEGER AUTOINCREMENT DEFAULT 1)", nil, true},
 		{"With forbidden tokens", "CREATE TABLE test(foo DEFAULT a)", nil, true},
 		{"With forbidden tokens", "CREATE TABLE test(foo DEFAULT 1 AND 2)", nil, true},
 		{"With unique", "CREATE TABLE test(foo UNIQUE)",
@@ -133,6 +180,20 @@ func TestParserCreateTable(t *testing.T) {
 					},
 				},
 			}, false},
+		{"With table constraints / composite PK", "CREATE TABLE test(foo INTEGER, bar TEXT, CONSTRAINT pk PRIMARY KEY (bar, foo, baz))",
+			&statement.CreateTableStmt{
+				Info: database.TableInfo{
+					TableName: "test",
+					FieldConstraints: []*database.FieldConstraint{
+						{Path: document.Path(testutil.ParsePath(t, "foo")), Type: document.IntegerValue, IsPrimaryKey: true, PrimaryKeyPosition: 1},
+						{Path: document.Path(testutil.ParsePath(t, "bar")), Type: document.TextValue, IsPrimaryKey: true, PrimaryKeyName: "pk"},
+						{Path: document.Path(testutil.ParsePath(t, "baz")), IsPrimaryKey: true, PrimaryKeyPosition: 2},
+					},
+				},
+			}, false},
+		{"With table constraints / composite PK with duplicate path", "CREATE TABLE test(foo INTEGER, PRIMARY KEY (foo, foo))", nil, true},
+		{"With table constraints / composite PK and field PK", "CREATE TABLE test(foo INTEGER PRIMARY KEY, PRIMARY KEY (foo, bar))", nil, true},
+		{"With table constraints / empty PK", "CREATE TABLE test(foo INTEGER, PRIMARY KEY ())", nil, true},
 		{"With table constraints / field constraint after table constraint", "CREATE TABLE test(PRIMARY KEY (bar), foo INTEGER)", nil, true},
 		{"With table constraints / duplicate pk", "CREATE TABLE test(foo INTEGER PRIMARY KEY, PRIMARY KEY (bar))", nil, true},
 		{"With table constraints / duplicate pk on same path", "CREATE TABLE test(foo INTEGER PRIMARY KEY, PRIMARY KEY (foo))", nil, true},
@@ -167,6 +228,39 @@ func TestParserCreateTable(t *testing.T) {
 			}, false},
 		{"With table constraints / duplicate pk on same path", "CREATE TABLE test(foo INTEGER PRIMARY KEY, PRIMARY KEY (foo))", nil, true},
 		{"With multiple primary keys", "CREATE TABLE test(foo PRIMARY KEY, bar PRIMARY KEY)", nil, true},
+		{"With named constraints", "CREATE TABLE test(foo INTEGER CONSTRAINT pk_foo PRIMARY KEY, bar CONSTRAINT uq_bar UNIQUE)",
+			&statement.CreateTableStmt{
+				Info: database.TableInfo{
+					TableName: "test",
+					FieldConstraints: []*database.FieldConstraint{
+						{Path: document.Path(testutil.ParsePath(t, "foo")), Type: document.IntegerValue, IsPrimaryKey: true, PrimaryKeyName: "pk_foo"},
+						{Path: document.Path(testutil.ParsePath(t, "bar")), IsUnique: true, UniqueName: "uq_bar"},
+					},
+				},
+			}, false},
+		{"With table constraints / named", "CREATE TABLE test(foo INTEGER, CONSTRAINT pk_foo PRIMARY KEY (foo), CONSTRAINT uq_bar UNIQUE (bar))",
+			&statement.CreateTableStmt{
+				Info: database.TableInfo{
+					TableName: "test",
+					FieldConstraints: []*database.FieldConstraint{
+						{Path: document.Path(testutil.ParsePath(t, "foo")), Type: document.IntegerValue, IsPrimaryKey: true, PrimaryKeyName: "pk_foo"},
+						{Path: document.Path(testutil.ParsePath(t, "bar")), IsUnique: true, UniqueName: "uq_bar"},
+					},
+				},
+			}, false},
+		{"With field named constraint", "CREATE TABLE test(constraint INTEGER, CONSTRAINT uq UNIQUE (constraint))",
+			&statement.CreateTableStmt{
+				Info: database.TableInfo{
+					TableName: "test",
+					FieldConstraints: []*database.FieldConstraint{
+						{Path: document.Path(testutil.ParsePath(t, "constraint")), Type: document.IntegerValue, IsUnique: true, UniqueName: "uq"},
+					},
+				},
+			}, false},
+		{"With named constraint / missing name", "CREATE TABLE test(foo INTEGER CONSTRAINT UNIQUE)", nil, true},
+		{"With named constraint / not null", "CREATE TABLE test(foo INTEGER CONSTRAINT nn NOT NULL)", nil, true},
+		{"With table constraints / named not null", "CREATE TABLE test(foo INTEGER, CONSTRAINT nn NOT NULL (foo))", nil, true},
+		{"With named constraints / duplicate name", "CREATE TABLE test(foo INTEGER CONSTRAINT c UNIQUE, CONSTRAINT c PRIMARY KEY (bar))", nil, true},
 		{"With all supported fixed size data types",
 			"CREATE TABLE test(d double, b bool)",
 			&statement.CreateTableStmt{
@@ -450,3 +544,41 @@ func TestParserCreateSequence(t *testing.T) {
 		})
 	}
 }
+
+func TestParserCreateView(t *testing.T) {
+	tests := []struct {
+		name        string
+		s           string
+		expected    database.ViewInfo
+		ifNotExists bool
+		errored     bool
+	}{
+		{"Basic", "CREATE VIEW v AS SELECT * FROM foo", database.ViewInfo{ViewName: "v", Query: "SELECT * FROM foo"}, false, false},
+		{"If not exists", "CREATE VIEW IF NOT EXISTS v AS SELECT a FROM foo", database.ViewInfo{ViewName: "v", Query: "SELECT a FROM foo"}, true, false},
+		{"Multiline", "CREATE VIEW v AS\n  SELECT a, b\n  FROM foo\n  WHERE a > 1;", database.ViewInfo{ViewName: "v", Query: "SELECT a, b\n  FROM foo\n  WHERE a > 1"}, false, false},
+		{"With", "CREATE VIEW v AS WITH t AS (SELECT a FROM foo) SELECT * FROM t", database.ViewInfo{ViewName: "v", Query: "WITH t AS (SELECT a FROM foo) SELECT * FROM t"}, false, false},
+		{"Lowercase", "create view v as select a from foo", database.ViewInfo{ViewName: "v", Query: "select a from foo"}, false, false},
+		{"No AS", "CREATE VIEW v SELECT * FROM foo", database.ViewInfo{}, false, true},
+		{"No query", "CREATE VIEW v AS", database.ViewInfo{}, false, true},
+		{"Not a select", "CREATE VIEW v AS DELETE FROM foo", database.ViewInfo{}, false, true},
+		{"Not read-only", "CREATE VIEW v AS SELECT NEXT VALUE FOR seq", database.ViewInfo{}, false, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := parser.ParseQuery(test.s)
+			if test.errored {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, q.Statements, 1)
+
+			stmt, ok := q.Statements[0].(*statement.CreateViewStmt)
+			require.True(t, ok)
+			require.Equal(t, test.expected, stmt.Info)
+			require.Equal(t, test.ifNotExists, stmt.IfNotExists)
+			require.NotNil(t, stmt.Stream)
+		})
+	}
+}
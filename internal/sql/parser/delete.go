@@ -48,5 +48,10 @@ func (p *Parser) parseDeleteStatement() (*statement.StreamStmt, error) {
 		return nil, err
 	}
 
+	stmt.Returning, err = p.parseReturning()
+	if err != nil {
+		return nil, err
+	}
+
 	return stmt.ToStream()
 }
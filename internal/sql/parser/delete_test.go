@@ -6,6 +6,7 @@ import (
 	"github.com/genjidb/genji/internal/query/statement"
 	"github.com/genjidb/genji/internal/sql/parser"
 	"github.com/genjidb/genji/internal/stream"
+	"github.com/genjidb/genji/internal/testutil"
 	"github.com/stretchr/testify/require"
 )
 
@@ -47,6 +48,13 @@ func TestParserDelete(t *testing.T) {
 				Pipe(stream.Take(10)).
 				Pipe(stream.TableDelete("test")),
 		},
+		{"WithLimitThenReturning", "DELETE FROM test WHERE age = 10 LIMIT 10 RETURNING pk(), age",
+			stream.New(stream.SeqScan("test")).
+				Pipe(stream.Filter(parser.MustParseExpr("age = 10"))).
+				Pipe(stream.Take(10)).
+				Pipe(stream.TableDelete("test")).
+				Pipe(stream.Project(testutil.ParseNamedExpr(t, "pk()"), testutil.ParseNamedExpr(t, "age"))),
+		},
 	}
 
 	for _, test := range tests {
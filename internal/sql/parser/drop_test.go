@@ -19,6 +19,9 @@ func TestParserDrop(t *testing.T) {
 		{"Drop table If not exists", "DROP TABLE IF EXISTS test", statement.DropTableStmt{TableName: "test", IfExists: true}, false},
 		{"Drop index", "DROP INDEX test", statement.DropIndexStmt{IndexName: "test"}, false},
 		{"Drop index if exists", "DROP INDEX IF EXISTS test", statement.DropIndexStmt{IndexName: "test", IfExists: true}, false},
+		{"Drop view", "DROP VIEW test", statement.DropViewStmt{ViewName: "test"}, false},
+		{"Drop view if exists", "DROP VIEW IF EXISTS test", statement.DropViewStmt{ViewName: "test", IfExists: true}, false},
+		{"Drop view without name", "DROP VIEW", nil, true},
 	}
 
 	for _, test := range tests {
@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"strings"
+
 	"github.com/genjidb/genji/internal/query/statement"
 	"github.com/genjidb/genji/internal/sql/scanner"
 )
@@ -8,10 +10,23 @@ import (
 // parseExplainStatement parses any statement and returns an ExplainStmt object.
 // This function assumes the EXPLAIN token has already been consumed.
 func (p *Parser) parseExplainStatement() (statement.Statement, error) {
+	var stmt statement.ExplainStmt
+
+	// parse optional format
+	if ok, err := p.parseOptional(scanner.LPAREN); err != nil {
+		return nil, err
+	} else if ok {
+		json, err := p.parseExplainFormat()
+		if err != nil {
+			return nil, err
+		}
+		stmt.JSON = json
+	}
+
 	// ensure we don't have multiple EXPLAIN keywords
 	tok, pos, lit := p.ScanIgnoreWhitespace()
-	if tok != scanner.SELECT && tok != scanner.UPDATE && tok != scanner.DELETE && tok != scanner.INSERT {
-		return nil, newParseError(scanner.Tokstr(tok, lit), []string{"INSERT", "SELECT", "UPDATE", "DELETE"}, pos)
+	if tok != scanner.SELECT && tok != scanner.UPDATE && tok != scanner.DELETE && tok != scanner.INSERT && tok != scanner.WITH {
+		return nil, newParseError(scanner.Tokstr(tok, lit), []string{"INSERT", "SELECT", "UPDATE", "DELETE", "WITH"}, pos)
 	}
 	p.Unscan()
 
@@ -20,5 +35,32 @@ func (p *Parser) parseExplainStatement() (statement.Statement, error) {
 		return nil, err
 	}
 
-	return &statement.ExplainStmt{Statement: innerStmt}, nil
+	stmt.Statement = innerStmt
+	return &stmt, nil
+}
+
+// parseExplainFormat parses "FORMAT TEXT)" or "FORMAT JSON)" and reports whether
+// the format is JSON. This function assumes the LPAREN token has already been consumed.
+func (p *Parser) parseExplainFormat() (bool, error) {
+	// option names are not keywords, to allow using them as identifiers.
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	if tok != scanner.IDENT || !strings.EqualFold(lit, "FORMAT") {
+		return false, newParseError(scanner.Tokstr(tok, lit), []string{"FORMAT"}, pos)
+	}
+
+	var json bool
+	tok, pos, lit = p.ScanIgnoreWhitespace()
+	switch {
+	case tok == scanner.IDENT && strings.EqualFold(lit, "JSON"):
+		json = true
+	case tok == scanner.TYPETEXT:
+	default:
+		return false, newParseError(scanner.Tokstr(tok, lit), []string{"TEXT", "JSON"}, pos)
+	}
+
+	if err := p.parseTokens(scanner.RPAREN); err != nil {
+		return false, err
+	}
+
+	return json, nil
 }
@@ -21,7 +21,18 @@ func TestParserExplain(t *testing.T) {
 			ReadOnly: true,
 			Stream:   stream.New(stream.SeqScan("test")).Pipe(stream.Project(expr.Wildcard{})),
 		}}, false},
+		{"Explain text format", "EXPLAIN (FORMAT TEXT) SELECT * FROM test", &statement.ExplainStmt{Statement: &statement.StreamStmt{
+			ReadOnly: true,
+			Stream:   stream.New(stream.SeqScan("test")).Pipe(stream.Project(expr.Wildcard{})),
+		}}, false},
+		{"Explain json format", "EXPLAIN (format json) SELECT * FROM test", &statement.ExplainStmt{Statement: &statement.StreamStmt{
+			ReadOnly: true,
+			Stream:   stream.New(stream.SeqScan("test")).Pipe(stream.Project(expr.Wildcard{})),
+		}, JSON: true}, false},
 		{"Multiple Explains", "EXPLAIN EXPLAIN CREATE TABLE test", nil, true},
+		{"Unknown format", "EXPLAIN (FORMAT XML) SELECT * FROM test", nil, true},
+		{"Missing format", "EXPLAIN () SELECT * FROM test", nil, true},
+		{"Unclosed format", "EXPLAIN (FORMAT JSON SELECT * FROM test", nil, true},
 	}
 
 	for _, test := range tests {
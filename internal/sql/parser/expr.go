@@ -1,12 +1,14 @@
 package parser
 
 import (
+	"encoding/hex"
 	"strconv"
 
 	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/internal/environment"
 	"github.com/genjidb/genji/internal/expr"
 	"github.com/genjidb/genji/internal/sql/scanner"
+	"github.com/genjidb/genji/internal/stream"
 	"github.com/genjidb/genji/internal/stringutil"
 )
 
@@ -190,20 +192,23 @@ func (p *Parser) parseUnaryExpr(allowed ...scanner.Token) (expr.Expr, error) {
 		p.Unscan()
 		return p.parseCastExpression()
 	case scanner.IDENT:
+		var e expr.Expr
+		var err error
 		// if the next token is a left parenthesis, this is a function
 		if tok1, _, _ := p.Scan(); tok1 == scanner.LPAREN {
 			p.Unscan()
 			p.Unscan()
-			return p.parseFunction()
+			e, err = p.parseFunction()
+		} else {
+			p.Unscan()
+			p.Unscan()
+			e, err = p.parsePathExpr()
 		}
-		p.Unscan()
-		p.Unscan()
-		field, err := p.parsePath()
 		if err != nil {
 			return nil, err
 		}
-		fs := expr.Path(field)
-		return fs, nil
+
+		return p.parseDereference(e)
 	case scanner.NAMEDPARAM:
 		if len(lit) == 1 {
 			return nil, &ParseError{Message: "missing param name"}
@@ -221,6 +226,12 @@ func (p *Parser) parseUnaryExpr(allowed ...scanner.Token) (expr.Expr, error) {
 		return expr.PositionalParam(p.orderedParams), nil
 	case scanner.STRING:
 		return expr.LiteralValue(document.NewTextValue(lit)), nil
+	case scanner.BLOB:
+		b, err := hex.DecodeString(lit)
+		if err != nil {
+			return nil, &ParseError{Message: "invalid blob literal", Pos: pos}
+		}
+		return expr.LiteralValue(document.NewBlobValue(b)), nil
 	case scanner.NUMBER:
 		v, err := strconv.ParseFloat(lit, 64)
 		if err != nil {
@@ -249,6 +260,12 @@ func (p *Parser) parseUnaryExpr(allowed ...scanner.Token) (expr.Expr, error) {
 		p.Unscan()
 		return p.parseExprList(scanner.LSBRACKET, scanner.RSBRACKET)
 	case scanner.LPAREN:
+		// (SELECT ...) is a subquery
+		if tok, _, _ := p.ScanIgnoreWhitespace(); tok == scanner.SELECT {
+			return p.parseSubquery()
+		}
+		p.Unscan()
+
 		e, err := p.ParseExpr()
 		if err != nil {
 			return nil, err
@@ -276,6 +293,18 @@ func (p *Parser) parseUnaryExpr(allowed ...scanner.Token) (expr.Expr, error) {
 			return nil, err
 		}
 		return expr.Not(e), nil
+	case scanner.EXISTS:
+		err := p.parseTokens(scanner.LPAREN, scanner.SELECT)
+		if err != nil {
+			return nil, err
+		}
+
+		sq, err := p.parseSubquery()
+		if err != nil {
+			return nil, err
+		}
+
+		return expr.Exists{Subquery: sq}, nil
 	case scanner.NEXT:
 		err := p.parseTokens(scanner.VALUE, scanner.FOR)
 		if err != nil {
@@ -292,6 +321,39 @@ func (p *Parser) parseUnaryExpr(allowed ...scanner.Token) (expr.Expr, error) {
 	}
 }
 
+// parseSubquery parses a SELECT statement followed by a right parenthesis.
+// This function assumes the left parenthesis and the SELECT token have already been consumed.
+func (p *Parser) parseSubquery() (*expr.Subquery, error) {
+	stmt, err := p.parseSelectStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.parseTokens(scanner.RPAREN); err != nil {
+		return nil, err
+	}
+
+	return &expr.Subquery{Stream: &stream.SubqueryStream{Stream: stmt.Stream}}, nil
+}
+
+// parseDereference parses the paths following the -> operator, if any,
+// to dereference the value of e.
+func (p *Parser) parseDereference(e expr.Expr) (expr.Expr, error) {
+	for {
+		if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.ARROW {
+			p.Unscan()
+			return e, nil
+		}
+
+		path, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+
+		e = expr.Dereference{Expr: e, Path: path}
+	}
+}
+
 // parseInteger parses an integer.
 func (p *Parser) parseInteger() (int64, error) {
 	tok, pos, lit := p.ScanIgnoreWhitespace()
@@ -535,6 +597,65 @@ LOOP:
 	return path, nil
 }
 
+// parsePathExpr parses a path whose bracketed fragments may be expressions,
+// evaluated for each document, e.g. a[$field] or b[i + 1].
+// If all the fragments are constants, it returns an expr.Path.
+func (p *Parser) parsePathExpr() (expr.Expr, error) {
+	// parse first mandatory ident
+	chunk, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	path := expr.DynamicPath{{PathFragment: document.PathFragment{FieldName: chunk}}}
+
+LOOP:
+	for {
+		tok, pos, _ := p.Scan()
+		switch tok {
+		case scanner.DOT:
+			// scan the next token for an ident
+			tok, pos, lit := p.Scan()
+			if tok != scanner.IDENT {
+				return nil, newParseError(lit, []string{"identifier"}, pos)
+			}
+			path = append(path, expr.DynamicPathFragment{PathFragment: document.PathFragment{FieldName: lit}})
+		case scanner.LSBRACKET:
+			e, err := p.ParseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.parseTokens(scanner.RSBRACKET); err != nil {
+				return nil, err
+			}
+
+			frag := expr.DynamicPathFragment{Expr: e}
+			// constant fragments are resolved right away
+			if lv, ok := e.(expr.LiteralValue); ok {
+				v := document.Value(lv)
+				switch v.Type {
+				case document.IntegerValue:
+					if v.V.(int64) < 0 {
+						return nil, newParseError(v.String(), []string{"array index"}, pos)
+					}
+					frag = expr.DynamicPathFragment{PathFragment: document.PathFragment{ArrayIndex: int(v.V.(int64))}}
+				case document.TextValue:
+					frag = expr.DynamicPathFragment{PathFragment: document.PathFragment{FieldName: v.V.(string)}}
+				}
+			}
+			path = append(path, frag)
+		default:
+			p.Unscan()
+			break LOOP
+		}
+	}
+
+	if dp, ok := path.Path(); ok {
+		return expr.Path(dp), nil
+	}
+
+	return path, nil
+}
+
 func (p *Parser) parseExprListUntil(rightToken scanner.Token) (expr.LiteralExprList, error) {
 	var exprList expr.LiteralExprList
 	var expr expr.Expr
@@ -7,6 +7,7 @@ import (
 	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/internal/expr"
 	"github.com/genjidb/genji/internal/sql/parser"
+	"github.com/genjidb/genji/internal/stream"
 	"github.com/genjidb/genji/internal/testutil"
 	"github.com/stretchr/testify/require"
 )
@@ -39,6 +40,28 @@ func TestParserExpr(t *testing.T) {
 		{"double quoted string", `"10.0"`, testutil.TextValue("10.0"), false},
 		{"single quoted string", "'-10.0'", testutil.TextValue("-10.0"), false},
 
+		// blobs
+		{"blob", "X'DEADbeef'", testutil.BlobValue([]byte{0xde, 0xad, 0xbe, 0xef}), false},
+		{"empty blob", "x''", testutil.BlobValue([]byte{}), false},
+		{"invalid blob", "X'DEADBEE'", nil, true},
+		{"invalid blob characters", "X'hello'", nil, true},
+		{"identifier starting with x", "xa", expr.Path(document.NewPath("xa")), false},
+
+		// paths
+		{"path with index", "a.b[1]", testutil.ParsePath(t, "a.b[1]"), false},
+		{"path with constant fragments", "a['b'][1]", testutil.ParsePath(t, "a.b[1]"), false},
+		{"path with negative index", "a[-1]", nil, true},
+		{"path with param", "a[$f].b", expr.DynamicPath{
+			{PathFragment: document.PathFragment{FieldName: "a"}},
+			{Expr: expr.NamedParam("f")},
+			{PathFragment: document.PathFragment{FieldName: "b"}},
+		}, false},
+		{"path with expression", "a[i + 1]", expr.DynamicPath{
+			{PathFragment: document.PathFragment{FieldName: "a"}},
+			{Expr: expr.Add(testutil.ParsePath(t, "i"), testutil.IntegerValue(1))},
+		}, false},
+		{"path with unclosed bracket", "a[1", nil, true},
+
 		// documents
 		{"empty document", `{}`, &expr.KVPairs{SelfReferenced: true}, false},
 		{"document values", `{a: 1, b: 1.0, c: true, d: 'string', e: "string", f: {foo: 'bar'}, g: h.i.j, k: [1, 2, 3]}`,
@@ -123,6 +146,18 @@ func TestParserExpr(t *testing.T) {
 		{"IS NOT", "age IS NOT NULL", expr.IsNot(testutil.ParsePath(t, "age"), testutil.NullValue()), false},
 		{"LIKE", "name LIKE 'foo'", expr.Like(testutil.ParsePath(t, "name"), testutil.TextValue("foo")), false},
 		{"NOT LIKE", "name NOT LIKE 'foo'", expr.NotLike(testutil.ParsePath(t, "name"), testutil.TextValue("foo")), false},
+		{"IN subquery", "age IN (SELECT a FROM b WHERE c > 1)", expr.In(testutil.ParsePath(t, "age"), &expr.Subquery{
+			Stream: &stream.SubqueryStream{Stream: stream.New(stream.SeqScan("b")).
+				Pipe(stream.Filter(parser.MustParseExpr("c > 1"))).
+				Pipe(stream.Project(testutil.ParsePath(t, "a")))},
+		}), false},
+		{"NOT IN subquery", "age NOT IN (SELECT a FROM b)", expr.NotIn(testutil.ParsePath(t, "age"), &expr.Subquery{
+			Stream: &stream.SubqueryStream{Stream: stream.New(stream.SeqScan("b")).Pipe(stream.Project(testutil.ParsePath(t, "a")))},
+		}), false},
+		{"IN unclosed subquery", "age IN (SELECT a FROM b", nil, true},
+		{"scalar subquery", "(SELECT a FROM b) + 1", expr.Add(&expr.Subquery{
+			Stream: &stream.SubqueryStream{Stream: stream.New(stream.SeqScan("b")).Pipe(stream.Project(testutil.ParsePath(t, "a")))},
+		}, testutil.IntegerValue(1)), false},
 		{"NOT =", "name NOT = 'foo'", nil, true},
 		{"precedence", "4 > 1 + 2", expr.Gt(
 			testutil.IntegerValue(4),
@@ -156,6 +191,13 @@ func TestParserExpr(t *testing.T) {
 		{"NOT", "NOT 10", expr.Not(testutil.IntegerValue(10)), false},
 		{"NOT", "NOT NOT", nil, true},
 		{"NOT", "NOT NOT 10", expr.Not(expr.Not(testutil.IntegerValue(10))), false},
+		{"EXISTS", "EXISTS (SELECT * FROM b)", expr.Exists{Subquery: &expr.Subquery{
+			Stream: &stream.SubqueryStream{Stream: stream.New(stream.SeqScan("b")).Pipe(stream.Project(expr.Wildcard{}))},
+		}}, false},
+		{"NOT EXISTS", "NOT EXISTS (SELECT * FROM b)", expr.Not(expr.Exists{Subquery: &expr.Subquery{
+			Stream: &stream.SubqueryStream{Stream: stream.New(stream.SeqScan("b")).Pipe(stream.Project(expr.Wildcard{}))},
+		}}), false},
+		{"EXISTS without subquery", "EXISTS (1)", nil, true},
 		{"NEXT VALUE FOR", "NEXT VALUE FOR hello", expr.NextValueFor{SeqName: "hello"}, false},
 		{"NEXT VALUE FOR", "NEXT VALUE FOR `good morning`", expr.NextValueFor{SeqName: "good morning"}, false},
 		{"NEXT VALUE FOR", "NEXT VALUE FOR 10", nil, true},
@@ -164,6 +206,17 @@ func TestParserExpr(t *testing.T) {
 		{"pk() function", "pk()", &expr.PKFunc{}, false},
 		{"count(expr) function", "count(a)", &expr.CountFunc{Expr: testutil.ParsePath(t, "a")}, false},
 		{"count(*) function", "count(*)", &expr.CountFunc{Wildcard: true}, false},
+		{"ref() function", "ref('users', 1)", &expr.RefFunc{Table: testutil.TextValue("users"), Key: testutil.IntegerValue(1)}, false},
+		{"deref() function", "deref(a)", &expr.DerefFunc{Expr: testutil.ParsePath(t, "a")}, false},
+
+		// dereference
+		{"->", "a.b->c", expr.Dereference{Expr: testutil.ParsePath(t, "a.b"), Path: document.NewPath("c")}, false},
+		{"-> with spaces", "a -> c[0]", expr.Dereference{Expr: testutil.ParsePath(t, "a"), Path: document.NewPath("c", "0")}, false},
+		{"-> chained", "a->b->c", expr.Dereference{Expr: expr.Dereference{Expr: testutil.ParsePath(t, "a"), Path: document.NewPath("b")}, Path: document.NewPath("c")}, false},
+		{"-> on function", "ref('users', 1)->name", expr.Dereference{Expr: &expr.RefFunc{Table: testutil.TextValue("users"), Key: testutil.IntegerValue(1)}, Path: document.NewPath("name")}, false},
+		{"-> with operator", "a->b > 1", expr.Gt(expr.Dereference{Expr: testutil.ParsePath(t, "a"), Path: document.NewPath("b")}, testutil.IntegerValue(1)), false},
+		{"-> without path", "a->", nil, true},
+		{"-> with number", "a->1", nil, true},
 	}
 
 	for _, test := range tests {
@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"strings"
+
 	"github.com/genjidb/genji/internal/database"
 	"github.com/genjidb/genji/internal/expr"
 	"github.com/genjidb/genji/internal/query/statement"
@@ -128,8 +130,21 @@ func (p *Parser) parseDocumentsWithFields(fields []string) ([]expr.Expr, error)
 	return docs, nil
 }
 
-// parseParamOrDocument parses either a parameter or a document.
-func (p *Parser) parseExprListWithFields(fields []string) (*expr.KVPairs, error) {
+// parseExprListWithFields parses either a parameter or a list of expressions
+// and associates them with the given fields.
+func (p *Parser) parseExprListWithFields(fields []string) (expr.Expr, error) {
+	// Parse a param first
+	prm, err := p.parseParam()
+	if err != nil {
+		return nil, err
+	}
+	if prm != nil {
+		return &expr.ParamWithFields{Fields: fields, Param: prm}, nil
+	}
+
+	// If not a param, start over
+	p.Unscan()
+
 	list, err := p.parseExprList(scanner.LPAREN, scanner.RPAREN)
 	if err != nil {
 		return nil, err
@@ -204,6 +219,31 @@ func (p *Parser) parseOnConflictClause() (database.OnInsertConflictAction, error
 		return nil, err
 	}
 
+	// Parse optional ON CONSTRAINT name
+	if ok, err := p.parseOptional(scanner.ON); err != nil {
+		return nil, err
+	} else if ok {
+		if tok, pos, lit := p.ScanIgnoreWhitespace(); tok != scanner.IDENT || !strings.EqualFold(lit, "CONSTRAINT") {
+			return nil, newParseError(scanner.Tokstr(tok, lit), []string{"CONSTRAINT"}, pos)
+		}
+
+		name, err := p.parseIdent()
+		if err != nil {
+			return nil, err
+		}
+
+		action, err := p.parseOnConflictAction()
+		if err != nil {
+			return nil, err
+		}
+
+		return database.OnInsertConflictOnConstraint(name, action), nil
+	}
+
+	return p.parseOnConflictAction()
+}
+
+func (p *Parser) parseOnConflictAction() (database.OnInsertConflictAction, error) {
 	tok, pos, lit := p.ScanIgnoreWhitespace()
 	// SQLite compatibility: ON CONFLICT [IGNORE | REPLACE]
 	switch tok {
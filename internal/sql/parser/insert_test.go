@@ -61,6 +61,15 @@ func TestParserInsert(t *testing.T) {
 				}},
 			)).Pipe(stream.TableInsert("test", nil)),
 			false},
+		{"Values / With fields / Params", "INSERT INTO test (a, b) VALUES ?, ('e', 'f')",
+			stream.New(stream.Expressions(
+				&expr.ParamWithFields{Fields: []string{"a", "b"}, Param: expr.PositionalParam(1)},
+				&expr.KVPairs{Pairs: []expr.KVPair{
+					{K: "a", V: testutil.TextValue("e")},
+					{K: "b", V: testutil.TextValue("f")},
+				}},
+			)).Pipe(stream.TableInsert("test", nil)),
+			false},
 		{"Values / With too many values", "INSERT INTO test (a, b) VALUES ('c', 'd', 'e')",
 			nil, true},
 		{"Values / Multiple", "INSERT INTO test (a, b) VALUES ('c', 'd'), ('e', 'f')",
@@ -124,6 +133,18 @@ func TestParserInsert(t *testing.T) {
 			)).Pipe(stream.TableInsert("test", database.OnInsertConflictDoReplace)).
 				Pipe(stream.Project(expr.Wildcard{})),
 			false},
+		{"Values / ON CONFLICT ON CONSTRAINT DO NOTHING", "INSERT INTO test (a, b) VALUES ('c', 'd') ON CONFLICT ON CONSTRAINT uq_a DO NOTHING",
+			stream.New(stream.Expressions(
+				&expr.KVPairs{Pairs: []expr.KVPair{
+					{K: "a", V: testutil.TextValue("c")},
+					{K: "b", V: testutil.TextValue("d")},
+				}},
+			)).Pipe(stream.TableInsert("test", database.OnInsertConflictOnConstraint("uq_a", database.OnInsertConflictDoNothing))),
+			false},
+		{"Values / ON CONFLICT ON CONSTRAINT without name", "INSERT INTO test (a, b) VALUES ('c', 'd') ON CONFLICT ON CONSTRAINT DO NOTHING",
+			nil, true},
+		{"Values / ON CONFLICT ON CONSTRAINT without action", "INSERT INTO test (a, b) VALUES ('c', 'd') ON CONFLICT ON CONSTRAINT uq_a",
+			nil, true},
 		{"Values / ON CONFLICT BLA", "INSERT INTO test (a, b) VALUES ('c', 'd') ON CONFLICT BLA RETURNING *",
 			nil, true},
 		{"Values / ON CONFLICT DO BLA", "INSERT INTO test (a, b) VALUES ('c', 'd') ON CONFLICT DO BLA RETURNING *",
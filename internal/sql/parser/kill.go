@@ -0,0 +1,17 @@
+package parser
+
+import (
+	"github.com/genjidb/genji/internal/query"
+	"github.com/genjidb/genji/internal/query/statement"
+)
+
+// parseKillStatement parses a KILL statement.
+// This function assumes the KILL identifier has already been consumed.
+func (p *Parser) parseKillStatement() (statement.Statement, error) {
+	id, err := p.parseInteger()
+	if err != nil {
+		return nil, err
+	}
+
+	return query.KillStmt{ProcessID: id}, nil
+}
@@ -0,0 +1,37 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/internal/query"
+	"github.com/genjidb/genji/internal/query/statement"
+	"github.com/genjidb/genji/internal/sql/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserKill(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected statement.Statement
+		errored  bool
+	}{
+		{"KILL 10", query.KillStmt{ProcessID: 10}, false},
+		{"kill 1", query.KillStmt{ProcessID: 1}, false},
+		{"KILL", nil, true},
+		{"KILL foo", nil, true},
+		{"KILL 1.5", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			q, err := parser.ParseQuery(test.s)
+			if test.errored {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, q.Statements, 1)
+			require.EqualValues(t, test.expected, q.Statements[0])
+		})
+	}
+}
@@ -6,9 +6,11 @@ import (
 
 	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/internal/expr"
+	"github.com/genjidb/genji/internal/planner"
 	"github.com/genjidb/genji/internal/query"
 	"github.com/genjidb/genji/internal/query/statement"
 	"github.com/genjidb/genji/internal/sql/scanner"
+	"github.com/genjidb/genji/internal/stream"
 	"github.com/genjidb/genji/internal/stringutil"
 )
 
@@ -18,6 +20,16 @@ type Parser struct {
 	orderedParams int
 	namedParams   int
 	functions     expr.Functions
+
+	// common table expressions defined by the WITH clause of the statement being parsed.
+	ctes map[string]*stream.CommonTableExpr
+	// name of the recursive common table expression whose recursive part is being parsed.
+	workingTable string
+	// if true, the next SELECT statement stops before its UNION clause.
+	noUnion bool
+
+	// text read by the scanner so far.
+	src *strings.Builder
 }
 
 // NewParser returns a new instance of Parser.
@@ -31,7 +43,29 @@ func NewParserWithOptions(r io.Reader, opts *Options) *Parser {
 		opts = defaultOptions()
 	}
 
-	return &Parser{s: scanner.NewScanner(r), functions: opts.Functions}
+	// record the text being parsed, to keep the SQL of some statements as written
+	var src strings.Builder
+	return &Parser{s: scanner.NewScanner(io.TeeReader(r, &src)), functions: opts.Functions, src: &src}
+}
+
+func init() {
+	// the planner parses the queries of the views it expands
+	planner.ParseViewQuery = parseViewQuery
+}
+
+// parseViewQuery parses the SELECT statement of a view and returns its stream.
+func parseViewQuery(q string) (*stream.Stream, error) {
+	stmt, err := NewParser(strings.NewReader(q)).ParseStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	st, ok := stmt.(*statement.StreamStmt)
+	if !ok || !st.ReadOnly {
+		return nil, stringutil.Errorf("invalid view query %q", q)
+	}
+
+	return st.Stream, nil
 }
 
 // ParseQuery parses a query string and returns its AST representation.
@@ -91,6 +125,8 @@ func (p *Parser) ParseStatement() (statement.Statement, error) {
 	switch tok {
 	case scanner.ALTER:
 		return p.parseAlterStatement()
+	case scanner.ANALYZE:
+		return p.parseAnalyzeStatement()
 	case scanner.BEGIN:
 		return p.parseBeginStatement()
 	case scanner.COMMIT:
@@ -113,10 +149,24 @@ func (p *Parser) ParseStatement() (statement.Statement, error) {
 		return p.parseReIndexStatement()
 	case scanner.ROLLBACK:
 		return p.parseRollbackStatement()
+	case scanner.SET:
+		return p.parseSetStatement()
+	case scanner.WITH:
+		return p.parseWithStatement()
+	case scanner.IDENT:
+		// SHOW, CHECK and KILL are not keywords, to allow using them as identifiers.
+		switch {
+		case strings.EqualFold(lit, "SHOW"):
+			return p.parseShowStatement()
+		case strings.EqualFold(lit, "CHECK"):
+			return p.parseCheckStatement()
+		case strings.EqualFold(lit, "KILL"):
+			return p.parseKillStatement()
+		}
 	}
 
 	return nil, newParseError(scanner.Tokstr(tok, lit), []string{
-		"ALTER", "BEGIN", "COMMIT", "SELECT", "DELETE", "UPDATE", "INSERT", "CREATE", "DROP", "EXPLAIN", "REINDEX", "ROLLBACK",
+		"ALTER", "ANALYZE", "BEGIN", "CHECK", "COMMIT", "SELECT", "DELETE", "UPDATE", "INSERT", "CREATE", "DROP", "EXPLAIN", "KILL", "REINDEX", "ROLLBACK", "SET", "SHOW", "WITH",
 	}, pos)
 }
 
@@ -179,6 +229,38 @@ func (p *Parser) parsePathList() ([]document.Path, error) {
 // Scan returns the next token from the underlying scanner.
 func (p *Parser) Scan() (tok scanner.Token, pos scanner.Pos, lit string) { return p.s.Scan() }
 
+// sourceText returns the text parsed between the start and end positions,
+// end excluded.
+func (p *Parser) sourceText(start, end scanner.Pos) string {
+	var sb strings.Builder
+	var pos scanner.Pos
+
+	src := p.src.String()
+	for i, ch := range src {
+		if pos == end {
+			break
+		}
+
+		// the scanner reads \r\n and \r as \n
+		if ch == '\r' && i+1 < len(src) && src[i+1] == '\n' {
+			continue
+		}
+
+		if pos.Line > start.Line || (pos.Line == start.Line && pos.Char >= start.Char) {
+			sb.WriteRune(ch)
+		}
+
+		if ch == '\n' || ch == '\r' {
+			pos.Line++
+			pos.Char = 0
+		} else {
+			pos.Char++
+		}
+	}
+
+	return sb.String()
+}
+
 // ScanIgnoreWhitespace scans the next non-whitespace and non-comment token.
 func (p *Parser) ScanIgnoreWhitespace() (tok scanner.Token, pos scanner.Pos, lit string) {
 	for {
@@ -1,9 +1,13 @@
 package parser
 
 import (
+	"strings"
+
 	"github.com/genjidb/genji/internal/expr"
 	"github.com/genjidb/genji/internal/query/statement"
 	"github.com/genjidb/genji/internal/sql/scanner"
+	"github.com/genjidb/genji/internal/stream"
+	"github.com/genjidb/genji/internal/stringutil"
 )
 
 // parseSelectStatement parses a select string and returns a Statement AST object.
@@ -12,6 +16,13 @@ func (p *Parser) parseSelectStatement() (*statement.StreamStmt, error) {
 	var stmt statement.SelectStmt
 	var err error
 
+	// only this statement stops before UNION, not its subqueries
+	noUnion := p.noUnion
+	p.noUnion = false
+
+	stmt.CTEs = p.ctes
+	stmt.WorkingTable = p.workingTable
+
 	stmt.Distinct, err = p.parseDistinct()
 	if err != nil {
 		return nil, err
@@ -25,7 +36,7 @@ func (p *Parser) parseSelectStatement() (*statement.StreamStmt, error) {
 
 	// Parse "FROM".
 	var found bool
-	stmt.TableName, found, err = p.parseFrom()
+	stmt.TableName, stmt.TableFunction, found, err = p.parseFrom()
 	if err != nil {
 		return nil, err
 	}
@@ -33,14 +44,28 @@ func (p *Parser) parseSelectStatement() (*statement.StreamStmt, error) {
 		return stmt.ToStream()
 	}
 
+	if stmt.TableFunction == nil {
+		// Parse "FOR SYSTEM_TIME AS OF expr".
+		stmt.AsOfExpr, err = p.parseSystemTime()
+		if err != nil {
+			return nil, err
+		}
+
+		// Parse "[INNER] JOIN table_name ON expr".
+		stmt.Joins, err = p.parseJoins()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Parse condition: "WHERE expr".
 	stmt.WhereExpr, err = p.parseCondition()
 	if err != nil {
 		return nil, err
 	}
 
-	// Parse group by: "GROUP BY expr"
-	stmt.GroupByExpr, err = p.parseGroupBy()
+	// Parse group by: "GROUP BY expr [GAPFILL]"
+	stmt.GroupByExpr, stmt.GapFill, err = p.parseGroupBy()
 	if err != nil {
 		return nil, err
 	}
@@ -64,9 +89,11 @@ func (p *Parser) parseSelectStatement() (*statement.StreamStmt, error) {
 	}
 
 	// Parse union: "UNION expr"
-	stmt.Union.SelectStmt, stmt.Union.All, err = p.parseUnion()
-	if err != nil {
-		return nil, err
+	if !noUnion {
+		stmt.Union.SelectStmt, stmt.Union.All, err = p.parseUnion()
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return stmt.ToStream()
@@ -134,9 +161,11 @@ func (p *Parser) parseDistinct() (bool, error) {
 	return true, nil
 }
 
-func (p *Parser) parseFrom() (string, bool, error) {
+// parseFrom parses the optional FROM clause, which selects either a table
+// or a table function, such as generate_series(1, 10).
+func (p *Parser) parseFrom() (string, stream.Operator, bool, error) {
 	if ok, err := p.parseOptional(scanner.FROM); !ok || err != nil {
-		return "", false, err
+		return "", nil, false, err
 	}
 
 	// Parse table name
@@ -144,21 +173,119 @@ func (p *Parser) parseFrom() (string, bool, error) {
 	if err != nil {
 		pErr := err.(*ParseError)
 		pErr.Expected = []string{"table_name"}
-		return ident, true, pErr
+		return ident, nil, true, pErr
 	}
 
-	return ident, true, nil
+	// if the next token is a left parenthesis, this is a table function
+	if tok, _, _ := p.Scan(); tok != scanner.LPAREN {
+		p.Unscan()
+		return ident, nil, true, nil
+	}
+
+	fn, ok := stream.TableFunctions()[strings.ToLower(ident)]
+	if !ok {
+		return "", nil, true, stringutil.Errorf("no such table function %q", ident)
+	}
+
+	args, err := p.parseExprListUntil(scanner.RPAREN)
+	if err != nil {
+		return "", nil, true, err
+	}
+
+	// Parse optional alias, used to name the generated field.
+	var alias string
+	if tok, _, _ := p.ScanIgnoreWhitespace(); tok == scanner.AS {
+		alias, err = p.parseIdent()
+		if err != nil {
+			return "", nil, true, err
+		}
+	} else {
+		p.Unscan()
+	}
+
+	op, err := fn(alias, args...)
+	return "", op, true, err
 }
 
-func (p *Parser) parseGroupBy() (expr.Expr, error) {
+// parseJoins parses the optional list of [INNER] JOIN table_name ON expr clauses.
+func (p *Parser) parseJoins() ([]statement.JoinClause, error) {
+	var joins []statement.JoinClause
+
+	for {
+		// JOIN and INNER are not keywords, to allow using them as identifiers.
+		tok, _, lit := p.ScanIgnoreWhitespace()
+		if tok == scanner.IDENT && strings.EqualFold(lit, "INNER") {
+			tok, pos, lit := p.ScanIgnoreWhitespace()
+			if tok != scanner.IDENT || !strings.EqualFold(lit, "JOIN") {
+				return nil, newParseError(scanner.Tokstr(tok, lit), []string{"JOIN"}, pos)
+			}
+		} else if tok != scanner.IDENT || !strings.EqualFold(lit, "JOIN") {
+			p.Unscan()
+			return joins, nil
+		}
+
+		var j statement.JoinClause
+		var err error
+		j.TableName, err = p.parseIdent()
+		if err != nil {
+			pErr := err.(*ParseError)
+			pErr.Expected = []string{"table_name"}
+			return nil, pErr
+		}
+
+		if err := p.parseTokens(scanner.ON); err != nil {
+			return nil, err
+		}
+
+		j.On, err = p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		joins = append(joins, j)
+	}
+}
+
+// parseSystemTime parses the optional FOR SYSTEM_TIME AS OF clause
+// and returns its expression.
+func (p *Parser) parseSystemTime() (expr.Expr, error) {
+	if ok, err := p.parseOptional(scanner.FOR); !ok || err != nil {
+		return nil, err
+	}
+
+	// SYSTEM_TIME and OF are not keywords, to allow using them as identifiers.
+	for _, want := range []string{"SYSTEM_TIME", "AS", "OF"} {
+		tok, pos, lit := p.ScanIgnoreWhitespace()
+		if want == "AS" && tok == scanner.AS {
+			continue
+		}
+		if tok != scanner.IDENT || !strings.EqualFold(lit, want) {
+			return nil, newParseError(scanner.Tokstr(tok, lit), []string{want}, pos)
+		}
+	}
+
+	return p.ParseExpr()
+}
+
+func (p *Parser) parseGroupBy() (expr.Expr, bool, error) {
 	ok, err := p.parseOptional(scanner.GROUP, scanner.BY)
 	if err != nil || !ok {
-		return nil, err
+		return nil, false, err
 	}
 
 	// parse expr
 	e, err := p.ParseExpr()
-	return e, err
+	if err != nil {
+		return nil, false, err
+	}
+
+	// GAPFILL is not a keyword, to allow using it as an identifier.
+	if tok, _, lit := p.ScanIgnoreWhitespace(); tok == scanner.IDENT && strings.EqualFold(lit, "GAPFILL") {
+		return e, true, nil
+	}
+	p.Unscan()
+
+	return e, false, nil
 }
 
 func (p *Parser) parseUnion() (*statement.StreamStmt, bool, error) {
@@ -3,6 +3,7 @@ package parser_test
 import (
 	"testing"
 
+	"github.com/genjidb/genji/document"
 	"github.com/genjidb/genji/internal/expr"
 	"github.com/genjidb/genji/internal/query/statement"
 	"github.com/genjidb/genji/internal/sql/parser"
@@ -89,12 +90,46 @@ func TestParserSelect(t *testing.T) {
 				Pipe(stream.Project(expr.Wildcard{})),
 			false,
 		},
+		{"WithSystemTime", "SELECT * FROM test FOR SYSTEM_TIME AS OF '2021-01-01' WHERE age = 10",
+			stream.New(stream.HistoryScan("test", parser.MustParseExpr("'2021-01-01'"))).
+				Pipe(stream.Filter(parser.MustParseExpr("age = 10"))).
+				Pipe(stream.Project(expr.Wildcard{})),
+			false,
+		},
+		{"WithSystemTime / missing OF", "SELECT * FROM test FOR SYSTEM_TIME AS '2021-01-01'", nil, true},
+		{"WithTableFunction", "SELECT * FROM generate_series(1, 10) WHERE generate_series > 2",
+			stream.New(stream.GenerateSeries(parser.MustParseExpr("1"), parser.MustParseExpr("10"), nil)).
+				Pipe(stream.Filter(parser.MustParseExpr("generate_series > 2"))).
+				Pipe(stream.Project(expr.Wildcard{})),
+			false,
+		},
+		{"WithTableFunction / alias", "SELECT * FROM RANGE(0, 10, 2) AS n",
+			stream.New(func() stream.Operator {
+				op := stream.Range(parser.MustParseExpr("0"), parser.MustParseExpr("10"), parser.MustParseExpr("2"))
+				op.FieldName = "n"
+				return op
+			}()).
+				Pipe(stream.Project(expr.Wildcard{})),
+			false,
+		},
+		{"WithTableFunction / unknown", "SELECT * FROM foo(1, 10)", nil, true},
+		{"WithTableFunction / wrong arity", "SELECT * FROM generate_series(1)", nil, true},
+		{"WithTableFunction / system time", "SELECT * FROM generate_series(1, 2) FOR SYSTEM_TIME AS OF '2021-01-01'", nil, true},
 		{"WithGroupBy", "SELECT a.b.c FROM test WHERE age = 10 GROUP BY a.b.c",
 			stream.New(stream.SeqScan("test")).
 				Pipe(stream.Filter(parser.MustParseExpr("age = 10"))).
 				Pipe(stream.GroupBy(parser.MustParseExpr("a.b.c"))).
 				Pipe(stream.HashAggregate()).
-				Pipe(stream.Project(testutil.ParseNamedExpr(t, "a.b.c"))),
+				// the group is read from the field named after the GROUP BY expression
+				Pipe(stream.Project(&expr.NamedExpr{Expr: expr.Path{document.PathFragment{FieldName: "a.b.c"}}, ExprName: "a.b.c"})),
+			false,
+		},
+		{"WithGroupBy / gapfill", "SELECT COUNT(*) FROM test GROUP BY date_bin('1h', a) GAPFILL",
+			stream.New(stream.SeqScan("test")).
+				Pipe(stream.GroupBy(parser.MustParseExpr("date_bin('1h', a)"))).
+				Pipe(stream.HashAggregate(&expr.CountFunc{Wildcard: true})).
+				Pipe(stream.GapFill(parser.MustParseExpr("date_bin('1h', a)").(*expr.DateBinFunc), &expr.CountFunc{Wildcard: true})).
+				Pipe(stream.Project(testutil.ParseNamedExpr(t, "COUNT(*)"))),
 			false,
 		},
 		{"WithOrderBy", "SELECT * FROM test WHERE age = 10 ORDER BY a.b.c",
@@ -146,6 +181,33 @@ func TestParserSelect(t *testing.T) {
 				Pipe(stream.HashAggregate(&expr.CountFunc{Wildcard: true})).
 				Pipe(stream.Project(testutil.ParseNamedExpr(t, "COUNT(*)"))),
 			false},
+		{"WithJoin", "SELECT a.x, b.y FROM a JOIN b ON a.x = b.y WHERE a.z > 1",
+			stream.New(stream.Join(
+				stream.New(stream.SeqScan("a")),
+				stream.New(stream.SeqScan("b")),
+				"a", "b", parser.MustParseExpr("a.x = b.y"),
+			)).
+				Pipe(stream.Filter(parser.MustParseExpr("a.z > 1"))).
+				Pipe(stream.Project(testutil.ParseNamedExpr(t, "a.x"), testutil.ParseNamedExpr(t, "b.y"))),
+			false,
+		},
+		{"WithJoin / inner", "SELECT * FROM a inner join b ON a.x = b.y JOIN c ON c.z = b.z",
+			stream.New(stream.Join(
+				stream.New(stream.Join(
+					stream.New(stream.SeqScan("a")),
+					stream.New(stream.SeqScan("b")),
+					"a", "b", parser.MustParseExpr("a.x = b.y"),
+				)),
+				stream.New(stream.SeqScan("c")),
+				"", "c", parser.MustParseExpr("c.z = b.z"),
+			)).
+				Pipe(stream.Project(expr.Wildcard{})),
+			false,
+		},
+		{"WithJoin / missing ON", "SELECT * FROM a JOIN b", nil, true},
+		{"WithJoin / missing JOIN", "SELECT * FROM a INNER b ON a.x = b.y", nil, true},
+		{"WithJoin / same table", "SELECT * FROM a JOIN a ON a.x = a.y", nil, true},
+		{"WithJoin / system time", "SELECT * FROM a FOR SYSTEM_TIME AS OF '2021-01-01' JOIN b ON a.x = b.y", nil, true},
 		{"WithUnionAll", "SELECT * FROM test1 UNION ALL SELECT * FROM test2",
 			stream.New(stream.Concat(
 				stream.New(stream.SeqScan("test1")).Pipe(stream.Project(expr.Wildcard{})),
@@ -0,0 +1,44 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/query"
+	"github.com/genjidb/genji/internal/query/statement"
+	"github.com/genjidb/genji/internal/sql/scanner"
+)
+
+// parseSetStatement parses a SET statement.
+// This function assumes the SET token has already been consumed.
+func (p *Parser) parseSetStatement() (statement.Statement, error) {
+	var stmt query.SetStmt
+	var err error
+
+	stmt.Name, err = p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Name = strings.ToLower(stmt.Name)
+
+	if err = p.parseTokens(scanner.EQ); err != nil {
+		return nil, err
+	}
+
+	if stmt.Name == database.SynchronousSetting {
+		stmt.Text, err = p.parseIdent()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Text = strings.ToLower(stmt.Text)
+
+		return stmt, nil
+	}
+
+	stmt.Value, err = p.parseInteger()
+	if err != nil {
+		return nil, err
+	}
+
+	return stmt, nil
+}
@@ -0,0 +1,41 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/internal/query"
+	"github.com/genjidb/genji/internal/query/statement"
+	"github.com/genjidb/genji/internal/sql/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserSet(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected statement.Statement
+		errored  bool
+	}{
+		{"SET max_scanned_documents = 10", query.SetStmt{Name: "max_scanned_documents", Value: 10}, false},
+		{"SET MAX_MEMORY = 1024", query.SetStmt{Name: "max_memory", Value: 1024}, false},
+		{"SET max_memory = 0", query.SetStmt{Name: "max_memory", Value: 0}, false},
+		{"SET max_memory", nil, true},
+		{"SET max_memory = 'a'", nil, true},
+		{"SET = 10", nil, true},
+		{"SET synchronous = off", query.SetStmt{Name: "synchronous", Text: "off"}, false},
+		{"SET SYNCHRONOUS = FULL", query.SetStmt{Name: "synchronous", Text: "full"}, false},
+		{"SET synchronous = 1", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			q, err := parser.ParseQuery(test.s)
+			if test.errored {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, q.Statements, 1)
+			require.EqualValues(t, test.expected, q.Statements[0])
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/genjidb/genji/internal/query/statement"
+	"github.com/genjidb/genji/internal/sql/scanner"
+)
+
+// parseShowStatement parses a SHOW statement.
+// This function assumes the SHOW identifier has already been consumed.
+func (p *Parser) parseShowStatement() (statement.Statement, error) {
+	// SEQUENCE is a keyword, SEQUENCES is not.
+	tok, pos, lit := p.ScanIgnoreWhitespace()
+	switch {
+	case tok == scanner.IDENT && strings.EqualFold(lit, "SEQUENCES"):
+		return statement.ShowSequencesStmt{}, nil
+	case tok == scanner.SEQUENCE:
+		name, err := p.parseIdent()
+		if err != nil {
+			return nil, err
+		}
+		return statement.ShowSequencesStmt{Name: name}, nil
+	case tok == scanner.TRANSACTION:
+		// STATUS is not a keyword either.
+		tok, pos, lit = p.ScanIgnoreWhitespace()
+		if tok != scanner.IDENT || !strings.EqualFold(lit, "STATUS") {
+			return nil, newParseError(scanner.Tokstr(tok, lit), []string{"STATUS"}, pos)
+		}
+		return statement.ShowTransactionStatusStmt{}, nil
+	}
+
+	return nil, newParseError(scanner.Tokstr(tok, lit), []string{"SEQUENCE", "SEQUENCES", "TRANSACTION"}, pos)
+}
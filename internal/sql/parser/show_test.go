@@ -0,0 +1,41 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/internal/query/statement"
+	"github.com/genjidb/genji/internal/sql/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserShow(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected statement.Statement
+		errored  bool
+	}{
+		{"SHOW SEQUENCES", statement.ShowSequencesStmt{}, false},
+		{"show sequences", statement.ShowSequencesStmt{}, false},
+		{"SHOW SEQUENCE foo", statement.ShowSequencesStmt{Name: "foo"}, false},
+		{"SHOW SEQUENCE", nil, true},
+		{"SHOW TRANSACTION STATUS", statement.ShowTransactionStatusStmt{}, false},
+		{"show transaction status", statement.ShowTransactionStatusStmt{}, false},
+		{"SHOW TRANSACTION", nil, true},
+		{"SHOW TRANSACTION foo", nil, true},
+		{"SHOW TABLES", nil, true},
+		{"SHOW", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			q, err := parser.ParseQuery(test.s)
+			if test.errored {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, q.Statements, 1)
+			require.EqualValues(t, test.expected, q.Statements[0])
+		})
+	}
+}
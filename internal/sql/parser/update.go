@@ -39,6 +39,11 @@ func (p *Parser) parseUpdateStatement() (*statement.StreamStmt, error) {
 		return nil, err
 	}
 
+	stmt.Returning, err = p.parseReturning()
+	if err != nil {
+		return nil, err
+	}
+
 	return stmt.ToStream(), nil
 }
 
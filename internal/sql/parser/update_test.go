@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/expr"
 	"github.com/genjidb/genji/internal/query/statement"
 	"github.com/genjidb/genji/internal/sql/parser"
 	"github.com/genjidb/genji/internal/stream"
@@ -58,6 +59,22 @@ func TestParserUpdate(t *testing.T) {
 				Pipe(stream.TableReplace("test")),
 			false,
 		},
+		{"SET/With cond/Returning", "UPDATE test SET a = 1 WHERE age = 10 RETURNING *, a AS b",
+			stream.New(stream.SeqScan("test")).
+				Pipe(stream.Filter(parser.MustParseExpr("age = 10"))).
+				Pipe(stream.Set(document.Path(testutil.ParsePath(t, "a")), testutil.IntegerValue(1))).
+				Pipe(stream.TableReplace("test")).
+				Pipe(stream.Project(expr.Wildcard{}, testutil.ParseNamedExpr(t, "a", "b"))),
+			false,
+		},
+		{"UNSET/Returning", "UPDATE test UNSET a RETURNING pk()",
+			stream.New(stream.SeqScan("test")).
+				Pipe(stream.Unset("a")).
+				Pipe(stream.TableReplace("test")).
+				Pipe(stream.Project(testutil.ParseNamedExpr(t, "pk()"))),
+			false,
+		},
+		{"Returning without expression", "UPDATE test SET a = 1 RETURNING", nil, true},
 		{"Trailing comma", "UPDATE test SET a = 1, WHERE age = 10", nil, true},
 		{"No SET", "UPDATE test WHERE age = 10", nil, true},
 		{"No pair", "UPDATE test SET WHERE age = 10", nil, true},
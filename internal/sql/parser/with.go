@@ -0,0 +1,134 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/genjidb/genji/internal/query/statement"
+	"github.com/genjidb/genji/internal/sql/scanner"
+	"github.com/genjidb/genji/internal/stream"
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// parseWithStatement parses a select statement preceded by a WITH clause and returns a Statement AST object.
+// This function assumes the WITH token has already been consumed.
+func (p *Parser) parseWithStatement() (*statement.StreamStmt, error) {
+	// RECURSIVE is not a keyword, to allow using it as an identifier.
+	var recursive bool
+	if tok, _, lit := p.ScanIgnoreWhitespace(); tok == scanner.IDENT && strings.EqualFold(lit, "RECURSIVE") {
+		recursive = true
+	} else {
+		p.Unscan()
+	}
+
+	// the expressions are only visible to the statement following the clause
+	defer func() { p.ctes = nil }()
+
+	ctes := make(map[string]*stream.CommonTableExpr)
+	readOnly := true
+	for {
+		cte, ro, err := p.parseCommonTableExpr(recursive)
+		if err != nil {
+			return nil, err
+		}
+		readOnly = readOnly && ro
+
+		if _, ok := ctes[cte.Name]; ok {
+			return nil, stringutil.Errorf("WITH query name %q specified more than once", cte.Name)
+		}
+		ctes[cte.Name] = cte
+		// following expressions can refer to the previous ones
+		p.ctes = ctes
+
+		if tok, _, _ := p.ScanIgnoreWhitespace(); tok != scanner.COMMA {
+			p.Unscan()
+			break
+		}
+	}
+
+	if err := p.parseTokens(scanner.SELECT); err != nil {
+		return nil, err
+	}
+
+	stmt, err := p.parseSelectStatement()
+	if err != nil {
+		return nil, err
+	}
+	stmt.ReadOnly = stmt.ReadOnly && readOnly
+
+	return stmt, nil
+}
+
+// parseCommonTableExpr parses one expression of a WITH clause:
+//   name [(field, ...)] AS (SELECT ... [UNION [ALL] SELECT ...])
+// If recursive is true, the select statement following UNION is the recursive part
+// of the expression, and can refer to the documents of the previous iteration by name.
+// It also reports whether the statements of the expression are read-only.
+func (p *Parser) parseCommonTableExpr(recursive bool) (*stream.CommonTableExpr, bool, error) {
+	var cte stream.CommonTableExpr
+	var err error
+
+	cte.Name, err = p.parseIdent()
+	if err != nil {
+		pErr := err.(*ParseError)
+		pErr.Expected = []string{"name"}
+		return nil, false, pErr
+	}
+
+	// Parse optional list of field names
+	if ok, err := p.parseOptional(scanner.LPAREN); err != nil {
+		return nil, false, err
+	} else if ok {
+		cte.Fields, err = p.parseIdentList()
+		if err != nil {
+			return nil, false, err
+		}
+		if err := p.parseTokens(scanner.RPAREN); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if err := p.parseTokens(scanner.AS, scanner.LPAREN, scanner.SELECT); err != nil {
+		return nil, false, err
+	}
+
+	// the anchor of a recursive expression stops before UNION
+	p.noUnion = recursive
+	stmt, err := p.parseSelectStatement()
+	p.noUnion = false
+	if err != nil {
+		return nil, false, err
+	}
+	cte.Stream = stmt.Stream
+	readOnly := stmt.ReadOnly
+
+	if recursive {
+		ok, err := p.parseOptional(scanner.UNION)
+		if err != nil {
+			return nil, false, err
+		}
+		if ok {
+			cte.All, err = p.parseOptional(scanner.ALL)
+			if err != nil {
+				return nil, false, err
+			}
+			if err := p.parseTokens(scanner.SELECT); err != nil {
+				return nil, false, err
+			}
+
+			p.workingTable = cte.Name
+			stmt, err = p.parseSelectStatement()
+			p.workingTable = ""
+			if err != nil {
+				return nil, false, err
+			}
+			cte.Recursive = stmt.Stream
+			readOnly = readOnly && stmt.ReadOnly
+		}
+	}
+
+	if err := p.parseTokens(scanner.RPAREN); err != nil {
+		return nil, false, err
+	}
+
+	return &cte, readOnly, nil
+}
@@ -0,0 +1,83 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/internal/expr"
+	"github.com/genjidb/genji/internal/query/statement"
+	"github.com/genjidb/genji/internal/sql/parser"
+	"github.com/genjidb/genji/internal/stream"
+	"github.com/genjidb/genji/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserWith(t *testing.T) {
+	top := &stream.CommonTableExpr{
+		Name:   "top",
+		Stream: stream.New(stream.SeqScan("a")).Pipe(stream.Project(expr.Wildcard{})),
+	}
+	tree := &stream.CommonTableExpr{
+		Name:   "tree",
+		Fields: []string{"id"},
+		Stream: stream.New(stream.SeqScan("a")).
+			Pipe(stream.Filter(parser.MustParseExpr("parent IS NULL"))).
+			Pipe(stream.Project(testutil.ParseNamedExpr(t, "id"))),
+		Recursive: stream.New(stream.Join(
+			stream.New(stream.SeqScan("a")),
+			stream.New(stream.WorkingTableScan("tree")),
+			"a", "tree", parser.MustParseExpr("a.parent = tree.id"),
+		)).Pipe(stream.Project(testutil.ParseNamedExpr(t, "a.id"))),
+		All: true,
+	}
+
+	tests := []struct {
+		name     string
+		s        string
+		expected *stream.Stream
+		mustFail bool
+	}{
+		{"Simple", "WITH top AS (SELECT * FROM a) SELECT * FROM top",
+			stream.New(stream.CTEScan(top)).Pipe(stream.Project(expr.Wildcard{})),
+			false,
+		},
+		{"Join", "WITH top AS (SELECT * FROM a) SELECT * FROM b JOIN top ON b.x = top.x",
+			stream.New(stream.Join(
+				stream.New(stream.SeqScan("b")),
+				stream.New(stream.CTEScan(top)),
+				"b", "top", parser.MustParseExpr("b.x = top.x"),
+			)).Pipe(stream.Project(expr.Wildcard{})),
+			false,
+		},
+		{"Recursive", "WITH RECURSIVE tree(id) AS (SELECT id FROM a WHERE parent IS NULL UNION ALL SELECT a.id FROM a JOIN tree ON a.parent = tree.id) SELECT * FROM tree",
+			stream.New(stream.CTEScan(tree)).Pipe(stream.Project(expr.Wildcard{})),
+			false,
+		},
+		{"Recursive without recursive part", "WITH RECURSIVE top AS (SELECT * FROM a) SELECT * FROM top",
+			stream.New(stream.CTEScan(top)).Pipe(stream.Project(expr.Wildcard{})),
+			false,
+		},
+		{"Same name", "WITH top AS (SELECT * FROM a), top AS (SELECT * FROM a) SELECT * FROM top", nil, true},
+		{"Missing AS", "WITH top (SELECT * FROM a) SELECT * FROM top", nil, true},
+		{"Missing SELECT", "WITH top AS (SELECT * FROM a)", nil, true},
+		{"System time", "WITH top AS (SELECT * FROM a) SELECT * FROM top FOR SYSTEM_TIME AS OF '2021-01-01'", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := parser.ParseQuery(test.s)
+			if !test.mustFail {
+				require.NoError(t, err)
+				require.Len(t, q.Statements, 1)
+				require.EqualValues(t, &statement.StreamStmt{Stream: test.expected, ReadOnly: true}, q.Statements[0].(*statement.StreamStmt))
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+
+	// the expressions are only visible to the statement following the WITH clause
+	q, err := parser.ParseQuery("WITH top AS (SELECT * FROM a) SELECT * FROM top; SELECT * FROM top")
+	require.NoError(t, err)
+	require.Len(t, q.Statements, 2)
+	require.EqualValues(t, &statement.StreamStmt{Stream: stream.New(stream.SeqScan("top")).Pipe(stream.Project(expr.Wildcard{})), ReadOnly: true}, q.Statements[1])
+}
@@ -0,0 +1,111 @@
+package scanner
+
+import (
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// normalizedToken is a token of a normalized query, along with its text.
+type normalizedToken struct {
+	tok  Token
+	text string
+}
+
+// Normalize returns a canonical form of the given query, shared by the queries
+// only differing by their literal values, parameters, comments, spacing or
+// the case of their keywords:
+//   - literal values, including signed numbers, and parameters are replaced by ?
+//   - comments are removed and tokens are separated by a single space, if any
+//   - keywords and operators are uppercased
+//   - the trailing semicolon is removed
+// NULL is kept, as it changes the meaning of the expressions using it.
+// It returns an error if the query contains an invalid token.
+func Normalize(q string) (string, error) {
+	var toks []normalizedToken
+
+	s := NewScanner(strings.NewReader(q))
+	for {
+		tok, pos, lit := s.Scan()
+
+		t := normalizedToken{tok: tok}
+		switch tok {
+		case EOF:
+			if n := len(toks); n > 0 && toks[n-1].tok == SEMICOLON {
+				toks = toks[:n-1]
+			}
+			return joinTokens(toks), nil
+		case WS, COMMENT:
+			continue
+		case ILLEGAL, BADSTRING, BADESCAPE, BADREGEX:
+			return "", stringutil.Errorf("invalid token %q at line %d, char %d", Tokstr(tok, lit), pos.Line+1, pos.Char+1)
+		case NUMBER, INTEGER:
+			// the sign of a number is part of the literal, unless it follows an operand
+			if n := len(toks); n > 0 && (toks[n-1].tok == SUB || toks[n-1].tok == ADD) && (n == 1 || !endsOperand(toks[n-2].tok)) {
+				toks = toks[:n-1]
+			}
+			t.tok, t.text = POSITIONALPARAM, "?"
+		case STRING, BLOB, REGEX, TRUE, FALSE, NAMEDPARAM, POSITIONALPARAM:
+			t.tok, t.text = POSITIONALPARAM, "?"
+		case IDENT:
+			t.text = stringutil.NormalizeIdentifier(lit, '`')
+		default:
+			t.text = Tokstr(tok, lit)
+			if tok.IsOperator() || (tok > keywordBeg && tok < keywordEnd) {
+				t.text = tok.String()
+			}
+		}
+
+		toks = append(toks, t)
+	}
+}
+
+// Fingerprint returns a short hash of the normalized form of q,
+// as returned by Normalize, which identifies the queries sharing that form.
+func Fingerprint(q string) (string, error) {
+	n, err := Normalize(q)
+	if err != nil {
+		return "", err
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(n))
+	return strconv.FormatUint(h.Sum64(), 16), nil
+}
+
+// endsOperand reports whether tok can end an operand,
+// in which case a following sign is a binary operator.
+func endsOperand(tok Token) bool {
+	switch tok {
+	case IDENT, POSITIONALPARAM, NULL, RPAREN, RSBRACKET, RBRACKET:
+		return true
+	}
+
+	return false
+}
+
+// joinTokens separates the tokens with a single space, except after opening
+// brackets, before closing brackets, commas and colons, around dots,
+// between a function name and its arguments and before array indexes.
+func joinTokens(toks []normalizedToken) string {
+	var sb strings.Builder
+
+	for i, t := range toks {
+		if i > 0 {
+			switch prev := toks[i-1].tok; {
+			case prev == LPAREN || prev == LSBRACKET || prev == LBRACKET || prev == DOT || prev == DOUBLECOLON:
+			case t.tok == RPAREN || t.tok == RSBRACKET || t.tok == RBRACKET || t.tok == COMMA || t.tok == DOT ||
+				t.tok == COLON || t.tok == DOUBLECOLON || t.tok == SEMICOLON:
+			case t.tok == LPAREN && prev == IDENT:
+			case t.tok == LSBRACKET && (prev == IDENT || prev == RSBRACKET):
+			default:
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteString(t.text)
+	}
+
+	return sb.String()
+}
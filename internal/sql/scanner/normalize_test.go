@@ -0,0 +1,72 @@
+package scanner
+
+import (
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	var tests = []struct {
+		s        string
+		expected string
+		errored  bool
+	}{
+		{s: ``, expected: ``},
+		{s: `SELECT * FROM foo`, expected: `SELECT * FROM foo`},
+		{s: `select * from foo where a = 1 and b = 'x';`, expected: `SELECT * FROM foo WHERE a = ? AND b = ?`},
+		{s: "SELECT  *\nFROM foo -- comment\n WHERE a=1.5 AND b = /* c */ $b", expected: `SELECT * FROM foo WHERE a = ? AND b = ?`},
+		{s: `SELECT * FROM foo WHERE a = ? AND b = true AND c IS NOT NULL`, expected: `SELECT * FROM foo WHERE a = ? AND b = ? AND c IS NOT NULL`},
+		{s: `SELECT a - 1, -2, +3, (-4), a * -5 FROM foo`, expected: `SELECT a - ?, ?, ?, (?), a * ? FROM foo`},
+		{s: `SELECT COUNT(*), lower(a) FROM foo GROUP BY b`, expected: `SELECT COUNT(*), lower(a) FROM foo GROUP BY b`},
+		{s: "SELECT `my t`.a[0] FROM `my t`", expected: "SELECT `my t`.a[?] FROM `my t`"},
+		{s: `SELECT a FROM foo WHERE a IN (1, 2) AND b::TEXT = 'x'`, expected: `SELECT a FROM foo WHERE a IN (?, ?) AND b::TEXT = ?`},
+		{s: `INSERT INTO foo (a, b) VALUES (1, X'ABCD'), {a: 1, b: [1, 2]}`, expected: `INSERT INTO foo(a, b) VALUES (?, ?), {a: ?, b: [?, ?]}`},
+		{s: `SELECT 1; SELECT 2;`, expected: `SELECT ?; SELECT ?`},
+		{s: `SELECT 'unterminated`, errored: true},
+		{s: `SELECT # FROM foo`, errored: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			n, err := Normalize(tt.s)
+			if tt.errored {
+				if err == nil {
+					t.Fatalf("expected error, got %q", n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != tt.expected {
+				t.Fatalf("expected %q, got %q", tt.expected, n)
+			}
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	f1, err := Fingerprint(`select * from foo where a = 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f2, err := Fingerprint("SELECT *\n  FROM foo\n  WHERE a = $a;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f3, err := Fingerprint(`SELECT * FROM foo WHERE b = 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f1 != f2 {
+		t.Fatalf("expected the same fingerprints, got %q and %q", f1, f2)
+	}
+	if f1 == f3 {
+		t.Fatalf("expected different fingerprints, got %q", f1)
+	}
+
+	_, err = Fingerprint(`SELECT 'unterminated`)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -45,6 +45,18 @@ func (s *scanner) Scan() (tok Token, pos Pos, lit string) {
 	// as an ident or reserved word.
 	if isWhitespace(ch0) {
 		return s.scanWhitespace()
+	} else if ch0 == 'x' || ch0 == 'X' {
+		// X'DEADBEEF' is a blob literal
+		if ch1, _ := s.r.read(); ch1 == '\'' {
+			tok, _, lit := s.scanString()
+			if tok == STRING {
+				tok = BLOB
+			}
+			return tok, pos, lit
+		}
+		s.r.unread()
+		s.r.unread()
+		return s.scanIdent(true)
 	} else if isLetter(ch0) || ch0 == '_' {
 		s.r.unread()
 		return s.scanIdent(true)
@@ -87,6 +99,9 @@ func (s *scanner) Scan() (tok Token, pos Pos, lit string) {
 			s.skipUntilNewline()
 			return COMMENT, pos, ""
 		}
+		if ch1 == '>' {
+			return ARROW, pos, ""
+		}
 		if isDigit(ch1) {
 			s.r.unread()
 			return s.scanNumber()
@@ -68,6 +68,7 @@ func TestScanner_Scan(t *testing.T) {
 		{s: `!~`, tok: NEQREGEX},
 		{s: `:`, tok: COLON},
 		{s: `::`, tok: DOUBLECOLON},
+		{s: `->`, tok: ARROW},
 		{s: `--`, tok: COMMENT},
 		{s: `--10.3`, tok: COMMENT, lit: ``},
 
@@ -107,6 +108,13 @@ func TestScanner_Scan(t *testing.T) {
 		{s: "\"test\nfoo", tok: BADSTRING, lit: `test`},
 		{s: `"test\g"`, tok: BADESCAPE, lit: `\g`, pos: Pos{Line: 0, Char: 6}},
 
+		// Blobs
+		{s: `X'DEADBEEF'`, tok: BLOB, lit: `DEADBEEF`},
+		{s: `x'00'`, tok: BLOB, lit: `00`},
+		{s: `X'00`, tok: BADSTRING, lit: `00`},
+		{s: `x`, tok: IDENT, lit: `x`},
+		{s: `xyz`, tok: IDENT, lit: `xyz`},
+
 		// Numbers
 		{s: `100`, tok: INTEGER, lit: `100`},
 		{s: `100.23`, tok: NUMBER, lit: `100.23`},
@@ -117,6 +125,7 @@ func TestScanner_Scan(t *testing.T) {
 		// Keywords
 		{s: `ADD`, tok: ADD_KEYWORD},
 		{s: `ALTER`, tok: ALTER},
+		{s: `ANALYZE`, tok: ANALYZE},
 		{s: `AS`, tok: AS},
 		{s: `ASC`, tok: ASC},
 		{s: `ALL`, tok: ALL},
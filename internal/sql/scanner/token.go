@@ -29,6 +29,7 @@ const (
 	NULL            // NULL
 	REGEX           // Regular expressions
 	BADREGEX        // `.*
+	BLOB            // X'DEADBEEF'
 	literalEnd
 
 	operatorBeg
@@ -73,12 +74,14 @@ const (
 	DOUBLECOLON // ::
 	SEMICOLON   // ;
 	DOT         // .
+	ARROW       // ->
 
 	keywordBeg
 	// ALL and the following are Genji SQL Keywords
 	ADD_KEYWORD
 	ALL
 	ALTER
+	ANALYZE
 	AS
 	ASC
 	BEGIN
@@ -182,6 +185,7 @@ var tokens = [...]string{
 	FALSE:           "FALSE",
 	REGEX:           "REGEX",
 	NULL:            "NULL",
+	BLOB:            "BLOB",
 
 	ADD:        "+",
 	SUB:        "-",
@@ -219,10 +223,12 @@ var tokens = [...]string{
 	DOUBLECOLON: "::",
 	SEMICOLON:   ";",
 	DOT:         ".",
+	ARROW:       "->",
 
 	ADD_KEYWORD: "ADD",
 	ALL:         "ALL",
 	ALTER:       "ALTER",
+	ANALYZE:     "ANALYZE",
 	AS:          "AS",
 	ASC:         "ASC",
 	BEGIN:       "BEGIN",
@@ -10,10 +10,16 @@ import (
 	"github.com/genjidb/genji/internal/stringutil"
 )
 
+// groupAggregatorSize is the estimated memory used by
+// an aggregator of a group, in bytes.
+const groupAggregatorSize = 64
+
 // A HashAggregateOperator consumes the given stream and outputs one value per group.
 // It reads the _group variable from the environment to determine witch group
 // to assign each value. If no _group variable is available, it will assume all
 // values are part of the same group and aggregate them into one value.
+// The state of the aggregators cannot be spilled to a temporary store,
+// so the operator fails if the groups exceed the memory budget.
 type HashAggregateOperator struct {
 	baseOperator
 	Builders []expr.AggregatorBuilder
@@ -41,8 +47,23 @@ func (op *HashAggregateOperator) Iterate(in *environment.Environment, f func(out
 	// store a groupAggregator per group
 	aggregators := make(map[string]*groupAggregator)
 
+	// account for the memory used by the groups
+	tracker := in.GetResourceTracker()
+	var size int64
+	defer func() { tracker.Shrink(size) }()
+
+	mask, err := newFieldMasker(in, op.Prev)
+	if err != nil {
+		return err
+	}
+
 	// iterate over s and for each group, aggregate the incoming document
 	err = op.Prev.Iterate(in, func(out *environment.Environment) error {
+		out, err := maskedEnv(mask, out)
+		if err != nil {
+			return err
+		}
+
 		// we extract the group name from the environment and encode it
 		// to be used as a key to the aggregators map.
 		groupName, err := encGroup(out)
@@ -53,6 +74,12 @@ func (op *HashAggregateOperator) Iterate(in *environment.Environment, f func(out
 		// get the group aggregator from the map or create a new one.
 		a, ok := aggregators[groupName]
 		if !ok {
+			n := int64(len(groupName) + groupAggregatorSize*len(op.Builders))
+			size += n
+			if err := tracker.Grow(n); err != nil {
+				return err
+			}
+
 			a = newGroupAggregator(out, op.Builders)
 			aggregators[groupName] = a
 			encGroupNames = append(encGroupNames, groupName)
@@ -0,0 +1,226 @@
+package stream
+
+import (
+	"strings"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/environment"
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// A CommonTableExpr is a named query defined by a WITH clause, which can be
+// read like a table by the statement following the clause.
+//
+// A recursive common table expression is evaluated by running Stream once,
+// then by running Recursive repeatedly until it doesn't return any new document.
+// Each run of Recursive reads the documents returned by the previous one
+// from the working table of the expression, using a WorkingTableScan operator.
+type CommonTableExpr struct {
+	Name string
+	// Names given to the fields of the returned documents, in order.
+	// If empty, the fields are left untouched.
+	Fields    []string
+	Stream    *Stream
+	Recursive *Stream
+	// If true, the documents of each run of Recursive are combined with UNION ALL,
+	// otherwise documents that were already returned are discarded.
+	All bool
+}
+
+// document copies d and renames its fields, if needed.
+func (c *CommonTableExpr) document(d document.Document) (*document.FieldBuffer, error) {
+	fb := document.NewFieldBuffer()
+	if len(c.Fields) == 0 {
+		return fb, fb.Copy(d)
+	}
+
+	var renamed document.FieldBuffer
+	var n int
+	err := d.Iterate(func(field string, v document.Value) error {
+		if n < len(c.Fields) {
+			renamed.Add(c.Fields[n], v)
+		}
+		n++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if n != len(c.Fields) {
+		return nil, stringutil.Errorf("%q defines %d fields, got a document with %d fields", c.Name, len(c.Fields), n)
+	}
+
+	return fb, fb.Copy(&renamed)
+}
+
+func (c *CommonTableExpr) String() string {
+	var sb strings.Builder
+
+	sb.WriteString(c.Name)
+	if len(c.Fields) > 0 {
+		stringutil.Fprintf(&sb, "(%s)", strings.Join(c.Fields, ", "))
+	}
+	stringutil.Fprintf(&sb, " AS (%s", c.Stream)
+	if c.Recursive != nil {
+		sb.WriteString(" UNION ")
+		if c.All {
+			sb.WriteString("ALL ")
+		}
+		sb.WriteString(c.Recursive.String())
+	}
+	sb.WriteString(")")
+
+	return sb.String()
+}
+
+// A CTEScanOperator returns the documents of a common table expression.
+type CTEScanOperator struct {
+	baseOperator
+	CTE *CommonTableExpr
+}
+
+// CTEScan creates an operator that evaluates the common table expression
+// and returns its documents.
+func CTEScan(cte *CommonTableExpr) *CTEScanOperator {
+	return &CTEScanOperator{CTE: cte}
+}
+
+// Iterate evaluates the common table expression and calls fn for every document it returns.
+// The documents of a recursive expression are returned as soon as each run produces them.
+// A recursive expression using UNION ALL returns an error if a run of its recursive part
+// returns a document that a previous run already returned, as it would never end.
+func (op *CTEScanOperator) Iterate(in *environment.Environment, fn func(out *environment.Environment) error) error {
+	var newEnv environment.Environment
+	newEnv.SetOuter(in)
+
+	cte := op.CTE
+	if cte.Recursive == nil {
+		return cte.Stream.Iterate(in, func(out *environment.Environment) error {
+			d, ok := out.GetDocument()
+			if !ok {
+				return nil
+			}
+
+			fb, err := cte.document(d)
+			if err != nil {
+				return err
+			}
+
+			newEnv.SetDocument(fb)
+			return fn(&newEnv)
+		})
+	}
+
+	// hashes of the documents returned by the previous runs
+	seen := make(map[string]struct{})
+	var working []document.Document
+
+	run := func(s *Stream, env *environment.Environment) error {
+		var docs []document.Document
+		var hashes []string
+
+		err := s.Iterate(env, func(out *environment.Environment) error {
+			d, ok := out.GetDocument()
+			if !ok {
+				return nil
+			}
+
+			fb, err := cte.document(d)
+			if err != nil {
+				return err
+			}
+
+			h, err := document.Hash(fb)
+			if err != nil {
+				return err
+			}
+
+			if _, ok := seen[string(h)]; ok {
+				if cte.All && s == cte.Recursive {
+					return stringutil.Errorf("recursive query %q contains a cycle: it returned %s more than once, use UNION instead of UNION ALL to ignore duplicates", cte.Name, fb)
+				}
+
+				return nil
+			}
+
+			if cte.All {
+				hashes = append(hashes, string(h))
+			} else {
+				seen[string(h)] = struct{}{}
+			}
+			docs = append(docs, fb)
+
+			newEnv.SetDocument(fb)
+			return fn(&newEnv)
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, h := range hashes {
+			seen[h] = struct{}{}
+		}
+		working = docs
+		return nil
+	}
+
+	err := run(cte.Stream, in)
+	if err != nil {
+		return err
+	}
+
+	for len(working) > 0 {
+		var wenv environment.Environment
+		wenv.SetOuter(in)
+		wenv.SetWorkingTable(cte.Name, working)
+
+		err = run(cte.Recursive, &wenv)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (op *CTEScanOperator) String() string {
+	return stringutil.Sprintf("cteScan(%s)", op.CTE)
+}
+
+// A WorkingTableScanOperator returns the documents returned by the previous run
+// of a recursive common table expression.
+type WorkingTableScanOperator struct {
+	baseOperator
+	Name string
+}
+
+// WorkingTableScan creates an operator that returns the documents of the working table
+// of the recursive common table expression with the given name.
+func WorkingTableScan(name string) *WorkingTableScanOperator {
+	return &WorkingTableScanOperator{Name: name}
+}
+
+// Iterate calls fn for every document of the working table.
+func (op *WorkingTableScanOperator) Iterate(in *environment.Environment, fn func(out *environment.Environment) error) error {
+	docs, ok := in.GetWorkingTable(op.Name)
+	if !ok {
+		return stringutil.Errorf("working table %q not found", op.Name)
+	}
+
+	var newEnv environment.Environment
+	newEnv.SetOuter(in)
+
+	for _, d := range docs {
+		newEnv.SetDocument(d)
+		err := fn(&newEnv)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (op *WorkingTableScanOperator) String() string {
+	return stringutil.Sprintf("workingTableScan(%s)", op.Name)
+}
@@ -0,0 +1,86 @@
+package stream_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/environment"
+	"github.com/genjidb/genji/internal/sql/parser"
+	"github.com/genjidb/genji/internal/stream"
+	"github.com/genjidb/genji/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCTEScanOperator(t *testing.T) {
+	tests := []struct {
+		name     string
+		cte      stream.CommonTableExpr
+		expected []string
+		fails    bool
+	}{
+		{"Not recursive", stream.CommonTableExpr{
+			Name:   "c",
+			Fields: []string{"b"},
+			Stream: stream.New(stream.Documents(testutil.MakeDocuments(t, `{"a": 1}`, `{"a": 2}`)...)),
+		}, []string{`{"b": 1}`, `{"b": 2}`}, false},
+		{"Wrong number of fields", stream.CommonTableExpr{
+			Name:   "c",
+			Fields: []string{"a", "b"},
+			Stream: stream.New(stream.Documents(testutil.MakeDocuments(t, `{"a": 1}`)...)),
+		}, nil, true},
+		{"Recursive", stream.CommonTableExpr{
+			Name:   "c",
+			Stream: stream.New(stream.Documents(testutil.MakeDocuments(t, `{"a": 1}`)...)),
+			Recursive: stream.New(stream.WorkingTableScan("c")).
+				Pipe(stream.Filter(parser.MustParseExpr("a < 3"))).
+				Pipe(stream.Project(testutil.ParseNamedExpr(t, "a + 1", "a"))),
+			All: true,
+		}, []string{`{"a": 1}`, `{"a": 2}`, `{"a": 3}`}, false},
+		{"Recursive with duplicates", stream.CommonTableExpr{
+			Name:   "c",
+			Stream: stream.New(stream.Documents(testutil.MakeDocuments(t, `{"a": 1}`)...)),
+			Recursive: stream.New(stream.WorkingTableScan("c")).
+				Pipe(stream.Project(testutil.ParseNamedExpr(t, "(a + 1) % 3", "a"))),
+		}, []string{`{"a": 1}`, `{"a": 2}`, `{"a": 0}`}, false},
+		{"Recursive with cycle", stream.CommonTableExpr{
+			Name:   "c",
+			Stream: stream.New(stream.Documents(testutil.MakeDocuments(t, `{"a": 1}`)...)),
+			Recursive: stream.New(stream.WorkingTableScan("c")).
+				Pipe(stream.Project(testutil.ParseNamedExpr(t, "(a + 1) % 3", "a"))),
+			All: true,
+		}, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []document.Document
+			err := stream.New(stream.CTEScan(&test.cte)).Iterate(new(environment.Environment), func(out *environment.Environment) error {
+				d, ok := out.GetDocument()
+				require.True(t, ok)
+
+				fb := document.NewFieldBuffer()
+				err := fb.Copy(d)
+				require.NoError(t, err)
+				got = append(got, fb)
+				return nil
+			})
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			require.Len(t, got, len(test.expected))
+			for i, e := range test.expected {
+				testutil.RequireDocJSONEq(t, got[i], e)
+			}
+		})
+	}
+
+	t.Run("Missing working table", func(t *testing.T) {
+		err := stream.New(stream.WorkingTableScan("c")).Iterate(new(environment.Environment), func(out *environment.Environment) error {
+			return nil
+		})
+		require.Error(t, err)
+	})
+}
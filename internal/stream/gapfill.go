@@ -0,0 +1,164 @@
+package stream
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/environment"
+	"github.com/genjidb/genji/internal/expr"
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// A GapFillOperator consumes the groups output by a HashAggregateOperator grouping
+// documents by time buckets, and outputs them ordered by bucket, adding an empty group
+// for every bucket missing between the first and the last one.
+// The aggregators of empty groups return their initial value, e.g. 0 for COUNT(*).
+// Groups whose bucket is NULL are output first.
+type GapFillOperator struct {
+	baseOperator
+	Bucket   *expr.DateBinFunc
+	Builders []expr.AggregatorBuilder
+}
+
+// GapFill creates a GapFillOperator filling the gaps between the buckets computed by bucket.
+// builders must be the aggregators of the preceding HashAggregateOperator.
+func GapFill(bucket *expr.DateBinFunc, builders ...expr.AggregatorBuilder) *GapFillOperator {
+	return &GapFillOperator{Bucket: bucket, Builders: builders}
+}
+
+type bucketGroup struct {
+	bucket document.Value
+	doc    document.Document
+}
+
+func (op *GapFillOperator) Iterate(in *environment.Environment, f func(out *environment.Environment) error) error {
+	groupName := op.Bucket.String()
+
+	// account for the memory used by the groups
+	tracker := in.GetResourceTracker()
+	var size int64
+	defer func() { tracker.Shrink(size) }()
+
+	var nulls, groups []bucketGroup
+	err := op.Prev.Iterate(in, func(out *environment.Environment) error {
+		d, ok := out.GetDocument()
+		if !ok {
+			return errors.New("missing document")
+		}
+
+		n := int64(groupAggregatorSize * (len(op.Builders) + 1))
+		size += n
+		if err := tracker.Grow(n); err != nil {
+			return err
+		}
+
+		v, err := d.GetByField(groupName)
+		if err != nil && err != document.ErrFieldNotFound {
+			return err
+		}
+		if err == document.ErrFieldNotFound || v.Type == document.NullValue {
+			nulls = append(nulls, bucketGroup{bucket: document.NewNullValue(), doc: d})
+			return nil
+		}
+
+		groups = append(groups, bucketGroup{bucket: v, doc: d})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var sortErr error
+	sort.SliceStable(groups, func(i, j int) bool {
+		ok, err := groups[i].bucket.IsLesserThan(groups[j].bucket)
+		if err != nil {
+			sortErr = err
+		}
+		return ok
+	})
+	if sortErr != nil {
+		return sortErr
+	}
+
+	var newEnv environment.Environment
+	newEnv.SetOuter(in)
+
+	for _, g := range nulls {
+		newEnv.SetDocument(g.doc)
+		if err := f(&newEnv); err != nil {
+			return err
+		}
+	}
+
+	for i, g := range groups {
+		newEnv.SetDocument(g.doc)
+		if err := f(&newEnv); err != nil {
+			return err
+		}
+
+		if i+1 == len(groups) {
+			break
+		}
+
+		// output an empty group for each bucket up to the next one
+		bucket := g.bucket
+		for {
+			next, err := op.Bucket.Next(in, bucket)
+			if err != nil {
+				return err
+			}
+			if ok, err := next.IsGreaterThan(bucket); err != nil || !ok {
+				return stringutil.Errorf("%s: unable to compute the bucket following %s", op.Bucket.Name, bucket)
+			}
+			if ok, err := next.IsLesserThan(groups[i+1].bucket); err != nil || !ok {
+				break
+			}
+
+			d, err := op.emptyGroup(in, groupName, next)
+			if err != nil {
+				return err
+			}
+			newEnv.SetDocument(d)
+			if err := f(&newEnv); err != nil {
+				return err
+			}
+
+			bucket = next
+		}
+	}
+
+	return nil
+}
+
+// emptyGroup returns the document of a group without any document.
+func (op *GapFillOperator) emptyGroup(env *environment.Environment, groupName string, bucket document.Value) (document.Document, error) {
+	fb := document.NewFieldBuffer()
+	fb.Add(groupName, bucket)
+
+	for _, b := range op.Builders {
+		agg := b.Aggregator()
+		v, err := agg.Eval(env)
+		if err != nil {
+			return nil, err
+		}
+		fb.Add(stringutil.Sprintf("%s", agg), v)
+	}
+
+	return fb, nil
+}
+
+func (op *GapFillOperator) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("gapFill(")
+	sb.WriteString(op.Bucket.String())
+	for _, agg := range op.Builders {
+		sb.WriteString(", ")
+		sb.WriteString(agg.(stringutil.Stringer).String())
+	}
+	sb.WriteString(")")
+
+	return sb.String()
+}
@@ -0,0 +1,76 @@
+package stream_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/environment"
+	"github.com/genjidb/genji/internal/expr"
+	"github.com/genjidb/genji/internal/sql/parser"
+	"github.com/genjidb/genji/internal/stream"
+	"github.com/genjidb/genji/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGapFill(t *testing.T) {
+	bucket := parser.MustParseExpr("date_bin('1h', a)").(*expr.DateBinFunc)
+	count := &expr.CountFunc{Wildcard: true}
+
+	tests := []struct {
+		name string
+		in   []document.Document
+		want testutil.Docs
+	}{
+		{
+			"empty",
+			nil,
+			testutil.MakeDocuments(t, `{"COUNT(*)": 0}`),
+		},
+		{
+			"no gap",
+			testutil.MakeDocuments(t, `{"a": "2021-01-01T11:10:00Z"}`, `{"a": "2021-01-01T10:10:00Z"}`),
+			testutil.MakeDocuments(t,
+				`{"date_bin(\"1h\", a)": "2021-01-01T10:00:00Z", "COUNT(*)": 1}`,
+				`{"date_bin(\"1h\", a)": "2021-01-01T11:00:00Z", "COUNT(*)": 1}`,
+			),
+		},
+		{
+			"gaps",
+			testutil.MakeDocuments(t, `{"a": "2021-01-01T13:10:00Z"}`, `{"a": null}`, `{"a": "2021-01-01T10:10:00Z"}`, `{"a": "2021-01-01T10:20:00Z"}`),
+			testutil.MakeDocuments(t,
+				`{"date_bin(\"1h\", a)": null, "COUNT(*)": 1}`,
+				`{"date_bin(\"1h\", a)": "2021-01-01T10:00:00Z", "COUNT(*)": 2}`,
+				`{"date_bin(\"1h\", a)": "2021-01-01T11:00:00Z", "COUNT(*)": 0}`,
+				`{"date_bin(\"1h\", a)": "2021-01-01T12:00:00Z", "COUNT(*)": 0}`,
+				`{"date_bin(\"1h\", a)": "2021-01-01T13:00:00Z", "COUNT(*)": 1}`,
+			),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := stream.New(stream.Documents(test.in...)).
+				Pipe(stream.GroupBy(bucket)).
+				Pipe(stream.HashAggregate(count)).
+				Pipe(stream.GapFill(bucket, count))
+
+			var got testutil.Docs
+			err := s.Iterate(new(environment.Environment), func(out *environment.Environment) error {
+				d, ok := out.GetDocument()
+				require.True(t, ok)
+
+				fb := document.NewFieldBuffer()
+				err := fb.Copy(d)
+				require.NoError(t, err)
+				got = append(got, fb)
+				return nil
+			})
+			require.NoError(t, err)
+			test.want.RequireEqual(t, got)
+		})
+	}
+
+	t.Run("String", func(t *testing.T) {
+		require.Equal(t, `gapFill(date_bin("1h", a), COUNT(*))`, stream.GapFill(bucket, count).String())
+	})
+}
@@ -0,0 +1,199 @@
+package stream
+
+import (
+	"sort"
+	"time"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/environment"
+	"github.com/genjidb/genji/internal/expr"
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// layouts accepted by FOR SYSTEM_TIME AS OF.
+var historyTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// A HistoryScanOperator iterates over the documents of a table as they were at a given time.
+// The table must be audited: its documents are rebuilt by undoing the mutations recorded
+// in its audit trail after that time.
+type HistoryScanOperator struct {
+	baseOperator
+	TableName string
+	AsOf      expr.Expr
+}
+
+// HistoryScan creates an iterator that iterates over each document of the given table,
+// as it was at the time returned by asOf.
+func HistoryScan(tableName string, asOf expr.Expr) *HistoryScanOperator {
+	return &HistoryScanOperator{TableName: tableName, AsOf: asOf}
+}
+
+func (it *HistoryScanOperator) Iterate(in *environment.Environment, fn func(out *environment.Environment) error) error {
+	at, err := it.evalTime(in)
+	if err != nil {
+		return err
+	}
+
+	table, err := in.GetCatalog().GetTable(in.GetTx(), it.TableName)
+	if err != nil {
+		return err
+	}
+	if !table.Info.Audit {
+		return stringutil.Errorf("table %q has no history, it must be created WITH (audit = true)", it.TableName)
+	}
+
+	audit, err := in.GetCatalog().GetTable(in.GetTx(), database.AuditTableName(it.TableName))
+	if err != nil {
+		return err
+	}
+
+	// for each document modified after the requested time, find the version
+	// it had at that time, which is the old version of its first modification.
+	// a nil version means the document didn't exist.
+	versions := make(map[string]document.Document)
+	err = audit.Iterate(func(d document.Document) error {
+		entryAt, err := d.GetByField("at")
+		if err != nil {
+			return err
+		}
+		if entryAt.V.(string) <= at {
+			return nil
+		}
+
+		v, err := d.GetByField("op")
+		if err != nil {
+			return err
+		}
+		if v.V.(string) == "purge" {
+			return stringutil.Errorf("the history of table %q before %s has been purged", it.TableName, entryAt.V)
+		}
+
+		pk, err := d.GetByField("pk")
+		if err != nil {
+			return err
+		}
+		key, err := table.EncodeValue(pk)
+		if err != nil {
+			return err
+		}
+		if _, ok := versions[string(key)]; ok {
+			return nil
+		}
+
+		v, err = d.GetByField("old")
+		if err == document.ErrFieldNotFound {
+			versions[string(key)] = nil
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		fb := document.NewFieldBuffer()
+		err = fb.Copy(v.V.(document.Document))
+		versions[string(key)] = fb
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	var newEnv environment.Environment
+	newEnv.SetOuter(in)
+
+	tracker := in.GetResourceTracker()
+
+	emit := func(d document.Document) error {
+		if err := tracker.ScanDocument(); err != nil {
+			return err
+		}
+
+		newEnv.SetDocument(d)
+		return fn(&newEnv)
+	}
+
+	// documents are returned in key order, which requires inserting
+	// the documents deleted since then between the existing ones.
+	keys := make([]string, 0, len(versions))
+	for k := range versions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// emitVersions emits the versions of the keys lower than key.
+	emitVersions := func(key string) error {
+		for len(keys) > 0 && keys[0] < key {
+			if d := versions[keys[0]]; d != nil {
+				if err := emit(d); err != nil {
+					return err
+				}
+			}
+			keys = keys[1:]
+		}
+
+		return nil
+	}
+
+	err = table.Iterate(func(d document.Document) error {
+		key := string(d.(document.Keyer).RawKey())
+
+		err := emitVersions(key)
+		if err != nil {
+			return err
+		}
+
+		if len(keys) == 0 || keys[0] != key {
+			return emit(d)
+		}
+
+		// the document was modified since then.
+		keys = keys[1:]
+		if old := versions[key]; old != nil {
+			return emit(old)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// emit the remaining versions
+	for _, k := range keys {
+		if d := versions[k]; d != nil {
+			if err := emit(d); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// evalTime evaluates the AsOf expression and returns it in the format of the audit entries.
+func (it *HistoryScanOperator) evalTime(in *environment.Environment) (string, error) {
+	v, err := it.AsOf.Eval(in)
+	if err != nil {
+		return "", err
+	}
+	if v.Type != document.TextValue {
+		return "", stringutil.Errorf("AS OF expects a text value, got %q", v.Type)
+	}
+
+	for _, layout := range historyTimeLayouts {
+		t, err := time.Parse(layout, v.V.(string))
+		if err == nil {
+			return t.UTC().Format(database.AuditTimeFormat), nil
+		}
+	}
+
+	return "", stringutil.Errorf("invalid time %q", v.V)
+}
+
+func (it *HistoryScanOperator) String() string {
+	return stringutil.Sprintf("historyScan(%s, %s)", it.TableName, it.AsOf)
+}
@@ -0,0 +1,318 @@
+package stream
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/environment"
+	"github.com/genjidb/genji/internal/expr"
+	"github.com/genjidb/genji/internal/sql/scanner"
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// size accounted in the memory tracker for each document of the build side,
+// in addition to its encoded size.
+const joinEntrySize = 32
+
+// A JoinOperator returns a document for each pair of documents of two streams
+// satisfying the join condition.
+// The document of each side is stored in a field named after its table, e.g. joining
+// a with b returns documents like {"a": {...}, "b": {...}}, which allows the condition
+// and the rest of the statement to refer to the fields of a table with paths like a.x.
+// A side without name returns documents that are already joined, like the output
+// of another JoinOperator, whose fields are copied as is.
+//
+// The documents of one side, the build side, are loaded in memory and indexed by the
+// values of the paths compared with = by the condition. Each document of the other side
+// then only evaluates the condition against the documents with the same values.
+// If the condition doesn't compare paths of both sides, every pair of documents is evaluated.
+type JoinOperator struct {
+	baseOperator
+	Left      *Stream
+	Right     *Stream
+	LeftName  string
+	RightName string
+	On        expr.Expr
+
+	// Paths compared with = by the condition, evaluated on the documents
+	// of the left and right sides respectively.
+	LeftKeys  []expr.Path
+	RightKeys []expr.Path
+
+	// If true, the left side is loaded in memory instead of the right side.
+	BuildLeft bool
+}
+
+// Join creates a JoinOperator returning the pairs of documents of left and right
+// satisfying the condition. leftName and rightName are the names of the fields
+// in which the documents of each side are stored.
+func Join(left, right *Stream, leftName, rightName string, on expr.Expr) *JoinOperator {
+	op := JoinOperator{
+		Left:      left,
+		Right:     right,
+		LeftName:  leftName,
+		RightName: rightName,
+		On:        on,
+	}
+
+	leftTables := sideTables(left, leftName)
+	rightTables := sideTables(right, rightName)
+
+	for _, e := range splitAND(on) {
+		cmp, ok := e.(expr.Operator)
+		if !ok || cmp.Token() != scanner.EQ {
+			continue
+		}
+
+		l, lok := cmp.LeftHand().(expr.Path)
+		r, rok := cmp.RightHand().(expr.Path)
+		if !lok || !rok {
+			continue
+		}
+
+		switch {
+		case pathOf(l, leftTables) && pathOf(r, rightTables):
+			op.LeftKeys = append(op.LeftKeys, l)
+			op.RightKeys = append(op.RightKeys, r)
+		case pathOf(l, rightTables) && pathOf(r, leftTables):
+			op.LeftKeys = append(op.LeftKeys, r)
+			op.RightKeys = append(op.RightKeys, l)
+		}
+	}
+
+	return &op
+}
+
+// Tables returns the names of the tables joined by the operator, in order.
+func (op *JoinOperator) Tables() []string {
+	return append(sideTables(op.Left, op.LeftName), sideTables(op.Right, op.RightName)...)
+}
+
+// sideTables returns the names of the tables read by one side of a join.
+func sideTables(s *Stream, name string) []string {
+	if name != "" {
+		return []string{name}
+	}
+
+	if j, ok := s.First().(*JoinOperator); ok {
+		return j.Tables()
+	}
+
+	return nil
+}
+
+// pathOf returns true if p refers to a field of one of the given tables.
+func pathOf(p expr.Path, tables []string) bool {
+	if len(p) < 2 || p[0].FieldName == "" {
+		return false
+	}
+
+	for _, t := range tables {
+		if p[0].FieldName == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitAND splits an expression by AND operator, ignoring parentheses.
+func splitAND(e expr.Expr) []expr.Expr {
+	switch t := e.(type) {
+	case expr.Parentheses:
+		return splitAND(t.E)
+	case expr.Operator:
+		if t.Token() == scanner.AND {
+			return append(splitAND(t.LeftHand()), splitAND(t.RightHand())...)
+		}
+	}
+
+	return []expr.Expr{e}
+}
+
+// Iterate loads the build side in memory, then iterates over the other side
+// and calls fn for every pair of documents satisfying the condition.
+// The pairs are returned in the order of the side that is not loaded in memory.
+func (op *JoinOperator) Iterate(in *environment.Environment, fn func(out *environment.Environment) error) error {
+	codec, err := getCodec(in)
+	if err != nil {
+		return err
+	}
+
+	build, probe := op.Right, op.Left
+	buildName, probeName := op.RightName, op.LeftName
+	buildKeys, probeKeys := op.RightKeys, op.LeftKeys
+	if op.BuildLeft {
+		build, probe = probe, build
+		buildName, probeName = probeName, buildName
+		buildKeys, probeKeys = probeKeys, buildKeys
+	}
+
+	// account for the memory used by the build side
+	tracker := in.GetResourceTracker()
+	var size int64
+	defer func() { tracker.Shrink(size) }()
+
+	var buf bytes.Buffer
+	enc := document.NewValueEncoder(&buf)
+
+	// the documents of the build side are stored encoded,
+	// grouped by the values of their keys.
+	table := make(map[string][][]byte)
+	err = build.Iterate(in, func(out *environment.Environment) error {
+		d, ok := out.GetDocument()
+		if !ok {
+			return nil
+		}
+
+		k, err := joinKey(enc, &buf, sideDocument(buildName, d), buildKeys)
+		if err != nil {
+			return err
+		}
+
+		var data bytes.Buffer
+		de := codec.NewEncoder(&data)
+		err = de.EncodeDocument(d)
+		de.Close()
+		if err != nil {
+			return err
+		}
+
+		n := int64(len(k) + data.Len() + joinEntrySize)
+		size += n
+		if err := tracker.Grow(n); err != nil {
+			return err
+		}
+
+		table[k] = append(table[k], data.Bytes())
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(table) == 0 {
+		return nil
+	}
+
+	var fb document.FieldBuffer
+	var newEnv environment.Environment
+	newEnv.SetOuter(in)
+
+	return probe.Iterate(in, func(out *environment.Environment) error {
+		d, ok := out.GetDocument()
+		if !ok {
+			return nil
+		}
+		probeDoc := sideDocument(probeName, d)
+
+		k, err := joinKey(enc, &buf, probeDoc, probeKeys)
+		if err != nil {
+			return err
+		}
+
+		for _, data := range table[k] {
+			buildDoc := sideDocument(buildName, codec.NewDecoder(data))
+
+			fb.Reset()
+			if op.BuildLeft {
+				err = joinDocuments(&fb, buildDoc, probeDoc)
+			} else {
+				err = joinDocuments(&fb, probeDoc, buildDoc)
+			}
+			if err != nil {
+				return err
+			}
+
+			newEnv.SetDocument(&fb)
+			v, err := op.On.Eval(&newEnv)
+			if err != nil {
+				return err
+			}
+
+			ok, err := v.IsTruthy()
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+
+			err = fn(&newEnv)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// sideDocument returns the document d of a side of a join,
+// stored in a field named after its table, if any.
+func sideDocument(name string, d document.Document) document.Document {
+	if name == "" {
+		return d
+	}
+
+	return document.NewFieldBuffer().Add(name, document.NewDocumentValue(d))
+}
+
+// joinDocuments adds the fields of the documents of both sides to fb.
+func joinDocuments(fb *document.FieldBuffer, left, right document.Document) error {
+	err := fb.ScanDocument(left)
+	if err != nil {
+		return err
+	}
+
+	return fb.ScanDocument(right)
+}
+
+// joinKey encodes the values of the keys of a document.
+// Documents whose keys are equal have the same encoded keys: integers are encoded
+// as doubles, and arrays and documents only by their type, since their content
+// may be equal without being encoded the same way. The condition is always evaluated
+// on the matching pairs, which filters out the documents with different values.
+func joinKey(enc *document.ValueEncoder, buf *bytes.Buffer, d document.Document, keys []expr.Path) (string, error) {
+	buf.Reset()
+
+	for _, k := range keys {
+		v, err := document.Path(k).GetValueFromDocument(d)
+		if err == document.ErrFieldNotFound {
+			v, err = document.NewNullValue(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch v.Type {
+		case document.ArrayValue, document.DocumentValue:
+			buf.WriteByte(byte(v.Type))
+			continue
+		}
+
+		err = enc.Encode(document.ConvertUntyped(v))
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return buf.String(), nil
+}
+
+func (op *JoinOperator) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("hashJoin(")
+	sb.WriteString(op.Left.String())
+	sb.WriteString(", ")
+	sb.WriteString(op.Right.String())
+	stringutil.Fprintf(&sb, ", %s", op.On)
+	if op.BuildLeft {
+		sb.WriteString(", build left")
+	}
+	sb.WriteString(")")
+
+	return sb.String()
+}
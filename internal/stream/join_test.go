@@ -0,0 +1,107 @@
+package stream_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/genjidb/genji/document"
+	errs "github.com/genjidb/genji/errors"
+	"github.com/genjidb/genji/internal/environment"
+	"github.com/genjidb/genji/internal/expr"
+	"github.com/genjidb/genji/internal/sql/parser"
+	"github.com/genjidb/genji/internal/stream"
+	"github.com/genjidb/genji/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJoinOperator(t *testing.T) {
+	left := testutil.MakeDocuments(t, `{"x": 1, "z": 1}`, `{"x": 2, "z": 2}`, `{"x": 3, "z": 3}`, `{"z": 4}`)
+	right := testutil.MakeDocuments(t, `{"y": 1.0}`, `{"y": 2}`, `{"y": 2}`, `{"y": "1"}`, `{"y": [1]}`)
+
+	tests := []struct {
+		on       string
+		expected []string
+	}{
+		{"a.x = b.y", []string{
+			`{"a": {"x": 1, "z": 1}, "b": {"y": 1.0}}`,
+			`{"a": {"x": 2, "z": 2}, "b": {"y": 2}}`,
+			`{"a": {"x": 2, "z": 2}, "b": {"y": 2}}`,
+		}},
+		{"b.y = a.x AND a.z > 1", []string{
+			`{"a": {"x": 2, "z": 2}, "b": {"y": 2}}`,
+			`{"a": {"x": 2, "z": 2}, "b": {"y": 2}}`,
+		}},
+		{"a.x < b.y", []string{
+			`{"a": {"x": 1, "z": 1}, "b": {"y": 2}}`,
+			`{"a": {"x": 1, "z": 1}, "b": {"y": 2}}`,
+		}},
+		{"a.z = 4 AND b.y = '1'", []string{
+			`{"a": {"z": 4}, "b": {"y": "1"}}`,
+		}},
+		{"false", nil},
+	}
+
+	for _, test := range tests {
+		for _, buildLeft := range []bool{false, true} {
+			t.Run(test.on, func(t *testing.T) {
+				db, tx, cleanup := testutil.NewTestTx(t)
+				defer cleanup()
+
+				op := stream.Join(stream.New(stream.Documents(left...)), stream.New(stream.Documents(right...)), "a", "b", parser.MustParseExpr(test.on))
+				op.BuildLeft = buildLeft
+
+				in := environment.Environment{Tx: tx, Catalog: db.Catalog}
+
+				var got []string
+				err := op.Iterate(&in, func(out *environment.Environment) error {
+					d, ok := out.GetDocument()
+					require.True(t, ok)
+					data, err := document.MarshalJSON(d)
+					require.NoError(t, err)
+					got = append(got, string(data))
+					return nil
+				})
+				require.NoError(t, err)
+
+				// the order of the pairs depends on the build side
+				sort.Strings(got)
+				require.Len(t, got, len(test.expected))
+				for i := range got {
+					require.JSONEq(t, test.expected[i], got[i])
+				}
+			})
+		}
+	}
+
+	t.Run("Keys", func(t *testing.T) {
+		op := stream.Join(stream.New(stream.Documents(left...)), stream.New(stream.Documents(right...)), "a", "b",
+			parser.MustParseExpr("(b.y = a.x AND a.z = b.z) AND a.x + 1 = b.y AND a.x = a.z"))
+		require.Equal(t, []expr.Path{expr.Path(document.NewPath("a", "x")), expr.Path(document.NewPath("a", "z"))}, op.LeftKeys)
+		require.Equal(t, []expr.Path{expr.Path(document.NewPath("b", "y")), expr.Path(document.NewPath("b", "z"))}, op.RightKeys)
+
+		// paths of the tables joined by the left side
+		op = stream.Join(stream.New(op), stream.New(stream.Documents(right...)), "", "c", parser.MustParseExpr("c.y = a.x AND b.y = c.y"))
+		require.Equal(t, []string{"a", "b", "c"}, op.Tables())
+		require.Equal(t, []expr.Path{expr.Path(document.NewPath("a", "x")), expr.Path(document.NewPath("b", "y"))}, op.LeftKeys)
+	})
+
+	t.Run("Memory budget", func(t *testing.T) {
+		db, tx, cleanup := newSpillTestTx(t, 10)
+		defer cleanup()
+
+		in := environment.Environment{Tx: tx, Catalog: db.Catalog, Tracker: db.NewResourceTracker()}
+		defer in.Tracker.Close()
+
+		op := stream.Join(stream.New(stream.Documents(left...)), stream.New(stream.Documents(right...)), "a", "b", parser.MustParseExpr("a.x = b.y"))
+		err := op.Iterate(&in, func(out *environment.Environment) error { return nil })
+		require.True(t, errs.IsLimitExceededError(err))
+	})
+}
+
+func TestJoinOperatorString(t *testing.T) {
+	op := stream.Join(stream.New(stream.SeqScan("a")), stream.New(stream.SeqScan("b")), "a", "b", parser.MustParseExpr("a.x = b.y"))
+	require.Equal(t, "hashJoin(seqScan(a), seqScan(b), a.x = b.y)", op.String())
+
+	op.BuildLeft = true
+	require.Equal(t, "hashJoin(seqScan(a), seqScan(b), a.x = b.y, build left)", op.String())
+}
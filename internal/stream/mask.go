@@ -0,0 +1,80 @@
+package stream
+
+import (
+	"github.com/genjidb/genji/internal/environment"
+)
+
+// newFieldMasker returns a function applying the field masks of the table
+// read by the stream to the documents output by op, if they must be masked.
+// Masks are applied by the first projection, aggregation or insertion of the stream,
+// or to its output if there is none, which means filters still operate on the unmasked values.
+// It returns nil if the transaction is privileged, if the table has no mask,
+// or if the documents were already masked.
+func newFieldMasker(in *environment.Environment, op Operator) (func(env *environment.Environment) (*environment.Environment, error), error) {
+	if in == nil {
+		return nil, nil
+	}
+
+	tx := in.GetTx()
+	if tx == nil || !tx.Unprivileged {
+		return nil, nil
+	}
+
+	var tableName string
+	for prev := op; prev != nil; prev = prev.GetPrev() {
+		switch t := prev.(type) {
+		case *ProjectOperator, *GroupByOperator, *HashAggregateOperator, *TableInsertOperator:
+			return nil, nil
+		case *SeqScanOperator:
+			tableName = t.TableName
+		case *PkScanOperator:
+			tableName = t.TableName
+		case *HistoryScanOperator:
+			tableName = t.TableName
+		case *IndexScanOperator:
+			info, err := in.GetCatalog().GetIndexInfo(t.IndexName)
+			if err != nil {
+				return nil, err
+			}
+			tableName = info.TableName
+		}
+	}
+	if tableName == "" {
+		return nil, nil
+	}
+
+	ti, err := in.GetCatalog().GetTableInfo(tableName)
+	if err != nil {
+		return nil, err
+	}
+	if len(ti.Masks) == 0 {
+		return nil, nil
+	}
+
+	var newEnv environment.Environment
+	return func(env *environment.Environment) (*environment.Environment, error) {
+		d, ok := env.GetDocument()
+		if !ok {
+			return env, nil
+		}
+
+		var err error
+		d, err = ti.MaskDocument(tx, d)
+		if err != nil {
+			return nil, err
+		}
+
+		newEnv.SetOuter(env)
+		newEnv.SetDocument(d)
+		return &newEnv, nil
+	}, nil
+}
+
+// maskedEnv applies mask to env, if not nil.
+func maskedEnv(mask func(env *environment.Environment) (*environment.Environment, error), env *environment.Environment) (*environment.Environment, error) {
+	if mask == nil {
+		return env, nil
+	}
+
+	return mask(env)
+}
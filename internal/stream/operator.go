@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/engine"
 	"github.com/genjidb/genji/internal/database"
 	"github.com/genjidb/genji/internal/environment"
 	"github.com/genjidb/genji/internal/expr"
@@ -89,7 +90,17 @@ func Map(e expr.Expr) *MapOperator {
 func (op *MapOperator) Iterate(in *environment.Environment, f func(out *environment.Environment) error) error {
 	var newEnv environment.Environment
 
+	mask, err := newFieldMasker(in, op.Prev)
+	if err != nil {
+		return err
+	}
+
 	return op.Prev.Iterate(in, func(out *environment.Environment) error {
+		out, err := maskedEnv(mask, out)
+		if err != nil {
+			return err
+		}
+
 		v, err := op.E.Eval(out)
 		if err != nil {
 			return err
@@ -215,7 +226,17 @@ func GroupBy(e expr.Expr) *GroupByOperator {
 func (op *GroupByOperator) Iterate(in *environment.Environment, f func(out *environment.Environment) error) error {
 	var newEnv environment.Environment
 
+	mask, err := newFieldMasker(in, op.Prev)
+	if err != nil {
+		return err
+	}
+
 	return op.Prev.Iterate(in, func(out *environment.Environment) error {
+		out, err := maskedEnv(mask, out)
+		if err != nil {
+			return err
+		}
+
 		v, err := op.E.Eval(out)
 		if err != nil {
 			return err
@@ -260,6 +281,12 @@ func SortReverse(e expr.Expr) *SortOperator {
 }
 
 func (op *SortOperator) Iterate(in *environment.Environment, f func(out *environment.Environment) error) error {
+	// if memory usage is limited, the stream is written to a temporary store
+	// which spills to disk once the memory budget is exhausted.
+	if tracker := in.GetResourceTracker(); tracker.TracksMemory() {
+		return op.iterateTempStore(in, tracker, f)
+	}
+
 	h, err := op.sortStream(op.Prev, in)
 	if err != nil {
 		return err
@@ -276,6 +303,82 @@ func (op *SortOperator) Iterate(in *environment.Environment, f func(out *environ
 	return nil
 }
 
+// iterateTempStore sorts the stream using a temporary store
+// whose keys are the encoded sort values.
+func (op *SortOperator) iterateTempStore(in *environment.Environment, tracker *database.ResourceTracker, f func(out *environment.Environment) error) error {
+	codec, err := getCodec(in)
+	if err != nil {
+		return err
+	}
+
+	st := tracker.NewTempStore()
+	defer st.Close()
+
+	getValue := op.getValueFunc()
+
+	var buf bytes.Buffer
+	var seq uint64
+	err = op.Prev.Iterate(in, func(env *environment.Environment) error {
+		sortV, err := getValue(env)
+		if err != nil {
+			return err
+		}
+
+		buf.Reset()
+		err = document.NewValueEncoder(&buf).Encode(sortV)
+		if err != nil {
+			return err
+		}
+
+		data, err := encodeEnv(codec, env, envDepth(in, env))
+		if err != nil {
+			return err
+		}
+
+		seq++
+		return st.Put(sortKey(buf.Bytes(), seq), data)
+	})
+	if err != nil {
+		return err
+	}
+
+	return st.Iterate(op.Desc, func(k, v []byte) error {
+		out, err := decodeEnv(codec, in, v)
+		if err != nil {
+			return err
+		}
+
+		return f(out)
+	})
+}
+
+// getValueFunc returns a function evaluating the sort expression.
+func (op *SortOperator) getValueFunc() func(env *environment.Environment) (document.Value, error) {
+	p, ok := op.Expr.(expr.Path)
+	if !ok {
+		return op.Expr.Eval
+	}
+
+	return func(env *environment.Environment) (document.Value, error) {
+		for env != nil {
+			d, ok := env.GetDocument()
+			if !ok {
+				env = env.GetOuter()
+				continue
+			}
+
+			v, err := document.Path(p).GetValueFromDocument(d)
+			if err == document.ErrFieldNotFound {
+				env = env.GetOuter()
+				continue
+			}
+			return v, err
+		}
+
+		return document.NewNullValue(), nil
+	}
+}
+
 func (op *SortOperator) sortStream(prev Operator, in *environment.Environment) (heap.Interface, error) {
 	var h heap.Interface
 	if op.Desc {
@@ -286,27 +389,7 @@ func (op *SortOperator) sortStream(prev Operator, in *environment.Environment) (
 
 	heap.Init(h)
 
-	getValue := op.Expr.Eval
-	if p, ok := op.Expr.(expr.Path); ok {
-		getValue = func(env *environment.Environment) (document.Value, error) {
-			for env != nil {
-				d, ok := env.GetDocument()
-				if !ok {
-					env = env.GetOuter()
-					continue
-				}
-
-				v, err := document.Path(p).GetValueFromDocument(d)
-				if err == document.ErrFieldNotFound {
-					env = env.GetOuter()
-					continue
-				}
-				return v, err
-			}
-
-			return document.NewNullValue(), nil
-		}
-	}
+	getValue := op.getValueFunc()
 
 	return h, prev.Iterate(in, func(env *environment.Environment) error {
 		sortV, err := getValue(env)
@@ -348,6 +431,22 @@ func (op *SortOperator) String() string {
 	return stringutil.Sprintf("sort(%s)", op.Expr)
 }
 
+// documentSize returns the size of the encoded document, which
+// is used as an estimate of the memory it uses.
+func documentSize(d document.Document) (int64, error) {
+	var w countingWriter
+	err := document.NewValueEncoder(&w).Encode(document.NewDocumentValue(d))
+	return int64(w), err
+}
+
+// countingWriter counts the bytes written to it.
+type countingWriter int
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	*w += countingWriter(len(p))
+	return len(p), nil
+}
+
 type heapNode struct {
 	value []byte
 	data  *environment.Environment
@@ -395,14 +494,23 @@ func TableInsert(tableName string, onConflict database.OnInsertConflictAction) *
 func (op *TableInsertOperator) Iterate(in *environment.Environment, f func(out *environment.Environment) error) error {
 	var newEnv environment.Environment
 
+	mask, err := newFieldMasker(in, op.Prev)
+	if err != nil {
+		return err
+	}
+
 	var table *database.Table
 	return op.Prev.Iterate(in, func(env *environment.Environment) error {
+		env, err := maskedEnv(mask, env)
+		if err != nil {
+			return err
+		}
+
 		d, ok := env.GetDocument()
 		if !ok {
 			return errors.New("missing document")
 		}
 
-		var err error
 		if table == nil {
 			table, err = env.GetCatalog().GetTable(env.GetTx(), op.Name)
 			if err != nil {
@@ -445,7 +553,7 @@ func (op *TableReplaceOperator) Iterate(in *environment.Environment, f func(out
 	var table *database.Table
 	var newEnv environment.Environment
 
-	return op.Prev.Iterate(in, func(out *environment.Environment) error {
+	err := op.Prev.Iterate(in, func(out *environment.Environment) error {
 		d, ok := out.GetDocument()
 		if !ok {
 			return errors.New("missing document")
@@ -457,6 +565,7 @@ func (op *TableReplaceOperator) Iterate(in *environment.Environment, f func(out
 			if err != nil {
 				return err
 			}
+			table.BatchIndexes()
 		}
 
 		ker, ok := d.(document.Keyer)
@@ -469,14 +578,29 @@ func (op *TableReplaceOperator) Iterate(in *environment.Environment, f func(out
 			return errors.New("missing key")
 		}
 
-		_, err := table.Replace(ker.RawKey(), d)
+		d, err := table.Replace(ker.RawKey(), d)
 		if err != nil {
 			return err
 		}
 
+		// the stored document, with its default values
+		newEnv.SetDocument(d)
 		newEnv.SetOuter(out)
 		return f(&newEnv)
 	})
+	if err != nil && err != ErrStreamClosed {
+		return err
+	}
+
+	// apply index mutations once every document has been processed
+	if table != nil {
+		ferr := table.FlushIndexes()
+		if ferr != nil {
+			return ferr
+		}
+	}
+
+	return err
 }
 
 func (op *TableReplaceOperator) String() string {
@@ -499,7 +623,7 @@ func (op *TableDeleteOperator) Iterate(in *environment.Environment, f func(out *
 	var table *database.Table
 	var newEnv environment.Environment
 
-	return op.Prev.Iterate(in, func(out *environment.Environment) error {
+	err := op.Prev.Iterate(in, func(out *environment.Environment) error {
 		d, ok := out.GetDocument()
 		if !ok {
 			return errors.New("missing document")
@@ -511,6 +635,7 @@ func (op *TableDeleteOperator) Iterate(in *environment.Environment, f func(out *
 			if err != nil {
 				return err
 			}
+			table.BatchIndexes()
 		}
 
 		ker, ok := d.(document.Keyer)
@@ -531,6 +656,19 @@ func (op *TableDeleteOperator) Iterate(in *environment.Environment, f func(out *
 		newEnv.SetOuter(out)
 		return f(&newEnv)
 	})
+	if err != nil && err != ErrStreamClosed {
+		return err
+	}
+
+	// apply index mutations once every document has been processed
+	if table != nil {
+		ferr := table.FlushIndexes()
+		if ferr != nil {
+			return ferr
+		}
+	}
+
+	return err
 }
 
 func (op *TableDeleteOperator) String() string {
@@ -551,10 +689,16 @@ func Distinct() *DistinctOperator {
 func (op *DistinctOperator) Iterate(in *environment.Environment, f func(out *environment.Environment) error) error {
 	var buf bytes.Buffer
 	enc := document.NewValueEncoder(&buf)
-	m := make(map[string]struct{})
+
+	// store the keys in a temporary store, which spills
+	// to disk once the memory budget is exhausted.
+	st := in.GetResourceTracker().NewTempStore()
+	defer st.Close()
 
 	return op.Prev.Iterate(in, func(out *environment.Environment) error {
 		buf.Reset()
+		// prefix the key to avoid storing empty keys
+		buf.WriteByte(0)
 
 		d, ok := out.GetDocument()
 		if !ok {
@@ -578,13 +722,19 @@ func (op *DistinctOperator) Iterate(in *environment.Environment, f func(out *env
 			}
 		}
 
-		_, ok = m[string(buf.Bytes())]
+		_, err = st.Get(buf.Bytes())
 		// if value already exists, filter it out
-		if ok {
+		if err == nil {
 			return nil
 		}
+		if err != engine.ErrKeyNotFound {
+			return err
+		}
 
-		m[buf.String()] = struct{}{}
+		err = st.Put(buf.Bytes(), spilledValue)
+		if err != nil {
+			return err
+		}
 
 		return f(out)
 	})
@@ -0,0 +1,168 @@
+package stream
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// Plan returns a structured representation of the stream, meant to be
+// consumed by tools rendering execution plans.
+// It returns an array with one document per operator, in execution order.
+// Each document contains the following fields:
+//   - operator: the name of the operator, i.e. "seqScan"
+//   - args: the list of arguments of the operator, as text
+//   - estimates: a document containing the estimated cost of the operator, if known
+//   - streams: the plans of the streams read by the operator, if any
+func (s *Stream) Plan() *document.ValueBuffer {
+	var vb document.ValueBuffer
+
+	if s == nil {
+		return &vb
+	}
+
+	for op := s.First(); op != nil; op = op.GetNext() {
+		vb.Append(document.NewDocumentValue(planNode(op)))
+	}
+
+	return &vb
+}
+
+// MarshalJSON encodes the plan of the stream to JSON.
+func (s *Stream) MarshalJSON() ([]byte, error) {
+	return document.MarshalJSONArray(s.Plan())
+}
+
+func planNode(op Operator) *document.FieldBuffer {
+	var args []string
+	var cost = -1
+	var streams []*Stream
+
+	switch t := op.(type) {
+	case *MapOperator:
+		args = append(args, t.E.String())
+	case *FilterOperator:
+		args = append(args, t.E.String())
+	case *TakeOperator:
+		args = append(args, strconv.FormatInt(t.N, 10))
+	case *SkipOperator:
+		args = append(args, strconv.FormatInt(t.N, 10))
+	case *GroupByOperator:
+		args = append(args, t.E.String())
+	case *SortOperator:
+		args = append(args, t.Expr.String())
+	case *TableInsertOperator:
+		args = append(args, t.Name)
+		if t.OnConflict != nil {
+			args = append(args, "onConflictDoNothing")
+		}
+	case *TableReplaceOperator:
+		args = append(args, t.Name)
+	case *TableDeleteOperator:
+		args = append(args, t.Name)
+	case *DistinctOperator:
+	case *SetOperator:
+		args = append(args, t.Path.String(), t.E.String())
+	case *UnsetOperator:
+		args = append(args, t.Field)
+	case *IterRenameOperator:
+		args = append(args, t.FieldNames...)
+	case *HashAggregateOperator:
+		for _, agg := range t.Builders {
+			args = append(args, agg.(stringutil.Stringer).String())
+		}
+	case *ProjectOperator:
+		for _, e := range t.Exprs {
+			args = append(args, e.(stringutil.Stringer).String())
+		}
+	case *DocumentsOperator:
+		for _, d := range t.Docs {
+			args = append(args, d.(stringutil.Stringer).String())
+		}
+	case *ExprsOperator:
+		for _, e := range t.Exprs {
+			args = append(args, e.(stringutil.Stringer).String())
+		}
+	case *SeqScanOperator:
+		args = append(args, t.TableName)
+	case *PkScanOperator:
+		args = append(args, t.TableName)
+		for i := range t.Ranges {
+			args = append(args, t.Ranges[i].String())
+		}
+		if len(t.Ranges) > 0 {
+			cost = t.Ranges.Cost()
+		}
+	case *IndexScanOperator:
+		args = append(args, t.IndexName)
+		for i := range t.Ranges {
+			args = append(args, t.Ranges[i].String())
+		}
+		if len(t.Ranges) > 0 {
+			cost = t.Ranges.Cost()
+		}
+	case *ConcatOperator:
+		streams = append(streams, t.S1, t.S2)
+	case *CTEScanOperator:
+		args = append(args, t.CTE.Name)
+		streams = append(streams, t.CTE.Stream)
+		if t.CTE.Recursive != nil {
+			streams = append(streams, t.CTE.Recursive)
+		}
+	case *WorkingTableScanOperator:
+		args = append(args, t.Name)
+	default:
+		// unknown operators are described by their string representation
+		if s := operatorArgs(op); s != "" {
+			args = append(args, s)
+		}
+	}
+
+	var fb document.FieldBuffer
+	fb.Add("operator", document.NewTextValue(operatorName(op)))
+
+	vb := document.NewValueBuffer()
+	for _, arg := range args {
+		vb.Append(document.NewTextValue(arg))
+	}
+	fb.Add("args", document.NewArrayValue(vb))
+
+	if cost >= 0 {
+		fb.Add("estimates", document.NewDocumentValue(
+			document.NewFieldBuffer().Add("cost", document.NewIntegerValue(int64(cost))),
+		))
+	}
+
+	if len(streams) > 0 {
+		vb := document.NewValueBuffer()
+		for _, s := range streams {
+			vb.Append(document.NewArrayValue(s.Plan()))
+		}
+		fb.Add("streams", document.NewArrayValue(vb))
+	}
+
+	return &fb
+}
+
+// operatorName returns the name of the operator, as displayed by its String method.
+func operatorName(op Operator) string {
+	s := op.String()
+	if i := strings.IndexByte(s, '('); i >= 0 {
+		return s[:i]
+	}
+
+	return s
+}
+
+// operatorArgs returns the arguments of the operator, as displayed by its String method.
+func operatorArgs(op Operator) string {
+	s := op.String()
+	i := strings.IndexByte(s, '(')
+	if i < 0 || !strings.HasSuffix(s, ")") {
+		return ""
+	}
+
+	return s[i+1 : len(s)-1]
+}
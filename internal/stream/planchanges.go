@@ -0,0 +1,48 @@
+package stream
+
+import (
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/environment"
+)
+
+// A PlanChangesOperator generates one document per statement whose plan changed
+// since the database was opened, oldest first. It is used to read the virtual table
+// named database.PlanChangesTableName.
+type PlanChangesOperator struct {
+	baseOperator
+}
+
+// PlanChanges creates a PlanChangesOperator.
+func PlanChanges() *PlanChangesOperator {
+	return &PlanChangesOperator{}
+}
+
+// Iterate reads the plan changes recorded by the plan history of the transaction.
+// Each document contains the normalized statement, its previous and new plans
+// along with their fingerprints, and the time of the change.
+func (op *PlanChangesOperator) Iterate(in *environment.Environment, fn func(out *environment.Environment) error) error {
+	var newEnv environment.Environment
+	newEnv.SetOuter(in)
+
+	for _, c := range in.GetTx().Plans.Changes() {
+		newEnv.SetDocument(document.NewFieldBuffer().
+			Add("statement", document.NewTextValue(c.Statement)).
+			Add("old_plan", document.NewTextValue(c.OldPlan)).
+			Add("old_fingerprint", document.NewTextValue(database.PlanFingerprint(c.OldPlan))).
+			Add("new_plan", document.NewTextValue(c.NewPlan)).
+			Add("new_fingerprint", document.NewTextValue(database.PlanFingerprint(c.NewPlan))).
+			Add("at", document.NewTextValue(c.At.UTC().Format(database.AuditTimeFormat))))
+
+		err := fn(&newEnv)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (op *PlanChangesOperator) String() string {
+	return "planChanges()"
+}
@@ -33,7 +33,17 @@ func (op *ProjectOperator) Iterate(in *environment.Environment, f func(out *envi
 		return f(&newEnv)
 	}
 
+	fieldMask, err := newFieldMasker(in, op.Prev)
+	if err != nil {
+		return err
+	}
+
 	return op.Prev.Iterate(in, func(env *environment.Environment) error {
+		env, err := maskedEnv(fieldMask, env)
+		if err != nil {
+			return err
+		}
+
 		mask.Env = env
 		mask.Exprs = op.Exprs
 		newEnv.SetDocument(&mask)
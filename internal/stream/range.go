@@ -2,7 +2,6 @@ package stream
 
 import (
 	"bytes"
-	"math"
 	"strings"
 
 	"github.com/genjidb/genji/document"
@@ -16,6 +15,57 @@ type Costable interface {
 	Cost() int
 }
 
+// convertBound converts v, the bound of a range, to the type t of the key it selects,
+// following the rules of document.ConvertBound. If the bound was rounded, exclusive is updated
+// to preserve the comparison logic with the key:
+//
+//	a > 1.1  -> a >= 2; exclusive -> false
+//	a >= 1.1 -> a >= 2; exclusive -> false
+//	a < 1.1  -> a < 2;  exclusive -> true
+//	a <= 1.1 -> a < 2;  exclusive -> true
+//	a BETWEEN 1.1 AND 2.2 -> a >= 2 AND a <= 3; exclusive -> false
+//
+// onlyMax is true if the range has no lower bound, i.e. it isn't a BETWEEN.
+// Exact ranges are never rounded: if v cannot be converted, it is returned as is
+// and won't match any key.
+func convertBound(v document.Value, t document.ValueType, exact, isMin, onlyMax bool, exclusive *bool) document.Value {
+	if exact {
+		v, _ = document.ConvertKey(v, t)
+		return v
+	}
+
+	v, rounded, _ := document.ConvertBound(v, t)
+	if rounded {
+		if isMin {
+			*exclusive = false
+		} else {
+			*exclusive = onlyMax
+		}
+	}
+
+	return v
+}
+
+// checkStrictBound returns false if the boundary v of a range cannot select any value
+// in strict mode, because comparisons with NULL never match, and returns an error
+// if it cannot be compared with values of type t.
+func checkStrictBound(env *environment.Environment, v document.Value, t document.ValueType) (bool, error) {
+	tx := env.GetTx()
+	if tx == nil || !tx.Strict {
+		return true, nil
+	}
+
+	if v.Type == document.NullValue {
+		return false, nil
+	}
+
+	if t.IsAny() {
+		return true, nil
+	}
+
+	return true, expr.CheckComparable(v.Type, t)
+}
+
 type ValueRange struct {
 	Min, Max expr.Expr
 	// Exclude Min and Max from the results.
@@ -48,7 +98,11 @@ func (r *ValueRange) evalRange(table *database.Table, env *environment.Environme
 			return nil, false, err
 		}
 
-		var ok bool
+		ok, err := checkStrictBound(env, rng.Min, pk.Type)
+		if err != nil || !ok {
+			return nil, ok, err
+		}
+
 		rng.Min, ok, err = rng.Convert(rng.Min, true)
 		if err != nil || !ok {
 			return nil, ok, err
@@ -61,7 +115,11 @@ func (r *ValueRange) evalRange(table *database.Table, env *environment.Environme
 			return nil, false, err
 		}
 
-		var ok bool
+		ok, err := checkStrictBound(env, rng.Max, pk.Type)
+		if err != nil || !ok {
+			return nil, ok, err
+		}
+
 		rng.Max, ok, err = rng.Convert(rng.Max, false)
 		if err != nil || !ok {
 			return nil, ok, err
@@ -171,49 +229,9 @@ type encodedValueRange struct {
 
 func (r *encodedValueRange) Convert(v document.Value, isMin bool) (document.Value, bool, error) {
 	// ensure the operand satisfies all the constraints, index can work only on exact types.
-	// if a number is encountered, try to convert it to the right type if and only if the conversion
-	// is lossless.
+	// numbers are converted to the type of the key, see convertBound.
 	v, err := r.constraints.ConvertValueAtPath(r.path, v, func(v document.Value, path document.Path, targetType document.ValueType) (document.Value, error) {
-		if v.Type == document.IntegerValue && targetType == document.DoubleValue {
-			return v.CastAsDouble()
-		}
-
-		if v.Type == document.DoubleValue && targetType == document.IntegerValue {
-			f := v.V.(float64)
-			if float64(int64(f)) == f {
-				return v.CastAsInteger()
-			}
-
-			if r.Exact {
-				return v, nil
-			}
-
-			// we want to convert a non rounded double to int in a way that preserves
-			// comparison logic with the index. ex:
-			// a > 1.1  -> a >= 2; exclusive -> false
-			// a >= 1.1 -> a >= 2; exclusive -> false
-			// a < 1.1  -> a < 2;  exclusive -> true
-			// a <= 1.1 -> a < 2;  exclusive -> true
-			// a BETWEEN 1.1 AND 2.2 -> a >= 2 AND a <= 3; exclusive -> false
-
-			// First, we need to ceil the number. Ex: 1.1 -> 2
-			v = document.NewIntegerValue(int64(math.Ceil(f)))
-
-			// Next, we need to convert the boundaries
-			if isMin {
-				// (a > 1.1) or (a >= 1.1) must be transformed to (a >= 2)
-				r.Exclusive = false
-			} else {
-				// (a < 1.1) or (a <= 1.1) must be transformed to (a < 2)
-				// But there is an exception: if we are dealing with both min
-				// and max boundaries, we are operating a BETWEEN operation,
-				// meaning that we need to convert a BETWEEN 1.1 AND 2.2 to a >= 2 AND a <= 3,
-				// and thus have to set exclusive to false.
-				r.Exclusive = r.Min.Type.IsAny()
-			}
-		}
-
-		return v, nil
+		return convertBound(v, targetType, r.Exact, isMin, r.Min.Type.IsAny(), &r.Exclusive), nil
 	})
 	if err != nil {
 		return v, false, err
@@ -383,6 +401,11 @@ func (r *IndexRange) evalRange(index *database.Index, table *database.Table, env
 
 		var ok bool
 		for i := range rng.Min.Values {
+			ok, err = checkStrictBound(env, rng.Min.Values[i], index.Info.Types[i])
+			if err != nil || !ok {
+				return nil, ok, err
+			}
+
 			rng.Min.Values[i], ok, err = rng.Convert(rng.Min.Values[i], index.Info.Paths[i], index.Info.Types[i], true)
 			if err != nil || !ok {
 				return nil, ok, err
@@ -399,6 +422,11 @@ func (r *IndexRange) evalRange(index *database.Index, table *database.Table, env
 
 		var ok bool
 		for i := range rng.Max.Values {
+			ok, err = checkStrictBound(env, rng.Max.Values[i], index.Info.Types[i])
+			if err != nil || !ok {
+				return nil, ok, err
+			}
+
 			rng.Max.Values[i], ok, err = rng.Convert(rng.Max.Values[i], index.Info.Paths[i], index.Info.Types[i], false)
 			if err != nil || !ok {
 				return nil, ok, err
@@ -542,49 +570,9 @@ type encodedIndexRange struct {
 
 func (r *encodedIndexRange) Convert(v document.Value, p document.Path, t document.ValueType, isMin bool) (document.Value, bool, error) {
 	// ensure the operand satisfies all the constraints, index can work only on exact types.
-	// if a number is encountered, try to convert it to the right type if and only if the conversion
-	// is lossless.
+	// numbers are converted to the type of the key, see convertBound.
 	v, err := r.constraints.ConvertValueAtPath(p, v, func(v document.Value, path document.Path, targetType document.ValueType) (document.Value, error) {
-		if v.Type == document.IntegerValue && targetType == document.DoubleValue {
-			return v.CastAsDouble()
-		}
-
-		if v.Type == document.DoubleValue && targetType == document.IntegerValue {
-			f := v.V.(float64)
-			if float64(int64(f)) == f {
-				return v.CastAsInteger()
-			}
-
-			if r.Exact {
-				return v, nil
-			}
-
-			// we want to convert a non rounded double to int in a way that preserves
-			// comparison logic with the index. ex:
-			// a > 1.1  -> a >= 2; exclusive -> false
-			// a >= 1.1 -> a >= 2; exclusive -> false
-			// a < 1.1  -> a < 2;  exclusive -> true
-			// a <= 1.1 -> a < 2;  exclusive -> true
-			// a BETWEEN 1.1 AND 2.2 -> a >= 2 AND a <= 3; exclusive -> false
-
-			// First, we need to ceil the number. Ex: 1.1 -> 2
-			v = document.NewIntegerValue(int64(math.Ceil(f)))
-
-			// Next, we need to convert the boundaries
-			if isMin {
-				// (a > 1.1) or (a >= 1.1) must be transformed to (a >= 2)
-				r.Exclusive = false
-			} else {
-				// (a < 1.1) or (a <= 1.1) must be transformed to (a < 2)
-				// But there is an exception: if we are dealing with both min
-				// and max boundaries, we are operating a BETWEEN operation,
-				// meaning that we need to convert a BETWEEN 1.1 AND 2.2 to a >= 2 AND a <= 3,
-				// and thus have to set exclusive to false.
-				r.Exclusive = r.Min == nil || r.Min.Len() == 0
-			}
-		}
-
-		return v, nil
+		return convertBound(v, targetType, r.Exact, isMin, r.Min == nil || r.Min.Len() == 0, &r.Exclusive), nil
 	})
 	if err != nil {
 		return v, false, err
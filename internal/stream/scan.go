@@ -60,7 +60,8 @@ type ExprsOperator struct {
 }
 
 // Expressions creates an operator that iterates over the given expressions.
-// Each expression must evaluate to a document.
+// Each expression must evaluate to a document. Parameters can also evaluate
+// to an array of documents, in which case each document is returned.
 func Expressions(exprs ...expr.Expr) *ExprsOperator {
 	return &ExprsOperator{Exprs: exprs}
 }
@@ -74,12 +75,27 @@ func (op *ExprsOperator) Iterate(in *environment.Environment, fn func(out *envir
 		if err != nil {
 			return err
 		}
-		if v.Type != document.DocumentValue {
+
+		switch v.Type {
+		case document.DocumentValue:
+			newEnv.SetDocument(v.V.(document.Document))
+			err = fn(&newEnv)
+		case document.ArrayValue:
+			if !isParam(e) {
+				return ErrInvalidResult
+			}
+
+			err = v.V.(document.Array).Iterate(func(i int, v document.Value) error {
+				if v.Type != document.DocumentValue {
+					return ErrInvalidResult
+				}
+
+				newEnv.SetDocument(v.V.(document.Document))
+				return fn(&newEnv)
+			})
+		default:
 			return ErrInvalidResult
 		}
-
-		newEnv.SetDocument(v.V.(document.Document))
-		err = fn(&newEnv)
 		if err != nil {
 			return err
 		}
@@ -88,6 +104,15 @@ func (op *ExprsOperator) Iterate(in *environment.Environment, fn func(out *envir
 	return nil
 }
 
+func isParam(e expr.Expr) bool {
+	switch e.(type) {
+	case expr.PositionalParam, expr.NamedParam:
+		return true
+	}
+
+	return false
+}
+
 func (op *ExprsOperator) String() string {
 	var sb strings.Builder
 
@@ -108,6 +133,9 @@ type SeqScanOperator struct {
 	baseOperator
 	TableName string
 	Reverse   bool
+	// KeysOnly indicates that the next operators only need the keys
+	// of the documents, which are then read lazily.
+	KeysOnly bool
 }
 
 // SeqScan creates an iterator that iterates over each document of the given table.
@@ -129,14 +157,25 @@ func (it *SeqScanOperator) Iterate(in *environment.Environment, fn func(out *env
 	var newEnv environment.Environment
 	newEnv.SetOuter(in)
 
+	tracker := in.GetResourceTracker()
+
 	var iterator func(pivot document.Value, fn func(d document.Document) error) error
-	if !it.Reverse {
+	switch {
+	case it.KeysOnly:
+		iterator = func(pivot document.Value, fn func(d document.Document) error) error {
+			return table.IterateKeys(pivot, it.Reverse, fn)
+		}
+	case !it.Reverse:
 		iterator = table.AscendGreaterOrEqual
-	} else {
+	default:
 		iterator = table.DescendLessOrEqual
 	}
 
 	return iterator(document.Value{}, func(d document.Document) error {
+		if err := tracker.ScanDocument(); err != nil {
+			return err
+		}
+
 		newEnv.SetDocument(d)
 		return fn(&newEnv)
 	})
@@ -155,6 +194,9 @@ type PkScanOperator struct {
 	TableName string
 	Ranges    ValueRanges
 	Reverse   bool
+	// KeysOnly indicates that the next operators only need the keys
+	// of the documents, which are then read lazily.
+	KeysOnly bool
 }
 
 // PkScan creates an iterator that iterates over each document of the given table.
@@ -200,6 +242,7 @@ func (it *PkScanOperator) Iterate(in *environment.Environment, fn func(out *envi
 	if len(it.Ranges) == 0 {
 		s := SeqScan(it.TableName)
 		s.Reverse = it.Reverse
+		s.KeysOnly = it.KeysOnly
 		return s.Iterate(in, fn)
 	}
 
@@ -216,11 +259,18 @@ func (it *PkScanOperator) Iterate(in *environment.Environment, fn func(out *envi
 		return err
 	}
 
+	tracker := in.GetResourceTracker()
+
 	var iterator func(pivot document.Value, fn func(d document.Document) error) error
 
-	if !it.Reverse {
+	switch {
+	case it.KeysOnly:
+		iterator = func(pivot document.Value, fn func(d document.Document) error) error {
+			return table.IterateKeys(pivot, it.Reverse, fn)
+		}
+	case !it.Reverse:
 		iterator = table.AscendGreaterOrEqual
-	} else {
+	default:
 		iterator = table.DescendLessOrEqual
 	}
 
@@ -243,6 +293,10 @@ func (it *PkScanOperator) Iterate(in *environment.Environment, fn func(out *envi
 		}
 
 		err = iterator(start, func(d document.Document) error {
+			if err := tracker.ScanDocument(); err != nil {
+				return err
+			}
+
 			key := d.(document.Keyer).RawKey()
 
 			if !rng.IsInRange(key) {
@@ -284,6 +338,9 @@ type IndexScanOperator struct {
 	Ranges IndexRanges
 	// Reverse indicates the direction used to traverse the index.
 	Reverse bool
+	// KeysOnly indicates that the next operators only need the keys
+	// of the documents, which are then only fetched from the table if they are read.
+	KeysOnly bool
 }
 
 // IndexScan creates an iterator that iterates over each document of the given table.
@@ -334,22 +391,32 @@ func (it *IndexScanOperator) Iterate(in *environment.Environment, fn func(out *e
 	}
 
 	ranges, err := it.Ranges.EncodeBuffer(index, table, in)
-	if err != nil || len(ranges) != len(it.Ranges) {
+	if err != nil {
 		return err
 	}
+	// none of the ranges can select values of the index
+	if len(ranges) == 0 && len(it.Ranges) > 0 {
+		return nil
+	}
+
+	tracker := in.GetResourceTracker()
 
-	var iterator func(pivot database.Pivot, fn func(val, key []byte) error) error
+	var iterator func(pivot database.Pivot, bounds database.IndexBounds, fn func(val, key []byte) error) error
 
 	if !it.Reverse {
-		iterator = index.AscendGreaterOrEqual
+		iterator = index.AscendRange
 	} else {
-		iterator = index.DescendLessOrEqual
+		iterator = index.DescendRange
 	}
 
 	// if there are no ranges use a simpler and faster iteration function
 	if len(ranges) == 0 {
-		return iterator(nil, func(val, key []byte) error {
-			d, err := table.GetDocument(key)
+		return iterator(nil, database.IndexBounds{}, func(val, key []byte) error {
+			if err := tracker.ScanDocument(); err != nil {
+				return err
+			}
+
+			d, err := it.getDocument(table, key)
 			if err != nil {
 				return err
 			}
@@ -382,7 +449,13 @@ func (it *IndexScanOperator) Iterate(in *environment.Environment, fn func(out *e
 			pivot = start.Values
 		}
 
-		err = iterator(pivot, func(val, key []byte) error {
+		// let the engine skip the values that are out of the range
+		bounds := database.IndexBounds{Min: rng.EncodedMin, Max: rng.EncodedMax}
+		if rng.Exact {
+			bounds.Max = rng.EncodedMin
+		}
+
+		err = iterator(pivot, bounds, func(val, key []byte) error {
 			if !rng.IsInRange(val) {
 				// if we reached the end of our range, we can stop iterating.
 				if encEnd == nil {
@@ -399,7 +472,11 @@ func (it *IndexScanOperator) Iterate(in *environment.Environment, fn func(out *e
 				return nil
 			}
 
-			d, err := table.GetDocument(key)
+			if err := tracker.ScanDocument(); err != nil {
+				return err
+			}
+
+			d, err := it.getDocument(table, key)
 			if err != nil {
 				return err
 			}
@@ -418,3 +495,11 @@ func (it *IndexScanOperator) Iterate(in *environment.Environment, fn func(out *e
 
 	return nil
 }
+
+func (it *IndexScanOperator) getDocument(table *database.Table, key []byte) (document.Document, error) {
+	if it.KeysOnly {
+		return table.LazyDocument(key), nil
+	}
+
+	return table.GetDocument(key)
+}
@@ -0,0 +1,203 @@
+package stream
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/environment"
+	"github.com/genjidb/genji/internal/expr"
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// A TableFunctionBuilder builds the operator of a function used in place of a table.
+// The documents returned by the operator have a single field named after alias,
+// or after the function if alias is empty.
+type TableFunctionBuilder func(alias string, args ...expr.Expr) (Operator, error)
+
+// TableFunctions returns the functions that can be used in place of a table
+// in the FROM clause.
+func TableFunctions() map[string]TableFunctionBuilder {
+	return map[string]TableFunctionBuilder{
+		"generate_series": func(alias string, args ...expr.Expr) (Operator, error) {
+			if len(args) != 2 && len(args) != 3 {
+				return nil, stringutil.Errorf("generate_series() takes 2 or 3 arguments")
+			}
+			op := GenerateSeries(args[0], args[1], seriesStep(args))
+			if alias != "" {
+				op.FieldName = alias
+			}
+			return op, nil
+		},
+		"range": func(alias string, args ...expr.Expr) (Operator, error) {
+			if len(args) != 2 && len(args) != 3 {
+				return nil, stringutil.Errorf("range() takes 2 or 3 arguments")
+			}
+			op := Range(args[0], args[1], seriesStep(args))
+			if alias != "" {
+				op.FieldName = alias
+			}
+			return op, nil
+		},
+	}
+}
+
+func seriesStep(args []expr.Expr) expr.Expr {
+	if len(args) == 3 {
+		return args[2]
+	}
+
+	return nil
+}
+
+// A SeriesOperator generates a document per number between Start and Stop, separated by Step.
+// Each document has a single field named FieldName.
+type SeriesOperator struct {
+	baseOperator
+	Start, Stop, Step expr.Expr
+	// If set to true, Stop is excluded from the series.
+	Exclusive bool
+	FieldName string
+}
+
+// GenerateSeries creates an operator that generates the numbers from start to stop, inclusive.
+// If step is nil, it defaults to 1. The numbers are stored in the generate_series field.
+func GenerateSeries(start, stop, step expr.Expr) *SeriesOperator {
+	return &SeriesOperator{Start: start, Stop: stop, Step: step, FieldName: "generate_series"}
+}
+
+// Range creates an operator that generates the numbers from start to stop, exclusive.
+// If step is nil, it defaults to 1. The numbers are stored in the range field.
+func Range(start, stop, step expr.Expr) *SeriesOperator {
+	return &SeriesOperator{Start: start, Stop: stop, Step: step, Exclusive: true, FieldName: "range"}
+}
+
+// Iterate evaluates the bounds and the step of the series and generates its documents.
+// If all of them are integers, the series is made of integers, otherwise of doubles.
+// A negative step generates a decreasing series.
+func (op *SeriesOperator) Iterate(in *environment.Environment, fn func(out *environment.Environment) error) error {
+	exprs := []expr.Expr{op.Start, op.Stop, op.Step}
+	values := make([]document.Value, len(exprs))
+	isInteger := true
+
+	for i, e := range exprs {
+		if e == nil {
+			values[i] = document.NewIntegerValue(1)
+			continue
+		}
+
+		v, err := e.Eval(in)
+		if err != nil {
+			return err
+		}
+		// like any other function, a NULL argument returns nothing
+		if v.Type == document.NullValue {
+			return nil
+		}
+		if !v.Type.IsNumber() {
+			return stringutil.Errorf("%s: arguments must be numbers, got %s", op.name(), v)
+		}
+		if v.Type != document.IntegerValue {
+			isInteger = false
+		}
+		values[i] = v
+	}
+
+	var newEnv environment.Environment
+	newEnv.SetOuter(in)
+
+	emit := func(v document.Value) error {
+		newEnv.SetDocument(document.NewFieldBuffer().Add(op.FieldName, v))
+		return fn(&newEnv)
+	}
+
+	if isInteger {
+		start, stop, step := values[0].V.(int64), values[1].V.(int64), values[2].V.(int64)
+		if step == 0 {
+			return errors.New(op.name() + ": step cannot be zero")
+		}
+
+		for i := start; op.inSeries(i < stop, i == stop, i > stop, step > 0); i += step {
+			err := emit(document.NewIntegerValue(i))
+			if err != nil {
+				return err
+			}
+
+			// stop before overflowing
+			if (step > 0 && i > math.MaxInt64-step) || (step < 0 && i < math.MinInt64-step) {
+				return nil
+			}
+		}
+
+		return nil
+	}
+
+	var floats [3]float64
+	for i, v := range values {
+		v, err := v.CastAsDouble()
+		if err != nil {
+			return err
+		}
+		floats[i] = v.V.(float64)
+	}
+
+	start, stop, step := floats[0], floats[1], floats[2]
+	if step == 0 {
+		return errors.New(op.name() + ": step cannot be zero")
+	}
+
+	// compute each value from the start to avoid accumulating rounding errors
+	for n := 0; ; n++ {
+		f := start + float64(n)*step
+		if !op.inSeries(f < stop, f == stop, f > stop, step > 0) {
+			return nil
+		}
+
+		err := emit(document.NewDoubleValue(f))
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// inSeries reports whether a value, compared to the upper bound, belongs to the series.
+func (op *SeriesOperator) inSeries(lt, eq, gt, increasing bool) bool {
+	if eq {
+		return !op.Exclusive
+	}
+	if increasing {
+		return lt
+	}
+
+	return gt
+}
+
+func (op *SeriesOperator) name() string {
+	if op.Exclusive {
+		return "range"
+	}
+
+	return "generate_series"
+}
+
+func (op *SeriesOperator) String() string {
+	var sb strings.Builder
+
+	sb.WriteString(op.name())
+	sb.WriteString("(")
+	sb.WriteString(op.Start.String())
+	sb.WriteString(", ")
+	sb.WriteString(op.Stop.String())
+	if op.Step != nil {
+		sb.WriteString(", ")
+		sb.WriteString(op.Step.String())
+	}
+	sb.WriteString(")")
+	if op.FieldName != op.name() {
+		sb.WriteString(" AS ")
+		sb.WriteString(op.FieldName)
+	}
+
+	return sb.String()
+}
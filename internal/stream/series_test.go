@@ -0,0 +1,65 @@
+package stream_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji/internal/environment"
+	"github.com/genjidb/genji/internal/sql/parser"
+	"github.com/genjidb/genji/internal/stream"
+	"github.com/genjidb/genji/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeries(t *testing.T) {
+	e := parser.MustParseExpr
+
+	tests := []struct {
+		name     string
+		op       *stream.SeriesOperator
+		expected testutil.Docs
+		fails    bool
+	}{
+		{"generate_series", stream.GenerateSeries(e("1"), e("3"), nil),
+			testutil.MakeDocuments(t, `{"generate_series": 1}`, `{"generate_series": 2}`, `{"generate_series": 3}`), false},
+		{"range", stream.Range(e("1"), e("3"), nil),
+			testutil.MakeDocuments(t, `{"range": 1}`, `{"range": 2}`), false},
+		{"step", stream.GenerateSeries(e("0"), e("10"), e("4")),
+			testutil.MakeDocuments(t, `{"generate_series": 0}`, `{"generate_series": 4}`, `{"generate_series": 8}`), false},
+		{"negative step", stream.Range(e("3"), e("0"), e("-1")),
+			testutil.MakeDocuments(t, `{"range": 3}`, `{"range": 2}`, `{"range": 1}`), false},
+		{"doubles", stream.GenerateSeries(e("0"), e("1"), e("0.5")),
+			testutil.MakeDocuments(t, `{"generate_series": 0.0}`, `{"generate_series": 0.5}`, `{"generate_series": 1.0}`), false},
+		{"empty", stream.GenerateSeries(e("3"), e("1"), nil), nil, false},
+		{"overflow", stream.GenerateSeries(e("9223372036854775806"), e("9223372036854775807"), e("10")),
+			testutil.MakeDocuments(t, `{"generate_series": 9223372036854775806}`), false},
+		{"null", stream.GenerateSeries(e("1"), e("NULL"), nil), nil, false},
+		{"zero step", stream.GenerateSeries(e("1"), e("3"), e("0")), nil, true},
+		{"text", stream.GenerateSeries(e("1"), e("'a'"), nil), nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got testutil.Docs
+			err := stream.New(test.op).Iterate(new(environment.Environment), func(env *environment.Environment) error {
+				d, ok := env.GetDocument()
+				require.True(t, ok)
+				got = append(got, d)
+				return nil
+			})
+			if test.fails {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			test.expected.RequireEqual(t, got)
+		})
+	}
+
+	t.Run("String", func(t *testing.T) {
+		op := stream.Range(e("1"), e("10"), e("2"))
+		require.Equal(t, "range(1, 10, 2)", op.String())
+		op.FieldName = "n"
+		require.Equal(t, "range(1, 10, 2) AS n", op.String())
+		require.Equal(t, "generate_series(1, 10)", stream.GenerateSeries(e("1"), e("10"), nil).String())
+	})
+}
@@ -26,12 +26,30 @@ func (s *Stream) Pipe(op Operator) *Stream {
 	return s
 }
 
+// Iterate runs the stream and calls fn for every value it outputs.
+// If the transaction is unprivileged, the documents read from a table
+// with field masks are masked before being passed to fn.
 func (s *Stream) Iterate(in *environment.Environment, fn func(out *environment.Environment) error) error {
 	if s.Op == nil {
 		return nil
 	}
 
-	return s.Op.Iterate(in, fn)
+	mask, err := newFieldMasker(in, s.Op)
+	if err != nil {
+		return err
+	}
+	if mask == nil {
+		return s.Op.Iterate(in, fn)
+	}
+
+	return s.Op.Iterate(in, func(out *environment.Environment) error {
+		out, err := mask(out)
+		if err != nil {
+			return err
+		}
+
+		return fn(out)
+	})
 }
 
 func (s *Stream) Remove(op Operator) {
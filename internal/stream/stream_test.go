@@ -32,3 +32,56 @@ func TestStream(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, int64(1), count)
 }
+
+func TestStreamPlan(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        *stream.Stream
+		expected string
+	}{
+		{"empty", &stream.Stream{}, `[]`},
+		{
+			"seqScan",
+			stream.New(stream.SeqScan("foo")).
+				Pipe(stream.Filter(parser.MustParseExpr("a > 1"))).
+				Pipe(stream.Project(parser.MustParseExpr("a"), parser.MustParseExpr("b + 1"))).
+				Pipe(stream.Take(10)),
+			`[
+				{"operator": "seqScan", "args": ["foo"]},
+				{"operator": "filter", "args": ["a > 1"]},
+				{"operator": "project", "args": ["a", "b + 1"]},
+				{"operator": "take", "args": ["10"]}
+			]`,
+		},
+		{
+			"indexScan",
+			stream.New(stream.IndexScanReverse("idx_foo_a", stream.IndexRange{Min: testutil.ExprList(t, `[1]`), Exact: true})).
+				Pipe(stream.Distinct()),
+			`[
+				{"operator": "indexScanReverse", "args": ["idx_foo_a", "1"], "estimates": {"cost": 1}},
+				{"operator": "distinct", "args": []}
+			]`,
+		},
+		{
+			"concat",
+			stream.New(stream.Concat(
+				stream.New(stream.SeqScan("foo")),
+				stream.New(stream.PkScan("bar", stream.ValueRange{Min: testutil.IntegerValue(1)})),
+			)),
+			`[
+				{"operator": "concat", "args": [], "streams": [
+					[{"operator": "seqScan", "args": ["foo"]}],
+					[{"operator": "pkScan", "args": ["bar", "[1, -1]"], "estimates": {"cost": 100}}]
+				]}
+			]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b, err := test.s.MarshalJSON()
+			require.NoError(t, err)
+			require.JSONEq(t, test.expected, string(b))
+		})
+	}
+}
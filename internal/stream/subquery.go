@@ -0,0 +1,26 @@
+package stream
+
+import (
+	"github.com/genjidb/genji/internal/environment"
+)
+
+// A SubqueryStream is the stream of a subquery expression.
+// The planner optimizes it along with the statement using the subquery.
+type SubqueryStream struct {
+	Stream *Stream
+}
+
+// Iterate implements the expr.Streamer interface.
+// Unlike Stream.Iterate, it returns nil if the stream is closed early.
+func (s *SubqueryStream) Iterate(in *environment.Environment, fn func(out *environment.Environment) error) error {
+	err := s.Stream.Iterate(in, fn)
+	if err == ErrStreamClosed {
+		return nil
+	}
+
+	return err
+}
+
+func (s *SubqueryStream) String() string {
+	return s.Stream.String()
+}
@@ -0,0 +1,68 @@
+package stream
+
+import (
+	"sort"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/environment"
+)
+
+// A TablesStatsOperator generates a document per table of the database,
+// describing the space it uses. It is used to read the virtual table
+// named database.TablesStatsTableName.
+type TablesStatsOperator struct {
+	baseOperator
+}
+
+// TablesStats creates a TablesStatsOperator.
+func TablesStats() *TablesStatsOperator {
+	return &TablesStatsOperator{}
+}
+
+// Iterate reads the statistics of every table, ordered by name. Each document contains
+// the name of the table, its number of documents, the number of bytes used by the table
+// and by its indexes, and the average size of its documents, or NULL if it is empty.
+func (op *TablesStatsOperator) Iterate(in *environment.Environment, fn func(out *environment.Environment) error) error {
+	catalog := in.GetCatalog()
+	tx := in.GetTx()
+
+	names := catalog.ListTables()
+	sort.Strings(names)
+
+	var newEnv environment.Environment
+	newEnv.SetOuter(in)
+
+	for _, name := range names {
+		tb, err := catalog.GetTable(tx, name)
+		if err != nil {
+			return err
+		}
+
+		stats, err := tb.Stats()
+		if err != nil {
+			return err
+		}
+
+		avg := document.NewNullValue()
+		if stats.Documents > 0 {
+			avg = document.NewDoubleValue(float64(stats.Size) / float64(stats.Documents))
+		}
+
+		newEnv.SetDocument(document.NewFieldBuffer().
+			Add("table_name", document.NewTextValue(name)).
+			Add("documents", document.NewIntegerValue(stats.Documents)).
+			Add("size", document.NewIntegerValue(stats.Size)).
+			Add("index_size", document.NewIntegerValue(stats.IndexSize)).
+			Add("avg_document_size", avg))
+		err = fn(&newEnv)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (op *TablesStatsOperator) String() string {
+	return "tablesStats()"
+}
@@ -0,0 +1,139 @@
+package stream
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/document/encoding"
+	"github.com/genjidb/genji/internal/environment"
+)
+
+// spilledValue is the value associated with keys stored
+// in a temporary store when only the key matters.
+// Some engines don't support empty values.
+var spilledValue = []byte{0}
+
+// envDepth returns the number of environments between env and in.
+func envDepth(in, env *environment.Environment) int {
+	var n int
+	for e := env; e != nil && e != in; e = e.GetOuter() {
+		n++
+	}
+
+	return n
+}
+
+// getCodec returns the codec used to encode spilled environments.
+func getCodec(in *environment.Environment) (encoding.Codec, error) {
+	tx := in.GetTx()
+	if tx == nil {
+		return nil, errors.New("missing transaction")
+	}
+
+	return tx.Codec, nil
+}
+
+// encodeEnv encodes the documents and variables of the depth first
+// environments of env, so that they can be written to a temporary store.
+func encodeEnv(codec encoding.Codec, env *environment.Environment, depth int) ([]byte, error) {
+	var envs document.ValueBuffer
+	for i := 0; i < depth && env != nil; i, env = i+1, env.GetOuter() {
+		fb := document.NewFieldBuffer()
+		if env.Doc != nil {
+			fb.Add("doc", document.NewDocumentValue(env.Doc))
+		}
+		if env.Vars != nil {
+			fb.Add("vars", document.NewDocumentValue(env.Vars))
+		}
+
+		envs.Append(document.NewDocumentValue(fb))
+	}
+
+	var buf bytes.Buffer
+	enc := codec.NewEncoder(&buf)
+	defer enc.Close()
+
+	err := enc.EncodeDocument(document.NewFieldBuffer().Add("envs", document.NewArrayValue(&envs)))
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decodeEnv decodes environments encoded by encodeEnv.
+// The outermost decoded environment has in as outer environment.
+func decodeEnv(codec encoding.Codec, in *environment.Environment, data []byte) (*environment.Environment, error) {
+	v, err := codec.NewDecoder(data).GetByField("envs")
+	if err != nil {
+		return nil, err
+	}
+
+	var envs []*environment.Environment
+	err = v.V.(document.Array).Iterate(func(i int, v document.Value) error {
+		var env environment.Environment
+
+		d := v.V.(document.Document)
+		doc, err := copyField(d, "doc")
+		if err != nil {
+			return err
+		}
+		if doc != nil {
+			env.Doc = doc
+		}
+
+		env.Vars, err = copyField(d, "vars")
+		if err != nil {
+			return err
+		}
+
+		envs = append(envs, &env)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(envs) == 0 {
+		return in, nil
+	}
+
+	for i := 0; i < len(envs)-1; i++ {
+		envs[i].SetOuter(envs[i+1])
+	}
+	envs[len(envs)-1].SetOuter(in)
+
+	return envs[0], nil
+}
+
+// copyField returns a copy of the document stored in the given field of d,
+// or nil if the field doesn't exist.
+func copyField(d document.Document, field string) (*document.FieldBuffer, error) {
+	v, err := d.GetByField(field)
+	if err == document.ErrFieldNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fb := document.NewFieldBuffer()
+	err = fb.Copy(v.V.(document.Document))
+	if err != nil {
+		return nil, err
+	}
+
+	return fb, nil
+}
+
+// sortKey returns the key of an environment sorted by value.
+// The sequence number keeps keys unique and preserves the
+// insertion order of equal values.
+func sortKey(value []byte, seq uint64) []byte {
+	k := make([]byte, len(value)+8)
+	copy(k, value)
+	binary.BigEndian.PutUint64(k[len(value):], seq)
+	return k
+}
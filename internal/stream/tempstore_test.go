@@ -0,0 +1,136 @@
+package stream_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/document/encoding/msgpack"
+	"github.com/genjidb/genji/engine/memoryengine"
+	errs "github.com/genjidb/genji/errors"
+	"github.com/genjidb/genji/internal/catalog"
+	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/environment"
+	"github.com/genjidb/genji/internal/sql/parser"
+	"github.com/genjidb/genji/internal/stream"
+	"github.com/genjidb/genji/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newSpillTestTx(t *testing.T, budget int64) (*database.Database, *database.Transaction, func()) {
+	t.Helper()
+
+	db, err := database.New(context.Background(), memoryengine.NewEngine(), database.Options{
+		Codec:        msgpack.NewCodec(),
+		Catalog:      catalog.New(),
+		MemoryBudget: budget,
+	})
+	require.NoError(t, err)
+
+	tx, err := db.Begin(true)
+	require.NoError(t, err)
+
+	return db, tx, func() {
+		tx.Rollback()
+		db.Close()
+	}
+}
+
+func TestSpill(t *testing.T) {
+	var docs testutil.Docs
+	for i := 0; i < 20; i++ {
+		docs = append(docs, document.NewFieldBuffer().
+			Add("a", document.NewIntegerValue(int64(i%7))).
+			Add("b", document.NewIntegerValue(int64(i))))
+	}
+
+	// run the operator with and without a memory budget
+	// and return the values of a
+	run := func(t *testing.T, docs testutil.Docs, op stream.Operator, budget int64) []int64 {
+		t.Helper()
+
+		db, tx, cleanup := newSpillTestTx(t, budget)
+		defer cleanup()
+
+		in := &environment.Environment{}
+		in.Tx = tx
+		in.Catalog = db.Catalog
+		in.Tracker = db.NewResourceTracker()
+
+		var got []int64
+		err := stream.New(stream.Documents(docs...)).Pipe(op).Iterate(in, func(out *environment.Environment) error {
+			d, ok := out.GetDocument()
+			require.True(t, ok)
+
+			v, err := d.GetByField("a")
+			require.NoError(t, err)
+			got = append(got, v.V.(int64))
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, in.Tracker.Close())
+		require.Zero(t, db.Memory().Used())
+		return got
+	}
+
+	var dups testutil.Docs
+	for i := 0; i < 20; i++ {
+		dups = append(dups, document.NewFieldBuffer().Add("a", document.NewIntegerValue(int64(i%7))))
+	}
+
+	tests := []struct {
+		name string
+		docs testutil.Docs
+		op   func() stream.Operator
+	}{
+		{"Sort", docs, func() stream.Operator { return stream.Sort(parser.MustParseExpr("a")) }},
+		{"SortReverse", docs, func() stream.Operator { return stream.SortReverse(parser.MustParseExpr("a")) }},
+		{"Distinct", dups, func() stream.Operator { return stream.Distinct() }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			want := run(t, test.docs, test.op(), 0)
+			got := run(t, test.docs, test.op(), 64)
+			require.Equal(t, want, got)
+		})
+	}
+
+	t.Run("Query", func(t *testing.T) {
+		db, tx, cleanup := newSpillTestTx(t, 64)
+		defer cleanup()
+
+		testutil.MustExec(t, db, tx, "CREATE TABLE test(a INTEGER, b INTEGER)")
+		for _, d := range docs {
+			testutil.MustExec(t, db, tx, "INSERT INTO test VALUES ?", environment.Param{Value: d})
+		}
+
+		// sorting by a field that is not projected requires
+		// restoring the outer environments of spilled documents
+		res := testutil.MustQuery(t, db, tx, "SELECT b FROM test ORDER BY a DESC LIMIT 2")
+		defer res.Close()
+
+		var got []int64
+		err := res.Iterate(func(d document.Document) error {
+			v, err := d.GetByField("b")
+			require.NoError(t, err)
+			got = append(got, v.V.(int64))
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		for _, b := range got {
+			require.Equal(t, int64(6), b%7)
+		}
+		require.Zero(t, db.Memory().Used())
+
+		// aggregators cannot be spilled
+		res2, err := testutil.Query(db, tx, "SELECT COUNT(*) FROM test GROUP BY b")
+		if err == nil {
+			err = res2.Iterate(func(d document.Document) error { return nil })
+			res2.Close()
+		}
+		require.True(t, errs.IsLimitExceededError(err), "expected limit error, got %v", err)
+	})
+}
@@ -0,0 +1,42 @@
+package stream
+
+import (
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/environment"
+)
+
+// A ThrottleStatsOperator generates a single document describing
+// the state of the throttle of the database. It is used to read the virtual table
+// named database.ThrottleStatsTableName.
+type ThrottleStatsOperator struct {
+	baseOperator
+}
+
+// ThrottleStats creates a ThrottleStatsOperator.
+func ThrottleStats() *ThrottleStatsOperator {
+	return &ThrottleStatsOperator{}
+}
+
+// Iterate reads the state of the throttle. The document contains the limits,
+// the number of statements running or waiting, and the number of statements
+// delayed since the database was opened.
+func (op *ThrottleStatsOperator) Iterate(in *environment.Environment, fn func(out *environment.Environment) error) error {
+	stats := in.GetTx().Throttle.Stats()
+
+	var newEnv environment.Environment
+	newEnv.SetOuter(in)
+	newEnv.SetDocument(document.NewFieldBuffer().
+		Add("max_concurrent_statements", document.NewIntegerValue(int64(stats.MaxConcurrentStatements))).
+		Add("max_writes_per_second", document.NewIntegerValue(int64(stats.MaxWritesPerSecond))).
+		Add("running_statements", document.NewIntegerValue(stats.RunningStatements)).
+		Add("waiting_statements", document.NewIntegerValue(stats.WaitingStatements)).
+		Add("waiting_writes", document.NewIntegerValue(stats.WaitingWrites)).
+		Add("throttled_statements", document.NewIntegerValue(stats.ThrottledStatements)).
+		Add("throttled_writes", document.NewIntegerValue(stats.ThrottledWrites)))
+
+	return fn(&newEnv)
+}
+
+func (op *ThrottleStatsOperator) String() string {
+	return "throttleStats()"
+}
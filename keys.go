@@ -0,0 +1,35 @@
+package genji
+
+import (
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// A KeyProvider returns the keys used by the encrypt and decrypt SQL functions,
+// which encrypt individual values, e.g.:
+//
+//	INSERT INTO users (name, ssn) VALUES ('alice', encrypt('123-45-6789', 'users'))
+//	SELECT name, decrypt(ssn) AS ssn FROM users
+type KeyProvider interface {
+	// Key returns the key with the given id.
+	// It must be 16, 24 or 32 bytes long, to select AES-128, AES-192 or AES-256.
+	Key(id string) ([]byte, error)
+}
+
+// KeyMap is a KeyProvider returning the keys it contains.
+type KeyMap map[string][]byte
+
+// Key returns the key with the given id. It implements the KeyProvider interface.
+func (m KeyMap) Key(id string) ([]byte, error) {
+	k, ok := m[id]
+	if !ok {
+		return nil, stringutil.Errorf("unknown key %q", id)
+	}
+
+	return k, nil
+}
+
+// SetKeyProvider sets the provider of the keys used by the encrypt and decrypt functions.
+// It applies to the transactions started after this call.
+func (db *DB) SetKeyProvider(p KeyProvider) {
+	db.db.SetKeyProvider(p)
+}
@@ -0,0 +1,70 @@
+package genji_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec("CREATE TABLE users(name TEXT, ssn BLOB)")
+	require.NoError(t, err)
+
+	// no key provider
+	err = db.Exec("INSERT INTO users (name, ssn) VALUES ('alice', encrypt('123-45-6789', 'k1'))")
+	require.Error(t, err)
+
+	db.SetKeyProvider(genji.KeyMap{
+		"k1": bytes.Repeat([]byte{1}, 32),
+		"k2": bytes.Repeat([]byte{2}, 16),
+	})
+
+	err = db.Exec(`INSERT INTO users (name, ssn) VALUES
+		('alice', encrypt('123-45-6789', 'k1')),
+		('bob', encrypt({a: [1, 2.5]}, 'k2')),
+		('carol', encrypt(NULL, 'k1'))`)
+	require.NoError(t, err)
+
+	// values are stored encrypted
+	d, err := db.QueryDocument("SELECT ssn FROM users WHERE name = 'alice'")
+	require.NoError(t, err)
+	v, err := d.GetByField("ssn")
+	require.NoError(t, err)
+	require.Equal(t, document.BlobValue, v.Type)
+	require.NotContains(t, string(v.V.([]byte)), "123-45-6789")
+
+	res, err := db.Query("SELECT name, decrypt(ssn) AS ssn FROM users")
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	err = testutil.IteratorToJSONArray(&buf, res)
+	require.NoError(t, err)
+	require.NoError(t, res.Close())
+	require.JSONEq(t, `[
+		{"name": "alice", "ssn": "123-45-6789"},
+		{"name": "bob", "ssn": {"a": [1, 2.5]}},
+		{"name": "carol", "ssn": null}
+	]`, buf.String())
+
+	// unknown key
+	_, err = db.QueryDocument("SELECT encrypt('a', 'k3')")
+	require.Error(t, err)
+
+	// the encrypted value is authenticated
+	err = db.Exec("UPDATE users SET ssn = substr_blob(ssn, 1, blob_length(ssn) - 1) WHERE name = 'alice'")
+	require.NoError(t, err)
+	_, err = db.QueryDocument("SELECT decrypt(ssn) FROM users WHERE name = 'alice'")
+	require.Error(t, err)
+
+	// a key change makes the values unreadable
+	db.SetKeyProvider(genji.KeyMap{"k2": bytes.Repeat([]byte{3}, 16)})
+	_, err = db.QueryDocument("SELECT decrypt(ssn) FROM users WHERE name = 'bob'")
+	require.Error(t, err)
+}
@@ -0,0 +1,21 @@
+package genji
+
+import "github.com/genjidb/genji/internal/sql/scanner"
+
+// NormalizeQuery returns the canonical form of q, which is shared by the queries
+// only differing by their literal values, parameters, comments, spacing or the case
+// of their keywords. Literal values and parameters are replaced by ?, e.g.
+//   select * from foo where a = 1 and b = $b;
+// becomes
+//   SELECT * FROM foo WHERE a = ? AND b = ?
+// The query is not parsed, only its tokens must be valid.
+func NormalizeQuery(q string) (string, error) {
+	return scanner.Normalize(q)
+}
+
+// QueryFingerprint returns a short hash of the normalized form of q,
+// as returned by NormalizeQuery. It doesn't depend on the process computing it,
+// and can be used to group the queries sharing the same form.
+func QueryFingerprint(q string) (string, error) {
+	return scanner.Fingerprint(q)
+}
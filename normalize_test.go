@@ -0,0 +1,23 @@
+package genji_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeQuery(t *testing.T) {
+	n, err := genji.NormalizeQuery("select * from foo\n  where a = 1 and b = $b;")
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM foo WHERE a = ? AND b = ?", n)
+
+	f1, err := genji.QueryFingerprint("select * from foo where a = 1")
+	require.NoError(t, err)
+	f2, err := genji.QueryFingerprint("SELECT * FROM foo WHERE a = 'x'")
+	require.NoError(t, err)
+	require.Equal(t, f1, f2)
+
+	_, err = genji.NormalizeQuery("SELECT 'unterminated")
+	require.Error(t, err)
+}
@@ -1,4 +1,4 @@
-// +build !wasm
+// +build !wasm,!plan9,!loong64
 
 package genji
 
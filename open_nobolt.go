@@ -0,0 +1,34 @@
+// +build plan9 loong64
+// +build !wasm
+
+package genji
+
+import (
+	"context"
+
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/engine/fileengine"
+	"github.com/genjidb/genji/engine/memoryengine"
+)
+
+// Open creates a Genji database at the given path.
+// If path is equal to ":memory:" it will open an in-memory database,
+// otherwise it will create an on-disk database using the file engine,
+// since BoltDB doesn't support this platform.
+func Open(path string) (*DB, error) {
+	var ng engine.Engine
+	var err error
+
+	switch path {
+	case ":memory:":
+		ng = memoryengine.NewEngine()
+	default:
+		ng, err = fileengine.NewEngine(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	return New(ctx, ng)
+}
@@ -0,0 +1,33 @@
+package genji
+
+import (
+	"time"
+)
+
+// Process describes a query being run by the database.
+// A query is running from the moment it starts until its result is closed.
+type Process struct {
+	ID int64
+	// SQL text of the query.
+	SQL       string
+	StartedAt time.Time
+}
+
+// Processes returns the queries currently running, ordered by ID.
+func (db *DB) Processes() []Process {
+	procs := db.db.Processes()
+
+	list := make([]Process, len(procs))
+	for i, p := range procs {
+		list[i] = Process{ID: p.ID, SQL: p.SQL, StartedAt: p.StartedAt}
+	}
+
+	return list
+}
+
+// Kill cancels the query run by the selected process.
+// The query fails with context.Canceled the next time it reads a document.
+// It can also be done with the KILL statement.
+func (db *DB) Kill(id int64) error {
+	return db.db.Kill(id)
+}
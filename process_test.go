@@ -0,0 +1,79 @@
+package genji_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKill(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec("CREATE TABLE foo; INSERT INTO foo SELECT * FROM generate_series(1, 100)")
+	require.NoError(t, err)
+
+	const q = "SELECT * FROM foo"
+
+	find := func() int64 {
+		for _, p := range db.Processes() {
+			if p.SQL == q {
+				return p.ID
+			}
+		}
+		return 0
+	}
+
+	t.Run("Kill", func(t *testing.T) {
+		res, err := db.Query(q)
+		require.NoError(t, err)
+
+		var n int
+		err = res.Iterate(func(d document.Document) error {
+			n++
+			if n == 10 {
+				id := find()
+				require.NotZero(t, id)
+				return db.Kill(id)
+			}
+			return nil
+		})
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, 10, n)
+		require.NoError(t, res.Close())
+
+		// the process is removed once the result is closed
+		require.Zero(t, find())
+	})
+
+	t.Run("KILL statement", func(t *testing.T) {
+		tx, err := db.Begin(false)
+		require.NoError(t, err)
+		defer tx.Rollback()
+
+		res, err := tx.Query(q)
+		require.NoError(t, err)
+		defer res.Close()
+
+		err = res.Iterate(func(d document.Document) error {
+			err := db.Exec(fmt.Sprintf("KILL %d", find()))
+			require.NoError(t, err)
+			return nil
+		})
+		require.ErrorIs(t, err, context.Canceled)
+
+		// the transaction can still be used
+		_, err = tx.QueryDocument("SELECT COUNT(*) FROM foo")
+		require.NoError(t, err)
+	})
+
+	t.Run("Unknown process", func(t *testing.T) {
+		require.Error(t, db.Kill(1000))
+		require.Error(t, db.Exec("KILL 1000"))
+	})
+}
@@ -0,0 +1,93 @@
+package genji
+
+import (
+	"time"
+)
+
+// DefaultReIndexBatchSize is the number of documents indexed per transaction
+// by ReIndex if no batch size is specified.
+const DefaultReIndexBatchSize = 1000
+
+// ReIndexOptions configures ReIndex.
+type ReIndexOptions struct {
+	// Number of documents indexed per transaction.
+	// If zero, DefaultReIndexBatchSize is used.
+	BatchSize int
+	// Maximum number of documents indexed per second, to limit the load
+	// caused on the database. Zero means no limit.
+	Rate int
+	// Key from which to resume an interrupted reindexing, as reported by
+	// ReIndexProgress.Next. If nil, the index is rebuilt from scratch.
+	Resume []byte
+	// Called after every batch. If it returns an error, ReIndex stops
+	// and returns it. The reindexing can then be resumed using the last key reported.
+	Progress func(p ReIndexProgress) error
+}
+
+// ReIndexProgress reports the progress of a ReIndex call.
+type ReIndexProgress struct {
+	IndexName string
+	// Number of documents indexed so far by this call.
+	Indexed int64
+	// Key of the next document to index, nil once the index is complete.
+	Next []byte
+}
+
+// ReIndex rebuilds the selected index in batches, each one in its own transaction,
+// instead of holding a single transaction for the whole table like the REINDEX statement.
+// While it runs, the index is incomplete and queries using it may miss documents.
+// It stops when the context of the database is canceled, and can be resumed
+// using ReIndexOptions.Resume.
+func (db *DB) ReIndex(indexName string, opts *ReIndexOptions) error {
+	var o ReIndexOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = DefaultReIndexBatchSize
+	}
+
+	start := time.Now()
+	progress := ReIndexProgress{IndexName: indexName, Next: o.Resume}
+	for {
+		if err := db.ctx.Err(); err != nil {
+			return err
+		}
+
+		var next []byte
+		var n int
+		err := db.RunInTx(func(tx *Tx) error {
+			var err error
+			next, n, err = db.db.Catalog.ReIndexBatch(tx.tx, indexName, progress.Next, o.BatchSize)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		progress.Indexed += int64(n)
+		progress.Next = next
+		if o.Progress != nil {
+			err = o.Progress(progress)
+			if err != nil {
+				return err
+			}
+		}
+
+		if next == nil {
+			return nil
+		}
+
+		if o.Rate > 0 {
+			// wait until the average rate goes below the limit
+			wait := time.Duration(progress.Indexed)*time.Second/time.Duration(o.Rate) - time.Since(start)
+			if wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-db.ctx.Done():
+					return db.ctx.Err()
+				}
+			}
+		}
+	}
+}
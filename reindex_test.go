@@ -0,0 +1,111 @@
+package genji_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReIndex(t *testing.T) {
+	newDB := func(t *testing.T) *genji.DB {
+		db, err := genji.Open(":memory:")
+		require.NoError(t, err)
+
+		err = db.Exec("CREATE TABLE foo(a INTEGER PRIMARY KEY, b INTEGER UNIQUE)")
+		require.NoError(t, err)
+		for i := 0; i < 25; i++ {
+			err = db.Exec("INSERT INTO foo (a, b) VALUES (?, ?)", i, i*10)
+			require.NoError(t, err)
+		}
+
+		return db
+	}
+
+	count := func(t *testing.T, db *genji.DB) int64 {
+		d, err := db.QueryDocument("SELECT COUNT(*) AS n FROM foo WHERE b >= 0")
+		require.NoError(t, err)
+		v, err := d.GetByField("n")
+		require.NoError(t, err)
+		return v.V.(int64)
+	}
+
+	t.Run("Batches", func(t *testing.T) {
+		db := newDB(t)
+		defer db.Close()
+
+		var reports []genji.ReIndexProgress
+		err := db.ReIndex("foo_b_idx", &genji.ReIndexOptions{
+			BatchSize: 10,
+			Progress: func(p genji.ReIndexProgress) error {
+				reports = append(reports, p)
+				return nil
+			},
+		})
+		require.NoError(t, err)
+
+		require.Len(t, reports, 3)
+		require.Equal(t, int64(10), reports[0].Indexed)
+		require.NotNil(t, reports[0].Next)
+		require.Equal(t, int64(25), reports[2].Indexed)
+		require.Nil(t, reports[2].Next)
+		require.Equal(t, int64(25), count(t, db))
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		db := newDB(t)
+		defer db.Close()
+
+		errStop := errors.New("stop")
+		var next []byte
+		err := db.ReIndex("foo_b_idx", &genji.ReIndexOptions{
+			BatchSize: 10,
+			Progress: func(p genji.ReIndexProgress) error {
+				next = p.Next
+				return errStop
+			},
+		})
+		require.Equal(t, errStop, err)
+
+		// the incomplete index is not used by queries
+		require.Equal(t, int64(25), count(t, db))
+
+		// documents written between two batches are indexed by the write itself
+		err = db.Exec("INSERT INTO foo (a, b) VALUES (100, 1000)")
+		require.NoError(t, err)
+		err = db.Exec("UPDATE foo SET b = b + 1 WHERE a = 20")
+		require.NoError(t, err)
+
+		err = db.ReIndex("foo_b_idx", &genji.ReIndexOptions{BatchSize: 10, Resume: next})
+		require.NoError(t, err)
+		require.Equal(t, int64(26), count(t, db))
+
+		d, err := db.QueryDocument("SELECT a FROM foo WHERE b = 201")
+		require.NoError(t, err)
+		v, err := d.GetByField("a")
+		require.NoError(t, err)
+		require.Equal(t, document.NewIntegerValue(20), v)
+	})
+
+	t.Run("Rate", func(t *testing.T) {
+		db := newDB(t)
+		defer db.Close()
+
+		start := time.Now()
+		err := db.ReIndex("foo_b_idx", &genji.ReIndexOptions{BatchSize: 5, Rate: 100})
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, int64(time.Since(start)), int64(200*time.Millisecond))
+		require.Equal(t, int64(25), count(t, db))
+	})
+
+	t.Run("Unknown index", func(t *testing.T) {
+		db := newDB(t)
+		defer db.Close()
+
+		err := db.ReIndex("unknown", nil)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,225 @@
+package genji
+
+import (
+	"strings"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/query/statement"
+	"github.com/genjidb/genji/internal/sql/parser"
+	"github.com/genjidb/genji/internal/stringutil"
+)
+
+// Schema returns the structure of the database as a document with the following fields:
+//   - tables: the list of tables, with their name, their field constraints and their SQL definition
+//   - indexes: the list of indexes, with their name, table, paths, uniqueness and SQL definition
+//   - sequences: the list of sequences, with their name, configuration and SQL definition
+//
+// Indexes and sequences created implicitly by a table, i.e. by a UNIQUE constraint,
+// are described by the table only.
+// The returned document can be passed to ApplySchema to create the same structure
+// in another database.
+func (db *DB) Schema() (document.Document, error) {
+	var fb document.FieldBuffer
+
+	err := db.View(func(tx *Tx) error {
+		catalog := db.db.Catalog
+
+		tables := document.NewValueBuffer()
+		for _, name := range catalog.ListTables() {
+			ti, err := catalog.GetTableInfo(name)
+			if err != nil {
+				return err
+			}
+
+			tables.Append(document.NewDocumentValue(tableSchema(ti)))
+		}
+
+		indexes := document.NewValueBuffer()
+		for _, name := range catalog.ListIndexes("") {
+			info, err := catalog.GetIndexInfo(name)
+			if err != nil {
+				return err
+			}
+			if info.Owner.TableName != "" || strings.HasPrefix(info.TableName, database.InternalPrefix) {
+				continue
+			}
+
+			indexes.Append(document.NewDocumentValue(indexSchema(info)))
+		}
+
+		sequences := document.NewValueBuffer()
+		for _, name := range catalog.ListSequences() {
+			seq, err := catalog.GetSequence(name)
+			if err != nil {
+				return err
+			}
+			if seq.Info.Owner.TableName != "" || strings.HasPrefix(name, database.InternalPrefix) {
+				continue
+			}
+
+			sequences.Append(document.NewDocumentValue(sequenceSchema(seq.Info)))
+		}
+
+		fb.Add("tables", document.NewArrayValue(tables))
+		fb.Add("indexes", document.NewArrayValue(indexes))
+		fb.Add("sequences", document.NewArrayValue(sequences))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &fb, nil
+}
+
+func tableSchema(ti *database.TableInfo) *document.FieldBuffer {
+	fields := document.NewValueBuffer()
+	for _, fc := range ti.FieldConstraints {
+		if fc.IsInferred {
+			continue
+		}
+
+		f := document.NewFieldBuffer().
+			Add("path", document.NewTextValue(fc.Path.String())).
+			Add("type", document.NewTextValue(fc.Type.String())).
+			Add("primary_key", document.NewBoolValue(fc.IsPrimaryKey)).
+			Add("not_null", document.NewBoolValue(fc.IsNotNull)).
+			Add("unique", document.NewBoolValue(fc.IsUnique))
+		if fc.DefaultValue != nil {
+			f.Add("default", document.NewTextValue(fc.DefaultValue.String()))
+		}
+
+		fields.Append(document.NewDocumentValue(f))
+	}
+
+	return document.NewFieldBuffer().
+		Add("name", document.NewTextValue(ti.TableName)).
+		Add("fields", document.NewArrayValue(fields)).
+		Add("checksum", document.NewBoolValue(ti.Checksum)).
+		Add("audit", document.NewBoolValue(ti.Audit)).
+		Add("sql", document.NewTextValue(ti.String()))
+}
+
+func indexSchema(info *database.IndexInfo) *document.FieldBuffer {
+	paths := document.NewValueBuffer()
+	for _, p := range info.Paths {
+		paths.Append(document.NewTextValue(p.String()))
+	}
+
+	return document.NewFieldBuffer().
+		Add("name", document.NewTextValue(info.IndexName)).
+		Add("table", document.NewTextValue(info.TableName)).
+		Add("paths", document.NewArrayValue(paths)).
+		Add("unique", document.NewBoolValue(info.Unique)).
+		Add("sql", document.NewTextValue(info.String()))
+}
+
+func sequenceSchema(info *database.SequenceInfo) *document.FieldBuffer {
+	return document.NewFieldBuffer().
+		Add("name", document.NewTextValue(info.Name)).
+		Add("increment_by", document.NewIntegerValue(info.IncrementBy)).
+		Add("min", document.NewIntegerValue(info.Min)).
+		Add("max", document.NewIntegerValue(info.Max)).
+		Add("start", document.NewIntegerValue(info.Start)).
+		Add("cache", document.NewIntegerValue(int64(info.Cache))).
+		Add("cycle", document.NewBoolValue(info.Cycle)).
+		Add("sql", document.NewTextValue(info.String()))
+}
+
+// ApplySchema compares the structure of the database with schema, as returned by Schema,
+// and creates the missing tables, indexes and sequences, in a single transaction.
+// Objects are created and compared using the sql field of their description, the other
+// fields are ignored.
+// Existing objects whose definition differs from the schema are left untouched and reported
+// in the returned warnings. Objects absent from the schema are never dropped.
+func (db *DB) ApplySchema(schema document.Document) (warnings []string, err error) {
+	err = db.Update(func(tx *Tx) error {
+		// sequences and tables must exist before the indexes and
+		// tables referring to them.
+		for _, field := range []string{"sequences", "tables", "indexes"} {
+			v, err := schema.GetByField(field)
+			if err == document.ErrFieldNotFound {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if v.Type != document.ArrayValue {
+				return stringutil.Errorf("invalid schema: %s must be an array", field)
+			}
+
+			err = v.V.(document.Array).Iterate(func(i int, v document.Value) error {
+				if v.Type != document.DocumentValue {
+					return stringutil.Errorf("invalid schema: %s[%d] must be a document", field, i)
+				}
+
+				q, err := v.V.(document.Document).GetByField("sql")
+				if err != nil || q.Type != document.TextValue {
+					return stringutil.Errorf("invalid schema: %s[%d] must have a sql field", field, i)
+				}
+
+				warning, err := db.applySchemaObject(tx, q.V.(string))
+				if warning != "" {
+					warnings = append(warnings, warning)
+				}
+				return err
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return warnings, nil
+}
+
+// applySchemaObject creates the object defined by q if it doesn't exist,
+// or returns a warning if it exists with a different definition.
+func (db *DB) applySchemaObject(tx *Tx, q string) (warning string, err error) {
+	pq, err := parser.ParseQuery(q)
+	if err != nil {
+		return "", err
+	}
+	if len(pq.Statements) != 1 {
+		return "", stringutil.Errorf("invalid schema: %q must contain exactly one statement", q)
+	}
+
+	catalog := db.db.Catalog
+
+	var kind, name, existing, expected string
+	switch t := pq.Statements[0].(type) {
+	case *statement.CreateTableStmt:
+		kind, name, expected = "table", t.Info.TableName, t.Info.String()
+		if ti, err := catalog.GetTableInfo(name); err == nil {
+			existing = ti.String()
+		}
+	case *statement.CreateIndexStmt:
+		kind, name, expected = "index", t.Info.IndexName, t.Info.String()
+		if info, err := catalog.GetIndexInfo(name); err == nil {
+			existing = info.String()
+		}
+	case *statement.CreateSequenceStmt:
+		kind, name, expected = "sequence", t.Info.Name, t.Info.String()
+		if seq, err := catalog.GetSequence(name); err == nil {
+			existing = seq.Info.String()
+		}
+	default:
+		return "", stringutil.Errorf("invalid schema: %q is not a CREATE TABLE, INDEX or SEQUENCE statement", q)
+	}
+
+	if existing == "" {
+		return "", tx.Exec(q)
+	}
+
+	if existing != expected {
+		return stringutil.Sprintf("%s %s differs from the schema: expected %q, got %q", kind, name, expected, existing), nil
+	}
+
+	return "", nil
+}
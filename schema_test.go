@@ -0,0 +1,76 @@
+package genji_test
+
+import (
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema(t *testing.T) {
+	src, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer src.Close()
+
+	err = src.Exec(`
+		CREATE TABLE foo(a INTEGER PRIMARY KEY, b TEXT NOT NULL UNIQUE, c DOUBLE DEFAULT 10);
+		CREATE TABLE bar;
+		CREATE INDEX idx_bar_a_b ON bar(a, b);
+		CREATE SEQUENCE seq INCREMENT BY 2;
+	`)
+	require.NoError(t, err)
+
+	schema, err := src.Schema()
+	require.NoError(t, err)
+
+	testutil.RequireDocJSONEq(t, schema, `{
+		"tables": [
+			{"name": "bar", "fields": [], "checksum": false, "audit": false, "sql": "CREATE TABLE bar"},
+			{"name": "foo", "fields": [
+				{"path": "a", "type": "integer", "primary_key": true, "not_null": false, "unique": false},
+				{"path": "b", "type": "text", "primary_key": false, "not_null": true, "unique": true},
+				{"path": "c", "type": "double", "primary_key": false, "not_null": false, "unique": false, "default": "10"}
+			], "checksum": false, "audit": false, "sql": "CREATE TABLE foo (a INTEGER PRIMARY KEY, b TEXT NOT NULL UNIQUE, c DOUBLE DEFAULT 10)"}
+		],
+		"indexes": [
+			{"name": "idx_bar_a_b", "table": "bar", "paths": ["a", "b"], "unique": false, "sql": "CREATE INDEX idx_bar_a_b ON bar (a, b)"}
+		],
+		"sequences": [
+			{"name": "seq", "increment_by": 2, "min": 1, "max": 9223372036854775807, "start": 1, "cache": 1, "cycle": false, "sql": "CREATE SEQUENCE seq INCREMENT BY 2"}
+		]
+	}`)
+
+	t.Run("apply", func(t *testing.T) {
+		dst, err := genji.Open(":memory:")
+		require.NoError(t, err)
+		defer dst.Close()
+
+		err = dst.Exec("CREATE TABLE bar(a INTEGER)")
+		require.NoError(t, err)
+
+		warnings, err := dst.ApplySchema(schema)
+		require.NoError(t, err)
+		require.Len(t, warnings, 1)
+		require.Contains(t, warnings[0], "table bar differs from the schema")
+
+		schema2, err := dst.Schema()
+		require.NoError(t, err)
+
+		// only the existing table differs
+		for _, field := range []string{"indexes", "sequences"} {
+			v1, err := schema.GetByField(field)
+			require.NoError(t, err)
+			v2, err := schema2.GetByField(field)
+			require.NoError(t, err)
+			ok, err := v1.IsEqual(v2)
+			require.NoError(t, err)
+			require.True(t, ok)
+		}
+
+		// applying the schema again is a no-op
+		warnings, err = dst.ApplySchema(schema2)
+		require.NoError(t, err)
+		require.Empty(t, warnings)
+	})
+}
@@ -0,0 +1,244 @@
+package genji
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/database"
+	"github.com/genjidb/genji/internal/environment"
+	"github.com/genjidb/genji/internal/query/statement"
+)
+
+// maxSessionStatements is the number of prepared statements
+// cached by a session.
+const maxSessionStatements = 128
+
+// A Session is a handle to the database meant to be used by a single client,
+// like a connection of a server or of a pool.
+// It carries settings applied to every statement it runs and caches
+// the statements it prepares, which are reused when the same query is run again.
+// Cached statements are discarded when the session runs statements altering
+// the structure of the database.
+// Sessions are unprivileged by default: the fields having a mask,
+// set with ALTER TABLE ... SET MASK, are returned masked by their queries.
+// It's safe for concurrent use by multiple goroutines.
+type Session struct {
+	db *DB
+
+	mu               sync.Mutex
+	privileged       bool
+	statementTimeout time.Duration
+	vars             []environment.Param
+	statements       map[string]*list.Element
+	lru              list.List
+}
+
+type cachedStatement struct {
+	q    string
+	stmt *Statement
+}
+
+// Session creates a session using ctx for every statement it runs.
+func (db *DB) Session(ctx context.Context) *Session {
+	return &Session{
+		db:         db.WithContext(ctx),
+		statements: make(map[string]*list.Element),
+	}
+}
+
+// SetStatementTimeout sets the maximum duration of the statements run by the session.
+// For queries, it includes the iteration of the result.
+// Zero means no timeout.
+func (s *Session) SetStatementTimeout(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.statementTimeout = d
+}
+
+// SetPrivileged sets whether the session sees the values of the masked fields.
+// It applies to the transactions started after this call.
+func (s *Session) SetPrivileged(privileged bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.privileged = privileged
+}
+
+// Set the session variable with the given name. Session variables are passed
+// to every statement run by the session as named parameters, e.g. $user, unless
+// the statement is given a parameter with the same name. As with any named parameter,
+// they cannot be used by statements using positional parameters.
+// Setting a variable to nil removes it.
+func (s *Session) Set(name string, v interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.vars {
+		if s.vars[i].Name != name {
+			continue
+		}
+
+		if v == nil {
+			s.vars = append(s.vars[:i:i], s.vars[i+1:]...)
+		} else {
+			s.vars[i].Value = v
+		}
+		return nil
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	// ensure the value can be used by statements
+	_, err := document.NewValue(v)
+	if err != nil {
+		return err
+	}
+
+	s.vars = append(s.vars, environment.Param{Name: name, Value: v})
+	return nil
+}
+
+// Get returns the value of the session variable with the given name,
+// or nil if it doesn't exist.
+func (s *Session) Get(name string) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range s.vars {
+		if p.Name == name {
+			return p.Value
+		}
+	}
+
+	return nil
+}
+
+// Prepare returns a prepared statement for q, reusing the one prepared
+// the last time q was run or prepared by the session, if any.
+func (s *Session) Prepare(q string) (*Statement, error) {
+	s.mu.Lock()
+	if e, ok := s.statements[q]; ok {
+		s.lru.MoveToFront(e)
+		s.mu.Unlock()
+		return e.Value.(*cachedStatement).stmt, nil
+	}
+	s.mu.Unlock()
+
+	stmt, err := s.db.Prepare(q)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.statements[q]; ok {
+		return e.Value.(*cachedStatement).stmt, nil
+	}
+
+	s.statements[q] = s.lru.PushFront(&cachedStatement{q: q, stmt: stmt})
+	if s.lru.Len() > maxSessionStatements {
+		e := s.lru.Back()
+		s.lru.Remove(e)
+		delete(s.statements, e.Value.(*cachedStatement).q)
+	}
+
+	return stmt, nil
+}
+
+// Query the database and return the result.
+// The returned result must always be closed after usage.
+func (s *Session) Query(q string, args ...interface{}) (*Result, error) {
+	stmt, err := s.Prepare(q)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.run(stmt, args)
+}
+
+// QueryDocument runs the query and returns the first document.
+// If the query returns no error, QueryDocument returns errs.ErrDocumentNotFound.
+func (s *Session) QueryDocument(q string, args ...interface{}) (d document.Document, err error) {
+	res, err := s.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		er := res.Close()
+		if err == nil {
+			err = er
+		}
+	}()
+
+	return scanDocument(res)
+}
+
+// Exec a query against the database without returning the result.
+func (s *Session) Exec(q string, args ...interface{}) (err error) {
+	res, err := s.Query(q, args...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		er := res.Close()
+		if err == nil {
+			err = er
+		}
+	}()
+
+	return res.Iterate(func(d document.Document) error {
+		return nil
+	})
+}
+
+// run the statement with the settings of the session.
+func (s *Session) run(stmt *Statement, args []interface{}) (*Result, error) {
+	s.mu.Lock()
+	timeout := s.statementTimeout
+	privileged := s.privileged
+	params := append(argsToParams(args), s.vars...)
+	s.mu.Unlock()
+
+	db := s.db
+	if !privileged {
+		db = db.WithContext(database.WithUnprivileged(db.ctx))
+	}
+	cancel := func() {}
+	if timeout > 0 {
+		var ctx context.Context
+		ctx, cancel = context.WithTimeout(db.ctx, timeout)
+		db = db.WithContext(ctx)
+	}
+
+	r, err := stmt.pq.Run(newQueryContext(db, nil, params))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// statements altering the structure of the database
+	// may invalidate the cached ones.
+	for _, st := range stmt.pq.Statements {
+		if _, ok := st.(*statement.StreamStmt); !ok {
+			s.clearCache()
+			break
+		}
+	}
+
+	return &Result{result: r, cancel: cancel}, nil
+}
+
+func (s *Session) clearCache() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.statements = make(map[string]*list.Element)
+	s.lru.Init()
+}
@@ -0,0 +1,132 @@
+package genji_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession(t *testing.T) {
+	db, err := genji.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec("CREATE TABLE foo(a int, owner text); INSERT INTO foo (a, owner) VALUES (1, 'alice'), (2, 'bob'), (3, 'alice')")
+	require.NoError(t, err)
+
+	t.Run("variables", func(t *testing.T) {
+		s := db.Session(context.Background())
+		require.NoError(t, s.Set("owner", "alice"))
+		require.Equal(t, "alice", s.Get("owner"))
+
+		d, err := s.QueryDocument("SELECT COUNT(*) AS n FROM foo WHERE owner = $owner")
+		require.NoError(t, err)
+		testutil.RequireDocJSONEq(t, d, `{"n": 2}`)
+
+		// parameters take precedence over variables
+		d, err = s.QueryDocument("SELECT COUNT(*) AS n FROM foo WHERE owner = $owner AND a > $min", sql.Named("owner", "bob"), sql.Named("min", 0))
+		require.NoError(t, err)
+		testutil.RequireDocJSONEq(t, d, `{"n": 1}`)
+
+		require.NoError(t, s.Set("owner", nil))
+		require.Nil(t, s.Get("owner"))
+		_, err = s.QueryDocument("SELECT COUNT(*) AS n FROM foo WHERE owner = $owner")
+		require.Error(t, err)
+	})
+
+	t.Run("cache", func(t *testing.T) {
+		s := db.Session(context.Background())
+
+		stmt1, err := s.Prepare("SELECT * FROM foo")
+		require.NoError(t, err)
+		stmt2, err := s.Prepare("SELECT * FROM foo")
+		require.NoError(t, err)
+		require.True(t, stmt1 == stmt2)
+
+		require.NoError(t, s.Exec("CREATE INDEX idx_foo_a ON foo(a)"))
+
+		stmt2, err = s.Prepare("SELECT * FROM foo")
+		require.NoError(t, err)
+		require.False(t, stmt1 == stmt2)
+	})
+
+	t.Run("statement timeout", func(t *testing.T) {
+		s := db.Session(context.Background())
+		s.SetStatementTimeout(10 * time.Millisecond)
+
+		res, err := s.Query("SELECT * FROM foo")
+		require.NoError(t, err)
+		defer res.Close()
+
+		time.Sleep(20 * time.Millisecond)
+		err = res.Iterate(func(d document.Document) error { return nil })
+		require.Equal(t, context.DeadlineExceeded, err)
+	})
+}
+
+func TestSessionMasks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "genji")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	db, err := genji.Open(filepath.Join(dir, "test.db"))
+	require.NoError(t, err)
+
+	err = db.Exec(`
+		CREATE TABLE users(id INTEGER PRIMARY KEY, name TEXT, ssn TEXT);
+		INSERT INTO users (id, name, ssn) VALUES (1, 'alice', '123-45-6789'), (2, 'bob', '987-65-4321');
+		ALTER TABLE users SET MASK ON ssn USING mask(ssn, 4);
+		ALTER TABLE users SET MASK ON name USING 'hidden';
+	`)
+	require.NoError(t, err)
+
+	// masks are loaded with the catalog
+	require.NoError(t, db.Close())
+	db, err = genji.Open(filepath.Join(dir, "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	query := func(s *genji.Session, q string) string {
+		res, err := s.Query(q)
+		require.NoError(t, err)
+		defer res.Close()
+
+		var buf bytes.Buffer
+		err = testutil.IteratorToJSONArray(&buf, res)
+		require.NoError(t, err)
+		return buf.String()
+	}
+
+	s := db.Session(context.Background())
+	require.JSONEq(t, `[{"id": 1, "name": "hidden", "ssn": "XXXXXXX6789"}, {"id": 2, "name": "hidden", "ssn": "XXXXXXX4321"}]`, query(s, "SELECT * FROM users"))
+	require.JSONEq(t, `[{"id": 1, "s": "XXXXXXX6789"}]`, query(s, "SELECT id, ssn AS s FROM users WHERE ssn = '123-45-6789'"))
+	require.JSONEq(t, `[{"MAX(ssn)": "XXXXXXX6789"}]`, query(s, "SELECT MAX(ssn) FROM users"))
+	require.JSONEq(t, `[{"name": "hidden"}]`, query(s, "SELECT name FROM users GROUP BY name"))
+
+	// updates see the actual values
+	err = s.Exec("UPDATE users SET ssn = ssn || '0' WHERE id = 1")
+	require.NoError(t, err)
+
+	// privileged sessions and the database see the actual values
+	s.SetPrivileged(true)
+	require.JSONEq(t, `[{"id": 1, "name": "alice", "ssn": "123-45-67890"}]`, query(s, "SELECT * FROM users WHERE id = 1"))
+	d, err := db.QueryDocument("SELECT name FROM users WHERE id = 2")
+	require.NoError(t, err)
+	testutil.RequireDocJSONEq(t, d, `{"name": "bob"}`)
+
+	// masks are checked when the statement is run
+	s.SetPrivileged(false)
+	err = db.Exec("ALTER TABLE users DROP MASK ON name")
+	require.NoError(t, err)
+	require.JSONEq(t, `[{"name": "alice"}, {"name": "bob"}]`, query(s, "SELECT name FROM users"))
+}
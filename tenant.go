@@ -0,0 +1,28 @@
+package genji
+
+import (
+	"context"
+
+	"github.com/genjidb/genji/engine"
+	"github.com/genjidb/genji/internal/database"
+)
+
+// WithTenant returns a copy of ctx associated with the given tenant.
+// The databases created with NewMultiTenant and the sessions using that context
+// only read and write the documents of this tenant.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return database.WithTenant(ctx, tenant)
+}
+
+// NewMultiTenant initializes a DB storing the documents of several tenants in the given engine.
+// The keys of the tables and indexes are prefixed with the tenant associated with the context
+// of the database or of the session, set with WithTenant, guaranteeing at the storage level
+// that tenants never see the documents of each other. Databases and sessions without a tenant
+// use a namespace of their own.
+// The tables, indexes and sequences are shared by every tenant.
+// As CREATE INDEX only indexes the existing documents of the tenant creating the index,
+// indexes must be created before tenants insert documents in their table.
+// The engine must always be opened with NewMultiTenant.
+func NewMultiTenant(ctx context.Context, ng engine.Engine) (*DB, error) {
+	return New(ctx, database.NewTenantEngine(ng))
+}
@@ -0,0 +1,71 @@
+package genji_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/genjidb/genji"
+	"github.com/genjidb/genji/document"
+	"github.com/genjidb/genji/engine/memoryengine"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiTenant(t *testing.T) {
+	db, err := genji.NewMultiTenant(context.Background(), memoryengine.NewEngine())
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.Exec(`
+		CREATE TABLE test (k INTEGER PRIMARY KEY, a TEXT);
+		CREATE INDEX idx_a ON test (a);
+		CREATE TABLE docs;
+	`)
+	require.NoError(t, err)
+
+	a := db.Session(genji.WithTenant(context.Background(), "a"))
+	b := db.Session(genji.WithTenant(context.Background(), "b"))
+
+	require.NoError(t, a.Exec(`INSERT INTO test (k, a) VALUES (1, 'foo'), (2, 'bar'); INSERT INTO docs (a) VALUES (1)`))
+	// the same primary key can be used by every tenant
+	require.NoError(t, b.Exec(`INSERT INTO test (k, a) VALUES (1, 'baz')`))
+
+	count := func(s *genji.Session, q string) int64 {
+		d, err := s.QueryDocument(q)
+		require.NoError(t, err)
+		v, err := d.GetByField("COUNT(*)")
+		require.NoError(t, err)
+		return v.V.(int64)
+	}
+
+	require.EqualValues(t, 2, count(a, "SELECT COUNT(*) FROM test"))
+	require.EqualValues(t, 1, count(b, "SELECT COUNT(*) FROM test"))
+	require.EqualValues(t, 1, count(a, "SELECT COUNT(*) FROM test WHERE a = 'foo'"))
+	require.EqualValues(t, 0, count(b, "SELECT COUNT(*) FROM test WHERE a = 'foo'"))
+	require.EqualValues(t, 0, count(b, "SELECT COUNT(*) FROM docs"))
+
+	d, err := b.QueryDocument("SELECT a FROM test WHERE k = 1")
+	require.NoError(t, err)
+	var s string
+	require.NoError(t, document.Scan(d, &s))
+	require.Equal(t, "baz", s)
+
+	// databases use the tenant of their context
+	d, err = db.WithContext(genji.WithTenant(context.Background(), "a")).QueryDocument("SELECT COUNT(*) FROM test")
+	require.NoError(t, err)
+	v, err := d.GetByField("COUNT(*)")
+	require.NoError(t, err)
+	require.EqualValues(t, 2, v.V)
+
+	// without a tenant, the database uses its own namespace
+	d, err = db.QueryDocument("SELECT COUNT(*) FROM test")
+	require.NoError(t, err)
+	v, err = d.GetByField("COUNT(*)")
+	require.NoError(t, err)
+	require.EqualValues(t, 0, v.V)
+
+	// deleting every document only affects the tenant
+	require.NoError(t, b.Exec("DELETE FROM test"))
+	require.EqualValues(t, 0, count(b, "SELECT COUNT(*) FROM test"))
+	require.EqualValues(t, 2, count(a, "SELECT COUNT(*) FROM test"))
+	require.EqualValues(t, 1, count(a, "SELECT COUNT(*) FROM test WHERE a = 'bar'"))
+}